@@ -0,0 +1,656 @@
+// The essenz extraction API, served by sz serve --grpc.
+//
+// Both calls stream their result: large documents arrive as a sequence of
+// chunks that the client concatenates in order.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.28.3
+// source: essenz/v1/essenz.proto
+
+package essenzv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// FetchOptions control how a page is loaded.
+type FetchOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// CSS selector to wait for before the page is read.
+	WaitForSelector string `protobuf:"bytes,1,opt,name=wait_for_selector,json=waitForSelector,proto3" json:"wait_for_selector,omitempty"`
+	// Extra request headers.
+	Headers map[string]string `protobuf:"bytes,2,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// User agent string, or a preset name such as "chrome".
+	UserAgent     string `protobuf:"bytes,3,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchOptions) Reset() {
+	*x = FetchOptions{}
+	mi := &file_essenz_v1_essenz_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchOptions) ProtoMessage() {}
+
+func (x *FetchOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_essenz_v1_essenz_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchOptions.ProtoReflect.Descriptor instead.
+func (*FetchOptions) Descriptor() ([]byte, []int) {
+	return file_essenz_v1_essenz_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *FetchOptions) GetWaitForSelector() string {
+	if x != nil {
+		return x.WaitForSelector
+	}
+	return ""
+}
+
+func (x *FetchOptions) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *FetchOptions) GetUserAgent() string {
+	if x != nil {
+		return x.UserAgent
+	}
+	return ""
+}
+
+type FetchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The http or https URL to fetch.
+	Url           string        `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Options       *FetchOptions `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchRequest) Reset() {
+	*x = FetchRequest{}
+	mi := &file_essenz_v1_essenz_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchRequest) ProtoMessage() {}
+
+func (x *FetchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_essenz_v1_essenz_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchRequest.ProtoReflect.Descriptor instead.
+func (*FetchRequest) Descriptor() ([]byte, []int) {
+	return file_essenz_v1_essenz_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *FetchRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *FetchRequest) GetOptions() *FetchOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type FetchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The next part of the page's HTML.
+	Chunk         string `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchResponse) Reset() {
+	*x = FetchResponse{}
+	mi := &file_essenz_v1_essenz_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchResponse) ProtoMessage() {}
+
+func (x *FetchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_essenz_v1_essenz_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchResponse.ProtoReflect.Descriptor instead.
+func (*FetchResponse) Descriptor() ([]byte, []int) {
+	return file_essenz_v1_essenz_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *FetchResponse) GetChunk() string {
+	if x != nil {
+		return x.Chunk
+	}
+	return ""
+}
+
+type ExtractRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The http or https URL to distill. When html is set, the URL is only used
+	// to resolve relative links in the metadata and may be empty.
+	Url string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	// HTML to distill instead of fetching the URL.
+	Html    string        `protobuf:"bytes,2,opt,name=html,proto3" json:"html,omitempty"`
+	Options *FetchOptions `protobuf:"bytes,3,opt,name=options,proto3" json:"options,omitempty"`
+	// Return the raw HTML instead of the reader view.
+	Raw bool `protobuf:"varint,4,opt,name=raw,proto3" json:"raw,omitempty"`
+	// Append reader comments as a separate section.
+	WithComments  bool `protobuf:"varint,5,opt,name=with_comments,json=withComments,proto3" json:"with_comments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtractRequest) Reset() {
+	*x = ExtractRequest{}
+	mi := &file_essenz_v1_essenz_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtractRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtractRequest) ProtoMessage() {}
+
+func (x *ExtractRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_essenz_v1_essenz_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtractRequest.ProtoReflect.Descriptor instead.
+func (*ExtractRequest) Descriptor() ([]byte, []int) {
+	return file_essenz_v1_essenz_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ExtractRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ExtractRequest) GetHtml() string {
+	if x != nil {
+		return x.Html
+	}
+	return ""
+}
+
+func (x *ExtractRequest) GetOptions() *FetchOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *ExtractRequest) GetRaw() bool {
+	if x != nil {
+		return x.Raw
+	}
+	return false
+}
+
+func (x *ExtractRequest) GetWithComments() bool {
+	if x != nil {
+		return x.WithComments
+	}
+	return false
+}
+
+type ExtractResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*ExtractResponse_Metadata
+	//	*ExtractResponse_Chunk
+	Payload       isExtractResponse_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtractResponse) Reset() {
+	*x = ExtractResponse{}
+	mi := &file_essenz_v1_essenz_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtractResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtractResponse) ProtoMessage() {}
+
+func (x *ExtractResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_essenz_v1_essenz_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtractResponse.ProtoReflect.Descriptor instead.
+func (*ExtractResponse) Descriptor() ([]byte, []int) {
+	return file_essenz_v1_essenz_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ExtractResponse) GetPayload() isExtractResponse_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *ExtractResponse) GetMetadata() *Metadata {
+	if x != nil {
+		if x, ok := x.Payload.(*ExtractResponse_Metadata); ok {
+			return x.Metadata
+		}
+	}
+	return nil
+}
+
+func (x *ExtractResponse) GetChunk() string {
+	if x != nil {
+		if x, ok := x.Payload.(*ExtractResponse_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return ""
+}
+
+type isExtractResponse_Payload interface {
+	isExtractResponse_Payload()
+}
+
+type ExtractResponse_Metadata struct {
+	Metadata *Metadata `protobuf:"bytes,1,opt,name=metadata,proto3,oneof"`
+}
+
+type ExtractResponse_Chunk struct {
+	// The next part of the markdown.
+	Chunk string `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*ExtractResponse_Metadata) isExtractResponse_Payload() {}
+
+func (*ExtractResponse_Chunk) isExtractResponse_Payload() {}
+
+// Metadata describes a page, as printed by sz meta.
+type Metadata struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Author        string                 `protobuf:"bytes,4,opt,name=author,proto3" json:"author,omitempty"`
+	Published     string                 `protobuf:"bytes,5,opt,name=published,proto3" json:"published,omitempty"`
+	Modified      string                 `protobuf:"bytes,6,opt,name=modified,proto3" json:"modified,omitempty"`
+	Canonical     string                 `protobuf:"bytes,7,opt,name=canonical,proto3" json:"canonical,omitempty"`
+	Language      string                 `protobuf:"bytes,8,opt,name=language,proto3" json:"language,omitempty"`
+	Image         string                 `protobuf:"bytes,9,opt,name=image,proto3" json:"image,omitempty"`
+	SiteName      string                 `protobuf:"bytes,10,opt,name=site_name,json=siteName,proto3" json:"site_name,omitempty"`
+	Feeds         []*Feed                `protobuf:"bytes,11,rep,name=feeds,proto3" json:"feeds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Metadata) Reset() {
+	*x = Metadata{}
+	mi := &file_essenz_v1_essenz_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Metadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Metadata) ProtoMessage() {}
+
+func (x *Metadata) ProtoReflect() protoreflect.Message {
+	mi := &file_essenz_v1_essenz_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Metadata.ProtoReflect.Descriptor instead.
+func (*Metadata) Descriptor() ([]byte, []int) {
+	return file_essenz_v1_essenz_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Metadata) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Metadata) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Metadata) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Metadata) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *Metadata) GetPublished() string {
+	if x != nil {
+		return x.Published
+	}
+	return ""
+}
+
+func (x *Metadata) GetModified() string {
+	if x != nil {
+		return x.Modified
+	}
+	return ""
+}
+
+func (x *Metadata) GetCanonical() string {
+	if x != nil {
+		return x.Canonical
+	}
+	return ""
+}
+
+func (x *Metadata) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *Metadata) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *Metadata) GetSiteName() string {
+	if x != nil {
+		return x.SiteName
+	}
+	return ""
+}
+
+func (x *Metadata) GetFeeds() []*Feed {
+	if x != nil {
+		return x.Feeds
+	}
+	return nil
+}
+
+// Feed is a feed a page advertises.
+type Feed struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Feed) Reset() {
+	*x = Feed{}
+	mi := &file_essenz_v1_essenz_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Feed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Feed) ProtoMessage() {}
+
+func (x *Feed) ProtoReflect() protoreflect.Message {
+	mi := &file_essenz_v1_essenz_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Feed.ProtoReflect.Descriptor instead.
+func (*Feed) Descriptor() ([]byte, []int) {
+	return file_essenz_v1_essenz_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Feed) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Feed) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Feed) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+var File_essenz_v1_essenz_proto protoreflect.FileDescriptor
+
+const file_essenz_v1_essenz_proto_rawDesc = "" +
+	"\n" +
+	"\x16essenz/v1/essenz.proto\x12\tessenz.v1\"\xd5\x01\n" +
+	"\fFetchOptions\x12*\n" +
+	"\x11wait_for_selector\x18\x01 \x01(\tR\x0fwaitForSelector\x12>\n" +
+	"\aheaders\x18\x02 \x03(\v2$.essenz.v1.FetchOptions.HeadersEntryR\aheaders\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\x03 \x01(\tR\tuserAgent\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"S\n" +
+	"\fFetchRequest\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x121\n" +
+	"\aoptions\x18\x02 \x01(\v2\x17.essenz.v1.FetchOptionsR\aoptions\"%\n" +
+	"\rFetchResponse\x12\x14\n" +
+	"\x05chunk\x18\x01 \x01(\tR\x05chunk\"\xa0\x01\n" +
+	"\x0eExtractRequest\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x12\n" +
+	"\x04html\x18\x02 \x01(\tR\x04html\x121\n" +
+	"\aoptions\x18\x03 \x01(\v2\x17.essenz.v1.FetchOptionsR\aoptions\x12\x10\n" +
+	"\x03raw\x18\x04 \x01(\bR\x03raw\x12#\n" +
+	"\rwith_comments\x18\x05 \x01(\bR\fwithComments\"g\n" +
+	"\x0fExtractResponse\x121\n" +
+	"\bmetadata\x18\x01 \x01(\v2\x13.essenz.v1.MetadataH\x00R\bmetadata\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\tH\x00R\x05chunkB\t\n" +
+	"\apayload\"\xba\x02\n" +
+	"\bMetadata\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06author\x18\x04 \x01(\tR\x06author\x12\x1c\n" +
+	"\tpublished\x18\x05 \x01(\tR\tpublished\x12\x1a\n" +
+	"\bmodified\x18\x06 \x01(\tR\bmodified\x12\x1c\n" +
+	"\tcanonical\x18\a \x01(\tR\tcanonical\x12\x1a\n" +
+	"\blanguage\x18\b \x01(\tR\blanguage\x12\x14\n" +
+	"\x05image\x18\t \x01(\tR\x05image\x12\x1b\n" +
+	"\tsite_name\x18\n" +
+	" \x01(\tR\bsiteName\x12%\n" +
+	"\x05feeds\x18\v \x03(\v2\x0f.essenz.v1.FeedR\x05feeds\"B\n" +
+	"\x04Feed\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type2\x92\x01\n" +
+	"\x0eExtractService\x12<\n" +
+	"\x05Fetch\x12\x17.essenz.v1.FetchRequest\x1a\x18.essenz.v1.FetchResponse0\x01\x12B\n" +
+	"\aExtract\x12\x19.essenz.v1.ExtractRequest\x1a\x1a.essenz.v1.ExtractResponse0\x01B6Z4github.com/jewell-lgtm/essenz/api/essenz/v1;essenzv1b\x06proto3"
+
+var (
+	file_essenz_v1_essenz_proto_rawDescOnce sync.Once
+	file_essenz_v1_essenz_proto_rawDescData []byte
+)
+
+func file_essenz_v1_essenz_proto_rawDescGZIP() []byte {
+	file_essenz_v1_essenz_proto_rawDescOnce.Do(func() {
+		file_essenz_v1_essenz_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_essenz_v1_essenz_proto_rawDesc), len(file_essenz_v1_essenz_proto_rawDesc)))
+	})
+	return file_essenz_v1_essenz_proto_rawDescData
+}
+
+var file_essenz_v1_essenz_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_essenz_v1_essenz_proto_goTypes = []any{
+	(*FetchOptions)(nil),    // 0: essenz.v1.FetchOptions
+	(*FetchRequest)(nil),    // 1: essenz.v1.FetchRequest
+	(*FetchResponse)(nil),   // 2: essenz.v1.FetchResponse
+	(*ExtractRequest)(nil),  // 3: essenz.v1.ExtractRequest
+	(*ExtractResponse)(nil), // 4: essenz.v1.ExtractResponse
+	(*Metadata)(nil),        // 5: essenz.v1.Metadata
+	(*Feed)(nil),            // 6: essenz.v1.Feed
+	nil,                     // 7: essenz.v1.FetchOptions.HeadersEntry
+}
+var file_essenz_v1_essenz_proto_depIdxs = []int32{
+	7, // 0: essenz.v1.FetchOptions.headers:type_name -> essenz.v1.FetchOptions.HeadersEntry
+	0, // 1: essenz.v1.FetchRequest.options:type_name -> essenz.v1.FetchOptions
+	0, // 2: essenz.v1.ExtractRequest.options:type_name -> essenz.v1.FetchOptions
+	5, // 3: essenz.v1.ExtractResponse.metadata:type_name -> essenz.v1.Metadata
+	6, // 4: essenz.v1.Metadata.feeds:type_name -> essenz.v1.Feed
+	1, // 5: essenz.v1.ExtractService.Fetch:input_type -> essenz.v1.FetchRequest
+	3, // 6: essenz.v1.ExtractService.Extract:input_type -> essenz.v1.ExtractRequest
+	2, // 7: essenz.v1.ExtractService.Fetch:output_type -> essenz.v1.FetchResponse
+	4, // 8: essenz.v1.ExtractService.Extract:output_type -> essenz.v1.ExtractResponse
+	7, // [7:9] is the sub-list for method output_type
+	5, // [5:7] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_essenz_v1_essenz_proto_init() }
+func file_essenz_v1_essenz_proto_init() {
+	if File_essenz_v1_essenz_proto != nil {
+		return
+	}
+	file_essenz_v1_essenz_proto_msgTypes[4].OneofWrappers = []any{
+		(*ExtractResponse_Metadata)(nil),
+		(*ExtractResponse_Chunk)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_essenz_v1_essenz_proto_rawDesc), len(file_essenz_v1_essenz_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_essenz_v1_essenz_proto_goTypes,
+		DependencyIndexes: file_essenz_v1_essenz_proto_depIdxs,
+		MessageInfos:      file_essenz_v1_essenz_proto_msgTypes,
+	}.Build()
+	File_essenz_v1_essenz_proto = out.File
+	file_essenz_v1_essenz_proto_goTypes = nil
+	file_essenz_v1_essenz_proto_depIdxs = nil
+}
@@ -0,0 +1,176 @@
+// The essenz extraction API, served by sz serve --grpc.
+//
+// Both calls stream their result: large documents arrive as a sequence of
+// chunks that the client concatenates in order.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.28.3
+// source: essenz/v1/essenz.proto
+
+package essenzv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ExtractService_Fetch_FullMethodName   = "/essenz.v1.ExtractService/Fetch"
+	ExtractService_Extract_FullMethodName = "/essenz.v1.ExtractService/Extract"
+)
+
+// ExtractServiceClient is the client API for ExtractService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ExtractServiceClient interface {
+	// Fetch renders a page and streams its HTML as fetched.
+	Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FetchResponse], error)
+	// Extract distills a page to markdown. The first message carries the
+	// page metadata and the rest the markdown.
+	Extract(ctx context.Context, in *ExtractRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExtractResponse], error)
+}
+
+type extractServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewExtractServiceClient(cc grpc.ClientConnInterface) ExtractServiceClient {
+	return &extractServiceClient{cc}
+}
+
+func (c *extractServiceClient) Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FetchResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ExtractService_ServiceDesc.Streams[0], ExtractService_Fetch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[FetchRequest, FetchResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ExtractService_FetchClient = grpc.ServerStreamingClient[FetchResponse]
+
+func (c *extractServiceClient) Extract(ctx context.Context, in *ExtractRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExtractResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ExtractService_ServiceDesc.Streams[1], ExtractService_Extract_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExtractRequest, ExtractResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ExtractService_ExtractClient = grpc.ServerStreamingClient[ExtractResponse]
+
+// ExtractServiceServer is the server API for ExtractService service.
+// All implementations must embed UnimplementedExtractServiceServer
+// for forward compatibility.
+type ExtractServiceServer interface {
+	// Fetch renders a page and streams its HTML as fetched.
+	Fetch(*FetchRequest, grpc.ServerStreamingServer[FetchResponse]) error
+	// Extract distills a page to markdown. The first message carries the
+	// page metadata and the rest the markdown.
+	Extract(*ExtractRequest, grpc.ServerStreamingServer[ExtractResponse]) error
+	mustEmbedUnimplementedExtractServiceServer()
+}
+
+// UnimplementedExtractServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedExtractServiceServer struct{}
+
+func (UnimplementedExtractServiceServer) Fetch(*FetchRequest, grpc.ServerStreamingServer[FetchResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Fetch not implemented")
+}
+func (UnimplementedExtractServiceServer) Extract(*ExtractRequest, grpc.ServerStreamingServer[ExtractResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Extract not implemented")
+}
+func (UnimplementedExtractServiceServer) mustEmbedUnimplementedExtractServiceServer() {}
+func (UnimplementedExtractServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeExtractServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ExtractServiceServer will
+// result in compilation errors.
+type UnsafeExtractServiceServer interface {
+	mustEmbedUnimplementedExtractServiceServer()
+}
+
+func RegisterExtractServiceServer(s grpc.ServiceRegistrar, srv ExtractServiceServer) {
+	// If the following call pancis, it indicates UnimplementedExtractServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ExtractService_ServiceDesc, srv)
+}
+
+func _ExtractService_Fetch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FetchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ExtractServiceServer).Fetch(m, &grpc.GenericServerStream[FetchRequest, FetchResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ExtractService_FetchServer = grpc.ServerStreamingServer[FetchResponse]
+
+func _ExtractService_Extract_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExtractRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ExtractServiceServer).Extract(m, &grpc.GenericServerStream[ExtractRequest, ExtractResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ExtractService_ExtractServer = grpc.ServerStreamingServer[ExtractResponse]
+
+// ExtractService_ServiceDesc is the grpc.ServiceDesc for ExtractService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ExtractService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "essenz.v1.ExtractService",
+	HandlerType: (*ExtractServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Fetch",
+			Handler:       _ExtractService_Fetch_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Extract",
+			Handler:       _ExtractService_Extract_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "essenz/v1/essenz.proto",
+}
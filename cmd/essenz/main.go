@@ -2,24 +2,82 @@
 package main
 
 import (
+	"bytes"
 	"context"
-	"crypto/tls"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/jewell-lgtm/essenz/internal/actions"
+	"github.com/jewell-lgtm/essenz/internal/adblock"
+	"github.com/jewell-lgtm/essenz/internal/batch"
+	"github.com/jewell-lgtm/essenz/internal/bookmark"
 	"github.com/jewell-lgtm/essenz/internal/browser"
+	"github.com/jewell-lgtm/essenz/internal/chrome"
+	"github.com/jewell-lgtm/essenz/internal/chunk"
+	"github.com/jewell-lgtm/essenz/internal/cite"
+	"github.com/jewell-lgtm/essenz/internal/compact"
+	"github.com/jewell-lgtm/essenz/internal/config"
+	"github.com/jewell-lgtm/essenz/internal/console"
+	"github.com/jewell-lgtm/essenz/internal/crawl"
 	"github.com/jewell-lgtm/essenz/internal/daemon"
+	"github.com/jewell-lgtm/essenz/internal/dataview"
+	"github.com/jewell-lgtm/essenz/internal/diff"
+	"github.com/jewell-lgtm/essenz/internal/download"
+	"github.com/jewell-lgtm/essenz/internal/emulate"
+	"github.com/jewell-lgtm/essenz/internal/export"
 	"github.com/jewell-lgtm/essenz/internal/extractor"
+	"github.com/jewell-lgtm/essenz/internal/feed"
 	"github.com/jewell-lgtm/essenz/internal/filter"
+	"github.com/jewell-lgtm/essenz/internal/har"
+	"github.com/jewell-lgtm/essenz/internal/hook"
+	"github.com/jewell-lgtm/essenz/internal/learn"
+	"github.com/jewell-lgtm/essenz/internal/llm"
+	"github.com/jewell-lgtm/essenz/internal/logging"
 	"github.com/jewell-lgtm/essenz/internal/markdown"
 	"github.com/jewell-lgtm/essenz/internal/media"
+	"github.com/jewell-lgtm/essenz/internal/merge"
+	"github.com/jewell-lgtm/essenz/internal/metadata"
+	"github.com/jewell-lgtm/essenz/internal/output"
 	"github.com/jewell-lgtm/essenz/internal/pageready"
+	"github.com/jewell-lgtm/essenz/internal/paginate"
+	"github.com/jewell-lgtm/essenz/internal/push"
+	"github.com/jewell-lgtm/essenz/internal/retry"
+	"github.com/jewell-lgtm/essenz/internal/revalidate"
+	"github.com/jewell-lgtm/essenz/internal/rpc"
+	"github.com/jewell-lgtm/essenz/internal/search"
+	"github.com/jewell-lgtm/essenz/internal/service"
+	"github.com/jewell-lgtm/essenz/internal/session"
+	"github.com/jewell-lgtm/essenz/internal/sitemap"
+	"github.com/jewell-lgtm/essenz/internal/textdoc"
+	"github.com/jewell-lgtm/essenz/internal/tokens"
+	"github.com/jewell-lgtm/essenz/internal/transcode"
+	"github.com/jewell-lgtm/essenz/internal/translate"
+	"github.com/jewell-lgtm/essenz/internal/transport"
 	"github.com/jewell-lgtm/essenz/internal/tree"
+	"github.com/jewell-lgtm/essenz/internal/tune"
+	"github.com/jewell-lgtm/essenz/internal/wayback"
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"gopkg.in/yaml.v3"
 )
 
 var version = "0.1.0"
@@ -27,12 +85,59 @@ var version = "0.1.0"
 // Command line flags
 var readerView bool
 var rawOutput bool
+var withComments bool
 
 // DOM ready event flags
 var waitForFrameworks bool
 var domReadyTimeout string
 var waitForSelector string
+var waitForText string
+var waitForLayoutStable bool
+var waitForNetworkIdle bool
+var stageTimeouts []string
 var debugReadiness bool
+var readinessReport string
+var scrollMode string
+var scrollMaxHeight int
+var evalScripts []string
+var actionsFile string
+var loadState string
+var cookiesFile string
+var saveCookiesFile string
+var requestHeaders []string
+var basicAuth string
+var userAgent string
+var viewportSize string
+var deviceName string
+var locale string
+var languages string
+var timezone string
+var geolocation string
+var adblockEnabled bool
+var adblockLists []string
+var captureConsole bool
+var consoleLog string
+var harFile string
+var downloadDir string
+var queueTimeout string
+var fetchRetries int
+var retryDelay string
+var insecureTLS bool
+var caCertFile string
+var clientCertFile string
+var clientKeyFile string
+var browserProfile string
+var incognito bool
+var socketPath string
+var daemonName string
+var remoteChrome string
+var outputTemplate string
+var presetName string
+var logLevel string
+var logFormat string
+var failOnEmpty bool
+var minWords int
+var ifExists string
 
 // Text node tree flags (F2)
 var textNodeTree bool
@@ -44,23 +149,81 @@ var preserveAttributes bool
 var contentFilter bool
 var aggressiveFiltering bool
 var preserveSelector string
+var excludeSelectors []string
+var filterHooks []string
 
 // Media handler flags (F4)
 var mediaHandler bool
 var includeDecorative bool
+var describeImages bool
 
 // Markdown renderer flags (F5)
 var markdownRenderer bool
 var emphasisStyle string
 var listStyle string
+
+// Statistics flags
+var showStats bool
+
+// Language model flags
+var showSummary bool
+var translateTo string
+var chunkSize int
+var chunkOverlap int
+var countTokens string
+var outputFormat string
+var flattenHeadings bool
+
+// followCanonicalURL re-fetches a page from the canonical URL it declares.
+var followCanonicalURL bool
+
+// preferAMP fetches the AMP version a page links to in its place.
+var preferAMP bool
+
+// preferPrint fetches the print version a page links to in its place.
+var preferPrint bool
+
+// followPagination fetches the following pages of a multi-page article.
+var followPagination bool
+
+// dataOutline outlines the structure of JSON and XML responses rather than
+// printing them whole.
+var dataOutline bool
+
+// Wayback Machine flags
+var waybackFallback bool
+var asOf string
+
+// archivedSnapshot is the Wayback Machine copy the root or fetch command
+// loaded in place of the live page, if any.
+var archivedSnapshot *wayback.Snapshot
+
+// pageValidators holds the ETag and Last-Modified of each page fetched, by
+// URL, once sz watch sets it up to ask whether a page changed before fetching
+// it again. A nil entry means the page had neither.
+var pageValidators map[string]*revalidate.Validators
+
+// Split flags
+var splitBy string
+var splitDir string
+
+// Export flags
+var exportTo string
+var vaultDir string
+
+// exportSource is the page the root command distilled, which --export takes
+// images and metadata from.
+var exportSource string
+
 var rootCmd = &cobra.Command{
-	Use:   "sz [URL or file path]",
+	Use:   "sz [URL, file path, or -]",
 	Short: "Distill the web into semantic markdown",
 	Long: `sz is a CLI web browser that extracts the essence of web pages, reordering content by importance rather than DOM structure.
 
 Examples:
   sz https://example.com         # Extract clean content from URL
   sz /path/to/article.html       # Extract clean content from local file
+  curl -s URL | sz -             # Extract clean content from HTML on stdin
   sz --raw https://example.com   # Get raw HTML without processing
   sz                             # Show this help`,
 	Args: cobra.MaximumNArgs(1),
@@ -72,16 +235,42 @@ Examples:
 		}
 
 		target := args[0]
+		checkSelectors(cmd)
+		if followPagination && rawOutput {
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Error: --follow-pagination cannot be combined with --raw")
+			os.Exit(exitUsage)
+		}
+		writeOutput, ok := captureOutput(cmd, target)
+		if !ok {
+			return
+		}
+		defer writeOutput()
+
 		var content string
+		var followingPages []string
 		var err error
 
 		// Check if it looks like a URL (simple heuristic)
-		if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
-			content, err = fetchURLWithChrome(cmd.Context(), target)
+		if target == "-" {
+			// HTML piped in from curl or another tool skips the browser
+			content, err = readStdin(cmd)
 			if err != nil {
-				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error fetching URL: %v\n", err)
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error reading stdin: %v\n", err)
 				os.Exit(1)
 			}
+		} else if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+			content, err = fetchURLOrArchive(cmd.Context(), target)
+			if reportDownload(cmd, err) {
+				return
+			}
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error fetching URL: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			content, target = preferredVersion(cmd.Context(), target, content)
+			if followPagination {
+				followingPages = fetchFollowingPages(cmd.Context(), target, content)
+			}
 		} else {
 			// Treat as file path
 			// If DOM ready flags are set, process file through Chrome for consistency
@@ -102,6 +291,17 @@ Examples:
 			}
 		}
 
+		if exportTo != "" {
+			exportSource = content
+		}
+
+		// Report statistics on stderr once the output has been written
+		var contentFilterer *filter.ContentFilter
+		if showStats {
+			source := content
+			defer func() { reportStats(cmd, source, contentFilterer) }()
+		}
+
 		// Apply text node tree processing if requested
 		if textNodeTree {
 			treeBuilder := tree.NewTreeBuilder().
@@ -133,7 +333,7 @@ Examples:
 		}
 
 		// Apply content filtering if requested
-		if contentFilter {
+		if contentFilter || len(filterHooks) > 0 {
 			// Build tree first
 			treeBuilder := tree.NewTreeBuilder().
 				WithFilterNavigation(false). // Don't use tree builder filtering, use content filter instead
@@ -146,13 +346,23 @@ Examples:
 			}
 
 			// Apply content filtering
-			contentFilterer := filter.NewContentFilter().
+			contentFilterer = filter.NewContentFilter().
 				WithAggressiveMode(aggressiveFiltering)
 
 			if preserveSelector != "" {
 				contentFilterer = contentFilterer.WithPreserveSelector(preserveSelector)
 			}
 
+			for _, selector := range excludeSelectors {
+				contentFilterer = contentFilterer.WithExcludeSelector(selector)
+			}
+
+			for _, hook := range filterHooks {
+				contentFilterer = contentFilterer.WithHook(hook)
+			}
+
+			contentFilterer = applySiteRules(cmd, contentFilterer, target)
+
 			filtered, err := contentFilterer.FilterTree(cmd.Context(), root)
 			if err != nil {
 				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error applying content filter: %v\n", err)
@@ -161,8 +371,7 @@ Examples:
 
 			// Apply media handling if requested after content filtering
 			if mediaHandler {
-				mediaHandler := media.NewMediaHandler().
-					WithIncludeDecorative(includeDecorative)
+				mediaHandler := newMediaHandler(cmd, target)
 
 				err := mediaHandler.ProcessMediaInTree(cmd.Context(), filtered)
 				if err != nil {
@@ -207,8 +416,7 @@ Examples:
 			}
 
 			// Apply media handling
-			mediaHandler := media.NewMediaHandler().
-				WithIncludeDecorative(includeDecorative)
+			mediaHandler := newMediaHandler(cmd, target)
 
 			err = mediaHandler.ProcessMediaInTree(cmd.Context(), root)
 			if err != nil {
@@ -268,17 +476,26 @@ Examples:
 		}
 		// Apply reader view processing by default, unless --raw flag is used
 		if !rawOutput {
-			ext := extractor.New()
-			markdown, err := ext.ExtractContent(content)
+			ext := extractor.New().WithComments(withComments)
+			markdown, err := extractMarkdown(ext, target, content)
 			if err != nil {
 				// Fallback to raw content on extraction error
-				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: Reader view extraction failed, showing raw content: %v\n", err)
+				slog.Warn("reader view extraction failed, showing raw content", "error", err)
 			} else {
 				content = markdown
 			}
+			if err == nil && len(followingPages) > 0 {
+				pages := []string{markdown}
+				for _, page := range followingPages {
+					if pageMarkdown, err := ext.ExtractContent(page); err == nil {
+						pages = append(pages, pageMarkdown)
+					}
+				}
+				content = paginate.Stitch(pages)
+			}
 		}
 
-		_, _ = fmt.Fprint(cmd.OutOrStdout(), content)
+		_, _ = fmt.Fprint(cmd.OutOrStdout(), markArchived(content, rawOutput))
 	},
 }
 
@@ -292,29 +509,68 @@ var versionCmd = &cobra.Command{
 }
 
 var fetchCmd = &cobra.Command{
-	Use:   "fetch [URL or file path]",
+	Use:   "fetch [URL, file path, or -]",
 	Short: "Fetch content from a URL or local file",
-	Long: `Fetch content from an HTTP(S) URL or read from a local file.
+	Long: `Fetch content from an HTTP(S) URL or read from a local file, or from stdin with -.
 
 Examples:
   sz fetch https://example.com
   sz fetch http://example.com
   sz fetch /path/to/file.html
-  sz fetch --reader-view https://example.com`,
+  curl -s https://example.com | sz fetch -
+  sz fetch --reader-view https://example.com
+  sz fetch --as-of 2022-06-01 -r https://example.com`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		target := args[0]
+		checkSelectors(cmd)
+
+		var asOfDate time.Time
+		if asOf != "" {
+			var err error
+			if asOfDate, err = time.Parse(time.DateOnly, asOf); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: invalid --as-of %q: use YYYY-MM-DD\n", asOf)
+				os.Exit(exitUsage)
+			}
+			if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+				_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Error: --as-of needs an http or https URL")
+				os.Exit(exitUsage)
+			}
+		}
+
+		writeOutput, ok := captureOutput(cmd, target)
+		if !ok {
+			return
+		}
+		defer writeOutput()
 
 		var content string
 		var err error
 
 		// Check if it looks like a URL (simple heuristic)
-		if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
-			content, err = fetchURLWithChrome(cmd.Context(), target)
+		if target == "-" {
+			// HTML piped in from curl or another tool skips the browser
+			content, err = readStdin(cmd)
 			if err != nil {
-				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error fetching URL: %v\n", err)
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error reading stdin: %v\n", err)
 				os.Exit(1)
 			}
+		} else if asOf != "" {
+			content, err = fetchSnapshot(cmd.Context(), target, asOfDate)
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error fetching snapshot: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+		} else if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+			content, err = fetchURLOrArchive(cmd.Context(), target)
+			if reportDownload(cmd, err) {
+				return
+			}
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error fetching URL: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			content, target = preferredVersion(cmd.Context(), target, content)
 		} else {
 			// Treat as file path
 			// If DOM ready flags are set, process file through Chrome for consistency
@@ -366,7 +622,7 @@ Examples:
 		}
 
 		// Apply content filtering if requested
-		if contentFilter {
+		if contentFilter || len(filterHooks) > 0 {
 			// Build tree first
 			treeBuilder := tree.NewTreeBuilder().
 				WithFilterNavigation(false). // Don't use tree builder filtering, use content filter instead
@@ -386,6 +642,16 @@ Examples:
 				contentFilterer = contentFilterer.WithPreserveSelector(preserveSelector)
 			}
 
+			for _, selector := range excludeSelectors {
+				contentFilterer = contentFilterer.WithExcludeSelector(selector)
+			}
+
+			for _, hook := range filterHooks {
+				contentFilterer = contentFilterer.WithHook(hook)
+			}
+
+			contentFilterer = applySiteRules(cmd, contentFilterer, target)
+
 			filtered, err := contentFilterer.FilterTree(cmd.Context(), root)
 			if err != nil {
 				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error applying content filter: %v\n", err)
@@ -394,8 +660,7 @@ Examples:
 
 			// Apply media handling if requested after content filtering
 			if mediaHandler {
-				mediaHandler := media.NewMediaHandler().
-					WithIncludeDecorative(includeDecorative)
+				mediaHandler := newMediaHandler(cmd, target)
 
 				err := mediaHandler.ProcessMediaInTree(cmd.Context(), filtered)
 				if err != nil {
@@ -440,8 +705,7 @@ Examples:
 			}
 
 			// Apply media handling
-			mediaHandler := media.NewMediaHandler().
-				WithIncludeDecorative(includeDecorative)
+			mediaHandler := newMediaHandler(cmd, target)
 
 			err = mediaHandler.ProcessMediaInTree(cmd.Context(), root)
 			if err != nil {
@@ -501,245 +765,4788 @@ Examples:
 		}
 		// Apply reader view processing if requested
 		if readerView {
-			ext := extractor.New()
-			markdown, err := ext.ExtractContent(content)
+			markdown, err := extractMarkdown(extractor.New().WithComments(withComments), target, content)
 			if err != nil {
 				// Fallback to raw content on extraction error
-				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: Reader view extraction failed, showing raw content: %v\n", err)
+				slog.Warn("reader view extraction failed, showing raw content", "error", err)
 			} else {
 				content = markdown
 			}
 		}
 
-		_, _ = fmt.Fprint(cmd.OutOrStdout(), content)
+		_, _ = fmt.Fprint(cmd.OutOrStdout(), markArchived(content, !readerView))
 	},
 }
 
-var daemonCmd = &cobra.Command{
-	Use:   "daemon",
-	Short: "Manage the Chrome daemon",
-	Long:  `Start, stop, or check the status of the Chrome daemon process.`,
-}
+// Batch command flags
+var (
+	batchOutputDir   string
+	batchConcurrency int
+	batchPerHost     int
+	batchAsCompleted bool
+	jsonlOutput      bool
+)
 
-var daemonStartCmd = &cobra.Command{
-	Use:   "start",
-	Short: "Start the Chrome daemon",
-	Run: func(cmd *cobra.Command, _ []string) {
-		server := daemon.NewServer()
-		if err := server.Start(); err != nil {
-			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error starting daemon: %v\n", err)
-			os.Exit(1)
-		}
-		fmt.Println("Chrome daemon started")
+var batchCmd = &cobra.Command{
+	Use:   "batch [FILE or -]",
+	Short: "Distill a list of URLs or files",
+	Long: `Distill every URL or file path listed in FILE, or on stdin with -, one per
+line. Blank lines and lines starting with # are skipped.
 
-		// Keep the daemon running
-		select {}
-	},
-}
+Results go to stdout one after another, each preceded by an HTML comment
+naming its source, or to one file per target with --output-dir or an
+--output template such as '{{.Host}}/{{.Path}}.md'. A
+target that fails is reported on stderr and the run carries on; the command
+exits with status 1 if any target failed.
 
-var daemonStopCmd = &cobra.Command{
-	Use:   "stop",
-	Short: "Stop the Chrome daemon",
-	Run: func(cmd *cobra.Command, _ []string) {
-		client := daemon.NewDaemonClient()
-		if err := client.Shutdown(); err != nil {
-			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error stopping daemon: %v\n", err)
+--jsonl writes one JSON object per target instead, with its URL, title,
+markdown, metadata, when it was fetched and how long it took, or the error
+that stopped it: the usual shape for building a dataset.
+
+Targets are fetched in parallel, one per CPU by default (--concurrency) and at
+most two at once from the same host (--per-host). Results are written in list
+order, or as each finishes with --as-completed.
+
+Examples:
+  sz batch urls.txt
+  sz batch --output-dir archive urls.txt
+  sz batch -o 'archive/{{.Host}}/{{.Slug}}.md' --if-exists skip urls.txt
+  sz batch --jsonl urls.txt > corpus.jsonl
+  grep -o 'https://[^ ]*' notes.md | sz batch -`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		input := cmd.InOrStdin()
+		if args[0] != "-" {
+			file, err := os.Open(args[0])
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error reading target list: %v\n", err)
+				os.Exit(1)
+			}
+			defer func() { _ = file.Close() }()
+			input = file
+		}
+		targets, err := batch.ReadTargets(input)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error reading target list: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println("Chrome daemon stopped")
+		runBatch(cmd, targets, batchDistill())
 	},
 }
 
-var daemonStatusCmd = &cobra.Command{
-	Use:   "status",
-	Short: "Check daemon status",
-	Run: func(_ *cobra.Command, _ []string) {
-		if daemon.IsDaemonRunning() {
-			fmt.Println("Chrome daemon is running")
-		} else {
-			fmt.Println("Chrome daemon is not running")
+// runBatch distills targets as sz batch does, writing each result to stdout
+// or its output file, and exits with status 1 if any target failed. With
+// --jsonl, distill must return JSON lines, as distillRecord does, including
+// one recording the error when a target fails.
+func runBatch(cmd *cobra.Command, targets []string, distill batch.DistillFunc) {
+	total := len(targets)
+	if batchOutputDir != "" && outputTemplate != "" {
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Error: --output-dir and --output cannot be combined")
+		os.Exit(1)
+	}
+	if jsonlOutput {
+		if batchOutputDir != "" || outputTemplate != "" {
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Error: --jsonl writes to stdout and cannot be combined with --output-dir or --output")
+			os.Exit(exitUsage)
 		}
-	},
-}
-
-func init() {
-	// Add daemon subcommands
-	daemonCmd.AddCommand(daemonStartCmd)
-	daemonCmd.AddCommand(daemonStopCmd)
-	daemonCmd.AddCommand(daemonStatusCmd)
-
-	// Add flags to root command
-	rootCmd.Flags().BoolVar(&rawOutput, "raw", false, "Output raw HTML without reader view processing")
-	rootCmd.Flags().BoolVar(&waitForFrameworks, "wait-for-frameworks", false, "Enable framework-specific readiness detection (React, Vue, Next.js)")
-	rootCmd.Flags().StringVar(&domReadyTimeout, "dom-ready-timeout", "5s", "Timeout for DOM readiness detection")
-	rootCmd.Flags().StringVar(&waitForSelector, "wait-for-selector", "", "Wait for specific CSS selector to appear before extraction")
-	rootCmd.Flags().BoolVar(&debugReadiness, "debug-readiness", false, "Show detailed DOM readiness detection information")
-
-	// Text node tree flags
-	rootCmd.Flags().BoolVar(&textNodeTree, "text-node-tree", false, "Build hierarchical text node tree structure")
-	rootCmd.Flags().StringVar(&treeFormat, "tree-format", "text", "Output format for text node tree (text, json)")
-	rootCmd.Flags().BoolVar(&filterNavigation, "filter-navigation", false, "Filter out navigation elements from tree")
-	rootCmd.Flags().BoolVar(&preserveAttributes, "preserve-attributes", false, "Preserve element attributes in tree structure")
-
-	// Content filter flags
-	rootCmd.Flags().BoolVar(&contentFilter, "content-filter", false, "Apply sophisticated content filtering to remove non-content elements")
-	rootCmd.Flags().BoolVar(&aggressiveFiltering, "aggressive-filtering", false, "Enable more aggressive content filtering")
-	rootCmd.Flags().StringVar(&preserveSelector, "preserve-selector", "", "CSS selector to always preserve (can be used multiple times)")
-
-	// Media handler flags
-	rootCmd.Flags().BoolVar(&mediaHandler, "media-handler", false, "Replace media elements with descriptive text")
-	rootCmd.Flags().BoolVar(&includeDecorative, "include-decorative", false, "Include decorative images in media processing")
-
-	// Markdown renderer flags
-	rootCmd.Flags().BoolVar(&markdownRenderer, "markdown-renderer", false, "Convert content tree to clean, formatted markdown")
-	rootCmd.Flags().StringVar(&emphasisStyle, "emphasis-style", "asterisk", "Emphasis style: 'asterisk' (*) or 'underscore' (_)")
-	rootCmd.Flags().StringVar(&listStyle, "list-style", "dash", "List style: 'dash' (-), 'asterisk' (*), or 'plus' (+)")
-	// Add flags to fetch command
-	fetchCmd.Flags().BoolVarP(&readerView, "reader-view", "r", false, "Extract main content and convert to clean markdown")
-	fetchCmd.Flags().BoolVar(&waitForFrameworks, "wait-for-frameworks", false, "Enable framework-specific readiness detection (React, Vue, Next.js)")
-	fetchCmd.Flags().StringVar(&domReadyTimeout, "dom-ready-timeout", "5s", "Timeout for DOM readiness detection")
-	fetchCmd.Flags().StringVar(&waitForSelector, "wait-for-selector", "", "Wait for specific CSS selector to appear before extraction")
-	fetchCmd.Flags().BoolVar(&debugReadiness, "debug-readiness", false, "Show detailed DOM readiness detection information")
-
-	// Text node tree flags for fetch command
-	fetchCmd.Flags().BoolVar(&textNodeTree, "text-node-tree", false, "Build hierarchical text node tree structure")
-	fetchCmd.Flags().StringVar(&treeFormat, "tree-format", "text", "Output format for text node tree (text, json)")
-	fetchCmd.Flags().BoolVar(&filterNavigation, "filter-navigation", false, "Filter out navigation elements from tree")
-	fetchCmd.Flags().BoolVar(&preserveAttributes, "preserve-attributes", false, "Preserve element attributes in tree structure")
+		runCorpus(cmd, targets, distill)
+		return
+	}
+	pathTemplate := outputTemplate
+	if batchOutputDir != "" {
+		pathTemplate = filepath.Join(batchOutputDir, "{{.Slug}}.md")
+	}
+	policy, err := output.ParsePolicy(ifExists)
+	if err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+		os.Exit(1)
+	}
 
-	// Content filter flags for fetch command
-	fetchCmd.Flags().BoolVar(&contentFilter, "content-filter", false, "Apply sophisticated content filtering to remove non-content elements")
-	fetchCmd.Flags().BoolVar(&aggressiveFiltering, "aggressive-filtering", false, "Enable more aggressive content filtering")
-	fetchCmd.Flags().StringVar(&preserveSelector, "preserve-selector", "", "CSS selector to always preserve (can be used multiple times)")
+	// Work out every path up front so duplicates are numbered in list
+	// order, and targets whose file exists are not fetched at all
+	var paths []string
+	if pathTemplate != "" {
+		tmpl, err := output.ParseTemplate(pathTemplate)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+		var pending []string
+		for i, target := range targets {
+			path, err := tmpl.Path(output.NewFields(target, i+1))
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if policy == output.Skip && output.Exists(path) {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s: skipped, %s exists\n", target, path)
+				continue
+			}
+			pending = append(pending, target)
+			paths = append(paths, path)
+		}
+		targets = pending
+	}
+	skipped := total - len(targets)
 
-	// Media handler flags for fetch command
-	fetchCmd.Flags().BoolVar(&mediaHandler, "media-handler", false, "Replace media elements with descriptive text")
-	fetchCmd.Flags().BoolVar(&includeDecorative, "include-decorative", false, "Include decorative images in media processing")
+	options := batch.Options{Workers: batchConcurrency, PerHost: batchPerHost, Ordered: !batchAsCompleted}
+	failed := 0
+	batch.Run(cmd.Context(), targets, options, distill, func(result batch.Result) {
+		if result.Err != nil {
+			failed++
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %s: %v\n", result.Target, result.Err)
+			return
+		}
 
-	// Markdown renderer flags for fetch command
-	fetchCmd.Flags().BoolVar(&markdownRenderer, "markdown-renderer", false, "Convert content tree to clean, formatted markdown")
-	fetchCmd.Flags().StringVar(&emphasisStyle, "emphasis-style", "asterisk", "Emphasis style: 'asterisk' (*) or 'underscore' (_)")
-	fetchCmd.Flags().StringVar(&listStyle, "list-style", "dash", "List style: 'dash' (-), 'asterisk' (*), or 'plus' (+)")
-	// Add all commands to root
-	rootCmd.AddCommand(versionCmd)
-	rootCmd.AddCommand(fetchCmd)
-	rootCmd.AddCommand(daemonCmd)
-}
+		if paths == nil {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "<!-- source: %s -->\n\n%s\n", result.Target, strings.TrimRight(result.Content, "\n"))
+			return
+		}
+		path := paths[result.Index]
+		if err := output.Write(path, []byte(result.Content), policy); err != nil {
+			failed++
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %s: %v\n", result.Target, err)
+			return
+		}
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s -> %s\n", result.Target, path)
+	})
 
-// readFile reads the contents of a file and returns it as a string
-func readFile(filepath string) (string, error) {
-	file, err := os.Open(filepath)
-	if err != nil {
-		return "", err
+	summary := fmt.Sprintf("Distilled %d of %d targets", len(targets)-failed, total)
+	if skipped > 0 {
+		summary += fmt.Sprintf(", %d skipped", skipped)
 	}
-	defer func() { _ = file.Close() }()
-
-	content, err := io.ReadAll(file)
-	if err != nil {
-		return "", err
+	_, _ = fmt.Fprintln(cmd.ErrOrStderr(), summary)
+	if failed > 0 {
+		os.Exit(1)
 	}
-
-	return string(content), nil
 }
 
-// shouldUseChromeForFile determines if file processing should use Chrome
-func shouldUseChromeForFile() bool {
-	// Use Chrome for files if any DOM ready flags or text node tree flags are set
-	return waitForFrameworks || domReadyTimeout != "5s" || waitForSelector != "" || debugReadiness || textNodeTree
+// corpusRecord is one line of --jsonl output: a distilled page with its
+// metadata, or the error that stopped it.
+type corpusRecord struct {
+	URL        string             `json:"url"`
+	Title      string             `json:"title,omitempty"`
+	Markdown   string             `json:"markdown,omitempty"`
+	HTML       string             `json:"html,omitempty"` // With --raw, instead of Markdown
+	Metadata   *metadata.Metadata `json:"metadata,omitempty"`
+	FetchedAt  time.Time          `json:"fetched_at"`
+	DurationMS int64              `json:"duration_ms"`
+	Error      string             `json:"error,omitempty"`
 }
 
-// createReadinessChecker creates a ReadinessChecker based on CLI flags
-func createReadinessChecker() (*pageready.ReadinessChecker, error) {
-	// Only create checker if any DOM ready flags are set
-	if !waitForFrameworks && domReadyTimeout == "5s" && waitForSelector == "" && !debugReadiness {
-		return nil, nil // Use default behavior
+// encodeRecord formats a record as a JSON line.
+func encodeRecord(record corpusRecord) string {
+	var line bytes.Buffer
+	encoder := json.NewEncoder(&line)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(record); err != nil {
+		// Records hold only strings, numbers and times, which always encode
+		panic(err)
 	}
+	return line.String()
+}
 
-	checker := pageready.NewReadinessChecker()
-
-	// Parse timeout
-	if domReadyTimeout != "5s" {
-		timeout, err := time.ParseDuration(domReadyTimeout)
+// distillRecord fetches and distills a target as distillTarget does, and
+// returns it as a JSON line with the page's metadata. When the target fails
+// it returns the error together with a line recording it.
+func distillRecord(ctx context.Context, target string) (string, error) {
+	record := corpusRecord{URL: target, FetchedAt: time.Now().UTC()}
+	content, err := fetchTarget(ctx, target)
+	if err != nil {
+		return failedRecord(record, err)
+	}
+	record.Metadata = metadata.Extract(content, target)
+	record.Title = record.Metadata.Title
+	if rawOutput {
+		record.HTML = content
+	} else {
+		markdown, err := extractMarkdown(extractor.New().WithComments(withComments), target, content)
 		if err != nil {
-			return nil, fmt.Errorf("invalid timeout format: %w", err)
+			return failedRecord(record, fmt.Errorf("reader view extraction failed: %w", err))
+		}
+		if err := checkWordCount(markdown); err != nil {
+			return failedRecord(record, err)
+		}
+		record.Markdown = markdown
+		if record.Title == "" {
+			record.Title = firstHeading(markdown)
 		}
-		checker = checker.WithTimeout(timeout)
-	}
-
-	// Set framework hints
-	if waitForFrameworks {
-		// Enable common framework detection
-		checker = checker.WithFrameworkHints([]string{"react", "vue", "angular", "nextjs"})
 	}
+	record.DurationMS = time.Since(record.FetchedAt).Milliseconds()
+	return encodeRecord(record), nil
+}
 
-	// Set custom selectors
-	if waitForSelector != "" {
-		checker = checker.WithCustomSelectors([]string{waitForSelector})
+// failedRecord returns err with a JSON line recording it for the target of
+// record, timed from when the record's fetch started.
+func failedRecord(record corpusRecord, err error) (string, error) {
+	failed := corpusRecord{
+		URL:        record.URL,
+		FetchedAt:  record.FetchedAt,
+		DurationMS: time.Since(record.FetchedAt).Milliseconds(),
+		Error:      err.Error(),
 	}
-
-	// Set debug mode
-	checker = checker.WithDebug(debugReadiness)
-
-	return checker, nil
+	return encodeRecord(failed), err
 }
 
-// fetchURLWithChrome fetches content using Chrome browser automation
-func fetchURLWithChrome(ctx context.Context, url string) (string, error) {
-	client := browser.NewClient()
-	defer client.Shutdown()
-
-	// Configure DOM readiness if flags are set
-	checker, err := createReadinessChecker()
-	if err != nil {
-		return "", fmt.Errorf("failed to configure DOM readiness: %w", err)
+// batchDistill returns how sz batch and sz sitemap distill a target: to
+// markdown, or to a JSON line with --jsonl.
+func batchDistill() batch.DistillFunc {
+	if jsonlOutput {
+		return distillRecord
 	}
+	return distillTarget
+}
 
-	if checker != nil {
-		client = client.WithReadinessChecker(checker)
-	}
+// runCorpus distills targets into JSON lines on stdout, writing a record with
+// the error for each target that fails, and exits with status 1 if any did.
+func runCorpus(cmd *cobra.Command, targets []string, distill batch.DistillFunc) {
+	options := batch.Options{Workers: batchConcurrency, PerHost: batchPerHost, Ordered: !batchAsCompleted}
+	failed := 0
+	batch.Run(cmd.Context(), targets, options, distill, func(result batch.Result) {
+		if result.Err != nil {
+			failed++
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %s: %v\n", result.Target, result.Err)
+			_, _ = fmt.Fprint(cmd.OutOrStdout(), result.Content)
+			return
+		}
+		_, _ = fmt.Fprint(cmd.OutOrStdout(), result.Content)
+	})
 
-	content, err := client.FetchContent(ctx, url)
-	if err != nil {
-		// Fallback to simple HTTP fetch if Chrome fails
-		return fetchURL(url)
+	_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Distilled %d of %d targets\n", len(targets)-failed, len(targets))
+	if failed > 0 {
+		os.Exit(1)
 	}
-
-	return content, nil
 }
 
-// fetchURL fetches content from an HTTP or HTTPS URL (fallback method)
-func fetchURL(url string) (string, error) {
-	// Create HTTP client with reasonable timeout and TLS config for tests
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true, // For test servers with self-signed certs
-			},
-		},
-	}
+// Sitemap command flags
+var (
+	sitemapInclude []string
+	sitemapExclude []string
+	sitemapSince   string
+	sitemapUntil   string
+	sitemapLimit   int
+	sitemapList    bool
+)
 
-	resp, err := client.Get(url)
-	if err != nil {
-		return "", err
-	}
-	defer func() { _ = resp.Body.Close() }()
+var sitemapCmd = &cobra.Command{
+	Use:   "sitemap URL",
+	Short: "Distill the pages listed in a sitemap",
+	Long: `Read an XML sitemap, from a URL or a file, and distill every page it lists
+as sz batch would. Sitemap indexes are followed to the sitemaps they list,
+and gzipped sitemaps are decompressed.
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-	}
+--include and --exclude take regular expressions matched against each page's
+URL. --since and --until keep pages whose <lastmod> falls in the range, and
+drop pages without one. --list prints the selected URLs instead of
+distilling them, for use with other tools or a later sz batch.
 
-	content, err := io.ReadAll(resp.Body)
-	if err != nil {
+All of the sz batch output and concurrency flags apply.
+
+Examples:
+  sz sitemap --output-dir site https://example.com/sitemap.xml
+  sz sitemap --include '/blog/' --since 2024-01-01 -o 'blog/{{.Path}}.md' https://example.com/sitemap.xml
+  sz sitemap --list https://example.com/sitemap_index.xml > urls.txt`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var filter sitemap.Filter
+		for _, pattern := range sitemapInclude {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: invalid --include: %v\n", err)
+				os.Exit(exitUsage)
+			}
+			filter.Include = append(filter.Include, re)
+		}
+		for _, pattern := range sitemapExclude {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: invalid --exclude: %v\n", err)
+				os.Exit(exitUsage)
+			}
+			filter.Exclude = append(filter.Exclude, re)
+		}
+		var err error
+		if sitemapSince != "" {
+			if filter.Since, err = sitemap.ParseDate(sitemapSince); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: --since: %v\n", err)
+				os.Exit(exitUsage)
+			}
+		}
+		if sitemapUntil != "" {
+			if filter.Until, err = sitemap.ParseDate(sitemapUntil); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: --until: %v\n", err)
+				os.Exit(exitUsage)
+			}
+		}
+
+		urls, err := sitemap.Collect(cmd.Context(), args[0], readDocument, func(location string, err error) {
+			slog.Warn("skipping sitemap", "sitemap", location, "error", err)
+		})
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error reading sitemap: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		var targets []string
+		for _, u := range urls {
+			if sitemapLimit > 0 && len(targets) >= sitemapLimit {
+				break
+			}
+			if filter.Match(u) {
+				targets = append(targets, u.Loc)
+			}
+		}
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Selected %d of %d URLs from %s\n", len(targets), len(urls), args[0])
+
+		if sitemapList {
+			for _, target := range targets {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), target)
+			}
+			return
+		}
+		runBatch(cmd, targets, batchDistill())
+	},
+}
+
+// Feed command flags
+var (
+	feedLimit    int
+	feedSince    string
+	feedFromFeed bool
+)
+
+var feedCmd = &cobra.Command{
+	Use:   "feed URL",
+	Short: "Distill the articles in an RSS, Atom or JSON feed",
+	Long: `Read an RSS, Atom or JSON Feed, from a URL or a file, fetch the page each
+entry links to, and distill it. The result is one markdown digest on stdout,
+with a linked heading and date for each article, or one file per article with
+--output-dir or an --output template as in sz batch.
+
+--from-feed distills the content the feed itself carries instead of fetching
+each page, which is faster and works for feeds that include full articles.
+--limit and --since pick the newest entries.
+
+Examples:
+  sz feed https://example.com/feed.xml > digest.md
+  sz feed --limit 5 --since 2024-06-01 https://blog.example.com/atom.xml
+  sz feed --output-dir articles --if-exists skip https://example.com/feed.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var since time.Time
+		if feedSince != "" {
+			var err error
+			if since, err = time.Parse(time.DateOnly, feedSince); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: invalid --since %q: use YYYY-MM-DD\n", feedSince)
+				os.Exit(exitUsage)
+			}
+		}
+
+		data, err := readDocument(cmd.Context(), args[0])
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error reading feed: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		parsed, err := feed.Parse(data)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error reading feed: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Entries are keyed by link, which also names their output files
+		items := make(map[string]feed.Item)
+		var targets []string
+		for _, item := range parsed.Items {
+			if feedLimit > 0 && len(targets) >= feedLimit {
+				break
+			}
+			if !since.IsZero() && item.Published.Before(since) {
+				continue
+			}
+			if item.Link == "" {
+				slog.Warn("skipping feed entry without a link", "title", item.Title)
+				continue
+			}
+			if _, ok := items[item.Link]; ok {
+				continue
+			}
+			items[item.Link] = item
+			targets = append(targets, item.Link)
+		}
+
+		distill := batchDistill()
+		if feedFromFeed {
+			distill = func(_ context.Context, link string) (string, error) {
+				if !jsonlOutput {
+					return distillFeedItem(items[link])
+				}
+				item := items[link]
+				record := corpusRecord{URL: link, Title: item.Title, FetchedAt: time.Now().UTC()}
+				markdown, err := distillFeedItem(item)
+				if err != nil {
+					return failedRecord(record, err)
+				}
+				record.Markdown = markdown
+				record.DurationMS = time.Since(record.FetchedAt).Milliseconds()
+				return encodeRecord(record), nil
+			}
+		}
+
+		if outputTemplate != "" || batchOutputDir != "" || jsonlOutput {
+			runBatch(cmd, targets, distill)
+			return
+		}
+
+		var articles []feed.Article
+		failed := 0
+		options := batch.Options{Workers: batchConcurrency, PerHost: batchPerHost, Ordered: true}
+		batch.Run(cmd.Context(), targets, options, distill, func(result batch.Result) {
+			if result.Err != nil {
+				failed++
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %s: %v\n", result.Target, result.Err)
+				return
+			}
+			articles = append(articles, feed.Article{Item: items[result.Target], Markdown: result.Content})
+		})
+		_, _ = fmt.Fprint(cmd.OutOrStdout(), feed.Digest(parsed.Title, articles))
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Distilled %d of %d articles\n", len(articles), len(targets))
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// distillFeedItem distills the content a feed carries for an entry.
+func distillFeedItem(item feed.Item) (string, error) {
+	if item.Content == "" {
+		return "", fmt.Errorf("feed has no content for this entry")
+	}
+	markdown, err := extractor.New().ExtractContent("<html><body><article>" + item.Content + "</article></body></html>")
+	if err != nil {
+		return "", fmt.Errorf("reader view extraction failed: %w", err)
+	}
+	if err := checkWordCount(markdown); err != nil {
+		return "", err
+	}
+	return markdown, nil
+}
+
+// maxDocumentSize caps what readDocument reads, the most a sitemap may hold.
+const maxDocumentSize = sitemap.MaxSize
+
+// newRequest returns a request for url with the request headers, language
+// preference and user agent given on the command line.
+func newRequest(ctx context.Context, method, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := session.ParseHeaders(requestHeaders)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	auth, err := session.ParseBasicAuth(basicAuth)
+	if err != nil {
+		return nil, err
+	}
+	if auth != nil {
+		req.SetBasicAuth(auth.Username, auth.Password)
+	}
+	tags, err := emulate.ParseLanguages(languages)
+	if err != nil {
+		return nil, err
+	}
+	if language := emulate.AcceptLanguage(tags); language != "" && req.Header.Get("Accept-Language") == "" {
+		req.Header.Set("Accept-Language", language)
+	}
+	if agent := session.ResolveUserAgent(userAgent); agent != "" {
+		req.Header.Set("User-Agent", agent)
+	}
+	return req, nil
+}
+
+// pageUnchanged asks the server whether a page is still the version last
+// fetched, sending the cookies and request headers a fetch would. A page
+// without validators counts as changed.
+func pageUnchanged(ctx context.Context, url string) (bool, error) {
+	validators := pageValidators[url]
+	if validators == nil {
+		return false, nil
+	}
+	req, err := newRequest(ctx, http.MethodHead, url)
+	if err != nil {
+		return false, err
+	}
+	var cookies []session.Cookie
+	if cookiesFile != "" {
+		if cookies, err = session.LoadCookies(cookiesFile); err != nil {
+			return false, err
+		}
+	}
+	jar, err := session.NewJar(cookies)
+	if err != nil {
+		return false, err
+	}
+	roundTripper, err := httpTransport()
+	if err != nil {
+		return false, err
+	}
+	client := &http.Client{Timeout: 30 * time.Second, Jar: jar, Transport: roundTripper}
+	return revalidate.Unchanged(client, req, validators)
+}
+
+// readDocument loads a sitemap or feed from a URL, with the request headers
+// and user agent given on the command line, or from a file.
+func readDocument(ctx context.Context, location string) ([]byte, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		return os.ReadFile(location)
+	}
+
+	req, err := newRequest(ctx, http.MethodGet, location)
+	if err != nil {
+		return nil, err
+	}
+	roundTripper, err := httpTransport()
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: roundTripper}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &httpStatusError{status: resp.Status, code: resp.StatusCode}
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize))
+}
+
+// captureOutput holds the command's output back until it is done, when it
+// goes to the --output file or, after the --fail-on-empty check, to stdout.
+// It returns the function that releases the output, and reports false when
+// the file exists and --if-exists is skip. Output is only released on
+// success, so a failed fetch leaves no partial file.
+func captureOutput(cmd *cobra.Command, target string) (func(), bool) {
+	if chunkSize < 0 || chunkOverlap < 0 || (cmd.Flags().Changed("chunk-overlap") && chunkOverlap >= chunkSize) {
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Error: --chunk-size must be positive and larger than --chunk-overlap")
+		os.Exit(exitUsage)
+	}
+	switch {
+	case outputFormat != "markdown" && outputFormat != "llm":
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: invalid --format %q: use markdown or llm\n", outputFormat)
+		os.Exit(exitUsage)
+	case outputFormat == "llm" && rawOutput:
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Error: --format llm cannot be combined with --raw")
+		os.Exit(exitUsage)
+	case flattenHeadings && outputFormat != "llm":
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Error: --flatten-headings needs --format llm")
+		os.Exit(exitUsage)
+	case splitBy != "" && splitBy != "h1" && splitBy != "h2":
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: invalid --split-by %q: use h1 or h2\n", splitBy)
+		os.Exit(exitUsage)
+	case splitBy != "" && (rawOutput || outputTemplate != "" || chunkSize > 0 || exportTo != ""):
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Error: --split-by cannot be combined with --raw, --output, --chunk-size or --export")
+		os.Exit(exitUsage)
+	case splitDir != "" && splitBy == "":
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Error: --output-dir needs --split-by")
+		os.Exit(exitUsage)
+	}
+	var exporter *export.Obsidian
+	if exportTo != "" || vaultDir != "" {
+		exporter = newExporter(cmd)
+	}
+	if outputTemplate == "" && !failOnEmpty && !showSummary && translateTo == "" && chunkSize == 0 && countTokens == "" && outputFormat == "markdown" && exporter == nil && splitBy == "" {
+		return func() {}, true
+	}
+
+	var encoding *tokens.Encoding
+	if countTokens != "" {
+		e, err := tokens.ForModel(countTokens)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: invalid --count-tokens: %v\n", err)
+			os.Exit(exitUsage)
+		}
+		encoding = &e
+	}
+
+	var summarizer *llm.Client
+	if showSummary {
+		if rawOutput {
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Error: --summary cannot be combined with --raw")
+			os.Exit(exitUsage)
+		}
+		summarizer = newLLMClient(cmd)
+	}
+	var translator translate.Translator
+	if translateTo != "" {
+		translator = newTranslator(cmd)
+	}
+
+	var path string
+	if outputTemplate != "" {
+		policy, err := output.ParsePolicy(ifExists)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+		tmpl, err := output.ParseTemplate(outputTemplate)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+		path, err = tmpl.Path(output.NewFields(target, 1))
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if output.Exists(path) {
+			switch policy {
+			case output.Skip:
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Skipped %s: %s exists\n", target, path)
+				return nil, false
+			case output.Fail:
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error writing output: %s already exists\n", path)
+				os.Exit(1)
+			}
+		}
+	}
+
+	stdout := cmd.OutOrStdout()
+	var buffer bytes.Buffer
+	cmd.SetOut(&buffer)
+	return func() {
+		if err := checkWordCount(buffer.String()); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		if summarizer != nil {
+			summary, err := summarize(cmd.Context(), summarizer, target, buffer.String())
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error summarizing: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			content := buffer.String()
+			buffer.Reset()
+			buffer.WriteString(llm.SummarySection(summary))
+			buffer.WriteString(content)
+		}
+		if translator != nil {
+			translated, err := translate.Markdown(cmd.Context(), translator, buffer.String(), translateTo)
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			buffer.Reset()
+			buffer.WriteString(translated)
+		}
+		if outputFormat == "llm" {
+			content := compact.Markdown(buffer.String(), flattenHeadings)
+			buffer.Reset()
+			buffer.WriteString(content)
+		}
+		if encoding != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Tokens:        %d (%s, %s, estimated)\n", encoding.Count(buffer.String()), countTokens, encoding.Name)
+		}
+		if chunkSize > 0 {
+			chunks := chunkContent(target, buffer.String())
+			if encoding != nil {
+				for i := range chunks {
+					chunks[i].Tokens = encoding.Count(chunks[i].Content)
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "  chunk %-6d %d\n", i, chunks[i].Tokens)
+				}
+			}
+			buffer.Reset()
+			if err := writeChunks(&buffer, chunks); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				os.Exit(exitError)
+			}
+		}
+		if splitBy != "" {
+			writeSections(cmd, buffer.String())
+			return
+		}
+		if exporter != nil {
+			note, err := exporter.Save(cmd.Context(), exportNote(target, buffer.String()))
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error exporting: %v\n", err)
+				os.Exit(exitError)
+			}
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s -> %s\n", target, note)
+			return
+		}
+		if path == "" {
+			_, _ = stdout.Write(buffer.Bytes())
+			return
+		}
+		if err := output.Write(path, buffer.Bytes(), output.Overwrite); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	}, true
+}
+
+// writeSections writes each top-level section of content to its own
+// numbered file in --output-dir, as --split-by asks, listing the files on
+// stderr.
+func writeSections(cmd *cobra.Command, content string) {
+	policy, err := output.ParsePolicy(ifExists)
+	if err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+		os.Exit(1)
+	}
+	dir := splitDir
+	if dir == "" {
+		dir = "."
+	}
+
+	sections := chunk.Sections(content, int(splitBy[1]-'0'))
+	if len(sections) == 0 {
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Error: nothing to split, the output is empty")
+		os.Exit(1)
+	}
+	for i, section := range sections {
+		path := output.SectionPath(dir, i+1, len(sections), section.Title)
+		if policy == output.Skip && output.Exists(path) {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Skipped %s: %s exists\n", section.Title, path)
+			continue
+		}
+		if err := output.Write(path, []byte(section.Content), policy); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), path)
+	}
+}
+
+// newExporter checks the --export flags and creates the exporter they ask
+// for, exiting with status 2 when they are wrong.
+func newExporter(cmd *cobra.Command) *export.Obsidian {
+	var problem string
+	switch {
+	case exportTo == "":
+		problem = "--vault needs --export obsidian"
+	case exportTo != "obsidian":
+		problem = fmt.Sprintf("invalid --export %q: use obsidian", exportTo)
+	case vaultDir == "":
+		problem = "--export obsidian needs --vault"
+	case rawOutput || outputTemplate != "" || chunkSize > 0:
+		problem = "--export cannot be combined with --raw, --output or --chunk-size"
+	}
+	if problem != "" {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %s\n", problem)
+		os.Exit(exitUsage)
+	}
+	dir, err := config.ExpandHome(vaultDir)
+	if err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+	return export.NewObsidian(dir)
+}
+
+// exportNote describes distilled content for the exporter, with the
+// metadata and images of the page it came from.
+func exportNote(target, content string) export.Note {
+	note := export.Note{Source: target, Markdown: content, Clipped: time.Now()}
+	if exportSource == "" {
+		return note
+	}
+	note.Meta = metadata.Extract(exportSource, target)
+	images, err := extractor.New().ContentImages(exportSource)
+	if err != nil {
+		slog.Warn("could not find the page's images", "error", err)
+	}
+	note.Images = images
+	return note
+}
+
+// emptyError reports output below --min-words with --fail-on-empty.
+type emptyError struct {
+	words int
+}
+
+// Error says how much was extracted.
+func (e *emptyError) Error() string {
+	return fmt.Sprintf("extracted %d words, fewer than --min-words %d", e.words, minWords)
+}
+
+// checkWordCount fails with --fail-on-empty when content has fewer than
+// --min-words words, which usually means extraction silently missed the page.
+func checkWordCount(content string) error {
+	if !failOnEmpty {
+		return nil
+	}
+	if words := len(strings.Fields(content)); words < minWords {
+		return &emptyError{words: words}
+	}
+	return nil
+}
+
+// distillTarget fetches one URL or file and extracts its reader view, or
+// keeps the raw HTML with --raw. Unlike the root command it returns errors
+// so a batch can carry on past them.
+func distillTarget(ctx context.Context, target string) (string, error) {
+	content, err := fetchTarget(ctx, target)
+	if err != nil {
+		return "", err
+	}
+	if !rawOutput {
+		content, err = extractMarkdown(extractor.New().WithComments(withComments), target, content)
+		if err != nil {
+			return "", fmt.Errorf("reader view extraction failed: %w", err)
+		}
+	}
+	if err := checkWordCount(content); err != nil {
+		return "", err
+	}
+	return content, nil
+}
+
+// extractMarkdown extracts the reader view of fetched content as markdown. A
+// source that is already markdown or plain text, by its file extension or
+// because it is not HTML, skips extraction and is normalized instead, and a
+// JSON or XML document is pretty-printed, or outlined with --data-outline.
+func extractMarkdown(ext *extractor.Extractor, target, content string) (string, error) {
+	source := content
+	text, isText := textdoc.Detect(content)
+	if isText {
+		// Chrome wraps text responses in a page of its own
+		source = text
+	}
+	if kind := dataview.Detect(source); kind != "" {
+		if dataOutline {
+			return dataview.Outline(kind, source)
+		}
+		return dataview.Pretty(kind, source), nil
+	}
+	isURL := strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://")
+	if isText || (!isURL && target != "-" && textdoc.IsTextFile(target)) {
+		return textdoc.Normalize(source), nil
+	}
+	return ext.ExtractContent(content)
+}
+
+// Meta command flags
+var metaChrome bool
+
+var metaCmd = &cobra.Command{
+	Use:   "meta [URL or file]",
+	Short: "Print a page's metadata as JSON",
+	Long: `Print the title, description, author, dates, canonical URL, language, lead
+image, site name and advertised feeds of a page as JSON, without extracting
+its content.
+
+URLs are fetched over plain HTTP, which is much faster than rendering the
+page. Only when the HTML has neither a title nor a description, as with pages
+built by script, is the page rendered in Chrome; --chrome always renders it.
+Use - to read HTML from stdin.
+
+Examples:
+  sz meta https://example.com/article
+  sz meta https://example.com/article | jq -r .canonical
+  sz meta --chrome https://app.example.com/dashboard`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		meta := pageMetadata(cmd, args[0])
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(meta); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// pageMetadata reads the metadata of a page for sz meta and sz cite: over
+// plain HTTP unless --chrome is given or the HTML has neither a title nor a
+// description. It exits when the page cannot be fetched.
+func pageMetadata(cmd *cobra.Command, target string) *metadata.Metadata {
+	isURL := strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://")
+
+	var content string
+	var err error
+	switch {
+	case target == "-":
+		content, err = readStdin(cmd)
+	case isURL && !metaChrome:
+		content, err = fetchPlain(target)
+	default:
+		content, err = fetchTarget(cmd.Context(), target)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error fetching %s: %v\n", target, err)
+		os.Exit(exitCode(err))
+	}
+
+	meta := metadata.Extract(content, target)
+	if meta.Empty() && isURL && !metaChrome {
+		slog.Info("page HTML has no title or description, rendering it in Chrome", "url", target)
+		if content, err = fetchTarget(cmd.Context(), target); err == nil {
+			meta = metadata.Extract(content, target)
+		}
+	}
+	return meta
+}
+
+// Cite command flags
+var (
+	citeStyle    string
+	citeAccessed string
+)
+
+var citeCmd = &cobra.Command{
+	Use:   "cite [URL or file]",
+	Short: "Print a citation for a page",
+	Long: `Print a citation for a page in APA, MLA or BibTeX style, built from its
+title, author, publication date, site name and canonical URL, with today as
+the access date. Metadata is read as sz meta reads it.
+
+Pages rarely give every field; check the result against the page before
+publishing it.
+
+Examples:
+  sz cite https://example.com/article
+  sz cite --style mla https://example.com/article
+  sz cite --style bibtex https://example.com/article >> sources.bib`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !slices.Contains(cite.Styles, citeStyle) {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: invalid --style %q: use %s\n", citeStyle, strings.Join(cite.Styles, ", "))
+			os.Exit(exitUsage)
+		}
+		accessed := time.Now()
+		if citeAccessed != "" {
+			var err error
+			if accessed, err = time.Parse(time.DateOnly, citeAccessed); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: invalid --accessed %q: use YYYY-MM-DD\n", citeAccessed)
+				os.Exit(exitUsage)
+			}
+		}
+
+		meta := pageMetadata(cmd, args[0])
+		citation, err := cite.Format(cite.FromMetadata(meta, accessed), citeStyle)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), citation)
+	},
+}
+
+// mergeTitle is the heading of the document sz merge writes.
+var mergeTitle string
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge URL...",
+	Short: "Merge several pages into one markdown document",
+	Long: `Distill several URLs or files and merge them into one markdown document: a
+section per page, in the order given, with the page's headings moved below
+the section's, a line naming its source, author and date, and a table of
+contents up front. Handy for a daily reading digest.
+
+Pages are fetched in parallel as sz batch fetches them. A page that fails is
+reported on stderr and left out; the command then exits with status 1.
+
+Examples:
+  sz merge https://example.com/a https://example.org/b -o digest.md
+  sz merge --title "Monday reading" $(cat today.txt) > digest.md`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		writeOutput, ok := captureOutput(cmd, args[0])
+		if !ok {
+			return
+		}
+		title := mergeTitle
+		if title == "" {
+			title = "Digest " + time.Now().Format(time.DateOnly)
+		}
+
+		// distillRecord keeps the metadata each section's attribution needs
+		sources := make([]merge.Source, len(args))
+		distilled := make([]bool, len(args))
+		failed := 0
+		options := batch.Options{Workers: batchConcurrency, PerHost: batchPerHost, Ordered: true}
+		batch.Run(cmd.Context(), args, options, distillRecord, func(result batch.Result) {
+			var record corpusRecord
+			err := result.Err
+			if err == nil {
+				err = json.Unmarshal([]byte(result.Content), &record)
+			}
+			if err != nil {
+				failed++
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %s: %v\n", result.Target, err)
+				return
+			}
+			sources[result.Index] = mergeSource(record)
+			distilled[result.Index] = true
+		})
+		if failed == len(args) {
+			os.Exit(exitError)
+		}
+
+		var merged []merge.Source
+		for i, source := range sources {
+			if distilled[i] {
+				merged = append(merged, source)
+			}
+		}
+		_, _ = fmt.Fprint(cmd.OutOrStdout(), merge.Document(title, merged))
+		writeOutput()
+		if failed > 0 {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Merged %d of %d pages\n", len(merged), len(args))
+			os.Exit(exitError)
+		}
+	},
+}
+
+// mergeSource describes a distilled page for sz merge, naming it after its
+// first heading, which the page's title often pads with the site's name.
+func mergeSource(record corpusRecord) merge.Source {
+	source := merge.Source{URL: record.URL, Title: firstHeading(record.Markdown), Markdown: record.Markdown}
+	if source.Title == "" {
+		source.Title = record.Title
+	}
+	if meta := record.Metadata; meta != nil {
+		if meta.Canonical != "" {
+			source.URL = meta.Canonical
+		}
+		source.Author = meta.Author
+		source.SiteName = meta.SiteName
+		source.Published = meta.Published
+	}
+	return source
+}
+
+// fetchPlain fetches a URL over HTTP without Chrome, sending the headers,
+// cookies and user agent given on the command line.
+func fetchPlain(target string) (string, error) {
+	headers, err := session.ParseHeaders(requestHeaders)
+	if err != nil {
+		return "", err
+	}
+	auth, err := session.ParseBasicAuth(basicAuth)
+	if err != nil {
+		return "", err
+	}
+	var cookies []session.Cookie
+	if cookiesFile != "" {
+		if cookies, err = session.LoadCookies(cookiesFile); err != nil {
+			return "", err
+		}
+	}
+	policy, err := fetchRetryPolicy()
+	if err != nil {
+		return "", err
+	}
+	content, _, err := fetchURL(target, httpOptions{
+		cookies:   cookies,
+		headers:   headers,
+		auth:      auth,
+		userAgent: session.ResolveUserAgent(userAgent),
+		retry:     policy,
+	})
+	return content, err
+}
+
+// Tables command flags
+var (
+	tablesFormat    string
+	tablesIndex     int
+	tablesOutputDir string
+)
+
+var tablesCmd = &cobra.Command{
+	Use:   "tables [URL or file]",
+	Short: "Extract the data tables from a page as CSV, TSV or JSON",
+	Long: `Extract the data tables in a page's main content. Tables used only for
+layout are skipped, and cells spanning several rows or columns are repeated
+in each position they cover so every row lines up.
+
+With one table, or --table N to pick one, the output is that table. With
+several, CSV and TSV rows are prefixed with the table's number so they can
+be told apart in one stream, and JSON is a list of tables. --output-dir
+writes each table to its own file, table-1.csv and so on.
+
+Examples:
+  sz tables https://en.wikipedia.org/wiki/List_of_sovereign_states
+  sz tables --table 2 --format tsv https://example.com/pricing
+  sz tables --format json --output-dir data https://example.com/reference`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+		switch tablesFormat {
+		case "csv", "tsv", "json":
+		default:
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: invalid --format %q: use csv, tsv, or json\n", tablesFormat)
+			os.Exit(exitUsage)
+		}
+
+		var content string
+		var err error
+		if target == "-" {
+			content, err = readStdin(cmd)
+		} else {
+			content, err = fetchTarget(cmd.Context(), target)
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error fetching %s: %v\n", target, err)
+			os.Exit(exitCode(err))
+		}
+
+		tables, err := extractor.New().ContentTables(content)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(tables) == 0 {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: no data tables found in %s\n", target)
+			os.Exit(exitEmpty)
+		}
+		if tablesIndex > 0 {
+			if tablesIndex > len(tables) {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: --table %d: the page has %d tables\n", tablesIndex, len(tables))
+				os.Exit(exitUsage)
+			}
+			tables = tables[tablesIndex-1 : tablesIndex]
+		}
+
+		if tablesOutputDir != "" {
+			for i, table := range tables {
+				number := i + 1
+				if tablesIndex > 0 {
+					number = tablesIndex
+				}
+				var buffer bytes.Buffer
+				if err := writeTables(&buffer, []extractor.Table{table}, tablesFormat, false); err != nil {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+					os.Exit(1)
+				}
+				path := filepath.Join(tablesOutputDir, fmt.Sprintf("table-%d.%s", number, tablesFormat))
+				if err := output.Write(path, buffer.Bytes(), output.Overwrite); err != nil {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+					os.Exit(1)
+				}
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Table %d -> %s\n", number, path)
+			}
+			return
+		}
+
+		if err := writeTables(cmd.OutOrStdout(), tables, tablesFormat, len(tables) > 1); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// writeTables writes tables as CSV, TSV or JSON. With indexed, CSV and TSV
+// rows start with the 1-based number of their table, and JSON is always a
+// list.
+func writeTables(w io.Writer, tables []extractor.Table, format string, indexed bool) error {
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if !indexed && len(tables) == 1 {
+			return encoder.Encode(tables[0])
+		}
+		return encoder.Encode(tables)
+	}
+
+	writer := csv.NewWriter(w)
+	if format == "tsv" {
+		writer.Comma = '\t'
+	}
+	for i, table := range tables {
+		rows := table.Rows
+		if table.Header != nil {
+			rows = append([][]string{table.Header}, rows...)
+		}
+		for _, row := range rows {
+			if indexed {
+				row = append([]string{strconv.Itoa(i + 1)}, row...)
+			}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Images command flags
+var imagesFormat string
+
+var imagesCmd = &cobra.Command{
+	Use:   "images [URL or file]",
+	Short: "List the images in a page's main content",
+	Long: `List the images in a page's main content with their URL, alt text,
+dimensions and caption, taken from the enclosing <figure> or the image's
+title. Navigation, ads and tracking pixels are left out, and lazily loaded
+images are listed by their real source.
+
+The text format prints one image per line with tab-separated URL,
+dimensions, alt text and caption, so "cut -f1" gives the URLs to download.
+--format json prints a list of objects.
+
+Examples:
+  sz images https://example.com/article
+  sz images https://example.com/article | cut -f1 | xargs -n1 curl -O
+  sz images --format json https://example.com/gallery`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+		if imagesFormat != "text" && imagesFormat != "json" {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: invalid --format %q: use text or json\n", imagesFormat)
+			os.Exit(exitUsage)
+		}
+
+		var content string
+		var err error
+		if target == "-" {
+			content, err = readStdin(cmd)
+		} else {
+			content, err = fetchTarget(cmd.Context(), target)
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error fetching %s: %v\n", target, err)
+			os.Exit(exitCode(err))
+		}
+
+		images, err := extractor.New().ContentImages(content)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if base, err := url.Parse(target); err == nil && base.Host != "" {
+			for i := range images {
+				if resolved, err := base.Parse(images[i].URL); err == nil {
+					images[i].URL = resolved.String()
+				}
+			}
+		}
+
+		if imagesFormat == "json" {
+			if images == nil {
+				images = []extractor.Image{}
+			}
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			encoder.SetEscapeHTML(false)
+			if err := encoder.Encode(images); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+		for _, image := range images {
+			size := ""
+			if image.Width > 0 || image.Height > 0 {
+				size = fmt.Sprintf("%dx%d", image.Width, image.Height)
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\t%s\n", image.URL, size, image.Alt, image.Caption)
+		}
+	},
+}
+
+// Select command flags
+var (
+	selectSelector string
+	selectFirst    bool
+)
+
+var selectCmd = &cobra.Command{
+	Use:   "select [URL or file]",
+	Short: "Render only the elements matching a CSS selector",
+	Long: `Fetch a page as usual, then render only the elements matching --selector
+to markdown, skipping the reader view's guess at where the main content is.
+Use it when you already know where the content lives, such as a changelog
+section or a documentation body the heuristics miss.
+
+Every matching element is rendered, in document order; an element inside
+another match is rendered once, as part of it. --first renders only the first
+match. For URLs, the fetch waits for the selector to appear unless
+--wait-for-selector names something else.
+
+Examples:
+  sz select --selector '.changelog' https://example.com/releases
+  sz select --selector 'main article, #content' page.html
+  sz select --first --selector 'section.release' https://example.com/releases`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+		selector, err := filter.ParseSelector(selectSelector)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: --selector: %v\n", err)
+			os.Exit(exitUsage)
+		}
+
+		var content string
+		if target == "-" {
+			content, err = readStdin(cmd)
+		} else {
+			if waitForSelector == "" {
+				waitForSelector = selectSelector
+			}
+			content, err = fetchTarget(cmd.Context(), target)
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error fetching %s: %v\n", target, err)
+			os.Exit(exitCode(err))
+		}
+
+		root, err := tree.NewTreeBuilder().
+			WithFilterNavigation(false).
+			WithPreserveAttributes(true).
+			BuildTree(cmd.Context(), content)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error building text node tree: %v\n", err)
+			os.Exit(1)
+		}
+
+		matches := selectNodes(root, selector)
+		if len(matches) == 0 {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: no elements match %q\n", selectSelector)
+			os.Exit(exitEmpty)
+		}
+		if selectFirst {
+			matches = matches[:1]
+		}
+
+		renderer := markdown.NewTreeRenderer().
+			WithEmphasisStyle(emphasisStyle).
+			WithListStyle(listStyle)
+		var sections []string
+		for _, match := range matches {
+			section, err := renderer.RenderTree(cmd.Context(), match)
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error rendering markdown: %v\n", err)
+				os.Exit(1)
+			}
+			if section = strings.TrimSpace(section); section != "" {
+				sections = append(sections, section)
+			}
+		}
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), strings.Join(sections, "\n\n"))
+	},
+}
+
+// selectNodes returns the outermost nodes matching selector, in document order.
+func selectNodes(node *tree.TextNode, selector *filter.Selector) []*tree.TextNode {
+	if selector.Matches(node) {
+		return []*tree.TextNode{node}
+	}
+	var matches []*tree.TextNode
+	for _, child := range node.Children {
+		matches = append(matches, selectNodes(child, selector)...)
+	}
+	return matches
+}
+
+// Grep command flags
+var (
+	grepIgnoreCase bool
+	grepFixed      bool
+	grepCount      bool
+	grepListOnly   bool
+)
+
+var grepCmd = &cobra.Command{
+	Use:   "grep PATTERN URL...",
+	Short: "Search the distilled text of pages",
+	Long: `Distill each URL or file and print the lines of its content matching
+PATTERN, a regular expression (Go syntax), under the headings they fall in.
+Because the search runs on the reader view, markup, scripts, navigation and
+other page furniture never match. Markdown snapshots (.md, .markdown or .txt)
+are searched as they are.
+
+Each match is printed with its line number in the distilled markdown, below
+the path of headings it sits under. With more than one target, matches are
+grouped under the target they came from.
+
+Like grep, sz grep exits 0 when something matched and 1 when nothing did. A
+target that fails to load is reported and the rest are still searched, but
+the exit status then reflects the failure.
+
+Examples:
+  sz grep 'rate limit' https://example.com/docs/api
+  sz grep -i 'breaking change' https://example.com/releases notes.md
+  sz grep -l -F 'go install' https://example.com/a https://example.com/b`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		source := args[0]
+		if grepFixed {
+			source = regexp.QuoteMeta(source)
+		}
+		if grepIgnoreCase {
+			source = "(?i)" + source
+		}
+		pattern, err := regexp.Compile(source)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: invalid pattern: %v\n", err)
+			os.Exit(exitUsage)
+		}
+
+		targets := args[1:]
+		out := cmd.OutOrStdout()
+		matched := false
+		var failure error
+		for _, target := range targets {
+			content, err := loadVersion(cmd.Context(), target)
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %s: %v\n", target, err)
+				failure = err
+				continue
+			}
+			matches := search.Lines(content, pattern)
+			if len(matches) == 0 {
+				continue
+			}
+			switch {
+			case grepListOnly:
+				_, _ = fmt.Fprintln(out, target)
+			case grepCount:
+				if len(targets) > 1 {
+					_, _ = fmt.Fprintf(out, "%s: ", target)
+				}
+				_, _ = fmt.Fprintln(out, len(matches))
+			default:
+				if matched {
+					_, _ = fmt.Fprintln(out)
+				}
+				writeMatches(out, target, matches, len(targets) > 1)
+			}
+			matched = true
+		}
+
+		if failure != nil {
+			os.Exit(exitCode(failure))
+		}
+		if !matched {
+			os.Exit(exitError)
+		}
+	},
+}
+
+// writeMatches prints one target's matches, each section's heading path
+// once above the lines under it.
+func writeMatches(w io.Writer, target string, matches []search.Match, named bool) {
+	if named {
+		_, _ = fmt.Fprintln(w, target)
+	}
+	section := ""
+	for i, match := range matches {
+		if current := match.Section(); i == 0 || current != section {
+			section = current
+			if section != "" {
+				_, _ = fmt.Fprintf(w, "%s:\n", section)
+			}
+		}
+		_, _ = fmt.Fprintf(w, "  %d: %s\n", match.Line, strings.TrimSpace(match.Text))
+	}
+}
+
+// Stats command flags
+var statsFormat string
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [URL or file]",
+	Short: "Report the size and shape of a page's content",
+	Long: `Report how long a page's main content is and what it is made of: its word
+count, an estimated reading time, and how many headings, paragraphs, links
+and images it has, counted as the reader view sees them. To show how much was
+stripped, the report also gives the words on the whole page and what the
+content filter removed, by rule.
+
+The text format is for reading; --format json prints one object for scripts.
+To get the same report alongside normal output, pass --stats to sz, which
+prints it to stderr.
+
+Examples:
+  sz stats https://example.com/article
+  sz stats --format json https://example.com/article | jq .reading_minutes
+  sz --stats https://example.com/article > article.md`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+		if statsFormat != "text" && statsFormat != "json" {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: invalid --format %q: use text or json\n", statsFormat)
+			os.Exit(exitUsage)
+		}
+
+		var content string
+		var err error
+		if target == "-" {
+			content, err = readStdin(cmd)
+		} else {
+			content, err = fetchTarget(cmd.Context(), target)
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error fetching %s: %v\n", target, err)
+			os.Exit(exitCode(err))
+		}
+
+		contentFilterer := filter.NewContentFilter().WithAggressiveMode(aggressiveFiltering)
+		contentFilterer = applySiteRules(cmd, contentFilterer, target)
+		root, err := tree.NewTreeBuilder().WithPreserveAttributes(true).BuildTree(cmd.Context(), content)
+		if err == nil {
+			_, err = contentFilterer.FilterTree(cmd.Context(), root)
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error applying content filter: %v\n", err)
+			os.Exit(1)
+		}
+
+		stats, err := collectStats(content, contentFilterer)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if statsFormat == "json" {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(stats); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+		writeStats(cmd.OutOrStdout(), stats)
+	},
+}
+
+// pageStats is the report printed by sz stats and --stats.
+type pageStats struct {
+	extractor.Stats
+	Filter *filter.FilterStats `json:"filter,omitempty"`
+}
+
+// collectStats counts the reader view content of an HTML page, adding the
+// statistics of a content filter that has already run over it, if any.
+func collectStats(content string, contentFilterer *filter.ContentFilter) (pageStats, error) {
+	counts, err := extractor.New().ContentStats(content)
+	if err != nil {
+		return pageStats{}, err
+	}
+	stats := pageStats{Stats: counts}
+	if contentFilterer != nil {
+		stats.Filter = contentFilterer.GetFilterStats()
+	}
+	return stats, nil
+}
+
+// writeStats prints the text form of a statistics report.
+func writeStats(w io.Writer, stats pageStats) {
+	_, _ = fmt.Fprintf(w, "Words:         %d\n", stats.Words)
+	_, _ = fmt.Fprintf(w, "Reading time:  %d min\n", stats.ReadingMinutes)
+	_, _ = fmt.Fprintf(w, "Headings:      %d\n", stats.Headings)
+	_, _ = fmt.Fprintf(w, "Paragraphs:    %d\n", stats.Paragraphs)
+	_, _ = fmt.Fprintf(w, "Links:         %d\n", stats.Links)
+	_, _ = fmt.Fprintf(w, "Images:        %d\n", stats.Images)
+	_, _ = fmt.Fprintf(w, "Page words:    %d (%.0f%% kept)\n", stats.SourceWords, stats.Kept()*100)
+	if stats.Filter == nil {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "Filtered:      %d of %d nodes removed\n", stats.Filter.NodesRemoved, stats.Filter.NodesProcessed)
+	rules := make([]string, 0, len(stats.Filter.RulesApplied))
+	for rule := range stats.Filter.RulesApplied {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	for _, rule := range rules {
+		_, _ = fmt.Fprintf(w, "  %-20s %d\n", rule+":", stats.Filter.RulesApplied[rule])
+	}
+}
+
+// reportStats prints --stats for the root command to stderr. Errors are only
+// logged, since the content itself has already been produced.
+func reportStats(cmd *cobra.Command, content string, contentFilterer *filter.ContentFilter) {
+	stats, err := collectStats(content, contentFilterer)
+	if err != nil {
+		slog.Warn("could not collect statistics", "error", err)
+		return
+	}
+	writeStats(cmd.ErrOrStderr(), stats)
+}
+
+var summarizeCmd = &cobra.Command{
+	Use:   "summarize [URL or file]",
+	Short: "Summarize a page with a language model",
+	Long: `Distill a page and send its markdown to the language model set up under
+llm in config.yaml, printing the summary it writes. Any OpenAI-compatible
+chat completions API works, including local servers such as Ollama:
+
+  llm:
+    endpoint: https://api.openai.com/v1   # or http://localhost:11434/v1
+    model: gpt-4o-mini
+    api_key: sk-...                       # or ESSENZ_LLM_API_KEY
+    max_tokens: 400
+    prompt: |                             # optional, over .Title, .URL and .Content
+      Summarize this in three bullet points:
+      {{.Content}}
+
+Nothing is sent to a model unless sz summarize or --summary is used. Pass
+--summary to sz to get a Summary section in front of the full content.
+
+Examples:
+  sz summarize https://example.com/article
+  sz --summary https://example.com/article > article.md`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+		client := newLLMClient(cmd)
+
+		var content string
+		var err error
+		if target == "-" {
+			if content, err = readStdin(cmd); err == nil {
+				content, err = extractMarkdown(extractor.New(), target, content)
+			}
+		} else {
+			content, err = distillTarget(cmd.Context(), target)
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %s: %v\n", target, err)
+			os.Exit(exitCode(err))
+		}
+
+		summary, err := summarize(cmd.Context(), client, target, content)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error summarizing: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), summary)
+	},
+}
+
+// newLLMClient creates a client for the language model in config.yaml,
+// exiting when none is set up.
+func newLLMClient(cmd *cobra.Command) *llm.Client {
+	cfg, err := config.Load()
+	if err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+	client, err := llm.NewClient(cfg.LLM)
+	if err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	return client
+}
+
+// newMediaHandler creates the media handler for --media-handler, with a
+// vision model describing images that lack alt text under --describe-images.
+func newMediaHandler(cmd *cobra.Command, target string) *media.MediaHandler {
+	handler := media.NewMediaHandler().WithIncludeDecorative(includeDecorative)
+	if describeImages {
+		if target != "-" {
+			handler = handler.WithBaseURL(target)
+		}
+		handler = handler.WithDescriber(newLLMClient(cmd))
+	}
+	return handler
+}
+
+// newTranslator creates the translator set up in config.yaml for
+// --translate, exiting when the flag or the configuration is unusable.
+func newTranslator(cmd *cobra.Command) translate.Translator {
+	if err := translate.ValidateLang(translateTo); err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: --translate: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	if rawOutput {
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Error: --translate cannot be combined with --raw")
+		os.Exit(exitUsage)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+	translator, err := translate.New(cfg)
+	if err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	return translator
+}
+
+// chunkContent splits distilled content into chunks of --chunk-size
+// characters, labelled with the page's title and URL.
+func chunkContent(target, content string) []chunk.Chunk {
+	chunks := chunk.Split(content, chunkSize, chunkOverlap)
+	title := firstHeading(content)
+	for i := range chunks {
+		chunks[i].Title = title
+		if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+			chunks[i].URL = target
+		}
+	}
+	return chunks
+}
+
+// writeChunks writes chunks as JSON lines.
+func writeChunks(w io.Writer, chunks []chunk.Chunk) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	for _, c := range chunks {
+		if err := encoder.Encode(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// summarize asks the model for a summary of a page's distilled markdown.
+func summarize(ctx context.Context, client *llm.Client, target, content string) (string, error) {
+	doc := llm.Document{Title: firstHeading(content), Content: content}
+	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		doc.URL = target
+	}
+	return client.Summarize(ctx, doc)
+}
+
+var bookmarkCmd = &cobra.Command{
+	Use:   "bookmark",
+	Short: "Save pages to read later",
+	Long: `Keep a list of URLs with tags, optionally with a distilled copy of each page
+so it can be read later, offline or after the page has changed. Bookmarks are
+kept in the bookmarks directory under the sz configuration directory.`,
+}
+
+// Bookmark add command flags
+var (
+	bookmarkTags  []string
+	bookmarkTitle string
+	bookmarkCache bool
+)
+
+var bookmarkAddCmd = &cobra.Command{
+	Use:   "add URL",
+	Short: "Bookmark a URL",
+	Long: `Bookmark a URL or local file with optional tags. With --cache the page is
+distilled now and the result kept with the bookmark, and its first heading
+becomes the title unless --title is given.
+
+Adding a URL that is already bookmarked adds the new tags to it, and with
+--cache replaces its cached copy.
+
+Examples:
+  sz bookmark add --tag go --tag tutorial https://go.dev/doc/tutorial/getting-started
+  sz bookmark add --cache https://example.com/article`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+		if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+			// Files are opened later from wherever sz runs
+			if abs, err := filepath.Abs(target); err == nil {
+				target = abs
+			}
+		}
+		store := openBookmarks(cmd)
+
+		var content string
+		if bookmarkCache {
+			var err error
+			content, err = distillTarget(cmd.Context(), target)
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error fetching %s: %v\n", target, err)
+				os.Exit(exitCode(err))
+			}
+			if bookmarkTitle == "" {
+				bookmarkTitle = firstHeading(content)
+			}
+		}
+
+		b := store.Add(target, bookmarkTitle, bookmarkTags)
+		if bookmarkCache {
+			if err := store.SaveContent(b, content); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if err := store.Save(); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Bookmarked %s as %d\n", b.URL, b.ID)
+	},
+}
+
+// Bookmark list command flags
+var (
+	bookmarkListTag  string
+	bookmarkListJSON bool
+)
+
+var bookmarkListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List bookmarks",
+	Long: `List bookmarks, oldest first, one per line with tab-separated ID, URL, tags
+and title. --tag lists only the bookmarks with that tag; --json prints a list
+of objects.
+
+Examples:
+  sz bookmark list
+  sz bookmark list --tag go | cut -f2`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		bookmarks := openBookmarks(cmd).List(bookmarkListTag)
+		if bookmarkListJSON {
+			if bookmarks == nil {
+				bookmarks = []bookmark.Bookmark{}
+			}
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			encoder.SetEscapeHTML(false)
+			if err := encoder.Encode(bookmarks); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+		for _, b := range bookmarks {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%s\t%s\n", b.ID, b.URL, strings.Join(b.Tags, ","), b.Title)
+		}
+	},
+}
+
+var bookmarkRefresh bool
+
+var bookmarkOpenCmd = &cobra.Command{
+	Use:   "open ID|URL",
+	Short: "Read a bookmarked page",
+	Long: `Print the distilled content of a bookmark, given by its ID or URL. A cached
+copy is printed as it was saved; otherwise the page is distilled now.
+--refresh distills the page again and keeps the result as its cached copy.
+
+Examples:
+  sz bookmark open 3 | less
+  sz bookmark open --refresh 3`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store := openBookmarks(cmd)
+		b, ok := store.Find(args[0])
+		if !ok {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: no bookmark %s\n", args[0])
+			os.Exit(1)
+		}
+
+		if b.Cached && !bookmarkRefresh {
+			content, err := store.Content(b)
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				os.Exit(1)
+			}
+			_, _ = fmt.Fprint(cmd.OutOrStdout(), content)
+			return
+		}
+
+		content, err := distillTarget(cmd.Context(), b.URL)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error fetching %s: %v\n", b.URL, err)
+			os.Exit(exitCode(err))
+		}
+		if bookmarkRefresh {
+			if b.Title == "" {
+				b.Title = firstHeading(content)
+			}
+			err := store.SaveContent(b, content)
+			if err == nil {
+				err = store.Save()
+			}
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		_, _ = fmt.Fprint(cmd.OutOrStdout(), content)
+	},
+}
+
+// openBookmarks opens the bookmark store, exiting when it cannot be read.
+func openBookmarks(cmd *cobra.Command) *bookmark.Store {
+	dir, err := bookmark.DefaultDir()
+	if err == nil {
+		var store *bookmark.Store
+		if store, err = bookmark.Open(dir); err == nil {
+			return store
+		}
+	}
+	_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+	os.Exit(1)
+	return nil
+}
+
+// firstHeading returns the title of the first heading in markdown, if any.
+func firstHeading(markdown string) string {
+	for _, line := range strings.Split(markdown, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			continue
+		}
+		if title := strings.TrimSpace(strings.TrimLeft(line, "#")); title != "" {
+			return title
+		}
+	}
+	return ""
+}
+
+// Push command flags
+var (
+	pushTags       []string
+	pushHighlights []string
+)
+
+var pushCmd = &cobra.Command{
+	Use:   "push SERVICE URL",
+	Short: "Save a page to Pocket, Instapaper or Readwise Reader",
+	Long: `Distill a page and save it to a read-it-later service, with the account set
+up under push in config.yaml:
+
+  push:
+    pocket:
+      consumer_key: ...
+      access_token: ...
+    instapaper:
+      username: you@example.com
+      password: ...
+    readwise:
+      token: ...                  # from readwise.io/access_token
+
+Readwise Reader gets the distilled content along with the title, author and
+date; Pocket and Instapaper fetch pages themselves and get the URL and title.
+--highlight sends passages to Readwise as highlights of the page instead.
+
+Examples:
+  sz push readwise --tag go https://go.dev/blog/go1.22
+  sz push pocket https://example.com/article
+  sz push readwise --highlight "The key sentence." https://example.com/article`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name, target := args[0], args[1]
+		if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %q is not a URL: services save pages by their URL\n", target)
+			os.Exit(exitUsage)
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		service, err := push.New(name, cfg.Push)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(exitUsage)
+		}
+
+		content, err := fetchTarget(cmd.Context(), target)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %s: %v\n", target, err)
+			os.Exit(exitCode(err))
+		}
+		markdown, err := extractMarkdown(extractor.New().WithComments(withComments), target, content)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %s: reader view extraction failed: %v\n", target, err)
+			os.Exit(exitError)
+		}
+		meta := metadata.Extract(content, target)
+		page := push.Page{
+			URL:         target,
+			Title:       meta.Title,
+			Author:      meta.Author,
+			Published:   meta.Published,
+			Description: meta.Description,
+			Markdown:    markdown,
+			Tags:        pushTags,
+			Highlights:  pushHighlights,
+		}
+		if page.Title == "" {
+			page.Title = firstHeading(markdown)
+		}
+
+		if err := service.Push(cmd.Context(), page); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error pushing to %s: %v\n", name, err)
+			if errors.Is(err, push.ErrHighlightsUnsupported) {
+				os.Exit(exitUsage)
+			}
+			os.Exit(exitCode(err))
+		}
+		what := "page"
+		if len(pushHighlights) > 0 {
+			what = fmt.Sprintf("%d highlights", len(pushHighlights))
+		}
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Pushed %s of %s to %s\n", what, target, name)
+	},
+}
+
+// Serve command flags
+var (
+	serveGRPC     string
+	serveHTTP     string
+	serveQueueDir string
+	serveWorkers  int
+	serveRate     float64
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the extraction API over gRPC and HTTP",
+	Long: `Serve the fetch and extract functionality over the network, for programs
+that embed sz in another language or hand pages to it from a pipeline.
+
+The gRPC API is defined in api/essenz/v1/essenz.proto; generate a client from
+it for your language. Extract distills a URL, or HTML sent with the request,
+streaming the page metadata first and then the markdown in chunks, so large
+documents never hit message size limits. Fetch streams the rendered HTML the
+same way.
+
+With --http, jobs can also be posted as JSON to /v1/jobs. The server answers
+202 with the job's id and status at once and queues the job; GET
+/v1/jobs/ID reports how far it has got, and GET /v1/jobs/ID/result returns
+the markdown and metadata, or the error, once it has finished. A job with a
+callback_url has its result POSTed there as JSON too, retried when the
+callback is unreachable or answers with a server error.
+
+The queue absorbs bursts: --workers jobs run at a time, and --rate caps how
+many start per minute. Jobs are kept on disk in --queue-dir, so those still
+queued or running when the server stops are run after it restarts. Finished
+jobs are kept for a day.
+
+Pages are rendered through the Chrome daemon, which is started if needed;
+only http and https URLs are accepted. The server has no authentication, so
+it listens on localhost unless told otherwise. --grpc "" turns the gRPC API
+off. It runs until interrupted.
+
+Examples:
+  sz serve
+  sz serve --grpc :50051
+  grpcurl -plaintext -d '{"url":"https://example.com"}' localhost:50051 essenz.v1.ExtractService/Extract
+  sz serve --http localhost:8080 --workers 4 --rate 30
+  curl -d '{"url":"https://example.com","callback_url":"https://ci.example.com/hook"}' localhost:8080/v1/jobs
+  curl localhost:8080/v1/jobs/ID/result`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		if serveGRPC == "" && serveHTTP == "" {
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Error: nothing to serve: give --grpc or --http an address")
+			os.Exit(exitUsage)
+		}
+		if serveRate < 0 {
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Error: --rate must not be negative")
+			os.Exit(exitUsage)
+		}
+		api := rpc.NewServer(serveFetch)
+		errs := make(chan error, 2)
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		var grpcServer *grpc.Server
+		if serveGRPC != "" {
+			listener, err := net.Listen("tcp", serveGRPC)
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				os.Exit(1)
+			}
+			grpcServer = grpc.NewServer()
+			api.Register(grpcServer)
+			// Reflection lets tools such as grpcurl call the API without the proto file
+			reflection.Register(grpcServer)
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Serving gRPC on %s\n", listener.Addr())
+			go func() { errs <- grpcServer.Serve(listener) }()
+		}
+
+		var httpServer *http.Server
+		if serveHTTP != "" {
+			listener, err := net.Listen("tcp", serveHTTP)
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				os.Exit(1)
+			}
+			var interval time.Duration
+			if serveRate > 0 {
+				interval = time.Duration(float64(time.Minute) / serveRate)
+			}
+			if err := api.StartQueue(ctx, serveQueueDir, serveWorkers, interval); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				os.Exit(1)
+			}
+			httpServer = &http.Server{Handler: api.Handler(), ReadHeaderTimeout: 10 * time.Second}
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Serving HTTP on %s\n", listener.Addr())
+			go func() { errs <- httpServer.Serve(listener) }()
+		}
+
+		select {
+		case <-ctx.Done():
+		case err := <-errs:
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if grpcServer != nil {
+			grpcServer.GracefulStop()
+		}
+		if httpServer != nil {
+			shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			_ = httpServer.Shutdown(shutdown)
+		}
+	},
+}
+
+// serveFetch renders a page for sz serve. Each request brings its own
+// options, so none of the command-line fetch flags apply, and like the
+// command line it falls back to plain HTTP when Chrome fails.
+func serveFetch(ctx context.Context, pageURL string, opts rpc.Options) (string, error) {
+	agent := session.ResolveUserAgent(opts.UserAgent)
+	client := browser.NewClient().
+		WithHeaders(opts.Headers).
+		WithUserAgent(agent).
+		WithRemoteChrome(remoteChrome)
+	if opts.WaitForSelector != "" {
+		client = client.WithReadinessChecker(pageready.NewReadinessChecker().WithCustomSelectors([]string{opts.WaitForSelector}))
+	}
+	defer client.Shutdown()
+
+	content, err := client.FetchContent(ctx, pageURL)
+	if err == nil {
+		return content, nil
+	}
+	if statusErr := chromeStatusError(err); statusErr != nil {
+		return "", statusErr
+	}
+	slog.Info("Chrome fetch failed, fetching over HTTP", "url", pageURL, "error", err)
+	content, _, err = fetchURL(pageURL, httpOptions{headers: opts.Headers, userAgent: agent})
+	return content, err
+}
+
+// Watch command flags
+var (
+	watchInterval     time.Duration
+	watchCount        int
+	watchFull         bool
+	watchOnChange     string
+	watchNoRevalidate bool
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [URL or file]",
+	Short: "Re-distill a page periodically and print what changed",
+	Long: `Distill a URL or file every --interval and compare the result with the
+previous run. Nothing is printed while the content stays the same; when it
+changes, a unified diff of the markdown goes to stdout, or the whole new
+version with --full. Fetch errors are reported on stderr and the watch
+carries on.
+
+--on-change runs a command through the shell on every change, with the new
+markdown on stdin, the URL or file in SZ_URL and a file holding the diff in
+SZ_DIFF_FILE. What the command prints goes to stderr.
+
+When the server sent an ETag or Last-Modified date with the page, each later
+fetch first asks whether the page changed since, and skips the fetch when the
+answer is 304 Not Modified. --no-revalidate fetches every time, for pages
+whose content is loaded by scripts rather than served with the page.
+
+Useful for keeping an eye on changelogs, documentation and status pages.
+Runs until interrupted, or for --count fetches.
+
+Examples:
+  sz watch https://example.com/changelog
+  sz watch --interval 1h https://status.example.com
+  sz watch --full --interval 30s https://example.com/news
+  sz watch --on-change 'curl -d @"$SZ_DIFF_FILE" ntfy.sh/my-topic' https://example.com/changelog`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+		if watchInterval <= 0 {
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Error: --interval must be positive")
+			os.Exit(exitUsage)
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		// The check cannot carry a browser profile's session, so pages in one
+		// are always fetched
+		isURL := strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://")
+		if isURL && !watchNoRevalidate && browserProfile == "" && loadState == "" {
+			pageValidators = make(map[string]*revalidate.Validators)
+		}
+
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Watching %s every %s\n", target, watchInterval)
+		var previous string
+		var fetchedAt time.Time
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		for fetches := 0; watchCount == 0 || fetches < watchCount; fetches++ {
+			if fetches > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+				unchanged, err := pageUnchanged(ctx, target)
+				if err != nil {
+					slog.Debug("revalidation failed, fetching the page", "url", target, "error", err)
+				}
+				if unchanged {
+					slog.Debug("page not modified", "url", target)
+					continue
+				}
+			}
+
+			content, err := distillTarget(ctx, target)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %s: %v\n", target, err)
+				// Fetch the page in full next time, whatever the server says
+				delete(pageValidators, target)
+				continue
+			}
+			now := time.Now()
+			if !fetchedAt.IsZero() && content != previous {
+				edits := diff.Compute(diff.Lines(previous), diff.Lines(content))
+				change := diff.Unified(
+					target+"\t"+fetchedAt.Format(time.RFC3339),
+					target+"\t"+now.Format(time.RFC3339),
+					edits, 3)
+				if watchFull {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "<!-- changed: %s at %s -->\n\n%s\n", target, now.Format(time.RFC3339), strings.TrimRight(content, "\n"))
+				} else {
+					_, _ = fmt.Fprint(cmd.OutOrStdout(), change)
+				}
+				if watchOnChange != "" {
+					err := hook.Run(ctx, watchOnChange, hook.Change{URL: target, Markdown: content, Diff: change}, cmd.ErrOrStderr())
+					if err != nil && ctx.Err() == nil {
+						_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %s: %v\n", target, err)
+					}
+				}
+			}
+			previous, fetchedAt = content, now
+		}
+	},
+}
+
+// Diff command flags
+var (
+	diffContext  int
+	diffExitCode bool
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff A B",
+	Short: "Compare the distilled content of two pages or snapshots",
+	Long: `Distill A and B and print a unified diff of their content. Each side may
+be a URL, an HTML file, or a markdown snapshot saved earlier (.md, .markdown
+or .txt), which is compared as it is.
+
+The diff is taken paragraph by paragraph rather than line by line, so text
+that was only re-wrapped or re-spaced does not show up as a change, and the
+reader view has already dropped navigation, ads and other page furniture.
+Nothing is printed when the content is the same.
+
+Examples:
+  sz diff https://example.com/docs saved/docs.md
+  sz diff https://example.com/v1/guide https://example.com/v2/guide
+  sz diff --exit-code snapshot.md https://example.com || echo changed`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		versions := make([]string, len(args))
+		for i, target := range args {
+			content, err := loadVersion(cmd.Context(), target)
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %s: %v\n", target, err)
+				os.Exit(exitCode(err))
+			}
+			versions[i] = content
+		}
+
+		edits := diff.Compute(diff.Blocks(versions[0]), diff.Blocks(versions[1]))
+		_, _ = fmt.Fprint(cmd.OutOrStdout(), diff.Unified(args[0], args[1], edits, diffContext))
+		if diffExitCode && diff.Changed(edits) {
+			os.Exit(exitError)
+		}
+	},
+}
+
+// loadVersion reads a saved markdown snapshot as it is and distills anything
+// else.
+func loadVersion(ctx context.Context, target string) (string, error) {
+	switch strings.ToLower(filepath.Ext(target)) {
+	case ".md", ".markdown", ".txt":
+		content, err := os.ReadFile(target)
+		if err != nil {
+			return "", fmt.Errorf("failed to read snapshot: %w", err)
+		}
+		return string(content), nil
+	}
+	return distillTarget(ctx, target)
+}
+
+// Crawl command flags
+var (
+	crawlDepth       int
+	crawlSameDomain  bool
+	crawlInclude     []string
+	crawlExclude     []string
+	crawlMaxPages    int
+	crawlDelay       time.Duration
+	crawlOutputDir   string
+	crawlConcurrency int
+	crawlPerHost     int
+)
+
+var crawlCmd = &cobra.Command{
+	Use:   "crawl URL",
+	Short: "Follow links from a page and distill every page reached",
+	Long: `Distill URL, follow the links in its main content (not its navigation or
+footer), and distill the pages they lead to, up to --depth links away. Each
+page is written to DIR/HOST/PATH.md under --output-dir, so a documentation
+site becomes a tree of markdown files mirroring its URLs. With --jsonl each
+page is written to stdout as a JSON line instead, as sz batch --jsonl does.
+
+--same-domain keeps the crawl on the start page's host and its subdomains.
+--include and --exclude take regular expressions matched against each link's
+URL; when --include is given, only matching links are followed. Links to
+images, archives and other files are never followed.
+
+Pages are fetched in parallel like sz batch, with --delay between fetches on
+the same host to go easy on the site. A page that fails is reported and the
+crawl carries on; the command exits with status 1 if any page failed.
+
+Examples:
+  sz crawl --same-domain https://example.com/docs/
+  sz crawl --depth 3 --same-domain --include '/docs/' --output-dir corpus https://example.com/docs/
+  sz crawl --exclude '/(tag|author)/' --delay 2s https://blog.example.com`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		start, err := url.Parse(args[0])
+		if err != nil || start.Hostname() == "" {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %q is not a URL\n", args[0])
+			os.Exit(exitUsage)
+		}
+		scope := crawl.Scope{Start: start, SameDomain: crawlSameDomain}
+		for _, pattern := range crawlInclude {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: invalid --include: %v\n", err)
+				os.Exit(exitUsage)
+			}
+			scope.Include = append(scope.Include, re)
+		}
+		for _, pattern := range crawlExclude {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: invalid --exclude: %v\n", err)
+				os.Exit(exitUsage)
+			}
+			scope.Exclude = append(scope.Exclude, re)
+		}
+		policy, err := output.ParsePolicy(ifExists)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(exitUsage)
+		}
+		tmpl, err := output.ParseTemplate(filepath.Join(crawlOutputDir, "{{.Host}}", "{{.Path}}.md"))
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		options := crawl.Options{
+			Depth:    crawlDepth,
+			MaxPages: crawlMaxPages,
+			Delay:    crawlDelay,
+			Scope:    scope,
+			Batch:    batch.Options{Workers: crawlConcurrency, PerHost: crawlPerHost, Ordered: true},
+		}
+		if jsonlOutput && cmd.Flags().Changed("output-dir") {
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Error: --jsonl writes to stdout and cannot be combined with --output-dir")
+			os.Exit(exitUsage)
+		}
+		crawled, failed := 0, 0
+		crawl.Run(cmd.Context(), args[0], options, crawlPage, func(page crawl.Page) {
+			crawled++
+			if page.Err != nil {
+				failed++
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %s: %v\n", page.URL, page.Err)
+				if jsonlOutput {
+					_, _ = fmt.Fprint(cmd.OutOrStdout(), encodeRecord(corpusRecord{URL: page.URL, FetchedAt: time.Now().UTC(), Error: page.Err.Error()}))
+				}
+				return
+			}
+			if jsonlOutput {
+				_, _ = fmt.Fprint(cmd.OutOrStdout(), page.Content)
+				return
+			}
+
+			path, err := tmpl.Path(output.NewFields(page.URL, crawled))
+			if err == nil && policy == output.Skip && output.Exists(path) {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s: kept, %s exists\n", page.URL, path)
+				return
+			}
+			if err == nil {
+				err = output.Write(path, []byte(page.Content), policy)
+			}
+			if err != nil {
+				failed++
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %s: %v\n", page.URL, err)
+				return
+			}
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s -> %s\n", page.URL, path)
+		})
+
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Crawled %d of %d pages\n", crawled-failed, crawled)
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// crawlPage distills one page of a crawl, or with --jsonl turns it into a
+// JSON line, and returns the links in its main content.
+func crawlPage(ctx context.Context, pageURL string) (string, []string, error) {
+	fetchedAt := time.Now().UTC()
+	content, err := fetchTarget(ctx, pageURL)
+	if err != nil {
+		return "", nil, err
+	}
+	ext := extractor.New().WithComments(withComments)
+	links, err := ext.ContentLinks(content)
+	if err != nil {
+		return "", nil, fmt.Errorf("reader view extraction failed: %w", err)
+	}
+	markdown, err := ext.ExtractContent(content)
+	if err != nil {
+		return "", nil, fmt.Errorf("reader view extraction failed: %w", err)
+	}
+	if jsonlOutput {
+		meta := metadata.Extract(content, pageURL)
+		title := meta.Title
+		if title == "" {
+			title = firstHeading(markdown)
+		}
+		return encodeRecord(corpusRecord{
+			URL:        pageURL,
+			Title:      title,
+			Markdown:   markdown,
+			Metadata:   meta,
+			FetchedAt:  fetchedAt,
+			DurationMS: time.Since(fetchedAt).Milliseconds(),
+		}), links, nil
+	}
+	return markdown, links, nil
+}
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Manage the Chrome daemon",
+	Long:  `Start, stop, or check the status of the Chrome daemon process, or install it to start at login.`,
+}
+
+// Daemon start command flags
+var (
+	daemonLogFile         string
+	daemonChromeContainer string
+	daemonChromeImage     string
+	daemonBrowser         string
+	daemonDetach          bool
+)
+
+var daemonStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start the Chrome daemon",
+	Long: `Start the Chrome daemon in the foreground. It logs to daemon.log under the
+user cache directory (or --log-file, "-" for stderr), rotating the file once
+it reaches ESSENZ_LOG_MAX_SIZE megabytes (default 10).
+
+The daemon also runs the schedules in config.yaml, distilling each page at
+start and then every interval. The markdown is written to the output file
+whenever it changes, and with diff set each change is appended to that file
+as a unified diff:
+
+  schedules:
+    - name: changelog
+      url: https://example.com/changelog
+      interval: 1h
+      output: ~/notes/changelog.md
+      diff: ~/notes/changelog.diff
+      profile: work    # optional: fetch in a saved browser profile
+      on_change: mail -s "Changelog updated" me@example.com
+
+on_change runs through the shell when the content changes, as sz watch
+--on-change does, with the output file in SZ_OUTPUT as well.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		if daemonLogFile != "" {
+			_ = os.Setenv("ESSENZ_LOG_FILE", daemonLogFile)
+		}
+		if err := daemon.ValidateBackend(daemonBrowser); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error starting daemon: %v\n", err)
+			os.Exit(1)
+		}
+		if daemonBrowser != "" {
+			_ = os.Setenv("ESSENZ_BROWSER", daemonBrowser)
+		}
+		if err := daemon.ValidateContainerRuntime(daemonChromeContainer); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error starting daemon: %v\n", err)
+			os.Exit(1)
+		}
+		if daemonChromeContainer != "" {
+			_ = os.Setenv("ESSENZ_CHROME_CONTAINER", daemonChromeContainer)
+		}
+		if daemonChromeImage != "" {
+			_ = os.Setenv("ESSENZ_CHROME_IMAGE", daemonChromeImage)
+		}
+		if insecureTLS {
+			_ = os.Setenv("ESSENZ_INSECURE", "1")
+		}
+		for env, value := range map[string]string{
+			"ESSENZ_CA_CERT":     caCertFile,
+			"ESSENZ_CLIENT_CERT": clientCertFile,
+			"ESSENZ_CLIENT_KEY":  clientKeyFile,
+			"ESSENZ_LANG":        languages,
+		} {
+			if value != "" {
+				_ = os.Setenv(env, value)
+			}
+		}
+
+		if daemonDetach {
+			startDetachedDaemon(cmd)
+			return
+		}
+
+		server := daemon.NewServer()
+		if err := server.Start(); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error starting daemon: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Chrome daemon started")
+		if logPath := daemon.LogPath(); logPath != "-" {
+			fmt.Printf("Logging to %s\n", logPath)
+		}
+
+		// Run until stopped by a client or a signal, cleaning up either way
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(signals)
+		select {
+		case <-signals:
+			_ = server.Stop()
+			fmt.Println("Chrome daemon stopped")
+		case <-server.Done():
+		}
+	},
+}
+
+// startDetachedDaemon runs the daemon in the background and returns once it answers.
+func startDetachedDaemon(cmd *cobra.Command) {
+	endpoint, err := daemon.DefaultEndpoint()
+	if err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error starting daemon: %v\n", err)
+		os.Exit(1)
+	}
+	if report := daemon.CheckStatus(endpoint); report.Status == daemon.StatusRunning {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error starting daemon: daemon already running at %s\n", endpoint)
+		os.Exit(1)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error starting daemon: %v\n", err)
+		os.Exit(1)
+	}
+	pid, err := daemon.StartDetached(executable)
+	if err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error starting daemon: %v\n", err)
+		os.Exit(1)
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Chrome daemon started in the background (pid %d)\n", pid)
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Logging to %s\n", daemon.LogPath())
+}
+
+var daemonStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the Chrome daemon",
+	Run: func(cmd *cobra.Command, _ []string) {
+		client := daemon.NewDaemonClient()
+		if err := client.Shutdown(); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error stopping daemon: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Chrome daemon stopped")
+	},
+}
+
+var daemonRestartGraceful bool
+
+var daemonRestartCmd = &cobra.Command{
+	Use:   "restart",
+	Short: "Restart the Chrome daemon in the background",
+	Long: `Stop the Chrome daemon and start it again in the background, for example
+after upgrading sz. The new daemon takes its settings from this command's
+flags and environment.
+
+With --graceful the new daemon starts first and takes over the socket, while
+the old one finishes the requests it has accepted or queued and then exits,
+so clients never see the daemon missing.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		executable, err := os.Executable()
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error restarting daemon: %v\n", err)
+			os.Exit(1)
+		}
+
+		restart := daemon.StartDetached
+		if daemon.IsDaemonRunning() {
+			if daemonRestartGraceful {
+				restart = daemon.RestartDetached
+			} else if err := stopDaemonAndWait(); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error restarting daemon: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		pid, err := restart(executable)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error restarting daemon: %v\n", err)
+			os.Exit(1)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Chrome daemon restarted in the background (pid %d)\n", pid)
+	},
+}
+
+// stopDaemonAndWait shuts the daemon down and waits until it no longer answers.
+func stopDaemonAndWait() error {
+	if err := daemon.NewDaemonClient().Shutdown(); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for daemon.IsDaemonRunning() {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("daemon did not stop within 10s")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return nil
+}
+
+var daemonStatusJSON bool
+
+var daemonStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Check daemon status",
+	Long:  `Report whether the Chrome daemon is running, with its uptime, Chrome process, pages served, open tabs, memory use, and last error.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		endpoint, err := daemon.DefaultEndpoint()
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+		report := daemon.CheckStatus(endpoint)
+		out := cmd.OutOrStdout()
+
+		if daemonStatusJSON {
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(report); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error encoding status: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		state := report.State
+		switch report.Status {
+		case daemon.StatusRunning:
+			_, _ = fmt.Fprintln(out, "Chrome daemon is running")
+			if report.Info != nil {
+				writeDaemonInfo(out, report.Info)
+			}
+		case daemon.StatusStale:
+			switch {
+			case state == nil:
+				_, _ = fmt.Fprintf(out, "Chrome daemon is not running: stale socket at %s\n", endpoint)
+			case daemon.StateAlive(state):
+				_, _ = fmt.Fprintf(out, "Chrome daemon is not responding: process %d still exists but %s does not answer\n", state.PID, endpoint)
+			default:
+				_, _ = fmt.Fprintf(out, "Chrome daemon is not running: process %d exited without cleaning up %s\n", state.PID, endpoint)
+			}
+			_, _ = fmt.Fprintln(out, "The stale files are removed the next time the daemon starts.")
+		default:
+			_, _ = fmt.Fprintln(out, "Chrome daemon is not running")
+		}
+	},
+}
+
+// Daemon install command flags
+var (
+	daemonInstallPrint   bool
+	daemonInstallNoStart bool
+)
+
+var daemonInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Start the Chrome daemon at login",
+	Long: `Install the Chrome daemon as a per-user service so it starts at login and is
+restarted if it fails: a systemd user unit on Linux or a launchd agent on macOS.
+The service runs this sz binary with the current --daemon-name and --socket.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		svc, err := daemonService()
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error installing daemon: %v\n", err)
+			os.Exit(1)
+		}
+
+		if daemonInstallPrint {
+			_, content, err := svc.Definition()
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error installing daemon: %v\n", err)
+				os.Exit(1)
+			}
+			_, _ = fmt.Fprint(cmd.OutOrStdout(), content)
+			return
+		}
+
+		path, err := svc.Install(!daemonInstallNoStart)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error installing daemon: %v\n", err)
+			os.Exit(1)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Installed %s at %s\n", svc.Label(), path)
+		if daemonInstallNoStart {
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "The daemon starts at your next login.")
+		}
+	},
+}
+
+var daemonUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Stop starting the Chrome daemon at login",
+	Long:  `Stop the daemon service installed by "sz daemon install" and remove its unit or plist.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		svc, err := daemonService()
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error uninstalling daemon: %v\n", err)
+			os.Exit(1)
+		}
+		path, err := svc.Uninstall()
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error uninstalling daemon: %v\n", err)
+			os.Exit(1)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Removed %s from %s\n", svc.Label(), path)
+	},
+}
+
+// daemonService describes the service that runs this binary's daemon with the
+// current --daemon-name, --socket, and --remote-chrome.
+func daemonService() (service.Service, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return service.Service{}, fmt.Errorf("failed to locate the sz binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+
+	svc := service.Service{Executable: executable, Name: daemonName, Env: map[string]string{}}
+	if socketPath != "" {
+		absolute, err := filepath.Abs(socketPath)
+		if err != nil {
+			return service.Service{}, fmt.Errorf("invalid socket path: %w", err)
+		}
+		svc.Env["ESSENZ_SOCKET"] = absolute
+	}
+	if remoteChrome != "" {
+		svc.Env["ESSENZ_REMOTE_CHROME"] = remoteChrome
+	}
+	return svc, nil
+}
+
+var chromeCmd = &cobra.Command{
+	Use:   "chrome",
+	Short: "Manage the Chrome build sz downloads",
+	Long: `Download a pinned Chrome for Testing build into the sz cache directory. The
+daemon uses it when no system Chrome is found and ESSENZ_CHROME_PATH is unset.`,
+}
+
+var chromeInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Download Chrome " + chrome.Version,
+	Run: func(cmd *cobra.Command, _ []string) {
+		path, err := chrome.Install(cmd.Context(), cmd.ErrOrStderr())
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error installing Chrome: %v\n", err)
+			os.Exit(1)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Chrome %s installed at %s\n", chrome.Version, path)
+	},
+}
+
+var chromePathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the path of the downloaded Chrome",
+	Run: func(cmd *cobra.Command, _ []string) {
+		path, ok := chrome.Installed()
+		if !ok {
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Error: Chrome is not installed; run sz chrome install")
+			os.Exit(1)
+		}
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), path)
+	},
+}
+
+var chromeUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the downloaded Chrome",
+	Run: func(cmd *cobra.Command, _ []string) {
+		if err := chrome.Uninstall(); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error removing Chrome: %v\n", err)
+			os.Exit(1)
+		}
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Downloaded Chrome removed")
+	},
+}
+
+// writeDaemonInfo prints the details a running daemon reports about itself.
+func writeDaemonInfo(out io.Writer, info *daemon.Info) {
+	if info.Name != "" {
+		_, _ = fmt.Fprintf(out, "  Name:         %s\n", info.Name)
+	}
+	_, _ = fmt.Fprintf(out, "  PID:          %d\n", info.PID)
+	_, _ = fmt.Fprintf(out, "  Endpoint:     %s\n", info.Endpoint)
+	_, _ = fmt.Fprintf(out, "  Uptime:       %s\n", info.Uptime)
+	_, _ = fmt.Fprintf(out, "  Browser:      %s\n", info.Browser)
+	switch {
+	case info.ChromeRunning && info.Container != "":
+		_, _ = fmt.Fprintf(out, "  Chrome:       %s container from %s, %s\n", info.Container, info.ChromeImage, info.ChromeVersion)
+	case info.ChromeRunning && info.RemoteChrome != "":
+		_, _ = fmt.Fprintf(out, "  Chrome:       remote at %s, %s\n", info.RemoteChrome, info.ChromeVersion)
+	case info.ChromeRunning:
+		_, _ = fmt.Fprintf(out, "  Chrome:       pid %d, %s\n", info.ChromePID, info.ChromeVersion)
+	case info.Container != "":
+		_, _ = fmt.Fprintf(out, "  Chrome:       %s container from %s, not started\n", info.Container, info.ChromeImage)
+	case info.RemoteChrome != "":
+		_, _ = fmt.Fprintf(out, "  Chrome:       remote at %s, not connected\n", info.RemoteChrome)
+	default:
+		_, _ = fmt.Fprintln(out, "  Chrome:       not started")
+	}
+	if info.ChromeWarning != "" {
+		_, _ = fmt.Fprintf(out, "  Warning:      %s\n", info.ChromeWarning)
+	}
+	_, _ = fmt.Fprintf(out, "  Pages served: %d\n", info.PagesServed)
+	_, _ = fmt.Fprintf(out, "  Open tabs:    %d\n", info.OpenTabs)
+	if info.Recycles > 0 {
+		_, _ = fmt.Fprintf(out, "  Recycled:     %d times\n", info.Recycles)
+	}
+	memory := fmt.Sprintf("daemon %s", formatBytes(info.MemoryBytes))
+	if info.ChromeMemory > 0 {
+		memory += fmt.Sprintf(", Chrome %s", formatBytes(info.ChromeMemory))
+	}
+	_, _ = fmt.Fprintf(out, "  Memory:       %s\n", memory)
+	if info.Schedules > 0 {
+		_, _ = fmt.Fprintf(out, "  Schedules:    %d\n", info.Schedules)
+	}
+	_, _ = fmt.Fprintf(out, "  Log:          %s\n", info.LogFile)
+	if info.LastError != "" {
+		_, _ = fmt.Fprintf(out, "  Last error:   %s (%s ago)\n", info.LastError, time.Since(info.LastErrorAt).Round(time.Second))
+	}
+}
+
+// formatBytes renders a byte count in binary units.
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// Tune command flags
+var tuneSite string
+
+var tuneCmd = &cobra.Command{
+	Use:   "tune [URL or file path]",
+	Short: "Interactively tune content filtering and save per-site rules",
+	Long: `Show the blocks found on a page together with the content filter's keep/drop
+decision, let you toggle individual blocks, and save the result as a rule file
+that is applied automatically on future runs against the same site.
+
+On a terminal the blocks are shown full screen: move with the arrow keys, toggle
+the selected block with space, press v to view the result, s to save or q to quit.
+When input is not a terminal, commands are read one per line instead: block
+numbers to toggle (e.g. "3 7-9"), v, l, s or q.
+
+Rules are written to ~/.config/essenz/sites/<host>.yaml.
+
+Examples:
+  sz tune https://example.com/article
+  sz tune --site example.com saved-page.html`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+
+		host := tuneSite
+		if host == "" {
+			host = config.SiteHost(target)
+		}
+		if host == "" {
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Error: cannot determine site for rules, use --site")
+			os.Exit(1)
+		}
+
+		content, err := fetchTarget(cmd.Context(), target)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		// Build two identical trees: one stays intact, the other is filtered
+		treeBuilder := tree.NewTreeBuilder().
+			WithFilterNavigation(false).
+			WithPreserveAttributes(true)
+
+		original, err := treeBuilder.BuildTree(cmd.Context(), content)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error building tree: %v\n", err)
+			os.Exit(1)
+		}
+		working, err := treeBuilder.BuildTree(cmd.Context(), content)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error building tree: %v\n", err)
+			os.Exit(1)
+		}
+
+		contentFilterer := applySiteRules(cmd, filter.NewContentFilter(), "https://"+host)
+		filtered, err := contentFilterer.FilterTree(cmd.Context(), working)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error applying content filter: %v\n", err)
+			os.Exit(1)
+		}
+
+		session := tune.NewSession(host, tune.CollectBlocks(original, filtered), cmd.InOrStdin(), cmd.OutOrStdout())
+		var save bool
+		if tune.IsTerminal(os.Stdin, os.Stdout) {
+			save, err = session.RunTerminal(os.Stdin, os.Stdout)
+		} else {
+			save, err = session.Run()
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if !save {
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No rules saved")
+			return
+		}
+
+		// Merge with any rules saved previously for this site
+		rules, err := config.LoadSiteRules(host)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if rules == nil {
+			rules = &config.SiteRules{Host: host}
+		}
+		rules.Merge(session.Rules())
+
+		if err := rules.Save(); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		path, _ := config.SiteRulesPath(host)
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Saved %d preserve and %d exclude rules to %s\n",
+			len(rules.Preserve), len(rules.Exclude), path)
+	},
+}
+
+// Learn-site command flags
+var learnPages int
+var learnThreshold float64
+var learnDryRun bool
+
+var learnSiteCmd = &cobra.Command{
+	Use:   "learn-site URL [URL...]",
+	Short: "Learn boilerplate shared across pages of a site",
+	Long: `Fetch several pages from the same site, find subtrees that repeat across them
+(navigation, footers, promos) by structural hashing, and save exclude rules so
+they are filtered on future single-page runs with --content-filter.
+
+When only one URL is given, additional pages are discovered from its same-site links.
+
+Examples:
+  sz learn-site https://example.com/blog/post-1
+  sz learn-site --pages 8 https://example.com/a https://example.com/b
+  sz learn-site --dry-run https://example.com/docs/`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		base, err := url.Parse(args[0])
+		if err != nil || base.Hostname() == "" {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %q is not a URL\n", args[0])
+			os.Exit(1)
+		}
+		host := config.SiteHost(args[0])
+
+		treeBuilder := tree.NewTreeBuilder().
+			WithFilterNavigation(false).
+			WithPreserveAttributes(true)
+		learner := learn.NewSiteLearner(learnThreshold)
+
+		queue := append([]string{}, args...)
+		discover := len(args) == 1
+		for i := 0; i < len(queue) && learner.Pages() < learnPages; i++ {
+			pageURL := queue[i]
+			if config.SiteHost(pageURL) != host {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Skipping %s: not on %s\n", pageURL, host)
+				continue
+			}
+
+			content, err := fetchTarget(cmd.Context(), pageURL)
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Skipping %s: %v\n", pageURL, err)
+				continue
+			}
+
+			root, err := treeBuilder.BuildTree(cmd.Context(), content)
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Skipping %s: %v\n", pageURL, err)
+				continue
+			}
+
+			learner.AddPage(root)
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Learned from %s\n", pageURL)
+
+			if discover && i == 0 {
+				queue = append(queue, learn.SameSiteLinks(root, base)...)
+			}
+		}
+
+		rules, err := learner.Rules(host)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if learnDryRun {
+			data, err := yaml.Marshal(rules)
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				os.Exit(1)
+			}
+			_, _ = fmt.Fprint(cmd.OutOrStdout(), string(data))
+			return
+		}
+
+		existing, err := config.LoadSiteRules(host)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if existing == nil {
+			existing = &config.SiteRules{Host: host}
+		}
+		existing.Merge(rules)
+
+		if err := existing.Save(); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		path, _ := config.SiteRulesPath(host)
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Learned %d exclude rules from %d pages, saved to %s\n",
+			len(rules.Exclude), learner.Pages(), path)
+	},
+}
+
+// Login command flags
+var loginSaveState string
+
+var loginCmd = &cobra.Command{
+	Use:   "login URL",
+	Short: "Log in through scripted steps and save the session for later fetches",
+	Long: `Open a URL in Chrome, run the --actions steps (typically filling in and
+submitting a login form), and save the resulting cookies and localStorage to
+a state file. Pass the file to --load-state on later runs to fetch pages as
+the logged-in user.
+
+The state file contains session credentials and is written with owner-only
+permissions.
+
+Examples:
+  sz login https://example.com/login --actions=login.yaml --save-state state.json
+  sz --load-state state.json https://example.com/members/article`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+
+		if loginSaveState == "" {
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Error: --save-state is required")
+			os.Exit(1)
+		}
+
+		client := browser.NewClient().WithSaveState(true)
+		defer client.Shutdown()
+
+		if actionsFile != "" {
+			script, err := actions.Load(actionsFile)
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				os.Exit(1)
+			}
+			client = client.WithActions(script)
+		}
+
+		if loadState != "" {
+			state, err := session.Load(loadState)
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				os.Exit(1)
+			}
+			client = client.WithLoadState(state)
+		}
+
+		// Logging in needs a real browser session, so there is no HTTP fallback
+		if _, err := client.FetchContent(cmd.Context(), target); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error logging in: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		state := client.State()
+		if state == nil {
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Error: browser returned no session state")
+			os.Exit(1)
+		}
+		if err := state.Save(loginSaveState); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Saved %d cookies and %d localStorage origins to %s\n",
+			len(state.Cookies), len(state.Origins), loginSaveState)
+	},
+}
+
+func init() {
+	// Every command talks to the same daemon, so these flags are handed down
+	// through the environment the daemon package reads
+	rootCmd.PersistentFlags().StringVar(&socketPath, "socket", "", "Unix socket the Chrome daemon listens on (default $XDG_RUNTIME_DIR/essenz/daemon.sock, or ESSENZ_SOCKET)")
+	rootCmd.PersistentFlags().StringVar(&daemonName, "daemon-name", "", "Use a separate named daemon with its own socket, Chrome profile, and debugging port (or ESSENZ_DAEMON_NAME)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "Log level: debug, info, warn, or error (or ESSENZ_LOG_LEVEL; default warn, info for the daemon)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "", "Log format: text or json (or ESSENZ_LOG_FORMAT, default text)")
+	rootCmd.PersistentFlags().StringVar(&remoteChrome, "remote-chrome", "", "Drive an already-running Chrome at this DevTools endpoint, e.g. ws://host:9222, instead of launching one (or ESSENZ_REMOTE_CHROME)")
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, _ []string) {
+		if logLevel != "" {
+			if _, err := logging.ParseLevel(logLevel); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				os.Exit(1)
+			}
+			_ = os.Setenv("ESSENZ_LOG_LEVEL", logLevel)
+		}
+		if err := logging.ValidateFormat(logFormat); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if logFormat != "" {
+			_ = os.Setenv("ESSENZ_LOG_FORMAT", logFormat)
+		}
+		slog.SetDefault(logging.NewCLI(cmd.ErrOrStderr()))
+
+		if err := daemon.ValidateDaemonName(daemonName); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if remoteChrome != "" {
+			if _, err := daemon.ParseRemoteChrome(remoteChrome); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				os.Exit(1)
+			}
+			_ = os.Setenv("ESSENZ_REMOTE_CHROME", remoteChrome)
+		}
+		if daemonName != "" {
+			_ = os.Setenv("ESSENZ_DAEMON_NAME", daemonName)
+		}
+		if socketPath != "" {
+			_ = os.Setenv("ESSENZ_SOCKET", socketPath)
+		}
+		if _, err := daemon.DefaultEndpoint(); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if presetName != "" {
+			if err := applyPreset(cmd, presetName); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if describeImages {
+			mediaHandler = true
+		}
+	}
+
+	// Add daemon subcommands
+	daemonCmd.AddCommand(daemonStartCmd)
+	daemonCmd.AddCommand(daemonStopCmd)
+	daemonCmd.AddCommand(daemonStatusCmd)
+	daemonCmd.AddCommand(daemonRestartCmd)
+	daemonRestartCmd.Flags().BoolVar(&daemonRestartGraceful, "graceful", false, "Start the new daemon before the old one stops, so no request fails")
+	daemonStatusCmd.Flags().BoolVar(&daemonStatusJSON, "json", false, "Print the status as JSON")
+	daemonStartCmd.Flags().StringVar(&daemonLogFile, "log-file", "", "File the daemon logs to, or - for stderr (or ESSENZ_LOG_FILE)")
+	daemonStartCmd.Flags().BoolVar(&daemonDetach, "detach", false, "Run the daemon in the background and return once it is ready")
+	daemonStartCmd.Flags().StringVar(&daemonBrowser, "browser", "", "Browser engine to render pages with: "+strings.Join(daemon.BackendNames(), ", ")+" (or ESSENZ_BROWSER, default chrome)")
+	daemonStartCmd.Flags().StringVar(&daemonChromeContainer, "chrome-container", "", "Launch Chrome in a container with docker or podman instead of on the host (or ESSENZ_CHROME_CONTAINER)")
+	daemonStartCmd.Flags().StringVar(&daemonChromeImage, "chrome-image", "", "Image for --chrome-container (or ESSENZ_CHROME_IMAGE, default chromedp/headless-shell:latest)")
+	daemonStartCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Accept any TLS certificate in schedules' HTTP requests (or ESSENZ_INSECURE)")
+	daemonStartCmd.Flags().StringVar(&caCertFile, "ca-cert", "", "PEM file of certificate authorities schedules trust besides the system's (or ESSENZ_CA_CERT)")
+	daemonStartCmd.Flags().StringVar(&clientCertFile, "client-cert", "", "PEM client certificate schedules present to sites that require one (or ESSENZ_CLIENT_CERT)")
+	daemonStartCmd.Flags().StringVar(&clientKeyFile, "client-key", "", "PEM key for --client-cert, if not in the same file (or ESSENZ_CLIENT_KEY)")
+	daemonStartCmd.Flags().StringVar(&languages, "lang", "", "Languages schedules prefer in their HTTP requests, e.g. fr-FR or fr-FR,en (or ESSENZ_LANG)")
+	daemonCmd.AddCommand(daemonInstallCmd)
+	chromeCmd.AddCommand(chromeInstallCmd)
+	chromeCmd.AddCommand(chromePathCmd)
+	chromeCmd.AddCommand(chromeUninstallCmd)
+	daemonCmd.AddCommand(daemonUninstallCmd)
+	daemonInstallCmd.Flags().BoolVar(&daemonInstallPrint, "print", false, "Print the unit or plist instead of installing it")
+	daemonInstallCmd.Flags().BoolVar(&daemonInstallNoStart, "no-start", false, "Write the service file without starting the daemon now")
+
+	// Add flags to root command
+	rootCmd.Flags().BoolVar(&rawOutput, "raw", false, "Output raw HTML without reader view processing")
+	rootCmd.Flags().BoolVar(&withComments, "with-comments", false, "Extract reader comments into a separate Comments section")
+	rootCmd.Flags().BoolVar(&waitForFrameworks, "wait-for-frameworks", false, "Enable framework-specific readiness detection (React, Vue, Angular, Next.js, Nuxt, Svelte, SolidJS, Ember, htmx)")
+	rootCmd.Flags().StringVar(&domReadyTimeout, "dom-ready-timeout", "5s", "Timeout for DOM readiness detection")
+	rootCmd.Flags().StringVar(&waitForSelector, "wait-for-selector", "", "Wait for specific CSS selector to appear before extraction")
+	rootCmd.Flags().StringVar(&waitForText, "wait-for-text", "", "Wait for specific text to appear in the page body before extraction")
+	rootCmd.Flags().BoolVar(&waitForLayoutStable, "wait-for-layout-stable", false, "Wait until the page stops shifting and reflowing before extraction")
+	rootCmd.Flags().BoolVar(&waitForNetworkIdle, "wait-for-network-idle", false, "Wait until the page stops starting network requests before extraction")
+	rootCmd.Flags().StringArrayVar(&stageTimeouts, "stage-timeout", nil, "Budget for one readiness stage as stage=duration, e.g. framework=1s (repeatable; stages: dom, route, selector, text, framework, network-idle, layout)")
+	rootCmd.Flags().BoolVar(&debugReadiness, "debug-readiness", false, "Show detailed DOM readiness detection information")
+	rootCmd.Flags().StringVar(&readinessReport, "readiness-report", "", "Write the DOM readiness result as JSON to this file")
+	rootCmd.Flags().StringVar(&scrollMode, "scroll", "", "Scroll before extraction to load lazy content: auto, or a number of viewports")
+	rootCmd.Flags().IntVar(&scrollMaxHeight, "scroll-max-height", pageready.DefaultScrollMaxHeight, "Maximum number of pixels to scroll")
+	rootCmd.Flags().StringArrayVar(&evalScripts, "eval", nil, "JavaScript to run in the page before extraction (repeatable)")
+	rootCmd.Flags().StringVar(&actionsFile, "actions", "", "YAML file of click/type/waitFor/scroll/select steps to run before extraction")
+	rootCmd.Flags().StringVar(&loadState, "load-state", "", "Session state file from sz login to restore cookies and localStorage")
+	rootCmd.Flags().StringVar(&cookiesFile, "cookies", "", "Cookie file to send with requests (Netscape cookies.txt or JSON)")
+	rootCmd.Flags().IntVar(&fetchRetries, "retries", 0, "Retry a fetch this many times after a server error, dropped connection or timeout")
+	rootCmd.Flags().StringVar(&retryDelay, "retry-delay", "1s", "Wait before the first retry, doubled for each one after, with jitter")
+	rootCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Accept any TLS certificate without verifying it")
+	rootCmd.Flags().StringVar(&caCertFile, "ca-cert", "", "PEM file of certificate authorities to trust besides the system's")
+	rootCmd.Flags().StringVar(&clientCertFile, "client-cert", "", "PEM client certificate to present to sites that require one (mutual TLS)")
+	rootCmd.Flags().StringVar(&clientKeyFile, "client-key", "", "PEM key for --client-cert, if not in the same file")
+	rootCmd.Flags().StringVar(&saveCookiesFile, "save-cookies", "", "Write the session's cookies to this file after fetching (JSON if it ends in .json, otherwise cookies.txt)")
+	rootCmd.Flags().StringArrayVar(&requestHeaders, "header", nil, "Extra request header as \"Name: value\" (repeatable)")
+	rootCmd.Flags().StringVar(&languages, "lang", "", "Languages to prefer, e.g. fr-FR or fr-FR,en (sets Accept-Language and the browser's languages)")
+	rootCmd.Flags().StringVar(&basicAuth, "auth", "", "HTTP basic auth credentials as user:pass")
+	rootCmd.Flags().StringVar(&userAgent, "user-agent", "", "User agent string or preset: "+strings.Join(session.UserAgentPresets(), ", "))
+	rootCmd.Flags().StringVar(&viewportSize, "viewport", "", "Viewport size as WIDTHxHEIGHT, e.g. 1280x800")
+	rootCmd.Flags().StringVar(&deviceName, "device", "", "Emulate a device such as \"iPhone 14\" or \"Pixel 5\"")
+	rootCmd.Flags().StringVar(&locale, "locale", "", "Locale the page sees, e.g. de-DE (also sets Accept-Language)")
+	rootCmd.Flags().StringVar(&timezone, "timezone", "", "Time zone the page sees, e.g. Europe/Berlin")
+	rootCmd.Flags().StringVar(&geolocation, "geolocation", "", "Position the page sees as latitude,longitude[,accuracy]")
+	rootCmd.Flags().BoolVar(&adblockEnabled, "adblock", false, "Block ad and tracker requests using the bundled filter list")
+	rootCmd.Flags().StringArrayVar(&adblockLists, "adblock-list", nil, "EasyList/uBlock-style filter list file to block requests with (repeatable, implies --adblock)")
+	rootCmd.Flags().BoolVar(&captureConsole, "capture-console", false, "Print the page's console messages and script errors to stderr")
+	rootCmd.Flags().StringVar(&consoleLog, "console-log", "", "Write the page's console messages and script errors to this file (implies --capture-console)")
+	rootCmd.Flags().StringVar(&harFile, "har", "", "Write the page load's requests and responses to this HAR file")
+	rootCmd.Flags().StringVar(&downloadDir, "download-dir", "", "Save the target to this directory when it is a file download, such as a PDF, and report the path")
+	rootCmd.Flags().StringVar(&queueTimeout, "queue-timeout", "", "How long to wait for the daemon when it is busy with other fetches, e.g. 1m (default 15s, or ESSENZ_QUEUE_TIMEOUT)")
+	rootCmd.Flags().StringVar(&browserProfile, "profile", "", "Run in a named browser profile whose cookies and storage persist between fetches")
+	rootCmd.Flags().BoolVar(&incognito, "incognito", false, "Run in a throwaway browser context that shares no cookies or storage with other fetches")
+	rootCmd.Flags().BoolVar(&followCanonicalURL, "follow-canonical", false, "Re-fetch the page from the canonical URL it declares (rel=canonical or og:url) when that differs from the one given")
+	rootCmd.Flags().BoolVar(&preferAMP, "prefer-amp", false, "Fetch the page's AMP version (rel=amphtml) instead when it has one, falling back to the page when the AMP version yields no content")
+	rootCmd.Flags().BoolVar(&preferPrint, "prefer-print", false, "Fetch the page's print version instead when it links to one, falling back to the page when the print version yields no content")
+	rootCmd.Flags().BoolVar(&dataOutline, "data-outline", false, "For JSON and XML responses, print an outline of their structure instead of the whole document")
+	rootCmd.Flags().BoolVar(&followPagination, "follow-pagination", false, "Fetch the following pages of an article split across pages (rel=next, \"Page 2 of N\") and stitch them into one document")
+	rootCmd.Flags().BoolVar(&waybackFallback, "wayback-fallback", false, "When the page is missing, forbidden or unreachable, distill its latest Wayback Machine snapshot instead")
+
+	// Text node tree flags
+	rootCmd.Flags().BoolVar(&textNodeTree, "text-node-tree", false, "Build hierarchical text node tree structure")
+	rootCmd.Flags().StringVar(&treeFormat, "tree-format", "text", "Output format for text node tree (text, json)")
+	rootCmd.Flags().BoolVar(&filterNavigation, "filter-navigation", false, "Filter out navigation elements from tree")
+	rootCmd.Flags().BoolVar(&preserveAttributes, "preserve-attributes", false, "Preserve element attributes in tree structure")
+
+	// Content filter flags
+	rootCmd.Flags().BoolVar(&contentFilter, "content-filter", false, "Apply sophisticated content filtering to remove non-content elements")
+	rootCmd.Flags().BoolVar(&aggressiveFiltering, "aggressive-filtering", false, "Enable more aggressive content filtering")
+	rootCmd.Flags().StringVar(&preserveSelector, "preserve-selector", "", "CSS selector to always preserve (can be used multiple times)")
+	rootCmd.Flags().StringArrayVar(&excludeSelectors, "exclude-selector", nil, "CSS selector whose subtree is always removed (repeatable)")
+	rootCmd.Flags().StringArrayVar(&filterHooks, "filter-hook", nil, "External command that filters the tree as JSON on stdin/stdout (repeatable, implies --content-filter)")
+
+	// Media handler flags
+	rootCmd.Flags().BoolVar(&mediaHandler, "media-handler", false, "Replace media elements with descriptive text")
+	rootCmd.Flags().BoolVar(&includeDecorative, "include-decorative", false, "Include decorative images in media processing")
+	rootCmd.Flags().BoolVar(&describeImages, "describe-images", false, "Have the language model in config.yaml describe images without alt text (implies --media-handler)")
+
+	// Markdown renderer flags
+	rootCmd.Flags().BoolVar(&markdownRenderer, "markdown-renderer", false, "Convert content tree to clean, formatted markdown")
+	rootCmd.Flags().StringVar(&emphasisStyle, "emphasis-style", "asterisk", "Emphasis style: 'asterisk' (*) or 'underscore' (_)")
+	rootCmd.Flags().StringVar(&listStyle, "list-style", "dash", "List style: 'dash' (-), 'asterisk' (*), or 'plus' (+)")
+	// Add flags to fetch command
+	fetchCmd.Flags().BoolVarP(&readerView, "reader-view", "r", false, "Extract main content and convert to clean markdown")
+	fetchCmd.Flags().BoolVar(&withComments, "with-comments", false, "Extract reader comments into a separate Comments section (with --reader-view)")
+	fetchCmd.Flags().BoolVar(&waitForFrameworks, "wait-for-frameworks", false, "Enable framework-specific readiness detection (React, Vue, Angular, Next.js, Nuxt, Svelte, SolidJS, Ember, htmx)")
+	fetchCmd.Flags().StringVar(&domReadyTimeout, "dom-ready-timeout", "5s", "Timeout for DOM readiness detection")
+	fetchCmd.Flags().StringVar(&waitForSelector, "wait-for-selector", "", "Wait for specific CSS selector to appear before extraction")
+	fetchCmd.Flags().StringVar(&waitForText, "wait-for-text", "", "Wait for specific text to appear in the page body before extraction")
+	fetchCmd.Flags().BoolVar(&waitForLayoutStable, "wait-for-layout-stable", false, "Wait until the page stops shifting and reflowing before extraction")
+	fetchCmd.Flags().BoolVar(&waitForNetworkIdle, "wait-for-network-idle", false, "Wait until the page stops starting network requests before extraction")
+	fetchCmd.Flags().StringArrayVar(&stageTimeouts, "stage-timeout", nil, "Budget for one readiness stage as stage=duration, e.g. framework=1s (repeatable; stages: dom, route, selector, text, framework, network-idle, layout)")
+	fetchCmd.Flags().BoolVar(&debugReadiness, "debug-readiness", false, "Show detailed DOM readiness detection information")
+	fetchCmd.Flags().StringVar(&readinessReport, "readiness-report", "", "Write the DOM readiness result as JSON to this file")
+	fetchCmd.Flags().StringVar(&scrollMode, "scroll", "", "Scroll before extraction to load lazy content: auto, or a number of viewports")
+	fetchCmd.Flags().IntVar(&scrollMaxHeight, "scroll-max-height", pageready.DefaultScrollMaxHeight, "Maximum number of pixels to scroll")
+	fetchCmd.Flags().StringArrayVar(&evalScripts, "eval", nil, "JavaScript to run in the page before extraction (repeatable)")
+	fetchCmd.Flags().StringVar(&actionsFile, "actions", "", "YAML file of click/type/waitFor/scroll/select steps to run before extraction")
+	fetchCmd.Flags().StringVar(&loadState, "load-state", "", "Session state file from sz login to restore cookies and localStorage")
+	fetchCmd.Flags().StringVar(&cookiesFile, "cookies", "", "Cookie file to send with requests (Netscape cookies.txt or JSON)")
+	fetchCmd.Flags().IntVar(&fetchRetries, "retries", 0, "Retry a fetch this many times after a server error, dropped connection or timeout")
+	fetchCmd.Flags().StringVar(&retryDelay, "retry-delay", "1s", "Wait before the first retry, doubled for each one after, with jitter")
+	fetchCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Accept any TLS certificate without verifying it")
+	fetchCmd.Flags().StringVar(&caCertFile, "ca-cert", "", "PEM file of certificate authorities to trust besides the system's")
+	fetchCmd.Flags().StringVar(&clientCertFile, "client-cert", "", "PEM client certificate to present to sites that require one (mutual TLS)")
+	fetchCmd.Flags().StringVar(&clientKeyFile, "client-key", "", "PEM key for --client-cert, if not in the same file")
+	fetchCmd.Flags().StringVar(&saveCookiesFile, "save-cookies", "", "Write the session's cookies to this file after fetching (JSON if it ends in .json, otherwise cookies.txt)")
+	fetchCmd.Flags().StringArrayVar(&requestHeaders, "header", nil, "Extra request header as \"Name: value\" (repeatable)")
+	fetchCmd.Flags().StringVar(&languages, "lang", "", "Languages to prefer, e.g. fr-FR or fr-FR,en (sets Accept-Language and the browser's languages)")
+	fetchCmd.Flags().StringVar(&basicAuth, "auth", "", "HTTP basic auth credentials as user:pass")
+	fetchCmd.Flags().StringVar(&userAgent, "user-agent", "", "User agent string or preset: "+strings.Join(session.UserAgentPresets(), ", "))
+	fetchCmd.Flags().StringVar(&viewportSize, "viewport", "", "Viewport size as WIDTHxHEIGHT, e.g. 1280x800")
+	fetchCmd.Flags().StringVar(&deviceName, "device", "", "Emulate a device such as \"iPhone 14\" or \"Pixel 5\"")
+	fetchCmd.Flags().StringVar(&locale, "locale", "", "Locale the page sees, e.g. de-DE (also sets Accept-Language)")
+	fetchCmd.Flags().StringVar(&timezone, "timezone", "", "Time zone the page sees, e.g. Europe/Berlin")
+	fetchCmd.Flags().StringVar(&geolocation, "geolocation", "", "Position the page sees as latitude,longitude[,accuracy]")
+	fetchCmd.Flags().BoolVar(&adblockEnabled, "adblock", false, "Block ad and tracker requests using the bundled filter list")
+	fetchCmd.Flags().StringArrayVar(&adblockLists, "adblock-list", nil, "EasyList/uBlock-style filter list file to block requests with (repeatable, implies --adblock)")
+	fetchCmd.Flags().BoolVar(&captureConsole, "capture-console", false, "Print the page's console messages and script errors to stderr")
+	fetchCmd.Flags().StringVar(&consoleLog, "console-log", "", "Write the page's console messages and script errors to this file (implies --capture-console)")
+	fetchCmd.Flags().StringVar(&harFile, "har", "", "Write the page load's requests and responses to this HAR file")
+	fetchCmd.Flags().StringVar(&downloadDir, "download-dir", "", "Save the target to this directory when it is a file download, such as a PDF, and report the path")
+	fetchCmd.Flags().StringVar(&queueTimeout, "queue-timeout", "", "How long to wait for the daemon when it is busy with other fetches, e.g. 1m (default 15s, or ESSENZ_QUEUE_TIMEOUT)")
+	fetchCmd.Flags().StringVar(&browserProfile, "profile", "", "Run in a named browser profile whose cookies and storage persist between fetches")
+	fetchCmd.Flags().BoolVar(&incognito, "incognito", false, "Run in a throwaway browser context that shares no cookies or storage with other fetches")
+	fetchCmd.Flags().BoolVar(&followCanonicalURL, "follow-canonical", false, "Re-fetch the page from the canonical URL it declares (rel=canonical or og:url) when that differs from the one given")
+	fetchCmd.Flags().BoolVar(&preferAMP, "prefer-amp", false, "Fetch the page's AMP version (rel=amphtml) instead when it has one, falling back to the page when the AMP version yields no content")
+	fetchCmd.Flags().BoolVar(&preferPrint, "prefer-print", false, "Fetch the page's print version instead when it links to one, falling back to the page when the print version yields no content")
+	fetchCmd.Flags().StringVar(&asOf, "as-of", "", "Fetch the Wayback Machine snapshot of the URL closest to this date, YYYY-MM-DD")
+	fetchCmd.Flags().BoolVar(&waybackFallback, "wayback-fallback", false, "When the page is missing, forbidden or unreachable, fetch its latest Wayback Machine snapshot instead")
+
+	// Text node tree flags for fetch command
+	fetchCmd.Flags().BoolVar(&textNodeTree, "text-node-tree", false, "Build hierarchical text node tree structure")
+	fetchCmd.Flags().StringVar(&treeFormat, "tree-format", "text", "Output format for text node tree (text, json)")
+	fetchCmd.Flags().BoolVar(&filterNavigation, "filter-navigation", false, "Filter out navigation elements from tree")
+	fetchCmd.Flags().BoolVar(&preserveAttributes, "preserve-attributes", false, "Preserve element attributes in tree structure")
+
+	// Content filter flags for fetch command
+	fetchCmd.Flags().BoolVar(&contentFilter, "content-filter", false, "Apply sophisticated content filtering to remove non-content elements")
+	fetchCmd.Flags().BoolVar(&aggressiveFiltering, "aggressive-filtering", false, "Enable more aggressive content filtering")
+	fetchCmd.Flags().StringVar(&preserveSelector, "preserve-selector", "", "CSS selector to always preserve (can be used multiple times)")
+	fetchCmd.Flags().StringArrayVar(&excludeSelectors, "exclude-selector", nil, "CSS selector whose subtree is always removed (repeatable)")
+	fetchCmd.Flags().StringArrayVar(&filterHooks, "filter-hook", nil, "External command that filters the tree as JSON on stdin/stdout (repeatable, implies --content-filter)")
+
+	// Media handler flags for fetch command
+	fetchCmd.Flags().BoolVar(&mediaHandler, "media-handler", false, "Replace media elements with descriptive text")
+	fetchCmd.Flags().BoolVar(&includeDecorative, "include-decorative", false, "Include decorative images in media processing")
+	fetchCmd.Flags().BoolVar(&describeImages, "describe-images", false, "Have the language model in config.yaml describe images without alt text (implies --media-handler)")
+
+	// Markdown renderer flags for fetch command
+	fetchCmd.Flags().BoolVar(&markdownRenderer, "markdown-renderer", false, "Convert content tree to clean, formatted markdown")
+	fetchCmd.Flags().StringVar(&emphasisStyle, "emphasis-style", "asterisk", "Emphasis style: 'asterisk' (*) or 'underscore' (_)")
+	fetchCmd.Flags().StringVar(&listStyle, "list-style", "dash", "List style: 'dash' (-), 'asterisk' (*), or 'plus' (+)")
+
+	// Empty output flags
+	rootCmd.Flags().BoolVar(&failOnEmpty, "fail-on-empty", false, "Exit with status 5 when the output has fewer than --min-words words")
+	rootCmd.Flags().IntVar(&minWords, "min-words", 20, "Fewest words --fail-on-empty accepts")
+	fetchCmd.Flags().BoolVar(&failOnEmpty, "fail-on-empty", false, "Exit with status 5 when the output has fewer than --min-words words")
+	fetchCmd.Flags().IntVar(&minWords, "min-words", 20, "Fewest words --fail-on-empty accepts")
+	batchCmd.Flags().BoolVar(&failOnEmpty, "fail-on-empty", false, "Exit with status 5 when the output has fewer than --min-words words")
+	batchCmd.Flags().IntVar(&minWords, "min-words", 20, "Fewest words --fail-on-empty accepts")
+
+	// Statistics flags
+	rootCmd.Flags().BoolVar(&showStats, "stats", false, "Print word count, reading time and other statistics for the page to stderr")
+	rootCmd.Flags().BoolVar(&showSummary, "summary", false, "Put a Summary section written by the language model in config.yaml in front of the content")
+	rootCmd.Flags().StringVar(&translateTo, "translate", "", "Translate the content into a language, e.g. de, with the backend in config.yaml")
+	rootCmd.Flags().IntVar(&chunkSize, "chunk-size", 0, "Split the content into chunks of at most this many characters, printed as JSON lines")
+	rootCmd.Flags().IntVar(&chunkOverlap, "chunk-overlap", 200, "Characters of whole paragraphs each chunk repeats from the one before, with --chunk-size")
+	rootCmd.Flags().StringVar(&outputFormat, "format", "markdown", "Output format: markdown, or llm for compact text that spends few tokens of a model's context")
+	rootCmd.Flags().BoolVar(&flattenHeadings, "flatten-headings", false, "With --format llm, replace headings with [Section > Subsection] labels")
+	rootCmd.Flags().StringVar(&exportTo, "export", "", "Save the page as a note in an app instead of printing it: obsidian")
+	rootCmd.Flags().StringVar(&vaultDir, "vault", "", "Obsidian vault folder to save the note and its images in, with --export obsidian")
+	rootCmd.Flags().StringVar(&countTokens, "count-tokens", "", "Print an estimate of the content's tokens for a model, e.g. gpt-4 or claude, to stderr")
+	rootCmd.Flags().Lookup("count-tokens").NoOptDefVal = tokens.DefaultModel
+
+	// Preset flags
+	rootCmd.Flags().StringVar(&presetName, "preset", "", "Apply a named bundle of flags from the presets section of the config")
+	fetchCmd.Flags().StringVar(&presetName, "preset", "", "Apply a named bundle of flags from the presets section of the config")
+	batchCmd.Flags().StringVar(&presetName, "preset", "", "Apply a named bundle of flags from the presets section of the config")
+
+	// Output flags
+	rootCmd.Flags().StringVarP(&outputTemplate, "output", "o", "", "Write the result to this file instead of stdout; may be a template such as '{{.Host}}/{{.Slug}}.md'")
+	rootCmd.Flags().StringVar(&ifExists, "if-exists", "overwrite", "When the --output file, or a --split-by file, exists: overwrite, skip, or error")
+	rootCmd.Flags().StringVar(&splitBy, "split-by", "", "Write each top-level section to its own numbered file, splitting at h1 or h2 headings")
+	rootCmd.Flags().StringVar(&splitDir, "output-dir", "", "Directory --split-by writes its files to (default the current directory)")
+	fetchCmd.Flags().StringVarP(&outputTemplate, "output", "o", "", "Write the result to this file instead of stdout; may be a template such as '{{.Host}}/{{.Slug}}.md'")
+	fetchCmd.Flags().StringVar(&ifExists, "if-exists", "overwrite", "When the --output file exists: overwrite, skip, or error")
+	batchCmd.Flags().StringVarP(&outputTemplate, "output", "o", "", "Template for each target's file, e.g. '{{.Host}}/{{.Path}}.md' (fields: Host, Path, Slug, Index, Date)")
+	batchCmd.Flags().StringVar(&ifExists, "if-exists", "overwrite", "When a target's file exists: overwrite, skip (without fetching), or error")
+
+	// Batch command flags
+	batchCmd.Flags().StringVar(&batchOutputDir, "output-dir", "", "Write each target's result to its own markdown file in this directory")
+	batchCmd.Flags().IntVar(&batchConcurrency, "concurrency", 0, "Targets to fetch at once (default one per CPU)")
+	batchCmd.Flags().IntVar(&batchPerHost, "per-host", batch.DefaultPerHost, "Targets to fetch at once from the same host")
+	batchCmd.Flags().BoolVar(&batchAsCompleted, "as-completed", false, "Write results as they finish instead of in list order")
+	batchCmd.Flags().BoolVar(&jsonlOutput, "jsonl", false, "Write one JSON object per target with its URL, title, markdown, metadata and any error, as JSON lines")
+	batchCmd.Flags().BoolVar(&rawOutput, "raw", false, "Output raw HTML without reader view processing")
+	batchCmd.Flags().BoolVar(&withComments, "with-comments", false, "Extract reader comments into a separate Comments section")
+	batchCmd.Flags().StringVar(&waitForSelector, "wait-for-selector", "", "Wait for specific CSS selector to appear before extraction")
+	batchCmd.Flags().StringArrayVar(&requestHeaders, "header", nil, "Extra request header as \"Name: value\" (repeatable)")
+	batchCmd.Flags().StringVar(&languages, "lang", "", "Languages to prefer, e.g. fr-FR or fr-FR,en (sets Accept-Language and the browser's languages)")
+	batchCmd.Flags().StringVar(&basicAuth, "auth", "", "HTTP basic auth credentials as user:pass")
+	batchCmd.Flags().StringVar(&userAgent, "user-agent", "", "User agent string or preset: "+strings.Join(session.UserAgentPresets(), ", "))
+	batchCmd.Flags().StringVar(&cookiesFile, "cookies", "", "Cookie file to send with requests (Netscape cookies.txt or JSON)")
+	batchCmd.Flags().IntVar(&fetchRetries, "retries", 0, "Retry a fetch this many times after a server error, dropped connection or timeout")
+	batchCmd.Flags().StringVar(&retryDelay, "retry-delay", "1s", "Wait before the first retry, doubled for each one after, with jitter")
+	batchCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Accept any TLS certificate without verifying it")
+	batchCmd.Flags().StringVar(&caCertFile, "ca-cert", "", "PEM file of certificate authorities to trust besides the system's")
+	batchCmd.Flags().StringVar(&clientCertFile, "client-cert", "", "PEM client certificate to present to sites that require one (mutual TLS)")
+	batchCmd.Flags().StringVar(&clientKeyFile, "client-key", "", "PEM key for --client-cert, if not in the same file")
+	batchCmd.Flags().BoolVar(&adblockEnabled, "adblock", false, "Block ad and tracker requests using the bundled filter list")
+	batchCmd.Flags().StringVar(&queueTimeout, "queue-timeout", "", "How long to wait for the daemon when it is busy with other fetches, e.g. 1m (default 15s, or ESSENZ_QUEUE_TIMEOUT)")
+	batchCmd.Flags().StringVar(&browserProfile, "profile", "", "Run in a named browser profile whose cookies and storage persist between fetches")
+	batchCmd.Flags().BoolVar(&incognito, "incognito", false, "Run in a throwaway browser context that shares no cookies or storage with other fetches")
+
+	// Sitemap command flags
+	sitemapCmd.Flags().StringArrayVar(&sitemapInclude, "include", nil, "Only distill pages whose URL matches this regular expression (repeatable)")
+	sitemapCmd.Flags().StringArrayVar(&sitemapExclude, "exclude", nil, "Skip pages whose URL matches this regular expression (repeatable)")
+	sitemapCmd.Flags().StringVar(&sitemapSince, "since", "", "Only distill pages last modified on or after this date (YYYY-MM-DD)")
+	sitemapCmd.Flags().StringVar(&sitemapUntil, "until", "", "Only distill pages last modified on or before this date (YYYY-MM-DD)")
+	sitemapCmd.Flags().IntVar(&sitemapLimit, "limit", 0, "Distill at most this many pages (0 for no limit)")
+	sitemapCmd.Flags().BoolVar(&sitemapList, "list", false, "Print the selected URLs instead of distilling them")
+	sitemapCmd.Flags().StringVarP(&outputTemplate, "output", "o", "", "Template for each target's file, e.g. '{{.Host}}/{{.Path}}.md' (fields: Host, Path, Slug, Index, Date)")
+	sitemapCmd.Flags().StringVar(&ifExists, "if-exists", "overwrite", "When a target's file exists: overwrite, skip (without fetching), or error")
+	sitemapCmd.Flags().StringVar(&batchOutputDir, "output-dir", "", "Write each target's result to its own markdown file in this directory")
+	sitemapCmd.Flags().IntVar(&batchConcurrency, "concurrency", 0, "Targets to fetch at once (default one per CPU)")
+	sitemapCmd.Flags().IntVar(&batchPerHost, "per-host", batch.DefaultPerHost, "Targets to fetch at once from the same host")
+	sitemapCmd.Flags().BoolVar(&batchAsCompleted, "as-completed", false, "Write results as they finish instead of in list order")
+	sitemapCmd.Flags().BoolVar(&jsonlOutput, "jsonl", false, "Write one JSON object per page with its URL, title, markdown, metadata and any error, as JSON lines")
+	sitemapCmd.Flags().BoolVar(&rawOutput, "raw", false, "Output raw HTML without reader view processing")
+	sitemapCmd.Flags().BoolVar(&withComments, "with-comments", false, "Extract reader comments into a separate Comments section")
+	sitemapCmd.Flags().StringVar(&waitForSelector, "wait-for-selector", "", "Wait for specific CSS selector to appear before extraction")
+	sitemapCmd.Flags().StringArrayVar(&requestHeaders, "header", nil, "Extra request header as \"Name: value\" (repeatable)")
+	sitemapCmd.Flags().StringVar(&languages, "lang", "", "Languages to prefer, e.g. fr-FR or fr-FR,en (sets Accept-Language and the browser's languages)")
+	sitemapCmd.Flags().StringVar(&basicAuth, "auth", "", "HTTP basic auth credentials as user:pass")
+	sitemapCmd.Flags().StringVar(&userAgent, "user-agent", "", "User agent string or preset: "+strings.Join(session.UserAgentPresets(), ", "))
+	sitemapCmd.Flags().StringVar(&cookiesFile, "cookies", "", "Cookie file to send with requests (Netscape cookies.txt or JSON)")
+	sitemapCmd.Flags().IntVar(&fetchRetries, "retries", 0, "Retry a fetch this many times after a server error, dropped connection or timeout")
+	sitemapCmd.Flags().StringVar(&retryDelay, "retry-delay", "1s", "Wait before the first retry, doubled for each one after, with jitter")
+	sitemapCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Accept any TLS certificate without verifying it")
+	sitemapCmd.Flags().StringVar(&caCertFile, "ca-cert", "", "PEM file of certificate authorities to trust besides the system's")
+	sitemapCmd.Flags().StringVar(&clientCertFile, "client-cert", "", "PEM client certificate to present to sites that require one (mutual TLS)")
+	sitemapCmd.Flags().StringVar(&clientKeyFile, "client-key", "", "PEM key for --client-cert, if not in the same file")
+	sitemapCmd.Flags().BoolVar(&adblockEnabled, "adblock", false, "Block ad and tracker requests using the bundled filter list")
+	sitemapCmd.Flags().StringVar(&queueTimeout, "queue-timeout", "", "How long to wait for the daemon when it is busy with other fetches, e.g. 1m (default 15s, or ESSENZ_QUEUE_TIMEOUT)")
+	sitemapCmd.Flags().StringVar(&browserProfile, "profile", "", "Run in a named browser profile whose cookies and storage persist between fetches")
+	sitemapCmd.Flags().BoolVar(&incognito, "incognito", false, "Run in a throwaway browser context that shares no cookies or storage with other fetches")
+	sitemapCmd.Flags().BoolVar(&failOnEmpty, "fail-on-empty", false, "Exit with status 5 when the output has fewer than --min-words words")
+	sitemapCmd.Flags().IntVar(&minWords, "min-words", 20, "Fewest words --fail-on-empty accepts")
+	sitemapCmd.Flags().StringVar(&presetName, "preset", "", "Apply a named bundle of flags from the presets section of the config")
+
+	// Feed command flags
+	feedCmd.Flags().IntVar(&feedLimit, "limit", 0, "Distill at most this many entries, in feed order (0 for all)")
+	feedCmd.Flags().StringVar(&feedSince, "since", "", "Only distill entries published on or after this date (YYYY-MM-DD)")
+	feedCmd.Flags().BoolVar(&feedFromFeed, "from-feed", false, "Distill the content included in the feed instead of fetching each page")
+	feedCmd.Flags().StringVarP(&outputTemplate, "output", "o", "", "Template for each article's file, e.g. '{{.Host}}/{{.Path}}.md' (fields: Host, Path, Slug, Index, Date)")
+	feedCmd.Flags().StringVar(&ifExists, "if-exists", "overwrite", "When a target's file exists: overwrite, skip (without fetching), or error")
+	feedCmd.Flags().StringVar(&batchOutputDir, "output-dir", "", "Write each article to its own markdown file in this directory instead of a digest")
+	feedCmd.Flags().IntVar(&batchConcurrency, "concurrency", 0, "Targets to fetch at once (default one per CPU)")
+	feedCmd.Flags().IntVar(&batchPerHost, "per-host", batch.DefaultPerHost, "Targets to fetch at once from the same host")
+	feedCmd.Flags().BoolVar(&batchAsCompleted, "as-completed", false, "Write results as they finish instead of in list order")
+	feedCmd.Flags().BoolVar(&jsonlOutput, "jsonl", false, "Write one JSON object per article with its URL, title, markdown, metadata and any error, as JSON lines")
+	feedCmd.Flags().BoolVar(&rawOutput, "raw", false, "Output raw HTML without reader view processing")
+	feedCmd.Flags().BoolVar(&withComments, "with-comments", false, "Extract reader comments into a separate Comments section")
+	feedCmd.Flags().StringVar(&waitForSelector, "wait-for-selector", "", "Wait for specific CSS selector to appear before extraction")
+	feedCmd.Flags().StringArrayVar(&requestHeaders, "header", nil, "Extra request header as \"Name: value\" (repeatable)")
+	feedCmd.Flags().StringVar(&languages, "lang", "", "Languages to prefer, e.g. fr-FR or fr-FR,en (sets Accept-Language and the browser's languages)")
+	feedCmd.Flags().StringVar(&basicAuth, "auth", "", "HTTP basic auth credentials as user:pass")
+	feedCmd.Flags().StringVar(&userAgent, "user-agent", "", "User agent string or preset: "+strings.Join(session.UserAgentPresets(), ", "))
+	feedCmd.Flags().StringVar(&cookiesFile, "cookies", "", "Cookie file to send with requests (Netscape cookies.txt or JSON)")
+	feedCmd.Flags().IntVar(&fetchRetries, "retries", 0, "Retry a fetch this many times after a server error, dropped connection or timeout")
+	feedCmd.Flags().StringVar(&retryDelay, "retry-delay", "1s", "Wait before the first retry, doubled for each one after, with jitter")
+	feedCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Accept any TLS certificate without verifying it")
+	feedCmd.Flags().StringVar(&caCertFile, "ca-cert", "", "PEM file of certificate authorities to trust besides the system's")
+	feedCmd.Flags().StringVar(&clientCertFile, "client-cert", "", "PEM client certificate to present to sites that require one (mutual TLS)")
+	feedCmd.Flags().StringVar(&clientKeyFile, "client-key", "", "PEM key for --client-cert, if not in the same file")
+	feedCmd.Flags().BoolVar(&adblockEnabled, "adblock", false, "Block ad and tracker requests using the bundled filter list")
+	feedCmd.Flags().StringVar(&queueTimeout, "queue-timeout", "", "How long to wait for the daemon when it is busy with other fetches, e.g. 1m (default 15s, or ESSENZ_QUEUE_TIMEOUT)")
+	feedCmd.Flags().StringVar(&browserProfile, "profile", "", "Run in a named browser profile whose cookies and storage persist between fetches")
+	feedCmd.Flags().BoolVar(&incognito, "incognito", false, "Run in a throwaway browser context that shares no cookies or storage with other fetches")
+	feedCmd.Flags().BoolVar(&failOnEmpty, "fail-on-empty", false, "Exit with status 5 when the output has fewer than --min-words words")
+	feedCmd.Flags().IntVar(&minWords, "min-words", 20, "Fewest words --fail-on-empty accepts")
+	feedCmd.Flags().StringVar(&presetName, "preset", "", "Apply a named bundle of flags from the presets section of the config")
+
+	// Meta command flags
+	metaCmd.Flags().BoolVar(&metaChrome, "chrome", false, "Always render the page in Chrome instead of fetching it over HTTP")
+	metaCmd.Flags().StringArrayVar(&requestHeaders, "header", nil, "Extra request header as \"Name: value\" (repeatable)")
+	metaCmd.Flags().StringVar(&languages, "lang", "", "Languages to prefer, e.g. fr-FR or fr-FR,en (sets Accept-Language and the browser's languages)")
+	metaCmd.Flags().StringVar(&basicAuth, "auth", "", "HTTP basic auth credentials as user:pass")
+	metaCmd.Flags().StringVar(&userAgent, "user-agent", "", "User agent string or preset: "+strings.Join(session.UserAgentPresets(), ", "))
+	metaCmd.Flags().StringVar(&cookiesFile, "cookies", "", "Cookie file to send with requests (Netscape cookies.txt or JSON)")
+	metaCmd.Flags().IntVar(&fetchRetries, "retries", 0, "Retry a fetch this many times after a server error, dropped connection or timeout")
+	metaCmd.Flags().StringVar(&retryDelay, "retry-delay", "1s", "Wait before the first retry, doubled for each one after, with jitter")
+	metaCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Accept any TLS certificate without verifying it")
+	metaCmd.Flags().StringVar(&caCertFile, "ca-cert", "", "PEM file of certificate authorities to trust besides the system's")
+	metaCmd.Flags().StringVar(&clientCertFile, "client-cert", "", "PEM client certificate to present to sites that require one (mutual TLS)")
+	metaCmd.Flags().StringVar(&clientKeyFile, "client-key", "", "PEM key for --client-cert, if not in the same file")
+
+	// Cite command flags
+	citeCmd.Flags().StringVar(&citeStyle, "style", "apa", "Citation style: "+strings.Join(cite.Styles, ", "))
+	citeCmd.Flags().StringVar(&citeAccessed, "accessed", "", "Date the page was accessed, YYYY-MM-DD (default today)")
+	citeCmd.Flags().BoolVar(&metaChrome, "chrome", false, "Always render the page in Chrome instead of fetching it over HTTP")
+	citeCmd.Flags().StringArrayVar(&requestHeaders, "header", nil, "Extra request header as \"Name: value\" (repeatable)")
+	citeCmd.Flags().StringVar(&languages, "lang", "", "Languages to prefer, e.g. fr-FR or fr-FR,en (sets Accept-Language and the browser's languages)")
+	citeCmd.Flags().StringVar(&basicAuth, "auth", "", "HTTP basic auth credentials as user:pass")
+	citeCmd.Flags().StringVar(&userAgent, "user-agent", "", "User agent string or preset: "+strings.Join(session.UserAgentPresets(), ", "))
+	citeCmd.Flags().StringVar(&cookiesFile, "cookies", "", "Cookie file to send with requests (Netscape cookies.txt or JSON)")
+	citeCmd.Flags().IntVar(&fetchRetries, "retries", 0, "Retry a fetch this many times after a server error, dropped connection or timeout")
+	citeCmd.Flags().StringVar(&retryDelay, "retry-delay", "1s", "Wait before the first retry, doubled for each one after, with jitter")
+	citeCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Accept any TLS certificate without verifying it")
+	citeCmd.Flags().StringVar(&caCertFile, "ca-cert", "", "PEM file of certificate authorities to trust besides the system's")
+	citeCmd.Flags().StringVar(&clientCertFile, "client-cert", "", "PEM client certificate to present to sites that require one (mutual TLS)")
+	citeCmd.Flags().StringVar(&clientKeyFile, "client-key", "", "PEM key for --client-cert, if not in the same file")
+
+	// Merge command flags
+	mergeCmd.Flags().StringVar(&mergeTitle, "title", "", "Heading of the merged document (default \"Digest\" and today's date)")
+	mergeCmd.Flags().StringVarP(&outputTemplate, "output", "o", "", "Write the merged document to this file instead of stdout")
+	mergeCmd.Flags().StringVar(&ifExists, "if-exists", "overwrite", "When the --output file exists: overwrite, skip, or error")
+	mergeCmd.Flags().IntVar(&batchConcurrency, "concurrency", 0, "Pages to fetch at once (default one per CPU)")
+	mergeCmd.Flags().IntVar(&batchPerHost, "per-host", batch.DefaultPerHost, "Pages to fetch at once from the same host")
+	mergeCmd.Flags().BoolVar(&withComments, "with-comments", false, "Extract reader comments into a separate Comments section")
+	mergeCmd.Flags().StringArrayVar(&requestHeaders, "header", nil, "Extra request header as \"Name: value\" (repeatable)")
+	mergeCmd.Flags().StringVar(&languages, "lang", "", "Languages to prefer, e.g. fr-FR or fr-FR,en (sets Accept-Language and the browser's languages)")
+	mergeCmd.Flags().StringVar(&basicAuth, "auth", "", "HTTP basic auth credentials as user:pass")
+	mergeCmd.Flags().StringVar(&userAgent, "user-agent", "", "User agent string or preset: "+strings.Join(session.UserAgentPresets(), ", "))
+	mergeCmd.Flags().StringVar(&cookiesFile, "cookies", "", "Cookie file to send with requests (Netscape cookies.txt or JSON)")
+	mergeCmd.Flags().IntVar(&fetchRetries, "retries", 0, "Retry a fetch this many times after a server error, dropped connection or timeout")
+	mergeCmd.Flags().StringVar(&retryDelay, "retry-delay", "1s", "Wait before the first retry, doubled for each one after, with jitter")
+	mergeCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Accept any TLS certificate without verifying it")
+	mergeCmd.Flags().StringVar(&caCertFile, "ca-cert", "", "PEM file of certificate authorities to trust besides the system's")
+	mergeCmd.Flags().StringVar(&clientCertFile, "client-cert", "", "PEM client certificate to present to sites that require one (mutual TLS)")
+	mergeCmd.Flags().StringVar(&clientKeyFile, "client-key", "", "PEM key for --client-cert, if not in the same file")
+
+	// Tables command flags
+	tablesCmd.Flags().StringVar(&tablesFormat, "format", "csv", "Output format: csv, tsv, or json")
+	tablesCmd.Flags().IntVar(&tablesIndex, "table", 0, "Only output the Nth table (1-based)")
+	tablesCmd.Flags().StringVar(&tablesOutputDir, "output-dir", "", "Write each table to its own file in this directory")
+	tablesCmd.Flags().StringVar(&waitForSelector, "wait-for-selector", "", "Wait for specific CSS selector to appear before extraction")
+	tablesCmd.Flags().StringArrayVar(&requestHeaders, "header", nil, "Extra request header as \"Name: value\" (repeatable)")
+	tablesCmd.Flags().StringVar(&languages, "lang", "", "Languages to prefer, e.g. fr-FR or fr-FR,en (sets Accept-Language and the browser's languages)")
+	tablesCmd.Flags().StringVar(&basicAuth, "auth", "", "HTTP basic auth credentials as user:pass")
+	tablesCmd.Flags().StringVar(&userAgent, "user-agent", "", "User agent string or preset: "+strings.Join(session.UserAgentPresets(), ", "))
+	tablesCmd.Flags().StringVar(&cookiesFile, "cookies", "", "Cookie file to send with requests (Netscape cookies.txt or JSON)")
+	tablesCmd.Flags().IntVar(&fetchRetries, "retries", 0, "Retry a fetch this many times after a server error, dropped connection or timeout")
+	tablesCmd.Flags().StringVar(&retryDelay, "retry-delay", "1s", "Wait before the first retry, doubled for each one after, with jitter")
+	tablesCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Accept any TLS certificate without verifying it")
+	tablesCmd.Flags().StringVar(&caCertFile, "ca-cert", "", "PEM file of certificate authorities to trust besides the system's")
+	tablesCmd.Flags().StringVar(&clientCertFile, "client-cert", "", "PEM client certificate to present to sites that require one (mutual TLS)")
+	tablesCmd.Flags().StringVar(&clientKeyFile, "client-key", "", "PEM key for --client-cert, if not in the same file")
+	tablesCmd.Flags().BoolVar(&adblockEnabled, "adblock", false, "Block ad and tracker requests using the bundled filter list")
+	tablesCmd.Flags().StringVar(&queueTimeout, "queue-timeout", "", "How long to wait for the daemon when it is busy with other fetches, e.g. 1m (default 15s, or ESSENZ_QUEUE_TIMEOUT)")
+	tablesCmd.Flags().StringVar(&browserProfile, "profile", "", "Run in a named browser profile whose cookies and storage persist between fetches")
+	tablesCmd.Flags().BoolVar(&incognito, "incognito", false, "Run in a throwaway browser context that shares no cookies or storage with other fetches")
+
+	// Images command flags
+	imagesCmd.Flags().StringVar(&imagesFormat, "format", "text", "Output format: text or json")
+	imagesCmd.Flags().StringVar(&waitForSelector, "wait-for-selector", "", "Wait for specific CSS selector to appear before extraction")
+	imagesCmd.Flags().StringArrayVar(&requestHeaders, "header", nil, "Extra request header as \"Name: value\" (repeatable)")
+	imagesCmd.Flags().StringVar(&languages, "lang", "", "Languages to prefer, e.g. fr-FR or fr-FR,en (sets Accept-Language and the browser's languages)")
+	imagesCmd.Flags().StringVar(&basicAuth, "auth", "", "HTTP basic auth credentials as user:pass")
+	imagesCmd.Flags().StringVar(&userAgent, "user-agent", "", "User agent string or preset: "+strings.Join(session.UserAgentPresets(), ", "))
+	imagesCmd.Flags().StringVar(&cookiesFile, "cookies", "", "Cookie file to send with requests (Netscape cookies.txt or JSON)")
+	imagesCmd.Flags().IntVar(&fetchRetries, "retries", 0, "Retry a fetch this many times after a server error, dropped connection or timeout")
+	imagesCmd.Flags().StringVar(&retryDelay, "retry-delay", "1s", "Wait before the first retry, doubled for each one after, with jitter")
+	imagesCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Accept any TLS certificate without verifying it")
+	imagesCmd.Flags().StringVar(&caCertFile, "ca-cert", "", "PEM file of certificate authorities to trust besides the system's")
+	imagesCmd.Flags().StringVar(&clientCertFile, "client-cert", "", "PEM client certificate to present to sites that require one (mutual TLS)")
+	imagesCmd.Flags().StringVar(&clientKeyFile, "client-key", "", "PEM key for --client-cert, if not in the same file")
+	imagesCmd.Flags().BoolVar(&adblockEnabled, "adblock", false, "Block ad and tracker requests using the bundled filter list")
+	imagesCmd.Flags().StringVar(&queueTimeout, "queue-timeout", "", "How long to wait for the daemon when it is busy with other fetches, e.g. 1m (default 15s, or ESSENZ_QUEUE_TIMEOUT)")
+	imagesCmd.Flags().StringVar(&browserProfile, "profile", "", "Run in a named browser profile whose cookies and storage persist between fetches")
+	imagesCmd.Flags().BoolVar(&incognito, "incognito", false, "Run in a throwaway browser context that shares no cookies or storage with other fetches")
+
+	// Select command flags
+	selectCmd.Flags().StringVar(&selectSelector, "selector", "", "CSS selector for the elements to render (required)")
+	selectCmd.Flags().BoolVar(&selectFirst, "first", false, "Render only the first matching element")
+	_ = selectCmd.MarkFlagRequired("selector")
+	selectCmd.Flags().StringVar(&waitForSelector, "wait-for-selector", "", "Wait for specific CSS selector to appear before extraction")
+	selectCmd.Flags().StringArrayVar(&requestHeaders, "header", nil, "Extra request header as \"Name: value\" (repeatable)")
+	selectCmd.Flags().StringVar(&languages, "lang", "", "Languages to prefer, e.g. fr-FR or fr-FR,en (sets Accept-Language and the browser's languages)")
+	selectCmd.Flags().StringVar(&basicAuth, "auth", "", "HTTP basic auth credentials as user:pass")
+	selectCmd.Flags().StringVar(&userAgent, "user-agent", "", "User agent string or preset: "+strings.Join(session.UserAgentPresets(), ", "))
+	selectCmd.Flags().StringVar(&cookiesFile, "cookies", "", "Cookie file to send with requests (Netscape cookies.txt or JSON)")
+	selectCmd.Flags().IntVar(&fetchRetries, "retries", 0, "Retry a fetch this many times after a server error, dropped connection or timeout")
+	selectCmd.Flags().StringVar(&retryDelay, "retry-delay", "1s", "Wait before the first retry, doubled for each one after, with jitter")
+	selectCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Accept any TLS certificate without verifying it")
+	selectCmd.Flags().StringVar(&caCertFile, "ca-cert", "", "PEM file of certificate authorities to trust besides the system's")
+	selectCmd.Flags().StringVar(&clientCertFile, "client-cert", "", "PEM client certificate to present to sites that require one (mutual TLS)")
+	selectCmd.Flags().StringVar(&clientKeyFile, "client-key", "", "PEM key for --client-cert, if not in the same file")
+	selectCmd.Flags().BoolVar(&adblockEnabled, "adblock", false, "Block ad and tracker requests using the bundled filter list")
+	selectCmd.Flags().StringVar(&queueTimeout, "queue-timeout", "", "How long to wait for the daemon when it is busy with other fetches, e.g. 1m (default 15s, or ESSENZ_QUEUE_TIMEOUT)")
+	selectCmd.Flags().StringVar(&browserProfile, "profile", "", "Run in a named browser profile whose cookies and storage persist between fetches")
+	selectCmd.Flags().BoolVar(&incognito, "incognito", false, "Run in a throwaway browser context that shares no cookies or storage with other fetches")
+
+	// Grep command flags
+	grepCmd.Flags().BoolVarP(&grepIgnoreCase, "ignore-case", "i", false, "Match without regard to case")
+	grepCmd.Flags().BoolVarP(&grepFixed, "fixed-strings", "F", false, "Treat PATTERN as plain text rather than a regular expression")
+	grepCmd.Flags().BoolVarP(&grepCount, "count", "c", false, "Print only the number of matching lines")
+	grepCmd.Flags().BoolVarP(&grepListOnly, "files-with-matches", "l", false, "Print only the targets that have a match")
+	grepCmd.Flags().BoolVar(&rawOutput, "raw", false, "Search the raw HTML without reader view processing")
+	grepCmd.Flags().BoolVar(&withComments, "with-comments", false, "Extract reader comments into a separate Comments section")
+	grepCmd.Flags().StringVar(&waitForSelector, "wait-for-selector", "", "Wait for specific CSS selector to appear before extraction")
+	grepCmd.Flags().StringArrayVar(&requestHeaders, "header", nil, "Extra request header as \"Name: value\" (repeatable)")
+	grepCmd.Flags().StringVar(&languages, "lang", "", "Languages to prefer, e.g. fr-FR or fr-FR,en (sets Accept-Language and the browser's languages)")
+	grepCmd.Flags().StringVar(&basicAuth, "auth", "", "HTTP basic auth credentials as user:pass")
+	grepCmd.Flags().StringVar(&userAgent, "user-agent", "", "User agent string or preset: "+strings.Join(session.UserAgentPresets(), ", "))
+	grepCmd.Flags().StringVar(&cookiesFile, "cookies", "", "Cookie file to send with requests (Netscape cookies.txt or JSON)")
+	grepCmd.Flags().IntVar(&fetchRetries, "retries", 0, "Retry a fetch this many times after a server error, dropped connection or timeout")
+	grepCmd.Flags().StringVar(&retryDelay, "retry-delay", "1s", "Wait before the first retry, doubled for each one after, with jitter")
+	grepCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Accept any TLS certificate without verifying it")
+	grepCmd.Flags().StringVar(&caCertFile, "ca-cert", "", "PEM file of certificate authorities to trust besides the system's")
+	grepCmd.Flags().StringVar(&clientCertFile, "client-cert", "", "PEM client certificate to present to sites that require one (mutual TLS)")
+	grepCmd.Flags().StringVar(&clientKeyFile, "client-key", "", "PEM key for --client-cert, if not in the same file")
+	grepCmd.Flags().BoolVar(&adblockEnabled, "adblock", false, "Block ad and tracker requests using the bundled filter list")
+	grepCmd.Flags().StringVar(&queueTimeout, "queue-timeout", "", "How long to wait for the daemon when it is busy with other fetches, e.g. 1m (default 15s, or ESSENZ_QUEUE_TIMEOUT)")
+	grepCmd.Flags().StringVar(&browserProfile, "profile", "", "Run in a named browser profile whose cookies and storage persist between fetches")
+	grepCmd.Flags().BoolVar(&incognito, "incognito", false, "Run in a throwaway browser context that shares no cookies or storage with other fetches")
+
+	// Stats command flags
+	statsCmd.Flags().StringVar(&statsFormat, "format", "text", "Output format: text or json")
+	statsCmd.Flags().BoolVar(&aggressiveFiltering, "aggressive-filtering", false, "Report the removals of aggressive content filtering")
+	statsCmd.Flags().StringVar(&waitForSelector, "wait-for-selector", "", "Wait for specific CSS selector to appear before extraction")
+	statsCmd.Flags().StringArrayVar(&requestHeaders, "header", nil, "Extra request header as \"Name: value\" (repeatable)")
+	statsCmd.Flags().StringVar(&languages, "lang", "", "Languages to prefer, e.g. fr-FR or fr-FR,en (sets Accept-Language and the browser's languages)")
+	statsCmd.Flags().StringVar(&basicAuth, "auth", "", "HTTP basic auth credentials as user:pass")
+	statsCmd.Flags().StringVar(&userAgent, "user-agent", "", "User agent string or preset: "+strings.Join(session.UserAgentPresets(), ", "))
+	statsCmd.Flags().StringVar(&cookiesFile, "cookies", "", "Cookie file to send with requests (Netscape cookies.txt or JSON)")
+	statsCmd.Flags().IntVar(&fetchRetries, "retries", 0, "Retry a fetch this many times after a server error, dropped connection or timeout")
+	statsCmd.Flags().StringVar(&retryDelay, "retry-delay", "1s", "Wait before the first retry, doubled for each one after, with jitter")
+	statsCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Accept any TLS certificate without verifying it")
+	statsCmd.Flags().StringVar(&caCertFile, "ca-cert", "", "PEM file of certificate authorities to trust besides the system's")
+	statsCmd.Flags().StringVar(&clientCertFile, "client-cert", "", "PEM client certificate to present to sites that require one (mutual TLS)")
+	statsCmd.Flags().StringVar(&clientKeyFile, "client-key", "", "PEM key for --client-cert, if not in the same file")
+	statsCmd.Flags().BoolVar(&adblockEnabled, "adblock", false, "Block ad and tracker requests using the bundled filter list")
+	statsCmd.Flags().StringVar(&queueTimeout, "queue-timeout", "", "How long to wait for the daemon when it is busy with other fetches, e.g. 1m (default 15s, or ESSENZ_QUEUE_TIMEOUT)")
+	statsCmd.Flags().StringVar(&browserProfile, "profile", "", "Run in a named browser profile whose cookies and storage persist between fetches")
+	statsCmd.Flags().BoolVar(&incognito, "incognito", false, "Run in a throwaway browser context that shares no cookies or storage with other fetches")
+
+	// Summarize command flags
+	summarizeCmd.Flags().BoolVar(&withComments, "with-comments", false, "Extract reader comments into a separate Comments section")
+	summarizeCmd.Flags().StringVar(&waitForSelector, "wait-for-selector", "", "Wait for specific CSS selector to appear before extraction")
+	summarizeCmd.Flags().StringArrayVar(&requestHeaders, "header", nil, "Extra request header as \"Name: value\" (repeatable)")
+	summarizeCmd.Flags().StringVar(&languages, "lang", "", "Languages to prefer, e.g. fr-FR or fr-FR,en (sets Accept-Language and the browser's languages)")
+	summarizeCmd.Flags().StringVar(&basicAuth, "auth", "", "HTTP basic auth credentials as user:pass")
+	summarizeCmd.Flags().StringVar(&userAgent, "user-agent", "", "User agent string or preset: "+strings.Join(session.UserAgentPresets(), ", "))
+	summarizeCmd.Flags().StringVar(&cookiesFile, "cookies", "", "Cookie file to send with requests (Netscape cookies.txt or JSON)")
+	summarizeCmd.Flags().IntVar(&fetchRetries, "retries", 0, "Retry a fetch this many times after a server error, dropped connection or timeout")
+	summarizeCmd.Flags().StringVar(&retryDelay, "retry-delay", "1s", "Wait before the first retry, doubled for each one after, with jitter")
+	summarizeCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Accept any TLS certificate without verifying it")
+	summarizeCmd.Flags().StringVar(&caCertFile, "ca-cert", "", "PEM file of certificate authorities to trust besides the system's")
+	summarizeCmd.Flags().StringVar(&clientCertFile, "client-cert", "", "PEM client certificate to present to sites that require one (mutual TLS)")
+	summarizeCmd.Flags().StringVar(&clientKeyFile, "client-key", "", "PEM key for --client-cert, if not in the same file")
+	summarizeCmd.Flags().BoolVar(&adblockEnabled, "adblock", false, "Block ad and tracker requests using the bundled filter list")
+	summarizeCmd.Flags().StringVar(&queueTimeout, "queue-timeout", "", "How long to wait for the daemon when it is busy with other fetches, e.g. 1m (default 15s, or ESSENZ_QUEUE_TIMEOUT)")
+	summarizeCmd.Flags().StringVar(&browserProfile, "profile", "", "Run in a named browser profile whose cookies and storage persist between fetches")
+	summarizeCmd.Flags().BoolVar(&incognito, "incognito", false, "Run in a throwaway browser context that shares no cookies or storage with other fetches")
+
+	// Push command flags
+	pushCmd.Flags().StringArrayVar(&pushTags, "tag", nil, "Tag to file the page under, for Pocket and Readwise (repeatable)")
+	pushCmd.Flags().StringArrayVar(&pushHighlights, "highlight", nil, "Passage to save to Readwise as a highlight of the page, instead of the page (repeatable)")
+	pushCmd.Flags().BoolVar(&withComments, "with-comments", false, "Extract reader comments into a separate Comments section")
+	pushCmd.Flags().StringVar(&waitForSelector, "wait-for-selector", "", "Wait for specific CSS selector to appear before extraction")
+	pushCmd.Flags().StringArrayVar(&requestHeaders, "header", nil, "Extra request header as \"Name: value\" (repeatable)")
+	pushCmd.Flags().StringVar(&languages, "lang", "", "Languages to prefer, e.g. fr-FR or fr-FR,en (sets Accept-Language and the browser's languages)")
+	pushCmd.Flags().StringVar(&basicAuth, "auth", "", "HTTP basic auth credentials as user:pass")
+	pushCmd.Flags().StringVar(&userAgent, "user-agent", "", "User agent string or preset: "+strings.Join(session.UserAgentPresets(), ", "))
+	pushCmd.Flags().StringVar(&cookiesFile, "cookies", "", "Cookie file to send with requests (Netscape cookies.txt or JSON)")
+	pushCmd.Flags().IntVar(&fetchRetries, "retries", 0, "Retry a fetch this many times after a server error, dropped connection or timeout")
+	pushCmd.Flags().StringVar(&retryDelay, "retry-delay", "1s", "Wait before the first retry, doubled for each one after, with jitter")
+	pushCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Accept any TLS certificate without verifying it")
+	pushCmd.Flags().StringVar(&caCertFile, "ca-cert", "", "PEM file of certificate authorities to trust besides the system's")
+	pushCmd.Flags().StringVar(&clientCertFile, "client-cert", "", "PEM client certificate to present to sites that require one (mutual TLS)")
+	pushCmd.Flags().StringVar(&clientKeyFile, "client-key", "", "PEM key for --client-cert, if not in the same file")
+	pushCmd.Flags().BoolVar(&adblockEnabled, "adblock", false, "Block ad and tracker requests using the bundled filter list")
+	pushCmd.Flags().StringVar(&queueTimeout, "queue-timeout", "", "How long to wait for the daemon when it is busy with other fetches, e.g. 1m (default 15s, or ESSENZ_QUEUE_TIMEOUT)")
+	pushCmd.Flags().StringVar(&browserProfile, "profile", "", "Run in a named browser profile whose cookies and storage persist between fetches")
+	pushCmd.Flags().BoolVar(&incognito, "incognito", false, "Run in a throwaway browser context that shares no cookies or storage with other fetches")
+
+	// Bookmark command flags
+	bookmarkAddCmd.Flags().StringArrayVar(&bookmarkTags, "tag", nil, "Tag the bookmark (repeatable)")
+	bookmarkAddCmd.Flags().StringVar(&bookmarkTitle, "title", "", "Title to list the bookmark under")
+	bookmarkAddCmd.Flags().BoolVar(&bookmarkCache, "cache", false, "Distill the page now and keep a copy to read later")
+	bookmarkAddCmd.Flags().BoolVar(&rawOutput, "raw", false, "Cache the raw HTML without reader view processing")
+	bookmarkAddCmd.Flags().StringVar(&waitForSelector, "wait-for-selector", "", "Wait for specific CSS selector to appear before extraction")
+	bookmarkAddCmd.Flags().StringArrayVar(&requestHeaders, "header", nil, "Extra request header as \"Name: value\" (repeatable)")
+	bookmarkAddCmd.Flags().StringVar(&languages, "lang", "", "Languages to prefer, e.g. fr-FR or fr-FR,en (sets Accept-Language and the browser's languages)")
+	bookmarkAddCmd.Flags().StringVar(&basicAuth, "auth", "", "HTTP basic auth credentials as user:pass")
+	bookmarkAddCmd.Flags().StringVar(&cookiesFile, "cookies", "", "Cookie file to send with requests (Netscape cookies.txt or JSON)")
+	bookmarkAddCmd.Flags().IntVar(&fetchRetries, "retries", 0, "Retry a fetch this many times after a server error, dropped connection or timeout")
+	bookmarkAddCmd.Flags().StringVar(&retryDelay, "retry-delay", "1s", "Wait before the first retry, doubled for each one after, with jitter")
+	bookmarkAddCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Accept any TLS certificate without verifying it")
+	bookmarkAddCmd.Flags().StringVar(&caCertFile, "ca-cert", "", "PEM file of certificate authorities to trust besides the system's")
+	bookmarkAddCmd.Flags().StringVar(&clientCertFile, "client-cert", "", "PEM client certificate to present to sites that require one (mutual TLS)")
+	bookmarkAddCmd.Flags().StringVar(&clientKeyFile, "client-key", "", "PEM key for --client-cert, if not in the same file")
+	bookmarkAddCmd.Flags().StringVar(&browserProfile, "profile", "", "Run in a named browser profile whose cookies and storage persist between fetches")
+	bookmarkListCmd.Flags().StringVar(&bookmarkListTag, "tag", "", "Only list bookmarks with this tag")
+	bookmarkListCmd.Flags().BoolVar(&bookmarkListJSON, "json", false, "Print the bookmarks as JSON")
+	bookmarkOpenCmd.Flags().BoolVar(&bookmarkRefresh, "refresh", false, "Distill the page again and replace the cached copy")
+	bookmarkOpenCmd.Flags().StringVar(&waitForSelector, "wait-for-selector", "", "Wait for specific CSS selector to appear before extraction")
+	bookmarkOpenCmd.Flags().StringArrayVar(&requestHeaders, "header", nil, "Extra request header as \"Name: value\" (repeatable)")
+	bookmarkOpenCmd.Flags().StringVar(&languages, "lang", "", "Languages to prefer, e.g. fr-FR or fr-FR,en (sets Accept-Language and the browser's languages)")
+	bookmarkOpenCmd.Flags().StringVar(&basicAuth, "auth", "", "HTTP basic auth credentials as user:pass")
+	bookmarkOpenCmd.Flags().StringVar(&cookiesFile, "cookies", "", "Cookie file to send with requests (Netscape cookies.txt or JSON)")
+	bookmarkOpenCmd.Flags().IntVar(&fetchRetries, "retries", 0, "Retry a fetch this many times after a server error, dropped connection or timeout")
+	bookmarkOpenCmd.Flags().StringVar(&retryDelay, "retry-delay", "1s", "Wait before the first retry, doubled for each one after, with jitter")
+	bookmarkOpenCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Accept any TLS certificate without verifying it")
+	bookmarkOpenCmd.Flags().StringVar(&caCertFile, "ca-cert", "", "PEM file of certificate authorities to trust besides the system's")
+	bookmarkOpenCmd.Flags().StringVar(&clientCertFile, "client-cert", "", "PEM client certificate to present to sites that require one (mutual TLS)")
+	bookmarkOpenCmd.Flags().StringVar(&clientKeyFile, "client-key", "", "PEM key for --client-cert, if not in the same file")
+	bookmarkOpenCmd.Flags().StringVar(&browserProfile, "profile", "", "Run in a named browser profile whose cookies and storage persist between fetches")
+	bookmarkCmd.AddCommand(bookmarkAddCmd)
+	bookmarkCmd.AddCommand(bookmarkListCmd)
+	bookmarkCmd.AddCommand(bookmarkOpenCmd)
+
+	// Serve command flags
+	serveCmd.Flags().StringVar(&serveGRPC, "grpc", "localhost:50051", "Address to serve the gRPC API on, or \"\" for none")
+	serveCmd.Flags().StringVar(&serveHTTP, "http", "", "Address to serve the HTTP job API on, e.g. localhost:8080")
+	serveCmd.Flags().StringVar(&serveQueueDir, "queue-dir", rpc.DefaultQueueDir(), "Directory the HTTP job queue is kept in")
+	serveCmd.Flags().IntVar(&serveWorkers, "workers", 2, "How many queued jobs to run at a time")
+	serveCmd.Flags().Float64Var(&serveRate, "rate", 0, "Most queued jobs to start per minute (default no limit)")
+
+	// Watch command flags
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 5*time.Minute, "How long to wait between fetches")
+	watchCmd.Flags().IntVar(&watchCount, "count", 0, "Stop after this many fetches (default: until interrupted)")
+	watchCmd.Flags().BoolVar(&watchFull, "full", false, "Print the whole new version on a change instead of a diff")
+	watchCmd.Flags().StringVar(&watchOnChange, "on-change", "", "Command to run through the shell on each change, with the new markdown on stdin")
+	watchCmd.Flags().BoolVar(&watchNoRevalidate, "no-revalidate", false, "Fetch the page every time instead of skipping it when the server says it has not changed")
+	watchCmd.Flags().BoolVar(&rawOutput, "raw", false, "Output raw HTML without reader view processing")
+	watchCmd.Flags().BoolVar(&withComments, "with-comments", false, "Extract reader comments into a separate Comments section")
+	watchCmd.Flags().StringVar(&waitForSelector, "wait-for-selector", "", "Wait for specific CSS selector to appear before extraction")
+	watchCmd.Flags().StringArrayVar(&requestHeaders, "header", nil, "Extra request header as \"Name: value\" (repeatable)")
+	watchCmd.Flags().StringVar(&languages, "lang", "", "Languages to prefer, e.g. fr-FR or fr-FR,en (sets Accept-Language and the browser's languages)")
+	watchCmd.Flags().StringVar(&basicAuth, "auth", "", "HTTP basic auth credentials as user:pass")
+	watchCmd.Flags().StringVar(&userAgent, "user-agent", "", "User agent string or preset: "+strings.Join(session.UserAgentPresets(), ", "))
+	watchCmd.Flags().StringVar(&cookiesFile, "cookies", "", "Cookie file to send with requests (Netscape cookies.txt or JSON)")
+	watchCmd.Flags().IntVar(&fetchRetries, "retries", 0, "Retry a fetch this many times after a server error, dropped connection or timeout")
+	watchCmd.Flags().StringVar(&retryDelay, "retry-delay", "1s", "Wait before the first retry, doubled for each one after, with jitter")
+	watchCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Accept any TLS certificate without verifying it")
+	watchCmd.Flags().StringVar(&caCertFile, "ca-cert", "", "PEM file of certificate authorities to trust besides the system's")
+	watchCmd.Flags().StringVar(&clientCertFile, "client-cert", "", "PEM client certificate to present to sites that require one (mutual TLS)")
+	watchCmd.Flags().StringVar(&clientKeyFile, "client-key", "", "PEM key for --client-cert, if not in the same file")
+	watchCmd.Flags().BoolVar(&adblockEnabled, "adblock", false, "Block ad and tracker requests using the bundled filter list")
+	watchCmd.Flags().StringVar(&queueTimeout, "queue-timeout", "", "How long to wait for the daemon when it is busy with other fetches, e.g. 1m (default 15s, or ESSENZ_QUEUE_TIMEOUT)")
+	watchCmd.Flags().StringVar(&browserProfile, "profile", "", "Run in a named browser profile whose cookies and storage persist between fetches")
+	watchCmd.Flags().BoolVar(&incognito, "incognito", false, "Run in a throwaway browser context that shares no cookies or storage with other fetches")
+
+	// Diff command flags
+	diffCmd.Flags().IntVar(&diffContext, "context", 3, "Unchanged paragraphs to show around each change")
+	diffCmd.Flags().BoolVar(&diffExitCode, "exit-code", false, "Exit with status 1 when the content differs")
+	diffCmd.Flags().BoolVar(&rawOutput, "raw", false, "Output raw HTML without reader view processing")
+	diffCmd.Flags().BoolVar(&withComments, "with-comments", false, "Extract reader comments into a separate Comments section")
+	diffCmd.Flags().StringVar(&waitForSelector, "wait-for-selector", "", "Wait for specific CSS selector to appear before extraction")
+	diffCmd.Flags().StringArrayVar(&requestHeaders, "header", nil, "Extra request header as \"Name: value\" (repeatable)")
+	diffCmd.Flags().StringVar(&languages, "lang", "", "Languages to prefer, e.g. fr-FR or fr-FR,en (sets Accept-Language and the browser's languages)")
+	diffCmd.Flags().StringVar(&basicAuth, "auth", "", "HTTP basic auth credentials as user:pass")
+	diffCmd.Flags().StringVar(&userAgent, "user-agent", "", "User agent string or preset: "+strings.Join(session.UserAgentPresets(), ", "))
+	diffCmd.Flags().StringVar(&cookiesFile, "cookies", "", "Cookie file to send with requests (Netscape cookies.txt or JSON)")
+	diffCmd.Flags().IntVar(&fetchRetries, "retries", 0, "Retry a fetch this many times after a server error, dropped connection or timeout")
+	diffCmd.Flags().StringVar(&retryDelay, "retry-delay", "1s", "Wait before the first retry, doubled for each one after, with jitter")
+	diffCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Accept any TLS certificate without verifying it")
+	diffCmd.Flags().StringVar(&caCertFile, "ca-cert", "", "PEM file of certificate authorities to trust besides the system's")
+	diffCmd.Flags().StringVar(&clientCertFile, "client-cert", "", "PEM client certificate to present to sites that require one (mutual TLS)")
+	diffCmd.Flags().StringVar(&clientKeyFile, "client-key", "", "PEM key for --client-cert, if not in the same file")
+	diffCmd.Flags().BoolVar(&adblockEnabled, "adblock", false, "Block ad and tracker requests using the bundled filter list")
+	diffCmd.Flags().StringVar(&queueTimeout, "queue-timeout", "", "How long to wait for the daemon when it is busy with other fetches, e.g. 1m (default 15s, or ESSENZ_QUEUE_TIMEOUT)")
+	diffCmd.Flags().StringVar(&browserProfile, "profile", "", "Run in a named browser profile whose cookies and storage persist between fetches")
+	diffCmd.Flags().BoolVar(&incognito, "incognito", false, "Run in a throwaway browser context that shares no cookies or storage with other fetches")
+
+	// Crawl command flags
+	crawlCmd.Flags().IntVar(&crawlDepth, "depth", 2, "Links to follow away from the start page")
+	crawlCmd.Flags().BoolVar(&crawlSameDomain, "same-domain", false, "Only follow links on the start page's host and its subdomains")
+	crawlCmd.Flags().StringArrayVar(&crawlInclude, "include", nil, "Only follow links whose URL matches this regular expression (repeatable)")
+	crawlCmd.Flags().StringArrayVar(&crawlExclude, "exclude", nil, "Do not follow links whose URL matches this regular expression (repeatable)")
+	crawlCmd.Flags().IntVar(&crawlMaxPages, "max-pages", 100, "Stop after this many pages (0 for no limit)")
+	crawlCmd.Flags().DurationVar(&crawlDelay, "delay", 500*time.Millisecond, "Least time between two fetches on the same host")
+	crawlCmd.Flags().StringVar(&crawlOutputDir, "output-dir", ".", "Directory to write the HOST/PATH.md tree into")
+	crawlCmd.Flags().StringVar(&ifExists, "if-exists", "overwrite", "When a page's file exists: overwrite, skip (keep the file), or error")
+	crawlCmd.Flags().IntVar(&crawlConcurrency, "concurrency", 0, "Pages to fetch at once (default one per CPU)")
+	crawlCmd.Flags().IntVar(&crawlPerHost, "per-host", batch.DefaultPerHost, "Pages to fetch at once from the same host")
+	crawlCmd.Flags().BoolVar(&jsonlOutput, "jsonl", false, "Write one JSON object per page with its URL, title, markdown, metadata and any error to stdout, as JSON lines")
+	crawlCmd.Flags().BoolVar(&withComments, "with-comments", false, "Extract reader comments into a separate Comments section")
+	crawlCmd.Flags().StringVar(&waitForSelector, "wait-for-selector", "", "Wait for specific CSS selector to appear before extraction")
+	crawlCmd.Flags().StringArrayVar(&requestHeaders, "header", nil, "Extra request header as \"Name: value\" (repeatable)")
+	crawlCmd.Flags().StringVar(&languages, "lang", "", "Languages to prefer, e.g. fr-FR or fr-FR,en (sets Accept-Language and the browser's languages)")
+	crawlCmd.Flags().StringVar(&basicAuth, "auth", "", "HTTP basic auth credentials as user:pass")
+	crawlCmd.Flags().StringVar(&userAgent, "user-agent", "", "User agent string or preset: "+strings.Join(session.UserAgentPresets(), ", "))
+	crawlCmd.Flags().StringVar(&cookiesFile, "cookies", "", "Cookie file to send with requests (Netscape cookies.txt or JSON)")
+	crawlCmd.Flags().IntVar(&fetchRetries, "retries", 0, "Retry a fetch this many times after a server error, dropped connection or timeout")
+	crawlCmd.Flags().StringVar(&retryDelay, "retry-delay", "1s", "Wait before the first retry, doubled for each one after, with jitter")
+	crawlCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Accept any TLS certificate without verifying it")
+	crawlCmd.Flags().StringVar(&caCertFile, "ca-cert", "", "PEM file of certificate authorities to trust besides the system's")
+	crawlCmd.Flags().StringVar(&clientCertFile, "client-cert", "", "PEM client certificate to present to sites that require one (mutual TLS)")
+	crawlCmd.Flags().StringVar(&clientKeyFile, "client-key", "", "PEM key for --client-cert, if not in the same file")
+	crawlCmd.Flags().BoolVar(&adblockEnabled, "adblock", false, "Block ad and tracker requests using the bundled filter list")
+	crawlCmd.Flags().StringVar(&queueTimeout, "queue-timeout", "", "How long to wait for the daemon when it is busy with other fetches, e.g. 1m (default 15s, or ESSENZ_QUEUE_TIMEOUT)")
+	crawlCmd.Flags().StringVar(&browserProfile, "profile", "", "Run in a named browser profile whose cookies and storage persist between fetches")
+	crawlCmd.Flags().BoolVar(&incognito, "incognito", false, "Run in a throwaway browser context that shares no cookies or storage with other fetches")
+
+	// Tune command flags
+	tuneCmd.Flags().StringVar(&tuneSite, "site", "", "Site host to write rules for (defaults to the URL host)")
+
+	// Learn-site command flags
+	learnSiteCmd.Flags().IntVar(&learnPages, "pages", 5, "Maximum number of pages to learn from")
+	learnSiteCmd.Flags().Float64Var(&learnThreshold, "threshold", 0.6, "Fraction of pages a subtree must appear on to count as boilerplate")
+	learnSiteCmd.Flags().BoolVar(&learnDryRun, "dry-run", false, "Print the learned rules instead of saving them")
+
+	// Login command flags
+	loginCmd.Flags().StringVar(&actionsFile, "actions", "", "YAML file of steps that perform the login")
+	loginCmd.Flags().StringVar(&loginSaveState, "save-state", "", "File to write the session cookies and localStorage to (required)")
+	loginCmd.Flags().StringVar(&loadState, "load-state", "", "Existing session state to start from")
+
+	// Add all commands to root
+	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(fetchCmd)
+	rootCmd.AddCommand(batchCmd)
+	rootCmd.AddCommand(sitemapCmd)
+	rootCmd.AddCommand(feedCmd)
+	rootCmd.AddCommand(metaCmd)
+	rootCmd.AddCommand(tablesCmd)
+	rootCmd.AddCommand(imagesCmd)
+	rootCmd.AddCommand(selectCmd)
+	rootCmd.AddCommand(grepCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(summarizeCmd)
+	rootCmd.AddCommand(pushCmd)
+	rootCmd.AddCommand(citeCmd)
+	rootCmd.AddCommand(mergeCmd)
+	rootCmd.AddCommand(bookmarkCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(crawlCmd)
+	rootCmd.AddCommand(daemonCmd)
+	rootCmd.AddCommand(chromeCmd)
+	rootCmd.AddCommand(tuneCmd)
+	rootCmd.AddCommand(learnSiteCmd)
+	rootCmd.AddCommand(loginCmd)
+}
+
+// applyPreset sets the flags bundled in the named config preset. Flags given
+// on the command line keep their values.
+func applyPreset(cmd *cobra.Command, name string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	preset, err := cfg.Preset(name)
+	if err != nil {
+		return err
+	}
+	values, err := preset.Values()
+	if err != nil {
+		return fmt.Errorf("preset %q: %w", name, err)
+	}
+
+	for _, value := range values {
+		flag := cmd.Flags().Lookup(value.Name)
+		if flag == nil || flag.Name == "preset" {
+			return fmt.Errorf("preset %q sets --%s, which %s does not accept", name, value.Name, cmd.CommandPath())
+		}
+		if flag.Changed {
+			continue
+		}
+		for _, v := range value.Values {
+			if err := cmd.Flags().Set(value.Name, v); err != nil {
+				return fmt.Errorf("preset %q: invalid value %q for --%s: %w", name, v, value.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// readStdin reads HTML piped to the command, as UTF-8.
+func readStdin(cmd *cobra.Command) (string, error) {
+	content, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return "", err
+	}
+	return transcode.ToUTF8(content, ""), nil
+}
+
+// readFile reads the contents of a file and returns it as a UTF-8 string
+func readFile(filepath string) (string, error) {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = file.Close() }()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+
+	return transcode.ToUTF8(content, ""), nil
+}
+
+// fetchTarget loads HTML from a URL through Chrome, or from a local file path.
+func fetchTarget(ctx context.Context, target string) (string, error) {
+	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		content, err := fetchURLWithChrome(ctx, target)
+		if err != nil {
+			return "", fmt.Errorf("error fetching URL: %w", err)
+		}
+		return content, nil
+	}
+
+	content, err := readFile(target)
+	if err != nil {
+		return "", fmt.Errorf("error reading file: %w", err)
+	}
+	return content, nil
+}
+
+// checkSelectors exits with a usage error naming the first --preserve-selector
+// or --exclude-selector that does not parse.
+func checkSelectors(cmd *cobra.Command) {
+	selectors := excludeSelectors
+	if preserveSelector != "" {
+		selectors = append([]string{preserveSelector}, selectors...)
+	}
+	for _, selector := range selectors {
+		if _, err := filter.ParseSelector(selector); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			os.Exit(exitUsage)
+		}
+	}
+}
+
+// applySiteRules adds the saved per-site preserve and exclude selectors for the target's host.
+func applySiteRules(cmd *cobra.Command, contentFilterer *filter.ContentFilter, target string) *filter.ContentFilter {
+	rules, err := config.LoadSiteRules(config.SiteHost(target))
+	if err != nil {
+		slog.Warn("ignoring site rules", "error", err)
+		return contentFilterer
+	}
+	if rules == nil {
+		return contentFilterer
+	}
+
+	for _, selector := range append(append([]string(nil), rules.Preserve...), rules.Exclude...) {
+		if _, err := filter.ParseSelector(selector); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: site rules for %s: %v\n", config.SiteHost(target), err)
+			os.Exit(exitUsage)
+		}
+	}
+	for _, selector := range rules.Preserve {
+		contentFilterer = contentFilterer.WithPreserveSelector(selector)
+	}
+	for _, selector := range rules.Exclude {
+		contentFilterer = contentFilterer.WithExcludeSelector(selector)
+	}
+	return contentFilterer
+}
+
+// shouldUseChromeForFile determines if file processing should use Chrome
+func shouldUseChromeForFile() bool {
+	// Use Chrome for files if any DOM ready flags or text node tree flags are set
+	return waitForFrameworks || domReadyTimeout != "5s" || waitForSelector != "" || waitForText != "" || waitForLayoutStable || waitForNetworkIdle || len(stageTimeouts) > 0 || debugReadiness || readinessReport != "" || textNodeTree || scrollMode != "" || len(evalScripts) > 0 || actionsFile != "" || loadState != "" || captureConsole || consoleLog != "" || harFile != ""
+}
+
+// createReadinessChecker creates a ReadinessChecker based on CLI flags
+func createReadinessChecker() (*pageready.ReadinessChecker, error) {
+	// Only create checker if any DOM ready flags are set
+	if !waitForFrameworks && domReadyTimeout == "5s" && waitForSelector == "" && waitForText == "" && !waitForLayoutStable && !waitForNetworkIdle && len(stageTimeouts) == 0 && !debugReadiness {
+		return nil, nil // Use default behavior
+	}
+
+	checker := pageready.NewReadinessChecker()
+
+	// Parse timeout
+	if domReadyTimeout != "5s" {
+		timeout, err := time.ParseDuration(domReadyTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout format: %w", err)
+		}
+		checker = checker.WithTimeout(timeout)
+	}
+
+	// Set framework hints
+	if waitForFrameworks {
+		// Enable common framework detection; meta-frameworks first so they win over their base library
+		checker = checker.WithFrameworkHints([]string{"nextjs", "nuxt", "react", "vue", "angular", "svelte", "solid", "ember", "htmx"})
+	}
+
+	// Set custom selectors
+	if waitForSelector != "" {
+		checker = checker.WithCustomSelectors([]string{waitForSelector})
+	}
+
+	// Set custom texts
+	if waitForText != "" {
+		checker = checker.WithCustomTexts([]string{waitForText})
+	}
+
+	// Wait for layout stability and network idle
+	checker = checker.WithLayoutStability(waitForLayoutStable).
+		WithNetworkIdle(waitForNetworkIdle)
+
+	// Set per-stage budgets
+	timeouts, err := pageready.ParseStageTimeouts(stageTimeouts)
+	if err != nil {
+		return nil, err
+	}
+	for stage, timeout := range timeouts {
+		checker = checker.WithStageTimeout(stage, timeout)
+	}
+
+	// Set debug mode
+	checker = checker.WithDebug(debugReadiness)
+
+	return checker, nil
+}
+
+// maxRefreshes bounds how many meta refresh redirects a fetch follows.
+const maxRefreshes = 5
+
+// fetchURLWithChrome fetches content using Chrome browser automation,
+// following the redirects pages make with <meta http-equiv="refresh">, which
+// would otherwise leave an empty shell. The final URL is reported on stderr.
+func fetchURLWithChrome(ctx context.Context, url string) (string, error) {
+	content, err := fetchPageWithChrome(ctx, url)
+	hops := 0
+	for err == nil {
+		next := metadata.Extract(content, url).Refresh
+		if !strings.HasPrefix(next, "http://") && !strings.HasPrefix(next, "https://") || sameURL(next, url) {
+			break
+		}
+		if hops == maxRefreshes {
+			return "", fmt.Errorf("stopped after %d meta refresh redirects at %s", maxRefreshes, url)
+		}
+		hops++
+		slog.Info("following meta refresh", "url", url, "to", next)
+		// Whether the redirecting page changed says nothing of its target
+		delete(pageValidators, url)
+		url = next
+		content, err = fetchPageWithChrome(ctx, url)
+	}
+	if err != nil {
+		return "", err
+	}
+	if hops > 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "Followed meta refresh to %s\n", url)
+	}
+	return content, nil
+}
+
+// fetchPageWithChrome fetches one page using Chrome browser automation,
+// falling back to plain HTTP when Chrome is unavailable.
+func fetchPageWithChrome(ctx context.Context, url string) (string, error) {
+	client := browser.NewClient()
+	defer client.Shutdown()
+
+	// Configure DOM readiness if flags are set
+	checker, err := createReadinessChecker()
+	if err != nil {
+		return "", fmt.Errorf("failed to configure DOM readiness: %w", err)
+	}
+
+	if checker != nil {
+		client = client.WithReadinessChecker(checker)
+	}
+
+	// Validate the scroll mode before handing it to the daemon
+	if _, err := pageready.ParseScroll(scrollMode); err != nil {
+		return "", err
+	}
+	client = client.WithScroll(scrollMode, scrollMaxHeight).
+		WithEval(evalScripts)
+
+	if actionsFile != "" {
+		script, err := actions.Load(actionsFile)
+		if err != nil {
+			return "", err
+		}
+		client = client.WithActions(script)
+	}
+
+	var state *session.StorageState
+	if loadState != "" {
+		state, err = session.Load(loadState)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var cookies []session.Cookie
+	if cookiesFile != "" {
+		cookies, err = session.LoadCookies(cookiesFile)
+		if err != nil {
+			return "", err
+		}
+		if state == nil {
+			state = &session.StorageState{}
+		}
+		state.Cookies = session.MergeCookies(state.Cookies, cookies)
+	}
+
+	if state != nil {
+		client = client.WithLoadState(state)
+	}
+	if saveCookiesFile != "" {
+		client = client.WithSaveState(true)
+	}
+
+	headers, err := session.ParseHeaders(requestHeaders)
+	if err != nil {
+		return "", err
+	}
+	auth, err := session.ParseBasicAuth(basicAuth)
+	if err != nil {
+		return "", err
+	}
+	viewport, err := createViewport()
+	if err != nil {
+		return "", err
+	}
+
+	region, err := emulate.NewRegion(locale, languages, timezone, geolocation)
+	if err != nil {
+		return "", err
+	}
+	// An explicit --header wins over the --lang or locale language preference
+	if language := region.AcceptLanguage(); language != "" {
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		if _, ok := headers["Accept-Language"]; !ok {
+			headers["Accept-Language"] = language
+		}
+	}
+
+	lists, err := resolveAdblockLists()
+	if err != nil {
+		return "", err
+	}
+
+	agent := session.ResolveUserAgent(userAgent)
+	client = client.WithHeaders(headers).
+		WithAuth(auth).
+		WithUserAgent(agent).
+		WithViewport(viewport).
+		WithRegion(region).
+		WithAdblock(adblockEnabled || len(lists) > 0, lists).
+		WithConsoleCapture(captureConsole || consoleLog != "").
+		WithHAR(harFile != "").
+		WithValidators(pageValidators != nil)
+
+	var downloads string
+	if downloadDir != "" {
+		downloads, err = filepath.Abs(downloadDir)
+		if err != nil {
+			return "", err
+		}
+		client = client.WithDownloadDir(downloads)
+	}
+
+	if incognito && browserProfile != "" {
+		return "", fmt.Errorf("--incognito and --profile cannot be combined")
+	}
+	if err := daemon.ValidateProfileName(browserProfile); err != nil {
+		return "", err
+	}
+	client = client.WithIncognito(incognito).WithProfile(browserProfile).
+		WithRemoteChrome(remoteChrome)
+
+	if queueTimeout != "" {
+		wait, err := time.ParseDuration(queueTimeout)
+		if err != nil || wait <= 0 {
+			return "", fmt.Errorf("invalid --queue-timeout %q: expected a positive duration such as 30s", queueTimeout)
+		}
+		client = client.WithQueueTimeout(wait)
+	}
+
+	policy, err := fetchRetryPolicy()
+	if err != nil {
+		return "", err
+	}
+	client = client.WithRetry(policy)
+
+	// Without an explicit agent, the fallback identifies as the emulated device
+	if agent == "" && viewport != nil {
+		agent = viewport.UserAgent
+	}
+
+	content, err := client.FetchContent(ctx, url)
+	if statusErr := chromeStatusError(err); statusErr != nil {
+		// The page answered, so fetching it over HTTP would get the same error
+		return "", statusErr
+	}
+	if err != nil {
+		writeReadinessReport(&pageready.ReadinessResult{
+			EventType: "unavailable",
+			Error:     fmt.Errorf("chrome fetch failed: %w", err),
+		})
+
+		if captureConsole || consoleLog != "" {
+			slog.Warn("console capture unavailable, Chrome fetch failed", "error", err)
+		}
+		if harFile != "" {
+			slog.Warn("HAR recording unavailable, Chrome fetch failed", "error", err)
+		}
+		slog.Info("Chrome fetch failed, fetching over HTTP", "url", url, "error", err)
+
+		// Fallback to simple HTTP fetch if Chrome fails
+		content, jarCookies, err := fetchURL(url, httpOptions{
+			cookies:     cookies,
+			headers:     headers,
+			auth:        auth,
+			userAgent:   agent,
+			downloadDir: downloads,
+			retry:       policy,
+		})
+		if err != nil {
+			return "", err
+		}
+		if err := saveCookies(jarCookies); err != nil {
+			return "", err
+		}
+		return content, nil
+	}
+
+	if pageValidators != nil {
+		pageValidators[url] = client.Validators()
+	}
+	writeReadinessReport(client.Readiness())
+	writeConsoleLog(client.Console())
+	if err := writeHAR(client.HAR()); err != nil {
+		return "", err
+	}
+
+	if saved := client.State(); saved != nil {
+		if err := saveCookies(saved.Cookies); err != nil {
+			return "", err
+		}
+	}
+
+	if saved := client.Download(); saved != nil {
+		// The page itself was a file, so there is nothing to extract
+		if content == "" {
+			return "", &downloadedError{download: saved}
+		}
+		// An action clicked a download link; extraction continues with the page
+		_, _ = fmt.Fprintf(os.Stderr, "Saved download %s to %s (%d bytes)\n", saved.URL, saved.Path, saved.Size)
+	}
+
+	return content, nil
+}
+
+// preferredVersion swaps a fetched page for the version of it the flags ask
+// for: its canonical URL with --follow-canonical, its AMP version with
+// --prefer-amp and its print version with --prefer-print, in that order. It
+// returns the content and URL to carry on with.
+func preferredVersion(ctx context.Context, target, content string) (string, string) {
+	if archivedSnapshot != nil {
+		// The live site is what failed; its other versions would fail too
+		return content, target
+	}
+	if followCanonicalURL {
+		// Links with tracking parameters or to a syndicated copy often name
+		// the original this way
+		content, target = fetchVersion(ctx, target, content, "canonical URL", metadata.Extract(content, target).Canonical)
+	}
+	if preferAMP {
+		content, target = fetchVersion(ctx, target, content, "AMP version", metadata.Extract(content, target).AMP)
+	}
+	if preferPrint {
+		content, target = fetchVersion(ctx, target, content, "print version", metadata.Extract(content, target).Print)
+	}
+	return content, target
+}
+
+// fetchVersion fetches another version of a page from versionURL. The page as
+// fetched is kept when there is no other version, or it cannot be loaded or
+// yields no content.
+func fetchVersion(ctx context.Context, target, content, kind, versionURL string) (string, string) {
+	isURL := strings.HasPrefix(versionURL, "http://") || strings.HasPrefix(versionURL, "https://")
+	if !isURL || sameURL(versionURL, target) {
+		return content, target
+	}
+
+	slog.Info("fetching "+kind, "url", target, "version", versionURL)
+	versionContent, err := fetchURLWithChrome(ctx, versionURL)
+	if err != nil {
+		slog.Warn("could not fetch "+kind+", using the page as fetched", "version", versionURL, "error", err)
+		return content, target
+	}
+	if markdown, err := extractor.New().ExtractContent(versionContent); err != nil || strings.TrimSpace(markdown) == "" {
+		slog.Warn(kind+" has no content, using the page as fetched", "version", versionURL, "error", err)
+		return content, target
+	}
+	return versionContent, versionURL
+}
+
+// maxPages bounds how many pages --follow-pagination stitches together.
+const maxPages = 50
+
+// fetchFollowingPages follows an article's pagination from the page fetched
+// from target, returning the HTML of the pages after it. A page that cannot
+// be fetched ends the article there.
+func fetchFollowingPages(ctx context.Context, target, content string) []string {
+	seen := map[string]bool{target: true}
+	var pages []string
+	for len(pages) < maxPages-1 {
+		next := paginate.Next(content, target)
+		if next == "" || seen[next] {
+			break
+		}
+		seen[next] = true
+
+		slog.Info("following pagination", "url", next, "page", len(pages)+2)
+		page, err := fetchURLWithChrome(ctx, next)
+		if err != nil {
+			slog.Warn("could not fetch next page, stopping there", "url", next, "error", err)
+			break
+		}
+		pages = append(pages, page)
+		content, target = page, next
+	}
+	return pages
+}
+
+// sameURL reports whether two URLs name the same page, ignoring fragments.
+func sameURL(a, b string) bool {
+	urlA, errA := url.Parse(a)
+	urlB, errB := url.Parse(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	urlA.Fragment, urlB.Fragment = "", ""
+	return urlA.String() == urlB.String()
+}
+
+// fetchURLOrArchive fetches a URL like fetchURLWithChrome. With
+// --wayback-fallback, a page that is missing, forbidden or unreachable is
+// replaced by its latest Wayback Machine snapshot, which is recorded in
+// archivedSnapshot so the output can say so.
+func fetchURLOrArchive(ctx context.Context, url string) (string, error) {
+	content, err := fetchURLWithChrome(ctx, url)
+	if err == nil || !waybackFallback || !worthArchiving(err) {
+		return content, err
+	}
+
+	archived, archiveErr := fetchSnapshot(ctx, url, time.Time{})
+	if archiveErr != nil {
+		slog.Warn("no Wayback Machine fallback", "url", url, "error", archiveErr)
+		return "", err
+	}
+	slog.Warn("page unavailable, using its Wayback Machine snapshot", "url", url, "error", err,
+		"captured", archivedSnapshot.Captured.Format(time.RFC3339))
+	return archived, nil
+}
+
+// fetchSnapshot loads the Wayback Machine snapshot of a URL captured closest
+// to a date, or its latest one when date is zero, recording it in archivedSnapshot so the output can say so.
+func fetchSnapshot(ctx context.Context, url string, date time.Time) (string, error) {
+	snapshot, err := wayback.Closest(ctx, url, date)
+	if err != nil {
 		return "", err
 	}
+	content, _, err := fetchURL(snapshot.RawURL(), httpOptions{userAgent: session.ResolveUserAgent(userAgent)})
+	if err != nil {
+		return "", err
+	}
+	slog.Info("using Wayback Machine snapshot", "url", url, "captured", snapshot.Captured.Format(time.RFC3339), "snapshot", snapshot.URL)
+	archivedSnapshot = &snapshot
+	return content, nil
+}
+
+// worthArchiving reports whether a failed fetch is one the Wayback Machine
+// may make up for: the page is gone, forbidden or could not be reached.
+func worthArchiving(err error) bool {
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.code {
+		case http.StatusForbidden, http.StatusNotFound, http.StatusGone:
+			return true
+		}
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// markArchived puts a notice before output that came from the Wayback
+// Machine, as an HTML comment when the output is HTML.
+func markArchived(content string, html bool) string {
+	if archivedSnapshot == nil {
+		return content
+	}
+	notice := wayback.Notice(*archivedSnapshot)
+	if html {
+		notice = "<!-- " + strings.TrimSpace(strings.TrimPrefix(notice, "> ")) + " -->\n"
+	}
+	return notice + content
+}
+
+// downloadedError reports that the target was a file saved to disk rather
+// than a page with content to extract.
+type downloadedError struct {
+	download *download.Download
+}
+
+// Error describes where the file was saved.
+func (e *downloadedError) Error() string {
+	return fmt.Sprintf("target is a file download, saved to %s", e.download.Path)
+}
+
+// reportDownload prints the saved path when err says the target was a file
+// download, and reports whether it did.
+func reportDownload(cmd *cobra.Command, err error) bool {
+	var downloaded *downloadedError
+	if !errors.As(err, &downloaded) {
+		return false
+	}
+	saved := downloaded.download
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Saved download %s to %s (%d bytes)\n", saved.URL, saved.Path, saved.Size)
+	return true
+}
+
+// createViewport builds the screen emulation from --device and --viewport.
+// A --viewport size overrides the device's own screen size.
+func createViewport() (*emulate.Viewport, error) {
+	var viewport *emulate.Viewport
+	if deviceName != "" {
+		device, err := emulate.LookupDevice(deviceName)
+		if err != nil {
+			return nil, err
+		}
+		viewport = device
+	}
+
+	if viewportSize != "" {
+		size, err := emulate.ParseViewport(viewportSize)
+		if err != nil {
+			return nil, err
+		}
+		if viewport == nil {
+			viewport = size
+		} else {
+			viewport.Width = size.Width
+			viewport.Height = size.Height
+		}
+	}
+	return viewport, nil
+}
+
+// resolveAdblockLists checks that the --adblock-list files parse and returns
+// their absolute paths, since the daemon may run in a different directory.
+func resolveAdblockLists() ([]string, error) {
+	if _, err := adblock.Load(adblockLists); err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(adblockLists))
+	for _, list := range adblockLists {
+		path, err := filepath.Abs(list)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// saveCookies writes cookies to the --save-cookies file, if one was given.
+func saveCookies(cookies []session.Cookie) error {
+	if saveCookiesFile == "" {
+		return nil
+	}
+	return session.SaveCookies(saveCookiesFile, cookies)
+}
+
+// writeReadinessReport emits the readiness result as JSON to the --readiness-report
+// file, or to stderr when --debug-readiness is set.
+func writeReadinessReport(result *pageready.ReadinessResult) {
+	if result == nil || (readinessReport == "" && !debugReadiness) {
+		return
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		slog.Warn("failed to encode readiness report", "error", err)
+		return
+	}
+
+	if readinessReport != "" {
+		if err := os.WriteFile(readinessReport, append(data, '\n'), 0o644); err != nil {
+			slog.Warn("failed to write readiness report", "path", readinessReport, "error", err)
+		}
+		return
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "%s\n", data)
+}
+
+// writeConsoleLog emits captured console messages, one per line, to the
+// --console-log file or to stderr.
+func writeConsoleLog(messages []console.Message) {
+	if !captureConsole && consoleLog == "" {
+		return
+	}
+
+	var b strings.Builder
+	for _, message := range messages {
+		b.WriteString(message.String())
+		b.WriteString("\n")
+	}
+
+	if consoleLog != "" {
+		if err := os.WriteFile(consoleLog, []byte(b.String()), 0o644); err != nil {
+			slog.Warn("failed to write console log", "path", consoleLog, "error", err)
+		}
+		return
+	}
+
+	_, _ = fmt.Fprint(os.Stderr, b.String())
+}
+
+// writeHAR writes the recorded network log to the --har file.
+func writeHAR(log *har.HAR) error {
+	if harFile == "" || log == nil {
+		return nil
+	}
+	log.Log.Creator.Version = version
+
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode HAR: %w", err)
+	}
+	if err := os.WriteFile(harFile, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write HAR file: %w", err)
+	}
+	return nil
+}
+
+// httpOptions configures the HTTP fallback fetcher.
+type httpOptions struct {
+	cookies     []session.Cookie
+	headers     map[string]string
+	auth        *session.BasicAuth // Sent with the request for the URL, not to other sites it redirects to
+	userAgent   string
+	downloadDir string       // Where file downloads are saved; empty rejects them
+	retry       retry.Policy // When to fetch again after a transient failure
+}
+
+// fallbackTransport carries every fetch of the HTTP fallback, so fetches from
+// the same site share connections. It is built on first use, once the TLS
+// flags are parsed.
+var (
+	fallbackTransportOnce sync.Once
+	fallbackTransport     http.RoundTripper
+	fallbackTransportErr  error
+)
+
+// httpTransport returns the transport of the HTTP fallback, verifying
+// certificates as --insecure, --ca-cert and --client-cert say.
+func httpTransport() (http.RoundTripper, error) {
+	fallbackTransportOnce.Do(func() {
+		config, err := transport.TLSOptions{
+			Insecure:   insecureTLS,
+			CACert:     caCertFile,
+			ClientCert: clientCertFile,
+			ClientKey:  clientKeyFile,
+		}.Config()
+		if err != nil {
+			fallbackTransportErr = err
+			return
+		}
+		fallbackTransport = transport.New(config)
+	})
+	return fallbackTransport, fallbackTransportErr
+}
+
+// fetchURL fetches content from an HTTP or HTTPS URL (fallback method),
+// retrying server errors and dropped connections as opts.retry allows.
+// It returns the cookies updated with any the server set.
+func fetchURL(url string, opts httpOptions) (string, []session.Cookie, error) {
+	jar, err := session.NewJar(opts.cookies)
+	if err != nil {
+		return "", nil, err
+	}
+
+	roundTripper, err := httpTransport()
+	if err != nil {
+		return "", nil, err
+	}
+	client := &http.Client{
+		Timeout:   30 * time.Second,
+		Jar:       jar,
+		Transport: roundTripper,
+	}
+
+	var content string
+	var cookies []session.Cookie
+	err = opts.retry.Do(context.Background(), isTransient, func() error {
+		var err error
+		content, cookies, err = fetchURLOnce(client, jar, url, opts)
+		return err
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return content, cookies, nil
+}
+
+// fetchURLOnce makes one attempt at fetching a URL for fetchURL.
+func fetchURLOnce(client *http.Client, jar http.CookieJar, url string, opts httpOptions) (string, []session.Cookie, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	for name, value := range opts.headers {
+		req.Header.Set(name, value)
+	}
+	if opts.auth != nil {
+		req.SetBasicAuth(opts.auth.Username, opts.auth.Password)
+	}
+	if opts.userAgent != "" {
+		req.Header.Set("User-Agent", opts.userAgent)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, &httpStatusError{status: resp.Status, code: resp.StatusCode}
+	}
+
+	if download.IsDownload(resp) {
+		if opts.downloadDir == "" {
+			return "", nil, fmt.Errorf("%s is a file download (%s); use --download-dir to save it", url, resp.Header.Get("Content-Type"))
+		}
+		saved, err := download.Save(resp, opts.downloadDir)
+		if err != nil {
+			return "", nil, err
+		}
+		return "", nil, &downloadedError{download: saved}
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if pageValidators != nil {
+		pageValidators[url] = revalidate.FromHeader(resp.Header)
+	}
+	// The rest of the pipeline assumes UTF-8, which Chrome hands back
+	// whatever the page was served in
+	return transcode.ToUTF8(content, resp.Header.Get("Content-Type")), session.JarCookies(jar, resp.Request.URL, opts.cookies), nil
+}
+
+// isTransient reports whether a fetch failed in a way that may not happen
+// again: a server error, a dropped connection or a timeout.
+func isTransient(err error) bool {
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code >= 500
+	}
+	var netErr net.Error
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// fetchRetryPolicy returns the retry policy set by --retries and --retry-delay.
+func fetchRetryPolicy() (retry.Policy, error) {
+	if fetchRetries < 0 {
+		return retry.Policy{}, fmt.Errorf("invalid --retries %d: expected 0 or more", fetchRetries)
+	}
+	delay, err := time.ParseDuration(retryDelay)
+	if err != nil || delay <= 0 {
+		return retry.Policy{}, fmt.Errorf("invalid --retry-delay %q: expected a positive duration such as 2s", retryDelay)
+	}
+	return retry.Policy{Retries: fetchRetries, Delay: delay}, nil
+}
+
+// httpStatusError reports a response other than 200 OK.
+type httpStatusError struct {
+	status string
+	code   int
+}
+
+// Error returns the status line.
+func (e *httpStatusError) Error() string {
+	return "HTTP " + e.status
+}
+
+// chromeStatusError returns the HTTP error status a page answered Chrome with
+// as an httpStatusError, or nil when err is not one.
+func chromeStatusError(err error) error {
+	var statusErr *browser.StatusError
+	if !errors.As(err, &statusErr) {
+		return nil
+	}
+	return &httpStatusError{status: fmt.Sprintf("%d %s", statusErr.Code, http.StatusText(statusErr.Code)), code: statusErr.Code}
+}
 
-	return string(content), nil
+// Exit codes, so scripts can tell why sz failed. Commands exit with
+// exitError for anything not listed.
+const (
+	exitError       = 1 // Any other failure
+	exitUsage       = 2 // Invalid command line
+	exitNetwork     = 3 // The target could not be reached or returned an HTTP error
+	exitTimeout     = 4 // The fetch ran out of time
+	exitEmpty       = 5 // The distilled output had fewer words than --min-words, with --fail-on-empty
+	exitUnavailable = 6 // Chrome could not be started or reached where no fallback exists
+)
+
+// exitCode picks the exit code describing why err happened.
+func exitCode(err error) int {
+	var netErr net.Error
+	var statusErr *httpStatusError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return exitTimeout
+	case errors.As(err, new(*emptyError)):
+		return exitEmpty
+	case errors.Is(err, daemon.ErrBrowserUnavailable):
+		return exitUnavailable
+	case errors.As(err, &netErr), errors.As(err, &statusErr):
+		return exitNetwork
+	}
+	return exitError
 }
 
 func main() {
+	// Commands report their own failures and exit; Execute only fails on
+	// the command line itself
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 }
@@ -0,0 +1,213 @@
+// Package actions runs scripted page interactions before content extraction.
+package actions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chromedp/chromedp"
+	"github.com/jewell-lgtm/essenz/internal/pageready"
+	"gopkg.in/yaml.v3"
+)
+
+// Script is an ordered list of interaction steps.
+type Script struct {
+	Steps []Step `yaml:"steps" json:"steps"`
+}
+
+// Step is a single interaction. Exactly one of its fields must be set.
+type Step struct {
+	Click   string        `yaml:"click,omitempty" json:"click,omitempty"`     // CSS selector to click
+	Type    *TypeAction   `yaml:"type,omitempty" json:"type,omitempty"`       // Text to type into a field
+	WaitFor string        `yaml:"waitFor,omitempty" json:"waitFor,omitempty"` // CSS selector to wait for
+	Scroll  string        `yaml:"scroll,omitempty" json:"scroll,omitempty"`   // "auto" or a number of viewports
+	Select  *SelectAction `yaml:"select,omitempty" json:"select,omitempty"`   // Option to choose in a <select>
+}
+
+// TypeAction types text into the element matching a selector.
+type TypeAction struct {
+	Selector string `yaml:"selector" json:"selector"`
+	Text     string `yaml:"text" json:"text"`
+}
+
+// SelectAction chooses an option value in the <select> matching a selector.
+type SelectAction struct {
+	Selector string `yaml:"selector" json:"selector"`
+	Value    string `yaml:"value" json:"value"`
+}
+
+// Load reads and validates an action script file.
+func Load(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read actions file: %w", err)
+	}
+
+	script, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid actions file %s: %w", path, err)
+	}
+	return script, nil
+}
+
+// Parse decodes an action script. The document may be a mapping with a steps
+// key or a bare list of steps.
+func Parse(data []byte) (*Script, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	var script Script
+	if len(document.Content) > 0 && document.Content[0].Kind == yaml.SequenceNode {
+		if err := decodeStrict(data, &script.Steps); err != nil {
+			return nil, err
+		}
+	} else if err := decodeStrict(data, &script); err != nil {
+		return nil, err
+	}
+
+	if err := script.Validate(); err != nil {
+		return nil, err
+	}
+	return &script, nil
+}
+
+// decodeStrict decodes YAML, rejecting unknown keys so typos in step names fail loudly.
+func decodeStrict(data []byte, out interface{}) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// Validate checks that every step sets exactly one complete action.
+func (s *Script) Validate() error {
+	for i, step := range s.Steps {
+		if err := step.validate(); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// validate checks that a step sets exactly one complete action.
+func (s Step) validate() error {
+	count := 0
+	if s.Click != "" {
+		count++
+	}
+	if s.Type != nil {
+		count++
+		if s.Type.Selector == "" {
+			return fmt.Errorf("type requires a selector")
+		}
+	}
+	if s.WaitFor != "" {
+		count++
+	}
+	if s.Scroll != "" {
+		count++
+		if _, err := pageready.ParseScroll(s.Scroll); err != nil {
+			return err
+		}
+	}
+	if s.Select != nil {
+		count++
+		if s.Select.Selector == "" {
+			return fmt.Errorf("select requires a selector")
+		}
+	}
+
+	switch count {
+	case 0:
+		return fmt.Errorf("no action set; use one of click, type, waitFor, scroll, select")
+	case 1:
+		return nil
+	default:
+		return fmt.Errorf("only one action may be set per step")
+	}
+}
+
+// Run executes the steps in order against a Chrome tab.
+func (s *Script) Run(ctx context.Context, chromeCtx context.Context) error {
+	for i, step := range s.Steps {
+		if err := step.run(ctx, chromeCtx); err != nil {
+			return fmt.Errorf("action step %d failed: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// run executes a single step.
+func (s Step) run(ctx context.Context, chromeCtx context.Context) error {
+	switch {
+	case s.Click != "":
+		return chromedp.Run(chromeCtx,
+			chromedp.WaitVisible(s.Click, chromedp.ByQuery),
+			chromedp.Click(s.Click, chromedp.ByQuery),
+		)
+	case s.Type != nil:
+		return chromedp.Run(chromeCtx,
+			chromedp.WaitVisible(s.Type.Selector, chromedp.ByQuery),
+			chromedp.SendKeys(s.Type.Selector, s.Type.Text, chromedp.ByQuery),
+		)
+	case s.WaitFor != "":
+		return chromedp.Run(chromeCtx,
+			chromedp.WaitVisible(s.WaitFor, chromedp.ByQuery),
+		)
+	case s.Scroll != "":
+		scroller, err := pageready.ParseScroll(s.Scroll)
+		if err != nil {
+			return err
+		}
+		return scroller.Scroll(ctx, chromeCtx)
+	case s.Select != nil:
+		return selectOption(chromeCtx, s.Select)
+	}
+	return nil
+}
+
+// selectOption sets a <select> value and fires the events a page listens for.
+func selectOption(chromeCtx context.Context, action *SelectAction) error {
+	// JSON encoding yields valid JavaScript string literals
+	selector, err := json.Marshal(action.Selector)
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal(action.Value)
+	if err != nil {
+		return err
+	}
+
+	var found bool
+	err = chromedp.Run(chromeCtx,
+		chromedp.WaitVisible(action.Selector, chromedp.ByQuery),
+		chromedp.Evaluate(fmt.Sprintf(`
+			(function() {
+				const el = document.querySelector(%s);
+				if (!el) {
+					return false;
+				}
+				el.value = %s;
+				el.dispatchEvent(new Event('input', { bubbles: true }));
+				el.dispatchEvent(new Event('change', { bubbles: true }));
+				return true;
+			})();
+		`, selector, value), &found),
+	)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("select element %s not found", action.Selector)
+	}
+	return nil
+}
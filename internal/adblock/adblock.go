@@ -0,0 +1,193 @@
+// Package adblock blocks ad and tracker requests using EasyList-style filter lists.
+package adblock
+
+import (
+	"bufio"
+	"context"
+	_ "embed" // Bundled default list
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+//go:embed default.txt
+var defaultList string
+
+// List is a set of network filter rules. Only URL rules are supported;
+// element hiding rules and most $options are ignored.
+type List struct {
+	blocks     []rule
+	exceptions []rule
+}
+
+// rule is one compiled network filter.
+type rule struct {
+	pattern *regexp.Regexp
+}
+
+// NewList returns an empty list.
+func NewList() *List {
+	return &List{}
+}
+
+// Default returns a list built from the bundled minimal block list.
+func Default() *List {
+	list := NewList()
+	list.Parse(defaultList)
+	return list
+}
+
+// Load builds a list from the bundled list plus the given filter list files.
+func Load(paths []string) (*List, error) {
+	list := Default()
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read block list: %w", err)
+		}
+		list.Parse(string(data))
+	}
+	return list, nil
+}
+
+// Parse adds the rules in a filter list. Lines that are comments, element
+// hiding rules, or use unsupported syntax are skipped.
+func (l *List) Parse(text string) {
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "!") || strings.HasPrefix(line, "[") {
+			continue
+		}
+		// Element hiding rules only affect rendering, not requests
+		if strings.Contains(line, "##") || strings.Contains(line, "#@#") || strings.Contains(line, "#?#") {
+			continue
+		}
+
+		exception := strings.HasPrefix(line, "@@")
+		line = strings.TrimPrefix(line, "@@")
+
+		// Options such as $script or $third-party narrow a rule; applying it
+		// more broadly is acceptable for text extraction
+		if i := strings.LastIndex(line, "$"); i > 0 {
+			line = line[:i]
+		}
+
+		compiled, ok := compile(line)
+		if !ok {
+			continue
+		}
+		if exception {
+			l.exceptions = append(l.exceptions, compiled)
+		} else {
+			l.blocks = append(l.blocks, compiled)
+		}
+	}
+}
+
+// Len returns the number of block rules.
+func (l *List) Len() int {
+	return len(l.blocks)
+}
+
+// Blocks reports whether a request URL should be blocked.
+func (l *List) Blocks(requestURL string) bool {
+	if !matchesAny(l.blocks, requestURL) {
+		return false
+	}
+	return !matchesAny(l.exceptions, requestURL)
+}
+
+// Intercept blocks matching requests in a tab. The page document itself is
+// never blocked. It must run before navigation.
+func (l *List) Intercept(chromeCtx context.Context) error {
+	chromedp.ListenTarget(chromeCtx, func(ev interface{}) {
+		paused, ok := ev.(*fetch.EventRequestPaused)
+		if !ok {
+			return
+		}
+		go func() {
+			c := chromedp.FromContext(chromeCtx)
+			ctx := cdp.WithExecutor(chromeCtx, c.Target)
+			if paused.ResourceType != network.ResourceTypeDocument && l.Blocks(paused.Request.URL) {
+				_ = fetch.FailRequest(paused.RequestID, network.ErrorReasonBlockedByClient).Do(ctx)
+				return
+			}
+			_ = fetch.ContinueRequest(paused.RequestID).Do(ctx)
+		}()
+	})
+
+	if err := chromedp.Run(chromeCtx, fetch.Enable()); err != nil {
+		return fmt.Errorf("failed to enable request interception: %w", err)
+	}
+	return nil
+}
+
+// matchesAny reports whether any rule matches the URL.
+func matchesAny(rules []rule, requestURL string) bool {
+	for _, r := range rules {
+		if r.pattern.MatchString(requestURL) {
+			return true
+		}
+	}
+	return false
+}
+
+// compile converts a filter pattern into a regular expression. It supports
+// the || domain anchor, | start and end anchors, * wildcards, and the ^
+// separator, plus /regex/ rules.
+func compile(pattern string) (rule, bool) {
+	if pattern == "" || pattern == "*" {
+		return rule{}, false
+	}
+
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return rule{}, false
+		}
+		return rule{pattern: re}, true
+	}
+
+	var expr strings.Builder
+	switch {
+	case strings.HasPrefix(pattern, "||"):
+		// Matches the domain or any subdomain, after the scheme
+		expr.WriteString(`^[a-z][a-z0-9+.-]*://([^/?#]*\.)?`)
+		pattern = pattern[2:]
+	case strings.HasPrefix(pattern, "|"):
+		expr.WriteString("^")
+		pattern = pattern[1:]
+	}
+
+	endAnchor := strings.HasSuffix(pattern, "|")
+	pattern = strings.TrimSuffix(pattern, "|")
+
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			expr.WriteString(".*")
+		case '^':
+			// Separator: anything but a letter, digit, or _-.% — or the end
+			expr.WriteString(`(?:[^\w.%-]|$)`)
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	if endAnchor {
+		expr.WriteString("$")
+	}
+
+	re, err := regexp.Compile("(?i)" + expr.String())
+	if err != nil {
+		return rule{}, false
+	}
+	return rule{pattern: re}, true
+}
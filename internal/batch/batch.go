@@ -0,0 +1,28 @@
+// Package batch reads the target lists sz batch works through and processes
+// them with a pool of workers.
+package batch
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ReadTargets reads one URL or file path per line. Blank lines and lines
+// starting with # are skipped.
+func ReadTargets(r io.Reader) ([]string, error) {
+	var targets []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		targets = append(targets, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read targets: %w", err)
+	}
+	return targets, nil
+}
@@ -0,0 +1,124 @@
+package batch
+
+import (
+	"context"
+	"net/url"
+	"runtime"
+	"sync"
+)
+
+// DefaultPerHost is how many targets on one host are fetched at once unless
+// Options.PerHost says otherwise, so a batch does not hammer a single site.
+const DefaultPerHost = 2
+
+// Options controls how Run spreads work.
+type Options struct {
+	Workers int  // Targets processed at once; 0 means one per CPU
+	PerHost int  // Targets on the same host processed at once; 0 means DefaultPerHost
+	Ordered bool // Emit results in input order rather than as they complete
+}
+
+// Result is the outcome of processing one target.
+type Result struct {
+	Index   int // Position of the target in the input
+	Target  string
+	Content string
+	Err     error
+}
+
+// DistillFunc processes one target.
+type DistillFunc func(ctx context.Context, target string) (string, error)
+
+// Run processes targets with a pool of workers and calls emit with each
+// result, from the calling goroutine only. With Options.Ordered a result is
+// held back until every earlier one has been emitted.
+func Run(ctx context.Context, targets []string, opts Options, distill DistillFunc, emit func(Result)) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, len(targets))
+	limits := newHostLimits(opts.PerHost)
+
+	jobs := make(chan int)
+	results := make(chan Result)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				target := targets[i]
+				release := limits.acquire(target)
+				content, err := distill(ctx, target)
+				release()
+				results <- Result{Index: i, Target: target, Content: content, Err: err}
+			}
+		}()
+	}
+	go func() {
+		for i := range targets {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	if !opts.Ordered {
+		for result := range results {
+			emit(result)
+		}
+		return
+	}
+
+	pending := make(map[int]Result)
+	next := 0
+	for result := range results {
+		pending[result.Index] = result
+		for {
+			ready, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			emit(ready)
+			next++
+		}
+	}
+}
+
+// hostLimits caps how many targets on each host are processed at once.
+// Local files have no host and are not limited.
+type hostLimits struct {
+	mu      sync.Mutex
+	perHost int
+	slots   map[string]chan struct{}
+}
+
+// newHostLimits creates limits allowing perHost targets per host at once.
+func newHostLimits(perHost int) *hostLimits {
+	if perHost <= 0 {
+		perHost = DefaultPerHost
+	}
+	return &hostLimits{perHost: perHost, slots: make(map[string]chan struct{})}
+}
+
+// acquire waits for a slot on target's host and returns the function that frees it.
+func (l *hostLimits) acquire(target string) func() {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return func() {}
+	}
+
+	l.mu.Lock()
+	slots, ok := l.slots[u.Host]
+	if !ok {
+		slots = make(chan struct{}, l.perHost)
+		l.slots[u.Host] = slots
+	}
+	l.mu.Unlock()
+
+	slots <- struct{}{}
+	return func() { <-slots }
+}
@@ -0,0 +1,171 @@
+// Package bookmark keeps a list of saved URLs with tags and, optionally, a
+// distilled copy of each page to read later without fetching it again.
+package bookmark
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jewell-lgtm/essenz/internal/config"
+)
+
+// Bookmark is a saved URL.
+type Bookmark struct {
+	ID     int       `json:"id"`
+	URL    string    `json:"url"`
+	Title  string    `json:"title,omitempty"`
+	Tags   []string  `json:"tags,omitempty"`
+	Added  time.Time `json:"added"`
+	Cached bool      `json:"cached,omitempty"` // A distilled copy is stored with the bookmark
+}
+
+// HasTag reports whether the bookmark carries tag, ignoring case.
+func (b *Bookmark) HasTag(tag string) bool {
+	for _, t := range b.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Store is the bookmark list and the cached copies kept next to it.
+type Store struct {
+	dir       string
+	NextID    int        `json:"next_id"`
+	Bookmarks []Bookmark `json:"bookmarks"`
+}
+
+// DefaultDir returns where bookmarks are kept: bookmarks under the sz
+// configuration directory.
+func DefaultDir() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "bookmarks"), nil
+}
+
+// Open reads the store in dir. A directory without bookmarks yields an empty
+// store; nothing is written until Save.
+func Open(dir string) (*Store, error) {
+	store := &Store{dir: dir, NextID: 1}
+	data, err := os.ReadFile(store.indexPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bookmarks: %w", err)
+	}
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("failed to parse bookmarks %s: %w", store.indexPath(), err)
+	}
+	return store, nil
+}
+
+// Add saves url with title and tags and returns its bookmark. Adding a URL
+// that is already saved keeps its ID and date, adds the new tags, and
+// replaces the title when one is given.
+func (s *Store) Add(url, title string, tags []string) *Bookmark {
+	if b := s.find(func(b *Bookmark) bool { return b.URL == url }); b != nil {
+		if title != "" {
+			b.Title = title
+		}
+		for _, tag := range tags {
+			if !b.HasTag(tag) {
+				b.Tags = append(b.Tags, tag)
+			}
+		}
+		return b
+	}
+
+	s.Bookmarks = append(s.Bookmarks, Bookmark{
+		ID:    s.NextID,
+		URL:   url,
+		Title: title,
+		Tags:  tags,
+		Added: time.Now().UTC().Truncate(time.Second),
+	})
+	s.NextID++
+	return &s.Bookmarks[len(s.Bookmarks)-1]
+}
+
+// Find looks a bookmark up by its ID or its URL.
+func (s *Store) Find(ref string) (*Bookmark, bool) {
+	id, err := strconv.Atoi(ref)
+	b := s.find(func(b *Bookmark) bool { return (err == nil && b.ID == id) || b.URL == ref })
+	return b, b != nil
+}
+
+// List returns the bookmarks carrying tag, or all of them when tag is empty,
+// oldest first.
+func (s *Store) List(tag string) []Bookmark {
+	var list []Bookmark
+	for i := range s.Bookmarks {
+		if tag == "" || s.Bookmarks[i].HasTag(tag) {
+			list = append(list, s.Bookmarks[i])
+		}
+	}
+	return list
+}
+
+// Save writes the bookmark list, creating the directory as needed.
+func (s *Store) Save() error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create bookmarks directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode bookmarks: %w", err)
+	}
+	if err := os.WriteFile(s.indexPath(), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write bookmarks: %w", err)
+	}
+	return nil
+}
+
+// SaveContent stores a distilled copy of the bookmarked page and marks the
+// bookmark as cached. The list itself still has to be saved.
+func (s *Store) SaveContent(b *Bookmark, content string) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create bookmarks directory: %w", err)
+	}
+	if err := os.WriteFile(s.contentPath(b), []byte(content), 0o644); err != nil {
+		return fmt.Errorf("failed to write cached copy: %w", err)
+	}
+	b.Cached = true
+	return nil
+}
+
+// Content returns the cached copy of a bookmarked page.
+func (s *Store) Content(b *Bookmark) (string, error) {
+	data, err := os.ReadFile(s.contentPath(b))
+	if err != nil {
+		return "", fmt.Errorf("failed to read cached copy: %w", err)
+	}
+	return string(data), nil
+}
+
+// find returns the first bookmark matching, or nil.
+func (s *Store) find(match func(b *Bookmark) bool) *Bookmark {
+	for i := range s.Bookmarks {
+		if match(&s.Bookmarks[i]) {
+			return &s.Bookmarks[i]
+		}
+	}
+	return nil
+}
+
+func (s *Store) indexPath() string {
+	return filepath.Join(s.dir, "bookmarks.json")
+}
+
+func (s *Store) contentPath(b *Bookmark) string {
+	return filepath.Join(s.dir, strconv.Itoa(b.ID)+".md")
+}
@@ -3,40 +3,281 @@ package browser
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
 
+	"github.com/jewell-lgtm/essenz/internal/actions"
+	"github.com/jewell-lgtm/essenz/internal/console"
 	"github.com/jewell-lgtm/essenz/internal/daemon"
+	"github.com/jewell-lgtm/essenz/internal/download"
+	"github.com/jewell-lgtm/essenz/internal/emulate"
+	"github.com/jewell-lgtm/essenz/internal/har"
 	"github.com/jewell-lgtm/essenz/internal/pageready"
+	"github.com/jewell-lgtm/essenz/internal/retry"
+	"github.com/jewell-lgtm/essenz/internal/revalidate"
+	"github.com/jewell-lgtm/essenz/internal/session"
 )
 
 // Client provides browser operations with automatic daemon management.
 type Client struct {
-	readinessChecker *pageready.ReadinessChecker
+	options    daemon.FetchOptions
+	readiness  *pageready.ReadinessResult
+	state      *session.StorageState
+	console    []console.Message
+	har        *har.HAR
+	download   *download.Download
+	validators *revalidate.Validators
+	retry      retry.Policy
+}
+
+// transientErrors are the parts of daemon errors that mark a failure worth
+// retrying: navigation timeouts and dropped connections. Server errors are
+// told apart by their StatusError.
+var transientErrors = []string{
+	"context deadline exceeded",
+	"net::ERR_CONNECTION_RESET",
+	"net::ERR_CONNECTION_CLOSED",
+	"net::ERR_CONNECTION_TIMED_OUT",
+	"net::ERR_EMPTY_RESPONSE",
+	"net::ERR_NETWORK_CHANGED",
+	"net::ERR_TIMED_OUT",
+}
+
+// StatusError reports that the page's own document answered with an HTTP
+// status outside 2xx. The page rendered, but it is the server's error page.
+type StatusError struct {
+	URL  string
+	Code int
+}
+
+// Error returns the status line.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP %d %s", e.Code, http.StatusText(e.Code))
 }
 
 // NewClient creates a new browser client with global daemon management.
 func NewClient() *Client {
-	return &Client{
-		readinessChecker: nil, // Default: no readiness checking
-	}
+	// A nil readiness checker in the options falls back to the daemon's default detection
+	return &Client{}
+}
+
+// WithOptions replaces all fetch options at once.
+func (c *Client) WithOptions(options daemon.FetchOptions) *Client {
+	c.options = options
+	return c
 }
 
 // WithReadinessChecker configures the client to use DOM readiness detection.
 func (c *Client) WithReadinessChecker(checker *pageready.ReadinessChecker) *Client {
-	c.readinessChecker = checker
+	c.options.Readiness = checker
+	return c
+}
+
+// WithScroll configures the client to scroll the page before extraction.
+// The scroll value is "auto" or a number of viewports.
+func (c *Client) WithScroll(scroll string, maxHeight int) *Client {
+	c.options.Scroll = scroll
+	c.options.ScrollMaxHeight = maxHeight
+	return c
+}
+
+// WithEval configures scripts to run in the page before extraction.
+func (c *Client) WithEval(scripts []string) *Client {
+	c.options.Eval = scripts
+	return c
+}
+
+// WithActions configures interaction steps to run before extraction.
+func (c *Client) WithActions(script *actions.Script) *Client {
+	c.options.Actions = script
+	return c
+}
+
+// WithLoadState configures a saved session to restore before navigation.
+func (c *Client) WithLoadState(state *session.StorageState) *Client {
+	c.options.LoadState = state
+	return c
+}
+
+// WithSaveState configures the client to capture the session after fetching.
+func (c *Client) WithSaveState(save bool) *Client {
+	c.options.SaveState = save
+	return c
+}
+
+// WithHeaders configures extra headers sent with every request the page makes.
+func (c *Client) WithHeaders(headers map[string]string) *Client {
+	c.options.Headers = headers
+	return c
+}
+
+// WithAuth configures credentials for basic auth challenges from the page's origin.
+func (c *Client) WithAuth(auth *session.BasicAuth) *Client {
+	c.options.Auth = auth
+	return c
+}
+
+// WithUserAgent configures the user agent the browser reports.
+func (c *Client) WithUserAgent(userAgent string) *Client {
+	c.options.UserAgent = userAgent
+	return c
+}
+
+// WithViewport configures the screen size or device the page is rendered for.
+func (c *Client) WithViewport(viewport *emulate.Viewport) *Client {
+	c.options.Viewport = viewport
+	return c
+}
+
+// WithRegion configures the locale, time zone, and geolocation the page sees.
+func (c *Client) WithRegion(region *emulate.Region) *Client {
+	c.options.Region = region
+	return c
+}
+
+// WithAdblock configures ad and tracker blocking with the bundled list plus
+// the given filter list files.
+func (c *Client) WithAdblock(enabled bool, lists []string) *Client {
+	c.options.Adblock = enabled
+	c.options.AdblockLists = lists
+	return c
+}
+
+// WithConsoleCapture configures the client to record the page's console messages.
+func (c *Client) WithConsoleCapture(capture bool) *Client {
+	c.options.CaptureConsole = capture
+	return c
+}
+
+// WithHAR configures the client to record the page load's network activity.
+func (c *Client) WithHAR(record bool) *Client {
+	c.options.RecordHAR = record
+	return c
+}
+
+// WithValidators configures the client to capture the page's ETag and
+// Last-Modified for revalidating it later.
+func (c *Client) WithValidators(capture bool) *Client {
+	c.options.CaptureValidators = capture
+	return c
+}
+
+// WithDownloadDir configures where file downloads are saved.
+func (c *Client) WithDownloadDir(dir string) *Client {
+	c.options.DownloadDir = dir
+	return c
+}
+
+// WithIncognito configures the fetch to run in a throwaway browser context.
+func (c *Client) WithIncognito(incognito bool) *Client {
+	c.options.Incognito = incognito
+	return c
+}
+
+// WithProfile configures the fetch to run in a named, persistent browser profile.
+func (c *Client) WithProfile(name string) *Client {
+	c.options.Profile = name
+	return c
+}
+
+// WithRemoteChrome configures the fetch to use the Chrome at a DevTools endpoint.
+func (c *Client) WithRemoteChrome(endpoint string) *Client {
+	c.options.RemoteChrome = endpoint
+	return c
+}
+
+// WithQueueTimeout configures how long the fetch waits when the daemon is busy.
+func (c *Client) WithQueueTimeout(timeout time.Duration) *Client {
+	c.options.QueueTimeout = timeout
+	return c
+}
+
+// WithRetry configures the fetch to be retried after server errors,
+// navigation timeouts and dropped connections.
+func (c *Client) WithRetry(policy retry.Policy) *Client {
+	c.retry = policy
 	return c
 }
 
 // FetchContent fetches content from a URL using Chrome rendering via daemon.
 func (c *Client) FetchContent(ctx context.Context, url string) (string, error) {
-	client := daemon.NewDaemonClient()
+	client := daemon.NewDaemonClient().WithOptions(c.options)
 
-	// If we have a readiness checker, use enhanced fetch
-	if c.readinessChecker != nil {
-		return client.FetchContentWithReadiness(ctx, url, c.readinessChecker)
+	start := time.Now()
+	slog.Debug("fetching through daemon", "url", url)
+	var resp *daemon.Response
+	err := c.retry.Do(ctx, isTransient, func() error {
+		var err error
+		resp, err = client.Fetch(ctx, url, nil)
+		if err == nil && resp.Status != 0 && (resp.Status < 200 || resp.Status > 299) {
+			return &StatusError{URL: url, Code: resp.Status}
+		}
+		return err
+	})
+	if err != nil {
+		slog.Debug("daemon fetch failed", "url", url, "error", err)
+		return "", err
 	}
+	slog.Debug("daemon fetch finished", "url", url, "bytes", len(resp.Content), "duration", time.Since(start))
+
+	c.readiness = resp.Readiness
+	c.state = resp.State
+	c.console = resp.Console
+	c.har = resp.HAR
+	c.download = resp.Download
+	c.validators = resp.Validators
+	return resp.Content, nil
+}
+
+// isTransient reports whether a daemon fetch failed in a way that may not
+// happen again: a server error, a navigation timeout or a dropped connection.
+func isTransient(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code >= 500
+	}
+	message := err.Error()
+	for _, transient := range transientErrors {
+		if strings.Contains(message, transient) {
+			return true
+		}
+	}
+	return false
+}
+
+// Readiness returns the readiness detection result of the last successful fetch.
+func (c *Client) Readiness() *pageready.ReadinessResult {
+	return c.readiness
+}
+
+// State returns the storage state captured by the last fetch when saving was requested.
+func (c *Client) State() *session.StorageState {
+	return c.state
+}
+
+// Console returns the console messages recorded by the last fetch when capture was requested.
+func (c *Client) Console() []console.Message {
+	return c.console
+}
+
+// HAR returns the network log recorded by the last fetch when recording was requested.
+func (c *Client) HAR() *har.HAR {
+	return c.har
+}
+
+// Validators returns the ETag and Last-Modified of the page the last fetch
+// loaded when capturing was requested, or nil when it had neither.
+func (c *Client) Validators() *revalidate.Validators {
+	return c.validators
+}
 
-	// Otherwise use basic fetch
-	return client.FetchContent(ctx, url)
+// Download returns the file saved by the last fetch, if it ran into a download.
+func (c *Client) Download() *download.Download {
+	return c.download
 }
 
 // Shutdown is a no-op since we use global daemon management.
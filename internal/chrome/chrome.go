@@ -0,0 +1,232 @@
+// Package chrome downloads and locates a pinned Chrome for Testing build so sz
+// works on machines without a system Chrome.
+package chrome
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Version is the Chrome for Testing release sz installs.
+const Version = "131.0.6778.85"
+
+// defaultDownloadURL is where Chrome for Testing builds are published.
+const defaultDownloadURL = "https://storage.googleapis.com/chrome-for-testing-public"
+
+// Platform returns the Chrome for Testing platform name for this machine.
+func Platform() (string, error) {
+	switch runtime.GOOS + "/" + runtime.GOARCH {
+	case "linux/amd64":
+		return "linux64", nil
+	case "darwin/arm64":
+		return "mac-arm64", nil
+	case "darwin/amd64":
+		return "mac-x64", nil
+	case "windows/amd64":
+		return "win64", nil
+	case "windows/386":
+		return "win32", nil
+	}
+	return "", fmt.Errorf("no Chrome for Testing build for %s/%s: install Chrome and set ESSENZ_CHROME_PATH", runtime.GOOS, runtime.GOARCH)
+}
+
+// Dir returns the directory the pinned version is installed in, under the
+// user cache directory.
+func Dir() string {
+	base := filepath.Join(os.TempDir(), "essenz")
+	if cacheDir, err := os.UserCacheDir(); err == nil {
+		base = filepath.Join(cacheDir, "essenz")
+	}
+	return filepath.Join(base, "chrome", Version)
+}
+
+// Executable returns where the installed headless Chrome binary lives.
+func Executable() (string, error) {
+	platform, err := Platform()
+	if err != nil {
+		return "", err
+	}
+	name := "chrome-headless-shell"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return filepath.Join(Dir(), "chrome-headless-shell-"+platform, name), nil
+}
+
+// Installed returns the installed Chrome binary, if sz has downloaded one.
+func Installed() (string, bool) {
+	path, err := Executable()
+	if err != nil {
+		return "", false
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// downloadURL returns the archive URL for the pinned version. ESSENZ_CHROME_DOWNLOAD_URL
+// replaces the Chrome for Testing host, for mirrors.
+func downloadURL(platform string) string {
+	base := os.Getenv("ESSENZ_CHROME_DOWNLOAD_URL")
+	if base == "" {
+		base = defaultDownloadURL
+	}
+	return fmt.Sprintf("%s/%s/%s/chrome-headless-shell-%s.zip", strings.TrimSuffix(base, "/"), Version, platform, platform)
+}
+
+// Install downloads and unpacks the pinned Chrome, reporting progress to out,
+// and returns the path of its binary. An existing install is kept.
+func Install(ctx context.Context, out io.Writer) (string, error) {
+	if path, ok := Installed(); ok {
+		return path, nil
+	}
+	platform, err := Platform()
+	if err != nil {
+		return "", err
+	}
+
+	url := downloadURL(platform)
+	_, _ = fmt.Fprintf(out, "Downloading Chrome %s for %s from %s\n", Version, platform, url)
+
+	archive, err := download(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.Remove(archive) }()
+
+	// Unpack next to the final directory and move it into place, so an
+	// interrupted install never looks complete
+	dir := Dir()
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create Chrome directory: %w", err)
+	}
+	staging, err := os.MkdirTemp(filepath.Dir(dir), Version+"-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create Chrome directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(staging) }()
+
+	if err := unzip(archive, staging); err != nil {
+		return "", err
+	}
+	_ = os.RemoveAll(dir)
+	if err := os.Rename(staging, dir); err != nil {
+		return "", fmt.Errorf("failed to install Chrome: %w", err)
+	}
+
+	path, err := Executable()
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("downloaded archive does not contain %s", filepath.Base(path))
+	}
+	return path, nil
+}
+
+// Uninstall removes every Chrome version sz has downloaded.
+func Uninstall() error {
+	return os.RemoveAll(filepath.Dir(Dir()))
+}
+
+// download saves url to a temporary file and returns its path.
+func download(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download Chrome: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download Chrome: %s returned %s", url, resp.Status)
+	}
+
+	file, err := os.CreateTemp("", "essenz-chrome-*.zip")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return "", fmt.Errorf("failed to download Chrome: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// unzip extracts archive into dir, keeping file modes so binaries stay executable.
+func unzip(archive, dir string) error {
+	reader, err := zip.OpenReader(archive)
+	if err != nil {
+		return fmt.Errorf("failed to open Chrome archive: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	for _, file := range reader.File {
+		target := filepath.Join(dir, filepath.FromSlash(file.Name))
+		// Reject entries that would land outside dir
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid path in Chrome archive: %s", file.Name)
+		}
+		if err := extractFile(file, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractFile writes one archive entry to target.
+func extractFile(file *zip.File, target string) error {
+	mode := file.Mode()
+	if mode.IsDir() {
+		return os.MkdirAll(target, 0o755)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	if mode&os.ModeSymlink != 0 {
+		// macOS app bundles link their frameworks
+		src, err := file.Open()
+		if err != nil {
+			return err
+		}
+		defer func() { _ = src.Close() }()
+		link, err := io.ReadAll(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(string(link), target)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	return dst.Close()
+}
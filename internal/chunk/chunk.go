@@ -0,0 +1,213 @@
+// Package chunk splits distilled markdown into overlapping pieces at heading
+// and paragraph boundaries, sized for embedding models and LLM context.
+package chunk
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Chunk is one piece of a document, with what an embedding pipeline needs to
+// cite it.
+type Chunk struct {
+	Title   string `json:"title,omitempty"`
+	URL     string `json:"url,omitempty"`
+	Index   int    `json:"index"`             // Position of the chunk, from 0
+	Total   int    `json:"total"`             // Number of chunks in the document
+	Section string `json:"section,omitempty"` // Headings the chunk starts under, as "Guide > Install"
+	Content string `json:"content"`
+	Tokens  int    `json:"tokens,omitempty"` // Estimated tokens of Content, with --count-tokens
+}
+
+// block is a paragraph, heading, list or code block of the document.
+type block struct {
+	text    string
+	heading int    // Heading level, or 0
+	section string // Headings the block sits under, itself included
+}
+
+// Split cuts markdown into chunks of at most size characters. Chunks end at
+// block boundaries, preferring to start a new chunk at a heading once the
+// current one is half full, and each chunk after the first repeats up to
+// overlap characters of whole blocks from the end of the one before. A block
+// longer than size is cut at line, sentence or word boundaries.
+func Split(markdown string, size, overlap int) []Chunk {
+	if size <= 0 {
+		return nil
+	}
+	if overlap >= size {
+		overlap = size / 2
+	}
+
+	var chunks []Chunk
+	var current []block
+	length := 0 // Characters in current, counting the blank lines between blocks
+	emit := func() {
+		texts := make([]string, len(current))
+		for i, b := range current {
+			texts[i] = b.text
+		}
+		chunks = append(chunks, Chunk{Section: current[0].section, Content: strings.Join(texts, "\n\n")})
+	}
+
+	for _, b := range blocks(markdown, size) {
+		blockLength := runeLen(b.text)
+		if len(current) > 0 && (length+2+blockLength > size || (b.heading > 0 && length >= size/2)) {
+			emit()
+			current, length = carry(current, overlap)
+			// A heading starts its chunk rather than trailing the overlap
+			if b.heading > 0 || length+2+blockLength > size {
+				current, length = nil, 0
+			}
+		}
+		if len(current) > 0 {
+			length += 2
+		}
+		current = append(current, b)
+		length += blockLength
+	}
+	if len(current) > 0 {
+		emit()
+	}
+
+	for i := range chunks {
+		chunks[i].Index = i
+		chunks[i].Total = len(chunks)
+	}
+	return chunks
+}
+
+// carry returns the whole blocks from the end of a chunk that fit in overlap
+// characters, to start the next chunk with, and their length. It never
+// returns the whole chunk.
+func carry(chunk []block, overlap int) ([]block, int) {
+	kept, length := len(chunk), 0
+	for kept > 1 {
+		next := runeLen(chunk[kept-1].text)
+		if length > 0 {
+			next += 2
+		}
+		if length+next > overlap {
+			break
+		}
+		length += next
+		kept--
+	}
+	return append([]block(nil), chunk[kept:]...), length
+}
+
+// blocks splits markdown at blank lines, keeping fenced code whole, and cuts
+// blocks longer than size.
+func blocks(markdown string, size int) []block {
+	var result []block
+	var headings []string
+	var lines []string
+	inFence := false
+	flush := func() {
+		text := strings.TrimSpace(strings.Join(lines, "\n"))
+		lines = nil
+		if text == "" {
+			return
+		}
+		level := headingLevel(text)
+		if level > 0 {
+			if level-1 < len(headings) {
+				headings = headings[:level-1]
+			}
+			for len(headings) < level-1 {
+				headings = append(headings, "")
+			}
+			headings = append(headings, strings.TrimSpace(strings.TrimLeft(text, "#")))
+		}
+		section := joinSection(headings)
+		for _, piece := range cut(text, size) {
+			result = append(result, block{text: piece, heading: level, section: section})
+			level = 0
+		}
+	}
+
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if !inFence && trimmed == "" {
+			flush()
+			continue
+		}
+		// A heading is a block of its own even without blank lines around it
+		if !inFence && headingLevel(trimmed) > 0 {
+			flush()
+			lines = append(lines, line)
+			flush()
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+	return result
+}
+
+// headingLevel returns the level of an ATX heading line, or 0.
+func headingLevel(line string) int {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || !strings.HasPrefix(line[level:], " ") {
+		return 0
+	}
+	return level
+}
+
+// joinSection formats the headings above a block.
+func joinSection(headings []string) string {
+	var parts []string
+	for _, heading := range headings {
+		if heading != "" {
+			parts = append(parts, heading)
+		}
+	}
+	return strings.Join(parts, " > ")
+}
+
+// cut splits text into pieces of at most size characters, preferring to cut
+// after a line, then after a sentence, then between words.
+func cut(text string, size int) []string {
+	var pieces []string
+	for runeLen(text) > size {
+		limit := byteOffset(text, size)
+		end := -1
+		for _, sep := range []string{"\n", ". ", "? ", "! ", " "} {
+			if i := strings.LastIndex(text[:limit], sep); i > 0 {
+				end = i + len(sep)
+				break
+			}
+		}
+		if end <= 0 {
+			end = limit
+		}
+		pieces = append(pieces, strings.TrimSpace(text[:end]))
+		text = strings.TrimSpace(text[end:])
+	}
+	if text != "" {
+		pieces = append(pieces, text)
+	}
+	return pieces
+}
+
+// runeLen counts the characters in text.
+func runeLen(text string) int {
+	return utf8.RuneCountInString(text)
+}
+
+// byteOffset returns the byte offset of the n-th character of text.
+func byteOffset(text string, n int) int {
+	for i := range text {
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+	return len(text)
+}
@@ -0,0 +1,48 @@
+package chunk
+
+import "strings"
+
+// Section is one part of a document split at its headings.
+type Section struct {
+	Title   string // The heading the section starts with
+	Content string // The heading and everything under it
+}
+
+// Sections splits markdown before every heading of the given level or a
+// higher one, so each section is one top-level part of the document. Text
+// before the first such heading, such as the page title and introduction
+// when splitting at level two, becomes a section of its own, named after its
+// first heading. Headings in fenced code are not split at.
+func Sections(markdown string, level int) []Section {
+	var sections []Section
+	var lines []string
+	flush := func() {
+		content := strings.TrimSpace(strings.Join(lines, "\n"))
+		lines = nil
+		if content == "" {
+			return
+		}
+		title := ""
+		for _, line := range strings.Split(content, "\n") {
+			if headingLevel(line) > 0 {
+				title = strings.TrimSpace(strings.TrimLeft(line, "#"))
+				break
+			}
+		}
+		sections = append(sections, Section{Title: title, Content: content + "\n"})
+	}
+
+	inFence := false
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if heading := headingLevel(trimmed); !inFence && heading > 0 && heading <= level {
+			flush()
+		}
+		lines = append(lines, line)
+	}
+	flush()
+	return sections
+}
@@ -0,0 +1,353 @@
+// Package cite formats a page's metadata as a citation in BibTeX, APA or
+// MLA style, for researchers keeping track of their sources.
+package cite
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jewell-lgtm/essenz/internal/metadata"
+)
+
+// Styles are the citation styles Format knows.
+var Styles = []string{"apa", "mla", "bibtex"}
+
+// Source is what a citation names.
+type Source struct {
+	Title     string
+	Authors   []Name
+	Published time.Time // Zero when the page gives no date
+	Site      string
+	URL       string
+	Accessed  time.Time
+}
+
+// Name is a person's name split for citation, or an organization's name in
+// Family alone.
+type Name struct {
+	Given  string
+	Family string
+}
+
+// FromMetadata describes the page meta was read from, accessed at the given
+// time. The canonical URL is preferred to the one fetched.
+func FromMetadata(meta *metadata.Metadata, accessed time.Time) Source {
+	source := Source{
+		Title:    meta.Title,
+		Authors:  ParseAuthors(meta.Author),
+		Site:     meta.SiteName,
+		URL:      meta.URL,
+		Accessed: accessed,
+	}
+	if meta.Canonical != "" {
+		source.URL = meta.Canonical
+	}
+	source.Published = parseDate(meta.Published)
+	if source.Site == "" {
+		if u, err := url.Parse(source.URL); err == nil && u.Host != "" {
+			source.Site = strings.TrimPrefix(u.Hostname(), "www.")
+		}
+	}
+	return source
+}
+
+// Format writes the citation of source in style.
+func Format(source Source, style string) (string, error) {
+	switch style {
+	case "apa":
+		return apa(source), nil
+	case "mla":
+		return mla(source), nil
+	case "bibtex":
+		return bibtex(source), nil
+	}
+	return "", fmt.Errorf("unknown style %q: use %s", style, strings.Join(Styles, ", "))
+}
+
+// ParseAuthors splits an author line such as "Ada Lovelace and Charles
+// Babbage" or "Lovelace, Ada; Babbage, Charles" into names.
+func ParseAuthors(author string) []Name {
+	author = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(author), "By "))
+	if author == "" {
+		return nil
+	}
+	var parts []string
+	switch {
+	case strings.Contains(author, ";"):
+		parts = strings.Split(author, ";")
+	default:
+		for _, part := range strings.Split(strings.ReplaceAll(author, " & ", " and "), " and ") {
+			// "Lovelace, Ada" is one name, "Ada Lovelace, Charles Babbage" two
+			if pieces := strings.Split(part, ","); len(pieces) > 1 && strings.Contains(strings.TrimSpace(pieces[0]), " ") {
+				parts = append(parts, pieces...)
+				continue
+			}
+			parts = append(parts, part)
+		}
+	}
+
+	var names []Name
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if family, given, ok := strings.Cut(part, ","); ok {
+			names = append(names, Name{Given: strings.TrimSpace(given), Family: strings.TrimSpace(family)})
+			continue
+		}
+		fields := strings.Fields(part)
+		if len(fields) == 1 {
+			names = append(names, Name{Family: part})
+			continue
+		}
+		names = append(names, Name{Given: strings.Join(fields[:len(fields)-1], " "), Family: fields[len(fields)-1]})
+	}
+	return names
+}
+
+// dateLayouts are the forms pages give their publication date in.
+var dateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", time.DateOnly, "2006-01", "2006", time.RFC1123, time.RFC1123Z, "January 2, 2006", "2 January 2006"}
+
+// parseDate reads a publication date, or returns the zero time.
+func parseDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// initials abbreviates given names, "Ada Augusta" to "A. A.".
+func initials(given string) string {
+	var parts []string
+	for _, name := range strings.FieldsFunc(given, func(r rune) bool { return r == ' ' || r == '.' }) {
+		if hyphenated := strings.Split(name, "-"); len(hyphenated) > 1 {
+			var pieces []string
+			for _, piece := range hyphenated {
+				pieces = append(pieces, firstLetter(piece)+".")
+			}
+			parts = append(parts, strings.Join(pieces, "-"))
+			continue
+		}
+		parts = append(parts, firstLetter(name)+".")
+	}
+	return strings.Join(parts, " ")
+}
+
+// firstLetter returns the first letter of name.
+func firstLetter(name string) string {
+	for _, r := range name {
+		return string(r)
+	}
+	return ""
+}
+
+// apa formats an APA 7 reference to a web page.
+func apa(s Source) string {
+	var names []string
+	for _, name := range s.Authors {
+		if name.Given == "" {
+			names = append(names, name.Family)
+		} else {
+			names = append(names, name.Family+", "+initials(name.Given))
+		}
+	}
+	var authors string
+	switch {
+	case len(names) == 1:
+		authors = names[0]
+	case len(names) == 2:
+		authors = names[0] + ", & " + names[1]
+	case len(names) > 2 && len(names) <= 20:
+		authors = strings.Join(names[:len(names)-1], ", ") + ", & " + names[len(names)-1]
+	case len(names) > 20:
+		authors = strings.Join(names[:19], ", ") + ", . . . " + names[len(names)-1]
+	}
+
+	date := "(n.d.)."
+	if !s.Published.IsZero() {
+		date = "(" + strconv.Itoa(s.Published.Year()) + ", " + s.Published.Format("January 2") + ")."
+	}
+	title := "*" + sentence(s.Title) + "*"
+	if !strings.HasSuffix(title, "?*") && !strings.HasSuffix(title, "!*") {
+		title += "."
+	}
+	site := ""
+	if s.Site != "" && !strings.EqualFold(s.Site, authors) {
+		site = " " + s.Site + "."
+	}
+	if authors == "" {
+		// Without an author the title takes its place
+		return title + " " + date + site + " " + s.URL
+	}
+	if !strings.HasSuffix(authors, ".") {
+		authors += "."
+	}
+	return authors + " " + date + " " + title + site + " " + s.URL
+}
+
+// sentence trims a title's trailing period, which the style adds back.
+func sentence(title string) string {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "Untitled"
+	}
+	return strings.TrimRight(title, ".")
+}
+
+// mlaMonths are the month abbreviations MLA uses.
+var mlaMonths = []string{"Jan.", "Feb.", "Mar.", "Apr.", "May", "June", "July", "Aug.", "Sept.", "Oct.", "Nov.", "Dec."}
+
+// mlaDate formats a date as MLA does, "5 Mar. 2024".
+func mlaDate(t time.Time) string {
+	return strconv.Itoa(t.Day()) + " " + mlaMonths[t.Month()-1] + " " + strconv.Itoa(t.Year())
+}
+
+// mla formats an MLA 9 works-cited entry for a web page.
+func mla(s Source) string {
+	var parts []string
+	if len(s.Authors) > 0 {
+		first := s.Authors[0]
+		author := first.Family
+		if first.Given != "" {
+			author += ", " + first.Given
+		}
+		switch {
+		case len(s.Authors) == 2:
+			second := s.Authors[1]
+			author += ", and " + strings.TrimSpace(second.Given+" "+second.Family)
+		case len(s.Authors) > 2:
+			author += ", et al"
+		}
+		parts = append(parts, strings.TrimSuffix(author, ".")+".")
+	}
+
+	title := sentence(s.Title)
+	if strings.HasSuffix(title, "?") || strings.HasSuffix(title, "!") {
+		parts = append(parts, "\""+title+"\"")
+	} else {
+		parts = append(parts, "\""+title+".\"")
+	}
+
+	var container []string
+	if s.Site != "" {
+		container = append(container, "*"+s.Site+"*")
+	}
+	if !s.Published.IsZero() {
+		container = append(container, mlaDate(s.Published))
+	}
+	if s.URL != "" {
+		container = append(container, strings.TrimPrefix(strings.TrimPrefix(s.URL, "https://"), "http://"))
+	}
+	if len(container) > 0 {
+		parts = append(parts, strings.Join(container, ", ")+".")
+	}
+	if !s.Accessed.IsZero() {
+		parts = append(parts, "Accessed "+mlaDate(s.Accessed)+".")
+	}
+	return strings.Join(parts, " ")
+}
+
+// bibtex formats a @misc entry, with the url and urldate fields biblatex
+// and natbib styles read.
+func bibtex(s Source) string {
+	var fields [][2]string
+	if len(s.Authors) > 0 {
+		var names []string
+		for _, name := range s.Authors {
+			if name.Given == "" {
+				// Braces keep an organization from being split into names
+				names = append(names, "{"+escapeTeX(name.Family)+"}")
+			} else {
+				names = append(names, escapeTeX(name.Family)+", "+escapeTeX(name.Given))
+			}
+		}
+		fields = append(fields, [2]string{"author", strings.Join(names, " and ")})
+	}
+	fields = append(fields, [2]string{"title", "{" + escapeTeX(sentence(s.Title)) + "}"})
+	if s.Site != "" {
+		fields = append(fields, [2]string{"howpublished", escapeTeX(s.Site)})
+	}
+	if !s.Published.IsZero() {
+		fields = append(fields,
+			[2]string{"year", strconv.Itoa(s.Published.Year())},
+			[2]string{"month", strings.ToLower(s.Published.Format("Jan"))})
+	}
+	if s.URL != "" {
+		fields = append(fields, [2]string{"url", s.URL})
+	}
+	if !s.Accessed.IsZero() {
+		fields = append(fields,
+			[2]string{"urldate", s.Accessed.Format(time.DateOnly)},
+			[2]string{"note", "Accessed " + s.Accessed.Format(time.DateOnly)})
+	}
+
+	var entry strings.Builder
+	entry.WriteString("@misc{" + citeKey(s) + ",\n")
+	for i, field := range fields {
+		// Months are macros and go without braces
+		value := "{" + field[1] + "}"
+		if field[0] == "month" {
+			value = field[1]
+		}
+		entry.WriteString("  " + field[0] + " = " + value)
+		if i < len(fields)-1 {
+			entry.WriteString(",")
+		}
+		entry.WriteString("\n")
+	}
+	entry.WriteString("}")
+	return entry.String()
+}
+
+// citeKey builds a key such as lovelace1843notes from the first author, or
+// the site, the year and the first word of the title.
+func citeKey(s Source) string {
+	var key strings.Builder
+	name := s.Site
+	if len(s.Authors) > 0 {
+		name = s.Authors[0].Family
+	}
+	key.WriteString(keyWord(name))
+	if !s.Published.IsZero() {
+		key.WriteString(strconv.Itoa(s.Published.Year()))
+	}
+	for _, word := range strings.Fields(s.Title) {
+		if w := keyWord(word); len(w) > 3 {
+			key.WriteString(w)
+			break
+		}
+	}
+	if key.Len() == 0 {
+		return "web"
+	}
+	return key.String()
+}
+
+// keyWord lowercases a word and keeps only its ASCII letters and digits.
+func keyWord(word string) string {
+	var out strings.Builder
+	for _, r := range strings.ToLower(word) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
+
+// escapeTeX escapes the characters TeX treats specially.
+func escapeTeX(text string) string {
+	return strings.NewReplacer(
+		`\`, `\textbackslash{}`,
+		"{", `\{`, "}", `\}`,
+		"&", `\&`, "%", `\%`, "$", `\$`, "#", `\#`, "_", `\_`,
+		"~", `\textasciitilde{}`, "^", `\textasciicircum{}`,
+	).Replace(text)
+}
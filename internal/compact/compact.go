@@ -0,0 +1,130 @@
+// Package compact squeezes distilled markdown into as few tokens as it can
+// take while keeping its text and structure, for pasting into a language
+// model's context.
+package compact
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Inline markdown that only decorates text, reduced to the text itself.
+var (
+	imagePattern     = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	linkPattern      = regexp.MustCompile(`\[([^\]]*)\]\((?:[^()]|\([^)]*\))*\)`)
+	refLinkPattern   = regexp.MustCompile(`\[([^\]]+)\]\[[^\]]*\]`)
+	autolinkPattern  = regexp.MustCompile(`<((?:https?|mailto):[^>\s]+)>`)
+	strongPattern    = regexp.MustCompile(`(\*\*|__)(\S(?:.*?\S)?)(\*\*|__)`)
+	starPattern      = regexp.MustCompile(`\*([^*\s](?:[^*]*?[^*\s])?)\*`)
+	underlinePattern = regexp.MustCompile(`(^|\W)_([^_\s](?:[^_]*?[^_\s])?)_($|\W)`)
+	strikePattern    = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+	spacePattern     = regexp.MustCompile(`[ \t]{2,}`)
+	ruleLinePattern  = regexp.MustCompile(`^\s*(?:[-*_]\s*){3,}$`)
+	tableRulePattern = regexp.MustCompile(`^\s*\|?\s*:?-{3,}:?\s*(?:\|\s*:?-{3,}:?\s*)*\|?\s*$`)
+	refDefPattern    = regexp.MustCompile(`^\s*\[[^\]]+\]:\s+\S+`)
+	listPattern      = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+`)
+)
+
+// Markdown compacts a markdown document: links and images become their bare
+// text, emphasis and rules are dropped, runs of whitespace and blank lines
+// collapse, and table cells lose their padding. Fenced code is kept as it is.
+// With flatten, headings become labels naming their section, as
+// "[Guide > Install]", instead of markdown headings.
+func Markdown(markdown string, flatten bool) string {
+	var out []string
+	var headings []string
+	inFence := false
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			out = append(out, trimmed)
+			continue
+		}
+		if inFence {
+			out = append(out, strings.TrimRight(line, " \t"))
+			continue
+		}
+		if trimmed == "" || ruleLinePattern.MatchString(trimmed) || tableRulePattern.MatchString(trimmed) || refDefPattern.MatchString(trimmed) {
+			continue
+		}
+
+		if level := headingLevel(trimmed); level > 0 {
+			text := Inline(strings.TrimSpace(strings.Trim(trimmed, "#")))
+			if !flatten {
+				out = append(out, strings.Repeat("#", level)+" "+text)
+				continue
+			}
+			if level-1 < len(headings) {
+				headings = headings[:level-1]
+			}
+			for len(headings) < level-1 {
+				headings = append(headings, "")
+			}
+			headings = append(headings, text)
+			out = append(out, "["+joinSection(headings)+"]")
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "|") {
+			out = append(out, tableRow(trimmed))
+			continue
+		}
+		// Nesting is kept, one space per level, as list items need it
+		indent := ""
+		if match := listPattern.FindStringSubmatch(line); match != nil {
+			indent = strings.Repeat(" ", len(match[1])/2)
+		}
+		text := Inline(trimmed)
+		if text != "" {
+			out = append(out, indent+text)
+		}
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+// Inline reduces the inline markdown of one line to its text.
+func Inline(text string) string {
+	text = imagePattern.ReplaceAllString(text, "$1")
+	text = linkPattern.ReplaceAllString(text, "$1")
+	text = refLinkPattern.ReplaceAllString(text, "$1")
+	text = autolinkPattern.ReplaceAllString(text, "$1")
+	text = strongPattern.ReplaceAllString(text, "$2")
+	text = strikePattern.ReplaceAllString(text, "$1")
+	text = starPattern.ReplaceAllString(text, "$1")
+	// Underscores inside words, as in snake_case, are not emphasis
+	text = underlinePattern.ReplaceAllString(text, "$1$2$3")
+	return strings.TrimSpace(spacePattern.ReplaceAllString(text, " "))
+}
+
+// tableRow trims the padding and outer pipes of a table row.
+func tableRow(row string) string {
+	cells := strings.Split(strings.Trim(row, "|"), "|")
+	for i, cell := range cells {
+		cells[i] = Inline(cell)
+	}
+	return strings.Join(cells, "|")
+}
+
+// headingLevel returns the level of an ATX heading line, or 0.
+func headingLevel(line string) int {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || !strings.HasPrefix(line[level:], " ") {
+		return 0
+	}
+	return level
+}
+
+// joinSection formats the path of headings down to a section.
+func joinSection(headings []string) string {
+	var parts []string
+	for _, heading := range headings {
+		if heading != "" {
+			parts = append(parts, heading)
+		}
+	}
+	return strings.Join(parts, " > ")
+}
@@ -0,0 +1,264 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the settings read from config.yaml in the configuration directory.
+type Config struct {
+	Browser   BrowserConfig     `yaml:"browser"`
+	Presets   map[string]Preset `yaml:"presets,omitempty"`
+	Schedules []Schedule        `yaml:"schedules,omitempty"`
+	LLM       LLMConfig         `yaml:"llm,omitempty"`
+	Translate TranslateConfig   `yaml:"translate,omitempty"`
+	Push      PushConfig        `yaml:"push,omitempty"`
+}
+
+// BrowserConfig holds browser settings.
+type BrowserConfig struct {
+	// ChromePaths are browser executables tried in order before the built-in locations
+	ChromePaths []string `yaml:"chrome_paths,omitempty"`
+}
+
+// LLMConfig sets up the OpenAI-compatible chat completions endpoint that
+// features such as sz summarize and --describe-images send content to. Nothing leaves the machine
+// unless one of those features is asked for.
+type LLMConfig struct {
+	// Endpoint is the API's base URL, e.g. https://api.openai.com/v1 or
+	// http://localhost:11434/v1 for a local model
+	Endpoint string `yaml:"endpoint,omitempty"`
+	Model    string `yaml:"model,omitempty"`
+	// APIKey is sent as a bearer token; ESSENZ_LLM_API_KEY takes precedence
+	APIKey    string `yaml:"api_key,omitempty"`
+	MaxTokens int    `yaml:"max_tokens,omitempty"`
+	// Prompt is the summary prompt, a Go template over .Title, .URL and .Content
+	Prompt string `yaml:"prompt,omitempty"`
+	// VisionModel describes images for --describe-images, defaulting to Model
+	VisionModel string `yaml:"vision_model,omitempty"`
+}
+
+// TranslateConfig selects the backend --translate uses: the language model
+// under llm, or a DeepL-compatible API.
+type TranslateConfig struct {
+	Backend string `yaml:"backend,omitempty"` // llm (default) or deepl
+	// Endpoint is the DeepL API's base URL, https://api-free.deepl.com by default
+	Endpoint string `yaml:"endpoint,omitempty"`
+	// APIKey authenticates with DeepL; ESSENZ_TRANSLATE_API_KEY takes precedence
+	APIKey string `yaml:"api_key,omitempty"`
+}
+
+// PushConfig holds the accounts sz push sends pages to.
+type PushConfig struct {
+	Pocket     PocketConfig     `yaml:"pocket,omitempty"`
+	Instapaper InstapaperConfig `yaml:"instapaper,omitempty"`
+	Readwise   ReadwiseConfig   `yaml:"readwise,omitempty"`
+}
+
+// PocketConfig authenticates with the Pocket API.
+type PocketConfig struct {
+	ConsumerKey string `yaml:"consumer_key,omitempty"`
+	AccessToken string `yaml:"access_token,omitempty"`
+	// Endpoint is the API's base URL, https://getpocket.com/v3 by default
+	Endpoint string `yaml:"endpoint,omitempty"`
+}
+
+// InstapaperConfig authenticates with the Instapaper Simple API.
+type InstapaperConfig struct {
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	// Endpoint is the API's base URL, https://www.instapaper.com/api by default
+	Endpoint string `yaml:"endpoint,omitempty"`
+}
+
+// ReadwiseConfig authenticates with the Readwise and Reader APIs.
+type ReadwiseConfig struct {
+	// Token is the access token from readwise.io/access_token
+	Token string `yaml:"token,omitempty"`
+	// Endpoint is the API's base URL, https://readwise.io/api by default
+	Endpoint string `yaml:"endpoint,omitempty"`
+}
+
+// Path returns the location of config.yaml.
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// Load reads config.yaml. It returns an empty configuration without error
+// when the file does not exist.
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Preset is a named bundle of command line settings, selected with --preset,
+// mapping flag names to values, for example:
+//
+//	presets:
+//	  docs:
+//	    wait-for-selector: main
+//	    content-filter: true
+//	    exclude-selector: [".sidebar", ".toc"]
+type Preset map[string]any
+
+// Preset returns the preset with the given name.
+func (c *Config) Preset(name string) (Preset, error) {
+	preset, ok := c.Presets[name]
+	if !ok {
+		names := make([]string, 0, len(c.Presets))
+		for known := range c.Presets {
+			names = append(names, known)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return nil, fmt.Errorf("unknown preset %q: no presets are defined in the config", name)
+		}
+		return nil, fmt.Errorf("unknown preset %q: defined presets are %s", name, strings.Join(names, ", "))
+	}
+	return preset, nil
+}
+
+// Values returns each flag's values as strings, in flag name order. A list
+// sets a repeatable flag once per item.
+func (p Preset) Values() ([]FlagValue, error) {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make([]FlagValue, 0, len(p))
+	for _, name := range names {
+		flag := FlagValue{Name: name}
+		switch value := p[name].(type) {
+		case []any:
+			for _, item := range value {
+				text, err := scalarString(item)
+				if err != nil {
+					return nil, fmt.Errorf("preset setting %s: %w", name, err)
+				}
+				flag.Values = append(flag.Values, text)
+			}
+		default:
+			text, err := scalarString(value)
+			if err != nil {
+				return nil, fmt.Errorf("preset setting %s: %w", name, err)
+			}
+			flag.Values = []string{text}
+		}
+		values = append(values, flag)
+	}
+	return values, nil
+}
+
+// FlagValue is one flag set by a preset.
+type FlagValue struct {
+	Name   string
+	Values []string
+}
+
+// scalarString formats a YAML scalar the way it would be typed on the command line.
+func scalarString(value any) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case bool, int, float64:
+		return fmt.Sprint(value), nil
+	}
+	return "", fmt.Errorf("expected a string, number, boolean or list, got %T", value)
+}
+
+// Schedule is a page the daemon distills every Interval, for example:
+//
+//	schedules:
+//	  - name: changelog
+//	    url: https://example.com/changelog
+//	    interval: 1h
+//	    output: ~/notes/changelog.md
+//	    diff: ~/notes/changelog.diff
+//	    on_change: mail -s "Changelog updated" me@example.com
+//
+// The markdown is written to Output whenever it changes, a unified diff of
+// each change is appended to Diff when set, and OnChange is run through the
+// shell with the new markdown on stdin.
+type Schedule struct {
+	Name     string        `yaml:"name,omitempty"`
+	URL      string        `yaml:"url"`
+	Interval time.Duration `yaml:"interval"`
+	Output   string        `yaml:"output"`
+	Diff     string        `yaml:"diff,omitempty"`
+	Profile  string        `yaml:"profile,omitempty"`   // Browser profile whose session the fetch uses
+	OnChange string        `yaml:"on_change,omitempty"` // Command run when the content changes
+	// NoRevalidate fetches the page every time, even when the server says it
+	// has not changed, for pages whose content is loaded by scripts
+	NoRevalidate bool `yaml:"no_revalidate,omitempty"`
+}
+
+// Label returns the schedule's name, or its URL when it has none.
+func (s *Schedule) Label() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.URL
+}
+
+// Validate checks the schedule's settings and expands a leading ~ in its
+// paths to the home directory.
+func (s *Schedule) Validate() error {
+	if !strings.HasPrefix(s.URL, "http://") && !strings.HasPrefix(s.URL, "https://") {
+		return fmt.Errorf("schedule %s: url must be http or https", s.Label())
+	}
+	if s.Interval <= 0 {
+		return fmt.Errorf("schedule %s: interval must be a positive duration such as 30m or 1h", s.Label())
+	}
+	if s.Output == "" {
+		return fmt.Errorf("schedule %s: output is required", s.Label())
+	}
+	var err error
+	if s.Output, err = ExpandHome(s.Output); err != nil {
+		return err
+	}
+	if s.Diff, err = ExpandHome(s.Diff); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ExpandHome replaces a leading ~ in path with the user's home directory.
+func ExpandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, path[1:]), nil
+}
@@ -0,0 +1,22 @@
+// Package config provides configuration loading and storage for sz.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Dir returns the sz configuration directory, honouring XDG_CONFIG_HOME and
+// defaulting to ~/.config/essenz.
+func Dir() (string, error) {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "essenz"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "essenz"), nil
+}
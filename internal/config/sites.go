@@ -0,0 +1,138 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SiteRules holds per-site content filter overrides.
+type SiteRules struct {
+	Host     string   `yaml:"host"`
+	Preserve []string `yaml:"preserve,omitempty"` // CSS selectors always kept
+	Exclude  []string `yaml:"exclude,omitempty"`  // CSS selectors always removed
+}
+
+// SiteHost returns the normalized host used to key site rules for a URL.
+// It returns an empty string for targets without a host, such as file paths.
+func SiteHost(target string) string {
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Hostname() == "" {
+		return ""
+	}
+	return normalizeHost(parsed.Hostname())
+}
+
+// SiteRulesPath returns the rule file location for a host.
+func SiteRulesPath(host string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sites", normalizeHost(host)+".yaml"), nil
+}
+
+// LoadSiteRules reads the rule file for a host. It returns nil without error
+// when no rules have been saved for the host.
+func LoadSiteRules(host string) (*SiteRules, error) {
+	if host == "" {
+		return nil, nil
+	}
+
+	path, err := SiteRulesPath(host)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read site rules: %w", err)
+	}
+
+	var rules SiteRules
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse site rules %s: %w", path, err)
+	}
+	if rules.Host == "" {
+		rules.Host = normalizeHost(host)
+	}
+	return &rules, nil
+}
+
+// Save writes the rules to the host's rule file, creating directories as needed.
+func (r *SiteRules) Save() error {
+	path, err := SiteRulesPath(r.Host)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create site rules directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode site rules: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write site rules: %w", err)
+	}
+	return nil
+}
+
+// Merge adds selectors from other that are not already present. The newer
+// decision about a selector wins: one other preserves is dropped from Exclude,
+// and one it excludes is dropped from Preserve, since exclusion is checked
+// first and would otherwise hide the block for good.
+func (r *SiteRules) Merge(other *SiteRules) {
+	if other == nil {
+		return
+	}
+	r.Preserve = appendUnique(removeAll(r.Preserve, other.Exclude...), other.Preserve...)
+	r.Exclude = appendUnique(removeAll(r.Exclude, other.Preserve...), other.Exclude...)
+}
+
+func appendUnique(values []string, additions ...string) []string {
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		seen[value] = true
+	}
+	for _, value := range additions {
+		if !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func removeAll(values []string, removals ...string) []string {
+	if len(removals) == 0 {
+		return values
+	}
+	remove := make(map[string]bool, len(removals))
+	for _, value := range removals {
+		remove[value] = true
+	}
+	var kept []string
+	for _, value := range values {
+		if !remove[value] {
+			kept = append(kept, value)
+		}
+	}
+	return kept
+}
+
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	return strings.TrimPrefix(host, "www.")
+}
@@ -0,0 +1,184 @@
+// Package console records page console output and script errors during a fetch.
+package console
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	cdplog "github.com/chromedp/cdproto/log"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// Message is one console call, uncaught exception, or browser log entry.
+type Message struct {
+	Level  string    `json:"level"`  // log, info, warning, error, debug, ...
+	Source string    `json:"source"` // console, exception, or a browser log source such as network
+	Text   string    `json:"text"`
+	URL    string    `json:"url,omitempty"`
+	Line   int64     `json:"line,omitempty"` // 1-based, zero when unknown
+	Time   time.Time `json:"time"`
+}
+
+// String formats a message as a single log line.
+func (m Message) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] ", m.Level)
+	if m.Source != "console" {
+		fmt.Fprintf(&b, "(%s) ", m.Source)
+	}
+	b.WriteString(m.Text)
+	if m.URL != "" {
+		fmt.Fprintf(&b, " (%s", m.URL)
+		if m.Line > 0 {
+			fmt.Fprintf(&b, ":%d", m.Line)
+		}
+		b.WriteString(")")
+	}
+	return b.String()
+}
+
+// Recorder collects messages from a tab. It is safe for concurrent use since
+// CDP events arrive on their own goroutine.
+type Recorder struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewRecorder creates an empty recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Start subscribes to console and error events in a tab. It must run before
+// navigation so messages logged during page load are captured.
+func (r *Recorder) Start(chromeCtx context.Context) error {
+	chromedp.ListenTarget(chromeCtx, func(ev interface{}) {
+		switch ev := ev.(type) {
+		case *runtime.EventConsoleAPICalled:
+			r.add(consoleMessage(ev))
+		case *runtime.EventExceptionThrown:
+			r.add(exceptionMessage(ev))
+		case *cdplog.EventEntryAdded:
+			r.add(logMessage(ev))
+		}
+	})
+
+	if err := chromedp.Run(chromeCtx, runtime.Enable(), cdplog.Enable()); err != nil {
+		return fmt.Errorf("failed to enable console capture: %w", err)
+	}
+	return nil
+}
+
+// Messages returns the messages recorded so far.
+func (r *Recorder) Messages() []Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Message{}, r.messages...)
+}
+
+// add appends a message.
+func (r *Recorder) add(message Message) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, message)
+}
+
+// consoleMessage converts a console API call.
+func consoleMessage(ev *runtime.EventConsoleAPICalled) Message {
+	args := make([]string, 0, len(ev.Args))
+	for _, arg := range ev.Args {
+		args = append(args, describe(arg))
+	}
+
+	message := Message{
+		Level:  consoleLevel(ev.Type),
+		Source: "console",
+		Text:   strings.Join(args, " "),
+		Time:   timestamp(ev.Timestamp),
+	}
+	if ev.StackTrace != nil && len(ev.StackTrace.CallFrames) > 0 {
+		frame := ev.StackTrace.CallFrames[0]
+		message.URL = frame.URL
+		message.Line = frame.LineNumber + 1
+	}
+	return message
+}
+
+// exceptionMessage converts an uncaught exception.
+func exceptionMessage(ev *runtime.EventExceptionThrown) Message {
+	details := ev.ExceptionDetails
+	text := details.Text
+	if details.Exception != nil && details.Exception.Description != "" {
+		// The description holds the error message and stack
+		text = strings.SplitN(details.Exception.Description, "\n", 2)[0]
+	}
+
+	message := Message{
+		Level:  "error",
+		Source: "exception",
+		Text:   text,
+		URL:    details.URL,
+		Line:   details.LineNumber + 1,
+		Time:   timestamp(ev.Timestamp),
+	}
+	if message.URL == "" && details.StackTrace != nil && len(details.StackTrace.CallFrames) > 0 {
+		message.URL = details.StackTrace.CallFrames[0].URL
+	}
+	return message
+}
+
+// logMessage converts a browser log entry, such as a failed resource load.
+func logMessage(ev *cdplog.EventEntryAdded) Message {
+	entry := ev.Entry
+	return Message{
+		Level:  string(entry.Level),
+		Source: string(entry.Source),
+		Text:   entry.Text,
+		URL:    entry.URL,
+		Line:   entry.LineNumber,
+		Time:   timestamp(entry.Timestamp),
+	}
+}
+
+// consoleLevel maps console API types onto log levels.
+func consoleLevel(t runtime.APIType) string {
+	switch t {
+	case runtime.APITypeWarning:
+		return "warning"
+	case runtime.APITypeError, runtime.APITypeAssert:
+		return "error"
+	default:
+		return string(t)
+	}
+}
+
+// describe renders a console argument the way DevTools shows it.
+func describe(arg *runtime.RemoteObject) string {
+	if len(arg.Value) > 0 {
+		var text string
+		if err := json.Unmarshal(arg.Value, &text); err == nil {
+			return text
+		}
+		return string(arg.Value)
+	}
+	if arg.UnserializableValue != "" {
+		return string(arg.UnserializableValue)
+	}
+	if arg.Description != "" {
+		return arg.Description
+	}
+	return string(arg.Type)
+}
+
+// timestamp converts a CDP timestamp, which may be missing.
+func timestamp(t *runtime.Timestamp) time.Time {
+	if t == nil {
+		return time.Now()
+	}
+	return t.Time()
+}
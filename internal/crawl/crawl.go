@@ -0,0 +1,195 @@
+// Package crawl follows links outward from a start page, level by level, and
+// hands each page it reaches to a caller-supplied distill function.
+package crawl
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jewell-lgtm/essenz/internal/batch"
+)
+
+// skippedExtensions mark links to files that are not pages worth distilling.
+var skippedExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".webp": true, ".ico": true,
+	".pdf": true, ".zip": true, ".gz": true, ".tar": true, ".tgz": true, ".dmg": true, ".exe": true,
+	".mp3": true, ".mp4": true, ".webm": true, ".mov": true,
+	".css": true, ".js": true, ".json": true, ".xml": true,
+}
+
+// Scope decides which links a crawl follows.
+type Scope struct {
+	Start      *url.URL
+	SameDomain bool             // Only follow links on the start page's host or its subdomains
+	Include    []*regexp.Regexp // When set, a link's URL must match one of these
+	Exclude    []*regexp.Regexp // A link whose URL matches any of these is not followed
+}
+
+// Allows reports whether the crawl should follow a link to u.
+func (s Scope) Allows(u *url.URL) bool {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	if skippedExtensions[strings.ToLower(path.Ext(u.Path))] {
+		return false
+	}
+	if s.SameDomain {
+		host, start := strings.ToLower(u.Hostname()), strings.ToLower(s.Start.Hostname())
+		if host != start && !strings.HasSuffix(host, "."+start) {
+			return false
+		}
+	}
+
+	link := u.String()
+	for _, pattern := range s.Exclude {
+		if pattern.MatchString(link) {
+			return false
+		}
+	}
+	if len(s.Include) == 0 {
+		return true
+	}
+	for _, pattern := range s.Include {
+		if pattern.MatchString(link) {
+			return true
+		}
+	}
+	return false
+}
+
+// Options controls how far and how fast a crawl goes.
+type Options struct {
+	Depth    int           // Link hops to follow from the start page; 0 fetches only the start page
+	MaxPages int           // Stop queuing pages after this many; 0 means no limit
+	Delay    time.Duration // Least time between starting two fetches on the same host
+	Scope    Scope
+	Batch    batch.Options // Concurrency for each level
+}
+
+// Page is the outcome of distilling one page.
+type Page struct {
+	URL     string
+	Depth   int // Link hops from the start page
+	Content string
+	Err     error
+}
+
+// DistillFunc distills one page and returns the links in its content, which
+// may be relative to the page.
+type DistillFunc func(ctx context.Context, pageURL string) (content string, links []string, err error)
+
+// Run crawls from start, calling emit with each page from the calling
+// goroutine only. Pages are distilled one level at a time, so every page is
+// reached by its shortest path, and each URL is visited once.
+func Run(ctx context.Context, start string, opts Options, distill DistillFunc, emit func(Page)) {
+	seen := map[string]bool{normalize(opts.Scope.Start): true}
+	frontier := []string{start}
+	queued := 1
+	throttle := newThrottle(opts.Delay)
+
+	for depth := 0; depth <= opts.Depth && len(frontier) > 0; depth++ {
+		if ctx.Err() != nil {
+			return
+		}
+
+		var mu sync.Mutex
+		links := make(map[string][]string)
+		level := func(ctx context.Context, pageURL string) (string, error) {
+			if err := throttle.wait(ctx, pageURL); err != nil {
+				return "", err
+			}
+			content, found, err := distill(ctx, pageURL)
+			if err != nil {
+				return "", err
+			}
+			mu.Lock()
+			links[pageURL] = found
+			mu.Unlock()
+			return content, nil
+		}
+
+		var next []string
+		batch.Run(ctx, frontier, opts.Batch, level, func(result batch.Result) {
+			emit(Page{URL: result.Target, Depth: depth, Content: result.Content, Err: result.Err})
+			if depth == opts.Depth {
+				return
+			}
+
+			base, err := url.Parse(result.Target)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			found := links[result.Target]
+			mu.Unlock()
+			for _, href := range found {
+				link, err := base.Parse(href)
+				if err != nil || !opts.Scope.Allows(link) {
+					continue
+				}
+				key := normalize(link)
+				if seen[key] || (opts.MaxPages > 0 && queued >= opts.MaxPages) {
+					continue
+				}
+				seen[key] = true
+				queued++
+				next = append(next, key)
+			}
+		})
+		frontier = next
+	}
+}
+
+// normalize drops the parts of a URL that do not change the page, so the
+// same page is not visited twice.
+func normalize(u *url.URL) string {
+	clean := *u
+	clean.Fragment = ""
+	clean.Host = strings.ToLower(clean.Host)
+	if clean.Path == "" {
+		clean.Path = "/"
+	}
+	return clean.String()
+}
+
+// throttle spaces out fetches on each host by a fixed delay.
+type throttle struct {
+	mu    sync.Mutex
+	delay time.Duration
+	next  map[string]time.Time
+}
+
+// newThrottle creates a throttle; a zero delay never waits.
+func newThrottle(delay time.Duration) *throttle {
+	return &throttle{delay: delay, next: make(map[string]time.Time)}
+}
+
+// wait blocks until pageURL's host may be fetched again.
+func (t *throttle) wait(ctx context.Context, pageURL string) error {
+	u, err := url.Parse(pageURL)
+	if t.delay <= 0 || err != nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	slot := time.Now()
+	if next := t.next[u.Host]; next.After(slot) {
+		slot = next
+	}
+	t.next[u.Host] = slot.Add(t.delay)
+	t.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(slot))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,58 @@
+package daemon
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// errUnauthorized is reported to clients whose request token does not match the daemon's.
+var errUnauthorized = errors.New("unauthorized: request token does not match the daemon's token file")
+
+// tokenPath returns where the daemon listening on the endpoint keeps its auth token.
+func (e Endpoint) tokenPath() string {
+	return e.sidecarPath(".token")
+}
+
+// writeToken creates a fresh random token readable only by the current user.
+// An existing file is replaced so a token from a previous daemon cannot be reused.
+func writeToken(path string) (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate auth token: %w", err)
+	}
+	token := hex.EncodeToString(secret)
+
+	_ = os.Remove(path)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create auth token file: %w", err)
+	}
+	if _, err := file.WriteString(token); err != nil {
+		_ = file.Close()
+		return "", fmt.Errorf("failed to write auth token file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write auth token file: %w", err)
+	}
+	return token, nil
+}
+
+// readToken reads the token the daemon wrote. A missing file yields an empty
+// token, which the daemon rejects.
+func readToken(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// tokenMatches compares tokens in constant time.
+func tokenMatches(expected, given string) bool {
+	return expected != "" && subtle.ConstantTimeCompare([]byte(expected), []byte(given)) == 1
+}
@@ -0,0 +1,60 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		given    string
+		want     bool
+	}{
+		{"same token", "3f2a9c", "3f2a9c", true},
+		{"different token", "3f2a9c", "3f2a9d", false},
+		{"missing token", "3f2a9c", "", false},
+		{"prefix of the token", "3f2a9c", "3f2a", false},
+		{"token with extra characters", "3f2a9c", "3f2a9c00", false},
+		{"daemon without a token", "", "", false},
+		{"daemon without a token given one", "", "3f2a9c", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tokenMatches(tt.expected, tt.given))
+		})
+	}
+}
+
+func TestWriteToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.sock.token")
+
+	first, err := writeToken(path)
+	require.NoError(t, err)
+	assert.Len(t, first, 64, "The token should be 32 random bytes in hex")
+	assert.Equal(t, first, readToken(path))
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "Only the current user should read the token")
+	}
+
+	second, err := writeToken(path)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second, "A restarted daemon should get a fresh token")
+	assert.Equal(t, second, readToken(path))
+	assert.False(t, tokenMatches(second, first), "The previous daemon's token should be rejected")
+}
+
+func TestReadTokenMissingFile(t *testing.T) {
+	given := readToken(filepath.Join(t.TempDir(), "missing.token"))
+	assert.Empty(t, given)
+	assert.False(t, tokenMatches("3f2a9c", given))
+}
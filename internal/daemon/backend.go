@@ -0,0 +1,105 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Backend is a browser engine the daemon renders pages with. Chrome over the
+// DevTools protocol is the built-in backend; engines such as Firefox over
+// WebDriver BiDi plug in by implementing this interface and registering a
+// constructor in backends.
+type Backend interface {
+	// Fetch loads the request's page, starting the browser if needed, and
+	// returns the response for the client.
+	Fetch(ctx context.Context, logger *slog.Logger, req Request) (Response, error)
+
+	// Status reports the browser's state without starting it.
+	Status() BackendStatus
+
+	// IsRunning reports whether the browser is up.
+	IsRunning() bool
+
+	// Shutdown stops the browser. The next Fetch starts it again.
+	Shutdown()
+
+	// SetLogger directs the backend's log output.
+	SetLogger(logger *slog.Logger)
+}
+
+// BackendStatus is what a backend reports about its browser.
+type BackendStatus struct {
+	Running   bool
+	PID       int
+	Version   string
+	Warning   string // Why the browser may not work, if it may not
+	OpenTabs  int
+	Memory    uint64
+	Remote    string // Endpoint of a browser the backend attaches to instead of launching
+	Container string // Container runtime the browser runs under
+	Image     string // Image the browser container runs from
+	Recycles  int    // Times the browser was restarted to bound its memory use
+}
+
+// ErrBrowserUnavailable matches errors from a fetch whose browser could not be
+// started or reached, as opposed to a page that failed to load.
+var ErrBrowserUnavailable = errors.New("browser unavailable")
+
+// unavailableError marks an error as ErrBrowserUnavailable, keeping its message.
+type unavailableError struct {
+	err error
+}
+
+// Error returns the wrapped error's message.
+func (e unavailableError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap lets errors.Is match both ErrBrowserUnavailable and the cause.
+func (e unavailableError) Unwrap() []error {
+	return []error{ErrBrowserUnavailable, e.err}
+}
+
+// defaultBackend is the engine used when ESSENZ_BROWSER is unset.
+const defaultBackend = "chrome"
+
+// backends maps engine names to constructors.
+var backends = map[string]func() Backend{
+	"chrome": func() Backend { return NewManager() },
+}
+
+// BackendName returns the browser engine selected with ESSENZ_BROWSER, or
+// chrome. Unknown names are ignored.
+func BackendName() string {
+	name := os.Getenv("ESSENZ_BROWSER")
+	if ValidateBackend(name) != nil || name == "" {
+		return defaultBackend
+	}
+	return name
+}
+
+// ValidateBackend checks that a browser engine is available.
+func ValidateBackend(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, ok := backends[name]; !ok {
+		return fmt.Errorf("unknown browser %q: available browsers are %s", name, strings.Join(BackendNames(), ", "))
+	}
+	return nil
+}
+
+// BackendNames lists the available browser engines.
+func BackendNames() []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
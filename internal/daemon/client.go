@@ -5,130 +5,311 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net"
-	"os"
-	"path/filepath"
+	"log/slog"
 	"time"
 
+	"github.com/jewell-lgtm/essenz/internal/actions"
+	"github.com/jewell-lgtm/essenz/internal/emulate"
 	"github.com/jewell-lgtm/essenz/internal/pageready"
+	"github.com/jewell-lgtm/essenz/internal/session"
 )
 
 // Client communicates with the Chrome daemon.
 type Client struct {
-	socketPath string
+	endpoint    Endpoint
+	endpointErr error // Why the default endpoint could not be resolved
+	options     FetchOptions
 }
 
 // NewDaemonClient creates a new daemon client.
 func NewDaemonClient() *Client {
-	socketPath := filepath.Join(os.TempDir(), "essenz-daemon.sock")
+	endpoint, err := DefaultEndpoint()
 	return &Client{
-		socketPath: socketPath,
+		endpoint:    endpoint,
+		endpointErr: err,
 	}
 }
 
+// WithEndpoint points the client at a daemon other than the default one.
+func (c *Client) WithEndpoint(endpoint Endpoint) *Client {
+	c.endpoint = endpoint
+	c.endpointErr = nil
+	return c
+}
+
+// WithOptions replaces all fetch options at once.
+func (c *Client) WithOptions(options FetchOptions) *Client {
+	c.options = options
+	return c
+}
+
+// WithScroll makes the daemon scroll the page before extraction.
+func (c *Client) WithScroll(scroll string, maxHeight int) *Client {
+	c.options.Scroll = scroll
+	c.options.ScrollMaxHeight = maxHeight
+	return c
+}
+
+// WithEval makes the daemon run scripts in the page before extraction.
+func (c *Client) WithEval(scripts []string) *Client {
+	c.options.Eval = scripts
+	return c
+}
+
+// WithActions makes the daemon run interaction steps before extraction.
+func (c *Client) WithActions(script *actions.Script) *Client {
+	c.options.Actions = script
+	return c
+}
+
+// WithLoadState makes the daemon restore a saved session before navigation.
+func (c *Client) WithLoadState(state *session.StorageState) *Client {
+	c.options.LoadState = state
+	return c
+}
+
+// WithSaveState makes the daemon return the session's storage state after the fetch.
+func (c *Client) WithSaveState(save bool) *Client {
+	c.options.SaveState = save
+	return c
+}
+
+// WithHeaders makes the daemon send extra headers with every request the page makes.
+func (c *Client) WithHeaders(headers map[string]string) *Client {
+	c.options.Headers = headers
+	return c
+}
+
+// WithAuth makes the daemon answer basic auth challenges from the page's origin.
+func (c *Client) WithAuth(auth *session.BasicAuth) *Client {
+	c.options.Auth = auth
+	return c
+}
+
+// WithUserAgent makes the daemon override the browser's user agent.
+func (c *Client) WithUserAgent(userAgent string) *Client {
+	c.options.UserAgent = userAgent
+	return c
+}
+
+// WithViewport makes the daemon emulate a screen size or device.
+func (c *Client) WithViewport(viewport *emulate.Viewport) *Client {
+	c.options.Viewport = viewport
+	return c
+}
+
+// WithRegion makes the daemon emulate a locale, time zone, and geolocation.
+func (c *Client) WithRegion(region *emulate.Region) *Client {
+	c.options.Region = region
+	return c
+}
+
+// WithAdblock makes the daemon block ad and tracker requests using the bundled
+// list plus the given filter list files.
+func (c *Client) WithAdblock(enabled bool, lists []string) *Client {
+	c.options.Adblock = enabled
+	c.options.AdblockLists = lists
+	return c
+}
+
+// WithConsoleCapture makes the daemon return the page's console messages.
+func (c *Client) WithConsoleCapture(capture bool) *Client {
+	c.options.CaptureConsole = capture
+	return c
+}
+
+// WithHAR makes the daemon return the page load's network activity.
+func (c *Client) WithHAR(record bool) *Client {
+	c.options.RecordHAR = record
+	return c
+}
+
+// WithValidators makes the daemon return the page's ETag and Last-Modified.
+func (c *Client) WithValidators(capture bool) *Client {
+	c.options.CaptureValidators = capture
+	return c
+}
+
+// WithDownloadDir makes the daemon save file downloads into dir.
+func (c *Client) WithDownloadDir(dir string) *Client {
+	c.options.DownloadDir = dir
+	return c
+}
+
+// WithOutput selects what the daemon returns: OutputHTML or OutputText.
+func (c *Client) WithOutput(output string) *Client {
+	c.options.Output = output
+	return c
+}
+
+// WithIncognito makes the daemon run the request in a throwaway browser context.
+func (c *Client) WithIncognito(incognito bool) *Client {
+	c.options.Incognito = incognito
+	return c
+}
+
+// WithProfile makes the daemon run the request in a named, persistent browser profile.
+func (c *Client) WithProfile(name string) *Client {
+	c.options.Profile = name
+	return c
+}
+
+// WithRemoteChrome makes the request fail unless the daemon drives the Chrome
+// at the given DevTools endpoint.
+func (c *Client) WithRemoteChrome(endpoint string) *Client {
+	c.options.RemoteChrome = endpoint
+	return c
+}
+
+// WithQueueTimeout limits how long the request waits for a free fetch slot
+// when the daemon is busy.
+func (c *Client) WithQueueTimeout(timeout time.Duration) *Client {
+	c.options.QueueTimeout = timeout
+	return c
+}
+
 // FetchContent fetches content via the daemon.
-func (c *Client) FetchContent(_ context.Context, url string) (string, error) {
+func (c *Client) FetchContent(ctx context.Context, url string) (string, error) {
+	content, _, err := c.FetchContentWithReport(ctx, url, nil)
+	return content, err
+}
+
+// FetchContentWithReadiness fetches content via the daemon with DOM readiness detection.
+func (c *Client) FetchContentWithReadiness(ctx context.Context, url string, checker *pageready.ReadinessChecker) (string, error) {
+	content, _, err := c.FetchContentWithReport(ctx, url, checker)
+	return content, err
+}
+
+// FetchContentWithReport fetches content via the daemon and returns the readiness
+// detection result. A nil checker uses the daemon's default readiness detection.
+func (c *Client) FetchContentWithReport(ctx context.Context, url string, checker *pageready.ReadinessChecker) (string, *pageready.ReadinessResult, error) {
+	resp, err := c.Fetch(ctx, url, checker)
+	if err != nil {
+		return "", nil, err
+	}
+	return resp.Content, resp.Readiness, nil
+}
+
+// Fetch sends a fetch request to the daemon and returns its full response.
+func (c *Client) Fetch(_ context.Context, url string, checker *pageready.ReadinessChecker) (*Response, error) {
+	if c.endpointErr != nil {
+		return nil, c.endpointErr
+	}
+
 	// Ensure daemon is running
 	if !IsDaemonRunning() {
+		slog.Debug("no daemon running, starting one in this process", "endpoint", c.endpoint.String())
+		// The socket is listening once this returns
 		if err := StartDaemonIfNeeded(); err != nil {
-			return "", fmt.Errorf("failed to start daemon: %w", err)
+			return nil, fmt.Errorf("failed to start daemon: %w", err)
 		}
-		// Give daemon time to start
-		time.Sleep(1 * time.Second)
 	}
 
 	// Connect to daemon
-	conn, err := net.DialTimeout("unix", c.socketPath, 5*time.Second)
+	conn, err := c.endpoint.dial(5 * time.Second)
 	if err != nil {
-		return "", fmt.Errorf("failed to connect to daemon: %w", err)
+		return nil, fmt.Errorf("failed to connect to daemon: %w", err)
 	}
 	defer func() { _ = conn.Close() }()
 
-	// Set connection timeout
-	_ = conn.SetDeadline(time.Now().Add(30 * time.Second))
+	// Set connection timeout, allowing for time spent in the daemon's queue
+	queueTimeout := c.options.QueueTimeout
+	if queueTimeout <= 0 {
+		queueTimeout = getQueueTimeout()
+	}
+	_ = conn.SetDeadline(time.Now().Add(30*time.Second + queueTimeout))
 
 	// Send request
 	encoder := json.NewEncoder(conn)
 	decoder := json.NewDecoder(conn)
 
+	// A checker passed to this call takes precedence over one in the options
+	options := c.options
+	if checker != nil {
+		options.Readiness = checker
+	}
 	req := Request{
-		Action: "fetch",
-		URL:    url,
+		Action:  "fetch",
+		Token:   readToken(c.endpoint.tokenPath()),
+		URL:     url,
+		Options: &options,
 	}
 
 	if err := encoder.Encode(req); err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 
 	// Read response
 	var resp Response
 	if err := decoder.Decode(&resp); err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	if !resp.Success {
-		return "", fmt.Errorf("daemon error: %s", resp.Error)
-	}
-
-	return resp.Content, nil
-}
-
-// FetchContentWithReadiness fetches content via the daemon with DOM readiness detection.
-func (c *Client) FetchContentWithReadiness(ctx context.Context, url string, _ *pageready.ReadinessChecker) (string, error) {
-	// For now, implement this by falling back to regular fetch
-	// TODO: Extend the daemon protocol to support readiness checking
-	content, err := c.FetchContent(ctx, url)
-	if err != nil {
-		return "", err
+		err := fmt.Errorf("daemon error: %s", resp.Error)
+		if resp.Unavailable {
+			return nil, unavailableError{err}
+		}
+		return nil, err
 	}
 
-	// TODO: In future iterations, we'll integrate the readiness checker
-	// into the daemon server for proper DOM event waiting
-
-	return content, nil
+	return &resp, nil
 }
 
 // Ping checks if the daemon is responsive.
 func (c *Client) Ping() error {
-	conn, err := net.DialTimeout("unix", c.socketPath, 2*time.Second)
+	_, err := c.Info()
+	return err
+}
+
+// Info pings the daemon and returns what it reports about itself.
+func (c *Client) Info() (*Info, error) {
+	if c.endpointErr != nil {
+		return nil, c.endpointErr
+	}
+
+	conn, err := c.endpoint.dial(2 * time.Second)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer func() { _ = conn.Close() }()
 
 	encoder := json.NewEncoder(conn)
 	decoder := json.NewDecoder(conn)
 
-	req := Request{Action: "ping"}
+	req := Request{Action: "ping", Token: readToken(c.endpoint.tokenPath())}
 	if err := encoder.Encode(req); err != nil {
-		return err
+		return nil, err
 	}
 
 	var resp Response
 	if err := decoder.Decode(&resp); err != nil {
-		return err
+		return nil, err
 	}
 
 	if !resp.Success {
-		return fmt.Errorf("ping failed: %s", resp.Error)
+		return nil, fmt.Errorf("ping failed: %s", resp.Error)
 	}
 
-	return nil
+	return resp.Info, nil
 }
 
 // Shutdown requests the daemon to shutdown.
 func (c *Client) Shutdown() error {
+	if c.endpointErr != nil {
+		return c.endpointErr
+	}
 	if !IsDaemonRunning() {
 		return nil
 	}
 
-	conn, err := net.DialTimeout("unix", c.socketPath, 2*time.Second)
+	conn, err := c.endpoint.dial(2 * time.Second)
 	if err != nil {
 		return err
 	}
 	defer func() { _ = conn.Close() }()
 
 	encoder := json.NewEncoder(conn)
-	req := Request{Action: "shutdown"}
+	req := Request{Action: "shutdown", Token: readToken(c.endpoint.tokenPath())}
 	return encoder.Encode(req)
 }
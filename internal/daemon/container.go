@@ -0,0 +1,143 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+)
+
+// defaultChromeImage is a headless Chrome build that serves DevTools on port 9222.
+const defaultChromeImage = "chromedp/headless-shell:latest"
+
+// containerDebugPort is the DevTools port Chrome listens on inside the container.
+const containerDebugPort = 9222
+
+// ChromeContainer returns the container runtime (docker or podman) the daemon
+// launches Chrome with, from ESSENZ_CHROME_CONTAINER, and the image to run,
+// from ESSENZ_CHROME_IMAGE. An empty runtime means Chrome runs on the host.
+func ChromeContainer() (runtime, image string) {
+	image = os.Getenv("ESSENZ_CHROME_IMAGE")
+	if image == "" {
+		image = defaultChromeImage
+	}
+	return os.Getenv("ESSENZ_CHROME_CONTAINER"), image
+}
+
+// ValidateContainerRuntime checks that Chrome can be launched with the runtime.
+func ValidateContainerRuntime(runtime string) error {
+	switch runtime {
+	case "", "docker", "podman":
+		return nil
+	}
+	return fmt.Errorf("unsupported container runtime %q: use docker or podman", runtime)
+}
+
+// containerName returns the name of the named daemon's Chrome container.
+func containerName(name string) string {
+	return namedFile("essenz-chrome", name, "")
+}
+
+// startContainer runs Chrome in a container with its DevTools port published
+// on a free loopback port, and connects to it. The caller must hold m.mu.
+func (m *Manager) startContainer() error {
+	if err := ValidateContainerRuntime(m.container); err != nil {
+		return err
+	}
+	if _, err := exec.LookPath(m.container); err != nil {
+		return fmt.Errorf("failed to find %s: %w", m.container, err)
+	}
+
+	// A daemon that crashed may have left its container running
+	name := containerName(m.name)
+	_ = exec.Command(m.container, "rm", "-f", name).Run()
+
+	args := []string{
+		"run", "--detach", "--rm",
+		"--name", name,
+		"--publish", fmt.Sprintf("127.0.0.1::%d", containerDebugPort),
+		// Chrome's renderers need more shared memory than the container default
+		"--shm-size=1g",
+		m.image,
+		// Containers have no user namespaces for Chrome's sandbox
+		"--no-sandbox",
+		"--disable-dev-shm-usage",
+	}
+	m.logger.Debug("starting chrome container", "runtime", m.container, "image", m.image)
+	if output, err := exec.Command(m.container, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to start Chrome container: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	m.containerID = name
+
+	port, err := m.containerPort(name)
+	if err != nil {
+		m.stopContainer()
+		return err
+	}
+	if err := waitForDebugger(port, chromeStartTimeout); err != nil {
+		m.stopContainer()
+		return err
+	}
+
+	m.debugPort = port
+	m.debugAddr = fmt.Sprintf("127.0.0.1:%d", port)
+	m.allocCtx, m.allocCancel = chromedp.NewRemoteAllocator(context.Background(), "ws://"+m.debugAddr)
+
+	testCtx, testCancel := chromedp.NewContext(m.allocCtx)
+	defer testCancel()
+	if err := chromedp.Run(testCtx); err != nil {
+		m.allocCancel()
+		m.allocCancel = nil
+		m.stopContainer()
+		return fmt.Errorf("failed to connect to Chrome container: %w", err)
+	}
+
+	m.chromePID = 0
+	m.isRunning = true
+	return nil
+}
+
+// containerPort returns the host port the container's DevTools port is published on.
+func (m *Manager) containerPort(name string) (int, error) {
+	output, err := exec.Command(m.container, "port", name, fmt.Sprintf("%d/tcp", containerDebugPort)).Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to find the Chrome container's port: %w", err)
+	}
+	// Output is one mapping per line, such as 127.0.0.1:49153
+	line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	_, portStr, err := net.SplitHostPort(strings.TrimSpace(line))
+	if err != nil {
+		return 0, fmt.Errorf("unexpected %s port output %q", m.container, line)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected %s port output %q", m.container, line)
+	}
+	return port, nil
+}
+
+// stopContainer removes the Chrome container the manager started.
+func (m *Manager) stopContainer() {
+	if m.containerID == "" {
+		return
+	}
+	_ = exec.Command(m.container, "rm", "-f", m.containerID).Run()
+	m.containerID = ""
+}
+
+// inContainer reports whether the daemon itself runs inside a container,
+// where Chrome needs the same flags it gets in a Chrome container.
+func inContainer() bool {
+	if _, err := os.Stat("/.dockerenv"); err == nil {
+		return true
+	}
+	if _, err := os.Stat("/run/.containerenv"); err == nil {
+		return true
+	}
+	return false
+}
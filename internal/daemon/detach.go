@@ -0,0 +1,57 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// detachTimeout bounds how long StartDetached waits for the new daemon to answer.
+const detachTimeout = 15 * time.Second
+
+// StartDetached runs "executable daemon start" as a background process in its
+// own session, with its output appended to the daemon log, and returns its
+// PID once it answers. The daemon's settings are passed in the environment.
+func StartDetached(executable string) (int, error) {
+	logPath := LogPath()
+	if logPath == "-" {
+		return 0, fmt.Errorf("a detached daemon has no terminal to log to: use --log-file with a path")
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath), 0o700); err != nil {
+		return 0, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	output, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer func() { _ = output.Close() }()
+
+	cmd := exec.Command(executable, "daemon", "start")
+	cmd.Stdin = nil
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cmd.SysProcAttr = detachedProcAttr()
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start daemon: %w", err)
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	client := NewDaemonClient()
+	deadline := time.After(detachTimeout)
+	for {
+		select {
+		case err := <-exited:
+			return 0, fmt.Errorf("daemon exited during startup (%v); see %s", err, logPath)
+		case <-deadline:
+			return cmd.Process.Pid, fmt.Errorf("daemon did not answer within %v; see %s", detachTimeout, logPath)
+		case <-time.After(100 * time.Millisecond):
+			if info, err := client.Info(); err == nil && info.PID == cmd.Process.Pid {
+				return info.PID, nil
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jewell-lgtm/essenz/internal/chrome"
+	"github.com/jewell-lgtm/essenz/internal/config"
+)
+
+// minChromeMajor is the oldest Chrome release with the new headless mode and
+// the DevTools protocol methods the daemon relies on.
+const minChromeMajor = 112
+
+// browserVersion matches the product version Chrome and Edge report, such as
+// HeadlessChrome/131.0.6778.85 or Edg/120.0.2210.61.
+var browserVersion = regexp.MustCompile(`/(\d+)\.`)
+
+// findChrome locates the browser executable. ESSENZ_CHROME_PATH, a list
+// separated like PATH, is authoritative when set. Otherwise the chrome_paths
+// from config.yaml are tried, then the platform's usual install locations,
+// then the build downloaded by sz chrome install.
+func (m *Manager) findChrome() (string, error) {
+	if env := os.Getenv("ESSENZ_CHROME_PATH"); env != "" {
+		for _, candidate := range filepath.SplitList(env) {
+			if path, ok := executableAt(candidate); ok {
+				return path, nil
+			}
+		}
+		return "", fmt.Errorf("no browser found at ESSENZ_CHROME_PATH=%s", env)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+	candidates := append(cfg.Browser.ChromePaths, chromePaths...)
+	candidates = append(candidates, userChromePaths()...)
+	for _, candidate := range candidates {
+		if path, ok := executableAt(candidate); ok {
+			return path, nil
+		}
+	}
+
+	if path, ok := chrome.Installed(); ok {
+		return path, nil
+	}
+
+	return "", fmt.Errorf("Chrome not found in common locations: install it, set ESSENZ_CHROME_PATH, or run sz chrome install")
+}
+
+// executableAt resolves a candidate browser: a bare name is looked up on
+// PATH, and a leading ~ stands for the home directory.
+func executableAt(candidate string) (string, bool) {
+	candidate = strings.TrimSpace(candidate)
+	if candidate == "" {
+		return "", false
+	}
+	if !strings.ContainsAny(candidate, `/\`) {
+		path, err := exec.LookPath(candidate)
+		return path, err == nil
+	}
+	if rest, ok := strings.CutPrefix(candidate, "~"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		candidate = filepath.Join(home, rest)
+	}
+	info, err := os.Stat(candidate)
+	if err != nil || info.IsDir() {
+		return "", false
+	}
+	return candidate, true
+}
+
+// userChromePaths lists per-user install locations, such as Flatpak apps
+// installed with --user.
+func userChromePaths() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	exports := filepath.Join(home, ".local", "share", "flatpak", "exports", "bin")
+	return []string{
+		filepath.Join(exports, "com.google.Chrome"),
+		filepath.Join(exports, "org.chromium.Chromium"),
+		filepath.Join(exports, "com.microsoft.Edge"),
+	}
+}
+
+// chromeMajor returns the major version in a browser product string, or 0.
+func chromeMajor(version string) int {
+	match := browserVersion.FindStringSubmatch(version)
+	if match == nil {
+		return 0
+	}
+	major, _ := strconv.Atoi(match[1])
+	return major
+}
+
+// versionWarning explains why a browser version may not work, or returns
+// the empty string when it is new enough or unknown.
+func versionWarning(version string) string {
+	major := chromeMajor(version)
+	if major == 0 || major >= minChromeMajor {
+		return ""
+	}
+	return fmt.Sprintf("%s is older than Chrome %d; page loading and browser contexts may fail, so update it or run sz chrome install", version, minChromeMajor)
+}
@@ -0,0 +1,120 @@
+package daemon
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Endpoint is where the daemon listens: a Unix socket or a TCP address on the
+// loopback interface. Windows has no Unix sockets in every release, so it
+// defaults to TCP.
+type Endpoint struct {
+	Network string // "unix" or "tcp"
+	Address string // Socket path or host:port
+}
+
+// DefaultEndpoint returns the endpoint from ESSENZ_SOCKET (a socket path),
+// ESSENZ_DAEMON_ADDR, or the platform default, in that order. An invalid
+// ESSENZ_DAEMON_ADDR is an error rather than a reason to fall back, which
+// would talk to a different daemon than the one asked for.
+func DefaultEndpoint() (Endpoint, error) {
+	if socket := os.Getenv("ESSENZ_SOCKET"); socket != "" {
+		return Endpoint{Network: "unix", Address: socket}, nil
+	}
+	if addr := os.Getenv("ESSENZ_DAEMON_ADDR"); addr != "" {
+		endpoint, err := ParseEndpoint(addr)
+		if err != nil {
+			return Endpoint{}, fmt.Errorf("ESSENZ_DAEMON_ADDR: %w", err)
+		}
+		return endpoint, nil
+	}
+	return defaultEndpoint(), nil
+}
+
+// ParseEndpoint parses "unix:PATH" or "tcp:HOST:PORT". A value without a
+// scheme is treated as a socket path. TCP endpoints must be on the loopback
+// interface so the daemon is never reachable from the network.
+func ParseEndpoint(value string) (Endpoint, error) {
+	scheme, rest, found := strings.Cut(value, ":")
+	if !found || (scheme != "unix" && scheme != "tcp") {
+		return Endpoint{Network: "unix", Address: value}, nil
+	}
+	rest = strings.TrimPrefix(rest, "//")
+
+	if scheme == "unix" {
+		if rest == "" {
+			return Endpoint{}, fmt.Errorf("invalid daemon address %q: missing socket path", value)
+		}
+		return Endpoint{Network: "unix", Address: rest}, nil
+	}
+
+	host, port, err := net.SplitHostPort(rest)
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("invalid daemon address %q: expected tcp:HOST:PORT", value)
+	}
+	if !isLoopback(host) {
+		return Endpoint{}, fmt.Errorf("invalid daemon address %q: host must be localhost or a loopback address", value)
+	}
+	return Endpoint{Network: "tcp", Address: net.JoinHostPort(host, port)}, nil
+}
+
+// String formats the endpoint the way ParseEndpoint reads it.
+func (e Endpoint) String() string {
+	return e.Network + ":" + e.Address
+}
+
+// listen opens the endpoint for the server. Socket files are restricted to
+// the current user.
+func (e Endpoint) listen() (net.Listener, error) {
+	if e.Network != "unix" {
+		return net.Listen(e.Network, e.Address)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(e.Address), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	listener, err := net.Listen(e.Network, e.Address)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(e.Address, 0o600); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+	return listener, nil
+}
+
+// dial connects to the daemon at the endpoint.
+func (e Endpoint) dial(timeout time.Duration) (net.Conn, error) {
+	return net.DialTimeout(e.Network, e.Address, timeout)
+}
+
+// cleanup removes what the listener left on disk.
+func (e Endpoint) cleanup() {
+	if e.Network == "unix" {
+		_ = os.Remove(e.Address)
+	}
+}
+
+// sidecarPath returns a file that belongs to the daemon at the endpoint:
+// next to the socket, or in the temp directory for TCP endpoints.
+func (e Endpoint) sidecarPath(suffix string) string {
+	if e.Network == "unix" {
+		return e.Address + suffix
+	}
+	name := "essenz-daemon-" + strings.NewReplacer(":", "-", "[", "", "]", "").Replace(e.Address) + suffix
+	return filepath.Join(os.TempDir(), name)
+}
+
+// isLoopback reports whether host names the local machine.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
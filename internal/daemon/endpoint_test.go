@@ -0,0 +1,102 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEndpoint(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  Endpoint
+	}{
+		{"unix scheme", "unix:/run/essenz/daemon.sock", Endpoint{Network: "unix", Address: "/run/essenz/daemon.sock"}},
+		{"unix scheme with slashes", "unix:///run/essenz/daemon.sock", Endpoint{Network: "unix", Address: "/run/essenz/daemon.sock"}},
+		{"bare path", "/tmp/essenz.sock", Endpoint{Network: "unix", Address: "/tmp/essenz.sock"}},
+		{"relative path", "essenz.sock", Endpoint{Network: "unix", Address: "essenz.sock"}},
+		{"localhost", "tcp:localhost:7400", Endpoint{Network: "tcp", Address: "localhost:7400"}},
+		{"ipv4 loopback", "tcp:127.0.0.1:7400", Endpoint{Network: "tcp", Address: "127.0.0.1:7400"}},
+		{"ipv6 loopback", "tcp:[::1]:7400", Endpoint{Network: "tcp", Address: "[::1]:7400"}},
+		{"tcp scheme with slashes", "tcp://127.0.0.1:7400", Endpoint{Network: "tcp", Address: "127.0.0.1:7400"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEndpoint(tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want.String(), got.String())
+		})
+	}
+}
+
+func TestParseEndpointRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"all interfaces", "tcp:0.0.0.0:7400", "loopback"},
+		{"all ipv6 interfaces", "tcp:[::]:7400", "loopback"},
+		{"remote host", "tcp:example.com:7400", "loopback"},
+		{"lan address", "tcp:192.168.1.10:7400", "loopback"},
+		{"missing port", "tcp:127.0.0.1", "tcp:HOST:PORT"},
+		{"missing socket path", "unix:", "missing socket path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseEndpoint(tt.value)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestDefaultEndpoint(t *testing.T) {
+	t.Run("socket wins", func(t *testing.T) {
+		t.Setenv("ESSENZ_SOCKET", "/tmp/essenz-test.sock")
+		t.Setenv("ESSENZ_DAEMON_ADDR", "tcp:127.0.0.1:7400")
+
+		endpoint, err := DefaultEndpoint()
+		require.NoError(t, err)
+		assert.Equal(t, Endpoint{Network: "unix", Address: "/tmp/essenz-test.sock"}, endpoint)
+	})
+
+	t.Run("daemon address", func(t *testing.T) {
+		t.Setenv("ESSENZ_SOCKET", "")
+		t.Setenv("ESSENZ_DAEMON_ADDR", "tcp:127.0.0.1:7400")
+
+		endpoint, err := DefaultEndpoint()
+		require.NoError(t, err)
+		assert.Equal(t, Endpoint{Network: "tcp", Address: "127.0.0.1:7400"}, endpoint)
+	})
+
+	t.Run("invalid daemon address", func(t *testing.T) {
+		t.Setenv("ESSENZ_SOCKET", "")
+		t.Setenv("ESSENZ_DAEMON_ADDR", "tcp:0.0.0.0:7400")
+
+		_, err := DefaultEndpoint()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ESSENZ_DAEMON_ADDR")
+	})
+
+	t.Run("platform default", func(t *testing.T) {
+		t.Setenv("ESSENZ_SOCKET", "")
+		t.Setenv("ESSENZ_DAEMON_ADDR", "")
+
+		endpoint, err := DefaultEndpoint()
+		require.NoError(t, err)
+		assert.Equal(t, defaultEndpoint(), endpoint)
+	})
+
+	t.Run("invalid address is not used by the client", func(t *testing.T) {
+		t.Setenv("ESSENZ_SOCKET", "")
+		t.Setenv("ESSENZ_DAEMON_ADDR", "tcp:example.com:7400")
+
+		_, err := NewDaemonClient().Info()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ESSENZ_DAEMON_ADDR")
+	})
+}
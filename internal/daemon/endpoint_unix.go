@@ -0,0 +1,19 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// defaultEndpoint is a Unix socket in XDG_RUNTIME_DIR, which is private to
+// the user, or in the temp directory when that is unset. Named daemons get
+// their own socket.
+func defaultEndpoint() Endpoint {
+	name := DaemonName()
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		return Endpoint{Network: "unix", Address: filepath.Join(runtimeDir, "essenz", namedFile("daemon", name, ".sock"))}
+	}
+	return Endpoint{Network: "unix", Address: filepath.Join(os.TempDir(), namedFile("essenz-daemon", name, ".sock"))}
+}
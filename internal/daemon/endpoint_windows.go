@@ -0,0 +1,15 @@
+//go:build windows
+
+package daemon
+
+import "strconv"
+
+// defaultDaemonPort is the loopback port the daemon listens on when Unix sockets are unavailable.
+const defaultDaemonPort = 47321
+
+// defaultEndpoint is a TCP port on the loopback interface. Named daemons get
+// their own port.
+func defaultEndpoint() Endpoint {
+	port := namedPort(defaultDaemonPort, DaemonName())
+	return Endpoint{Network: "tcp", Address: "127.0.0.1:" + strconv.Itoa(port)}
+}
@@ -0,0 +1,131 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// takeoverEnv tells a starting daemon to take its endpoint over from the
+// daemon already serving it rather than refusing to start.
+const takeoverEnv = "ESSENZ_TAKEOVER"
+
+// takeoverListenTimeout bounds how long a daemon taking over a TCP endpoint
+// waits for the old daemon to release the port.
+const takeoverListenTimeout = 5 * time.Second
+
+// RestartDetached starts a new daemon in the background that takes over from
+// the running one without dropping requests: the new daemon answers new
+// connections at once, while the old one finishes the requests it has
+// accepted or queued and then exits. It returns the new daemon's PID.
+func RestartDetached(executable string) (int, error) {
+	if err := os.Setenv(takeoverEnv, "1"); err != nil {
+		return 0, err
+	}
+	defer func() { _ = os.Unsetenv(takeoverEnv) }()
+	return StartDetached(executable)
+}
+
+// takeOver starts listening at an endpoint another daemon is serving. On a
+// Unix socket the new socket is renamed over the old one, so every connection
+// reaches one daemon or the other; on TCP the port is free once the old daemon
+// acknowledges the handover. The old daemon keeps conn open until it has
+// drained and stopped its browser, which waitForHandover watches for. The
+// caller must hold s.mu.
+func (s *Server) takeOver(conn net.Conn) (net.Listener, error) {
+	encoder := json.NewEncoder(conn)
+	decoder := json.NewDecoder(conn)
+	handover := Request{Action: "handover", Token: readToken(s.endpoint.tokenPath())}
+
+	if s.endpoint.Network != "unix" {
+		if err := s.requestHandover(encoder, decoder, handover); err != nil {
+			return nil, err
+		}
+		deadline := time.Now().Add(takeoverListenTimeout)
+		for {
+			listener, err := s.endpoint.listen()
+			if err == nil || time.Now().After(deadline) {
+				return listener, err
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+
+	next := Endpoint{Network: "unix", Address: s.endpoint.Address + ".next"}
+	_ = os.Remove(next.Address)
+	listener, err := next.listen()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Rename(next.Address, s.endpoint.Address); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("failed to take over socket: %w", err)
+	}
+	if err := s.requestHandover(encoder, decoder, handover); err != nil {
+		// Clients already reach this daemon, so carry on; the old one no
+		// longer receives requests
+		s.logger.Warn("previous daemon did not acknowledge handover", "error", err)
+	}
+	return listener, nil
+}
+
+// requestHandover asks the old daemon to stop accepting connections.
+func (s *Server) requestHandover(encoder *json.Encoder, decoder *json.Decoder, req Request) error {
+	if err := encoder.Encode(req); err != nil {
+		return fmt.Errorf("failed to request handover: %w", err)
+	}
+	var resp Response
+	if err := decoder.Decode(&resp); err != nil {
+		return fmt.Errorf("failed to request handover: %w", err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("previous daemon refused handover: %s", resp.Error)
+	}
+	return nil
+}
+
+// waitForHandover lets fetches through once the old daemon has closed its
+// browser, so the two never share a Chrome profile or container.
+func (s *Server) waitForHandover(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	defer close(s.ready)
+
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		s.logger.Warn("lost contact with previous daemon", "error", err)
+		return
+	}
+	s.logger.Info("previous daemon drained")
+}
+
+// handOver stops accepting connections, leaving the endpoint to the daemon
+// taking over, then finishes every accepted request, stops the browser and
+// exits. It acknowledges the handover on conn at once, and again once the
+// browser is closed; conn is closed after that.
+func (s *Server) handOver(conn net.Conn, encoder *json.Encoder) {
+	s.mu.Lock()
+	s.handedOver = true
+	if listener, ok := s.listener.(*net.UnixListener); ok {
+		// The path now belongs to the new daemon's socket
+		listener.SetUnlinkOnClose(false)
+	}
+	_ = s.listener.Close()
+	s.mu.Unlock()
+
+	s.logger.Info("handing over to new daemon, draining requests")
+	if err := encoder.Encode(Response{Success: true}); err != nil {
+		s.logger.Warn("failed to acknowledge handover", "error", err)
+	}
+
+	go func() {
+		defer func() { _ = conn.Close() }()
+		s.conns.Wait()
+		s.backend.Shutdown()
+		if err := encoder.Encode(Response{Success: true}); err != nil {
+			s.logger.Warn("failed to report drained", "error", err)
+		}
+		_ = s.Stop()
+	}()
+}
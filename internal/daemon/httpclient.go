@@ -0,0 +1,54 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/jewell-lgtm/essenz/internal/emulate"
+	"github.com/jewell-lgtm/essenz/internal/transport"
+)
+
+// httpClient makes the daemon's own HTTP requests, those schedules make to
+// revalidate a page or to fetch it when no browser can be started. It talks
+// HTTP as the CLI's fallback does, with the TLS settings and languages of
+// ESSENZ_INSECURE, ESSENZ_CA_CERT, ESSENZ_CLIENT_CERT, ESSENZ_CLIENT_KEY and
+// ESSENZ_LANG, which sz daemon start sets from its flags.
+type httpClient struct {
+	client         *http.Client
+	acceptLanguage string
+}
+
+// newHTTPClient builds the daemon's HTTP client from the environment.
+func newHTTPClient() (*httpClient, error) {
+	config, err := transport.TLSOptions{
+		Insecure:   os.Getenv("ESSENZ_INSECURE") != "",
+		CACert:     os.Getenv("ESSENZ_CA_CERT"),
+		ClientCert: os.Getenv("ESSENZ_CLIENT_CERT"),
+		ClientKey:  os.Getenv("ESSENZ_CLIENT_KEY"),
+	}.Config()
+	if err != nil {
+		return nil, err
+	}
+	languages, err := emulate.ParseLanguages(os.Getenv("ESSENZ_LANG"))
+	if err != nil {
+		return nil, fmt.Errorf("ESSENZ_LANG: %w", err)
+	}
+	return &httpClient{
+		client:         &http.Client{Transport: transport.New(config)},
+		acceptLanguage: emulate.AcceptLanguage(languages),
+	}, nil
+}
+
+// newRequest returns a request for url carrying the configured languages.
+func (c *httpClient) newRequest(ctx context.Context, method, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.acceptLanguage != "" {
+		req.Header.Set("Accept-Language", c.acceptLanguage)
+	}
+	return req, nil
+}
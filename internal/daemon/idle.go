@@ -0,0 +1,72 @@
+package daemon
+
+import (
+	"os"
+	"time"
+)
+
+// defaultIdleTimeout is how long Chrome stays up without requests when ESSENZ_DAEMON_TIMEOUT is unset.
+const defaultIdleTimeout = 300 * time.Second
+
+// beginRequest marks a request as in flight so Chrome is not stopped under it.
+func (s *Server) beginRequest() {
+	s.idleMu.Lock()
+	defer s.idleMu.Unlock()
+
+	s.inFlight++
+	if s.idleTimer != nil {
+		s.idleTimer.Stop()
+		s.idleTimer = nil
+	}
+}
+
+// endRequest marks a request as finished and starts the idle countdown once
+// nothing else is running.
+func (s *Server) endRequest() {
+	s.idleMu.Lock()
+	defer s.idleMu.Unlock()
+
+	s.inFlight--
+	if s.inFlight == 0 {
+		s.idleTimer = time.AfterFunc(s.idleTimeout, s.stopIdleChrome)
+	}
+}
+
+// stopIdleChrome shuts Chrome down after the idle timeout. The daemon keeps
+// listening and starts Chrome again for the next request.
+func (s *Server) stopIdleChrome() {
+	s.idleMu.Lock()
+	defer s.idleMu.Unlock()
+
+	// A request may have arrived while the timer fired
+	if s.inFlight > 0 || s.idleTimer == nil {
+		return
+	}
+	s.idleTimer = nil
+
+	if s.backend.IsRunning() {
+		s.logger.Info("no requests, stopping browser", "idle", s.idleTimeout)
+		s.backend.Shutdown()
+	}
+}
+
+// stopIdleTimer cancels a pending idle shutdown.
+func (s *Server) stopIdleTimer() {
+	s.idleMu.Lock()
+	defer s.idleMu.Unlock()
+
+	if s.idleTimer != nil {
+		s.idleTimer.Stop()
+		s.idleTimer = nil
+	}
+}
+
+// getIdleTimeout returns the idle timeout from environment or default.
+func getIdleTimeout() time.Duration {
+	if timeoutStr := os.Getenv("ESSENZ_DAEMON_TIMEOUT"); timeoutStr != "" {
+		if timeout, err := time.ParseDuration(timeoutStr); err == nil && timeout > 0 {
+			return timeout
+		}
+	}
+	return defaultIdleTimeout
+}
@@ -0,0 +1,146 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"time"
+)
+
+// Info describes a running daemon. The daemon returns it in reply to a ping.
+type Info struct {
+	Name          string    `json:"name,omitempty"`
+	PID           int       `json:"pid"`
+	Endpoint      string    `json:"endpoint"`
+	Started       time.Time `json:"started"`
+	Uptime        string    `json:"uptime"`
+	Browser       string    `json:"browser"` // Engine the daemon renders pages with
+	ChromeRunning bool      `json:"chrome_running"`
+	ChromePID     int       `json:"chrome_pid,omitempty"`
+	ChromeVersion string    `json:"chrome_version,omitempty"`
+	ChromeWarning string    `json:"chrome_warning,omitempty"`   // Set when the browser is older than the daemon supports
+	RemoteChrome  string    `json:"remote_chrome,omitempty"`    // DevTools endpoint of a Chrome the daemon attaches to instead of launching
+	ChromeImage   string    `json:"chrome_image,omitempty"`     // Image Chrome runs from when the daemon launches it in a container
+	Container     string    `json:"chrome_container,omitempty"` // Container runtime Chrome runs under
+	PagesServed   int64     `json:"pages_served"`
+	OpenTabs      int       `json:"open_tabs"`
+	Recycles      int       `json:"chrome_recycles"`               // Times Chrome was restarted after too many requests or too much memory
+	MemoryBytes   uint64    `json:"memory_bytes"`                  // Memory the daemon process got from the OS
+	ChromeMemory  uint64    `json:"chrome_memory_bytes,omitempty"` // Resident memory of Chrome's browser process, where the OS reports it
+	Schedules     int       `json:"schedules,omitempty"`           // Pages the daemon distills on a timer
+	LastError     string    `json:"last_error,omitempty"`
+	LastErrorAt   time.Time `json:"last_error_at,omitzero"`
+	LogFile       string    `json:"log_file"`
+}
+
+// Status reports the Chrome process's state without starting it.
+func (m *Manager) Status() BackendStatus {
+	m.mu.RLock()
+	status := BackendStatus{
+		Running:   m.isRunning,
+		PID:       m.chromePID,
+		Version:   m.chromeVersion,
+		Warning:   m.chromeWarning,
+		Remote:    m.remoteURL,
+		Container: m.container,
+		Recycles:  m.recycles,
+	}
+	if m.container != "" {
+		status.Image = m.image
+	}
+	addr := m.debugAddr
+	m.mu.RUnlock()
+
+	if !status.Running {
+		return status
+	}
+	if tabs, err := countTabs(addr); err == nil {
+		status.OpenTabs = tabs
+	}
+	if status.PID != 0 {
+		status.Memory = processMemory(status.PID)
+	}
+	return status
+}
+
+// info gathers the daemon's current status.
+func (s *Server) info() *Info {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	chrome := s.backend.Status()
+	info := &Info{
+		Name:          DaemonName(),
+		PID:           os.Getpid(),
+		Endpoint:      s.endpoint.String(),
+		Started:       s.started,
+		Uptime:        time.Since(s.started).Round(time.Second).String(),
+		Browser:       s.backendName,
+		ChromeRunning: chrome.Running,
+		ChromePID:     chrome.PID,
+		ChromeVersion: chrome.Version,
+		ChromeWarning: chrome.Warning,
+		RemoteChrome:  chrome.Remote,
+		ChromeImage:   chrome.Image,
+		Container:     chrome.Container,
+		PagesServed:   s.pagesServed.Load(),
+		OpenTabs:      chrome.OpenTabs,
+		Recycles:      chrome.Recycles,
+		MemoryBytes:   mem.Sys,
+		ChromeMemory:  chrome.Memory,
+		Schedules:     len(s.schedules),
+		LogFile:       LogPath(),
+	}
+
+	s.errMu.Lock()
+	info.LastError = s.lastError
+	info.LastErrorAt = s.lastErrorAt
+	s.errMu.Unlock()
+	return info
+}
+
+// fetchChromeVersion asks Chrome's debugging endpoint for its product version.
+func fetchChromeVersion(addr string) (string, error) {
+	var version struct {
+		Browser string `json:"Browser"`
+	}
+	if err := getDebuggerJSON(addr, "/json/version", &version); err != nil {
+		return "", err
+	}
+	return version.Browser, nil
+}
+
+// countTabs returns the number of open pages in Chrome.
+func countTabs(addr string) (int, error) {
+	var targets []struct {
+		Type string `json:"type"`
+	}
+	if err := getDebuggerJSON(addr, "/json/list", &targets); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, target := range targets {
+		if target.Type == "page" {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// getDebuggerJSON decodes a JSON document from Chrome's debugging HTTP endpoint.
+func getDebuggerJSON(addr, path string, target any) error {
+	client := &http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Get("http://" + addr + path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("chrome debugger returned %s for %s", resp.Status, path)
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
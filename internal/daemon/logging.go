@@ -0,0 +1,131 @@
+package daemon
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jewell-lgtm/essenz/internal/logging"
+)
+
+// Log rotation defaults used when ESSENZ_LOG_MAX_SIZE is unset.
+const (
+	defaultLogMaxSize = 10 << 20 // Bytes a log file may grow to before it is rotated
+	logBackups        = 3        // Rotated files kept next to the current one
+)
+
+// LogPath returns the file the daemon writes its log to: ESSENZ_LOG_FILE, or
+// daemon.log (daemon-NAME.log for a named daemon) under the user cache
+// directory. "-" means standard error.
+func LogPath() string {
+	if path := os.Getenv("ESSENZ_LOG_FILE"); path != "" {
+		return path
+	}
+	return filepath.Join(dataDir(), "logs", namedFile("daemon", DaemonName(), ".log"))
+}
+
+// openLog creates the daemon's logger from ESSENZ_LOG_FILE, ESSENZ_LOG_LEVEL,
+// ESSENZ_LOG_FORMAT, and ESSENZ_LOG_MAX_SIZE. It logs at info unless told
+// otherwise. The returned closer releases the log file.
+func openLog() (*slog.Logger, io.Closer, error) {
+	path := LogPath()
+	if path == "-" {
+		return logging.New(os.Stderr, slog.LevelInfo), io.NopCloser(nil), nil
+	}
+
+	file, err := openRotatingFile(path, getLogMaxSize(), logBackups)
+	if err != nil {
+		return nil, nil, err
+	}
+	return logging.New(file, slog.LevelInfo), file, nil
+}
+
+// getLogMaxSize returns the rotation size in bytes from ESSENZ_LOG_MAX_SIZE,
+// given in megabytes, or the default.
+func getLogMaxSize() int64 {
+	if sizeStr := os.Getenv("ESSENZ_LOG_MAX_SIZE"); sizeStr != "" {
+		if size, err := strconv.Atoi(strings.TrimSpace(sizeStr)); err == nil && size > 0 {
+			return int64(size) << 20
+		}
+	}
+	return defaultLogMaxSize
+}
+
+// rotatingFile is a log file that is renamed to path.1 once it reaches
+// maxSize, shifting older files up and dropping the oldest.
+type rotatingFile struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	backups int
+	file    *os.File
+	size    int64
+}
+
+// openRotatingFile opens path for appending, creating its directory.
+func openRotatingFile(path string, maxSize int64, backups int) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	r := &rotatingFile{path: path, maxSize: maxSize, backups: backups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Write appends to the log, rotating first if p would take it past maxSize.
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close closes the current log file.
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// open opens the log file and records its current size.
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	r.file, r.size = file, info.Size()
+	return nil
+}
+
+// rotate shifts path.N-1 to path.N down to path to path.1 and starts a new file.
+func (r *rotatingFile) rotate() error {
+	_ = r.file.Close()
+	for i := r.backups - 1; i > 0; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	if r.backups > 0 {
+		_ = os.Rename(r.path, r.path+".1")
+	} else {
+		_ = os.Remove(r.path)
+	}
+	return r.open()
+}
@@ -4,35 +4,74 @@ package daemon
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
-	"syscall"
 	"time"
 
 	"github.com/chromedp/chromedp"
 )
 
+// chromeStartTimeout bounds how long a freshly launched Chrome may take to accept connections.
+const chromeStartTimeout = 10 * time.Second
+
 // Manager handles Chrome daemon lifecycle and connection management.
 type Manager struct {
-	mu          sync.RWMutex
-	chromeCmd   *exec.Cmd
-	allocCtx    context.Context
-	allocCancel context.CancelFunc
-	idleTimer   *time.Timer
-	idleTimeout time.Duration
-	isRunning   bool
-	debugPort   int
-	chromePID   int
+	mu            sync.RWMutex
+	chromeCmd     *exec.Cmd
+	allocCtx      context.Context
+	allocCancel   context.CancelFunc
+	isRunning     bool
+	name          string // Daemon instance name; empty for the default daemon
+	requestedPort int    // Debugging port to launch Chrome with; 0 lets Chrome pick
+	debugPort     int    // Debugging port of the running Chrome
+	debugAddr     string // Host and port of the running Chrome's debugging endpoint
+	remoteURL     string // DevTools endpoint of a Chrome the manager attaches to instead of launching
+	container     string // Container runtime Chrome is launched with; empty to run it on the host
+	image         string // Chrome image for the container runtime
+	containerID   string // Name of the running Chrome container
+	chromePID     int
+	chromeVersion string
+	chromeWarning string // Why the running browser may be too old, if it is
+	pool          *tabPool
+	contexts      *browserContexts
+	logger        *slog.Logger
+	inUse         int        // Tabs handed out by AcquireTab and not yet released
+	drained       *sync.Cond // Signalled on m.mu whenever a tab is released
+	served        int        // Requests the current Chrome has finished
+	recycle       recycleLimits
+	recycleReason string // Why Chrome is restarted once inUse drops to zero
+	recycles      int    // Times Chrome has been restarted by recycling
 }
 
 // NewManager creates a new Chrome daemon manager.
 func NewManager() *Manager {
-	timeout := getIdleTimeout()
-	return &Manager{
-		idleTimeout: timeout,
-		debugPort:   9222, // Default Chrome remote debugging port
+	name := DaemonName()
+	port := 9222 // Default Chrome remote debugging port
+	if name != "" {
+		// Named daemons run side by side, so each Chrome picks a free port
+		port = 0
+	}
+	container, image := ChromeContainer()
+	m := &Manager{
+		name:          name,
+		container:     container,
+		image:         image,
+		requestedPort: port,
+		debugPort:     port,
+		remoteURL:     RemoteChrome(),
+		pool:          newTabPool(getPoolSize()),
+		contexts:      newBrowserContexts(),
+		logger:        slog.New(slog.DiscardHandler),
+		recycle:       getRecycleLimits(),
 	}
+	m.drained = sync.NewCond(&m.mu)
+	return m
 }
 
 // GetContext returns a browser context, starting the daemon if needed.
@@ -40,30 +79,160 @@ func (m *Manager) GetContext(_ context.Context) (context.Context, context.Cancel
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if err := m.ensureRunning(); err != nil {
+		return nil, nil, err
+	}
+
+	// Create new browser context for this operation
+	browserCtx, cancel := chromedp.NewContext(m.allocCtx)
+	return browserCtx, cancel, nil
+}
+
+// AcquireTab returns a tab for a request, starting the daemon if needed.
+// Incognito and profile requests, and those that load or save a session, get
+// a tab in their own browser context; everything else takes a warm tab from
+// the pool.
+// The tab must be handed back with ReleaseTab.
+func (m *Manager) AcquireTab(_ context.Context, opts *FetchOptions) (*Tab, error) {
+	m.mu.Lock()
+	// A Chrome due for recycling takes no new requests; wait for the ones
+	// using it to finish, then restart it
+	for m.recycleReason != "" && m.inUse > 0 {
+		m.drained.Wait()
+	}
+	if m.recycleReason != "" {
+		m.recycleChrome()
+	}
+	err := m.ensureRunning()
+	alloc := m.allocCtx
+	if err == nil {
+		m.inUse++
+	}
+	m.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var tab *Tab
+	switch {
+	case opts != nil && opts.Incognito:
+		tab, err = m.contexts.incognito(alloc)
+	case opts != nil && opts.Profile != "":
+		tab, err = m.contexts.profileTab(alloc, opts.Profile)
+	case opts.carriesSession():
+		tab, err = m.contexts.incognito(alloc)
+	default:
+		tab, err = m.pool.get()
+	}
+	if err != nil {
+		m.mu.Lock()
+		m.inUse--
+		m.drained.Broadcast()
+		m.mu.Unlock()
+		return nil, err
+	}
+	return tab, nil
+}
+
+// ReleaseTab hands a tab back after a request. Reusable tabs return to the
+// pool; others are closed and replaced. Incognito and profile tabs are
+// always closed.
+func (m *Manager) ReleaseTab(tab *Tab, reusable bool) {
+	if tab.isolated {
+		tab.cancel()
+	} else {
+		m.pool.put(tab, reusable)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.finishRequest()
+}
+
+// Fetch renders the request's page in a tab, reusing a warm tab from the
+// pool unless the request needs its own browser context.
+// The tab is closed when ctx ends or the fetch panics, so an abandoned
+// request never leaves a tab behind.
+func (m *Manager) Fetch(ctx context.Context, logger *slog.Logger, req Request) (resp Response, err error) {
+	if err := m.checkRemoteChrome(req.Options); err != nil {
+		return Response{}, err
+	}
+
+	tab, err := m.AcquireTab(ctx, req.Options)
+	if err != nil {
+		return Response{}, unavailableError{fmt.Errorf("failed to get browser context: %w", err)}
+	}
+
+	stop := context.AfterFunc(ctx, tab.cancel)
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("fetch panicked", "panic", r)
+			stop()
+			m.ReleaseTab(tab, false)
+			resp, err = Response{}, fmt.Errorf("fetch panicked: %v", r)
+		}
+	}()
+
+	resp, err = fetchContentWithContext(tab.Context, logger, req)
+	if err == nil && req.Options != nil && req.Options.Profile != "" {
+		if err := m.SaveProfile(req.Options.Profile, tab); err != nil {
+			logger.Warn("failed to save profile", "profile", req.Options.Profile, "error", err)
+		}
+	}
+	// A tab closed because the request timed out cannot go back to the pool
+	timedOut := !stop()
+	m.ReleaseTab(tab, err == nil && !timedOut && req.Options.leavesTabClean(req.URL))
+	return resp, err
+}
+
+// SetLogger directs the manager's log output, which is discarded by default.
+func (m *Manager) SetLogger(logger *slog.Logger) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logger = logger
+}
+
+// SaveProfile stores the session a request left in a profile tab.
+func (m *Manager) SaveProfile(name string, tab *Tab) error {
+	return m.contexts.saveProfile(name, tab)
+}
+
+// ensureRunning starts or reconnects to Chrome if needed. The caller must
+// hold m.mu.
+func (m *Manager) ensureRunning() error {
 	// Check if we need to start or reconnect
 	if !m.isRunning {
-		// Try to reconnect to existing Chrome process first
-		if m.chromePID != 0 && m.processExists(m.chromePID) {
+		if m.remoteURL != "" {
+			if err := m.connectRemote(); err != nil {
+				return err
+			}
+		} else if m.container != "" {
+			if err := m.startContainer(); err != nil {
+				return err
+			}
+		} else if m.chromePID != 0 && m.processExists(m.chromePID) {
+			// Try to reconnect to existing Chrome process first
 			if err := m.reconnect(); err != nil {
 				// Reconnection failed, start new Chrome
 				if err := m.start(); err != nil {
-					return nil, nil, err
+					return err
 				}
 			}
 		} else {
 			// Start new Chrome process
 			if err := m.start(); err != nil {
-				return nil, nil, err
+				return err
 			}
 		}
+		m.pool.reset(m.allocCtx)
+		m.chromeVersion, _ = fetchChromeVersion(m.debugAddr)
+		m.chromeWarning = versionWarning(m.chromeVersion)
+		if m.chromeWarning != "" {
+			m.logger.Warn("browser may be too old", "version", m.chromeVersion, "minimum", minChromeMajor)
+		}
+		m.logger.Info("chrome running", "pid", m.chromePID, "address", m.debugAddr, "remote", m.remoteURL != "", "version", m.chromeVersion)
 	}
-
-	// Reset idle timer
-	m.resetIdleTimer()
-
-	// Create new browser context for this operation
-	browserCtx, cancel := chromedp.NewContext(m.allocCtx)
-	return browserCtx, cancel, nil
+	return nil
 }
 
 // reconnect attempts to reconnect to an existing Chrome process.
@@ -84,6 +253,7 @@ func (m *Manager) reconnect() error {
 		return fmt.Errorf("failed to reconnect to Chrome: %w", err)
 	}
 
+	m.debugAddr = fmt.Sprintf("localhost:%d", m.debugPort)
 	m.isRunning = true
 	return nil
 }
@@ -94,10 +264,7 @@ func (m *Manager) processExists(pid int) bool {
 	if err != nil {
 		return false
 	}
-
-	// Try to send signal 0 to check if process exists
-	err = process.Signal(syscall.Signal(0))
-	return err == nil
+	return processAlive(process)
 }
 
 // start initializes the Chrome daemon process.
@@ -107,6 +274,14 @@ func (m *Manager) start() error {
 	if err != nil {
 		return fmt.Errorf("failed to find Chrome: %w", err)
 	}
+	m.logger.Debug("starting chrome", "path", chromePath, "port", m.requestedPort)
+
+	profileDir := chromeProfileDir(m.name)
+	activePortFile := filepath.Join(profileDir, "DevToolsActivePort")
+	if m.requestedPort == 0 {
+		// Chrome writes the port it picked here; drop the one from the last run
+		_ = os.Remove(activePortFile)
+	}
 
 	// Start Chrome with remote debugging
 	args := []string{
@@ -117,16 +292,17 @@ func (m *Manager) start() error {
 		"--disable-backgrounding-occluded-windows",
 		"--disable-renderer-backgrounding",
 		"--disable-features=VizDisplayCompositor",
-		fmt.Sprintf("--remote-debugging-port=%d", m.debugPort),
-		"--user-data-dir=/tmp/essenz-chrome-profile",
-		"about:blank",
+		fmt.Sprintf("--remote-debugging-port=%d", m.requestedPort),
+		"--user-data-dir=" + profileDir,
+	}
+	if inContainer() {
+		// Container /dev/shm is too small for Chrome's renderers
+		args = append(args, "--disable-dev-shm-usage")
 	}
+	args = append(args, "about:blank")
 
 	m.chromeCmd = exec.Command(chromePath, args...)
-	m.chromeCmd.SysProcAttr = &syscall.SysProcAttr{
-		Setpgid: true, // Create new process group
-		Setsid:  true, // Create new session
-	}
+	m.chromeCmd.SysProcAttr = detachedProcAttr()
 
 	// Detach from parent process completely
 	m.chromeCmd.Stdin = nil
@@ -146,8 +322,20 @@ func (m *Manager) start() error {
 		_ = m.chromeCmd.Wait()
 	}()
 
-	// Wait a moment for Chrome to start
-	time.Sleep(2 * time.Second)
+	m.debugPort = m.requestedPort
+	if m.debugPort == 0 {
+		m.debugPort, err = waitForActivePort(activePortFile, chromeStartTimeout)
+		if err != nil {
+			_ = m.chromeCmd.Process.Kill()
+			return err
+		}
+	}
+
+	// Wait for the debugging endpoint instead of a fixed delay
+	if err := waitForDebugger(m.debugPort, chromeStartTimeout); err != nil {
+		_ = m.chromeCmd.Process.Kill()
+		return err
+	}
 
 	// Create chromedp allocator that connects to the running Chrome
 	m.allocCtx, m.allocCancel = chromedp.NewRemoteAllocator(
@@ -166,100 +354,50 @@ func (m *Manager) start() error {
 		return fmt.Errorf("failed to connect to Chrome: %w", err)
 	}
 
+	m.debugAddr = fmt.Sprintf("localhost:%d", m.debugPort)
 	m.isRunning = true
 	return nil
 }
 
-// findChrome locates the Chrome executable
-func (m *Manager) findChrome() (string, error) {
-	// Check environment variable first
-	if chromePath := os.Getenv("ESSENZ_CHROME_PATH"); chromePath != "" {
-		return chromePath, nil
-	}
-
-	// Common Chrome locations
-	paths := []string{
-		"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
-		"/Applications/Chromium.app/Contents/MacOS/Chromium",
-		"/usr/bin/google-chrome",
-		"/usr/bin/chromium-browser",
-		"/usr/bin/chromium",
-	}
-
-	for _, path := range paths {
-		if _, err := os.Stat(path); err == nil {
-			return path, nil
-		}
-	}
-
-	return "", fmt.Errorf("Chrome not found in common locations")
-}
-
-// resetIdleTimer resets the idle timeout timer.
-func (m *Manager) resetIdleTimer() {
-	if m.idleTimer != nil {
-		m.idleTimer.Stop()
-	}
-
-	m.idleTimer = time.AfterFunc(m.idleTimeout, func() {
-		m.shutdownWithKill()
-	})
-}
-
-// shutdown gracefully shuts down the Chrome daemon without killing process.
+// shutdown closes all tabs and stops the Chrome process the manager started.
 func (m *Manager) shutdown() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-
-	if !m.isRunning {
-		return
-	}
-
-	if m.idleTimer != nil {
-		m.idleTimer.Stop()
-		m.idleTimer = nil
-	}
-
-	if m.allocCancel != nil {
-		m.allocCancel()
-		m.allocCancel = nil
-	}
-
-	// Don't kill Chrome process - let it persist
-	m.isRunning = false
-	// Keep chromePID for process monitoring
+	m.shutdownLocked()
 }
 
-// shutdownWithKill shuts down and kills the Chrome process.
-func (m *Manager) shutdownWithKill() {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
+// shutdownLocked stops Chrome. The caller must hold m.mu.
+func (m *Manager) shutdownLocked() {
 	if !m.isRunning {
 		return
 	}
 
-	if m.idleTimer != nil {
-		m.idleTimer.Stop()
-		m.idleTimer = nil
-	}
-
+	m.pool.drain()
+	m.contexts.close()
 	if m.allocCancel != nil {
 		m.allocCancel()
 		m.allocCancel = nil
 	}
 
-	// Kill Chrome process on idle timeout
 	if m.chromeCmd != nil && m.chromeCmd.Process != nil {
 		_ = m.chromeCmd.Process.Kill()
 		m.chromeCmd = nil
 	}
+	m.stopContainer()
 
+	if m.remoteURL != "" {
+		m.logger.Info("disconnected from remote chrome", "address", m.debugAddr)
+	} else {
+		m.logger.Info("chrome stopped", "pid", m.chromePID)
+	}
 	m.isRunning = false
 	m.chromePID = 0
+	m.chromeVersion = ""
+	m.chromeWarning = ""
+	m.served = 0
 }
 
-// Shutdown manually shuts down the daemon.
+// Shutdown stops Chrome. The next request starts it again.
 func (m *Manager) Shutdown() {
 	m.shutdown()
 }
@@ -271,9 +409,7 @@ func (m *Manager) IsRunning() bool {
 
 	// Check if process is still alive
 	if m.chromeCmd != nil && m.chromeCmd.Process != nil {
-		// Try to send signal 0 to check if process exists
-		err := m.chromeCmd.Process.Signal(syscall.Signal(0))
-		if err != nil {
+		if !processAlive(m.chromeCmd.Process) {
 			// Process is dead, update state
 			m.isRunning = false
 			return false
@@ -283,12 +419,55 @@ func (m *Manager) IsRunning() bool {
 	return m.isRunning
 }
 
-// getIdleTimeout returns the idle timeout from environment or default.
-func getIdleTimeout() time.Duration {
-	if timeoutStr := os.Getenv("ESSENZ_DAEMON_TIMEOUT"); timeoutStr != "" {
-		if timeout, err := time.ParseDuration(timeoutStr); err == nil {
-			return timeout
+// waitForDebugger polls Chrome's remote debugging endpoint until it answers.
+func waitForDebugger(port int, timeout time.Duration) error {
+	endpoint := fmt.Sprintf("http://localhost:%d/json/version", port)
+	client := &http.Client{Timeout: time.Second}
+	deadline := time.Now().Add(timeout)
+
+	for {
+		resp, err := client.Get(endpoint)
+		if err == nil {
+			_ = resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("Chrome did not open its debugging port within %v", timeout)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// chromeProfileDir returns the Chrome profile directory of the named daemon.
+func chromeProfileDir(name string) string {
+	return filepath.Join(dataDir(), namedFile("chrome-profile", name, ""))
+}
+
+// dataDir returns the directory essenz keeps browser data in: under the user
+// cache directory (XDG_CACHE_HOME on Linux), or the temp directory.
+func dataDir() string {
+	if cacheDir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(cacheDir, "essenz")
+	}
+	return filepath.Join(os.TempDir(), "essenz")
+}
+
+// waitForActivePort reads the debugging port Chrome picked from the
+// DevToolsActivePort file in its profile directory.
+func waitForActivePort(path string, timeout time.Duration) (int, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		if data, err := os.ReadFile(path); err == nil {
+			line, _, _ := strings.Cut(string(data), "\n")
+			if port, err := strconv.Atoi(strings.TrimSpace(line)); err == nil && port > 0 {
+				return port, nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return 0, fmt.Errorf("Chrome did not report its debugging port within %v", timeout)
 		}
+		time.Sleep(50 * time.Millisecond)
 	}
-	return 300 * time.Second // Default 300 seconds (5 minutes)
 }
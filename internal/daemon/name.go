@@ -0,0 +1,58 @@
+package daemon
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"regexp"
+)
+
+// validName restricts daemon names to characters that are safe in file names.
+var validName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// DaemonName returns the name of the daemon instance selected with
+// ESSENZ_DAEMON_NAME. The empty name is the default daemon; invalid names
+// are ignored.
+func DaemonName() string {
+	name := os.Getenv("ESSENZ_DAEMON_NAME")
+	if ValidateDaemonName(name) != nil {
+		return ""
+	}
+	return name
+}
+
+// ValidateDaemonName checks that a daemon name can be used in socket and profile paths.
+func ValidateDaemonName(name string) error {
+	if name != "" && !validName.MatchString(name) {
+		return fmt.Errorf("invalid daemon name %q: use letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// ValidateProfileName checks that a browser profile name can be used as a file name.
+func ValidateProfileName(name string) error {
+	if name != "" && !validName.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// namedFile returns base for the default daemon and base-name for a named one,
+// keeping ext at the end.
+func namedFile(base, name, ext string) string {
+	if name == "" {
+		return base + ext
+	}
+	return base + "-" + name + ext
+}
+
+// namedPort offsets a default port by a stable hash of the daemon name so
+// named daemons on TCP do not collide with each other.
+func namedPort(base int, name string) int {
+	if name == "" {
+		return base
+	}
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(name))
+	return base + 1 + int(hash.Sum32()%1000)
+}
@@ -0,0 +1,143 @@
+package daemon
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jewell-lgtm/essenz/internal/actions"
+	"github.com/jewell-lgtm/essenz/internal/emulate"
+	"github.com/jewell-lgtm/essenz/internal/pageready"
+	"github.com/jewell-lgtm/essenz/internal/session"
+)
+
+// Output modes for the content the daemon returns.
+const (
+	OutputHTML = "html" // Rendered HTML of the whole document (default)
+	OutputText = "text" // Visible text of the body
+)
+
+// FetchOptions configures how the daemon loads a page and what it returns.
+// New fetch features add a field here rather than to Request.
+type FetchOptions struct {
+	// Readiness overrides the daemon's default DOM readiness detection
+	Readiness *pageready.ReadinessChecker `json:"readiness,omitempty"`
+
+	// Scroll is "auto" or a number of viewports to scroll before extraction
+	Scroll          string `json:"scroll,omitempty"`
+	ScrollMaxHeight int    `json:"scroll_max_height,omitempty"` // Pixel limit for scrolling
+
+	// Actions holds interaction steps run after readiness, before scrolling
+	Actions *actions.Script `json:"actions,omitempty"`
+
+	// Eval holds scripts run in the page after readiness and scrolling
+	Eval []string `json:"eval,omitempty"`
+
+	// LoadState seeds cookies and localStorage before navigation
+	LoadState *session.StorageState `json:"load_state,omitempty"`
+
+	// SaveState returns the session's cookies and localStorage after the fetch
+	SaveState bool `json:"save_state,omitempty"`
+
+	// Headers are sent with every request the page makes
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Auth answers basic auth challenges from the page's own origin
+	Auth *session.BasicAuth `json:"auth,omitempty"`
+
+	// UserAgent overrides the browser's user agent for the page
+	UserAgent string `json:"user_agent,omitempty"`
+
+	// Viewport emulates a screen size or device before navigation
+	Viewport *emulate.Viewport `json:"viewport,omitempty"`
+
+	// Region emulates a locale, time zone, and geolocation before navigation
+	Region *emulate.Region `json:"region,omitempty"`
+
+	// Adblock blocks ad and tracker requests using the bundled list plus AdblockLists files
+	Adblock      bool     `json:"adblock,omitempty"`
+	AdblockLists []string `json:"adblock_lists,omitempty"`
+
+	// CaptureConsole returns the page's console messages and script errors
+	CaptureConsole bool `json:"capture_console,omitempty"`
+
+	// RecordHAR returns the page load's network activity as a HAR log
+	RecordHAR bool `json:"record_har,omitempty"`
+
+	// CaptureValidators returns the ETag and Last-Modified of the page's
+	// document, so the next fetch can ask whether it changed first
+	CaptureValidators bool `json:"capture_validators,omitempty"`
+
+	// DownloadDir is where file downloads are saved instead of failing the fetch
+	DownloadDir string `json:"download_dir,omitempty"`
+
+	// Output selects what the response content holds: OutputHTML or OutputText
+	Output string `json:"output,omitempty"`
+
+	// Incognito runs the request in a throwaway browser context that shares no cookies or storage
+	Incognito bool `json:"incognito,omitempty"`
+
+	// Profile runs the request in a named browser context whose session persists between requests
+	Profile string `json:"profile,omitempty"`
+
+	// RemoteChrome is the DevTools endpoint the client expects the daemon to drive
+	RemoteChrome string `json:"remote_chrome,omitempty"`
+
+	// QueueTimeout overrides how long the request may wait for a free fetch slot
+	QueueTimeout time.Duration `json:"queue_timeout,omitempty"`
+}
+
+// Validate checks option values that the daemon cannot act on.
+func (o *FetchOptions) Validate() error {
+	if _, err := pageready.ParseScroll(o.Scroll); err != nil {
+		return err
+	}
+
+	switch o.Output {
+	case "", OutputHTML, OutputText:
+	default:
+		return fmt.Errorf("unknown output mode %q: use %s or %s", o.Output, OutputHTML, OutputText)
+	}
+
+	if o.Incognito && o.Profile != "" {
+		return fmt.Errorf("incognito and profile cannot be combined")
+	}
+	if err := ValidateProfileName(o.Profile); err != nil {
+		return err
+	}
+
+	if o.QueueTimeout < 0 {
+		return fmt.Errorf("queue timeout must not be negative")
+	}
+	return nil
+}
+
+// carriesSession reports whether the request loads or saves a browser
+// session. It then runs in a throwaway browser context, so the cookies it
+// loads are not sent by later requests, and the cookies it saves are its own
+// and not every cookie the daemon collected from earlier requests.
+// A nil receiver means default options.
+func (o *FetchOptions) carriesSession() bool {
+	return o != nil && (o.LoadState != nil || o.SaveState)
+}
+
+// leavesTabClean reports whether a fetch with these options leaves no
+// overrides, listeners, or injected scripts behind, so its tab can be reused.
+// A nil receiver means default options.
+func (o *FetchOptions) leavesTabClean(url string) bool {
+	if pageready.HashRoute(url) != "" {
+		return false
+	}
+	if o == nil {
+		return true
+	}
+	return o.LoadState == nil &&
+		len(o.Headers) == 0 &&
+		o.Auth == nil &&
+		o.UserAgent == "" &&
+		o.Viewport == nil &&
+		o.Region == nil &&
+		!o.Adblock &&
+		!o.CaptureConsole &&
+		!o.RecordHAR &&
+		o.DownloadDir == ""
+}
@@ -0,0 +1,110 @@
+package daemon
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jewell-lgtm/essenz/internal/actions"
+	"github.com/jewell-lgtm/essenz/internal/emulate"
+	"github.com/jewell-lgtm/essenz/internal/pageready"
+	"github.com/jewell-lgtm/essenz/internal/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestOptionsRoundTrip(t *testing.T) {
+	req := Request{
+		Action: "fetch",
+		Token:  "3f2a9c",
+		URL:    "https://example.com/article",
+		Options: &FetchOptions{
+			Readiness: &pageready.ReadinessChecker{
+				MaxWaitTime:     5 * time.Second,
+				CustomSelectors: []string{"article"},
+				NetworkIdle:     true,
+				StageTimeouts:   map[string]time.Duration{"framework": time.Second},
+			},
+			Scroll:          "auto",
+			ScrollMaxHeight: 20000,
+			Actions:         &actions.Script{Steps: []actions.Step{{Click: "button.more"}, {WaitFor: ".comments"}}},
+			Eval:            []string{"document.title"},
+			LoadState: &session.StorageState{
+				Cookies: []session.Cookie{{Name: "session", Value: "abc", Domain: "example.com", Path: "/", HTTPOnly: true}},
+				Origins: []session.OriginStorage{{Origin: "https://example.com", LocalStorage: map[string]string{"theme": "dark"}}},
+			},
+			SaveState:         true,
+			Headers:           map[string]string{"X-Api-Key": "abc123"},
+			Auth:              &session.BasicAuth{Username: "staging", Password: "s3cret"},
+			UserAgent:         "MyReader/1.0",
+			Viewport:          &emulate.Viewport{Width: 390, Height: 844, Scale: 3, Mobile: true, Touch: true},
+			Region:            &emulate.Region{Locale: "de-DE", Languages: []string{"de-DE", "en"}, Timezone: "Europe/Berlin", Geolocation: &emulate.Geolocation{Latitude: 52.52, Longitude: 13.405}},
+			Adblock:           true,
+			AdblockLists:      []string{"/etc/essenz/extra.txt"},
+			CaptureConsole:    true,
+			RecordHAR:         true,
+			CaptureValidators: true,
+			DownloadDir:       "/tmp/downloads",
+			Output:            OutputText,
+			Profile:           "work",
+			RemoteChrome:      "ws://127.0.0.1:9222/devtools/browser/remote",
+			QueueTimeout:      45 * time.Second,
+		},
+	}
+
+	data, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	var decoded Request
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, req, decoded)
+}
+
+func TestRequestWithoutOptions(t *testing.T) {
+	data, err := json.Marshal(Request{Action: "ping", Token: "3f2a9c"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"action":"ping","token":"3f2a9c"}`, string(data), "A request without options should not send any")
+
+	data, err = json.Marshal(Request{Action: "fetch", URL: "https://example.com", Options: &FetchOptions{}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"action":"fetch","token":"","url":"https://example.com","options":{}}`, string(data), "Default options should be empty")
+}
+
+func TestLeavesTabClean(t *testing.T) {
+	const page = "https://example.com/article"
+
+	tests := []struct {
+		name string
+		opts *FetchOptions
+		url  string
+		want bool
+	}{
+		{"nil options", nil, page, true},
+		{"default options", &FetchOptions{}, page, true},
+		{"options that only change extraction", &FetchOptions{Scroll: "auto", Eval: []string{"document.title"}, Output: OutputText, CaptureValidators: true}, page, true},
+		{"hash route", nil, "https://example.com/#/article/5", false},
+		{"load state", &FetchOptions{LoadState: &session.StorageState{}}, page, false},
+		{"headers", &FetchOptions{Headers: map[string]string{"X-Api-Key": "abc123"}}, page, false},
+		{"basic auth", &FetchOptions{Auth: &session.BasicAuth{Username: "staging", Password: "s3cret"}}, page, false},
+		{"user agent", &FetchOptions{UserAgent: "MyReader/1.0"}, page, false},
+		{"viewport", &FetchOptions{Viewport: &emulate.Viewport{Width: 390, Height: 844}}, page, false},
+		{"region", &FetchOptions{Region: &emulate.Region{Timezone: "Europe/Berlin"}}, page, false},
+		{"adblock", &FetchOptions{Adblock: true}, page, false},
+		{"console capture", &FetchOptions{CaptureConsole: true}, page, false},
+		{"HAR recording", &FetchOptions{RecordHAR: true}, page, false},
+		{"download directory", &FetchOptions{DownloadDir: "/tmp/downloads"}, page, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.opts.leavesTabClean(tt.url))
+		})
+	}
+}
+
+func TestCarriesSession(t *testing.T) {
+	var none *FetchOptions
+	assert.False(t, none.carriesSession())
+	assert.False(t, (&FetchOptions{Incognito: true}).carriesSession())
+	assert.True(t, (&FetchOptions{LoadState: &session.StorageState{}}).carriesSession())
+	assert.True(t, (&FetchOptions{SaveState: true}).carriesSession())
+}
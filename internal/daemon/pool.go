@@ -0,0 +1,143 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// defaultPoolSize is how many tabs are kept warm when ESSENZ_TAB_POOL_SIZE is unset.
+const defaultPoolSize = 2
+
+// Tab is a browser tab handed out for one request.
+type Tab struct {
+	Context  context.Context
+	cancel   context.CancelFunc
+	isolated bool // Belongs to an incognito or profile context, not the pool
+}
+
+// tabPool keeps pre-created tabs ready so a request does not pay for opening one.
+type tabPool struct {
+	size  int
+	tabs  chan *Tab
+	mu    sync.Mutex
+	alloc context.Context // Allocator the pooled tabs belong to
+}
+
+// newTabPool creates an empty pool holding up to size tabs.
+func newTabPool(size int) *tabPool {
+	return &tabPool{
+		size: size,
+		tabs: make(chan *Tab, size),
+	}
+}
+
+// reset points the pool at a new allocator, closing tabs from the old one.
+func (p *tabPool) reset(alloc context.Context) {
+	p.mu.Lock()
+	p.alloc = alloc
+	p.mu.Unlock()
+
+	p.drain()
+	for i := 0; i < p.size; i++ {
+		go p.refill()
+	}
+}
+
+// get returns a warm tab, or opens one if the pool is empty.
+func (p *tabPool) get() (*Tab, error) {
+	select {
+	case tab := <-p.tabs:
+		if tab.Context.Err() == nil {
+			return tab, nil
+		}
+	default:
+	}
+
+	p.mu.Lock()
+	alloc := p.alloc
+	p.mu.Unlock()
+	return openTab(alloc)
+}
+
+// put returns a tab after use. A tab whose state the request changed is
+// closed and replaced with a fresh one instead of being reused.
+func (p *tabPool) put(tab *Tab, reusable bool) {
+	if !reusable || p.size == 0 {
+		tab.cancel()
+		go p.refill()
+		return
+	}
+
+	// Stop scripts and timers left by the previous page
+	resetCtx, cancel := context.WithTimeout(tab.Context, 5*time.Second)
+	err := chromedp.Run(resetCtx, chromedp.Navigate("about:blank"))
+	cancel()
+	if err != nil {
+		tab.cancel()
+		go p.refill()
+		return
+	}
+
+	select {
+	case p.tabs <- tab:
+	default:
+		tab.cancel()
+	}
+}
+
+// refill opens a tab if the pool has room.
+func (p *tabPool) refill() {
+	p.mu.Lock()
+	alloc := p.alloc
+	p.mu.Unlock()
+	if alloc == nil || alloc.Err() != nil || len(p.tabs) >= p.size {
+		return
+	}
+
+	tab, err := openTab(alloc)
+	if err != nil {
+		return
+	}
+	select {
+	case p.tabs <- tab:
+	default:
+		tab.cancel()
+	}
+}
+
+// drain closes all pooled tabs.
+func (p *tabPool) drain() {
+	for {
+		select {
+		case tab := <-p.tabs:
+			tab.cancel()
+		default:
+			return
+		}
+	}
+}
+
+// openTab creates a tab and waits until its target exists.
+func openTab(alloc context.Context) (*Tab, error) {
+	ctx, cancel := chromedp.NewContext(alloc)
+	if err := chromedp.Run(ctx); err != nil {
+		cancel()
+		return nil, err
+	}
+	return &Tab{Context: ctx, cancel: cancel}, nil
+}
+
+// getPoolSize returns the tab pool size from the environment or the default.
+func getPoolSize() int {
+	if sizeStr := os.Getenv("ESSENZ_TAB_POOL_SIZE"); sizeStr != "" {
+		if size, err := strconv.Atoi(sizeStr); err == nil && size >= 0 {
+			return size
+		}
+	}
+	return defaultPoolSize
+}
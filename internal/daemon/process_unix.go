@@ -0,0 +1,62 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// chromePaths lists common Chrome, Chromium, and Edge install locations on
+// macOS and Linux, including snap and system-wide Flatpak installs.
+var chromePaths = []string{
+	"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
+	"/Applications/Chromium.app/Contents/MacOS/Chromium",
+	"/Applications/Microsoft Edge.app/Contents/MacOS/Microsoft Edge",
+	"/usr/bin/google-chrome",
+	"/usr/bin/google-chrome-stable",
+	"/usr/bin/chromium-browser",
+	"/usr/bin/chromium",
+	"/snap/bin/chromium",
+	"/var/lib/flatpak/exports/bin/com.google.Chrome",
+	"/var/lib/flatpak/exports/bin/org.chromium.Chromium",
+	"/usr/bin/microsoft-edge",
+	"/usr/bin/microsoft-edge-stable",
+	"/var/lib/flatpak/exports/bin/com.microsoft.Edge",
+}
+
+// detachedProcAttr starts a process in its own session, and so its own
+// process group, so it outlives the terminal it was started from. Setpgid is
+// not combined with Setsid: a session leader cannot change its process group.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		Setsid: true,
+	}
+}
+
+// processAlive reports whether a process is still running.
+func processAlive(process *os.Process) bool {
+	// Signal 0 checks for existence without affecting the process
+	return process.Signal(syscall.Signal(0)) == nil
+}
+
+// processMemory returns a process's resident memory in bytes. Only Linux
+// exposes this without cgo, so other systems report 0.
+func processMemory(pid int) uint64 {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/statm", pid))
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return 0
+	}
+	pages, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return pages * uint64(os.Getpagesize())
+}
@@ -0,0 +1,57 @@
+//go:build windows
+
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// Windows API constants not exported by the syscall package.
+const (
+	createNewProcessGroup          = 0x00000200
+	detachedProcess                = 0x00000008
+	processQueryLimitedInformation = 0x1000
+	stillActive                    = 259
+)
+
+// chromePaths lists common Chrome install locations on Windows.
+var chromePaths = []string{
+	filepath.Join(os.Getenv("ProgramFiles"), `Google\Chrome\Application\chrome.exe`),
+	filepath.Join(os.Getenv("ProgramFiles(x86)"), `Google\Chrome\Application\chrome.exe`),
+	filepath.Join(os.Getenv("LocalAppData"), `Google\Chrome\Application\chrome.exe`),
+	filepath.Join(os.Getenv("ProgramFiles"), `Chromium\Application\chrome.exe`),
+	filepath.Join(os.Getenv("ProgramFiles(x86)"), `Microsoft\Edge\Application\msedge.exe`),
+	filepath.Join(os.Getenv("ProgramFiles"), `Microsoft\Edge\Application\msedge.exe`),
+}
+
+// detachedProcAttr starts Chrome without a console and outside the daemon's
+// process group so Ctrl+C in the terminal does not reach it.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		CreationFlags: createNewProcessGroup | detachedProcess,
+		HideWindow:    true,
+	}
+}
+
+// processAlive reports whether a process is still running. Windows does not
+// support signal 0, so the process exit code is checked instead.
+func processAlive(process *os.Process) bool {
+	handle, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(process.Pid))
+	if err != nil {
+		return false
+	}
+	defer func() { _ = syscall.CloseHandle(handle) }()
+
+	var code uint32
+	if err := syscall.GetExitCodeProcess(handle, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
+
+// processMemory is not reported on Windows, which needs the psapi DLL for it.
+func processMemory(_ int) uint64 {
+	return 0
+}
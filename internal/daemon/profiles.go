@@ -0,0 +1,200 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/chromedp"
+	"github.com/jewell-lgtm/essenz/internal/session"
+)
+
+// browserContexts hands out tabs in isolated browser contexts: a throwaway
+// one per incognito request, or a long-lived one per named profile whose
+// cookies and localStorage are saved to disk between daemon runs.
+type browserContexts struct {
+	mu       sync.Mutex
+	root     context.Context // Browser connection new contexts are created from
+	cancel   context.CancelFunc
+	profiles map[string]*profile
+}
+
+// profile is a named browser context kept open while the daemon runs.
+type profile struct {
+	anchor context.Context // Tab that keeps the browser context alive
+	cancel context.CancelFunc
+	id     cdp.BrowserContextID
+	state  *session.StorageState
+}
+
+// newBrowserContexts creates an empty set of isolated contexts.
+func newBrowserContexts() *browserContexts {
+	return &browserContexts{profiles: make(map[string]*profile)}
+}
+
+// incognito opens a tab in a fresh browser context that shares nothing with
+// other requests. Closing the tab disposes the context.
+func (b *browserContexts) incognito(alloc context.Context) (*Tab, error) {
+	root, err := b.rootContext(alloc)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := chromedp.NewContext(root, chromedp.WithNewBrowserContext())
+	if err := chromedp.Run(ctx); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to create incognito context: %w", err)
+	}
+	return &Tab{Context: ctx, cancel: cancel, isolated: true}, nil
+}
+
+// profileTab opens a tab in the named profile's browser context, creating the
+// context and restoring its saved session the first time.
+func (b *browserContexts) profileTab(alloc context.Context, name string) (*Tab, error) {
+	root, err := b.rootContext(alloc)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	p, ok := b.profiles[name]
+	if !ok {
+		p, err = openProfile(root, name)
+		if err != nil {
+			b.mu.Unlock()
+			return nil, err
+		}
+		b.profiles[name] = p
+	}
+	id, state := p.id, p.state
+	b.mu.Unlock()
+
+	ctx, cancel := chromedp.NewContext(root, chromedp.WithExistingBrowserContext(id))
+	if err := chromedp.Run(ctx); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to open tab in profile %s: %w", name, err)
+	}
+	// localStorage is seeded per tab, so every new tab gets the saved entries
+	if state != nil {
+		if err := state.Apply(ctx); err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+	return &Tab{Context: ctx, cancel: cancel, isolated: true}, nil
+}
+
+// saveProfile records the session a request left in a profile tab and writes
+// it to disk so the profile survives daemon restarts.
+func (b *browserContexts) saveProfile(name string, tab *Tab) error {
+	captured, err := session.Capture(tab.Context)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	p, ok := b.profiles[name]
+	if !ok {
+		return nil
+	}
+	p.state = mergeState(p.state, captured)
+	return p.state.Save(profileStatePath(name))
+}
+
+// close disposes all browser contexts. Profiles are reloaded from disk when
+// they are next used.
+func (b *browserContexts) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for name, p := range b.profiles {
+		p.cancel()
+		delete(b.profiles, name)
+	}
+	if b.cancel != nil {
+		b.cancel()
+		b.cancel = nil
+		b.root = nil
+	}
+}
+
+// rootContext returns the browser connection isolated contexts are created
+// from, opening it on first use.
+func (b *browserContexts) rootContext(alloc context.Context) (context.Context, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.root != nil && b.root.Err() == nil {
+		return b.root, nil
+	}
+
+	root, cancel := chromedp.NewContext(alloc)
+	if err := chromedp.Run(root); err != nil {
+		cancel()
+		return nil, err
+	}
+	b.root, b.cancel = root, cancel
+	return root, nil
+}
+
+// openProfile creates the browser context for a profile and restores its
+// cookies from disk.
+func openProfile(root context.Context, name string) (*profile, error) {
+	anchor, cancel := chromedp.NewContext(root, chromedp.WithNewBrowserContext())
+	if err := chromedp.Run(anchor); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to create profile %s: %w", name, err)
+	}
+
+	p := &profile{
+		anchor: anchor,
+		cancel: cancel,
+		id:     chromedp.FromContext(anchor).BrowserContextID,
+	}
+
+	path := profileStatePath(name)
+	if _, err := os.Stat(path); err == nil {
+		state, err := session.Load(path)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		p.state = state
+	}
+	return p, nil
+}
+
+// mergeState takes the cookies from the latest capture, which holds every
+// cookie in the context, and adds its localStorage to what earlier requests saved.
+func mergeState(saved, captured *session.StorageState) *session.StorageState {
+	merged := &session.StorageState{Cookies: captured.Cookies}
+	if saved != nil {
+		for _, origin := range saved.Origins {
+			if !hasOrigin(captured.Origins, origin.Origin) {
+				merged.Origins = append(merged.Origins, origin)
+			}
+		}
+	}
+	merged.Origins = append(merged.Origins, captured.Origins...)
+	return merged
+}
+
+// hasOrigin reports whether the list holds storage for origin.
+func hasOrigin(origins []session.OriginStorage, origin string) bool {
+	for _, o := range origins {
+		if o.Origin == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// profileStatePath returns where a named profile's session is saved.
+func profileStatePath(name string) string {
+	return filepath.Join(dataDir(), "profiles", name+".json")
+}
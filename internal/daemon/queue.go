@@ -0,0 +1,137 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults used when the ESSENZ_MAX_CONCURRENCY, ESSENZ_QUEUE_SIZE, and
+// ESSENZ_QUEUE_TIMEOUT environment variables are unset.
+const (
+	defaultMaxConcurrency = 4
+	defaultQueueSize      = 32
+	defaultQueueTimeout   = 15 * time.Second
+)
+
+var (
+	// ErrQueueFull is returned when every fetch slot is busy and the queue has no room.
+	ErrQueueFull = errors.New("daemon busy: request queue is full")
+
+	// ErrQueueTimeout is returned when a request waited too long for a fetch slot.
+	ErrQueueTimeout = errors.New("daemon busy: timed out waiting in request queue")
+)
+
+// requestQueue limits how many fetches run against Chrome at once. Requests
+// beyond the limit wait in FIFO order until a slot frees up.
+type requestQueue struct {
+	mu      sync.Mutex
+	limit   int
+	size    int
+	active  int
+	waiters []chan struct{}
+}
+
+// newRequestQueue creates a queue running up to limit requests at once with
+// room for size waiting requests.
+func newRequestQueue(limit, size int) *requestQueue {
+	return &requestQueue{
+		limit: limit,
+		size:  size,
+	}
+}
+
+// acquire takes a fetch slot, waiting in line until one is free or the
+// timeout passes. Every successful acquire must be paired with release.
+func (q *requestQueue) acquire(ctx context.Context, timeout time.Duration) error {
+	q.mu.Lock()
+	if q.active < q.limit && len(q.waiters) == 0 {
+		q.active++
+		q.mu.Unlock()
+		return nil
+	}
+	if len(q.waiters) >= q.size {
+		q.mu.Unlock()
+		return ErrQueueFull
+	}
+	ready := make(chan struct{})
+	q.waiters = append(q.waiters, ready)
+	q.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-ready:
+		return nil
+	case <-timer.C:
+		return q.abandon(ready, ErrQueueTimeout)
+	case <-ctx.Done():
+		return q.abandon(ready, ctx.Err())
+	}
+}
+
+// abandon removes a waiter that gave up. If the slot was handed over in the
+// meantime it is passed on so it does not leak.
+func (q *requestQueue) abandon(ready chan struct{}, err error) error {
+	q.mu.Lock()
+	for i, waiter := range q.waiters {
+		if waiter == ready {
+			q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+			q.mu.Unlock()
+			return err
+		}
+	}
+	q.mu.Unlock()
+
+	// Not in line anymore, so release handed us a slot
+	q.release()
+	return err
+}
+
+// release frees a fetch slot, handing it straight to the longest waiting request.
+func (q *requestQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.waiters) > 0 {
+		next := q.waiters[0]
+		q.waiters = q.waiters[1:]
+		close(next)
+		return
+	}
+	q.active--
+}
+
+// getMaxConcurrency returns the number of concurrent fetches from the environment or the default.
+func getMaxConcurrency() int {
+	if limitStr := os.Getenv("ESSENZ_MAX_CONCURRENCY"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			return limit
+		}
+	}
+	return defaultMaxConcurrency
+}
+
+// getQueueSize returns the number of requests allowed to wait from the environment or the default.
+func getQueueSize() int {
+	if sizeStr := os.Getenv("ESSENZ_QUEUE_SIZE"); sizeStr != "" {
+		if size, err := strconv.Atoi(sizeStr); err == nil && size >= 0 {
+			return size
+		}
+	}
+	return defaultQueueSize
+}
+
+// getQueueTimeout returns how long a request may wait for a slot from the environment or the default.
+func getQueueTimeout() time.Duration {
+	if timeoutStr := os.Getenv("ESSENZ_QUEUE_TIMEOUT"); timeoutStr != "" {
+		if timeout, err := time.ParseDuration(timeoutStr); err == nil && timeout > 0 {
+			return timeout
+		}
+	}
+	return defaultQueueTimeout
+}
@@ -0,0 +1,82 @@
+package daemon
+
+import (
+	"os"
+	"strconv"
+)
+
+// Recycling defaults used when ESSENZ_RECYCLE_REQUESTS and ESSENZ_RECYCLE_MEMORY_MB are unset.
+const (
+	defaultRecycleRequests = 500  // Requests a Chrome serves before it is restarted
+	defaultRecycleMemoryMB = 1024 // Resident size of Chrome's browser process that triggers a restart
+)
+
+// recycleLimits bounds how long one Chrome process is used. Chrome leaks
+// memory and targets over thousands of pages, so the manager restarts it
+// once either limit is reached. Zero disables a limit.
+type recycleLimits struct {
+	requests int
+	memory   uint64 // Bytes
+}
+
+// getRecycleLimits returns the recycling limits from the environment or the defaults.
+func getRecycleLimits() recycleLimits {
+	limits := recycleLimits{
+		requests: defaultRecycleRequests,
+		memory:   defaultRecycleMemoryMB << 20,
+	}
+	if requestsStr := os.Getenv("ESSENZ_RECYCLE_REQUESTS"); requestsStr != "" {
+		if requests, err := strconv.Atoi(requestsStr); err == nil && requests >= 0 {
+			limits.requests = requests
+		}
+	}
+	if memoryStr := os.Getenv("ESSENZ_RECYCLE_MEMORY_MB"); memoryStr != "" {
+		if memory, err := strconv.Atoi(memoryStr); err == nil && memory >= 0 {
+			limits.memory = uint64(memory) << 20
+		}
+	}
+	return limits
+}
+
+// dueForRecycle reports why Chrome should be restarted, or the empty string.
+// A remote Chrome is never restarted since the manager does not own it. The
+// caller must hold m.mu.
+func (m *Manager) dueForRecycle() string {
+	if m.remoteURL != "" || !m.isRunning {
+		return ""
+	}
+	if m.recycle.requests > 0 && m.served >= m.recycle.requests {
+		return "request limit"
+	}
+	if m.recycle.memory > 0 && m.chromePID != 0 && processMemory(m.chromePID) >= m.recycle.memory {
+		return "memory limit"
+	}
+	return ""
+}
+
+// finishRequest records that a request has released its tab, and restarts
+// Chrome once it is due and no request is using it. The caller must hold m.mu.
+func (m *Manager) finishRequest() {
+	m.inUse--
+	m.served++
+
+	if m.recycleReason == "" {
+		m.recycleReason = m.dueForRecycle()
+		if m.recycleReason != "" {
+			m.logger.Info("chrome due for recycling", "reason", m.recycleReason, "served", m.served, "in_use", m.inUse)
+		}
+	}
+	if m.recycleReason != "" && m.inUse == 0 {
+		m.recycleChrome()
+	}
+	m.drained.Broadcast()
+}
+
+// recycleChrome stops Chrome so the next request starts a fresh one. The
+// caller must hold m.mu.
+func (m *Manager) recycleChrome() {
+	m.logger.Info("recycling chrome", "reason", m.recycleReason, "served", m.served)
+	m.shutdownLocked()
+	m.recycleReason = ""
+	m.recycles++
+}
@@ -0,0 +1,116 @@
+package daemon
+
+import (
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRecycleLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		requests string
+		memoryMB string
+		want     recycleLimits
+	}{
+		{"defaults", "", "", recycleLimits{requests: defaultRecycleRequests, memory: defaultRecycleMemoryMB << 20}},
+		{"from the environment", "50", "256", recycleLimits{requests: 50, memory: 256 << 20}},
+		{"zero disables", "0", "0", recycleLimits{}},
+		{"invalid values keep the defaults", "many", "-1", recycleLimits{requests: defaultRecycleRequests, memory: defaultRecycleMemoryMB << 20}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ESSENZ_RECYCLE_REQUESTS", tt.requests)
+			t.Setenv("ESSENZ_RECYCLE_MEMORY_MB", tt.memoryMB)
+			assert.Equal(t, tt.want, getRecycleLimits())
+		})
+	}
+}
+
+// runningManager returns a manager that believes it launched Chrome, without
+// starting one.
+func runningManager(limits recycleLimits) *Manager {
+	m := NewManager()
+	m.isRunning = true
+	m.remoteURL = ""
+	m.container = ""
+	m.recycle = limits
+	return m
+}
+
+func TestDueForRecycle(t *testing.T) {
+	t.Run("under the request limit", func(t *testing.T) {
+		m := runningManager(recycleLimits{requests: 3})
+		m.served = 2
+		assert.Empty(t, m.dueForRecycle())
+	})
+
+	t.Run("at the request limit", func(t *testing.T) {
+		m := runningManager(recycleLimits{requests: 3})
+		m.served = 3
+		assert.Equal(t, "request limit", m.dueForRecycle())
+	})
+
+	t.Run("request limit disabled", func(t *testing.T) {
+		m := runningManager(recycleLimits{})
+		m.served = 100000
+		assert.Empty(t, m.dueForRecycle())
+	})
+
+	t.Run("over the memory limit", func(t *testing.T) {
+		if runtime.GOOS != "linux" {
+			t.Skip("process memory is read from /proc")
+		}
+		// The test process stands in for Chrome; it uses more than one byte
+		m := runningManager(recycleLimits{memory: 1})
+		m.chromePID = os.Getpid()
+		assert.Equal(t, "memory limit", m.dueForRecycle())
+	})
+
+	t.Run("under the memory limit", func(t *testing.T) {
+		m := runningManager(recycleLimits{memory: 1 << 50})
+		m.chromePID = os.Getpid()
+		assert.Empty(t, m.dueForRecycle())
+	})
+
+	t.Run("remote chrome is never recycled", func(t *testing.T) {
+		m := runningManager(recycleLimits{requests: 1, memory: 1})
+		m.remoteURL = "ws://127.0.0.1:9222/devtools/browser/remote"
+		m.served = 10
+		m.chromePID = os.Getpid()
+		assert.Empty(t, m.dueForRecycle())
+	})
+
+	t.Run("stopped chrome is not recycled", func(t *testing.T) {
+		m := runningManager(recycleLimits{requests: 1})
+		m.isRunning = false
+		m.served = 10
+		assert.Empty(t, m.dueForRecycle())
+	})
+}
+
+func TestFinishRequestRecyclesOnceIdle(t *testing.T) {
+	m := runningManager(recycleLimits{requests: 2})
+	m.served = 1
+	m.inUse = 2
+
+	m.mu.Lock()
+	m.finishRequest()
+	m.mu.Unlock()
+
+	assert.Equal(t, "request limit", m.recycleReason, "The limit is reached once the second request finishes")
+	assert.True(t, m.isRunning, "Chrome should keep running while a request still uses it")
+	assert.Equal(t, 0, m.recycles)
+
+	m.mu.Lock()
+	m.finishRequest()
+	m.mu.Unlock()
+
+	assert.False(t, m.isRunning, "Chrome should stop once the last request finishes")
+	assert.Equal(t, 1, m.recycles)
+	assert.Empty(t, m.recycleReason)
+	assert.Equal(t, 0, m.served, "The next Chrome should start counting from zero")
+	assert.Equal(t, 0, m.inUse)
+}
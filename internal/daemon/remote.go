@@ -0,0 +1,92 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+)
+
+// RemoteChrome returns the DevTools endpoint of an already-running Chrome the
+// daemon should drive instead of launching its own, from ESSENZ_REMOTE_CHROME.
+func RemoteChrome() string {
+	return os.Getenv("ESSENZ_REMOTE_CHROME")
+}
+
+// ParseRemoteChrome normalizes a DevTools endpoint to a WebSocket URL. It
+// accepts ws://host:port, http://host:port, or a full
+// ws://host:port/devtools/browser/ID URL.
+func ParseRemoteChrome(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid remote Chrome endpoint %q: use ws://host:port", endpoint)
+	}
+	switch u.Scheme {
+	case "ws", "wss":
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	default:
+		return "", fmt.Errorf("invalid remote Chrome endpoint %q: use ws://host:port", endpoint)
+	}
+	if u.Port() == "" {
+		return "", fmt.Errorf("invalid remote Chrome endpoint %q: missing port", endpoint)
+	}
+	if !strings.HasPrefix(u.Path, "/devtools/browser/") {
+		u.Path = ""
+	}
+	return u.String(), nil
+}
+
+// connectRemote attaches to the Chrome at the manager's remote endpoint. The
+// manager never starts or kills that Chrome; shutting down only disconnects.
+// The caller must hold m.mu.
+func (m *Manager) connectRemote() error {
+	wsURL, err := ParseRemoteChrome(m.remoteURL)
+	if err != nil {
+		return err
+	}
+	u, _ := url.Parse(wsURL)
+
+	m.allocCtx, m.allocCancel = chromedp.NewRemoteAllocator(context.Background(), wsURL)
+
+	// Opening a tab verifies the endpoint speaks DevTools
+	ctx, cancel := context.WithTimeout(m.allocCtx, chromeStartTimeout)
+	defer cancel()
+	testCtx, testCancel := chromedp.NewContext(ctx)
+	defer testCancel()
+	if err := chromedp.Run(testCtx); err != nil {
+		m.allocCancel()
+		m.allocCancel = nil
+		return fmt.Errorf("failed to connect to remote Chrome at %s: %w", m.remoteURL, err)
+	}
+
+	m.debugAddr = u.Host
+	m.chromePID = 0
+	m.isRunning = true
+	return nil
+}
+
+// checkRemoteChrome rejects requests that expect a remote Chrome other than
+// the one this daemon drives, since a daemon has one browser for its lifetime.
+func (m *Manager) checkRemoteChrome(opts *FetchOptions) error {
+	if opts == nil || opts.RemoteChrome == "" {
+		return nil
+	}
+	want, err := ParseRemoteChrome(opts.RemoteChrome)
+	if err != nil {
+		return err
+	}
+	have, _ := ParseRemoteChrome(m.remoteURL)
+	if want == have {
+		return nil
+	}
+	if have == "" {
+		return fmt.Errorf("daemon launches its own Chrome; restart it with --remote-chrome %s, or use --daemon-name to run a second daemon", opts.RemoteChrome)
+	}
+	return fmt.Errorf("daemon is connected to %s; restart it with --remote-chrome %s, or use --daemon-name to run a second daemon", m.remoteURL, opts.RemoteChrome)
+}
@@ -0,0 +1,251 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jewell-lgtm/essenz/internal/config"
+	"github.com/jewell-lgtm/essenz/internal/diff"
+	"github.com/jewell-lgtm/essenz/internal/extractor"
+	"github.com/jewell-lgtm/essenz/internal/hook"
+	"github.com/jewell-lgtm/essenz/internal/revalidate"
+	"github.com/jewell-lgtm/essenz/internal/transcode"
+)
+
+// Limits for one run of a schedule: the fetch is bounded as handleFetch bounds
+// a client's.
+const (
+	scheduleTimeout = 30 * time.Second
+	hookTimeout     = time.Minute
+)
+
+// loadSchedules reads the schedules from the config and checks them, so a
+// mistake is reported when the daemon starts rather than on the first run.
+func loadSchedules() ([]config.Schedule, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	for i := range cfg.Schedules {
+		schedule := &cfg.Schedules[i]
+		if err := schedule.Validate(); err != nil {
+			return nil, err
+		}
+		if err := ValidateProfileName(schedule.Profile); err != nil {
+			return nil, fmt.Errorf("schedule %s: %w", schedule.Label(), err)
+		}
+	}
+	return cfg.Schedules, nil
+}
+
+// runSchedule distills a schedule's page at once and then every interval
+// until the daemon stops.
+func (s *Server) runSchedule(schedule config.Schedule) {
+	logger := s.logger.With("schedule", schedule.Label(), "url", schedule.URL)
+	ticker := time.NewTicker(schedule.Interval)
+	defer ticker.Stop()
+	var validators *revalidate.Validators
+	for {
+		var err error
+		if validators, err = s.runScheduled(logger, schedule, validators); err != nil {
+			s.errMu.Lock()
+			s.lastError = err.Error()
+			s.lastErrorAt = time.Now()
+			s.errMu.Unlock()
+			logger.Error("scheduled fetch failed", "error", err)
+		}
+		select {
+		case <-s.stopChannel:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runScheduled fetches and distills a schedule's page and, when its content
+// changed since the last run, writes it to the output file, appends the
+// change to the diff file and runs the change hook. When the validators of the
+// last run's page are given, the server is asked first whether the page
+// changed, and nothing is fetched if it says not. It returns the validators of
+// the page as fetched, or nil to fetch it in full next time.
+func (s *Server) runScheduled(logger *slog.Logger, schedule config.Schedule, validators *revalidate.Validators) (*revalidate.Validators, error) {
+	unchanged, err := s.unchangedSince(schedule, validators)
+	if err != nil {
+		logger.Debug("revalidation failed, fetching the page", "error", err)
+	}
+	if unchanged {
+		logger.Debug("page not modified")
+		return validators, nil
+	}
+
+	content, validators, err := s.fetchScheduled(logger, schedule)
+	if err != nil {
+		return nil, err
+	}
+	markdown, err := extractor.New().ExtractContent(content)
+	if err != nil {
+		return nil, fmt.Errorf("reader view extraction failed: %w", err)
+	}
+
+	previous, err := os.ReadFile(schedule.Output)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read previous output: %w", err)
+	}
+	firstRun := err != nil
+
+	var change string
+	if !firstRun {
+		// Re-wrapped or re-spaced text is not a change, as with sz diff
+		edits := diff.Compute(diff.Blocks(string(previous)), diff.Blocks(markdown))
+		if !diff.Changed(edits) {
+			logger.Debug("page unchanged")
+			return validators, nil
+		}
+		var since time.Time
+		if stat, err := os.Stat(schedule.Output); err == nil {
+			since = stat.ModTime()
+		}
+		change = diff.Unified(
+			schedule.URL+"\t"+since.Format(time.RFC3339),
+			schedule.URL+"\t"+time.Now().Format(time.RFC3339),
+			edits, 3)
+		if schedule.Diff != "" {
+			if err := appendFile(schedule.Diff, change); err != nil {
+				return nil, fmt.Errorf("failed to write diff: %w", err)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(schedule.Output), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(schedule.Output, []byte(markdown), 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write output: %w", err)
+	}
+	if firstRun {
+		logger.Info("saved page", "output", schedule.Output)
+		return validators, nil
+	}
+	logger.Info("page changed", "output", schedule.Output, "diff", schedule.Diff)
+
+	if schedule.OnChange != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+		defer cancel()
+		var output bytes.Buffer
+		err := hook.Run(ctx, schedule.OnChange, hook.Change{
+			URL:      schedule.URL,
+			Markdown: markdown,
+			Diff:     change,
+			Output:   schedule.Output,
+		}, &output)
+		if err != nil {
+			// The page was saved; only the hook failed
+			return validators, err
+		}
+		logger.Info("ran change hook", "output", strings.TrimSpace(output.String()))
+	}
+	return validators, nil
+}
+
+// unchangedSince asks the server whether a schedule's page is still the
+// version validators identify.
+func (s *Server) unchangedSince(schedule config.Schedule, validators *revalidate.Validators) (bool, error) {
+	if validators == nil {
+		return false, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), scheduleTimeout)
+	defer cancel()
+	req, err := s.http.newRequest(ctx, http.MethodHead, schedule.URL)
+	if err != nil {
+		return false, err
+	}
+	return revalidate.Unchanged(s.http.client, req, validators)
+}
+
+// fetchScheduled loads a schedule's page through the browser, waiting for a
+// free slot like any client request. When no browser can be started, pages
+// without a profile are fetched over plain HTTP instead, as the CLI does. It
+// returns the page's validators unless the schedule is not revalidated: a
+// page in a profile depends on a session a bare request would not carry.
+func (s *Server) fetchScheduled(logger *slog.Logger, schedule config.Schedule) (string, *revalidate.Validators, error) {
+	s.beginRequest()
+	defer s.endRequest()
+
+	if err := s.queue.acquire(context.Background(), s.queueTimeout); err != nil {
+		return "", nil, err
+	}
+	defer s.queue.release()
+
+	// A daemon that took over waits for the old one to close its browser
+	<-s.ready
+
+	ctx, cancel := context.WithTimeout(context.Background(), scheduleTimeout)
+	defer cancel()
+
+	revalidated := schedule.Profile == "" && !schedule.NoRevalidate
+	resp, err := s.backend.Fetch(ctx, logger, Request{
+		Action:  "fetch",
+		URL:     schedule.URL,
+		Options: &FetchOptions{Profile: schedule.Profile, CaptureValidators: revalidated},
+	})
+	if errors.Is(err, ErrBrowserUnavailable) && schedule.Profile == "" {
+		logger.Info("browser unavailable, fetching over HTTP", "error", err)
+		resp.Content, resp.Validators, err = s.fetchHTTP(ctx, schedule.URL)
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to fetch content: %w", err)
+	}
+	s.pagesServed.Add(1)
+	if !revalidated {
+		return resp.Content, nil, nil
+	}
+	return resp.Content, resp.Validators, nil
+}
+
+// fetchHTTP loads a page without a browser, with its validators.
+func (s *Server) fetchHTTP(ctx context.Context, url string) (string, *revalidate.Validators, error) {
+	req, err := s.http.newRequest(ctx, http.MethodGet, url)
+	if err != nil {
+		return "", nil, err
+	}
+	resp, err := s.http.client.Do(req)
+	if err != nil {
+		return "", nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, err
+	}
+	return transcode.ToUTF8(body, resp.Header.Get("Content-Type")), revalidate.FromHeader(resp.Header), nil
+}
+
+// appendFile adds text to the end of a file, creating it and its directory
+// as needed.
+func appendFile(path, text string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(text); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -4,48 +4,107 @@ package daemon
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"net"
 	"os"
-	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
 	"github.com/chromedp/chromedp"
+	"github.com/jewell-lgtm/essenz/internal/adblock"
+	"github.com/jewell-lgtm/essenz/internal/config"
+	"github.com/jewell-lgtm/essenz/internal/console"
+	"github.com/jewell-lgtm/essenz/internal/download"
+	"github.com/jewell-lgtm/essenz/internal/har"
 	"github.com/jewell-lgtm/essenz/internal/pageready"
+	"github.com/jewell-lgtm/essenz/internal/revalidate"
+	"github.com/jewell-lgtm/essenz/internal/session"
 )
 
 // Server manages Chrome processes as a long-running daemon.
 type Server struct {
-	mu          sync.RWMutex
-	manager     *Manager
-	listener    net.Listener
-	socketPath  string
-	isRunning   bool
-	stopChannel chan struct{}
+	mu           sync.RWMutex
+	backend      Backend
+	backendName  string
+	queue        *requestQueue
+	queueTimeout time.Duration
+	listener     net.Listener
+	endpoint     Endpoint
+	endpointErr  error // Why the default endpoint could not be resolved
+	token        string
+	started      time.Time
+	pagesServed  atomic.Int64
+	requestSeq   atomic.Uint64
+	logger       *slog.Logger
+	logFile      io.Closer
+	errMu        sync.Mutex
+	lastError    string
+	lastErrorAt  time.Time
+	idleMu       sync.Mutex
+	idleTimer    *time.Timer
+	idleTimeout  time.Duration
+	inFlight     int
+	isRunning    bool
+	stopChannel  chan struct{}
+	stopped      chan struct{} // Closed once Stop has finished cleaning up
+	conns        sync.WaitGroup
+	ready        chan struct{} // Closed once fetches may use the browser
+	handedOver   bool          // The endpoint belongs to a daemon that took over
+	schedules    []config.Schedule
+	http         *httpClient // Used by schedules for requests made without the browser
 }
 
 // Request represents a client request to the daemon.
 type Request struct {
-	Action string `json:"action"`
-	URL    string `json:"url,omitempty"`
+	Action  string        `json:"action"`
+	Token   string        `json:"token"`
+	URL     string        `json:"url,omitempty"`
+	Options *FetchOptions `json:"options,omitempty"`
 }
 
 // Response represents the daemon's response.
 type Response struct {
-	Success bool   `json:"success"`
-	Content string `json:"content,omitempty"`
-	Error   string `json:"error,omitempty"`
+	Success   bool                       `json:"success"`
+	Content   string                     `json:"content,omitempty"`
+	Error     string                     `json:"error,omitempty"`
+	Readiness *pageready.ReadinessResult `json:"readiness,omitempty"`
+	State     *session.StorageState      `json:"state,omitempty"`
+	Console   []console.Message          `json:"console,omitempty"`
+	HAR       *har.HAR                   `json:"har,omitempty"`
+	Download  *download.Download         `json:"download,omitempty"`
+	Info      *Info                      `json:"info,omitempty"`
+	// Validators identify the version of the page fetched, when asked for
+	Validators *revalidate.Validators `json:"validators,omitempty"`
+	// Status is the HTTP status of the page's own document, 0 when it was
+	// not loaded over HTTP
+	Status int `json:"status,omitempty"`
+	// Unavailable reports that the error came from a browser that could not
+	// be started or reached, rather than from the page
+	Unavailable bool `json:"unavailable,omitempty"`
 }
 
 // NewServer creates a new daemon server.
 func NewServer() *Server {
-	socketPath := filepath.Join(os.TempDir(), "essenz-daemon.sock")
+	endpoint, endpointErr := DefaultEndpoint()
 	return &Server{
-		manager:     NewManager(),
-		socketPath:  socketPath,
-		stopChannel: make(chan struct{}),
+		backend:      backends[BackendName()](),
+		backendName:  BackendName(),
+		queue:        newRequestQueue(getMaxConcurrency(), getQueueSize()),
+		queueTimeout: getQueueTimeout(),
+		idleTimeout:  getIdleTimeout(),
+		endpoint:     endpoint,
+		endpointErr:  endpointErr,
+		logger:       slog.Default(),
+		stopChannel:  make(chan struct{}),
+		stopped:      make(chan struct{}),
+		ready:        make(chan struct{}),
 	}
 }
 
@@ -57,27 +116,104 @@ func (s *Server) Start() error {
 	if s.isRunning {
 		return fmt.Errorf("daemon already running")
 	}
+	if s.endpointErr != nil {
+		return s.endpointErr
+	}
 
-	// Remove existing socket file
-	_ = os.Remove(s.socketPath)
+	// Never take the socket over from a live daemon unless asked to, but
+	// clear up after one that crashed
+	previous, err := s.endpoint.dial(time.Second)
+	if err == nil && os.Getenv(takeoverEnv) == "" {
+		_ = previous.Close()
+		return fmt.Errorf("daemon already running at %s", s.endpoint)
+	}
 
-	// Create Unix socket listener
-	listener, err := net.Listen("unix", s.socketPath)
+	schedules, err := loadSchedules()
+	if err == nil {
+		s.http, err = newHTTPClient()
+	}
 	if err != nil {
-		return fmt.Errorf("failed to create socket: %w", err)
+		if previous != nil {
+			_ = previous.Close()
+		}
+		return err
+	}
+
+	logger, logFile, err := openLog()
+	if err != nil {
+		if previous != nil {
+			_ = previous.Close()
+		}
+		return err
+	}
+	s.logger, s.logFile = logger, logFile
+	s.backend.SetLogger(logger)
+
+	var listener net.Listener
+	var token string
+	if previous != nil {
+		// Clients keep the token they have, so the new daemon shares it
+		token = readToken(s.endpoint.tokenPath())
+		listener, err = s.takeOver(previous)
+		if err != nil {
+			_ = previous.Close()
+			_ = logFile.Close()
+			return fmt.Errorf("failed to take over from running daemon: %w", err)
+		}
+		s.logger.Info("took over from previous daemon")
+		go s.waitForHandover(previous)
+	} else {
+		if state, err := ReadState(s.endpoint); err == nil {
+			s.logger.Warn("removing stale daemon files", "pid", state.PID)
+		}
+		s.endpoint.removeFiles()
+
+		listener, err = s.endpoint.listen()
+		if err != nil {
+			_ = logFile.Close()
+			return fmt.Errorf("failed to create socket: %w", err)
+		}
+
+		// Only clients that can read the token file may send requests
+		token, err = writeToken(s.endpoint.tokenPath())
+		if err != nil {
+			_ = listener.Close()
+			_ = logFile.Close()
+			s.endpoint.cleanup()
+			return err
+		}
+		close(s.ready)
+	}
+	s.started = time.Now()
+	if err := writeState(s.endpoint, s.started); err != nil {
+		_ = listener.Close()
+		_ = logFile.Close()
+		s.endpoint.removeFiles()
+		return err
 	}
 
 	s.listener = listener
+	s.token = token
+	s.schedules = schedules
 	s.isRunning = true
 
-	log.Printf("Daemon started, listening on %s", s.socketPath)
+	s.logger.Info("daemon started", "pid", os.Getpid(), "endpoint", s.endpoint.String(), "name", DaemonName(), "schedules", len(schedules))
 
 	// Start accepting connections
 	go s.acceptConnections()
+	for _, schedule := range schedules {
+		go s.runSchedule(schedule)
+	}
 
 	return nil
 }
 
+// Done returns a channel that is closed once the server has stopped and
+// cleaned up.
+func (s *Server) Done() <-chan struct{} {
+	return s.stopped
+}
+
 // Stop stops the daemon server.
 func (s *Server) Stop() error {
 	s.mu.Lock()
@@ -89,11 +225,16 @@ func (s *Server) Stop() error {
 
 	close(s.stopChannel)
 	_ = s.listener.Close()
-	s.manager.Shutdown()
-	_ = os.Remove(s.socketPath)
+	s.stopIdleTimer()
+	s.backend.Shutdown()
+	if !s.handedOver {
+		s.endpoint.removeFiles()
+	}
 	s.isRunning = false
 
-	log.Printf("Daemon stopped")
+	s.logger.Info("daemon stopped", "pages_served", s.pagesServed.Load(), "handed_over", s.handedOver)
+	_ = s.logFile.Close()
+	close(s.stopped)
 	return nil
 }
 
@@ -111,78 +252,139 @@ func (s *Server) acceptConnections() {
 				case <-s.stopChannel:
 					return
 				default:
-					log.Printf("Error accepting connection: %v", err)
+					if errors.Is(err, net.ErrClosed) {
+						// Handed over to another daemon
+						return
+					}
+					s.logger.Error("failed to accept connection", "error", err)
 					continue
 				}
 			}
 
+			s.conns.Add(1)
 			go s.handleConnection(conn)
 		}
 	}
 }
 
 // handleConnection processes a single client connection.
+// Every request is logged with an ID so its log lines can be followed.
 func (s *Server) handleConnection(conn net.Conn) {
-	defer func() { _ = conn.Close() }()
+	defer s.conns.Done()
+	keepOpen := false
+	defer func() {
+		if !keepOpen {
+			_ = conn.Close()
+		}
+	}()
 
 	decoder := json.NewDecoder(conn)
 	encoder := json.NewEncoder(conn)
+	logger := s.logger.With("request", s.requestSeq.Add(1))
+
+	// A bug handling one request must not take down the daemon
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("request panicked", "panic", r)
+		}
+	}()
 
 	var req Request
 	if err := decoder.Decode(&req); err != nil {
-		s.sendError(encoder, "Invalid request format")
+		// Clients probing whether the daemon is up connect and hang up
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		logger.Warn("invalid request", "error", err)
+		s.sendError(logger, encoder, "Invalid request format")
 		return
 	}
 
+	if !tokenMatches(s.token, req.Token) {
+		logger.Warn("rejected request with wrong token", "action", req.Action)
+		s.sendError(logger, encoder, errUnauthorized.Error())
+		return
+	}
+
+	logger.Debug("request received", "action", req.Action, "url", req.URL)
 	switch req.Action {
 	case "fetch":
-		s.handleFetch(encoder, req.URL)
+		s.handleFetch(logger, encoder, req)
 	case "ping":
-		s.sendResponse(encoder, Response{Success: true})
+		s.sendResponse(logger, encoder, Response{Success: true, Info: s.info()})
 	case "shutdown":
-		s.sendResponse(encoder, Response{Success: true})
+		logger.Info("shutdown requested")
+		s.sendResponse(logger, encoder, Response{Success: true})
 		go func() { _ = s.Stop() }()
+	case "handover":
+		// The connection stays open to report when draining is done
+		s.handOver(conn, encoder)
+		keepOpen = true
 	default:
-		s.sendError(encoder, "Unknown action: "+req.Action)
+		s.sendError(logger, encoder, "Unknown action: "+req.Action)
 	}
 }
 
 // handleFetch processes a fetch request.
-func (s *Server) handleFetch(encoder *json.Encoder, url string) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+func (s *Server) handleFetch(logger *slog.Logger, encoder *json.Encoder, req Request) {
+	s.beginRequest()
+	defer s.endRequest()
 
-	// Get browser context from manager
-	browserCtx, browserCancel, err := s.manager.GetContext(ctx)
-	if err != nil {
-		s.sendError(encoder, "Failed to get browser context: "+err.Error())
+	start := time.Now()
+	logger = logger.With("url", req.URL)
+
+	// Wait for a free slot so the browser is not flooded with tabs
+	queueTimeout := s.queueTimeout
+	if req.Options != nil && req.Options.QueueTimeout > 0 {
+		queueTimeout = req.Options.QueueTimeout
+	}
+	if err := s.queue.acquire(context.Background(), queueTimeout); err != nil {
+		s.failFetch(logger, encoder, Response{Error: err.Error()})
 		return
 	}
-	defer browserCancel()
+	defer s.queue.release()
+
+	// A daemon that took over waits for the old one to close its browser
+	<-s.ready
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 
-	// Use chromedp directly to fetch content
-	content, err := s.fetchContentWithContext(browserCtx, url)
+	resp, err := s.backend.Fetch(ctx, logger, req)
 	if err != nil {
-		s.sendError(encoder, "Failed to fetch content: "+err.Error())
+		s.failFetch(logger, encoder, Response{
+			Error:       "Failed to fetch content: " + err.Error(),
+			Unavailable: errors.Is(err, ErrBrowserUnavailable),
+		})
 		return
 	}
 
-	s.sendResponse(encoder, Response{
-		Success: true,
-		Content: content,
-	})
+	s.pagesServed.Add(1)
+	logger.Info("fetched page", "duration", time.Since(start).Round(time.Millisecond))
+	s.sendResponse(logger, encoder, resp)
+}
+
+// failFetch sends an error response and remembers it for status reports.
+func (s *Server) failFetch(logger *slog.Logger, encoder *json.Encoder, resp Response) {
+	s.errMu.Lock()
+	s.lastError = resp.Error
+	s.lastErrorAt = time.Now()
+	s.errMu.Unlock()
+
+	logger.Error("fetch failed", "error", resp.Error, "unavailable", resp.Unavailable)
+	s.sendResponse(logger, encoder, resp)
 }
 
 // sendResponse sends a successful response.
-func (s *Server) sendResponse(encoder *json.Encoder, resp Response) {
+func (s *Server) sendResponse(logger *slog.Logger, encoder *json.Encoder, resp Response) {
 	if err := encoder.Encode(resp); err != nil {
-		log.Printf("Error sending response: %v", err)
+		logger.Warn("failed to send response", "error", err)
 	}
 }
 
 // sendError sends an error response.
-func (s *Server) sendError(encoder *json.Encoder, errMsg string) {
-	s.sendResponse(encoder, Response{
+func (s *Server) sendError(logger *slog.Logger, encoder *json.Encoder, errMsg string) {
+	s.sendResponse(logger, encoder, Response{
 		Success: false,
 		Error:   errMsg,
 	})
@@ -190,8 +392,11 @@ func (s *Server) sendError(encoder *json.Encoder, errMsg string) {
 
 // IsDaemonRunning checks if the daemon is running by attempting to connect.
 func IsDaemonRunning() bool {
-	socketPath := filepath.Join(os.TempDir(), "essenz-daemon.sock")
-	conn, err := net.Dial("unix", socketPath)
+	endpoint, err := DefaultEndpoint()
+	if err != nil {
+		return false
+	}
+	conn, err := endpoint.dial(2 * time.Second)
 	if err != nil {
 		return false
 	}
@@ -200,48 +405,245 @@ func IsDaemonRunning() bool {
 }
 
 // fetchContentWithContext fetches content using an existing browser context.
-func (s *Server) fetchContentWithContext(ctx context.Context, url string) (string, error) {
+// The response also carries the readiness result and, if requested, the storage state.
+func fetchContentWithContext(ctx context.Context, logger *slog.Logger, req Request) (Response, error) {
+	url := req.URL
+	opts := req.Options
+	if opts == nil {
+		opts = &FetchOptions{}
+	}
+	if err := opts.Validate(); err != nil {
+		return Response{}, err
+	}
+
+	scroller, err := pageready.ParseScroll(opts.Scroll)
+	if err != nil {
+		return Response{}, err
+	}
+
 	// Set timeout for the operation
 	timeoutCtx, timeoutCancel := context.WithTimeout(ctx, 30*time.Second)
 	defer timeoutCancel()
 
 	// Use enhanced DOM readiness detection by default
 	checker := pageready.NewReadinessChecker().WithTimeout(5 * time.Second)
+	if opts.Readiness != nil {
+		checker = opts.Readiness
+	}
+
+	// Hash routes are rendered by a client-side router after the initial load
+	route := pageready.HashRoute(url)
+	if route != "" {
+		checker = checker.WithRoute(route)
+	}
+
+	// Restore a saved session before the first request
+	if opts.LoadState != nil {
+		if err := opts.LoadState.Apply(timeoutCtx); err != nil {
+			return Response{}, err
+		}
+	}
+
+	// Lay the page out for the requested screen from the first paint
+	if opts.Viewport != nil {
+		if err := opts.Viewport.Apply(timeoutCtx); err != nil {
+			return Response{}, err
+		}
+	}
+
+	if opts.Region != nil {
+		if err := opts.Region.Apply(timeoutCtx); err != nil {
+			return Response{}, err
+		}
+	}
+
+	if opts.Adblock {
+		list, err := adblock.Load(opts.AdblockLists)
+		if err != nil {
+			return Response{}, err
+		}
+		if err := list.Intercept(timeoutCtx); err != nil {
+			return Response{}, err
+		}
+	}
+
+	// Answer auth challenges after the ad blocker has enabled interception,
+	// which also continues the requests it pauses
+	if opts.Auth != nil {
+		if err := opts.Auth.Answer(timeoutCtx, url, opts.Adblock); err != nil {
+			return Response{}, err
+		}
+	}
+
+	var recorder *console.Recorder
+	if opts.CaptureConsole {
+		recorder = console.NewRecorder()
+		if err := recorder.Start(timeoutCtx); err != nil {
+			return Response{}, err
+		}
+	}
+
+	var harRecorder *har.Recorder
+	if opts.RecordHAR {
+		harRecorder = har.NewRecorder()
+		if err := harRecorder.Start(timeoutCtx); err != nil {
+			return Response{}, err
+		}
+	}
+
+	var downloads *download.Watcher
+	if opts.DownloadDir != "" {
+		downloads, err = download.Watch(timeoutCtx, opts.DownloadDir)
+		if err != nil {
+			return Response{}, err
+		}
+	}
 
 	// Fetch page content with DOM readiness
 	var htmlContent string
-	err := chromedp.Run(timeoutCtx,
-		chromedp.Navigate(url),
+	var document *network.Response
+	err = chromedp.Run(timeoutCtx,
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			if opts.UserAgent == "" {
+				return nil
+			}
+			return emulation.SetUserAgentOverride(opts.UserAgent).Do(ctx)
+		}),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			if len(opts.Headers) == 0 {
+				return nil
+			}
+			headers := make(network.Headers, len(opts.Headers))
+			for name, value := range opts.Headers {
+				headers[name] = value
+			}
+			if err := network.Enable().Do(ctx); err != nil {
+				return err
+			}
+			return network.SetExtraHTTPHeaders(headers).Do(ctx)
+		}),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			if route == "" {
+				return nil
+			}
+			_, err := page.AddScriptToEvaluateOnNewDocument(pageready.RouteHookScript).Do(ctx)
+			return err
+		}),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			// The page's own response carries its status and validators
+			var err error
+			document, err = chromedp.RunResponse(ctx, chromedp.Navigate(url))
+			return err
+		}),
 		chromedp.WaitReady("body"),
 	)
 	if err != nil {
-		return "", fmt.Errorf("failed to navigate to %s: %w", url, err)
+		// Navigating to a file aborts the page load and starts a download instead
+		if downloads != nil && downloads.Started() {
+			saved, err := downloads.Wait(timeoutCtx)
+			if err != nil {
+				return Response{}, err
+			}
+			return Response{Success: true, Download: saved}, nil
+		}
+		return Response{}, fmt.Errorf("failed to navigate to %s: %w", url, err)
 	}
 
 	// Apply DOM readiness detection
-	_, err = checker.WaitForReady(timeoutCtx, timeoutCtx)
+	readiness, err := checker.WaitForReady(timeoutCtx, timeoutCtx)
 	if err != nil {
 		// DOM readiness failed, but continue with basic content extraction
-		log.Printf("DOM readiness detection failed for %s: %v", url, err)
+		logger.Warn("DOM readiness detection failed", "error", err)
+	}
+
+	// Interact with the page before extraction
+	if opts.Actions != nil {
+		if err := opts.Actions.Run(timeoutCtx, timeoutCtx); err != nil {
+			return Response{}, err
+		}
+	}
+
+	// An action may have clicked a download link
+	var saved *download.Download
+	if downloads != nil && downloads.Started() {
+		saved, err = downloads.Wait(timeoutCtx)
+		if err != nil {
+			return Response{}, err
+		}
+	}
+
+	// Scroll to trigger lazy-loaded content
+	if scroller != nil {
+		if err := scroller.WithMaxHeight(opts.ScrollMaxHeight).Scroll(timeoutCtx, timeoutCtx); err != nil {
+			// Keep whatever has loaded so far
+			logger.Warn("scrolling failed", "error", err)
+		}
+	}
+
+	// Run user scripts to expand sections or dismiss overlays
+	if err := pageready.EvaluateScripts(timeoutCtx, opts.Eval); err != nil {
+		return Response{}, err
 	}
 
 	// Extract content after readiness
-	err = chromedp.Run(timeoutCtx,
-		chromedp.OuterHTML("html", &htmlContent),
-	)
+	extract := chromedp.OuterHTML("html", &htmlContent)
+	if opts.Output == OutputText {
+		extract = chromedp.Evaluate(`document.body ? document.body.innerText : ""`, &htmlContent)
+	}
+	err = chromedp.Run(timeoutCtx, extract)
 	if err != nil {
-		return "", fmt.Errorf("failed to extract content from %s: %w", url, err)
+		return Response{}, fmt.Errorf("failed to extract content from %s: %w", url, err)
+	}
+
+	resp := Response{
+		Success:   true,
+		Content:   htmlContent,
+		Readiness: readiness,
+		Download:  saved,
+		Status:    documentStatus(document),
+	}
+	if recorder != nil {
+		resp.Console = recorder.Messages()
+	}
+	if harRecorder != nil {
+		resp.HAR = harRecorder.HAR()
+	}
+	if opts.CaptureValidators {
+		resp.Validators = documentValidators(document)
+	}
+
+	// Capture the session after login steps so it can be reused
+	if opts.SaveState {
+		state, err := session.Capture(timeoutCtx)
+		if err != nil {
+			return Response{}, err
+		}
+		resp.State = state
 	}
 
-	return htmlContent, nil
+	return resp, nil
 }
 
 // StartDaemonIfNeeded starts the daemon if it's not already running.
 func StartDaemonIfNeeded() error {
+	startMu.Lock()
+	defer startMu.Unlock()
+
 	if IsDaemonRunning() {
 		return nil
 	}
 
 	server := NewServer()
-	return server.Start()
+	if err := server.Start(); err != nil {
+		// Another process may have started one first
+		if IsDaemonRunning() {
+			return nil
+		}
+		return err
+	}
+	return nil
 }
+
+// startMu serializes StartDaemonIfNeeded so concurrent fetches in one process
+// share a single daemon.
+var startMu sync.Mutex
@@ -0,0 +1,114 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// State is what a running daemon records about itself in its PID file.
+type State struct {
+	Name     string    `json:"name,omitempty"`
+	PID      int       `json:"pid"`
+	Endpoint string    `json:"endpoint"`
+	Started  time.Time `json:"started"`
+}
+
+// Status says whether a daemon is serving requests at an endpoint.
+type Status string
+
+// Daemon statuses reported by CheckStatus.
+const (
+	StatusRunning Status = "running" // Answers pings
+	StatusStale   Status = "stale"   // Left a socket or PID file behind but does not answer
+	StatusStopped Status = "stopped" // Nothing at the endpoint
+)
+
+// statePath returns where the daemon listening on the endpoint keeps its PID file.
+func (e Endpoint) statePath() string {
+	return e.sidecarPath(".pid")
+}
+
+// writeState records the current process as the daemon for the endpoint.
+func writeState(e Endpoint, started time.Time) error {
+	state := State{
+		Name:     DaemonName(),
+		PID:      os.Getpid(),
+		Endpoint: e.String(),
+		Started:  started,
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(e.statePath(), append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write PID file: %w", err)
+	}
+	return nil
+}
+
+// ReadState reads the PID file of the daemon at the endpoint.
+func ReadState(e Endpoint) (*State, error) {
+	data, err := os.ReadFile(e.statePath())
+	if err != nil {
+		return nil, err
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse PID file %s: %w", e.statePath(), err)
+	}
+	return &state, nil
+}
+
+// Report is the outcome of a status check.
+type Report struct {
+	Status Status `json:"status"`
+	State  *State `json:"state,omitempty"` // PID file contents, if any
+	Info   *Info  `json:"info,omitempty"`  // Details from a running daemon
+}
+
+// CheckStatus reports whether a daemon is serving at the endpoint, along with
+// its PID file and details if it is running. A daemon that crashed leaves its
+// socket and PID file behind, which is reported as StatusStale.
+func CheckStatus(e Endpoint) Report {
+	state, _ := ReadState(e)
+
+	client := NewDaemonClient().WithEndpoint(e)
+	if info, err := client.Info(); err == nil {
+		return Report{Status: StatusRunning, State: state, Info: info}
+	}
+
+	if state != nil || e.leftovers() {
+		return Report{Status: StatusStale, State: state}
+	}
+	return Report{Status: StatusStopped}
+}
+
+// StateAlive reports whether the process recorded in a PID file still exists.
+func StateAlive(state *State) bool {
+	if state == nil || state.PID <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(state.PID)
+	if err != nil {
+		return false
+	}
+	return processAlive(process)
+}
+
+// leftovers reports whether a socket file from an earlier daemon still exists.
+func (e Endpoint) leftovers() bool {
+	if e.Network != "unix" {
+		return false
+	}
+	_, err := os.Stat(e.Address)
+	return err == nil
+}
+
+// removeFiles deletes the socket, token, and PID file of the daemon at the endpoint.
+func (e Endpoint) removeFiles() {
+	e.cleanup()
+	_ = os.Remove(e.tokenPath())
+	_ = os.Remove(e.statePath())
+}
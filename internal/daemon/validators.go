@@ -0,0 +1,31 @@
+package daemon
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/jewell-lgtm/essenz/internal/revalidate"
+)
+
+// documentStatus returns the HTTP status of the page's own document, or 0
+// when the page was not loaded over HTTP.
+func documentStatus(document *network.Response) int {
+	if document == nil {
+		return 0
+	}
+	return int(document.Status)
+}
+
+// documentValidators returns the ETag and Last-Modified of the page's own
+// document, or nil when it had neither or was not a 200 response.
+func documentValidators(document *network.Response) *revalidate.Validators {
+	if document == nil || document.Status != http.StatusOK {
+		return nil
+	}
+	header := make(http.Header, len(document.Headers))
+	for name, value := range document.Headers {
+		header.Set(name, fmt.Sprint(value))
+	}
+	return revalidate.FromHeader(header)
+}
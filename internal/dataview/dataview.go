@@ -0,0 +1,149 @@
+// Package dataview renders JSON and XML responses, such as API examples and
+// data endpoints, as markdown: the document pretty-printed in a fenced code
+// block, or an outline of its structure.
+package dataview
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Kind is the format of a data document.
+type Kind string
+
+// Formats Detect recognises.
+const (
+	JSON Kind = "json"
+	XML  Kind = "xml"
+)
+
+// Detect reports whether content is a JSON or XML document rather than a
+// page, returning "" for anything else. XHTML is a page, and so is markup
+// that is not well-formed XML.
+func Detect(content string) Kind {
+	trimmed := strings.TrimSpace(content)
+	switch {
+	case trimmed == "":
+		return ""
+	case trimmed[0] == '{' || trimmed[0] == '[':
+		if json.Valid([]byte(trimmed)) {
+			return JSON
+		}
+	case trimmed[0] == '<':
+		root, hasDeclaration, err := xmlRoot(trimmed)
+		if err != nil || strings.EqualFold(root.Name.Local, "html") {
+			return ""
+		}
+		// Without a declaration, only namespaced documents and RSS are told
+		// apart from HTML fragments
+		if hasDeclaration || root.Name.Local == "rss" || hasNamespace(root) {
+			return XML
+		}
+	}
+	return ""
+}
+
+// Pretty returns a document indented in a fenced code block tagged with its
+// format. A document that cannot be indented is fenced as it is.
+func Pretty(kind Kind, content string) string {
+	content = strings.TrimSpace(content)
+	var pretty string
+	var err error
+	switch kind {
+	case JSON:
+		var out bytes.Buffer
+		err = json.Indent(&out, []byte(content), "", "  ")
+		pretty = out.String()
+	case XML:
+		pretty, err = indentXML(content)
+	}
+	if err != nil {
+		pretty = content
+	}
+	fence := codeFence(pretty)
+	return fence + string(kind) + "\n" + pretty + "\n" + fence + "\n"
+}
+
+// Outline returns a markdown outline of a document's structure: the keys and
+// value types of JSON, with arrays summarized by their items, or the element
+// tree of XML, with repeated elements given once with their count.
+func Outline(kind Kind, content string) (string, error) {
+	switch kind {
+	case JSON:
+		return outlineJSON(content)
+	case XML:
+		return outlineXML(content)
+	}
+	return "", errors.New("not a JSON or XML document")
+}
+
+// codeFence returns a run of backticks longer than any in text, and at least
+// three.
+func codeFence(text string) string {
+	longest, run := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+// xmlRoot returns the root element of a well-formed XML document and whether
+// it starts with an XML declaration.
+func xmlRoot(content string) (xml.StartElement, bool, error) {
+	decoder := newXMLDecoder(content)
+	var root *xml.StartElement
+	hasDeclaration := false
+	for {
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return xml.StartElement{}, false, err
+		}
+		switch t := token.(type) {
+		case xml.ProcInst:
+			if t.Target == "xml" && root == nil {
+				hasDeclaration = true
+			}
+		case xml.StartElement:
+			if root == nil {
+				start := t.Copy()
+				root = &start
+			}
+		}
+	}
+	if root == nil {
+		return xml.StartElement{}, false, errors.New("no root element")
+	}
+	return *root, hasDeclaration, nil
+}
+
+// newXMLDecoder returns a decoder for a document already transcoded to
+// UTF-8, whatever encoding its declaration names.
+func newXMLDecoder(content string) *xml.Decoder {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	return decoder
+}
+
+// hasNamespace reports whether an element declares an XML namespace.
+func hasNamespace(element xml.StartElement) bool {
+	for _, attr := range element.Attr {
+		if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+			return true
+		}
+	}
+	return false
+}
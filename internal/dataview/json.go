@@ -0,0 +1,165 @@
+package dataview
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// shape is the structure of the JSON values found in one place of a document,
+// such as one key of every item of an array, merged.
+type shape struct {
+	types  []string // Value types in the order first seen: object, array, string, ...
+	keys   []string // An object's keys in the order first seen
+	fields map[string]*shape
+	items  *shape // The shape of an array's items
+	arrays int    // How many arrays were merged here
+	most   int    // The most items any of them had
+}
+
+// outlineJSON returns the outline of a JSON document.
+func outlineJSON(content string) (string, error) {
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.UseNumber()
+	root := &shape{}
+	if err := root.read(decoder); err != nil {
+		return "", fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "JSON %s", root.describe())
+	if members := root.members(); members != nil {
+		out.WriteString(":\n\n")
+		members.writeFields(&out, 0)
+	} else {
+		out.WriteString(".\n")
+	}
+	return out.String(), nil
+}
+
+// read merges the next value from decoder into s.
+func (s *shape) read(decoder *json.Decoder) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	switch t := token.(type) {
+	case json.Delim:
+		if t == '{' {
+			s.addType("object")
+			if s.fields == nil {
+				s.fields = make(map[string]*shape)
+			}
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				name, _ := key.(string)
+				field, ok := s.fields[name]
+				if !ok {
+					field = &shape{}
+					s.fields[name] = field
+					s.keys = append(s.keys, name)
+				}
+				if err := field.read(decoder); err != nil {
+					return err
+				}
+			}
+		} else {
+			s.addType("array")
+			if s.items == nil {
+				s.items = &shape{}
+			}
+			count := 0
+			for decoder.More() {
+				count++
+				if err := s.items.read(decoder); err != nil {
+					return err
+				}
+			}
+			s.arrays++
+			s.most = max(s.most, count)
+		}
+		// The closing delimiter
+		_, err = decoder.Token()
+		return err
+	case string:
+		s.addType("string")
+	case json.Number:
+		s.addType("number")
+	case bool:
+		s.addType("boolean")
+	case nil:
+		s.addType("null")
+	}
+	return nil
+}
+
+// addType records that a value of the given type was found.
+func (s *shape) addType(name string) {
+	for _, seen := range s.types {
+		if seen == name {
+			return
+		}
+	}
+	s.types = append(s.types, name)
+}
+
+// describe names the types of the values found, such as "string or null" or
+// "array of 20 objects".
+func (s *shape) describe() string {
+	described := make([]string, 0, len(s.types))
+	for _, name := range s.types {
+		if name != "array" {
+			described = append(described, name)
+			continue
+		}
+		items := s.items.plural()
+		if s.most == 1 {
+			items = strings.Join(s.items.types, " or ")
+		}
+		switch {
+		case s.most == 0:
+			described = append(described, "empty array")
+		case s.arrays > 1:
+			described = append(described, fmt.Sprintf("array of up to %d %s", s.most, items))
+		default:
+			described = append(described, fmt.Sprintf("array of %d %s", s.most, items))
+		}
+	}
+	return strings.Join(described, " or ")
+}
+
+// plural names the types of an array's items in the plural.
+func (s *shape) plural() string {
+	names := make([]string, 0, len(s.types))
+	for _, name := range s.types {
+		names = append(names, name+"s")
+	}
+	return strings.Join(names, " or ")
+}
+
+// members returns the shape whose keys list under a value: its own when it is
+// an object, or its items' when it is an array of them.
+func (s *shape) members() *shape {
+	for ; s != nil; s = s.items {
+		if len(s.keys) > 0 {
+			return s
+		}
+	}
+	return nil
+}
+
+// writeFields writes a list item for each key of s, and under it the keys of
+// its value.
+func (s *shape) writeFields(out *strings.Builder, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, key := range s.keys {
+		field := s.fields[key]
+		fmt.Fprintf(out, "%s- `%s`: %s\n", indent, key, field.describe())
+		if members := field.members(); members != nil {
+			members.writeFields(out, depth+1)
+		}
+	}
+}
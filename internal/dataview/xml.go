@@ -0,0 +1,213 @@
+package dataview
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// element is the structure of the XML elements with one name under one
+// parent, merged.
+type element struct {
+	name     string
+	attrs    []string // Attribute names in the order first seen
+	children []*element
+	most     int  // The most times it appears under one parent
+	text     bool // Whether any of them hold text
+}
+
+// indentXML re-indents an XML document two spaces a level, keeping elements
+// that only hold text on one line and closing empty ones with "/>".
+func indentXML(content string) (string, error) {
+	decoder := newXMLDecoder(content)
+	var out strings.Builder
+	// Whether each open element has child elements, so its end tag goes on a
+	// line of its own
+	var open []bool
+	// Whether the last start tag is still waiting for its ">", in case the
+	// element is empty
+	pending := false
+	closeStart := func() {
+		if pending {
+			out.WriteString(">")
+			pending = false
+		}
+	}
+	newline := func() {
+		closeStart()
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString(strings.Repeat("  ", len(open)))
+	}
+	for {
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := token.(type) {
+		case xml.ProcInst:
+			newline()
+			fmt.Fprintf(&out, "<?%s %s?>", t.Target, strings.TrimSpace(string(t.Inst)))
+		case xml.Directive:
+			newline()
+			fmt.Fprintf(&out, "<!%s>", t)
+		case xml.Comment:
+			if len(open) > 0 {
+				open[len(open)-1] = true
+			}
+			newline()
+			fmt.Fprintf(&out, "<!--%s-->", t)
+		case xml.StartElement:
+			if len(open) > 0 {
+				open[len(open)-1] = true
+			}
+			newline()
+			out.WriteString("<" + qualifiedName(t.Name))
+			for _, attr := range t.Attr {
+				fmt.Fprintf(&out, ` %s="%s"`, qualifiedName(attr.Name), xmlEscaper.Replace(attr.Value))
+			}
+			pending = true
+			open = append(open, false)
+		case xml.CharData:
+			text := strings.TrimSpace(string(t))
+			if text == "" {
+				continue
+			}
+			if len(open) > 0 && open[len(open)-1] {
+				newline()
+			}
+			closeStart()
+			out.WriteString(xmlEscaper.Replace(text))
+		case xml.EndElement:
+			if len(open) == 0 {
+				return "", errors.New("unexpected end element")
+			}
+			hasChildren := open[len(open)-1]
+			open = open[:len(open)-1]
+			if pending {
+				out.WriteString("/>")
+				pending = false
+				continue
+			}
+			if hasChildren {
+				newline()
+			}
+			out.WriteString("</" + qualifiedName(t.Name) + ">")
+		}
+	}
+	return out.String(), nil
+}
+
+// outlineXML returns the outline of an XML document.
+func outlineXML(content string) (string, error) {
+	decoder := newXMLDecoder(content)
+	document := &element{}
+	// The open elements, with how many of each child they have held so far
+	type frame struct {
+		element *element
+		counts  map[string]int
+	}
+	stack := []frame{{element: document, counts: make(map[string]int)}}
+	for {
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid XML: %w", err)
+		}
+		top := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := qualifiedName(t.Name)
+			child := top.element.child(name)
+			top.counts[name]++
+			child.most = max(child.most, top.counts[name])
+			for _, attr := range t.Attr {
+				if attr.Name.Space != "xmlns" && attr.Name.Local != "xmlns" {
+					child.addAttr(qualifiedName(attr.Name))
+				}
+			}
+			stack = append(stack, frame{element: child, counts: make(map[string]int)})
+		case xml.CharData:
+			if strings.TrimSpace(string(t)) != "" {
+				top.element.text = true
+			}
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	if len(document.children) == 0 {
+		return "", errors.New("invalid XML: no root element")
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "XML document with root element `%s`:\n\n", document.children[0].name)
+	for _, root := range document.children {
+		root.write(&out, 0)
+	}
+	return out.String(), nil
+}
+
+// child returns the merged child element with the given name, adding it the
+// first time.
+func (e *element) child(name string) *element {
+	for _, child := range e.children {
+		if child.name == name {
+			return child
+		}
+	}
+	child := &element{name: name}
+	e.children = append(e.children, child)
+	return child
+}
+
+// addAttr records an attribute name the element was found with.
+func (e *element) addAttr(name string) {
+	for _, seen := range e.attrs {
+		if seen == name {
+			return
+		}
+	}
+	e.attrs = append(e.attrs, name)
+}
+
+// write writes a list item for the element, such as
+// "- `item` ×20 (attributes: id): text", and under it its children.
+func (e *element) write(out *strings.Builder, depth int) {
+	fmt.Fprintf(out, "%s- `%s`", strings.Repeat("  ", depth), e.name)
+	if e.most > 1 {
+		fmt.Fprintf(out, " ×%d", e.most)
+	}
+	if len(e.attrs) > 0 {
+		fmt.Fprintf(out, " (attributes: %s)", strings.Join(e.attrs, ", "))
+	}
+	if e.text {
+		out.WriteString(": text")
+	}
+	out.WriteString("\n")
+	for _, child := range e.children {
+		child.write(out, depth+1)
+	}
+}
+
+// qualifiedName returns a name as it appears in the document, with its
+// namespace prefix.
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// xmlEscaper escapes text for use in XML content or attribute values,
+// leaving line breaks as they are.
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
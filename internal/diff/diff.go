@@ -0,0 +1,270 @@
+// Package diff compares two versions of distilled content line by line and
+// formats the result as a unified diff.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op is the kind of an Edit.
+type Op int
+
+// Edit operations.
+const (
+	Equal  Op = iota // Line is in both versions
+	Delete           // Line is only in the old version
+	Insert           // Line is only in the new version
+)
+
+// Edit is one line of a diff.
+type Edit struct {
+	Op   Op
+	Text string
+}
+
+// Lines splits text into lines without their trailing newlines.
+func Lines(text string) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// Compute returns the shortest list of edits turning a into b.
+func Compute(a, b []string) []Edit {
+	// Common ends are cheap to match and keep the search below small
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]Edit, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		edits = append(edits, Edit{Equal, line})
+	}
+	edits = append(edits, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, Edit{Equal, line})
+	}
+	return edits
+}
+
+// Changed reports whether edits contain anything but equal lines.
+func Changed(edits []Edit) bool {
+	for _, edit := range edits {
+		if edit.Op != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+// myers finds a shortest edit script with Myers' O((N+M)D) algorithm.
+func myers(a, b []string) []Edit {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	var trace [][]int
+
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk the recorded frontiers back from the end, collecting edits in reverse
+	var edits []Edit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			edits = append(edits, Edit{Equal, a[x-1]})
+			x--
+			y--
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			edits = append(edits, Edit{Insert, b[y-1]})
+		} else {
+			edits = append(edits, Edit{Delete, a[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// Unified formats edits as a unified diff between the named versions, with
+// up to context unchanged lines around each change. It returns "" when
+// nothing changed.
+func Unified(fromName, toName string, edits []Edit, context int) string {
+	if !Changed(edits) {
+		return ""
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+
+	// Line numbers in each version before every edit
+	aLine := make([]int, len(edits)+1)
+	bLine := make([]int, len(edits)+1)
+	for i, edit := range edits {
+		aLine[i+1], bLine[i+1] = aLine[i], bLine[i]
+		if edit.Op != Insert {
+			aLine[i+1]++
+		}
+		if edit.Op != Delete {
+			bLine[i+1]++
+		}
+	}
+
+	for i := 0; i < len(edits); {
+		if edits[i].Op == Equal {
+			i++
+			continue
+		}
+
+		// Extend the hunk while the next change is close enough that their
+		// context would touch
+		start := max(0, i-context)
+		end := i
+		for j := i; j < len(edits); j++ {
+			if edits[j].Op == Equal {
+				continue
+			}
+			if j > end+2*context+1 {
+				break
+			}
+			end = j
+		}
+		end = min(len(edits), end+context+1)
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n",
+			hunkRange(aLine[start], aLine[end]-aLine[start]),
+			hunkRange(bLine[start], bLine[end]-bLine[start]))
+		for _, edit := range edits[start:end] {
+			switch edit.Op {
+			case Equal:
+				out.WriteString(" ")
+			case Delete:
+				out.WriteString("-")
+			case Insert:
+				out.WriteString("+")
+			}
+			out.WriteString(edit.Text)
+			out.WriteString("\n")
+		}
+		i = end
+	}
+	return out.String()
+}
+
+// hunkRange formats a 0-based start and a length the way unified diffs do.
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+// Blocks splits markdown into the units a content diff should compare: one
+// per paragraph, heading, list item or table row, with runs of whitespace
+// collapsed so that re-wrapped text compares equal. Lines inside code fences
+// are kept as they are, one unit each.
+func Blocks(markdown string) []string {
+	var blocks []string
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			blocks = append(blocks, strings.Join(paragraph, " "))
+			paragraph = nil
+		}
+	}
+
+	inFence := false
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			flush()
+			inFence = !inFence
+			blocks = append(blocks, trimmed)
+			continue
+		}
+		if inFence {
+			blocks = append(blocks, strings.TrimRight(line, " \t\r"))
+			continue
+		}
+		if trimmed == "" {
+			flush()
+			continue
+		}
+		if startsBlock(trimmed) {
+			flush()
+		}
+		paragraph = append(paragraph, strings.Join(strings.Fields(trimmed), " "))
+		if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "|") {
+			flush()
+		}
+	}
+	flush()
+	return blocks
+}
+
+// startsBlock reports whether a line begins a new unit rather than
+// continuing the paragraph above it.
+func startsBlock(line string) bool {
+	switch {
+	case strings.HasPrefix(line, "#"), strings.HasPrefix(line, "|"), strings.HasPrefix(line, ">"):
+		return true
+	case strings.HasPrefix(line, "- "), strings.HasPrefix(line, "* "), strings.HasPrefix(line, "+ "):
+		return true
+	}
+	// Ordered list items: digits followed by ". " or ") "
+	digits := 0
+	for digits < len(line) && line[digits] >= '0' && line[digits] <= '9' {
+		digits++
+	}
+	return digits > 0 && digits+1 < len(line) && (line[digits] == '.' || line[digits] == ')') && line[digits+1] == ' '
+}
@@ -0,0 +1,242 @@
+// Package download saves file downloads, such as PDFs or archives, that a
+// fetch runs into instead of an HTML page.
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/chromedp"
+)
+
+// Download describes a file saved to disk.
+type Download struct {
+	URL  string `json:"url"`
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// Watcher captures downloads started in a tab.
+type Watcher struct {
+	dir string
+
+	mu       sync.Mutex
+	started  bool
+	guid     string
+	url      string
+	filename string
+	done     chan error
+}
+
+// Watch makes the tab save downloads into dir and tracks the first one.
+// It must run before navigation.
+func Watch(chromeCtx context.Context, dir string) (*Watcher, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	w := &Watcher{dir: dir, done: make(chan error, 1)}
+	chromedp.ListenTarget(chromeCtx, func(ev interface{}) {
+		switch ev := ev.(type) {
+		case *browser.EventDownloadWillBegin:
+			w.begin(ev)
+		case *browser.EventDownloadProgress:
+			w.progress(ev)
+		}
+	})
+
+	// Files are saved under their GUID and renamed once complete, so a
+	// half-written file never has the final name
+	err := chromedp.Run(chromeCtx, browser.SetDownloadBehavior(browser.SetDownloadBehaviorBehaviorAllowAndName).
+		WithDownloadPath(dir).
+		WithEventsEnabled(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable downloads: %w", err)
+	}
+	return w, nil
+}
+
+// Started reports whether a download has begun.
+func (w *Watcher) Started() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.started
+}
+
+// Wait blocks until the download completes and returns where it was saved.
+func (w *Watcher) Wait(ctx context.Context) (*Download, error) {
+	select {
+	case err := <-w.done:
+		if err != nil {
+			return nil, err
+		}
+	case <-ctx.Done():
+		return nil, fmt.Errorf("download did not finish: %w", ctx.Err())
+	}
+
+	w.mu.Lock()
+	guid, rawURL, filename := w.guid, w.url, w.filename
+	w.mu.Unlock()
+
+	target, err := uniquePath(w.dir, filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Rename(filepath.Join(w.dir, guid), target); err != nil {
+		return nil, fmt.Errorf("failed to save download: %w", err)
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save download: %w", err)
+	}
+	return &Download{URL: rawURL, Path: target, Size: info.Size()}, nil
+}
+
+// begin records the first download in the tab.
+func (w *Watcher) begin(ev *browser.EventDownloadWillBegin) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.started {
+		return
+	}
+	w.started = true
+	w.guid = ev.GUID
+	w.url = ev.URL
+	w.filename = ev.SuggestedFilename
+	if w.filename == "" {
+		w.filename = filenameFromURL(ev.URL)
+	}
+}
+
+// progress signals completion or cancellation of the tracked download.
+func (w *Watcher) progress(ev *browser.EventDownloadProgress) {
+	w.mu.Lock()
+	tracked := w.started && ev.GUID == w.guid
+	w.mu.Unlock()
+	if !tracked {
+		return
+	}
+
+	switch ev.State {
+	case browser.DownloadProgressStateCompleted:
+		w.signal(nil)
+	case browser.DownloadProgressStateCanceled:
+		w.signal(errors.New("download was canceled"))
+	}
+}
+
+// signal reports the outcome once.
+func (w *Watcher) signal(err error) {
+	select {
+	case w.done <- err:
+	default:
+	}
+}
+
+// IsDownload reports whether an HTTP response is a file rather than a page:
+// it is marked as an attachment, or its content type is not text or markup.
+func IsDownload(resp *http.Response) bool {
+	if disposition, _, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil && disposition == "attachment" {
+		return true
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+xml"),
+		mediaType == "application/xml",
+		mediaType == "application/xhtml+xml",
+		mediaType == "application/json":
+		return false
+	}
+	return true
+}
+
+// Save writes an HTTP response body into dir, named after its
+// Content-Disposition filename or the URL path.
+func Save(resp *http.Response, dir string) (*Download, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	filename := ""
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		filename = params["filename"]
+	}
+	if filename == "" {
+		filename = filenameFromURL(resp.Request.URL.String())
+	}
+
+	target, err := uniquePath(dir, filename)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Create(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save download: %w", err)
+	}
+	size, copyErr := io.Copy(file, resp.Body)
+	closeErr := file.Close()
+	if copyErr != nil {
+		return nil, fmt.Errorf("failed to save download: %w", copyErr)
+	}
+	if closeErr != nil {
+		return nil, fmt.Errorf("failed to save download: %w", closeErr)
+	}
+
+	return &Download{URL: resp.Request.URL.String(), Path: target, Size: size}, nil
+}
+
+// filenameFromURL takes the last path segment of a URL as a file name.
+func filenameFromURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "download"
+	}
+	name := path.Base(parsed.Path)
+	if name == "." || name == "/" || name == "" {
+		return "download"
+	}
+	return name
+}
+
+// uniquePath returns a path in dir for filename that does not exist yet,
+// adding a numeric suffix if needed. Directory parts of the name are dropped
+// so a server cannot write outside dir.
+func uniquePath(dir, filename string) (string, error) {
+	name := filepath.Base(filepath.Clean("/" + filename))
+	if name == "/" || name == "." {
+		name = "download"
+	}
+
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	candidate := filepath.Join(dir, name)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(candidate); errors.Is(err, os.ErrNotExist) {
+			return candidate, nil
+		} else if err != nil {
+			return "", fmt.Errorf("failed to check download path: %w", err)
+		}
+		candidate = filepath.Join(dir, fmt.Sprintf("%s-%d%s", stem, i, ext))
+	}
+}
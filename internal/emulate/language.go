@@ -0,0 +1,83 @@
+package emulate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseLanguages splits a comma-separated list of language tags, most
+// preferred first, such as "fr-FR" or "fr-FR,en". It returns nil for an
+// empty value.
+func ParseLanguages(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	var languages []string
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+		if !validLocale(tag) {
+			return nil, fmt.Errorf("invalid language %q: expected language tags such as fr-FR or fr-FR,en", value)
+		}
+		languages = append(languages, tag)
+	}
+	return languages, nil
+}
+
+// AcceptLanguage returns an Accept-Language header value preferring the
+// languages in order, each followed by its base language the way browsers
+// send it, such as "fr-FR,fr;q=0.9,en;q=0.8". It returns an empty string for
+// no languages.
+func AcceptLanguage(languages []string) string {
+	listed := make(map[string]bool)
+	var ranges []string
+	add := func(tag string) {
+		if !listed[strings.ToLower(tag)] {
+			listed[strings.ToLower(tag)] = true
+			ranges = append(ranges, tag)
+		}
+	}
+	for i, tag := range languages {
+		add(tag)
+		// The base language follows the last of its regional variants, or
+		// keeps its own place when the user listed it
+		base, _, found := strings.Cut(tag, "-")
+		if found && !hasBase(languages[i+1:], base) {
+			add(base)
+		}
+	}
+
+	for i := range ranges {
+		if i > 0 {
+			// Weights fall by a tenth for each range, and bottom out there
+			weight := max(10-i, 1)
+			ranges[i] += ";q=0." + strconv.Itoa(weight)
+		}
+	}
+	return strings.Join(ranges, ",")
+}
+
+// languageScript returns a script that makes navigator.language and
+// navigator.languages report the languages, for pages that pick their
+// language in JavaScript rather than on the server.
+func languageScript(languages []string) string {
+	list, _ := json.Marshal(languages)
+	return `(() => {
+	const languages = Object.freeze(` + string(list) + `);
+	Object.defineProperty(Navigator.prototype, 'languages', {get: () => languages, configurable: true});
+	Object.defineProperty(Navigator.prototype, 'language', {get: () => languages[0], configurable: true});
+})();`
+}
+
+// hasBase reports whether any of tags is the base language or one of its
+// regional variants, ignoring case.
+func hasBase(tags []string, base string) bool {
+	for _, tag := range tags {
+		language, _, _ := strings.Cut(tag, "-")
+		if strings.EqualFold(language, base) {
+			return true
+		}
+	}
+	return false
+}
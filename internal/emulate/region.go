@@ -0,0 +1,170 @@
+package emulate
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	// Embedded zone data lets --timezone be validated on systems without tzdata
+	_ "time/tzdata"
+
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// Region describes where the browser appears to be: its language, time zone,
+// and position. Empty fields leave the browser's own setting in place.
+type Region struct {
+	Locale      string       `json:"locale,omitempty"`    // BCP 47 tag such as de-DE
+	Languages   []string     `json:"languages,omitempty"` // Preferred languages, most preferred first
+	Timezone    string       `json:"timezone,omitempty"`  // IANA zone such as Europe/Berlin
+	Geolocation *Geolocation `json:"geolocation,omitempty"`
+}
+
+// Geolocation is a position reported to the page's geolocation API.
+type Geolocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Accuracy  float64 `json:"accuracy"` // Meters
+}
+
+// defaultGeolocationAccuracy is used when a position is given without an accuracy.
+const defaultGeolocationAccuracy = 100
+
+// NewRegion validates and combines the locale, language, time zone, and
+// geolocation options. It returns nil when none are set.
+func NewRegion(locale, languages, timezone, geolocation string) (*Region, error) {
+	if locale == "" && languages == "" && timezone == "" && geolocation == "" {
+		return nil, nil
+	}
+
+	region := &Region{Locale: locale, Timezone: timezone}
+	if locale != "" && !validLocale(locale) {
+		return nil, fmt.Errorf("invalid locale %q: expected a language tag such as en-US", locale)
+	}
+	tags, err := ParseLanguages(languages)
+	if err != nil {
+		return nil, err
+	}
+	region.Languages = tags
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: expected an IANA zone such as Europe/Berlin", timezone)
+		}
+	}
+	if geolocation != "" {
+		position, err := ParseGeolocation(geolocation)
+		if err != nil {
+			return nil, err
+		}
+		region.Geolocation = position
+	}
+	return region, nil
+}
+
+// ParseGeolocation parses "latitude,longitude" with an optional third accuracy field in meters.
+func ParseGeolocation(value string) (*Geolocation, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("invalid geolocation %q: expected latitude,longitude[,accuracy]", value)
+	}
+
+	numbers := make([]float64, len(parts))
+	for i, part := range parts {
+		number, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid geolocation %q: expected latitude,longitude[,accuracy]", value)
+		}
+		numbers[i] = number
+	}
+
+	position := &Geolocation{
+		Latitude:  numbers[0],
+		Longitude: numbers[1],
+		Accuracy:  defaultGeolocationAccuracy,
+	}
+	if len(numbers) == 3 {
+		position.Accuracy = numbers[2]
+	}
+
+	if position.Latitude < -90 || position.Latitude > 90 {
+		return nil, fmt.Errorf("invalid geolocation latitude %v: must be between -90 and 90", position.Latitude)
+	}
+	if position.Longitude < -180 || position.Longitude > 180 {
+		return nil, fmt.Errorf("invalid geolocation longitude %v: must be between -180 and 180", position.Longitude)
+	}
+	return position, nil
+}
+
+// AcceptLanguage returns an Accept-Language header value preferring the
+// languages, or else the locale, or an empty string when neither is set.
+func (r *Region) AcceptLanguage() string {
+	switch {
+	case r == nil:
+		return ""
+	case len(r.Languages) > 0:
+		return AcceptLanguage(r.Languages)
+	case r.Locale != "":
+		return AcceptLanguage([]string{r.Locale})
+	}
+	return ""
+}
+
+// Apply sets the locale, languages, time zone, and geolocation on the tab. It
+// must run before navigation so scripts see them from the start.
+func (r *Region) Apply(chromeCtx context.Context) error {
+	return chromedp.Run(chromeCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		if r.Locale != "" {
+			if err := emulation.SetLocaleOverride().WithLocale(r.Locale).Do(ctx); err != nil {
+				return fmt.Errorf("failed to set locale: %w", err)
+			}
+		}
+
+		if len(r.Languages) > 0 {
+			if _, err := page.AddScriptToEvaluateOnNewDocument(languageScript(r.Languages)).Do(ctx); err != nil {
+				return fmt.Errorf("failed to set languages: %w", err)
+			}
+		}
+
+		if r.Timezone != "" {
+			if err := emulation.SetTimezoneOverride(r.Timezone).Do(ctx); err != nil {
+				return fmt.Errorf("failed to set timezone: %w", err)
+			}
+		}
+
+		if r.Geolocation != nil {
+			// Pages would otherwise get a permission prompt nobody can answer
+			err := browser.GrantPermissions([]browser.PermissionType{browser.PermissionTypeGeolocation}).Do(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to grant geolocation permission: %w", err)
+			}
+			err = emulation.SetGeolocationOverride().
+				WithLatitude(r.Geolocation.Latitude).
+				WithLongitude(r.Geolocation.Longitude).
+				WithAccuracy(r.Geolocation.Accuracy).
+				Do(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to set geolocation: %w", err)
+			}
+		}
+		return nil
+	}))
+}
+
+// validLocale reports whether a value looks like a BCP 47 language tag.
+func validLocale(locale string) bool {
+	for i, part := range strings.Split(locale, "-") {
+		if len(part) == 0 || len(part) > 8 || (i == 0 && len(part) < 2) {
+			return false
+		}
+		for _, r := range part {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+// Package emulate configures how the browser presents itself to a page:
+// viewport size, device characteristics, locale, and location.
+package emulate
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/chromedp"
+	"github.com/chromedp/chromedp/device"
+)
+
+// Viewport describes the screen a page is rendered on.
+type Viewport struct {
+	Width     int64   `json:"width"`
+	Height    int64   `json:"height"`
+	Scale     float64 `json:"scale,omitempty"`
+	Mobile    bool    `json:"mobile,omitempty"`
+	Touch     bool    `json:"touch,omitempty"`
+	Landscape bool    `json:"landscape,omitempty"`
+	UserAgent string  `json:"user_agent,omitempty"` // Set for device presets
+}
+
+// ParseViewport parses a WIDTHxHEIGHT size such as 1280x800.
+func ParseViewport(value string) (*Viewport, error) {
+	width, height, ok := strings.Cut(strings.ToLower(strings.TrimSpace(value)), "x")
+	if !ok {
+		return nil, fmt.Errorf("invalid viewport %q: expected WIDTHxHEIGHT, e.g. 1280x800", value)
+	}
+
+	w, err := strconv.ParseInt(width, 10, 64)
+	if err != nil || w <= 0 {
+		return nil, fmt.Errorf("invalid viewport width %q", width)
+	}
+	h, err := strconv.ParseInt(height, 10, 64)
+	if err != nil || h <= 0 {
+		return nil, fmt.Errorf("invalid viewport height %q", height)
+	}
+
+	return &Viewport{Width: w, Height: h, Scale: 1}, nil
+}
+
+// LookupDevice returns the viewport of a named device such as "iPhone 14".
+// Names match chromedp's device list, ignoring case.
+func LookupDevice(name string) (*Viewport, error) {
+	wanted := strings.ToLower(strings.TrimSpace(name))
+	for d := device.Reset + 1; d <= device.MotoG4landscape; d++ {
+		info := d.Device()
+		if strings.ToLower(info.Name) != wanted {
+			continue
+		}
+		return &Viewport{
+			Width:     info.Width,
+			Height:    info.Height,
+			Scale:     info.Scale,
+			Mobile:    info.Mobile,
+			Touch:     info.Touch,
+			Landscape: info.Landscape,
+			UserAgent: info.UserAgent,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown device %q", name)
+}
+
+// Apply sets the viewport on the tab. It must run before navigation so the
+// page lays itself out for this screen from the start.
+func (v *Viewport) Apply(chromeCtx context.Context) error {
+	return chromedp.Run(chromeCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		scale := v.Scale
+		if scale == 0 {
+			scale = 1
+		}
+
+		orientation := &emulation.ScreenOrientation{
+			Type:  emulation.OrientationTypePortraitPrimary,
+			Angle: 0,
+		}
+		if v.Landscape {
+			orientation = &emulation.ScreenOrientation{
+				Type:  emulation.OrientationTypeLandscapePrimary,
+				Angle: 90,
+			}
+		}
+
+		err := emulation.SetDeviceMetricsOverride(v.Width, v.Height, scale, v.Mobile).
+			WithScreenOrientation(orientation).
+			Do(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to set viewport: %w", err)
+		}
+
+		if err := emulation.SetTouchEmulationEnabled(v.Touch).Do(ctx); err != nil {
+			return fmt.Errorf("failed to set touch emulation: %w", err)
+		}
+
+		if v.UserAgent != "" {
+			if err := emulation.SetUserAgentOverride(v.UserAgent).Do(ctx); err != nil {
+				return fmt.Errorf("failed to set device user agent: %w", err)
+			}
+		}
+		return nil
+	}))
+}
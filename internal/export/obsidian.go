@@ -0,0 +1,335 @@
+// Package export files distilled pages into note-taking apps.
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jewell-lgtm/essenz/internal/extractor"
+	"github.com/jewell-lgtm/essenz/internal/metadata"
+)
+
+// DefaultAttachments is the folder under the vault images are saved to.
+const DefaultAttachments = "attachments"
+
+// maxImageSize caps one downloaded image.
+const maxImageSize = 50 << 20
+
+// downloadTimeout bounds fetching one image.
+const downloadTimeout = 30 * time.Second
+
+// Note is a distilled page to file in a vault.
+type Note struct {
+	Source   string             // URL or file the page came from
+	Markdown string             // Distilled content
+	Meta     *metadata.Metadata // Page metadata, if known
+	Images   []extractor.Image  // Images in the page's main content, which the markdown may leave out
+	Clipped  time.Time
+}
+
+// Obsidian writes notes into a folder of an Obsidian vault, saving the images
+// they show as attachments so the note reads the same offline.
+type Obsidian struct {
+	Vault       string // Folder the note is written to
+	Attachments string // Folder under Vault for images; DefaultAttachments if empty
+	client      *http.Client
+}
+
+// NewObsidian creates an exporter into the vault folder dir.
+func NewObsidian(dir string) *Obsidian {
+	return &Obsidian{
+		Vault:       dir,
+		Attachments: DefaultAttachments,
+		client:      &http.Client{Timeout: downloadTimeout},
+	}
+}
+
+// linkPattern matches markdown links and images with an inline target and
+// optional title.
+var linkPattern = regexp.MustCompile(`(!?)\[([^\]]*)\]\(<?([^)\s>]+)>?(\s+"[^"]*")?\)`)
+
+// Save writes note to the vault and returns the note's path. Images are
+// downloaded into the attachments folder and linked from there; images the
+// page shows but the markdown leaves out are added in an Images section.
+// Relative links are made absolute against the page. Neither notes nor
+// attachments overwrite existing files: a number is added to the name.
+func (o *Obsidian) Save(ctx context.Context, note Note) (string, error) {
+	if err := os.MkdirAll(o.Vault, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create vault folder: %w", err)
+	}
+	base := sourceBase(note.Source)
+	saved := make(map[string]string) // Image URL to its link in the note
+
+	body := linkPattern.ReplaceAllStringFunc(note.Markdown, func(match string) string {
+		parts := linkPattern.FindStringSubmatch(match)
+		target := resolve(base, parts[3])
+		if parts[1] == "" {
+			return "[" + parts[2] + "](" + target + parts[4] + ")"
+		}
+		link, ok := o.attach(ctx, target, saved)
+		if !ok {
+			return "![" + parts[2] + "](" + target + parts[4] + ")"
+		}
+		return "![" + parts[2] + "](" + link + ")"
+	})
+
+	var extra []string
+	for _, image := range note.Images {
+		target := resolve(base, image.URL)
+		if _, ok := saved[target]; ok {
+			continue
+		}
+		link, ok := o.attach(ctx, target, saved)
+		if !ok {
+			link = target
+		}
+		embed := "![" + image.Alt + "](" + link + ")"
+		if image.Caption != "" {
+			embed += "\n*" + image.Caption + "*"
+		}
+		extra = append(extra, embed)
+	}
+	if len(extra) > 0 {
+		body = strings.TrimRight(body, "\n") + "\n\n## Images\n\n" + strings.Join(extra, "\n\n")
+	}
+
+	title := noteTitle(note)
+	header, err := frontmatter(note, title)
+	if err != nil {
+		return "", err
+	}
+	return createUnique(o.Vault, fileName(title), ".md", []byte(header+strings.TrimRight(body, "\n")+"\n"))
+}
+
+// properties are the note properties Obsidian shows above the note.
+type properties struct {
+	Title       string   `yaml:"title"`
+	Source      string   `yaml:"source,omitempty"`
+	Author      string   `yaml:"author,omitempty"`
+	Published   string   `yaml:"published,omitempty"`
+	Description string   `yaml:"description,omitempty"`
+	Created     string   `yaml:"created"`
+	Tags        []string `yaml:"tags"`
+}
+
+// frontmatter formats a note's properties as YAML front matter.
+func frontmatter(note Note, title string) (string, error) {
+	props := properties{
+		Title:   title,
+		Source:  note.Source,
+		Created: note.Clipped.Format(time.DateOnly),
+		Tags:    []string{"clippings"},
+	}
+	if note.Meta != nil {
+		props.Author = note.Meta.Author
+		props.Published = note.Meta.Published
+		props.Description = note.Meta.Description
+	}
+	var data strings.Builder
+	encoder := yaml.NewEncoder(&data)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(props); err != nil {
+		return "", fmt.Errorf("failed to encode note properties: %w", err)
+	}
+	return "---\n" + data.String() + "---\n\n", nil
+}
+
+// attach saves the image at target into the attachments folder once, and
+// returns the note's link to it. Images that cannot be saved are logged and
+// reported as not ok, so the note links to them where they are.
+func (o *Obsidian) attach(ctx context.Context, target string, saved map[string]string) (string, bool) {
+	if link, ok := saved[target]; ok {
+		return link, link != target
+	}
+	saved[target] = target
+	if strings.HasPrefix(target, "data:") {
+		return target, false
+	}
+	data, ext, err := o.load(ctx, target)
+	if err != nil {
+		slog.Warn("could not save image", "image", target, "error", err)
+		return target, false
+	}
+
+	name := strings.TrimSuffix(imageName(target), path.Ext(imageName(target)))
+	if ext == "" {
+		ext = path.Ext(imageName(target))
+	}
+	dir := filepath.Join(o.Vault, o.Attachments)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		slog.Warn("could not save image", "image", target, "error", err)
+		return target, false
+	}
+	file, err := createUnique(dir, name, ext, data)
+	if err != nil {
+		slog.Warn("could not save image", "image", target, "error", err)
+		return target, false
+	}
+	link := (&url.URL{Path: filepath.ToSlash(filepath.Join(o.Attachments, filepath.Base(file)))}).EscapedPath()
+	saved[target] = link
+	return link, true
+}
+
+// load reads an image from a URL or a file, returning its data and, for
+// downloads, the file extension its content type calls for.
+func (o *Obsidian) load(ctx context.Context, target string) ([]byte, string, error) {
+	if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+		data, err := os.ReadFile(strings.TrimPrefix(target, "file://"))
+		return data, "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("HTTP %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxImageSize {
+		return nil, "", fmt.Errorf("image is larger than %d MiB", maxImageSize>>20)
+	}
+
+	var ext string
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil && path.Ext(imageName(target)) == "" {
+		switch exts, _ := mime.ExtensionsByType(mediaType); {
+		case mediaType == "image/jpeg":
+			ext = ".jpg"
+		case len(exts) > 0:
+			ext = exts[0]
+		}
+	}
+	return data, ext, nil
+}
+
+// sourceBase returns what relative links in a page resolve against: its URL,
+// or the folder of its file.
+func sourceBase(source string) *url.URL {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		base, err := url.Parse(source)
+		if err == nil {
+			return base
+		}
+		return nil
+	}
+	if source == "" || source == "-" {
+		return nil
+	}
+	abs, err := filepath.Abs(source)
+	if err != nil {
+		return nil
+	}
+	return &url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}
+}
+
+// resolve makes a link absolute against base. Fragments, other schemes and
+// links without a base are kept as they are.
+func resolve(base *url.URL, link string) string {
+	if base == nil || strings.HasPrefix(link, "#") {
+		return link
+	}
+	ref, err := url.Parse(link)
+	if err != nil || ref.Scheme != "" {
+		return link
+	}
+	resolved := base.ResolveReference(ref)
+	if resolved.Scheme == "file" {
+		return resolved.Path
+	}
+	return resolved.String()
+}
+
+// noteTitle picks a note's title: the page's title, its first heading, or
+// where it came from.
+func noteTitle(note Note) string {
+	if note.Meta != nil && note.Meta.Title != "" {
+		return note.Meta.Title
+	}
+	for _, line := range strings.Split(note.Markdown, "\n") {
+		if strings.HasPrefix(line, "#") {
+			if title := strings.TrimSpace(strings.TrimLeft(line, "#")); title != "" {
+				return title
+			}
+		}
+	}
+	if note.Source != "" && note.Source != "-" {
+		return note.Source
+	}
+	return "Untitled"
+}
+
+// unsafeName matches characters Obsidian or common filesystems do not allow
+// in file names.
+var unsafeName = regexp.MustCompile(`[\\/:*?"<>|#^\[\]\x00-\x1f]+`)
+
+// fileName turns a title into a file name without an extension.
+func fileName(title string) string {
+	name := strings.Join(strings.Fields(unsafeName.ReplaceAllString(title, " ")), " ")
+	name = strings.Trim(name, " .")
+	if runes := []rune(name); len(runes) > 100 {
+		name = strings.TrimSpace(string(runes[:100]))
+	}
+	if name == "" {
+		return "Untitled"
+	}
+	return name
+}
+
+// imageName returns a file name for an image from its URL or path.
+func imageName(target string) string {
+	name := target
+	if u, err := url.Parse(target); err == nil && u.Path != "" {
+		name = u.Path
+	}
+	name = fileName(path.Base(name))
+	if name == "Untitled" {
+		return "image"
+	}
+	return name
+}
+
+// createUnique writes data to dir/name+ext, or to "name 1"+ext and so on when
+// that file exists, and returns the path it wrote.
+func createUnique(dir, name, ext string, data []byte) (string, error) {
+	for i := 0; ; i++ {
+		candidate := name
+		if i > 0 {
+			candidate += " " + strconv.Itoa(i)
+		}
+		file := filepath.Join(dir, candidate+ext)
+		f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", file, err)
+		}
+		if _, err := f.Write(data); err != nil {
+			_ = f.Close()
+			return "", fmt.Errorf("failed to write %s: %w", file, err)
+		}
+		return file, f.Close()
+	}
+}
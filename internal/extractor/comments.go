@@ -0,0 +1,184 @@
+package extractor
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Comment is a single reader comment extracted from a discussion section.
+type Comment struct {
+	Author    string
+	Timestamp string
+	Body      string
+}
+
+// commentContainerIDs are class/ID tokens that mark a whole discussion section.
+var commentContainerIDs = []string{
+	"comments", "comment-list", "commentlist", "comment-section", "comments-section",
+	"comments-area", "comment-thread", "disqus_thread", "discussion",
+}
+
+// commentItemIDs are class tokens that mark a single comment.
+var commentItemIDs = []string{"comment", "comment-item", "comment-entry", "comment-wrapper", "reply"}
+
+// ExtractComments finds comment sections in the document and returns their comments in order.
+func (e *Extractor) ExtractComments(doc *html.Node) []Comment {
+	var comments []Comment
+	seen := make(map[*html.Node]bool)
+
+	e.walkNodes(doc, func(n *html.Node) {
+		if n.Type != html.ElementNode || !hasToken(n, commentContainerIDs) {
+			return
+		}
+		e.walkNodes(n, func(item *html.Node) {
+			if seen[item] || item == n || !isCommentItem(item) {
+				return
+			}
+			seen[item] = true
+			if comment, ok := e.extractComment(item); ok {
+				comments = append(comments, comment)
+			}
+		})
+	})
+
+	return comments
+}
+
+// CommentsToMarkdown renders comments as a "## Comments" section.
+func CommentsToMarkdown(comments []Comment) string {
+	if len(comments) == 0 {
+		return ""
+	}
+
+	var result strings.Builder
+	result.WriteString("## Comments\n\n")
+	for _, comment := range comments {
+		author := comment.Author
+		if author == "" {
+			author = "Anonymous"
+		}
+		result.WriteString("**" + author + "**")
+		if comment.Timestamp != "" {
+			result.WriteString(" — " + comment.Timestamp)
+		}
+		result.WriteString("\n\n")
+		result.WriteString(comment.Body)
+		result.WriteString("\n\n")
+	}
+	return strings.TrimSpace(result.String())
+}
+
+// extractComment reads author, timestamp and body from a comment element.
+func (e *Extractor) extractComment(n *html.Node) (Comment, bool) {
+	var comment Comment
+	var bodyNode *html.Node
+
+	e.walkCommentOwn(n, func(child *html.Node) {
+		if child.Type != html.ElementNode {
+			return
+		}
+		switch {
+		case comment.Timestamp == "" && child.Data == "time":
+			comment.Timestamp = attr(child, "datetime")
+			if comment.Timestamp == "" {
+				comment.Timestamp = collapseSpace(e.getTextContent(child))
+			}
+		case comment.Author == "" && isAuthorNode(child):
+			comment.Author = collapseSpace(e.getTextContent(child))
+		case comment.Timestamp == "" && hasToken(child, []string{"comment-date", "comment-time", "date", "timestamp", "published"}):
+			comment.Timestamp = collapseSpace(e.getTextContent(child))
+		case bodyNode == nil && (attr(child, "itemprop") == "text" ||
+			hasToken(child, []string{"comment-body", "comment-content", "comment-text", "comment-message"})):
+			bodyNode = child
+		}
+	})
+
+	if bodyNode != nil {
+		comment.Body = collapseSpace(e.ownText(bodyNode, nil))
+	} else {
+		comment.Body = collapseSpace(e.ownText(n, func(child *html.Node) bool {
+			return child.Data == "time" || isAuthorNode(child) ||
+				hasToken(child, []string{"comment-date", "comment-time", "date", "timestamp", "reply-link", "comment-reply-link", "comment-meta", "comment-actions"})
+		}))
+	}
+
+	return comment, comment.Body != ""
+}
+
+// walkCommentOwn visits descendants of a comment without entering nested replies.
+func (e *Extractor) walkCommentOwn(n *html.Node, fn func(*html.Node)) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if isCommentItem(child) {
+			continue
+		}
+		fn(child)
+		e.walkCommentOwn(child, fn)
+	}
+}
+
+// ownText returns text beneath n, skipping nested comments and nodes matching skip.
+func (e *Extractor) ownText(n *html.Node, skip func(*html.Node) bool) string {
+	var parts []string
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		switch {
+		case child.Type == html.TextNode:
+			parts = append(parts, child.Data)
+		case child.Type != html.ElementNode, isCommentItem(child):
+			continue
+		case child.Data == "script" || child.Data == "style" || child.Data == "button" || child.Data == "form":
+			continue
+		case skip != nil && skip(child):
+			continue
+		default:
+			parts = append(parts, e.ownText(child, skip))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// isCommentItem reports whether n is a single comment rather than a section.
+func isCommentItem(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	if strings.Contains(strings.ToLower(attr(n, "itemtype")), "schema.org/comment") {
+		return true
+	}
+	return hasToken(n, commentItemIDs) && !hasToken(n, commentContainerIDs)
+}
+
+// isAuthorNode reports whether n holds a comment author name.
+func isAuthorNode(n *html.Node) bool {
+	return attr(n, "itemprop") == "author" || attr(n, "rel") == "author" ||
+		hasToken(n, []string{"comment-author", "author", "fn", "username", "user-name", "commenter"})
+}
+
+// hasToken reports whether any class token or the ID of n equals one of tokens.
+func hasToken(n *html.Node, tokens []string) bool {
+	values := strings.Fields(strings.ToLower(attr(n, "class")))
+	if id := strings.ToLower(attr(n, "id")); id != "" {
+		values = append(values, id)
+	}
+	for _, value := range values {
+		for _, token := range tokens {
+			if value == token {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func collapseSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -6,14 +6,20 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/jewell-lgtm/essenz/internal/filter"
 	"golang.org/x/net/html"
 )
 
+// boilerplateIndicators are class/ID fragments that mark non-content elements.
+var boilerplateIndicators = []string{"nav", "menu", "sidebar", "footer", "header", "ad", "social", "comment"}
+
 // Extractor handles content extraction from HTML documents.
 type Extractor struct {
 	// Configuration options
 	minContentLength   int
 	preserveFormatting bool
+	negativeKeywords   []string
+	withComments       bool
 }
 
 // New creates a new content extractor with default settings.
@@ -21,9 +27,16 @@ func New() *Extractor {
 	return &Extractor{
 		minContentLength:   100,
 		preserveFormatting: true,
+		negativeKeywords:   boilerplateIndicators,
 	}
 }
 
+// WithComments appends the page's reader comments as a separate section.
+func (e *Extractor) WithComments(include bool) *Extractor {
+	e.withComments = include
+	return e
+}
+
 // ExtractContent extracts the main content from HTML and converts it to markdown.
 func (e *Extractor) ExtractContent(htmlContent string) (string, error) {
 	// Parse HTML
@@ -32,26 +45,66 @@ func (e *Extractor) ExtractContent(htmlContent string) (string, error) {
 		return "", fmt.Errorf("failed to parse HTML: %w", err)
 	}
 
-	// Find the main content
-	contentNode := e.findMainContent(doc)
-	if contentNode == nil {
-		// Fallback: use the body or entire document
-		if bodyNode := e.findNode(doc, "body"); bodyNode != nil {
-			contentNode = bodyNode
-		} else {
-			contentNode = doc
-		}
-	}
-
 	// Extract text and convert to markdown
-	markdown := e.nodeToMarkdown(contentNode)
+	markdown := e.nodeToMarkdown(e.contentNode(doc))
 
 	// Clean up the output
 	markdown = e.cleanMarkdown(markdown)
 
+	// Comment sections are stripped from the article; add them back separately if requested
+	if e.withComments {
+		if comments := CommentsToMarkdown(e.ExtractComments(doc)); comments != "" {
+			markdown += "\n\n" + comments
+		}
+	}
+
 	return markdown, nil
 }
 
+// ContentLinks returns the href of every link in the main content, in
+// document order, leaving out links in navigation and other boilerplate the
+// reader view drops. The hrefs are as written and may be relative.
+func (e *Extractor) ContentLinks(htmlContent string) ([]string, error) {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	var links []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && e.shouldSkipElement(n) {
+			return
+		}
+		if n.Type == html.ElementNode && n.Data == "a" {
+			if href := strings.TrimSpace(attr(n, "href")); href != "" {
+				links = append(links, href)
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(e.contentNode(doc))
+	return links, nil
+}
+
+// contentNode finds the main content of a parsed document, falling back to
+// the body or the whole document.
+func (e *Extractor) contentNode(doc *html.Node) *html.Node {
+	// Extend the boilerplate indicators with the keyword pack for the page language
+	e.negativeKeywords = append(boilerplateIndicators[:len(boilerplateIndicators):len(boilerplateIndicators)],
+		filter.BoilerplateKeywords(e.detectLanguage(doc))...)
+
+	if contentNode := e.findMainContent(doc); contentNode != nil {
+		return contentNode
+	}
+	if bodyNode := e.findNode(doc, "body"); bodyNode != nil {
+		return bodyNode
+	}
+	return doc
+}
+
 // findMainContent attempts to identify the main content area of the page.
 func (e *Extractor) findMainContent(n *html.Node) *html.Node {
 	// Look for semantic HTML5 elements first
@@ -125,7 +178,7 @@ func (e *Extractor) scoreNode(n *html.Node, selectors []string) int {
 			}
 
 			// Negative scores for non-content elements
-			if containsAny(value, []string{"nav", "menu", "sidebar", "footer", "header", "ad", "social", "comment"}) {
+			if containsAny(value, e.negativeKeywords) {
 				score -= 25
 			}
 		}
@@ -193,7 +246,7 @@ func (e *Extractor) shouldSkipElement(n *html.Node) bool {
 	for _, attr := range n.Attr {
 		if attr.Key == "class" || attr.Key == "id" {
 			value := strings.ToLower(attr.Val)
-			if containsAny(value, []string{"nav", "menu", "sidebar", "footer", "header", "ad", "social", "comment"}) {
+			if containsAny(value, e.negativeKeywords) {
 				return true
 			}
 		}
@@ -270,6 +323,17 @@ func (e *Extractor) writeClosingTag(n *html.Node, result *strings.Builder) {
 
 // Helper functions
 
+func (e *Extractor) detectLanguage(doc *html.Node) string {
+	if htmlNode := e.findNode(doc, "html"); htmlNode != nil {
+		for _, attr := range htmlNode.Attr {
+			if attr.Key == "lang" || attr.Key == "xml:lang" {
+				return attr.Val
+			}
+		}
+	}
+	return ""
+}
+
 func (e *Extractor) findNode(n *html.Node, tagName string) *html.Node {
 	if n.Type == html.ElementNode && n.Data == tagName {
 		return n
@@ -0,0 +1,126 @@
+package extractor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Image is an image in the main content.
+type Image struct {
+	URL     string `json:"url"`
+	Alt     string `json:"alt"`
+	Width   int    `json:"width,omitempty"`
+	Height  int    `json:"height,omitempty"`
+	Caption string `json:"caption,omitempty"`
+}
+
+// lazySourceAttrs hold the real source of lazily loaded images, whose src is
+// often a placeholder.
+var lazySourceAttrs = []string{"data-src", "data-lazy-src", "data-original", "data-url"}
+
+// ContentImages returns the images in the main content, in document order
+// and each once. Image URLs are as written and may be relative. Tracking
+// pixels are left out.
+func (e *Extractor) ContentImages(htmlContent string) ([]Image, error) {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	var images []Image
+	seen := make(map[string]bool)
+	var walk func(n *html.Node, caption string)
+	walk = func(n *html.Node, caption string) {
+		if n.Type == html.ElementNode && e.shouldSkipElement(n) {
+			return
+		}
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "figure":
+				caption = e.figureCaption(n)
+			case "img":
+				image, ok := e.contentImage(n, caption)
+				if ok && !seen[image.URL] {
+					seen[image.URL] = true
+					images = append(images, image)
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child, caption)
+		}
+	}
+	walk(e.contentNode(doc), "")
+	return images, nil
+}
+
+// contentImage reads an <img>, with the caption of the figure it is in.
+func (e *Extractor) contentImage(n *html.Node, caption string) (Image, bool) {
+	image := Image{
+		URL:     imageSource(n),
+		Alt:     collapseSpace(attr(n, "alt")),
+		Width:   dimension(attr(n, "width")),
+		Height:  dimension(attr(n, "height")),
+		Caption: caption,
+	}
+	if image.Caption == "" {
+		image.Caption = collapseSpace(attr(n, "title"))
+	}
+	if image.URL == "" || (image.Width == 1 && image.Height == 1) {
+		return Image{}, false
+	}
+	return image, true
+}
+
+// imageSource picks an image's real URL: src, unless it is missing or an
+// inline placeholder, then a lazy-loading attribute, then the widest srcset
+// candidate.
+func imageSource(n *html.Node) string {
+	if src := strings.TrimSpace(attr(n, "src")); src != "" && !strings.HasPrefix(src, "data:") {
+		return src
+	}
+	for _, key := range lazySourceAttrs {
+		if src := strings.TrimSpace(attr(n, key)); src != "" {
+			return src
+		}
+	}
+
+	best, bestWidth := "", -1
+	for _, candidate := range strings.Split(attr(n, "srcset"), ",") {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 {
+			continue
+		}
+		width := 0
+		if len(fields) > 1 {
+			width, _ = strconv.Atoi(strings.TrimRight(fields[1], "wx"))
+		}
+		if width > bestWidth {
+			best, bestWidth = fields[0], width
+		}
+	}
+	return best
+}
+
+// figureCaption returns the text of a figure's <figcaption>, if it has one.
+func (e *Extractor) figureCaption(figure *html.Node) string {
+	var caption string
+	e.walkNodes(figure, func(n *html.Node) {
+		if caption == "" && n.Type == html.ElementNode && n.Data == "figcaption" {
+			caption = collapseSpace(e.getTextContent(n))
+		}
+	})
+	return caption
+}
+
+// dimension reads a width or height attribute such as "640" or "640px".
+func dimension(value string) int {
+	size, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(value), "px"))
+	if err != nil || size < 0 {
+		return 0
+	}
+	return size
+}
@@ -0,0 +1,97 @@
+package extractor
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// wordsPerMinute is the reading speed behind Stats.ReadingMinutes, a common
+// estimate for adults reading prose on screen.
+const wordsPerMinute = 230
+
+// Stats describes the size and shape of a page's main content.
+type Stats struct {
+	Words          int `json:"words"`
+	ReadingMinutes int `json:"reading_minutes"`
+	Headings       int `json:"headings"`
+	Paragraphs     int `json:"paragraphs"`
+	Links          int `json:"links"`
+	Images         int `json:"images"`
+	SourceWords    int `json:"source_words"` // Words of visible text on the whole page
+}
+
+// Kept returns the share of the page's words that made it into the main
+// content, between 0 and 1.
+func (s Stats) Kept() float64 {
+	if s.SourceWords == 0 {
+		return 0
+	}
+	return float64(s.Words) / float64(s.SourceWords)
+}
+
+// ContentStats counts the words, headings, paragraphs, links and images in
+// the main content, as the reader view sees it, and the words on the whole
+// page for comparison. Images are counted as ContentImages lists them.
+func (e *Extractor) ContentStats(htmlContent string) (Stats, error) {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return Stats{}, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	var stats Stats
+	var page func(n *html.Node)
+	page = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "head", "script", "style", "noscript", "template":
+				return
+			}
+		}
+		if n.Type == html.TextNode {
+			stats.SourceWords += len(strings.Fields(n.Data))
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			page(child)
+		}
+	}
+	page(doc)
+
+	images := make(map[string]bool)
+	var content func(n *html.Node)
+	content = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			stats.Words += len(strings.Fields(n.Data))
+		case html.ElementNode:
+			if e.shouldSkipElement(n) {
+				return
+			}
+			switch n.Data {
+			case "h1", "h2", "h3", "h4", "h5", "h6":
+				stats.Headings++
+			case "p":
+				if e.hasTextContent(n) {
+					stats.Paragraphs++
+				}
+			case "a":
+				if strings.TrimSpace(attr(n, "href")) != "" {
+					stats.Links++
+				}
+			case "img":
+				if image, ok := e.contentImage(n, ""); ok && !images[image.URL] {
+					images[image.URL] = true
+					stats.Images++
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			content(child)
+		}
+	}
+	content(e.contentNode(doc))
+
+	stats.ReadingMinutes = (stats.Words + wordsPerMinute - 1) / wordsPerMinute
+	return stats, nil
+}
@@ -0,0 +1,184 @@
+package extractor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// maxSpan caps colspan and rowspan so a malformed table cannot blow up the grid.
+const maxSpan = 100
+
+// Table is a data table from the main content, with spanned cells repeated
+// so every row has one value per column.
+type Table struct {
+	Caption string     `json:"caption,omitempty"`
+	Header  []string   `json:"header,omitempty"`
+	Rows    [][]string `json:"rows"`
+}
+
+// ContentTables returns the data tables in the main content, in document
+// order. Layout tables - those marked presentational, those holding other
+// tables, and those with fewer than two rows or columns - are left out.
+func (e *Extractor) ContentTables(htmlContent string) ([]Table, error) {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	var tables []Table
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && e.shouldSkipElement(n) {
+			return
+		}
+		if n.Type == html.ElementNode && n.Data == "table" {
+			if table, ok := e.dataTable(n); ok {
+				tables = append(tables, table)
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(e.contentNode(doc))
+	return tables, nil
+}
+
+// dataTable reads a table element, reporting false for layout tables.
+func (e *Extractor) dataTable(n *html.Node) (Table, bool) {
+	switch strings.ToLower(attr(n, "role")) {
+	case "presentation", "none":
+		return Table{}, false
+	}
+
+	var table Table
+	var rows []*html.Node
+	headerRows := 0
+	nested := false
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode {
+			continue
+		}
+		switch child.Data {
+		case "caption":
+			table.Caption = collapseSpace(e.getTextContent(child))
+		case "thead", "tbody", "tfoot":
+			for row := child.FirstChild; row != nil; row = row.NextSibling {
+				if row.Type == html.ElementNode && row.Data == "tr" {
+					rows = append(rows, row)
+					if child.Data == "thead" {
+						headerRows++
+					}
+				}
+			}
+		case "tr":
+			rows = append(rows, child)
+		}
+	}
+	e.walkNodes(n, func(node *html.Node) {
+		if node != n && node.Type == html.ElementNode && node.Data == "table" {
+			nested = true
+		}
+	})
+
+	grid := e.spanGrid(rows)
+	// Without a <thead>, a first row made only of <th> cells is the header
+	if headerRows == 0 && len(rows) > 0 && allHeaderCells(rows[0]) {
+		headerRows = 1
+	}
+	if headerRows > 0 && len(grid) > 0 {
+		table.Header = grid[headerRows-1]
+		grid = grid[headerRows:]
+	}
+	table.Rows = grid
+
+	columns := len(table.Header)
+	for _, row := range table.Rows {
+		columns = max(columns, len(row))
+	}
+	if nested || columns < 2 || len(table.Rows)+min(headerRows, 1) < 2 {
+		return Table{}, false
+	}
+	return table, true
+}
+
+// spanGrid lays rows out as a grid, copying cells that span several columns
+// or rows into each position they cover, and pads rows to the same width.
+func (e *Extractor) spanGrid(rows []*html.Node) [][]string {
+	grid := make([][]string, len(rows))
+	for r, row := range rows {
+		col := 0
+		for cell := row.FirstChild; cell != nil; cell = cell.NextSibling {
+			if cell.Type != html.ElementNode || (cell.Data != "td" && cell.Data != "th") {
+				continue
+			}
+			text := collapseSpace(e.getTextContent(cell))
+			colspan := spanAttr(cell, "colspan")
+			rowspan := spanAttr(cell, "rowspan")
+			// Skip positions taken by cells spanning down from earlier rows
+			for col < len(grid[r]) && grid[r][col] != "\x00" {
+				col++
+			}
+			for dr := 0; dr < rowspan && r+dr < len(rows); dr++ {
+				for dc := 0; dc < colspan; dc++ {
+					setCell(grid, r+dr, col+dc, text)
+				}
+			}
+			col += colspan
+		}
+	}
+
+	width := 0
+	for _, row := range grid {
+		width = max(width, len(row))
+	}
+	for r := range grid {
+		for c := range grid[r] {
+			if grid[r][c] == "\x00" {
+				grid[r][c] = ""
+			}
+		}
+		for len(grid[r]) < width {
+			grid[r] = append(grid[r], "")
+		}
+	}
+	return grid
+}
+
+// setCell fills a grid position, growing the row with placeholders as needed.
+// A position already filled by a cell spanning from above is kept.
+func setCell(grid [][]string, r, c int, text string) {
+	for len(grid[r]) <= c {
+		grid[r] = append(grid[r], "\x00")
+	}
+	if grid[r][c] == "\x00" {
+		grid[r][c] = text
+	}
+}
+
+// spanAttr reads a colspan or rowspan, defaulting to 1.
+func spanAttr(n *html.Node, key string) int {
+	span, err := strconv.Atoi(strings.TrimSpace(attr(n, key)))
+	if err != nil || span < 1 {
+		return 1
+	}
+	return min(span, maxSpan)
+}
+
+// allHeaderCells reports whether every cell in a row is a <th>.
+func allHeaderCells(row *html.Node) bool {
+	cells := 0
+	for cell := row.FirstChild; cell != nil; cell = cell.NextSibling {
+		if cell.Type != html.ElementNode {
+			continue
+		}
+		if cell.Data != "th" {
+			return false
+		}
+		cells++
+	}
+	return cells > 0
+}
@@ -0,0 +1,296 @@
+// Package feed parses RSS, Atom and JSON Feed documents into a common list
+// of entries.
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Feed is a parsed feed of any supported format.
+type Feed struct {
+	Title string
+	Items []Item
+}
+
+// Item is one entry of a feed.
+type Item struct {
+	Title     string
+	Link      string    // The entry's page; may be empty
+	Published time.Time // Zero when the feed gives no date
+	Content   string    // HTML or text the feed carries for the entry, if any
+}
+
+// Parse reads an RSS 2.0, RSS 1.0, Atom or JSON Feed document.
+func Parse(data []byte) (*Feed, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		return parseJSON(trimmed)
+	}
+
+	var root struct{ XMLName xml.Name }
+	if err := xml.Unmarshal(trimmed, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+	switch root.XMLName.Local {
+	case "rss":
+		var doc struct {
+			Channel struct {
+				Title string    `xml:"title"`
+				Items []rssItem `xml:"item"`
+			} `xml:"channel"`
+		}
+		if err := xml.Unmarshal(trimmed, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse RSS feed: %w", err)
+		}
+		return rssFeed(doc.Channel.Title, doc.Channel.Items), nil
+	case "RDF":
+		var doc struct {
+			Channel struct {
+				Title string `xml:"title"`
+			} `xml:"channel"`
+			Items []rssItem `xml:"item"`
+		}
+		if err := xml.Unmarshal(trimmed, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse RSS feed: %w", err)
+		}
+		return rssFeed(doc.Channel.Title, doc.Items), nil
+	case "feed":
+		return parseAtom(trimmed)
+	}
+	return nil, fmt.Errorf("failed to parse feed: <%s> is not RSS or Atom", root.XMLName.Local)
+}
+
+// rssItem is an <item> in RSS 1.0 or 2.0.
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Description string `xml:"description"`
+	Encoded     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+}
+
+// rssFeed converts RSS items, preferring the full content over the summary.
+func rssFeed(title string, items []rssItem) *Feed {
+	feed := &Feed{Title: strings.TrimSpace(title)}
+	for _, item := range items {
+		link := strings.TrimSpace(item.Link)
+		if link == "" && isURL(item.GUID) {
+			link = strings.TrimSpace(item.GUID)
+		}
+		content := item.Encoded
+		if strings.TrimSpace(content) == "" {
+			content = item.Description
+		}
+		feed.Items = append(feed.Items, Item{
+			Title:     strings.TrimSpace(item.Title),
+			Link:      link,
+			Published: parseDate(firstNonEmpty(item.PubDate, item.Date)),
+			Content:   strings.TrimSpace(content),
+		})
+	}
+	return feed
+}
+
+// parseAtom reads an Atom <feed>.
+func parseAtom(data []byte) (*Feed, error) {
+	type atomText struct {
+		Type string `xml:"type,attr"`
+		Body string `xml:",innerxml"`
+	}
+	var doc struct {
+		Title   string `xml:"title"`
+		Entries []struct {
+			Title string `xml:"title"`
+			Links []struct {
+				Href string `xml:"href,attr"`
+				Rel  string `xml:"rel,attr"`
+			} `xml:"link"`
+			Published string   `xml:"published"`
+			Updated   string   `xml:"updated"`
+			Summary   atomText `xml:"summary"`
+			Content   atomText `xml:"content"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse Atom feed: %w", err)
+	}
+
+	feed := &Feed{Title: strings.TrimSpace(doc.Title)}
+	for _, entry := range doc.Entries {
+		var link string
+		for _, l := range entry.Links {
+			if l.Rel == "" || l.Rel == "alternate" {
+				link = strings.TrimSpace(l.Href)
+				break
+			}
+		}
+		content := entry.Content
+		if strings.TrimSpace(content.Body) == "" {
+			content = entry.Summary
+		}
+		feed.Items = append(feed.Items, Item{
+			Title:     strings.TrimSpace(entry.Title),
+			Link:      link,
+			Published: parseDate(firstNonEmpty(entry.Published, entry.Updated)),
+			Content:   atomContent(content.Type, content.Body),
+		})
+	}
+	return feed, nil
+}
+
+// atomContent turns an Atom text construct into HTML. Escaped HTML and
+// plain text arrive as character data; xhtml arrives as markup.
+func atomContent(kind, body string) string {
+	body = strings.TrimSpace(body)
+	if kind == "xhtml" {
+		return body
+	}
+	var text string
+	if err := xml.Unmarshal([]byte("<t>"+body+"</t>"), &text); err != nil {
+		return body
+	}
+	return strings.TrimSpace(text)
+}
+
+// parseJSON reads a JSON Feed.
+func parseJSON(data []byte) (*Feed, error) {
+	var doc struct {
+		Version string `json:"version"`
+		Title   string `json:"title"`
+		Items   []struct {
+			ID            string `json:"id"`
+			URL           string `json:"url"`
+			ExternalURL   string `json:"external_url"`
+			Title         string `json:"title"`
+			ContentHTML   string `json:"content_html"`
+			ContentText   string `json:"content_text"`
+			Summary       string `json:"summary"`
+			DatePublished string `json:"date_published"`
+			DateModified  string `json:"date_modified"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON feed: %w", err)
+	}
+	if !strings.HasPrefix(doc.Version, "https://jsonfeed.org/") {
+		return nil, fmt.Errorf("failed to parse feed: JSON document is not a JSON Feed")
+	}
+
+	feed := &Feed{Title: strings.TrimSpace(doc.Title)}
+	for _, item := range doc.Items {
+		link := firstNonEmpty(item.URL, item.ExternalURL)
+		if link == "" && isURL(item.ID) {
+			link = item.ID
+		}
+		feed.Items = append(feed.Items, Item{
+			Title:     strings.TrimSpace(item.Title),
+			Link:      strings.TrimSpace(link),
+			Published: parseDate(firstNonEmpty(item.DatePublished, item.DateModified)),
+			Content:   strings.TrimSpace(firstNonEmpty(item.ContentHTML, item.ContentText, item.Summary)),
+		})
+	}
+	return feed, nil
+}
+
+// dateLayouts cover RFC 822 dates from RSS, with the variations seen in the
+// wild, and RFC 3339 dates from Atom and JSON Feed.
+var dateLayouts = []string{
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC822Z,
+	time.RFC822,
+	"2006-01-02",
+}
+
+// parseDate reads a feed date, returning the zero time when it is missing
+// or malformed.
+func parseDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func isURL(value string) bool {
+	value = strings.TrimSpace(value)
+	return strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://")
+}
+
+// Article is a feed item together with its distilled markdown.
+type Article struct {
+	Item
+	Markdown string
+}
+
+// Digest renders articles as one markdown document under the feed's title,
+// each article under a linked heading with its date. Headings inside the
+// articles are pushed down two levels so they nest beneath.
+func Digest(title string, articles []Article) string {
+	var b strings.Builder
+	if title == "" {
+		title = "Feed digest"
+	}
+	fmt.Fprintf(&b, "# %s\n", title)
+	for _, article := range articles {
+		heading := article.Title
+		if heading == "" {
+			heading = article.Link
+		}
+		if article.Link != "" {
+			heading = fmt.Sprintf("[%s](%s)", heading, article.Link)
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", heading)
+		if !article.Published.IsZero() {
+			fmt.Fprintf(&b, "*%s*\n\n", article.Published.Format("2006-01-02"))
+		}
+		b.WriteString(demoteHeadings(strings.TrimSpace(article.Markdown), 2))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// demoteHeadings adds levels to every ATX heading outside code fences,
+// stopping at the deepest level markdown has.
+func demoteHeadings(markdown string, levels int) string {
+	lines := strings.Split(markdown, "\n")
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || !strings.HasPrefix(line, "#") {
+			continue
+		}
+		depth := len(line) - len(strings.TrimLeft(line, "#"))
+		if depth > 6 || (depth < len(line) && line[depth] != ' ') {
+			continue
+		}
+		lines[i] = strings.Repeat("#", min(6, depth+levels)) + line[depth:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,144 @@
+package filter
+
+import (
+	"strings"
+
+	"github.com/jewell-lgtm/essenz/internal/tree"
+)
+
+// FigureCaptionFilter removes figures and standalone images that carry no caption.
+// Uncaptioned media is usually decorative, so it is only dropped in aggressive mode.
+type FigureCaptionFilter struct{}
+
+// NewFigureCaptionFilter creates a new FigureCaptionFilter.
+func NewFigureCaptionFilter() *FigureCaptionFilter {
+	return &FigureCaptionFilter{}
+}
+
+// ShouldExclude determines if a node is a figure or image without a caption.
+func (f *FigureCaptionFilter) ShouldExclude(node *tree.TextNode, context *FilterContext) bool {
+	if node == nil || node.Tag == "#text" {
+		return false
+	}
+
+	switch strings.ToLower(node.Tag) {
+	case "figure":
+		return !hasDescendantTag(node, "figcaption")
+	case "img", "picture", "svg":
+		// Images inside a figure are judged together with their figure
+		if context != nil {
+			for _, parent := range context.ParentNodes {
+				if strings.EqualFold(parent.Tag, "figure") {
+					return false
+				}
+			}
+		}
+		return strings.TrimSpace(node.Attributes["alt"]) == "" && strings.TrimSpace(node.Attributes["title"]) == ""
+	}
+
+	return false
+}
+
+// Priority returns the priority of this filter rule.
+func (f *FigureCaptionFilter) Priority() int {
+	return 50 // Below structural rules so whitelisted containers keep their media
+}
+
+// Name returns the name of this filter rule.
+func (f *FigureCaptionFilter) Name() string {
+	return "FigureCaptionFilter"
+}
+
+// ShortSectionFilter removes sections whose total text is below a minimum length.
+// A section is a <section> element or a <div> that opens with a heading.
+type ShortSectionFilter struct {
+	minLength int
+}
+
+// NewShortSectionFilter creates a new ShortSectionFilter.
+func NewShortSectionFilter(minLength int) *ShortSectionFilter {
+	return &ShortSectionFilter{
+		minLength: minLength,
+	}
+}
+
+// ShouldExclude determines if a node is a section with too little text.
+func (f *ShortSectionFilter) ShouldExclude(node *tree.TextNode, _ *FilterContext) bool {
+	if node == nil || node.Tag == "#text" || f.minLength <= 0 {
+		return false
+	}
+
+	if !f.isSection(node) {
+		return false
+	}
+
+	// Structured content is worth keeping regardless of its prose length
+	for _, tag := range []string{"table", "pre", "code", "blockquote"} {
+		if hasDescendantTag(node, tag) {
+			return false
+		}
+	}
+
+	return len(strings.TrimSpace(collectText(node))) < f.minLength
+}
+
+// isSection checks if a node acts as a document section.
+func (f *ShortSectionFilter) isSection(node *tree.TextNode) bool {
+	switch strings.ToLower(node.Tag) {
+	case "section":
+		return true
+	case "div":
+		for _, child := range node.Children {
+			if child.Tag == "#text" {
+				if strings.TrimSpace(child.Text) != "" {
+					return false
+				}
+				continue
+			}
+			switch strings.ToLower(child.Tag) {
+			case "h1", "h2", "h3", "h4", "h5", "h6":
+				return true
+			}
+			return false
+		}
+	}
+	return false
+}
+
+// Priority returns the priority of this filter rule.
+func (f *ShortSectionFilter) Priority() int {
+	return 45 // Runs just ahead of the general length filter
+}
+
+// Name returns the name of this filter rule.
+func (f *ShortSectionFilter) Name() string {
+	return "ShortSectionFilter"
+}
+
+// hasDescendantTag checks if any descendant of a node has the given tag.
+func hasDescendantTag(node *tree.TextNode, tag string) bool {
+	for _, child := range node.Children {
+		if strings.EqualFold(child.Tag, tag) || hasDescendantTag(child, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectText joins the text of all descendant text nodes.
+func collectText(node *tree.TextNode) string {
+	if node == nil {
+		return ""
+	}
+	if node.Tag == "#text" {
+		return node.Text
+	}
+
+	var parts []string
+	for _, child := range node.Children {
+		if text := collectText(child); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
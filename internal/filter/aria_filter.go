@@ -0,0 +1,69 @@
+package filter
+
+import (
+	"strings"
+
+	"github.com/jewell-lgtm/essenz/internal/tree"
+)
+
+// AriaRoleFilter removes content based on ARIA landmark and widget roles.
+// Roles survive class name obfuscation, so they are strong signals on modern sites.
+type AriaRoleFilter struct {
+	excludedRoles map[string]bool
+}
+
+// NewAriaRoleFilter creates a new AriaRoleFilter.
+func NewAriaRoleFilter() *AriaRoleFilter {
+	return &AriaRoleFilter{
+		excludedRoles: map[string]bool{
+			// Landmark roles for page chrome
+			"navigation":    true,
+			"banner":        true,
+			"complementary": true,
+			"contentinfo":   true,
+			"search":        true,
+
+			// Widget roles that never carry article content
+			"menu":        true,
+			"menubar":     true,
+			"toolbar":     true,
+			"dialog":      true,
+			"alertdialog": true,
+			"tablist":     true,
+		},
+	}
+}
+
+// ShouldExclude determines if a node should be excluded based on its ARIA role.
+func (f *AriaRoleFilter) ShouldExclude(node *tree.TextNode, _ *FilterContext) bool {
+	if node == nil || node.Tag == "#text" {
+		return false
+	}
+
+	// Content hidden from assistive technology is hidden from readers too
+	if strings.EqualFold(strings.TrimSpace(node.Attributes["aria-hidden"]), "true") {
+		return true
+	}
+
+	// The role attribute may list fallback roles; the first recognised one applies
+	for _, role := range strings.Fields(strings.ToLower(node.Attributes["role"])) {
+		if f.excludedRoles[role] {
+			return true
+		}
+		if role == "main" || role == "article" || role == "region" {
+			return false
+		}
+	}
+
+	return false
+}
+
+// Priority returns the priority of this filter rule.
+func (f *AriaRoleFilter) Priority() int {
+	return 90 // High priority - explicit roles are authored intent
+}
+
+// Name returns the name of this filter rule.
+func (f *AriaRoleFilter) Name() string {
+	return "AriaRoleFilter"
+}
@@ -43,21 +43,29 @@ func NewClassNameFilter() *ClassNameFilter {
 }
 
 // ShouldExclude determines if a node should be excluded based on class/ID patterns.
-func (f *ClassNameFilter) ShouldExclude(node *tree.TextNode, _ *FilterContext) bool {
+func (f *ClassNameFilter) ShouldExclude(node *tree.TextNode, context *FilterContext) bool {
 	if node == nil {
 		return false
 	}
 
+	// Add the keyword pack for the document language on top of the English patterns
+	var languagePatterns []string
+	if context != nil {
+		languagePatterns = BoilerplateKeywords(context.Language)
+	}
+
 	// Check class attribute
 	if classValue, exists := node.Attributes["class"]; exists {
-		if f.matchesPattern(strings.ToLower(classValue)) {
+		value := strings.ToLower(classValue)
+		if f.matchesPattern(value, f.excludePatterns) || f.matchesPattern(value, languagePatterns) {
 			return true
 		}
 	}
 
 	// Check id attribute
 	if idValue, exists := node.Attributes["id"]; exists {
-		if f.matchesPattern(strings.ToLower(idValue)) {
+		value := strings.ToLower(idValue)
+		if f.matchesPattern(value, f.excludePatterns) || f.matchesPattern(value, languagePatterns) {
 			return true
 		}
 	}
@@ -65,9 +73,9 @@ func (f *ClassNameFilter) ShouldExclude(node *tree.TextNode, _ *FilterContext) b
 	return false
 }
 
-// matchesPattern checks if a value matches any of the exclude patterns.
-func (f *ClassNameFilter) matchesPattern(value string) bool {
-	for _, pattern := range f.excludePatterns {
+// matchesPattern checks if a value matches any of the given patterns.
+func (f *ClassNameFilter) matchesPattern(value string, patterns []string) bool {
+	for _, pattern := range patterns {
 		// Check for exact word match or pattern within CSS class names
 		if strings.Contains(value, pattern) {
 			// Additional check: ensure it's a word boundary to avoid false positives
@@ -4,6 +4,7 @@ package filter
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"strings"
 
 	"github.com/jewell-lgtm/essenz/internal/tree"
@@ -12,16 +13,21 @@ import (
 // ContentFilter provides sophisticated filtering to remove non-content elements.
 type ContentFilter struct {
 	rules  []FilterRule
+	hooks  []*ExecHook
 	config FilterConfig
+	stats  FilterStats
 }
 
 // FilterConfig configures the content filtering behavior.
 type FilterConfig struct {
-	MaxLinkDensity    float64  // 0.3 = 30% links max
-	MinContentLength  int      // Minimum characters for content blocks
+	MaxLinkDensity    float64  // Link density ceiling applied in aggressive mode, 0.2 = 20% links max
+	MinContentLength  int      // Minimum characters for content blocks in aggressive mode
+	MinSectionLength  int      // Sections with less text are dropped in aggressive mode, 0 disables
 	PreserveWhitelist []string // CSS selectors to always preserve
+	PreserveSelectors []string // User CSS selectors that override all heuristic rules
+	ExcludeSelectors  []string // CSS selectors to always remove, checked before all rules
 	AggressiveMode    bool     // More strict filtering
-	DebugMode         bool     // Log filtering decisions
+	Language          string   // Overrides the detected document language for keyword packs
 }
 
 // FilterRule defines an interface for content filtering rules.
@@ -38,6 +44,9 @@ type FilterContext struct {
 	ParentNodes   []*tree.TextNode
 	SiblingNodes  []*tree.TextNode
 	DocumentStats *DocumentStats
+	Language      string // Primary language subtag of the document, e.g. "de"
+
+	rules []FilterRule // Rules active for this filtering pass
 }
 
 // DocumentStats contains document-level statistics for filtering decisions.
@@ -51,9 +60,9 @@ type DocumentStats struct {
 
 // FilterStats contains statistics about the filtering process.
 type FilterStats struct {
-	NodesProcessed int
-	NodesRemoved   int
-	RulesApplied   map[string]int
+	NodesProcessed int            `json:"nodes_processed"`
+	NodesRemoved   int            `json:"nodes_removed"` // Subtrees removed, not counting their descendants
+	RulesApplied   map[string]int `json:"rules_applied"` // Removals by rule name, or "ExcludeSelector"
 }
 
 // NewContentFilter creates a new ContentFilter with default configuration.
@@ -63,14 +72,15 @@ func NewContentFilter() *ContentFilter {
 		config: FilterConfig{
 			MaxLinkDensity:    0.2, // More aggressive - 20% instead of 30%
 			MinContentLength:  20,  // Reduce from 50 to 20 to be less aggressive
-			PreserveWhitelist: []string{"main", "article", ".content", ".post", ".entry", ".main-article", ".main-content"},
+			MinSectionLength:  120,
+			PreserveWhitelist: []string{"main", "article", "[role=main]", "[role=article]", ".content", ".post", ".entry", ".main-article", ".main-content"},
 			AggressiveMode:    false,
-			DebugMode:         false,
 		},
 	}
 
 	// Add default filter rules
 	filter.AddRule(NewSemanticTagFilter())
+	filter.AddRule(NewAriaRoleFilter())
 	filter.AddRule(NewClassNameFilter())
 	filter.AddRule(NewLinkDensityFilter(0.3, 5)) // Balanced: 30% max link density, 5 min words
 	filter.AddRule(NewLengthFilter(10))          // Very low threshold but won't affect whitelist
@@ -84,21 +94,37 @@ func (cf *ContentFilter) WithConfig(config FilterConfig) *ContentFilter {
 	return cf
 }
 
-// WithAggressiveMode enables aggressive filtering.
+// WithAggressiveMode enables aggressive filtering. Aggressive mode lowers the link
+// density and content length thresholds to MaxLinkDensity and MinContentLength,
+// strips figures and images without captions, and drops sections shorter than
+// MinSectionLength.
 func (cf *ContentFilter) WithAggressiveMode(aggressive bool) *ContentFilter {
 	cf.config.AggressiveMode = aggressive
 	return cf
 }
 
-// WithDebugMode enables debug logging.
-func (cf *ContentFilter) WithDebugMode(debug bool) *ContentFilter {
-	cf.config.DebugMode = debug
+// WithLanguage forces the keyword pack language instead of detecting it from the document.
+func (cf *ContentFilter) WithLanguage(lang string) *ContentFilter {
+	cf.config.Language = lang
 	return cf
 }
 
-// WithPreserveSelector adds a CSS selector to the whitelist.
+// WithPreserveSelector adds a user CSS selector whose matching subtrees are always kept.
 func (cf *ContentFilter) WithPreserveSelector(selector string) *ContentFilter {
-	cf.config.PreserveWhitelist = append(cf.config.PreserveWhitelist, selector)
+	cf.config.PreserveSelectors = append(cf.config.PreserveSelectors, selector)
+	return cf
+}
+
+// WithExcludeSelector adds a CSS selector whose matching subtrees are always removed.
+func (cf *ContentFilter) WithExcludeSelector(selector string) *ContentFilter {
+	cf.config.ExcludeSelectors = append(cf.config.ExcludeSelectors, selector)
+	return cf
+}
+
+// WithHook adds an external filter hook that runs after the built-in rules.
+// Hooks run in the order they were added, each receiving the previous result.
+func (cf *ContentFilter) WithHook(command string) *ContentFilter {
+	cf.hooks = append(cf.hooks, NewExecHook(command))
 	return cf
 }
 
@@ -115,6 +141,13 @@ func (cf *ContentFilter) FilterTree(ctx context.Context, root *tree.TextNode) (*
 
 	// Calculate document statistics
 	stats := cf.calculateDocumentStats(root)
+	cf.stats = FilterStats{RulesApplied: make(map[string]int)}
+
+	// Determine which language keyword packs apply
+	language := primaryLanguage(cf.config.Language)
+	if language == "" {
+		language = DetectLanguage(root)
+	}
 
 	// Create filter context
 	filterCtx := &FilterContext{
@@ -123,11 +156,24 @@ func (cf *ContentFilter) FilterTree(ctx context.Context, root *tree.TextNode) (*
 		ParentNodes:   make([]*tree.TextNode, 0),
 		SiblingNodes:  make([]*tree.TextNode, 0),
 		DocumentStats: stats,
+		Language:      language,
+		rules:         cf.activeRules(),
 	}
 
 	// Apply filtering recursively
 	filtered := cf.filterNode(ctx, root, filterCtx)
 
+	// Hand the result to external hooks
+	if filtered != nil {
+		for _, hook := range cf.hooks {
+			hooked, err := hook.Apply(ctx, filtered)
+			if err != nil {
+				return nil, err
+			}
+			filtered = hooked
+		}
+	}
+
 	// Ensure we don't return a nil root
 	if filtered == nil {
 		// Return empty document root instead of nil
@@ -144,6 +190,26 @@ func (cf *ContentFilter) FilterTree(ctx context.Context, root *tree.TextNode) (*
 	return filtered, nil
 }
 
+// activeRules returns the rules for a filtering pass, adding the stricter
+// threshold rules when aggressive mode is enabled.
+func (cf *ContentFilter) activeRules() []FilterRule {
+	if !cf.config.AggressiveMode {
+		return cf.rules
+	}
+
+	rules := make([]FilterRule, 0, len(cf.rules)+4)
+	rules = append(rules, cf.rules...)
+	rules = append(rules,
+		NewLinkDensityFilter(cf.config.MaxLinkDensity, 3),
+		NewLengthFilter(cf.config.MinContentLength),
+		NewFigureCaptionFilter(),
+	)
+	if cf.config.MinSectionLength > 0 {
+		rules = append(rules, NewShortSectionFilter(cf.config.MinSectionLength))
+	}
+	return rules
+}
+
 // filterNode recursively filters a node and its children.
 func (cf *ContentFilter) filterNode(ctx context.Context, node *tree.TextNode, filterCtx *FilterContext) *tree.TextNode {
 	// Check for context cancellation
@@ -156,14 +222,32 @@ func (cf *ContentFilter) filterNode(ctx context.Context, node *tree.TextNode, fi
 	if node == nil {
 		return nil
 	}
+	cf.stats.NodesProcessed++
+
+	// User-supplied exclusions win over every rule and the whitelist
+	if cf.isExcluded(node) {
+		slog.Debug("excluding node by exclude selector", "tag", node.Tag, "class", node.Attributes["class"])
+		cf.recordRemoval("ExcludeSelector")
+		return nil
+	}
+
+	// User-supplied preserve selectors override the heuristic rules for the whole subtree
+	if cf.isPreserved(node) {
+		slog.Debug("preserving node by preserve selector", "tag", node.Tag, "class", node.Attributes["class"])
+		cf.pruneExcluded(node)
+		return node
+	}
 
 	// Check if node should be excluded by high-priority rules first (SemanticTagFilter, ClassNameFilter)
 	// These rules override whitelist for strong negative indicators
-	for _, rule := range cf.rules {
+	for _, rule := range filterCtx.rules {
 		if rule.Priority() >= 80 && rule.ShouldExclude(node, filterCtx) {
-			if cf.config.DebugMode {
-				fmt.Printf("DEBUG: Excluding node by high-priority rule %s: %s (class=%v)\n", rule.Name(), node.Tag, node.Attributes["class"])
+			if cf.containsPreserved(node) {
+				// Keep the container so preserved descendants survive
+				return cf.filterChildren(ctx, node, filterCtx)
 			}
+			slog.Debug("excluding node by high-priority rule", "rule", rule.Name(), "tag", node.Tag, "class", node.Attributes["class"])
+			cf.recordRemoval(rule.Name())
 			return nil // Remove this node
 		}
 	}
@@ -172,18 +256,18 @@ func (cf *ContentFilter) filterNode(ctx context.Context, node *tree.TextNode, fi
 	isWhitelisted := cf.isWhitelisted(node)
 	if !isWhitelisted {
 		// Apply remaining lower-priority rules
-		for _, rule := range cf.rules {
+		for _, rule := range filterCtx.rules {
 			if rule.Priority() < 80 && rule.ShouldExclude(node, filterCtx) {
-				if cf.config.DebugMode {
-					fmt.Printf("DEBUG: Excluding node by rule %s: %s (class=%v)\n", rule.Name(), node.Tag, node.Attributes["class"])
+				if cf.containsPreserved(node) {
+					return cf.filterChildren(ctx, node, filterCtx)
 				}
+				slog.Debug("excluding node by rule", "rule", rule.Name(), "tag", node.Tag, "class", node.Attributes["class"])
+				cf.recordRemoval(rule.Name())
 				return nil // Remove this node
 			}
 		}
 	} else {
-		if cf.config.DebugMode {
-			fmt.Printf("DEBUG: Preserving whitelisted node: %s\n", node.Tag)
-		}
+		slog.Debug("preserving whitelisted node", "tag", node.Tag)
 	}
 
 	// Node passes all filters, process its children
@@ -203,6 +287,8 @@ func (cf *ContentFilter) filterChildren(ctx context.Context, node *tree.TextNode
 		ParentNodes:   append(filterCtx.ParentNodes, node),
 		SiblingNodes:  node.Children, // Current children become siblings for the recursive call
 		DocumentStats: filterCtx.DocumentStats,
+		Language:      filterCtx.Language,
+		rules:         filterCtx.rules,
 	}
 
 	// Filter children
@@ -222,26 +308,68 @@ func (cf *ContentFilter) filterChildren(ctx context.Context, node *tree.TextNode
 
 // isWhitelisted checks if a node is in the whitelist.
 func (cf *ContentFilter) isWhitelisted(node *tree.TextNode) bool {
-	// Check tag-based whitelist
 	for _, selector := range cf.config.PreserveWhitelist {
-		if strings.HasPrefix(selector, ".") {
-			// CSS class selector
-			className := strings.TrimPrefix(selector, ".")
-			if classValue, exists := node.Attributes["class"]; exists {
-				if strings.Contains(classValue, className) {
-					return true
-				}
-			}
-		} else {
-			// Tag selector
-			if strings.EqualFold(node.Tag, selector) {
-				return true
-			}
+		if matchesSelector(node, selector) {
+			return true
+		}
+	}
+	return cf.isPreserved(node)
+}
+
+// isPreserved checks if a node matches any user preserve selector.
+func (cf *ContentFilter) isPreserved(node *tree.TextNode) bool {
+	for _, selector := range cf.config.PreserveSelectors {
+		if matchesSelector(node, selector) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsPreserved checks if any descendant of a node matches a user preserve selector.
+func (cf *ContentFilter) containsPreserved(node *tree.TextNode) bool {
+	if len(cf.config.PreserveSelectors) == 0 {
+		return false
+	}
+	for _, child := range node.Children {
+		if cf.isPreserved(child) || cf.containsPreserved(child) {
+			return true
 		}
 	}
 	return false
 }
 
+// pruneExcluded removes descendants matching exclude selectors from a preserved subtree.
+func (cf *ContentFilter) pruneExcluded(node *tree.TextNode) {
+	if len(cf.config.ExcludeSelectors) == 0 {
+		return
+	}
+	kept := make([]*tree.TextNode, 0, len(node.Children))
+	for _, child := range node.Children {
+		if cf.isExcluded(child) {
+			continue
+		}
+		cf.pruneExcluded(child)
+		kept = append(kept, child)
+	}
+	node.Children = kept
+}
+
+// isExcluded checks if a node matches any of the exclude selectors.
+func (cf *ContentFilter) isExcluded(node *tree.TextNode) bool {
+	for _, selector := range cf.config.ExcludeSelectors {
+		if matchesSelector(node, selector) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesSelector checks a node against a CSS selector; invalid selectors never match.
+func matchesSelector(node *tree.TextNode, selector string) bool {
+	return compileSelector(strings.TrimSpace(selector)).Matches(node)
+}
+
 // calculateDocumentStats calculates statistics about the document.
 func (cf *ContentFilter) calculateDocumentStats(root *tree.TextNode) *DocumentStats {
 	stats := &DocumentStats{}
@@ -282,12 +410,20 @@ func (cf *ContentFilter) collectStats(node *tree.TextNode, stats *DocumentStats)
 	}
 }
 
+// recordRemoval counts a subtree removed by the named rule.
+func (cf *ContentFilter) recordRemoval(rule string) {
+	cf.stats.NodesRemoved++
+	cf.stats.RulesApplied[rule]++
+}
+
 // GetFilterStats returns statistics about the last filtering operation.
+// Nodes inside removed subtrees are never visited, so they are not counted
+// as processed.
 func (cf *ContentFilter) GetFilterStats() *FilterStats {
-	// This would be populated during filtering
-	return &FilterStats{
-		NodesProcessed: 0,
-		NodesRemoved:   0,
-		RulesApplied:   make(map[string]int),
+	stats := cf.stats
+	stats.RulesApplied = make(map[string]int, len(cf.stats.RulesApplied))
+	for rule, count := range cf.stats.RulesApplied {
+		stats.RulesApplied[rule] = count
 	}
+	return &stats
 }
@@ -0,0 +1,80 @@
+package filter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/jewell-lgtm/essenz/internal/tree"
+)
+
+// ExecHook is an external filter plugin. The command receives the text node tree
+// as JSON on stdin and must write the modified tree as JSON to stdout.
+type ExecHook struct {
+	command string
+}
+
+// NewExecHook creates a hook that runs the given command line. The command is
+// split on whitespace, so "./hook.py --strict" passes --strict to the script.
+func NewExecHook(command string) *ExecHook {
+	return &ExecHook{
+		command: command,
+	}
+}
+
+// Apply runs the hook command on a tree and returns the tree it writes back.
+func (h *ExecHook) Apply(ctx context.Context, root *tree.TextNode) (*tree.TextNode, error) {
+	args := strings.Fields(h.command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("filter hook command is empty")
+	}
+
+	input, err := json.Marshal(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tree for filter hook: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("filter hook %s failed: %w: %s", args[0], err, msg)
+		}
+		return nil, fmt.Errorf("filter hook %s failed: %w", args[0], err)
+	}
+
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return nil, fmt.Errorf("filter hook %s produced no output", args[0])
+	}
+
+	var result tree.TextNode
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return nil, fmt.Errorf("filter hook %s returned invalid tree JSON: %w", args[0], err)
+	}
+
+	// Parent pointers are not serialized, so rebuild them
+	linkParents(&result, nil)
+
+	return &result, nil
+}
+
+// linkParents restores parent pointers after a tree has been decoded from JSON
+// and drops null children a hook may have left behind.
+func linkParents(node, parent *tree.TextNode) {
+	node.Parent = parent
+	children := node.Children[:0]
+	for _, child := range node.Children {
+		if child != nil {
+			linkParents(child, node)
+			children = append(children, child)
+		}
+	}
+	node.Children = children
+}
@@ -0,0 +1,112 @@
+package filter
+
+import (
+	"strings"
+
+	"github.com/jewell-lgtm/essenz/internal/tree"
+)
+
+// boilerplateKeywords maps a primary language subtag to class/ID keywords that
+// indicate navigation, advertising, and other non-content blocks in that language.
+var boilerplateKeywords = map[string][]string{
+	"de": {
+		"werbung", "anzeige", "navigation", "menü", "seitenleiste", "fusszeile", "fußzeile",
+		"kopfzeile", "kommentare", "teilen", "verwandte", "ähnliche", "impressum",
+	},
+	"fr": {
+		"publicité", "publicite", "annonce", "menu", "barre-laterale", "pied-de-page",
+		"entete", "en-tete", "commentaires", "partager", "articles-lies", "similaires",
+	},
+	"es": {
+		"publicidad", "anuncio", "menú", "barra-lateral", "pie-de-pagina", "cabecera",
+		"comentarios", "compartir", "relacionados", "navegacion", "navegación",
+	},
+	"it": {
+		"pubblicità", "pubblicita", "annuncio", "barra-laterale", "piede", "intestazione",
+		"commenti", "condividi", "correlati", "navigazione",
+	},
+	"pt": {
+		"publicidade", "anuncio", "anúncio", "barra-lateral", "rodape", "rodapé", "cabecalho",
+		"cabeçalho", "comentarios", "comentários", "compartilhar", "relacionados", "navegacao",
+	},
+	"nl": {
+		"advertentie", "reclame", "zijbalk", "voettekst", "koptekst", "reacties", "delen",
+		"gerelateerd", "navigatie",
+	},
+	"ru": {
+		"реклама", "меню", "навигация", "боковая-панель", "подвал", "шапка", "комментарии",
+		"поделиться", "похожие",
+	},
+	"ja": {
+		"広告", "メニュー", "ナビゲーション", "サイドバー", "フッター", "ヘッダー", "コメント",
+		"シェア", "関連記事",
+	},
+	"zh": {
+		"广告", "廣告", "菜单", "菜單", "导航", "導航", "侧边栏", "側邊欄", "页脚", "頁尾",
+		"评论", "評論", "分享", "相关", "相關",
+	},
+	"ko": {
+		"광고", "메뉴", "내비게이션", "사이드바", "푸터", "헤더", "댓글", "공유", "관련",
+	},
+	"pl": {
+		"reklama", "reklamy", "nawigacja", "pasek-boczny", "stopka", "naglowek", "komentarze",
+		"udostepnij", "powiazane",
+	},
+}
+
+// BoilerplateKeywords returns the boilerplate keyword pack for a language tag
+// such as "de" or "fr-CA". It returns nil when no pack exists for the language.
+func BoilerplateKeywords(lang string) []string {
+	return boilerplateKeywords[primaryLanguage(lang)]
+}
+
+// DetectLanguage returns the document language declared on the html element
+// or in a Content-Language meta tag, or an empty string if none is declared.
+func DetectLanguage(root *tree.TextNode) string {
+	var lang string
+	var walk func(node *tree.TextNode) bool
+	walk = func(node *tree.TextNode) bool {
+		if node == nil {
+			return false
+		}
+
+		switch strings.ToLower(node.Tag) {
+		case "html":
+			if value := node.Attributes["lang"]; value != "" {
+				lang = value
+				return true
+			}
+			if value := node.Attributes["xml:lang"]; value != "" {
+				lang = value
+				return true
+			}
+		case "meta":
+			if strings.EqualFold(node.Attributes["http-equiv"], "content-language") && node.Attributes["content"] != "" {
+				lang = node.Attributes["content"]
+				return true
+			}
+		case "body":
+			// Language declarations live in <html> or <head>; stop before the content
+			return true
+		}
+
+		for _, child := range node.Children {
+			if walk(child) {
+				return true
+			}
+		}
+		return false
+	}
+	walk(root)
+
+	return primaryLanguage(lang)
+}
+
+// primaryLanguage reduces a BCP 47 tag like "pt-BR" to its primary subtag.
+func primaryLanguage(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if idx := strings.IndexAny(lang, "-_,;"); idx != -1 {
+		lang = lang[:idx]
+	}
+	return lang
+}
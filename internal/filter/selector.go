@@ -0,0 +1,476 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/jewell-lgtm/essenz/internal/tree"
+)
+
+// Selector is a compiled CSS selector list that can be matched against tree nodes.
+// It supports type, universal, class, ID and attribute selectors, the :not() and
+// :nth-child(n) pseudo-classes, and the descendant, child, adjacent and general
+// sibling combinators.
+type Selector struct {
+	source    string
+	alternate []complexSelector
+}
+
+// complexSelector is a chain of compound selectors joined by combinators.
+type complexSelector struct {
+	compounds   []compoundSelector
+	combinators []rune // combinators[i] joins compounds[i] and compounds[i+1]
+}
+
+// compoundSelector is a sequence of simple selectors that apply to one element.
+type compoundSelector struct {
+	tag        string
+	ids        []string
+	classes    []string
+	attributes []attributeSelector
+	negations  []*Selector
+	positions  []int // 1-based :nth-child positions
+}
+
+// attributeSelector matches an attribute by presence or by value.
+type attributeSelector struct {
+	name     string
+	operator string // "", "=", "~=", "|=", "^=", "$=", "*="
+	value    string
+}
+
+var selectorCache sync.Map
+
+// ParseSelector compiles a CSS selector list such as "article > .body, main p:not(.ad)".
+func ParseSelector(source string) (*Selector, error) {
+	p := &selectorParser{input: []rune(source)}
+	selector, err := p.parseList()
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector %q: %w", source, err)
+	}
+
+	p.skipSpace()
+	if !p.done() {
+		return nil, fmt.Errorf("invalid selector %q: unexpected %q at offset %d", source, p.peek(), p.pos)
+	}
+
+	selector.source = source
+	return selector, nil
+}
+
+// compileSelector returns a cached compiled selector, or nil if it does not parse.
+func compileSelector(source string) *Selector {
+	if cached, ok := selectorCache.Load(source); ok {
+		return cached.(*Selector)
+	}
+
+	selector, err := ParseSelector(source)
+	if err != nil {
+		selector = nil
+	}
+	selectorCache.Store(source, selector)
+	return selector
+}
+
+// String returns the selector source text.
+func (s *Selector) String() string {
+	return s.source
+}
+
+// SelectorPath builds a selector that uniquely addresses node within its document,
+// anchored at the nearest ancestor with an ID or at the html element.
+func SelectorPath(node *tree.TextNode) string {
+	var parts []string
+	for current := node; isElement(current); current = current.Parent {
+		tag := strings.ToLower(current.Tag)
+		if id := current.Attributes["id"]; id != "" && !strings.ContainsAny(id, " \t\n\"'[]():.#>+~,") {
+			parts = append([]string{tag + "#" + id}, parts...)
+			break
+		}
+		if tag == "html" || tag == "body" || !isElement(current.Parent) {
+			parts = append([]string{tag}, parts...)
+			continue
+		}
+		position := len(previousElementSiblings(current)) + 1
+		parts = append([]string{fmt.Sprintf("%s:nth-child(%d)", tag, position)}, parts...)
+	}
+	return strings.Join(parts, " > ")
+}
+
+// Matches reports whether the node matches any selector in the list.
+func (s *Selector) Matches(node *tree.TextNode) bool {
+	if s == nil || !isElement(node) {
+		return false
+	}
+
+	for _, complex := range s.alternate {
+		if complex.matches(node, len(complex.compounds)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches checks compounds[0..index] right to left, with compounds[index] applied to node.
+func (c complexSelector) matches(node *tree.TextNode, index int) bool {
+	if !c.compounds[index].matches(node) {
+		return false
+	}
+	if index == 0 {
+		return true
+	}
+
+	switch c.combinators[index-1] {
+	case '>':
+		parent := node.Parent
+		return isElement(parent) && c.matches(parent, index-1)
+	case '+':
+		previous := previousElementSiblings(node)
+		return len(previous) > 0 && c.matches(previous[0], index-1)
+	case '~':
+		for _, sibling := range previousElementSiblings(node) {
+			if c.matches(sibling, index-1) {
+				return true
+			}
+		}
+		return false
+	default:
+		for ancestor := node.Parent; isElement(ancestor); ancestor = ancestor.Parent {
+			if c.matches(ancestor, index-1) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// matches checks every simple selector of the compound against the node.
+func (c compoundSelector) matches(node *tree.TextNode) bool {
+	if c.tag != "" && c.tag != "*" && !strings.EqualFold(node.Tag, c.tag) {
+		return false
+	}
+
+	for _, id := range c.ids {
+		if node.Attributes["id"] != id {
+			return false
+		}
+	}
+
+	if len(c.classes) > 0 {
+		classes := strings.Fields(node.Attributes["class"])
+		for _, class := range c.classes {
+			if !containsString(classes, class) {
+				return false
+			}
+		}
+	}
+
+	for _, attribute := range c.attributes {
+		if !attribute.matches(node) {
+			return false
+		}
+	}
+
+	for _, negation := range c.negations {
+		if negation.Matches(node) {
+			return false
+		}
+	}
+
+	for _, position := range c.positions {
+		if len(previousElementSiblings(node))+1 != position {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matches checks the attribute selector against the node.
+func (a attributeSelector) matches(node *tree.TextNode) bool {
+	value, exists := node.Attributes[a.name]
+	if !exists {
+		return false
+	}
+
+	switch a.operator {
+	case "":
+		return true
+	case "=":
+		return value == a.value
+	case "~=":
+		return containsString(strings.Fields(value), a.value)
+	case "|=":
+		return value == a.value || strings.HasPrefix(value, a.value+"-")
+	case "^=":
+		return a.value != "" && strings.HasPrefix(value, a.value)
+	case "$=":
+		return a.value != "" && strings.HasSuffix(value, a.value)
+	case "*=":
+		return a.value != "" && strings.Contains(value, a.value)
+	}
+	return false
+}
+
+// isElement reports whether the node is an element rather than text or the document root.
+func isElement(node *tree.TextNode) bool {
+	return node != nil && node.Tag != "#text" && node.Tag != "document"
+}
+
+// previousElementSiblings returns the element siblings before node, nearest first.
+func previousElementSiblings(node *tree.TextNode) []*tree.TextNode {
+	if node.Parent == nil {
+		return nil
+	}
+
+	var previous []*tree.TextNode
+	for _, sibling := range node.Parent.Children {
+		if sibling == node {
+			break
+		}
+		if isElement(sibling) {
+			previous = append([]*tree.TextNode{sibling}, previous...)
+		}
+	}
+	return previous
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+// selectorParser is a small recursive-descent parser for CSS selectors.
+type selectorParser struct {
+	input []rune
+	pos   int
+}
+
+func (p *selectorParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *selectorParser) peek() rune {
+	if p.done() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *selectorParser) skipSpace() bool {
+	skipped := false
+	for !p.done() && unicode.IsSpace(p.peek()) {
+		p.pos++
+		skipped = true
+	}
+	return skipped
+}
+
+// parseList parses comma-separated complex selectors up to the end or a closing parenthesis.
+func (p *selectorParser) parseList() (*Selector, error) {
+	selector := &Selector{}
+	for {
+		p.skipSpace()
+		complex, err := p.parseComplex()
+		if err != nil {
+			return nil, err
+		}
+		selector.alternate = append(selector.alternate, complex)
+
+		p.skipSpace()
+		if p.peek() != ',' {
+			return selector, nil
+		}
+		p.pos++
+	}
+}
+
+// parseComplex parses compound selectors joined by combinators.
+func (p *selectorParser) parseComplex() (complexSelector, error) {
+	var complex complexSelector
+
+	compound, err := p.parseCompound()
+	if err != nil {
+		return complex, err
+	}
+	complex.compounds = append(complex.compounds, compound)
+
+	for {
+		hadSpace := p.skipSpace()
+		combinator := ' '
+		switch next := p.peek(); next {
+		case '>', '+', '~':
+			combinator = next
+			p.pos++
+			p.skipSpace()
+		case 0, ',', ')':
+			return complex, nil
+		default:
+			if !hadSpace {
+				return complex, fmt.Errorf("unexpected %q at offset %d", next, p.pos)
+			}
+		}
+
+		compound, err := p.parseCompound()
+		if err != nil {
+			return complex, err
+		}
+		complex.combinators = append(complex.combinators, combinator)
+		complex.compounds = append(complex.compounds, compound)
+	}
+}
+
+// parseCompound parses an optional type selector followed by class, ID, attribute and pseudo-class selectors.
+func (p *selectorParser) parseCompound() (compoundSelector, error) {
+	var compound compoundSelector
+	start := p.pos
+
+	if p.peek() == '*' {
+		compound.tag = "*"
+		p.pos++
+	} else if isIdentRune(p.peek()) {
+		compound.tag = p.parseIdent()
+	}
+
+	for {
+		switch p.peek() {
+		case '.':
+			p.pos++
+			class := p.parseIdent()
+			if class == "" {
+				return compound, fmt.Errorf("expected class name at offset %d", p.pos)
+			}
+			compound.classes = append(compound.classes, class)
+		case '#':
+			p.pos++
+			id := p.parseIdent()
+			if id == "" {
+				return compound, fmt.Errorf("expected id at offset %d", p.pos)
+			}
+			compound.ids = append(compound.ids, id)
+		case '[':
+			attribute, err := p.parseAttribute()
+			if err != nil {
+				return compound, err
+			}
+			compound.attributes = append(compound.attributes, attribute)
+		case ':':
+			if err := p.parsePseudoClass(&compound); err != nil {
+				return compound, err
+			}
+		default:
+			if p.pos == start {
+				return compound, fmt.Errorf("expected selector at offset %d", p.pos)
+			}
+			return compound, nil
+		}
+	}
+}
+
+// parseAttribute parses [name], [name=value] and the substring operator forms.
+func (p *selectorParser) parseAttribute() (attributeSelector, error) {
+	var attribute attributeSelector
+	p.pos++ // consume '['
+	p.skipSpace()
+
+	attribute.name = strings.ToLower(p.parseIdent())
+	if attribute.name == "" {
+		return attribute, fmt.Errorf("expected attribute name at offset %d", p.pos)
+	}
+	p.skipSpace()
+
+	switch next := p.peek(); next {
+	case ']':
+		p.pos++
+		return attribute, nil
+	case '=':
+		attribute.operator = "="
+		p.pos++
+	case '~', '|', '^', '$', '*':
+		p.pos++
+		if p.peek() != '=' {
+			return attribute, fmt.Errorf("expected '=' after %q at offset %d", next, p.pos)
+		}
+		attribute.operator = string(next) + "="
+		p.pos++
+	default:
+		return attribute, fmt.Errorf("unexpected %q in attribute selector at offset %d", next, p.pos)
+	}
+
+	p.skipSpace()
+	if quote := p.peek(); quote == '"' || quote == '\'' {
+		p.pos++
+		begin := p.pos
+		for !p.done() && p.peek() != quote {
+			p.pos++
+		}
+		if p.done() {
+			return attribute, fmt.Errorf("unterminated string in attribute selector")
+		}
+		attribute.value = string(p.input[begin:p.pos])
+		p.pos++
+	} else {
+		attribute.value = p.parseIdent()
+	}
+
+	p.skipSpace()
+	if p.peek() != ']' {
+		return attribute, fmt.Errorf("expected ']' at offset %d", p.pos)
+	}
+	p.pos++
+	return attribute, nil
+}
+
+// parsePseudoClass parses :not(selector-list) and :nth-child(n); other pseudo-classes are rejected.
+func (p *selectorParser) parsePseudoClass(compound *compoundSelector) error {
+	p.pos++ // consume ':'
+	name := strings.ToLower(p.parseIdent())
+	if name != "not" && name != "nth-child" {
+		return fmt.Errorf("unsupported pseudo-class :%s", name)
+	}
+	if p.peek() != '(' {
+		return fmt.Errorf("expected '(' after :%s at offset %d", name, p.pos)
+	}
+	p.pos++
+	p.skipSpace()
+
+	if name == "nth-child" {
+		position, err := strconv.Atoi(p.parseIdent())
+		if err != nil || position < 1 {
+			return fmt.Errorf("only positive integer :nth-child arguments are supported")
+		}
+		compound.positions = append(compound.positions, position)
+	} else {
+		inner, err := p.parseList()
+		if err != nil {
+			return err
+		}
+		compound.negations = append(compound.negations, inner)
+	}
+
+	p.skipSpace()
+	if p.peek() != ')' {
+		return fmt.Errorf("expected ')' at offset %d", p.pos)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *selectorParser) parseIdent() string {
+	begin := p.pos
+	for !p.done() && isIdentRune(p.peek()) {
+		p.pos++
+	}
+	return string(p.input[begin:p.pos])
+}
+
+func isIdentRune(r rune) bool {
+	return r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || r > unicode.MaxASCII
+}
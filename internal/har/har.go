@@ -0,0 +1,389 @@
+// Package har records the network activity of a page load in HAR 1.2 format.
+package har
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// HAR is the top-level HAR document.
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+// Log holds the recorded entries.
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+}
+
+// Creator names the tool that wrote the log.
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Entry is one request and its response. Redirects are separate entries.
+type Entry struct {
+	StartedDateTime time.Time `json:"startedDateTime"`
+	Time            float64   `json:"time"` // Total milliseconds
+	Request         Request   `json:"request"`
+	Response        Response  `json:"response"`
+	Cache           struct{}  `json:"cache"`
+	Timings         Timings   `json:"timings"`
+	ServerIPAddress string    `json:"serverIPAddress,omitempty"`
+	ResourceType    string    `json:"_resourceType,omitempty"`
+	Error           string    `json:"_error,omitempty"` // Why the request failed or was blocked
+}
+
+// Request describes the request sent.
+type Request struct {
+	Method      string   `json:"method"`
+	URL         string   `json:"url"`
+	HTTPVersion string   `json:"httpVersion"`
+	Headers     []Header `json:"headers"`
+	QueryString []Header `json:"queryString"`
+	Cookies     []Header `json:"cookies"`
+	HeadersSize int      `json:"headersSize"`
+	BodySize    int      `json:"bodySize"`
+}
+
+// Response describes the response received.
+type Response struct {
+	Status      int64    `json:"status"`
+	StatusText  string   `json:"statusText"`
+	HTTPVersion string   `json:"httpVersion"`
+	Headers     []Header `json:"headers"`
+	Cookies     []Header `json:"cookies"`
+	Content     Content  `json:"content"`
+	RedirectURL string   `json:"redirectURL"`
+	HeadersSize int      `json:"headersSize"`
+	BodySize    int64    `json:"bodySize"`
+}
+
+// Content describes the response body. The body itself is not recorded.
+type Content struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+}
+
+// Header is a name/value pair, used for headers, query parameters, and cookies.
+type Header struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Timings breaks down the entry time in milliseconds; -1 means not applicable.
+type Timings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// pending tracks an in-flight request until it finishes or fails.
+type pending struct {
+	entry   Entry
+	started time.Time // Monotonic start for duration math
+	timing  *network.ResourceTiming
+}
+
+// Recorder builds a HAR from a tab's network events. It is safe for
+// concurrent use since CDP events arrive on their own goroutine.
+type Recorder struct {
+	mu       sync.Mutex
+	inFlight map[network.RequestID]*pending
+	entries  []Entry
+}
+
+// NewRecorder creates an empty recorder. The log's creator version is left
+// for the caller to fill in.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		inFlight: make(map[network.RequestID]*pending),
+	}
+}
+
+// Start subscribes to network events in a tab. It must run before navigation.
+func (r *Recorder) Start(chromeCtx context.Context) error {
+	chromedp.ListenTarget(chromeCtx, func(ev interface{}) {
+		switch ev := ev.(type) {
+		case *network.EventRequestWillBeSent:
+			r.requestWillBeSent(ev)
+		case *network.EventResponseReceived:
+			r.responseReceived(ev)
+		case *network.EventLoadingFinished:
+			r.loadingFinished(ev)
+		case *network.EventLoadingFailed:
+			r.loadingFailed(ev)
+		}
+	})
+
+	if err := chromedp.Run(chromeCtx, network.Enable()); err != nil {
+		return fmt.Errorf("failed to enable network recording: %w", err)
+	}
+	return nil
+}
+
+// HAR returns the log recorded so far, including requests still in flight,
+// ordered by start time.
+func (r *Recorder) HAR() *HAR {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := append([]Entry{}, r.entries...)
+	for _, p := range r.inFlight {
+		entry := p.entry
+		if entry.Error == "" {
+			entry.Error = "unfinished"
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartedDateTime.Before(entries[j].StartedDateTime)
+	})
+
+	return &HAR{Log: Log{
+		Version: "1.2",
+		Creator: Creator{Name: "sz"},
+		Entries: entries,
+	}}
+}
+
+// requestWillBeSent starts an entry. A redirect reuses the request ID, so
+// the previous hop is completed with the redirect response first.
+func (r *Recorder) requestWillBeSent(ev *network.EventRequestWillBeSent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if previous, ok := r.inFlight[ev.RequestID]; ok && ev.RedirectResponse != nil {
+		previous.setResponse(ev.RedirectResponse)
+		previous.entry.Response.RedirectURL = ev.Request.URL
+		previous.finish(monotonic(ev.Timestamp), 0)
+		r.entries = append(r.entries, previous.entry)
+	}
+
+	started := time.Now()
+	if ev.WallTime != nil {
+		started = ev.WallTime.Time()
+	}
+	r.inFlight[ev.RequestID] = &pending{
+		started: monotonic(ev.Timestamp),
+		entry: Entry{
+			StartedDateTime: started,
+			Request:         newRequest(ev.Request),
+			Response:        Response{Headers: []Header{}, Cookies: []Header{}, HeadersSize: -1, BodySize: -1},
+			Timings:         Timings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1},
+			ResourceType:    string(ev.Type),
+		},
+	}
+}
+
+// responseReceived records the response headers and timing.
+func (r *Recorder) responseReceived(ev *network.EventResponseReceived) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p, ok := r.inFlight[ev.RequestID]; ok && ev.Response != nil {
+		p.setResponse(ev.Response)
+	}
+}
+
+// loadingFinished completes an entry with the transferred size.
+func (r *Recorder) loadingFinished(ev *network.EventLoadingFinished) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.inFlight[ev.RequestID]
+	if !ok {
+		return
+	}
+	p.finish(monotonic(ev.Timestamp), int64(ev.EncodedDataLength))
+	r.entries = append(r.entries, p.entry)
+	delete(r.inFlight, ev.RequestID)
+}
+
+// loadingFailed completes an entry with the failure reason.
+func (r *Recorder) loadingFailed(ev *network.EventLoadingFailed) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.inFlight[ev.RequestID]
+	if !ok {
+		return
+	}
+	p.entry.Error = ev.ErrorText
+	if ev.BlockedReason != "" {
+		p.entry.Error = fmt.Sprintf("%s (blocked: %s)", ev.ErrorText, ev.BlockedReason)
+	}
+	p.finish(monotonic(ev.Timestamp), 0)
+	r.entries = append(r.entries, p.entry)
+	delete(r.inFlight, ev.RequestID)
+}
+
+// setResponse copies response details into the entry.
+func (p *pending) setResponse(resp *network.Response) {
+	version := httpVersion(resp.Protocol)
+	p.entry.Request.HTTPVersion = version
+	if len(resp.RequestHeaders) > 0 {
+		p.entry.Request.Headers = headerList(resp.RequestHeaders)
+	}
+	p.entry.Response = Response{
+		Status:      resp.Status,
+		StatusText:  resp.StatusText,
+		HTTPVersion: version,
+		Headers:     headerList(resp.Headers),
+		Cookies:     []Header{},
+		Content:     Content{Size: -1, MimeType: resp.MimeType},
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+	p.entry.ServerIPAddress = resp.RemoteIPAddress
+	p.timing = resp.Timing
+}
+
+// finish sets the total time, phase timings, and body size.
+func (p *pending) finish(ended time.Time, bodySize int64) {
+	total := milliseconds(ended.Sub(p.started))
+	if total < 0 {
+		total = 0
+	}
+	p.entry.Time = total
+
+	if bodySize > 0 {
+		p.entry.Response.BodySize = bodySize
+		p.entry.Response.Content.Size = bodySize
+	}
+
+	t := p.timing
+	if t == nil {
+		p.entry.Timings.Send = 0
+		p.entry.Timings.Wait = total
+		p.entry.Timings.Receive = 0
+		return
+	}
+
+	p.entry.Timings.DNS = span(t.DNSStart, t.DNSEnd)
+	p.entry.Timings.Connect = span(t.ConnectStart, t.ConnectEnd)
+	p.entry.Timings.SSL = span(t.SslStart, t.SslEnd)
+	p.entry.Timings.Send = nonNegative(t.SendEnd - t.SendStart)
+	p.entry.Timings.Wait = nonNegative(t.ReceiveHeadersEnd - t.SendEnd)
+	p.entry.Timings.Receive = nonNegative(total - t.ReceiveHeadersEnd)
+	if first := firstPhase(t); first > 0 {
+		p.entry.Timings.Blocked = first
+	}
+}
+
+// newRequest converts CDP request data.
+func newRequest(req *network.Request) Request {
+	out := Request{
+		Method:      req.Method,
+		URL:         req.URL + req.URLFragment,
+		HTTPVersion: "HTTP/1.1",
+		Headers:     headerList(req.Headers),
+		QueryString: []Header{},
+		Cookies:     []Header{},
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+	if parsed, err := url.Parse(req.URL); err == nil {
+		for name, values := range parsed.Query() {
+			for _, value := range values {
+				out.QueryString = append(out.QueryString, Header{Name: name, Value: value})
+			}
+		}
+		sortHeaders(out.QueryString)
+	}
+	if !req.HasPostData {
+		out.BodySize = 0
+	}
+	return out
+}
+
+// headerList converts CDP headers into sorted name/value pairs.
+func headerList(headers network.Headers) []Header {
+	list := make([]Header, 0, len(headers))
+	for name, value := range headers {
+		// Repeated headers arrive joined by newlines
+		for _, line := range strings.Split(fmt.Sprint(value), "\n") {
+			list = append(list, Header{Name: name, Value: line})
+		}
+	}
+	sortHeaders(list)
+	return list
+}
+
+// sortHeaders orders pairs by name for stable output.
+func sortHeaders(headers []Header) {
+	sort.SliceStable(headers, func(i, j int) bool {
+		return headers[i].Name < headers[j].Name
+	})
+}
+
+// httpVersion maps a CDP protocol name onto a HAR HTTP version.
+func httpVersion(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "h2":
+		return "HTTP/2"
+	case "h3", "http/3":
+		return "HTTP/3"
+	case "http/1.0":
+		return "HTTP/1.0"
+	case "":
+		return "HTTP/1.1"
+	default:
+		return strings.ToUpper(protocol)
+	}
+}
+
+// span returns the length of a timing phase, or -1 if it did not happen.
+func span(start, end float64) float64 {
+	if start < 0 || end < 0 {
+		return -1
+	}
+	return end - start
+}
+
+// firstPhase returns when the first network phase began, relative to the request.
+func firstPhase(t *network.ResourceTiming) float64 {
+	for _, start := range []float64{t.DNSStart, t.ConnectStart, t.SendStart} {
+		if start >= 0 {
+			return start
+		}
+	}
+	return -1
+}
+
+// nonNegative clamps negative durations to zero.
+func nonNegative(ms float64) float64 {
+	if ms < 0 {
+		return 0
+	}
+	return ms
+}
+
+// monotonic converts a CDP monotonic timestamp, which may be missing.
+func monotonic(t *cdp.MonotonicTime) time.Time {
+	if t == nil {
+		return time.Now()
+	}
+	return t.Time()
+}
+
+// milliseconds converts a duration to fractional milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,69 @@
+// Package hook runs the commands users attach to page changes in watch and
+// scheduled modes, so a change can send a mail or a chat message without
+// glue scripts around sz.
+package hook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Change describes a page whose distilled content changed.
+type Change struct {
+	URL      string
+	Markdown string // The new version
+	Diff     string // Unified diff from the previous version
+	Output   string // File the new version was written to, if any
+}
+
+// Run runs command through the shell with the new markdown on stdin. The
+// command finds the page's URL in SZ_URL, a file holding the diff in
+// SZ_DIFF_FILE, and the output file, if any, in SZ_OUTPUT. The diff file is
+// removed once the command exits. What the command prints goes to out.
+func Run(ctx context.Context, command string, change Change, out io.Writer) error {
+	diffFile, err := os.CreateTemp("", "sz-change-*.diff")
+	if err != nil {
+		return fmt.Errorf("failed to write diff for change hook: %w", err)
+	}
+	defer func() { _ = os.Remove(diffFile.Name()) }()
+	if _, err := diffFile.WriteString(change.Diff); err != nil {
+		_ = diffFile.Close()
+		return fmt.Errorf("failed to write diff for change hook: %w", err)
+	}
+	if err := diffFile.Close(); err != nil {
+		return fmt.Errorf("failed to write diff for change hook: %w", err)
+	}
+
+	var stderr bytes.Buffer
+	cmd := shell(ctx, command)
+	cmd.Stdin = strings.NewReader(change.Markdown)
+	cmd.Stdout = out
+	cmd.Stderr = io.MultiWriter(out, &stderr)
+	cmd.Env = append(os.Environ(),
+		"SZ_URL="+change.URL,
+		"SZ_DIFF_FILE="+diffFile.Name(),
+		"SZ_OUTPUT="+change.Output,
+	)
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("change hook failed: %w: %s", err, msg)
+		}
+		return fmt.Errorf("change hook failed: %w", err)
+	}
+	return nil
+}
+
+// shell returns a command running a command line through the system shell,
+// so hooks can use pipes and quoting.
+func shell(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -0,0 +1,338 @@
+// Package learn identifies boilerplate shared across pages of a site by
+// structural hashing and turns it into site rules.
+package learn
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/jewell-lgtm/essenz/internal/config"
+	"github.com/jewell-lgtm/essenz/internal/filter"
+	"github.com/jewell-lgtm/essenz/internal/tree"
+)
+
+// SiteLearner accumulates pages from one site and finds subtrees repeated across them.
+type SiteLearner struct {
+	pages     []*page
+	threshold float64
+	minText   int
+}
+
+// page holds the hashes computed for one document.
+type page struct {
+	root   *tree.TextNode
+	hashes map[*tree.TextNode]uint64
+}
+
+// candidate is a repeated subtree together with how many pages contain it.
+type candidate struct {
+	hash  uint64
+	pages int
+	node  *tree.TextNode // Representative occurrence from the first page containing it
+}
+
+// NewSiteLearner creates a learner that reports subtrees present on at least
+// threshold (0-1] of the pages.
+func NewSiteLearner(threshold float64) *SiteLearner {
+	if threshold <= 0 || threshold > 1 {
+		threshold = 0.6
+	}
+	return &SiteLearner{
+		threshold: threshold,
+		minText:   10,
+	}
+}
+
+// AddPage hashes a page tree built with preserved attributes.
+func (l *SiteLearner) AddPage(root *tree.TextNode) {
+	p := &page{root: root, hashes: make(map[*tree.TextNode]uint64)}
+	hashSubtree(root, p.hashes)
+	l.pages = append(l.pages, p)
+}
+
+// Pages returns the number of pages added so far.
+func (l *SiteLearner) Pages() int {
+	return len(l.pages)
+}
+
+// Rules returns exclude rules for the repeated subtrees found across the pages.
+func (l *SiteLearner) Rules(host string) (*config.SiteRules, error) {
+	if len(l.pages) < 2 {
+		return nil, fmt.Errorf("at least 2 pages are needed to learn a site profile, got %d", len(l.pages))
+	}
+
+	repeated := l.repeatedHashes()
+	rules := &config.SiteRules{Host: host}
+
+	for _, c := range l.maximalCandidates(repeated) {
+		if selector := l.stableSelector(c.node, repeated); selector != "" {
+			if !containsString(rules.Exclude, selector) {
+				rules.Exclude = append(rules.Exclude, selector)
+			}
+		}
+	}
+
+	return rules, nil
+}
+
+// repeatedHashes returns subtree hashes that appear on enough pages.
+func (l *SiteLearner) repeatedHashes() map[uint64]*candidate {
+	counts := make(map[uint64]*candidate)
+	for _, p := range l.pages {
+		seen := make(map[uint64]bool)
+		walkElements(p.root, func(node *tree.TextNode) {
+			hash := p.hashes[node]
+			if seen[hash] {
+				return
+			}
+			seen[hash] = true
+			if c, ok := counts[hash]; ok {
+				c.pages++
+			} else {
+				counts[hash] = &candidate{hash: hash, pages: 1, node: node}
+			}
+		})
+	}
+
+	required := int(float64(len(l.pages))*l.threshold + 0.999)
+	if required < 2 {
+		required = 2
+	}
+
+	repeated := make(map[uint64]*candidate)
+	for hash, c := range counts {
+		if c.pages >= required && l.isBoilerplateCandidate(c.node) {
+			repeated[hash] = c
+		}
+	}
+	return repeated
+}
+
+// isBoilerplateCandidate rejects document scaffolding and trivially small subtrees.
+func (l *SiteLearner) isBoilerplateCandidate(node *tree.TextNode) bool {
+	switch strings.ToLower(node.Tag) {
+	case "html", "head", "body", "main", "article":
+		return false
+	}
+	for ancestor := node; ancestor != nil; ancestor = ancestor.Parent {
+		if strings.EqualFold(ancestor.Tag, "head") {
+			return false
+		}
+	}
+	return len(strings.TrimSpace(textContent(node))) >= l.minText
+}
+
+// maximalCandidates returns repeated subtrees that are not inside another repeated subtree.
+func (l *SiteLearner) maximalCandidates(repeated map[uint64]*candidate) []*candidate {
+	var result []*candidate
+	var current *page
+	var visit func(node *tree.TextNode)
+	visit = func(node *tree.TextNode) {
+		if c, ok := repeated[current.hashes[node]]; ok && node.Tag != "#text" {
+			result = append(result, &candidate{hash: c.hash, pages: c.pages, node: node})
+			return
+		}
+		for _, child := range node.Children {
+			visit(child)
+		}
+	}
+
+	// Walk each page so subtrees missing from the first page are still found
+	for _, p := range l.pages {
+		current = p
+		visit(p.root)
+	}
+
+	// Deduplicate by hash while keeping document order
+	seen := make(map[uint64]bool)
+	unique := result[:0]
+	for _, c := range result {
+		if !seen[c.hash] {
+			seen[c.hash] = true
+			unique = append(unique, c)
+		}
+	}
+	return unique
+}
+
+// stableSelector picks the most general selector that only matches repeated
+// subtrees on every page, or returns an empty string if none is safe.
+func (l *SiteLearner) stableSelector(node *tree.TextNode, repeated map[uint64]*candidate) string {
+	for _, selector := range selectorCandidates(node) {
+		compiled, err := filter.ParseSelector(selector)
+		if err != nil {
+			continue
+		}
+		if l.selectorIsSafe(compiled, repeated) {
+			return selector
+		}
+	}
+	return ""
+}
+
+// selectorIsSafe checks that every node the selector matches is repeated boilerplate.
+func (l *SiteLearner) selectorIsSafe(selector *filter.Selector, repeated map[uint64]*candidate) bool {
+	matched := 0
+	for _, p := range l.pages {
+		safe := true
+		walkElements(p.root, func(node *tree.TextNode) {
+			if !safe || !selector.Matches(node) {
+				return
+			}
+			if _, ok := repeated[p.hashes[node]]; !ok {
+				safe = false
+				return
+			}
+			matched++
+		})
+		if !safe {
+			return false
+		}
+	}
+	return matched > 0
+}
+
+// selectorCandidates lists selectors for a node from most to least general.
+func selectorCandidates(node *tree.TextNode) []string {
+	tag := strings.ToLower(node.Tag)
+	var candidates []string
+
+	if id := node.Attributes["id"]; isPlainIdent(id) {
+		candidates = append(candidates, tag+"#"+id)
+	}
+
+	var classes []string
+	for _, class := range strings.Fields(node.Attributes["class"]) {
+		if isPlainIdent(class) {
+			classes = append(classes, class)
+		}
+	}
+	if len(classes) > 0 {
+		candidates = append(candidates, tag+"."+strings.Join(classes, "."))
+	}
+
+	if role := node.Attributes["role"]; isPlainIdent(role) {
+		candidates = append(candidates, fmt.Sprintf("%s[role=%s]", tag, role))
+	}
+
+	return append(candidates, filter.SelectorPath(node))
+}
+
+// SameSiteLinks returns absolute links from the page that stay on base's host,
+// excluding base itself, in document order.
+func SameSiteLinks(root *tree.TextNode, base *url.URL) []string {
+	var links []string
+	seen := map[string]bool{stripFragment(base): true}
+
+	walkElements(root, func(node *tree.TextNode) {
+		if !strings.EqualFold(node.Tag, "a") {
+			return
+		}
+		href := strings.TrimSpace(node.Attributes["href"])
+		if href == "" || strings.HasPrefix(href, "#") {
+			return
+		}
+		resolved, err := base.Parse(href)
+		if err != nil || (resolved.Scheme != "http" && resolved.Scheme != "https") {
+			return
+		}
+		if !strings.EqualFold(resolved.Hostname(), base.Hostname()) {
+			return
+		}
+		link := stripFragment(resolved)
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	})
+
+	return links
+}
+
+// hashSubtree computes a structural hash for every node from its tag, identifying
+// attributes, text, and children, storing results in hashes.
+func hashSubtree(node *tree.TextNode, hashes map[*tree.TextNode]uint64) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(strings.ToLower(node.Tag)))
+
+	if node.Tag == "#text" {
+		_, _ = h.Write([]byte(strings.Join(strings.Fields(node.Text), " ")))
+	} else {
+		for _, key := range sortedKeys(node.Attributes) {
+			if key == "id" || key == "class" || key == "role" || key == "href" {
+				_, _ = h.Write([]byte("|" + key + "=" + node.Attributes[key]))
+			}
+		}
+	}
+
+	for _, child := range node.Children {
+		childHash := hashSubtree(child, hashes)
+		_, _ = h.Write([]byte(fmt.Sprintf("/%x", childHash)))
+	}
+
+	sum := h.Sum64()
+	hashes[node] = sum
+	return sum
+}
+
+func walkElements(node *tree.TextNode, fn func(*tree.TextNode)) {
+	if node == nil {
+		return
+	}
+	if node.Tag != "#text" && node.Tag != "document" {
+		fn(node)
+	}
+	for _, child := range node.Children {
+		walkElements(child, fn)
+	}
+}
+
+func textContent(node *tree.TextNode) string {
+	if node.Tag == "#text" {
+		return node.Text
+	}
+	var parts []string
+	for _, child := range node.Children {
+		parts = append(parts, textContent(child))
+	}
+	return strings.Join(parts, " ")
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isPlainIdent(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if !(r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
+			return false
+		}
+	}
+	return !(value[0] >= '0' && value[0] <= '9')
+}
+
+func stripFragment(u *url.URL) string {
+	clean := *u
+	clean.Fragment = ""
+	return clean.String()
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
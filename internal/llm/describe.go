@@ -0,0 +1,74 @@
+package llm
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// maxImageSize is the largest local image sent to the model.
+const maxImageSize = 20 << 20
+
+// describePrompt asks for alt text for one image.
+const describePrompt = `Write alt text for this image: one short sentence describing what it shows
+to a reader who cannot see it. Answer with the description only.`
+
+// contentPart is one piece of a message for models that take images.
+type contentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *imageRef `json:"image_url,omitempty"`
+}
+
+// imageRef points the model at an image.
+type imageRef struct {
+	URL string `json:"url"`
+}
+
+// DescribeImage asks the vision model for alt text for an image, given as an
+// http, https or data URL or as a local file, which is sent inline. The text
+// around the image on the page, if any, helps the model say what matters.
+func (c *Client) DescribeImage(ctx context.Context, image, surrounding string) (string, error) {
+	ref, err := imageURL(image)
+	if err != nil {
+		return "", err
+	}
+	prompt := describePrompt
+	if surrounding = strings.TrimSpace(surrounding); surrounding != "" {
+		prompt += "\n\nText around the image on the page: " + surrounding
+	}
+
+	description, err := c.complete(ctx, c.visionModel, []Message{{Role: "user", Content: []contentPart{
+		{Type: "text", Text: prompt},
+		{Type: "image_url", ImageURL: &imageRef{URL: ref}},
+	}}})
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(description, "\"' \n"), nil
+}
+
+// imageURL returns a URL the model can load an image from, inlining local
+// files as data URLs.
+func imageURL(image string) (string, error) {
+	if strings.HasPrefix(image, "http://") || strings.HasPrefix(image, "https://") || strings.HasPrefix(image, "data:") {
+		return image, nil
+	}
+	file, err := os.Open(strings.TrimPrefix(image, "file://"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read image: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	data, err := io.ReadAll(io.LimitReader(file, maxImageSize+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read image: %w", err)
+	}
+	if len(data) > maxImageSize {
+		return "", fmt.Errorf("image %s is larger than %d MiB", image, maxImageSize>>20)
+	}
+	return "data:" + http.DetectContentType(data) + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
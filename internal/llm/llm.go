@@ -0,0 +1,133 @@
+// Package llm sends distilled content to a language model over an
+// OpenAI-compatible chat completions API, for the features that summarize or
+// otherwise rewrite a page. The rest of sz never talks to a model.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jewell-lgtm/essenz/internal/config"
+)
+
+// requestTimeout bounds one completion; models can take a while on long pages.
+const requestTimeout = 2 * time.Minute
+
+// ErrNotConfigured is returned when config.yaml sets up no model.
+var ErrNotConfigured = errors.New("no language model is configured: set llm.endpoint and llm.model in config.yaml")
+
+// Client calls a chat completions endpoint.
+type Client struct {
+	endpoint    string
+	model       string
+	visionModel string
+	apiKey      string
+	maxTokens   int
+	prompt      string
+	http        *http.Client
+}
+
+// NewClient creates a client for the model set up in cfg. The API key in
+// ESSENZ_LLM_API_KEY takes precedence over the one in the config.
+func NewClient(cfg config.LLMConfig) (*Client, error) {
+	if cfg.Endpoint == "" || cfg.Model == "" {
+		return nil, ErrNotConfigured
+	}
+	apiKey := cfg.APIKey
+	if key := os.Getenv("ESSENZ_LLM_API_KEY"); key != "" {
+		apiKey = key
+	}
+	visionModel := cfg.VisionModel
+	if visionModel == "" {
+		visionModel = cfg.Model
+	}
+	return &Client{
+		endpoint:    strings.TrimRight(cfg.Endpoint, "/"),
+		model:       cfg.Model,
+		visionModel: visionModel,
+		apiKey:      apiKey,
+		maxTokens:   cfg.MaxTokens,
+		prompt:      cfg.Prompt,
+		http:        &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+// Message is one turn of a chat. Content is a string, or a list of parts for
+// models that take images.
+type Message struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"`
+}
+
+// chatRequest is the body of a chat completions request.
+type chatRequest struct {
+	Model     string    `json:"model"`
+	Messages  []Message `json:"messages"`
+	MaxTokens int       `json:"max_tokens,omitempty"`
+}
+
+// chatResponse is the part of a chat completions answer sz reads.
+type chatResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Complete sends messages to the model and returns its reply.
+func (c *Client) Complete(ctx context.Context, messages []Message) (string, error) {
+	return c.complete(ctx, c.model, messages)
+}
+
+// complete sends messages to the given model and returns its reply.
+func (c *Client) complete(ctx context.Context, model string, messages []Message) (string, error) {
+	body, err := json.Marshal(chatRequest{Model: model, Messages: messages, MaxTokens: c.maxTokens})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode model request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("model request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read model response: %w", err)
+	}
+	var answer chatResponse
+	if err := json.Unmarshal(data, &answer); err != nil {
+		return "", fmt.Errorf("model answered %s with invalid JSON: %w", resp.Status, err)
+	}
+	if answer.Error != nil {
+		return "", fmt.Errorf("model answered %s: %s", resp.Status, answer.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("model answered %s", resp.Status)
+	}
+	if len(answer.Choices) == 0 {
+		return "", errors.New("model returned no answer")
+	}
+	return strings.TrimSpace(answer.Choices[0].Message.Content), nil
+}
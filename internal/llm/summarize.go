@@ -0,0 +1,57 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// defaultPrompt asks for a summary when the config gives no prompt.
+const defaultPrompt = `Summarize the following article in one short paragraph followed by up to
+five bullet points with its key facts. Answer in the article's language, in
+markdown, without a heading.
+
+Title: {{.Title}}
+URL: {{.URL}}
+
+{{.Content}}`
+
+// Document is a distilled page handed to the model.
+type Document struct {
+	Title   string
+	URL     string
+	Content string // Distilled markdown
+}
+
+// Summarize asks the model for a summary of doc, using the prompt template
+// from the config or a default one.
+func (c *Client) Summarize(ctx context.Context, doc Document) (string, error) {
+	text := c.prompt
+	if text == "" {
+		text = defaultPrompt
+	}
+	tmpl, err := template.New("prompt").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid llm.prompt: %w", err)
+	}
+	var prompt strings.Builder
+	if err := tmpl.Execute(&prompt, doc); err != nil {
+		return "", fmt.Errorf("invalid llm.prompt: %w", err)
+	}
+
+	summary, err := c.Complete(ctx, []Message{{Role: "user", Content: prompt.String()}})
+	if err != nil {
+		return "", err
+	}
+	if summary == "" {
+		return "", fmt.Errorf("model returned an empty summary")
+	}
+	return summary, nil
+}
+
+// SummarySection formats a summary as a markdown section to put before the
+// content it summarizes.
+func SummarySection(summary string) string {
+	return "## Summary\n\n" + strings.TrimSpace(summary) + "\n\n"
+}
@@ -0,0 +1,19 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+)
+
+// translatePrompt asks for a translation that keeps the text's markup.
+const translatePrompt = `Translate the text below into the language with the code %q. Keep its line
+breaks, markdown syntax, URLs and code spans exactly as they are, and answer
+with the translation only.
+
+%s`
+
+// Translate asks the model to translate text into lang, keeping its line
+// breaks and markdown.
+func (c *Client) Translate(ctx context.Context, text, lang string) (string, error) {
+	return c.Complete(ctx, []Message{{Role: "user", Content: fmt.Sprintf(translatePrompt, lang, text)}})
+}
@@ -0,0 +1,82 @@
+// Package logging builds the slog loggers shared by the sz command, the
+// browser client, and the daemon, so one --log-level and --log-format
+// control them all. The settings travel to daemons in ESSENZ_LOG_LEVEL and
+// ESSENZ_LOG_FORMAT.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// Output formats.
+const (
+	FormatText = "text" // key=value lines
+	FormatJSON = "json" // One JSON object per line
+)
+
+// ParseLevel converts debug, info, warn, or error to a slog level.
+func ParseLevel(level string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q: use debug, info, warn, or error", level)
+	}
+	return l, nil
+}
+
+// ValidateFormat checks a --log-format value.
+func ValidateFormat(format string) error {
+	switch format {
+	case "", FormatText, FormatJSON:
+		return nil
+	}
+	return fmt.Errorf("invalid log format %q: use text or json", format)
+}
+
+// Level returns the level from ESSENZ_LOG_LEVEL, or fallback when it is unset
+// or invalid.
+func Level(fallback slog.Level) slog.Level {
+	if levelStr := os.Getenv("ESSENZ_LOG_LEVEL"); levelStr != "" {
+		if level, err := ParseLevel(levelStr); err == nil {
+			return level
+		}
+	}
+	return fallback
+}
+
+// Format returns the format from ESSENZ_LOG_FORMAT, or text.
+func Format() string {
+	if format := os.Getenv("ESSENZ_LOG_FORMAT"); format != "" && ValidateFormat(format) == nil {
+		return format
+	}
+	return FormatText
+}
+
+// New creates a logger writing to w in the configured format, at the
+// configured level or fallback.
+func New(w io.Writer, fallback slog.Level) *slog.Logger {
+	options := &slog.HandlerOptions{Level: Level(fallback)}
+	if Format() == FormatJSON {
+		return slog.New(slog.NewJSONHandler(w, options))
+	}
+	return slog.New(slog.NewTextHandler(w, options))
+}
+
+// NewCLI creates the logger for a command's own messages: warnings and
+// errors by default, without timestamps in text form since they go to a
+// terminal.
+func NewCLI(w io.Writer) *slog.Logger {
+	options := &slog.HandlerOptions{Level: Level(slog.LevelWarn)}
+	if Format() == FormatJSON {
+		return slog.New(slog.NewJSONHandler(w, options))
+	}
+	options.ReplaceAttr = func(groups []string, attr slog.Attr) slog.Attr {
+		if len(groups) == 0 && attr.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+		return attr
+	}
+	return slog.New(slog.NewTextHandler(w, options))
+}
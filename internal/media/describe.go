@@ -0,0 +1,69 @@
+package media
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// ImageDescriber writes a description of an image, such as a vision model
+// does, for images the page gives no alt or title text.
+type ImageDescriber interface {
+	// DescribeImage describes the image at an http, https or data URL, or in
+	// a local file, given the text around it on the page.
+	DescribeImage(ctx context.Context, image, context string) (string, error)
+}
+
+// WithDescriber has images without alt or title text described by
+// describer. When it fails, descriptions fall back to the words in the file
+// name and the surrounding text.
+func (mh *MediaHandler) WithDescriber(describer ImageDescriber) *MediaHandler {
+	mh.describer = describer
+	mh.descriptions = make(map[string]string)
+	return mh
+}
+
+// WithBaseURL sets the URL or file path of the page, against which relative
+// image sources are resolved before they are described.
+func (mh *MediaHandler) WithBaseURL(base string) *MediaHandler {
+	mh.baseURL = base
+	return mh
+}
+
+// describeImage asks the describer about an image, once per image.
+func (mh *MediaHandler) describeImage(ctx context.Context, src, context string) string {
+	if mh.describer == nil || strings.TrimSpace(src) == "" {
+		return ""
+	}
+	image := resolveSource(mh.baseURL, src)
+	if description, ok := mh.descriptions[image]; ok {
+		return description
+	}
+
+	description, err := mh.describer.DescribeImage(ctx, image, context)
+	if err != nil {
+		slog.Warn("could not describe image, describing it from its file name", "image", image, "error", err)
+	}
+	description = strings.TrimSpace(description)
+	mh.descriptions[image] = description
+	return description
+}
+
+// resolveSource resolves an image source against the page's URL, or against
+// the directory of a page read from a file.
+func resolveSource(base, src string) string {
+	ref, err := url.Parse(src)
+	if err != nil || ref.IsAbs() || base == "" {
+		return src
+	}
+	if strings.HasPrefix(base, "http://") || strings.HasPrefix(base, "https://") {
+		page, err := url.Parse(base)
+		if err != nil {
+			return src
+		}
+		return page.ResolveReference(ref).String()
+	}
+	return filepath.Join(filepath.Dir(base), filepath.FromSlash(ref.Path))
+}
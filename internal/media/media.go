@@ -13,10 +13,13 @@ import (
 
 // MediaHandler processes media elements in a content tree and replaces them with descriptive text.
 type MediaHandler struct {
-	config    MediaConfig
-	detectors []MediaDetector
-	generator *MediaMarkdownGenerator
-	analyzer  *ContextAnalyzer
+	config       MediaConfig
+	detectors    []MediaDetector
+	generator    *MediaMarkdownGenerator
+	analyzer     *ContextAnalyzer
+	describer    ImageDescriber
+	baseURL      string
+	descriptions map[string]string // Describer results by image, so each is asked about once
 }
 
 // MediaConfig configures the media handling behavior.
@@ -133,7 +136,7 @@ func (mh *MediaHandler) processNode(ctx context.Context, node *tree.TextNode) er
 
 	// Process current node if it's a media element
 	if mh.isMediaElement(node) {
-		replacement, err := mh.generateReplacement(node)
+		replacement, err := mh.generateReplacement(ctx, node)
 		if err != nil {
 			return fmt.Errorf("failed to generate media replacement: %w", err)
 		}
@@ -193,7 +196,7 @@ func (mh *MediaHandler) containsMediaChild(node *tree.TextNode) bool {
 }
 
 // generateReplacement generates a replacement string for a media element.
-func (mh *MediaHandler) generateReplacement(node *tree.TextNode) (string, error) {
+func (mh *MediaHandler) generateReplacement(ctx context.Context, node *tree.TextNode) (string, error) {
 	// Detect media type and extract information
 	var replacement MediaReplacement
 	var detected bool
@@ -203,7 +206,7 @@ func (mh *MediaHandler) generateReplacement(node *tree.TextNode) (string, error)
 			elements := detector.Extract(node)
 			if len(elements) > 0 {
 				element := elements[0] // Use first detected element
-				replacement = mh.createReplacement(element, node)
+				replacement = mh.createReplacement(ctx, element, node)
 				detected = true
 				break
 			}
@@ -220,7 +223,7 @@ func (mh *MediaHandler) generateReplacement(node *tree.TextNode) (string, error)
 }
 
 // createReplacement creates a MediaReplacement from a detected media element.
-func (mh *MediaHandler) createReplacement(element MediaElement, node *tree.TextNode) MediaReplacement {
+func (mh *MediaHandler) createReplacement(ctx context.Context, element MediaElement, node *tree.TextNode) MediaReplacement {
 	replacement := MediaReplacement{
 		Type:        element.Type,
 		Description: element.Description,
@@ -232,6 +235,11 @@ func (mh *MediaHandler) createReplacement(element MediaElement, node *tree.TextN
 	replacement.Context = mh.analyzer.ExtractContext(node)
 	replacement.Caption = mh.analyzer.FindAssociatedCaption(node)
 
+	// Have undescribed images described, by a vision model for instance
+	if replacement.Description == "" && replacement.Type == IMAGE {
+		replacement.Description = mh.describeImage(ctx, replacement.URL, replacement.Context)
+	}
+
 	// Enhance description if needed
 	if replacement.Description == "" && mh.config.GenerateDescriptions {
 		replacement.Description = mh.generateDescriptionFromContext(replacement.Context, replacement.URL)
@@ -0,0 +1,191 @@
+// Package merge combines several distilled pages into one markdown document:
+// a section per page, with its headings moved under the section's, a table of
+// contents and a line saying where each section came from.
+package merge
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Source is one distilled page to merge.
+type Source struct {
+	URL       string
+	Title     string
+	Author    string
+	SiteName  string
+	Published string
+	Markdown  string
+}
+
+// heading is a heading of the merged document, for the table of contents.
+type heading struct {
+	level  int
+	text   string
+	anchor string
+}
+
+// Document merges sources, in order, into a document with the given title.
+// Each source becomes a level-two section named after it, its own headings
+// shifted to sit below that; the table of contents lists the sections and
+// their level-three headings.
+func Document(title string, sources []Source) string {
+	anchors := anchorSet{"contents": 1}
+	anchors.take(title)
+
+	var body strings.Builder
+	var toc []heading
+	for _, source := range sources {
+		name := source.Title
+		if name == "" {
+			name = source.URL
+		}
+		section := heading{level: 2, text: name, anchor: anchors.take(name)}
+		toc = append(toc, section)
+
+		fmt.Fprintf(&body, "## %s\n\n%s\n\n", name, attribution(source))
+		inFence := false
+		for _, line := range shiftHeadings(source.Markdown, name) {
+			if isFence(line) {
+				inFence = !inFence
+			}
+			level, text := parseHeading(line)
+			switch {
+			case inFence || level == 0:
+			case level == 3:
+				toc = append(toc, heading{level: level, text: text, anchor: anchors.take(text)})
+			default:
+				// Deeper headings are left out of the contents but still
+				// number the anchors of later ones
+				anchors.take(text)
+			}
+			body.WriteString(line)
+			body.WriteString("\n")
+		}
+		body.WriteString("\n")
+	}
+
+	var doc strings.Builder
+	fmt.Fprintf(&doc, "# %s\n\n## Contents\n\n", title)
+	for _, entry := range toc {
+		indent := strings.Repeat("  ", entry.level-2)
+		fmt.Fprintf(&doc, "%s- [%s](#%s)\n", indent, entry.text, entry.anchor)
+	}
+	doc.WriteString("\n")
+	doc.WriteString(strings.TrimRight(body.String(), "\n"))
+	doc.WriteString("\n")
+	return doc.String()
+}
+
+// attribution is the line under a section's heading naming its source.
+func attribution(source Source) string {
+	site := source.SiteName
+	if site == "" {
+		if u, err := url.Parse(source.URL); err == nil && u.Host != "" {
+			site = strings.TrimPrefix(u.Host, "www.")
+		} else {
+			site = source.URL
+		}
+	}
+	parts := []string{fmt.Sprintf("[%s](%s)", site, source.URL)}
+	if source.Author != "" {
+		parts = append(parts, "by "+source.Author)
+	}
+	if published := date(source.Published); published != "" {
+		parts = append(parts, published)
+	}
+	return "*Source: " + strings.Join(parts, ", ") + "*"
+}
+
+// date shortens a timestamp to its day, leaving anything it cannot read as
+// it is.
+func date(value string) string {
+	for _, layout := range []string{time.RFC3339, time.DateTime, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format(time.DateOnly)
+		}
+	}
+	return value
+}
+
+// shiftHeadings returns a page's lines with its headings moved so the
+// highest sits at level three, below the section heading. A leading heading
+// that repeats the section's name is dropped. Fenced code is left alone.
+func shiftHeadings(markdown, name string) []string {
+	lines := strings.Split(strings.TrimSpace(markdown), "\n")
+	if _, text := parseHeading(lines[0]); strings.EqualFold(text, name) {
+		lines = lines[1:]
+		for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+			lines = lines[1:]
+		}
+	}
+
+	top := 0
+	inFence := false
+	for _, line := range lines {
+		if isFence(line) {
+			inFence = !inFence
+		}
+		if level, _ := parseHeading(line); !inFence && level > 0 && (top == 0 || level < top) {
+			top = level
+		}
+	}
+
+	shifted := make([]string, 0, len(lines))
+	inFence = false
+	for _, line := range lines {
+		if isFence(line) {
+			inFence = !inFence
+		}
+		if level, text := parseHeading(line); !inFence && level > 0 {
+			line = strings.Repeat("#", min(level-top+3, 6)) + " " + text
+		}
+		shifted = append(shifted, line)
+	}
+	return shifted
+}
+
+// parseHeading returns the level and text of an ATX heading line, or 0.
+func parseHeading(line string) (int, string) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || level == len(line) || line[level] != ' ' {
+		return 0, ""
+	}
+	return level, strings.TrimSpace(strings.TrimRight(line[level:], "#"))
+}
+
+// isFence reports whether a line opens or closes a fenced code block.
+func isFence(line string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~")
+}
+
+// anchorSet hands out the anchors markdown renderers such as GitHub's give
+// headings, numbering repeats.
+type anchorSet map[string]int
+
+// take returns the anchor for the next heading with the given text.
+func (a anchorSet) take(text string) string {
+	var slug strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			slug.WriteRune(r)
+		case r == ' ':
+			slug.WriteRune('-')
+		}
+	}
+	anchor := slug.String()
+	if n := a[anchor]; n > 0 {
+		a[anchor] = n + 1
+		return fmt.Sprintf("%s-%d", anchor, n)
+	}
+	a[anchor] = 1
+	return anchor
+}
@@ -0,0 +1,236 @@
+// Package metadata reads a page's descriptive metadata - title, author,
+// dates, canonical URL and the like - from its markup, without extracting the
+// content.
+package metadata
+
+import (
+	"encoding/json"
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Metadata describes a page.
+type Metadata struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Author      string `json:"author"`
+	Published   string `json:"published"`
+	Modified    string `json:"modified"`
+	Canonical   string `json:"canonical"`
+	AMP         string `json:"amp"`     // The page's AMP version, if it has one
+	Print       string `json:"print"`   // The page's print version, if it links to one
+	Refresh     string `json:"refresh"` // Where the page redirects to with a meta refresh
+	Language    string `json:"language"`
+	Image       string `json:"image"`
+	SiteName    string `json:"site_name"`
+	Feeds       []Feed `json:"feeds"`
+}
+
+// Feed is a feed the page advertises with <link rel="alternate">.
+type Feed struct {
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+	Type  string `json:"type"`
+}
+
+// feedTypes are the link types that mark a feed.
+var feedTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+	"application/json":      true,
+}
+
+// Empty reports whether the page gave neither a title nor a description,
+// which usually means its markup is rendered by script.
+func (m *Metadata) Empty() bool {
+	return m.Title == "" && m.Description == ""
+}
+
+// candidates collects values from several sources for each field; the first
+// source in a field's preference order that has a value wins.
+type candidates map[string]string
+
+func (c candidates) set(key, value string) {
+	value = strings.Join(strings.Fields(value), " ")
+	if _, ok := c[key]; !ok && value != "" {
+		c[key] = value
+	}
+}
+
+func (c candidates) first(keys ...string) string {
+	for _, key := range keys {
+		if value := c[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// Extract reads metadata from an HTML document fetched from pageURL, which
+// relative links are resolved against. Only the tokens that carry metadata
+// are looked at, so this is much cheaper than parsing the page.
+func Extract(htmlContent, pageURL string) *Metadata {
+	base, _ := url.Parse(pageURL)
+	found := candidates{}
+	meta := &Metadata{URL: pageURL, Feeds: []Feed{}}
+
+	tokenizer := html.NewTokenizer(strings.NewReader(htmlContent))
+	for {
+		kind := tokenizer.Next()
+		if kind == html.ErrorToken {
+			break
+		}
+		if kind != html.StartTagToken && kind != html.SelfClosingTagToken {
+			continue
+		}
+		token := tokenizer.Token()
+		attrs := make(map[string]string, len(token.Attr))
+		for _, a := range token.Attr {
+			attrs[strings.ToLower(a.Key)] = a.Val
+		}
+
+		switch token.DataAtom {
+		case atom.Html:
+			found.set("lang", attrs["lang"])
+		case atom.Title:
+			if tokenizer.Next() == html.TextToken {
+				found.set("title", string(tokenizer.Text()))
+			}
+		case atom.Meta:
+			name := strings.ToLower(attrs["name"])
+			if name == "" {
+				name = strings.ToLower(attrs["property"])
+			}
+			if name == "" {
+				name = "http-equiv:" + strings.ToLower(attrs["http-equiv"])
+			}
+			found.set(name, attrs["content"])
+		case atom.Link:
+			rels := strings.Fields(strings.ToLower(attrs["rel"]))
+			for _, rel := range rels {
+				switch rel {
+				case "canonical", "image_src", "amphtml":
+					found.set("link:"+rel, resolve(base, attrs["href"]))
+				case "print":
+					found.set("rel:print", resolve(base, attrs["href"]))
+				case "alternate":
+					if strings.Contains(strings.ToLower(attrs["media"]), "print") {
+						found.set("link:print", resolve(base, attrs["href"]))
+					}
+					kind := strings.ToLower(strings.TrimSpace(strings.Split(attrs["type"], ";")[0]))
+					if feedTypes[kind] && attrs["href"] != "" {
+						meta.Feeds = append(meta.Feeds, Feed{URL: resolve(base, attrs["href"]), Title: strings.TrimSpace(attrs["title"]), Type: kind})
+					}
+				}
+			}
+		case atom.A:
+			if slices.Contains(strings.Fields(strings.ToLower(attrs["rel"])), "print") {
+				found.set("rel:print", resolve(base, attrs["href"]))
+			} else if isPrintURL(attrs["href"]) {
+				found.set("a:print", resolve(base, attrs["href"]))
+			}
+		case atom.Script:
+			if strings.EqualFold(strings.TrimSpace(attrs["type"]), "application/ld+json") && tokenizer.Next() == html.TextToken {
+				linkedData(found, tokenizer.Text())
+			}
+		}
+	}
+
+	meta.Title = found.first("og:title", "twitter:title", "title", "ld:headline")
+	meta.Description = found.first("description", "og:description", "twitter:description", "ld:description")
+	meta.Author = found.first("author", "ld:author", "article:author", "twitter:creator", "dc.creator")
+	meta.Published = found.first("article:published_time", "ld:datepublished", "date", "dc.date", "pubdate", "publish-date")
+	meta.Modified = found.first("article:modified_time", "ld:datemodified", "og:updated_time", "last-modified")
+	meta.Canonical = resolve(base, found.first("link:canonical", "og:url"))
+	meta.AMP = found.first("link:amphtml")
+	meta.Print = found.first("link:print", "rel:print", "a:print")
+	meta.Refresh = resolve(base, refreshURL(found.first("http-equiv:refresh")))
+	meta.Language = found.first("lang", "http-equiv:content-language", "og:locale", "ld:inlanguage")
+	meta.Image = resolve(base, found.first("og:image", "og:image:url", "twitter:image", "link:image_src", "ld:image"))
+	meta.SiteName = found.first("og:site_name", "application-name", "ld:publisher")
+	if strings.Contains(meta.Author, "://") {
+		// article:author is often a profile URL rather than a name
+		meta.Author = found.first("author", "ld:author", "twitter:creator", "dc.creator")
+	}
+	return meta
+}
+
+// linkedData takes metadata from a JSON-LD block, looking at the first
+// object that has a headline or name.
+func linkedData(found candidates, data []byte) {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return
+	}
+
+	var objects []map[string]any
+	var collect func(v any)
+	collect = func(v any) {
+		switch v := v.(type) {
+		case []any:
+			for _, item := range v {
+				collect(item)
+			}
+		case map[string]any:
+			objects = append(objects, v)
+			collect(v["@graph"])
+		}
+	}
+	collect(value)
+
+	for _, object := range objects {
+		if ldString(object["headline"]) == "" && ldString(object["name"]) == "" {
+			continue
+		}
+		found.set("ld:headline", ldString(object["headline"]))
+		found.set("ld:description", ldString(object["description"]))
+		found.set("ld:author", ldString(object["author"]))
+		found.set("ld:datepublished", ldString(object["datePublished"]))
+		found.set("ld:datemodified", ldString(object["dateModified"]))
+		found.set("ld:inlanguage", ldString(object["inLanguage"]))
+		found.set("ld:image", ldString(object["image"]))
+		found.set("ld:publisher", ldString(object["publisher"]))
+	}
+}
+
+// ldString reads a JSON-LD value that may be a string, an object with a
+// name or url, or a list of either.
+func ldString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []any:
+		var names []string
+		for _, item := range v {
+			if name := ldString(item); name != "" {
+				names = append(names, name)
+			}
+		}
+		return strings.Join(names, ", ")
+	case map[string]any:
+		if name := ldString(v["name"]); name != "" {
+			return name
+		}
+		return ldString(v["url"])
+	}
+	return ""
+}
+
+// resolve makes href absolute against base.
+func resolve(base *url.URL, href string) string {
+	href = strings.TrimSpace(href)
+	if base == nil || href == "" {
+		return href
+	}
+	u, err := base.Parse(href)
+	if err != nil {
+		return href
+	}
+	return u.String()
+}
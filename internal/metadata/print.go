@@ -0,0 +1,42 @@
+package metadata
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// isPrintURL reports whether a link looks like it leads to a page's print
+// version: ?print=1, ?view=print, or a path such as /print/ or /article/print.
+func isPrintURL(href string) bool {
+	u, err := url.Parse(strings.TrimSpace(href))
+	if err != nil || href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		return false
+	}
+
+	for key, values := range u.Query() {
+		key = strings.ToLower(key)
+		for _, value := range values {
+			value = strings.ToLower(value)
+			switch key {
+			case "print", "printable", "printer_friendly":
+				if value != "0" && value != "false" && value != "no" {
+					return true
+				}
+			case "view", "format", "output", "mode", "layout", "template":
+				if value == "print" || value == "printable" {
+					return true
+				}
+			}
+		}
+	}
+
+	for _, segment := range strings.Split(u.Path, "/") {
+		segment = strings.ToLower(segment)
+		name := strings.TrimSuffix(segment, path.Ext(segment))
+		if name == "print" || name == "printable" || name == "print-version" || name == "printer-friendly" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package metadata
+
+import (
+	"strconv"
+	"strings"
+)
+
+// maxRedirectDelay is the longest delay, in seconds, of a meta refresh that
+// counts as a redirect. Longer ones are timeouts, such as a news site sending
+// an idle reader back to its front page.
+const maxRedirectDelay = 10
+
+// refreshURL returns the URL a <meta http-equiv="refresh"> content value such
+// as "0; url=/next" redirects to, or "" when it only reloads the page or waits
+// too long to be a redirect.
+func refreshURL(content string) string {
+	content = strings.TrimSpace(content)
+	digits := len(content) - len(strings.TrimLeft(content, "0123456789."))
+	delay, err := strconv.ParseFloat(content[:digits], 64)
+	if err != nil || delay > maxRedirectDelay {
+		return ""
+	}
+
+	rest := strings.TrimSpace(content[digits:])
+	if rest == "" || (rest[0] != ';' && rest[0] != ',') {
+		return ""
+	}
+	rest = strings.TrimSpace(rest[1:])
+	if len(rest) >= 3 && strings.EqualFold(rest[:3], "url") {
+		if after := strings.TrimSpace(rest[3:]); strings.HasPrefix(after, "=") {
+			rest = strings.TrimSpace(after[1:])
+		}
+	}
+	if rest != "" && (rest[0] == '\'' || rest[0] == '"') {
+		quote := rest[0]
+		rest = rest[1:]
+		if end := strings.IndexByte(rest, quote); end >= 0 {
+			rest = rest[:end]
+		}
+	}
+	return strings.TrimSpace(rest)
+}
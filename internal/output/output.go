@@ -0,0 +1,195 @@
+// Package output decides where sz writes results: a file path built from a
+// template such as {{.Host}}/{{.Slug}}.md, and what to do when that file
+// already exists.
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// maxNameLength keeps generated names well under filesystem limits.
+const maxNameLength = 100
+
+// Policy says what to do when an output file already exists.
+type Policy string
+
+// Policies for existing output files.
+const (
+	Overwrite Policy = "overwrite" // Replace the file
+	Skip      Policy = "skip"      // Keep the file and do not fetch the target again
+	Fail      Policy = "error"     // Report an error
+)
+
+// ParsePolicy validates an --if-exists value.
+func ParsePolicy(value string) (Policy, error) {
+	switch policy := Policy(value); policy {
+	case Overwrite, Skip, Fail:
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid --if-exists %q: use overwrite, skip, or error", value)
+}
+
+// Fields are the values a path template can use.
+type Fields struct {
+	Host  string // URL host with any port joined by a dash, or "local" for files and stdin
+	Path  string // URL path as directories, or the file's base name; "index" for the root
+	Slug  string // Host and path as one file name, e.g. example.com-docs-intro
+	Index int    // 1-based position of the target in a batch
+	Date  string // Today as YYYY-MM-DD
+}
+
+// NewFields describes the target at the given 1-based position.
+func NewFields(target string, index int) Fields {
+	fields := Fields{
+		Host:  "local",
+		Slug:  Slug(target),
+		Index: index,
+		Date:  time.Now().Format("2006-01-02"),
+	}
+
+	if target == "-" {
+		fields.Path, fields.Slug = "stdin", "stdin"
+		return fields
+	}
+
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		fields.Path = cleanName(strings.TrimSuffix(filepath.Base(target), filepath.Ext(target)))
+		return fields
+	}
+
+	fields.Host = cleanName(u.Host)
+	var segments []string
+	for _, segment := range strings.Split(u.Path, "/") {
+		if name := cleanName(segment); name != "" {
+			segments = append(segments, name)
+		}
+	}
+	fields.Path = strings.Join(segments, "/")
+	if fields.Path == "" {
+		fields.Path = "index"
+	}
+	return fields
+}
+
+// Template builds output paths. A template without {{ is a literal path.
+type Template struct {
+	tmpl *template.Template
+	used map[string]int
+}
+
+// ParseTemplate parses a path template.
+func ParseTemplate(text string) (*Template, error) {
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --output template: %w", err)
+	}
+	return &Template{tmpl: tmpl, used: make(map[string]int)}, nil
+}
+
+// Path renders the template for fields. When an earlier call produced the
+// same path, a numeric suffix is added before the extension so results do
+// not overwrite each other.
+func (t *Template) Path(fields Fields) (string, error) {
+	var b bytes.Buffer
+	if err := t.tmpl.Execute(&b, fields); err != nil {
+		return "", fmt.Errorf("invalid --output template: %w", err)
+	}
+	path := filepath.Clean(filepath.FromSlash(b.String()))
+	if path == "." || strings.HasSuffix(b.String(), "/") {
+		return "", fmt.Errorf("--output template %q renders to a directory, not a file", t.tmpl.Root.String())
+	}
+
+	t.used[path]++
+	if count := t.used[path]; count > 1 {
+		ext := filepath.Ext(path)
+		path = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(path, ext), count, ext)
+	}
+	return path, nil
+}
+
+// Exists reports whether a file is already at path.
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Write saves content to path, creating its directories. An existing file
+// is replaced unless the policy is Fail. Callers check Skip before doing the
+// work, with Exists.
+func Write(path string, content []byte, policy Policy) error {
+	if policy == Fail && Exists(path) {
+		return fmt.Errorf("%s already exists", path)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// Slug turns a URL's host and path, or a file's base name, into a name made
+// of letters, digits, dots and dashes.
+func Slug(target string) string {
+	source := target
+	if u, err := url.Parse(target); err == nil && u.Host != "" {
+		source = u.Host + u.Path
+		if u.RawQuery != "" {
+			source += "-" + u.RawQuery
+		}
+	} else {
+		source = strings.TrimSuffix(filepath.Base(target), filepath.Ext(target))
+	}
+
+	slug := cleanName(source)
+	if slug == "" {
+		slug = "page"
+	}
+	return slug
+}
+
+// SectionPath returns the file in dir for one section of a document split
+// into total sections: its 1-based index, zero-padded so the files sort in
+// order, and its title, as in dir/03-getting-started.md.
+func SectionPath(dir string, index, total int, title string) string {
+	name := cleanName(title)
+	if name == "" {
+		name = "section"
+	}
+	width := max(2, len(strconv.Itoa(total)))
+	return filepath.Join(dir, fmt.Sprintf("%0*d-%s.md", width, index, name))
+}
+
+// cleanName keeps letters, digits and dots, joining everything else into
+// single dashes, so the result is safe as one path element on any system.
+func cleanName(source string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(source) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.':
+			b.WriteRune(r)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	name := strings.Trim(b.String(), "-.")
+	if len(name) > maxNameLength {
+		name = strings.TrimRight(name[:maxNameLength], "-.")
+	}
+	return name
+}
@@ -3,10 +3,12 @@ package pageready
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/chromedp/cdproto/runtime"
 	"github.com/chromedp/chromedp"
 )
 
@@ -15,7 +17,15 @@ type ReadinessChecker struct {
 	MaxWaitTime     time.Duration
 	FrameworkHints  []string
 	CustomSelectors []string
+	CustomTexts     []string
+	Route           string // Hash route such as "#/article/5" to wait for
+	LayoutStable    bool   // Wait until layout shifts and reflows stop
+	NetworkIdle     bool   // Wait until no new network requests start
 	Debug           bool
+
+	// StageTimeouts caps individual stages; a stage that exceeds its budget
+	// is skipped and the result is marked partial instead of failing.
+	StageTimeouts map[string]time.Duration
 }
 
 // ReadinessResult contains information about page readiness detection.
@@ -25,6 +35,8 @@ type ReadinessResult struct {
 	WaitTime  time.Duration
 	Error     error
 	DebugInfo string
+	Stages    []StageTiming
+	Partial   bool // A stage ran out of its budget and was skipped
 }
 
 // NewReadinessChecker creates a new readiness checker with default settings.
@@ -33,6 +45,7 @@ func NewReadinessChecker() *ReadinessChecker {
 		MaxWaitTime:     5 * time.Second,
 		FrameworkHints:  []string{},
 		CustomSelectors: []string{},
+		CustomTexts:     []string{},
 		Debug:           false,
 	}
 }
@@ -55,6 +68,12 @@ func (r *ReadinessChecker) WithCustomSelectors(selectors []string) *ReadinessChe
 	return r
 }
 
+// WithCustomTexts sets text phrases that must appear in the page body.
+func (r *ReadinessChecker) WithCustomTexts(texts []string) *ReadinessChecker {
+	r.CustomTexts = texts
+	return r
+}
+
 // WithDebug enables debug information collection.
 func (r *ReadinessChecker) WithDebug(debug bool) *ReadinessChecker {
 	r.Debug = debug
@@ -78,16 +97,44 @@ func (r *ReadinessChecker) WaitForReady(ctx context.Context, chromeCtx context.C
 	}
 
 	// Start with basic DOM ready detection
-	err := r.waitForBasicDOMReady(timeoutCtx, chromeCtx, result)
+	err := r.timeStage(timeoutCtx, chromeCtx, result, StageDOM, func(ctx, chromeCtx context.Context) error {
+		return r.waitForBasicDOMReady(ctx, chromeCtx, result)
+	})
 	if err != nil {
 		result.Error = err
 		result.WaitTime = time.Since(start)
 		return result, err
 	}
 
+	// If the URL targets a client-side route, wait for the router to settle
+	if r.Route != "" {
+		err = r.timeStage(timeoutCtx, chromeCtx, result, StageRoute, func(ctx, chromeCtx context.Context) error {
+			return r.waitForRouteChange(ctx, chromeCtx, result)
+		})
+		if err != nil {
+			result.Error = err
+			result.WaitTime = time.Since(start)
+			return result, err
+		}
+	}
+
 	// If we have custom selectors, wait for them
 	if len(r.CustomSelectors) > 0 {
-		err = r.waitForCustomSelectors(timeoutCtx, chromeCtx, result)
+		err = r.timeStage(timeoutCtx, chromeCtx, result, StageSelector, func(ctx, chromeCtx context.Context) error {
+			return r.waitForCustomSelectors(ctx, chromeCtx, result)
+		})
+		if err != nil {
+			result.Error = err
+			result.WaitTime = time.Since(start)
+			return result, err
+		}
+	}
+
+	// If we have custom texts, wait for them
+	if len(r.CustomTexts) > 0 {
+		err = r.timeStage(timeoutCtx, chromeCtx, result, StageText, func(ctx, chromeCtx context.Context) error {
+			return r.waitForCustomTexts(ctx, chromeCtx, result)
+		})
 		if err != nil {
 			result.Error = err
 			result.WaitTime = time.Since(start)
@@ -97,7 +144,9 @@ func (r *ReadinessChecker) WaitForReady(ctx context.Context, chromeCtx context.C
 
 	// If we have framework hints, try to detect framework readiness
 	if len(r.FrameworkHints) > 0 {
-		err = r.waitForFrameworkReady(timeoutCtx, chromeCtx, result)
+		err = r.timeStage(timeoutCtx, chromeCtx, result, StageFramework, func(ctx, chromeCtx context.Context) error {
+			return r.waitForFrameworkReady(ctx, chromeCtx, result)
+		})
 		if err != nil {
 			// Framework detection failure is not fatal - continue with basic readiness
 			if r.Debug {
@@ -106,6 +155,30 @@ func (r *ReadinessChecker) WaitForReady(ctx context.Context, chromeCtx context.C
 		}
 	}
 
+	// Wait for in-flight network requests to finish
+	if r.NetworkIdle {
+		err = r.timeStage(timeoutCtx, chromeCtx, result, StageNetworkIdle, func(ctx, chromeCtx context.Context) error {
+			return r.waitForNetworkIdle(ctx, chromeCtx, result)
+		})
+		if err != nil {
+			result.Error = err
+			result.WaitTime = time.Since(start)
+			return result, err
+		}
+	}
+
+	// Finally wait for the page to stop reflowing
+	if r.LayoutStable {
+		err = r.timeStage(timeoutCtx, chromeCtx, result, StageLayout, func(ctx, chromeCtx context.Context) error {
+			return r.waitForLayoutStable(ctx, chromeCtx, result)
+		})
+		if err != nil {
+			result.Error = err
+			result.WaitTime = time.Since(start)
+			return result, err
+		}
+	}
+
 	result.IsReady = true
 	result.WaitTime = time.Since(start)
 
@@ -156,6 +229,43 @@ func (r *ReadinessChecker) waitForCustomSelectors(_ context.Context, chromeCtx c
 	return nil
 }
 
+// waitForCustomTexts polls the page body until each custom text appears.
+func (r *ReadinessChecker) waitForCustomTexts(ctx context.Context, chromeCtx context.Context, result *ReadinessResult) error {
+	for _, text := range r.CustomTexts {
+		// JSON encoding yields a valid JavaScript string literal
+		literal, err := json.Marshal(text)
+		if err != nil {
+			return fmt.Errorf("invalid custom text '%s': %w", text, err)
+		}
+
+		for {
+			var found bool
+			err := chromedp.Run(chromeCtx,
+				chromedp.Evaluate(fmt.Sprintf(`document.body !== null && document.body.innerText.includes(%s)`, literal), &found),
+			)
+			if err != nil {
+				return fmt.Errorf("custom text '%s' check failed: %w", text, err)
+			}
+			if found {
+				break
+			}
+
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("custom text '%s' not found: %w", text, ctx.Err())
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+
+		if r.Debug {
+			result.DebugInfo += fmt.Sprintf("Custom text '%s' found; ", text)
+		}
+	}
+
+	result.EventType = "CustomText"
+	return nil
+}
+
 // waitForFrameworkReady attempts to detect JavaScript framework readiness.
 func (r *ReadinessChecker) waitForFrameworkReady(ctx context.Context, chromeCtx context.Context, result *ReadinessResult) error {
 	for _, hint := range r.FrameworkHints {
@@ -180,6 +290,31 @@ func (r *ReadinessChecker) waitForFrameworkReady(ctx context.Context, chromeCtx
 				result.EventType = "NextJSReady"
 				return nil
 			}
+		case "nuxt":
+			if err := r.waitForNuxtReady(ctx, chromeCtx, result); err == nil {
+				result.EventType = "NuxtReady"
+				return nil
+			}
+		case "svelte", "sveltekit":
+			if err := r.waitForSvelteReady(ctx, chromeCtx, result); err == nil {
+				result.EventType = "SvelteReady"
+				return nil
+			}
+		case "solid":
+			if err := r.waitForSolidReady(ctx, chromeCtx, result); err == nil {
+				result.EventType = "SolidReady"
+				return nil
+			}
+		case "ember":
+			if err := r.waitForEmberReady(ctx, chromeCtx, result); err == nil {
+				result.EventType = "EmberReady"
+				return nil
+			}
+		case "htmx":
+			if err := r.waitForHtmxReady(ctx, chromeCtx, result); err == nil {
+				result.EventType = "HtmxReady"
+				return nil
+			}
 		}
 	}
 
@@ -187,7 +322,7 @@ func (r *ReadinessChecker) waitForFrameworkReady(ctx context.Context, chromeCtx
 }
 
 // waitForReactReady waits for React app to be ready.
-func (r *ReadinessChecker) waitForReactReady(_ context.Context, chromeCtx context.Context, result *ReadinessResult) error {
+func (r *ReadinessChecker) waitForReactReady(ctx context.Context, chromeCtx context.Context, result *ReadinessResult) error {
 	var isReady bool
 
 	// Try multiple approaches to detect React readiness
@@ -230,8 +365,17 @@ func (r *ReadinessChecker) waitForReactReady(_ context.Context, chromeCtx contex
 		return fmt.Errorf("React not detected")
 	}
 
-	// Wait a bit for React hydration to complete
-	time.Sleep(500 * time.Millisecond)
+	// Wait for React to attach its root container
+	r.waitForHydration(ctx, chromeCtx, result, "React", `
+		(function() {
+			const candidates = [document.getElementById('root'), document.getElementById('app'), document.querySelector('[data-reactroot]')];
+			return candidates.some(function(el) {
+				return el && (el._reactRootContainer || Object.keys(el).some(function(key) {
+					return key.startsWith('__reactContainer$') || key.startsWith('__reactFiber$');
+				}));
+			});
+		})();
+	`)
 
 	if r.Debug {
 		result.DebugInfo += "React framework detected; "
@@ -322,7 +466,7 @@ func (r *ReadinessChecker) waitForAngularReady(_ context.Context, chromeCtx cont
 }
 
 // waitForNextJSReady waits for Next.js app to be ready.
-func (r *ReadinessChecker) waitForNextJSReady(_ context.Context, chromeCtx context.Context, result *ReadinessResult) error {
+func (r *ReadinessChecker) waitForNextJSReady(ctx context.Context, chromeCtx context.Context, result *ReadinessResult) error {
 	var isReady bool
 
 	err := chromedp.Run(chromeCtx,
@@ -362,8 +506,15 @@ func (r *ReadinessChecker) waitForNextJSReady(_ context.Context, chromeCtx conte
 		return fmt.Errorf("Next.js not detected")
 	}
 
-	// Wait for hydration to complete
-	time.Sleep(1 * time.Second)
+	// Next.js assigns window.next once the client runtime has hydrated
+	r.waitForHydration(ctx, chromeCtx, result, "Next.js", `
+		(function() {
+			if (!window.next) {
+				return false;
+			}
+			return !window.next.router || window.next.router.isReady !== false;
+		})();
+	`)
 
 	if r.Debug {
 		result.DebugInfo += "Next.js framework detected; "
@@ -371,3 +522,211 @@ func (r *ReadinessChecker) waitForNextJSReady(_ context.Context, chromeCtx conte
 
 	return nil
 }
+
+// waitForNuxtReady waits for a Nuxt app to be mounted.
+func (r *ReadinessChecker) waitForNuxtReady(ctx context.Context, chromeCtx context.Context, result *ReadinessResult) error {
+	detected, err := r.detectFramework(chromeCtx, `
+		(function() {
+			return !!(window.__NUXT__ || window.$nuxt || document.getElementById('__nuxt'));
+		})();
+	`)
+	if err != nil {
+		return fmt.Errorf("Nuxt detection failed: %w", err)
+	}
+	if !detected {
+		return fmt.Errorf("Nuxt not detected")
+	}
+
+	// Nuxt 2 flags the root instance as mounted; Nuxt 3 attaches the Vue app to #__nuxt
+	r.waitForHydration(ctx, chromeCtx, result, "Nuxt", `
+		(function() {
+			if (window.$nuxt && window.$nuxt.$root && window.$nuxt.$root._isMounted) {
+				return true;
+			}
+			const root = document.getElementById('__nuxt');
+			return !!(root && root.__vue_app__);
+		})();
+	`)
+
+	if r.Debug {
+		result.DebugInfo += "Nuxt framework detected; "
+	}
+
+	return nil
+}
+
+// waitForSvelteReady waits for a Svelte or SvelteKit app to be ready.
+func (r *ReadinessChecker) waitForSvelteReady(ctx context.Context, chromeCtx context.Context, result *ReadinessResult) error {
+	detected, err := r.detectFramework(chromeCtx, `
+		(function() {
+			// Svelte scopes component styles with svelte-<hash> classes
+			if (document.querySelector('[class*="svelte-"]')) {
+				return true;
+			}
+
+			// SvelteKit marks the document and exposes __sveltekit globals
+			if (document.querySelector('[data-sveltekit-preload-data], [data-sveltekit-hydrate]')) {
+				return true;
+			}
+			return Object.keys(window).some(function(key) {
+				return key.startsWith('__sveltekit');
+			});
+		})();
+	`)
+	if err != nil {
+		return fmt.Errorf("Svelte detection failed: %w", err)
+	}
+	if !detected {
+		return fmt.Errorf("Svelte not detected")
+	}
+
+	// Svelte has no global hydration flag, so wait for the next rendered frame
+	r.waitForHydration(ctx, chromeCtx, result, "Svelte", nextFrameScript)
+
+	if r.Debug {
+		result.DebugInfo += "Svelte framework detected; "
+	}
+
+	return nil
+}
+
+// waitForSolidReady waits for a SolidJS app to finish hydrating.
+func (r *ReadinessChecker) waitForSolidReady(ctx context.Context, chromeCtx context.Context, result *ReadinessResult) error {
+	detected, err := r.detectFramework(chromeCtx, `
+		(function() {
+			// _$HY is Solid's hydration registry; data-hk marks hydration keys
+			return !!(window._$HY || document.querySelector('[data-hk]'));
+		})();
+	`)
+	if err != nil {
+		return fmt.Errorf("SolidJS detection failed: %w", err)
+	}
+	if !detected {
+		return fmt.Errorf("SolidJS not detected")
+	}
+
+	r.waitForHydration(ctx, chromeCtx, result, "SolidJS", `
+		(function() {
+			return !window._$HY || window._$HY.done === true;
+		})();
+	`)
+
+	if r.Debug {
+		result.DebugInfo += "SolidJS framework detected; "
+	}
+
+	return nil
+}
+
+// waitForEmberReady waits for an Ember app to settle its run loop.
+func (r *ReadinessChecker) waitForEmberReady(ctx context.Context, chromeCtx context.Context, result *ReadinessResult) error {
+	detected, err := r.detectFramework(chromeCtx, `
+		(function() {
+			return !!(window.Ember || document.querySelector('.ember-application, .ember-view'));
+		})();
+	`)
+	if err != nil {
+		return fmt.Errorf("Ember detection failed: %w", err)
+	}
+	if !detected {
+		return fmt.Errorf("Ember not detected")
+	}
+
+	r.waitForHydration(ctx, chromeCtx, result, "Ember", `
+		(function() {
+			if (!document.querySelector('.ember-application')) {
+				return false;
+			}
+			if (window.Ember && Ember.run && Ember.run.hasScheduledTimers) {
+				return !Ember.run.hasScheduledTimers();
+			}
+			return true;
+		})();
+	`)
+
+	if r.Debug {
+		result.DebugInfo += "Ember framework detected; "
+	}
+
+	return nil
+}
+
+// waitForHtmxReady waits for in-flight htmx requests to complete.
+func (r *ReadinessChecker) waitForHtmxReady(ctx context.Context, chromeCtx context.Context, result *ReadinessResult) error {
+	detected, err := r.detectFramework(chromeCtx, `
+		(function() {
+			return !!(window.htmx || document.querySelector('[hx-get], [hx-post], [data-hx-get], [data-hx-post]'));
+		})();
+	`)
+	if err != nil {
+		return fmt.Errorf("htmx detection failed: %w", err)
+	}
+	if !detected {
+		return fmt.Errorf("htmx not detected")
+	}
+
+	// htmx adds the htmx-request class to elements while their request is in flight
+	r.waitForHydration(ctx, chromeCtx, result, "htmx", `
+		(function() {
+			return document.querySelector('.htmx-request') === null;
+		})();
+	`)
+
+	if r.Debug {
+		result.DebugInfo += "htmx detected; "
+	}
+
+	return nil
+}
+
+// hydrationTimeout caps how long a detected framework may take to signal readiness.
+const hydrationTimeout = 3 * time.Second
+
+// nextFrameScript resolves once the browser has rendered the next frame.
+const nextFrameScript = `
+	new Promise(function(resolve) {
+		requestAnimationFrame(function() {
+			requestAnimationFrame(function() { resolve(true); });
+		});
+	});
+`
+
+// detectFramework evaluates a detection script that returns a boolean.
+func (r *ReadinessChecker) detectFramework(chromeCtx context.Context, script string) (bool, error) {
+	var detected bool
+	err := chromedp.Run(chromeCtx,
+		chromedp.EvaluateAsDevTools(script, &detected),
+	)
+	return detected, err
+}
+
+// waitForHydration polls a readiness script until it returns true. Timing out is
+// not fatal: the framework was detected, so extraction proceeds with what rendered.
+func (r *ReadinessChecker) waitForHydration(ctx context.Context, chromeCtx context.Context, result *ReadinessResult, framework, script string) {
+	hydrationCtx, cancel := context.WithTimeout(ctx, hydrationTimeout)
+	defer cancel()
+
+	for {
+		var ready bool
+		err := chromedp.Run(chromeCtx,
+			chromedp.EvaluateAsDevTools(script, &ready, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+				return p.WithAwaitPromise(true)
+			}),
+		)
+		if err == nil && ready {
+			if r.Debug {
+				result.DebugInfo += fmt.Sprintf("%s hydration complete; ", framework)
+			}
+			return
+		}
+
+		select {
+		case <-hydrationCtx.Done():
+			if r.Debug {
+				result.DebugInfo += fmt.Sprintf("%s hydration signal not seen; ", framework)
+			}
+			return
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package pageready
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// EvaluateScripts runs user scripts in the page in order before extraction.
+// Scripts that return a promise are awaited, so async manipulation completes first.
+func EvaluateScripts(chromeCtx context.Context, scripts []string) error {
+	for i, script := range scripts {
+		err := chromedp.Run(chromeCtx,
+			chromedp.Evaluate(script, nil, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+				return p.WithAwaitPromise(true)
+			}),
+		)
+		if err != nil {
+			return fmt.Errorf("eval script %d failed: %w", i+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package pageready
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// layoutQuietPeriod is how long the layout must stay still to count as stable.
+const layoutQuietPeriod = 500 * time.Millisecond
+
+// layoutObserverScript records the time of the latest layout shift and document
+// resize. Buffered layout-shift entries include shifts from before installation.
+const layoutObserverScript = `
+(function() {
+	if (window.__szLayout) {
+		return true;
+	}
+	const state = window.__szLayout = { lastShift: 0, shifts: 0, score: 0 };
+	const mark = function() {
+		state.lastShift = performance.now();
+	};
+
+	if (window.PerformanceObserver && PerformanceObserver.supportedEntryTypes &&
+		PerformanceObserver.supportedEntryTypes.includes('layout-shift')) {
+		new PerformanceObserver(function(list) {
+			list.getEntries().forEach(function(entry) {
+				state.shifts++;
+				state.score += entry.value;
+				state.lastShift = Math.max(state.lastShift, entry.startTime);
+			});
+		}).observe({ type: 'layout-shift', buffered: true });
+	}
+
+	// Content appended below the fold reflows the page without a layout shift entry
+	if (window.ResizeObserver && document.body) {
+		new ResizeObserver(mark).observe(document.body);
+	}
+	mark();
+	return true;
+})();
+`
+
+// WithLayoutStability makes the checker wait until the page stops reflowing.
+func (r *ReadinessChecker) WithLayoutStability(wait bool) *ReadinessChecker {
+	r.LayoutStable = wait
+	return r
+}
+
+// waitForLayoutStable waits until no layout shift or document resize has happened
+// for the quiet period.
+func (r *ReadinessChecker) waitForLayoutStable(ctx context.Context, chromeCtx context.Context, result *ReadinessResult) error {
+	if err := chromedp.Run(chromeCtx, chromedp.Evaluate(layoutObserverScript, nil)); err != nil {
+		return fmt.Errorf("layout observer installation failed: %w", err)
+	}
+
+	script := fmt.Sprintf(`
+		(function() {
+			const state = window.__szLayout;
+			return {
+				stable: !!state && performance.now() - state.lastShift >= %d,
+				shifts: state ? state.shifts : 0,
+				score: state ? state.score : 0
+			};
+		})();
+	`, layoutQuietPeriod.Milliseconds())
+
+	var status struct {
+		Stable bool    `json:"stable"`
+		Shifts int     `json:"shifts"`
+		Score  float64 `json:"score"`
+	}
+
+	for {
+		err := chromedp.Run(chromeCtx,
+			chromedp.Evaluate(script, &status),
+		)
+		if err != nil {
+			return fmt.Errorf("layout stability check failed: %w", err)
+		}
+		if status.Stable {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("layout did not stabilize: %w", ctx.Err())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	result.EventType = "LayoutStable"
+
+	if r.Debug {
+		result.DebugInfo += fmt.Sprintf("Layout stable after %d shifts (CLS %.3f); ", status.Shifts, status.Score)
+	}
+
+	return nil
+}
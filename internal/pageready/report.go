@@ -0,0 +1,73 @@
+package pageready
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// StageTiming records how long one readiness strategy took and whether it succeeded.
+type StageTiming struct {
+	Name       string  `json:"name"`
+	DurationMS float64 `json:"duration_ms"`
+	Success    bool    `json:"success"`
+	TimedOut   bool    `json:"timed_out,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// readinessReport is the JSON form of a ReadinessResult.
+type readinessReport struct {
+	IsReady    bool          `json:"is_ready"`
+	EventType  string        `json:"event_type"`
+	WaitTimeMS float64       `json:"wait_time_ms"`
+	Partial    bool          `json:"partial"`
+	Error      string        `json:"error,omitempty"`
+	DebugInfo  string        `json:"debug_info,omitempty"`
+	Stages     []StageTiming `json:"stages"`
+}
+
+// MarshalJSON encodes the result with millisecond durations and a string error.
+func (r ReadinessResult) MarshalJSON() ([]byte, error) {
+	report := readinessReport{
+		IsReady:    r.IsReady,
+		EventType:  r.EventType,
+		WaitTimeMS: milliseconds(r.WaitTime),
+		Partial:    r.Partial,
+		DebugInfo:  r.DebugInfo,
+		Stages:     r.Stages,
+	}
+	if r.Error != nil {
+		report.Error = r.Error.Error()
+	}
+	if report.Stages == nil {
+		report.Stages = []StageTiming{}
+	}
+	return json.Marshal(report)
+}
+
+// UnmarshalJSON decodes a result produced by MarshalJSON.
+func (r *ReadinessResult) UnmarshalJSON(data []byte) error {
+	var report readinessReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return fmt.Errorf("invalid readiness result: %w", err)
+	}
+
+	*r = ReadinessResult{
+		IsReady:   report.IsReady,
+		EventType: report.EventType,
+		WaitTime:  time.Duration(report.WaitTimeMS * float64(time.Millisecond)),
+		DebugInfo: report.DebugInfo,
+		Stages:    report.Stages,
+		Partial:   report.Partial,
+	}
+	if report.Error != "" {
+		r.Error = errors.New(report.Error)
+	}
+	return nil
+}
+
+// milliseconds converts a duration to fractional milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
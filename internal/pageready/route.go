@@ -0,0 +1,124 @@
+package pageready
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// routeQuietPeriod is how long the DOM must stay unchanged after the last route change.
+const routeQuietPeriod = 300 * time.Millisecond
+
+// RouteHookScript must be installed before navigation. It wraps the history API and
+// records when the client-side router last changed the route or touched the DOM.
+const RouteHookScript = `
+(function() {
+	if (window.__szRoute) {
+		return;
+	}
+	const state = window.__szRoute = { lastChange: 0, lastMutation: 0, changes: 0 };
+	const markChange = function() {
+		state.lastChange = performance.now();
+		state.changes++;
+	};
+
+	['pushState', 'replaceState'].forEach(function(name) {
+		const original = history[name];
+		history[name] = function() {
+			const result = original.apply(this, arguments);
+			markChange();
+			return result;
+		};
+	});
+	window.addEventListener('hashchange', markChange);
+	window.addEventListener('popstate', markChange);
+
+	const observe = function() {
+		new MutationObserver(function() {
+			state.lastMutation = performance.now();
+		}).observe(document.documentElement, { childList: true, subtree: true, characterData: true });
+	};
+	if (document.documentElement) {
+		observe();
+	} else {
+		document.addEventListener('DOMContentLoaded', observe);
+	}
+})();
+`
+
+// HashRoute returns the client-side route in a URL fragment such as "#/article/5"
+// or "#!/article/5", or an empty string for plain anchors and fragment-less URLs.
+func HashRoute(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Fragment == "" {
+		return ""
+	}
+
+	fragment := parsed.Fragment
+	if strings.HasPrefix(fragment, "/") || strings.HasPrefix(fragment, "!/") {
+		return "#" + fragment
+	}
+	return ""
+}
+
+// WithRoute sets the hash route the client-side router must settle on before extraction.
+func (r *ReadinessChecker) WithRoute(route string) *ReadinessChecker {
+	r.Route = route
+	return r
+}
+
+// waitForRouteChange waits until the page shows the expected hash route and the
+// router has stopped changing routes and mutating the DOM.
+func (r *ReadinessChecker) waitForRouteChange(ctx context.Context, chromeCtx context.Context, result *ReadinessResult) error {
+	// JSON encoding yields a valid JavaScript string literal
+	route, err := json.Marshal(r.Route)
+	if err != nil {
+		return fmt.Errorf("invalid route '%s': %w", r.Route, err)
+	}
+
+	script := fmt.Sprintf(`
+		(function() {
+			const state = window.__szRoute;
+			if (!state || document.readyState !== 'complete') {
+				return false;
+			}
+			if (decodeURI(location.hash) !== decodeURI(%s)) {
+				return false;
+			}
+			const lastActivity = Math.max(state.lastChange, state.lastMutation);
+			return performance.now() - lastActivity >= %d;
+		})();
+	`, route, routeQuietPeriod.Milliseconds())
+
+	for {
+		var settled bool
+		err := chromedp.Run(chromeCtx,
+			chromedp.Evaluate(script, &settled),
+		)
+		if err != nil {
+			return fmt.Errorf("route '%s' check failed: %w", r.Route, err)
+		}
+		if settled {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("route '%s' did not settle: %w", r.Route, ctx.Err())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	result.EventType = "RouteSettled"
+
+	if r.Debug {
+		result.DebugInfo += fmt.Sprintf("Route '%s' settled; ", r.Route)
+	}
+
+	return nil
+}
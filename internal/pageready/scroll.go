@@ -0,0 +1,126 @@
+package pageready
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// DefaultScrollMaxHeight caps auto-scrolling so infinite feeds cannot scroll forever.
+const DefaultScrollMaxHeight = 50000
+
+// Scroller scrolls a page before extraction to trigger lazy-loaded content.
+type Scroller struct {
+	Auto      bool          // Scroll incrementally until the page stops growing
+	Viewports int           // Number of viewports to scroll when not in auto mode
+	MaxHeight int           // Stop once this many pixels have been scrolled
+	StepDelay time.Duration // Pause after each step so lazy content can load
+}
+
+// scrollState is the page geometry reported after each scroll step.
+type scrollState struct {
+	ScrollY      float64 `json:"scrollY"`
+	InnerHeight  float64 `json:"innerHeight"`
+	ScrollHeight float64 `json:"scrollHeight"`
+}
+
+// ParseScroll parses a --scroll value: "auto" or a positive number of viewports.
+// An empty value returns a nil Scroller.
+func ParseScroll(value string) (*Scroller, error) {
+	value = strings.TrimSpace(strings.ToLower(value))
+	if value == "" {
+		return nil, nil
+	}
+
+	scroller := NewScroller()
+	if value == "auto" {
+		scroller.Auto = true
+		return scroller, nil
+	}
+
+	viewports, err := strconv.Atoi(value)
+	if err != nil || viewports <= 0 {
+		return nil, fmt.Errorf("invalid scroll value %q: use \"auto\" or a positive number of viewports", value)
+	}
+	scroller.Viewports = viewports
+	return scroller, nil
+}
+
+// NewScroller creates a new Scroller with default settings.
+func NewScroller() *Scroller {
+	return &Scroller{
+		MaxHeight: DefaultScrollMaxHeight,
+		StepDelay: 250 * time.Millisecond,
+	}
+}
+
+// WithMaxHeight sets the maximum number of pixels to scroll.
+func (s *Scroller) WithMaxHeight(maxHeight int) *Scroller {
+	if maxHeight > 0 {
+		s.MaxHeight = maxHeight
+	}
+	return s
+}
+
+// Scroll scrolls the page one viewport at a time until the configured limit is reached.
+func (s *Scroller) Scroll(ctx context.Context, chromeCtx context.Context) error {
+	// Auto mode stops after the height has been stable for this many steps
+	const stableSteps = 2
+
+	var state scrollState
+	stable := 0
+	lastHeight := -1.0
+
+	for step := 0; ; step++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if !s.Auto && step >= s.Viewports {
+			break
+		}
+
+		err := chromedp.Run(chromeCtx,
+			chromedp.Evaluate(`
+				(function() {
+					window.scrollBy(0, window.innerHeight);
+					return {
+						scrollY: window.scrollY,
+						innerHeight: window.innerHeight,
+						scrollHeight: document.documentElement.scrollHeight
+					};
+				})();
+			`, &state),
+			chromedp.Sleep(s.StepDelay),
+		)
+		if err != nil {
+			return fmt.Errorf("scroll step %d failed: %w", step+1, err)
+		}
+
+		if state.ScrollY >= float64(s.MaxHeight) {
+			break
+		}
+
+		if s.Auto {
+			atBottom := state.ScrollY+state.InnerHeight >= state.ScrollHeight-1
+			if atBottom && state.ScrollHeight == lastHeight {
+				stable++
+				if stable >= stableSteps {
+					break
+				}
+			} else {
+				stable = 0
+			}
+			lastHeight = state.ScrollHeight
+		}
+	}
+
+	// Return to the top so position-dependent layouts render normally
+	return chromedp.Run(chromeCtx, chromedp.Evaluate(`window.scrollTo(0, 0)`, nil))
+}
@@ -0,0 +1,158 @@
+package pageready
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Readiness stage names, used in reports and as keys for per-stage budgets.
+const (
+	StageDOM         = "dom"
+	StageRoute       = "route"
+	StageSelector    = "selector"
+	StageText        = "text"
+	StageFramework   = "framework"
+	StageNetworkIdle = "network-idle"
+	StageLayout      = "layout"
+)
+
+// stageNames lists every stage in the order WaitForReady runs them.
+var stageNames = []string{StageDOM, StageRoute, StageSelector, StageText, StageFramework, StageNetworkIdle, StageLayout}
+
+// networkQuietPeriod is how long no new resource may start loading to count as idle.
+const networkQuietPeriod = 500 * time.Millisecond
+
+// ParseStageTimeouts parses budgets written as "stage=duration", e.g. "framework=1s".
+func ParseStageTimeouts(values []string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	for _, value := range values {
+		name, raw, found := strings.Cut(value, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid stage timeout %q: expected stage=duration", value)
+		}
+
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !containsStage(name) {
+			return nil, fmt.Errorf("unknown readiness stage %q: valid stages are %s", name, strings.Join(stageNames, ", "))
+		}
+
+		timeout, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid timeout for stage %q: %s", name, raw)
+		}
+		timeouts[name] = timeout
+	}
+	return timeouts, nil
+}
+
+// WithStageTimeout sets the budget for a single readiness stage.
+func (r *ReadinessChecker) WithStageTimeout(stage string, timeout time.Duration) *ReadinessChecker {
+	if r.StageTimeouts == nil {
+		r.StageTimeouts = make(map[string]time.Duration)
+	}
+	r.StageTimeouts[stage] = timeout
+	return r
+}
+
+// WithNetworkIdle makes the checker wait until the page stops starting new requests.
+func (r *ReadinessChecker) WithNetworkIdle(wait bool) *ReadinessChecker {
+	r.NetworkIdle = wait
+	return r
+}
+
+// timeStage runs one readiness strategy and records its timing in the result.
+// When the stage has its own budget and exceeds it, the stage is abandoned, the
+// result is marked partial, and nil is returned so later stages still run.
+func (r *ReadinessChecker) timeStage(ctx context.Context, chromeCtx context.Context, result *ReadinessResult, name string, stage func(ctx, chromeCtx context.Context) error) error {
+	stageCtx, stageChromeCtx := ctx, chromeCtx
+	budget, hasBudget := r.StageTimeouts[name]
+	if hasBudget {
+		var cancel, chromeCancel context.CancelFunc
+		stageCtx, cancel = context.WithTimeout(ctx, budget)
+		defer cancel()
+		stageChromeCtx, chromeCancel = context.WithTimeout(chromeCtx, budget)
+		defer chromeCancel()
+	}
+
+	start := time.Now()
+	err := stage(stageCtx, stageChromeCtx)
+
+	timing := StageTiming{
+		Name:       name,
+		DurationMS: milliseconds(time.Since(start)),
+		Success:    err == nil,
+	}
+	if err != nil {
+		timing.Error = err.Error()
+	}
+
+	// Only the stage's own budget expiring counts as a partial result
+	budgetExpired := hasBudget && err != nil && ctx.Err() == nil &&
+		(errors.Is(stageCtx.Err(), context.DeadlineExceeded) || errors.Is(stageChromeCtx.Err(), context.DeadlineExceeded))
+	if budgetExpired {
+		timing.TimedOut = true
+		result.Partial = true
+		if r.Debug {
+			result.DebugInfo += fmt.Sprintf("Stage %s exceeded its %v budget; ", name, budget)
+		}
+		err = nil
+	}
+
+	result.Stages = append(result.Stages, timing)
+	return err
+}
+
+// waitForNetworkIdle waits until no new resource has started loading for the quiet period.
+func (r *ReadinessChecker) waitForNetworkIdle(ctx context.Context, chromeCtx context.Context, result *ReadinessResult) error {
+	script := fmt.Sprintf(`
+		(function() {
+			const entries = performance.getEntriesByType('resource');
+			const last = entries.reduce(function(latest, entry) {
+				return Math.max(latest, entry.responseEnd || entry.startTime);
+			}, 0);
+			return document.readyState === 'complete' && performance.now() - last >= %d;
+		})();
+	`, networkQuietPeriod.Milliseconds())
+
+	for {
+		var idle bool
+		err := chromedp.Run(chromeCtx,
+			chromedp.Evaluate(script, &idle),
+		)
+		if err != nil {
+			return fmt.Errorf("network idle check failed: %w", err)
+		}
+		if idle {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("network did not become idle: %w", ctx.Err())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	result.EventType = "NetworkIdle"
+
+	if r.Debug {
+		result.DebugInfo += "Network idle; "
+	}
+
+	return nil
+}
+
+// containsStage checks if a name is a known readiness stage.
+func containsStage(name string) bool {
+	for _, stage := range stageNames {
+		if stage == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+// Package paginate follows articles split across several pages: it finds the
+// link to the next page and stitches the pages' distilled markdown back into
+// one document.
+package paginate
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// pageOf matches a "Page 2 of 5" indicator.
+var pageOf = regexp.MustCompile(`(?i)\bpage\s+(\d+)\s+(?:of|/)\s+(\d+)\b`)
+
+// pagerHints are the class and id fragments of elements holding pagination
+// controls.
+var pagerHints = []string{"pagination", "pager", "paging", "page-nav", "pagenav", "page-links"}
+
+// arrows are the characters next and previous links decorate their text with.
+const arrows = "«»‹›←→<>"
+
+// Next returns the URL of the page after the one in htmlContent, fetched from
+// pageURL, or "" when there is none. It looks for a rel=next link, then for a
+// "next" link among pagination controls, then, on a page that says "Page 2 of
+// 5", for a link to page 3.
+func Next(htmlContent, pageURL string) string {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return ""
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+
+	var relNext, pagerNext, numbered string
+	var anchors []*html.Node
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && (n.DataAtom == atom.Link || n.DataAtom == atom.A) {
+			rels := strings.Fields(strings.ToLower(attr(n, "rel")))
+			if relNext == "" && slices.Contains(rels, "next") {
+				relNext = resolve(base, attr(n, "href"))
+			}
+			if n.DataAtom == atom.A {
+				anchors = append(anchors, n)
+				if pagerNext == "" && isNextText(text(n)) && inPager(n) {
+					pagerNext = resolve(base, attr(n, "href"))
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+
+	if match := pageOf.FindStringSubmatch(text(doc)); match != nil {
+		current, _ := strconv.Atoi(match[1])
+		total, _ := strconv.Atoi(match[2])
+		if current < total {
+			want := strconv.Itoa(current + 1)
+			for _, a := range anchors {
+				if strings.TrimSpace(text(a)) == want {
+					numbered = resolve(base, attr(a, "href"))
+					break
+				}
+			}
+		}
+	}
+
+	self := resolve(base, "")
+	for _, next := range []string{relNext, pagerNext, numbered} {
+		if next != "" && next != self {
+			return next
+		}
+	}
+	return ""
+}
+
+// isNextText reports whether a link's text says it leads to the next page:
+// "Next", "Next page" or a bare forward arrow, with or without arrows around.
+func isNextText(s string) bool {
+	s = strings.ToLower(strings.TrimSpace(s))
+	word := strings.Trim(s, arrows+" ")
+	if word == "" {
+		return strings.ContainsAny(s, "»›→>")
+	}
+	return word == "next" || word == "next page"
+}
+
+// inPager reports whether n sits inside an element that looks like
+// pagination controls.
+func inPager(n *html.Node) bool {
+	for p := n; p != nil; p = p.Parent {
+		if p.Type != html.ElementNode {
+			continue
+		}
+		if p.DataAtom == atom.Nav && strings.Contains(strings.ToLower(attr(p, "aria-label")), "pag") {
+			return true
+		}
+		names := strings.ToLower(attr(p, "class") + " " + attr(p, "id"))
+		for _, hint := range pagerHints {
+			if strings.Contains(names, hint) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// attr returns an attribute of n, or "".
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// text returns the text n contains.
+func text(n *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			b.WriteString(n.Data)
+		case n.Type == html.ElementNode && (n.DataAtom == atom.Script || n.DataAtom == atom.Style):
+			return
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// resolve makes an http or https href absolute against base, without its
+// fragment, returning "" for anything else.
+func resolve(base *url.URL, href string) string {
+	u, err := base.Parse(strings.TrimSpace(href))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	u.Fragment = ""
+	return u.String()
+}
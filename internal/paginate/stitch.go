@@ -0,0 +1,89 @@
+package paginate
+
+import (
+	"regexp"
+	"strings"
+)
+
+// markdownLink matches a markdown link, keeping its text.
+var markdownLink = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+
+// pagerWords are the words pagination controls are made of, besides page
+// numbers.
+var pagerWords = map[string]bool{
+	"next": true, "previous": true, "prev": true, "first": true, "last": true,
+	"page": true, "of": true, "…": true, "...": true,
+	"«": true, "»": true, "‹": true, "›": true, "←": true, "→": true,
+}
+
+// Stitch joins the distilled markdown of consecutive pages of an article into
+// one document. The pagination controls are dropped, and so are the blocks at
+// the top of later pages that repeat the first page's, such as its title and
+// byline, and the blocks at the bottom of all but the last page that repeat
+// the last page's, such as a share footer.
+func Stitch(pages []string) string {
+	if len(pages) == 0 {
+		return ""
+	}
+	blocks := make([][]string, len(pages))
+	for i, page := range pages {
+		for _, block := range strings.Split(strings.TrimSpace(page), "\n\n") {
+			block = strings.TrimSpace(block)
+			if block != "" && !isPager(block) {
+				blocks[i] = append(blocks[i], block)
+			}
+		}
+	}
+
+	first, last := blocks[0], blocks[len(blocks)-1]
+	var kept []string
+	for i, page := range blocks {
+		if i > 0 {
+			page = page[commonPrefix(first, page):]
+		}
+		if i < len(blocks)-1 {
+			page = page[:len(page)-commonSuffix(last, page)]
+		}
+		kept = append(kept, page...)
+	}
+	return strings.Join(kept, "\n\n") + "\n"
+}
+
+// isPager reports whether a markdown block is pagination controls, such as
+// "Page 2 of 3" or "[1](…) 2 [3](…) [Next »](…)".
+func isPager(block string) bool {
+	words := strings.Fields(markdownLink.ReplaceAllString(block, " $1 "))
+	if len(words) == 0 {
+		return false
+	}
+	numbers := 0
+	for _, word := range words {
+		word = strings.ToLower(word)
+		switch {
+		case strings.Trim(word, "0123456789") == "":
+			numbers++
+		case !pagerWords[word]:
+			return false
+		}
+	}
+	// A lone number is more likely a year or a figure than a pager
+	return numbers >= 2 || numbers < len(words)
+}
+
+// commonPrefix returns how many leading blocks b repeats from a.
+func commonPrefix(a, b []string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// commonSuffix returns how many trailing blocks b repeats from a.
+func commonSuffix(a, b []string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[len(a)-1-n] == b[len(b)-1-n] {
+		n++
+	}
+	return n
+}
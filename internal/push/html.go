@@ -0,0 +1,105 @@
+package push
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Inline markdown the distiller writes, after HTML escaping.
+var (
+	imageSyntax  = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)`)
+	linkSyntax   = regexp.MustCompile(`\[([^\]]*)\]\(([^)\s]+)\)`)
+	codeSyntax   = regexp.MustCompile("`([^`]+)`")
+	strongSyntax = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*`)
+	emSyntax     = regexp.MustCompile(`\*(\S(?:[^*]*?\S)?)\*`)
+	itemSyntax   = regexp.MustCompile(`^\s*(?:[-*+]|(\d+)[.)])\s+`)
+)
+
+// HTML renders distilled markdown as simple HTML for services that take a
+// document's content: headings, paragraphs, lists, quotes, code blocks,
+// links, images and emphasis.
+func HTML(markdown string) string {
+	var out strings.Builder
+	var block []string
+	flush := func() {
+		if len(block) > 0 {
+			out.WriteString(renderBlock(block))
+			out.WriteString("\n")
+			block = nil
+		}
+	}
+
+	lines := strings.Split(markdown, "\n")
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			out.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(trimmed, "#"):
+			flush()
+			block = []string{trimmed}
+			flush()
+		default:
+			block = append(block, trimmed)
+		}
+	}
+	flush()
+	return out.String()
+}
+
+// renderBlock renders the lines of one block.
+func renderBlock(lines []string) string {
+	first := lines[0]
+	if level := strings.IndexFunc(first, func(r rune) bool { return r != '#' }); level > 0 && level <= 6 && strings.HasPrefix(first[level:], " ") {
+		tag := "h" + strconv.Itoa(level)
+		return "<" + tag + ">" + inline(strings.TrimSpace(first[level:])) + "</" + tag + ">"
+	}
+	if strings.HasPrefix(first, ">") {
+		quoted := make([]string, len(lines))
+		for i, line := range lines {
+			quoted[i] = strings.TrimSpace(strings.TrimPrefix(line, ">"))
+		}
+		return "<blockquote>" + renderBlock(quoted) + "</blockquote>"
+	}
+	if match := itemSyntax.FindStringSubmatch(first); match != nil {
+		tag := "ul"
+		if match[1] != "" {
+			tag = "ol"
+		}
+		var items strings.Builder
+		for _, line := range lines {
+			if loc := itemSyntax.FindStringIndex(line); loc != nil {
+				if items.Len() > 0 {
+					items.WriteString("</li>")
+				}
+				items.WriteString("<li>" + inline(line[loc[1]:]))
+				continue
+			}
+			items.WriteString(" " + inline(line))
+		}
+		return "<" + tag + ">" + items.String() + "</li></" + tag + ">"
+	}
+	for i, line := range lines {
+		lines[i] = inline(line)
+	}
+	return "<p>" + strings.Join(lines, "\n") + "</p>"
+}
+
+// inline escapes text and renders its inline markdown.
+func inline(text string) string {
+	text = html.EscapeString(text)
+	text = imageSyntax.ReplaceAllString(text, `<img src="$2" alt="$1">`)
+	text = linkSyntax.ReplaceAllString(text, `<a href="$2">$1</a>`)
+	text = codeSyntax.ReplaceAllString(text, "<code>$1</code>")
+	text = strongSyntax.ReplaceAllString(text, "<strong>$1</strong>")
+	return emSyntax.ReplaceAllString(text, "<em>$1</em>")
+}
@@ -0,0 +1,50 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jewell-lgtm/essenz/internal/config"
+)
+
+// Instapaper saves pages through the Instapaper Simple API, which takes a
+// URL, a title and a short description.
+type Instapaper struct {
+	endpoint string
+	username string
+	password string
+	http     *http.Client
+}
+
+func newInstapaper(cfg config.InstapaperConfig) *Instapaper {
+	return &Instapaper{
+		endpoint: endpointOr(cfg.Endpoint, "https://www.instapaper.com/api"),
+		username: cfg.Username,
+		password: cfg.Password,
+		http:     &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Push adds the page to the account's unread list.
+func (i *Instapaper) Push(ctx context.Context, page Page) error {
+	if len(page.Highlights) > 0 {
+		return ErrHighlightsUnsupported
+	}
+	if len(page.Tags) > 0 {
+		return errors.New("instapaper does not take tags")
+	}
+	form := url.Values{"url": {page.URL}, "title": {page.Title}}
+	if page.Description != "" {
+		form.Set("selection", page.Description)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.endpoint+"/add", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(i.username, i.password)
+	return send(i.http, req)
+}
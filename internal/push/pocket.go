@@ -0,0 +1,44 @@
+package push
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/jewell-lgtm/essenz/internal/config"
+)
+
+// Pocket saves pages to a Pocket list. Pocket fetches the page itself, so
+// only its URL, title and tags are sent.
+type Pocket struct {
+	endpoint    string
+	consumerKey string
+	accessToken string
+	http        *http.Client
+}
+
+func newPocket(cfg config.PocketConfig) *Pocket {
+	return &Pocket{
+		endpoint:    endpointOr(cfg.Endpoint, "https://getpocket.com/v3"),
+		consumerKey: cfg.ConsumerKey,
+		accessToken: cfg.AccessToken,
+		http:        &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Push adds the page to the list.
+func (p *Pocket) Push(ctx context.Context, page Page) error {
+	if len(page.Highlights) > 0 {
+		return ErrHighlightsUnsupported
+	}
+	body := map[string]string{
+		"url":          page.URL,
+		"title":        page.Title,
+		"consumer_key": p.consumerKey,
+		"access_token": p.accessToken,
+	}
+	if len(page.Tags) > 0 {
+		body["tags"] = strings.Join(page.Tags, ",")
+	}
+	return postJSON(ctx, p.http, p.endpoint+"/add", http.Header{"X-Accept": {"application/json"}}, body)
+}
@@ -0,0 +1,118 @@
+// Package push sends distilled pages to read-it-later services: Pocket,
+// Instapaper and Readwise Reader.
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jewell-lgtm/essenz/internal/config"
+)
+
+// requestTimeout bounds one call to a service.
+const requestTimeout = 30 * time.Second
+
+// Page is a distilled page to push.
+type Page struct {
+	URL         string
+	Title       string
+	Author      string
+	Published   string
+	Description string
+	Markdown    string
+	Tags        []string
+	Highlights  []string // Passages to save as highlights, for services that keep them
+}
+
+// Service is a read-it-later account pages are pushed to.
+type Service interface {
+	Push(ctx context.Context, page Page) error
+}
+
+// Services sz push knows.
+var Services = []string{"pocket", "instapaper", "readwise"}
+
+// ErrHighlightsUnsupported is returned for highlights pushed to a service
+// that does not keep them.
+var ErrHighlightsUnsupported = errors.New("only readwise keeps highlights")
+
+// New creates the service called name with the account in cfg.
+func New(name string, cfg config.PushConfig) (Service, error) {
+	switch name {
+	case "pocket":
+		if cfg.Pocket.ConsumerKey == "" || cfg.Pocket.AccessToken == "" {
+			return nil, notConfigured(name, "consumer_key and access_token")
+		}
+		return newPocket(cfg.Pocket), nil
+	case "instapaper":
+		if cfg.Instapaper.Username == "" {
+			return nil, notConfigured(name, "username and password")
+		}
+		return newInstapaper(cfg.Instapaper), nil
+	case "readwise":
+		if cfg.Readwise.Token == "" {
+			return nil, notConfigured(name, "token")
+		}
+		return newReadwise(cfg.Readwise), nil
+	}
+	return nil, fmt.Errorf("unknown service %q: use %s", name, strings.Join(Services, ", "))
+}
+
+// notConfigured reports a service with no account in config.yaml.
+func notConfigured(name, fields string) error {
+	return fmt.Errorf("%s is not configured: set push.%s.%s in config.yaml", name, name, strings.ReplaceAll(fields, " and ", " and push."+name+"."))
+}
+
+// endpointOr returns the configured endpoint without a trailing slash, or
+// the service's own.
+func endpointOr(endpoint, fallback string) string {
+	if endpoint == "" {
+		return fallback
+	}
+	return strings.TrimRight(endpoint, "/")
+}
+
+// postJSON sends body as JSON and fails on any status but 200 or 201.
+func postJSON(ctx context.Context, client *http.Client, url string, header http.Header, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return send(client, req)
+}
+
+// send makes a request and fails on any status but 200 or 201, with what the
+// service said about it.
+func send(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated {
+		return nil
+	}
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if message := strings.TrimSpace(string(detail)); message != "" {
+		return fmt.Errorf("%s answered %s: %s", req.URL.Host, resp.Status, message)
+	}
+	if message := resp.Header.Get("X-Error"); message != "" {
+		return fmt.Errorf("%s answered %s: %s", req.URL.Host, resp.Status, message)
+	}
+	return fmt.Errorf("%s answered %s", req.URL.Host, resp.Status)
+}
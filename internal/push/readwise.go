@@ -0,0 +1,69 @@
+package push
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/jewell-lgtm/essenz/internal/config"
+)
+
+// Readwise saves pages to Readwise Reader with their distilled content, and
+// highlights to Readwise.
+type Readwise struct {
+	endpoint string
+	token    string
+	http     *http.Client
+}
+
+func newReadwise(cfg config.ReadwiseConfig) *Readwise {
+	return &Readwise{
+		endpoint: endpointOr(cfg.Endpoint, "https://readwise.io/api"),
+		token:    cfg.Token,
+		http:     &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// readerDocument is the body of a Reader save request.
+type readerDocument struct {
+	URL             string   `json:"url"`
+	HTML            string   `json:"html,omitempty"`
+	ShouldCleanHTML bool     `json:"should_clean_html"`
+	Title           string   `json:"title,omitempty"`
+	Author          string   `json:"author,omitempty"`
+	PublishedDate   string   `json:"published_date,omitempty"`
+	Summary         string   `json:"summary,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+	SavedUsing      string   `json:"saved_using"`
+}
+
+// highlight is one highlight in a Readwise highlights request.
+type highlight struct {
+	Text      string `json:"text"`
+	Title     string `json:"title,omitempty"`
+	Author    string `json:"author,omitempty"`
+	SourceURL string `json:"source_url"`
+	Category  string `json:"category"`
+}
+
+// Push saves the page to Reader, or with highlights, saves those to
+// Readwise under the page's title.
+func (r *Readwise) Push(ctx context.Context, page Page) error {
+	header := http.Header{"Authorization": {"Token " + r.token}}
+	if len(page.Highlights) > 0 {
+		highlights := make([]highlight, len(page.Highlights))
+		for i, text := range page.Highlights {
+			highlights[i] = highlight{Text: text, Title: page.Title, Author: page.Author, SourceURL: page.URL, Category: "articles"}
+		}
+		return postJSON(ctx, r.http, r.endpoint+"/v2/highlights/", header, map[string]any{"highlights": highlights})
+	}
+	return postJSON(ctx, r.http, r.endpoint+"/v3/save/", header, readerDocument{
+		URL:           page.URL,
+		HTML:          HTML(page.Markdown),
+		Title:         page.Title,
+		Author:        page.Author,
+		PublishedDate: page.Published,
+		Summary:       page.Description,
+		Tags:          page.Tags,
+		SavedUsing:    "essenz",
+	})
+}
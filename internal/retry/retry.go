@@ -0,0 +1,53 @@
+// Package retry runs an operation again when it fails for a transient reason,
+// such as a server error or a dropped connection, backing off exponentially
+// with jitter between attempts.
+package retry
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+)
+
+// maxDelay caps the wait between two attempts.
+const maxDelay = 30 * time.Second
+
+// Policy says how often to retry and how long to wait first. The zero Policy
+// never retries.
+type Policy struct {
+	Retries int           // Attempts after the first
+	Delay   time.Duration // Wait before the first retry, doubled for each one after
+}
+
+// Do runs fn, and runs it again while it fails with an error transient
+// accepts and retries remain. Each wait is the delay for that retry, give or
+// take up to half of it at random, so clients that failed together do not
+// all retry together. It returns fn's last error, or the context's when it is
+// done during a wait.
+func (p Policy) Do(ctx context.Context, transient func(error) bool, fn func() error) error {
+	delay := p.Delay
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt > p.Retries || !transient(err) {
+			return err
+		}
+
+		wait := jitter(delay)
+		slog.Warn("transient failure, retrying", "retry", attempt, "of", p.Retries, "wait", wait.Round(time.Millisecond), "error", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		delay = min(delay*2, maxDelay)
+	}
+}
+
+// jitter returns a random wait between half and one and a half times d.
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d)
+}
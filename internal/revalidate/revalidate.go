@@ -0,0 +1,54 @@
+// Package revalidate asks a server whether a page changed since it was last
+// fetched. The ETag and Last-Modified headers of the last response go back as
+// If-None-Match and If-Modified-Since, and a 304 Not Modified answer means the
+// page need not be fetched, rendered and distilled again.
+package revalidate
+
+import (
+	"net/http"
+)
+
+// Validators are what a response said to identify its version of a page.
+type Validators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// FromHeader returns the validators in a response's headers, or nil when it
+// has neither, so there is nothing to revalidate with.
+func FromHeader(header http.Header) *Validators {
+	validators := Validators{
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+	}
+	if validators == (Validators{}) {
+		return nil
+	}
+	return &validators
+}
+
+// Unchanged asks whether the page req is for is still the version v
+// identifies. It sends req as a HEAD request with v's conditions, so the
+// answer costs no body either way, and reports whether the server answered
+// 304 Not Modified. A nil v is never unchanged and sends nothing.
+func Unchanged(client *http.Client, req *http.Request, v *Validators) (bool, error) {
+	if v == nil {
+		return false, nil
+	}
+
+	req = req.Clone(req.Context())
+	req.Method = http.MethodHead
+	if v.ETag != "" {
+		req.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		req.Header.Set("If-Modified-Since", v.LastModified)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode == http.StatusNotModified, nil
+}
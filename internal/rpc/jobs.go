@@ -0,0 +1,218 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/status"
+
+	"github.com/jewell-lgtm/essenz/internal/metadata"
+)
+
+// Limits for processing a job and delivering its result.
+const (
+	jobTimeout       = 2 * time.Minute
+	callbackTimeout  = 30 * time.Second
+	callbackAttempts = 3
+	maxJobSize       = 10 << 20 // Largest job request body, HTML included
+)
+
+// Job is a request to distill a page in the background. Its result can be
+// polled for, and is posted to the callback URL when one is given.
+type Job struct {
+	ID           string  `json:"id"`
+	URL          string  `json:"url,omitempty"`
+	HTML         string  `json:"html,omitempty"` // Distilled instead of fetching URL
+	Callback     string  `json:"callback_url,omitempty"`
+	Options      Options `json:"options,omitempty"`
+	Raw          bool    `json:"raw,omitempty"`
+	WithComments bool    `json:"with_comments,omitempty"`
+}
+
+// Result is the outcome of a job, served by GET /v1/jobs/{id}/result and
+// posted to its callback URL.
+type Result struct {
+	ID       string             `json:"id"`
+	URL      string             `json:"url,omitempty"`
+	Status   string             `json:"status"` // StatusDone or StatusFailed
+	Markdown string             `json:"markdown,omitempty"`
+	Metadata *metadata.Metadata `json:"metadata,omitempty"`
+	Error    string             `json:"error,omitempty"`
+}
+
+// Handler serves the HTTP job API:
+//
+//	POST /v1/jobs             queue a Job, answering 202 with its JobStatus
+//	GET  /v1/jobs/{id}        the job's JobStatus
+//	GET  /v1/jobs/{id}/result the job's Result once it has finished
+//
+// Jobs are only taken once StartQueue has been called.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/jobs", s.submitJob)
+	mux.HandleFunc("GET /v1/jobs/{id}", s.jobStatus)
+	mux.HandleFunc("GET /v1/jobs/{id}/result", s.jobResult)
+	return mux
+}
+
+// StartQueue opens the job queue kept in dir, picking up the jobs left
+// unfinished by a previous run, and starts workers that process jobs until
+// ctx is done. A positive interval spaces out the start of jobs to at most
+// one per interval, across all workers.
+func (s *Server) StartQueue(ctx context.Context, dir string, workers int, interval time.Duration) error {
+	q, err := openQueue(dir, s.process)
+	if err != nil {
+		return err
+	}
+	q.start(ctx, workers, interval)
+	s.queue = q
+	return nil
+}
+
+// submitJob validates a job and queues it.
+func (s *Server) submitJob(w http.ResponseWriter, r *http.Request) {
+	if s.queue == nil {
+		writeError(w, http.StatusServiceUnavailable, "the job queue is not running")
+		return
+	}
+	var job Job
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxJobSize)).Decode(&job); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid job: %v", err))
+		return
+	}
+	if job.Callback != "" && !isWebURL(job.Callback) {
+		writeError(w, http.StatusBadRequest, "callback_url must be http or https")
+		return
+	}
+	if job.HTML == "" && !isWebURL(job.URL) {
+		writeError(w, http.StatusBadRequest, "url must be http or https, or html must be given")
+		return
+	}
+	job.ID = newJobID()
+
+	state, err := s.queue.submit(job)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, state)
+}
+
+// jobStatus reports how far a job has got.
+func (s *Server) jobStatus(w http.ResponseWriter, r *http.Request) {
+	state, _, err := s.lookup(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+// jobResult serves a finished job's result.
+func (s *Server) jobResult(w http.ResponseWriter, r *http.Request) {
+	state, result, err := s.lookup(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if result == nil {
+		writeError(w, http.StatusConflict, fmt.Sprintf("job %s is %s", state.ID, state.Status))
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// lookup finds a job in the queue.
+func (s *Server) lookup(id string) (JobStatus, *Result, error) {
+	if s.queue != nil {
+		if state, result, ok := s.queue.get(id); ok {
+			return state, result, nil
+		}
+	}
+	return JobStatus{}, nil, errors.New("no job " + id)
+}
+
+// process distills a job's page.
+func (s *Server) process(ctx context.Context, job Job) Result {
+	result := Result{ID: job.ID, URL: job.URL, Status: StatusDone}
+	meta, markdown, err := s.distill(ctx, job.URL, job.HTML, job.Options, job.Raw, job.WithComments)
+	if err != nil {
+		result.Status = StatusFailed
+		result.Error = status.Convert(err).Message()
+		return result
+	}
+	result.Markdown = markdown
+	result.Metadata = meta
+	return result
+}
+
+// deliver posts a result to a callback URL, retrying with backoff when the
+// callback cannot be reached or answers with a server error.
+func deliver(ctx context.Context, callback string, result *Result) error {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: callbackTimeout}
+	delay := time.Second
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, callback, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err == nil {
+			_ = resp.Body.Close()
+			if resp.StatusCode < 300 {
+				return nil
+			}
+			err = fmt.Errorf("callback answered %s", resp.Status)
+			if resp.StatusCode < 500 {
+				return err
+			}
+		}
+		if attempt == callbackAttempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// writeJSON answers with a JSON value.
+func writeJSON(w http.ResponseWriter, code int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// writeError answers with a JSON error message.
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, map[string]string{"error": message})
+}
+
+// newJobID returns a random job ID.
+func newJobID() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// logJob returns a logger for a job's progress.
+func logJob(job Job) *slog.Logger {
+	return slog.With("job", job.ID, "url", job.URL)
+}
@@ -0,0 +1,296 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// jobRetention is how long finished jobs and their results are kept.
+const jobRetention = 24 * time.Hour
+
+// Job statuses.
+const (
+	StatusQueued  = "queued"
+	StatusRunning = "running"
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+)
+
+// JobStatus is how far a job has got.
+type JobStatus struct {
+	ID        string     `json:"id"`
+	URL       string     `json:"url,omitempty"`
+	Status    string     `json:"status"`
+	Submitted time.Time  `json:"submitted"`
+	Started   *time.Time `json:"started,omitempty"`
+	Finished  *time.Time `json:"finished,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// record is a job as kept on disk, one file per job.
+type record struct {
+	Job    Job       `json:"job"`
+	State  JobStatus `json:"state"`
+	Result *Result   `json:"result,omitempty"`
+}
+
+// queue runs jobs in the order they were submitted, keeping every job on
+// disk so a restart picks up where the last run stopped.
+type queue struct {
+	dir     string
+	run     func(ctx context.Context, job Job) Result
+	mu      sync.Mutex
+	records map[string]*record
+	waiting []string      // IDs of queued jobs, oldest first
+	wake    chan struct{} // Signalled when a job is queued
+}
+
+// DefaultQueueDir returns where sz serve keeps its job queue: under the user
+// cache directory (XDG_CACHE_HOME on Linux), or the temp directory.
+func DefaultQueueDir() string {
+	if cacheDir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(cacheDir, "essenz", "jobs")
+	}
+	return filepath.Join(os.TempDir(), "essenz", "jobs")
+}
+
+// openQueue loads the jobs kept in dir. Jobs that were running when the last
+// run stopped are queued again; finished jobs past their retention are
+// removed.
+func openQueue(dir string, run func(ctx context.Context, job Job) Result) (*queue, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create job queue directory: %w", err)
+	}
+	q := &queue{
+		dir:     dir,
+		run:     run,
+		records: make(map[string]*record),
+		wake:    make(chan struct{}, 1),
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read job: %w", err)
+		}
+		var rec record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("failed to parse job %s: %w", path, err)
+		}
+		if rec.State.Finished != nil && time.Since(*rec.State.Finished) > jobRetention {
+			_ = os.Remove(path)
+			continue
+		}
+		if rec.State.Status == StatusRunning {
+			rec.State.Status = StatusQueued
+			rec.State.Started = nil
+		}
+		q.records[rec.Job.ID] = &rec
+		if rec.State.Status == StatusQueued {
+			q.waiting = append(q.waiting, rec.Job.ID)
+		}
+	}
+	sort.Slice(q.waiting, func(i, j int) bool {
+		return q.records[q.waiting[i]].State.Submitted.Before(q.records[q.waiting[j]].State.Submitted)
+	})
+	return q, nil
+}
+
+// submit saves a job and queues it.
+func (q *queue) submit(job Job) (JobStatus, error) {
+	rec := &record{Job: job, State: JobStatus{
+		ID:        job.ID,
+		URL:       job.URL,
+		Status:    StatusQueued,
+		Submitted: time.Now().UTC(),
+	}}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.save(rec); err != nil {
+		return JobStatus{}, err
+	}
+	q.records[job.ID] = rec
+	q.waiting = append(q.waiting, job.ID)
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return rec.State, nil
+}
+
+// get returns a job's status, and its result once it has finished.
+func (q *queue) get(id string) (JobStatus, *Result, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	rec, ok := q.records[id]
+	if !ok {
+		return JobStatus{}, nil, false
+	}
+	return rec.State, rec.Result, true
+}
+
+// start runs workers until ctx is done. A positive interval spaces out the
+// start of jobs across all workers; after a quiet spell the first job starts
+// at once.
+func (q *queue) start(ctx context.Context, workers int, interval time.Duration) {
+	var limiter chan struct{}
+	if interval > 0 {
+		limiter = make(chan struct{}, 1)
+		limiter <- struct{}{}
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					select {
+					case limiter <- struct{}{}:
+					default:
+					}
+				}
+			}
+		}()
+	}
+	for i := 0; i < max(workers, 1); i++ {
+		go q.work(ctx, limiter)
+	}
+}
+
+// work takes jobs off the queue and runs them until ctx is done.
+func (q *queue) work(ctx context.Context, limiter <-chan struct{}) {
+	for {
+		if limiter != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-limiter:
+			}
+		}
+		rec, ok := q.next(ctx)
+		if !ok {
+			return
+		}
+		q.runJob(ctx, rec)
+	}
+}
+
+// next waits for a queued job and marks it running.
+func (q *queue) next(ctx context.Context) (*record, bool) {
+	for {
+		q.mu.Lock()
+		if len(q.waiting) > 0 {
+			id := q.waiting[0]
+			q.waiting = q.waiting[1:]
+			rec := q.records[id]
+			now := time.Now().UTC()
+			rec.State.Status = StatusRunning
+			rec.State.Started = &now
+			if err := q.save(rec); err != nil {
+				logJob(rec.Job).Warn("could not save job", "error", err)
+			}
+			// Let another worker see the jobs still waiting
+			if len(q.waiting) > 0 {
+				select {
+				case q.wake <- struct{}{}:
+				default:
+				}
+			}
+			q.mu.Unlock()
+			return rec, true
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-q.wake:
+		}
+	}
+}
+
+// runJob processes a job, records its result and delivers it to the
+// callback. A job cut short by shutdown is queued again for the next run.
+func (q *queue) runJob(ctx context.Context, rec *record) {
+	logger := logJob(rec.Job)
+	jobCtx, cancel := context.WithTimeout(ctx, jobTimeout)
+	result := q.run(jobCtx, rec.Job)
+	cancel()
+
+	q.mu.Lock()
+	if ctx.Err() != nil {
+		rec.State.Status = StatusQueued
+		rec.State.Started = nil
+		if err := q.save(rec); err != nil {
+			logger.Warn("could not save job", "error", err)
+		}
+		q.mu.Unlock()
+		return
+	}
+	now := time.Now().UTC()
+	rec.State.Status = result.Status
+	rec.State.Finished = &now
+	rec.State.Error = result.Error
+	rec.Result = &result
+	if err := q.save(rec); err != nil {
+		logger.Warn("could not save job", "error", err)
+	}
+	q.prune()
+	q.mu.Unlock()
+
+	if result.Status == StatusFailed {
+		logger.Warn("job failed", "error", result.Error)
+	} else {
+		logger.Info("job done")
+	}
+	if rec.Job.Callback != "" {
+		if err := deliver(ctx, rec.Job.Callback, &result); err != nil {
+			logger.Error("could not deliver job result", "callback", rec.Job.Callback, "error", err)
+		}
+	}
+}
+
+// prune removes finished jobs past their retention. The caller holds q.mu.
+func (q *queue) prune() {
+	for id, rec := range q.records {
+		if rec.State.Finished != nil && time.Since(*rec.State.Finished) > jobRetention {
+			delete(q.records, id)
+			_ = os.Remove(q.path(id))
+		}
+	}
+}
+
+// save writes a job's file, replacing it whole so a crash never leaves half
+// a job behind. The caller holds q.mu.
+func (q *queue) save(rec *record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+	path := q.path(rec.Job.ID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write job: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write job: %w", err)
+	}
+	return nil
+}
+
+// path returns the file a job is kept in.
+func (q *queue) path(id string) string {
+	return filepath.Join(q.dir, id+".json")
+}
@@ -0,0 +1,171 @@
+// Package rpc serves the essenz network APIs: the gRPC API defined in
+// api/essenz/v1, which streams fetched HTML and distilled markdown back in
+// chunks, and HTTP jobs that post their result to a callback URL.
+package rpc
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	essenzv1 "github.com/jewell-lgtm/essenz/api/essenz/v1"
+	"github.com/jewell-lgtm/essenz/internal/extractor"
+	"github.com/jewell-lgtm/essenz/internal/metadata"
+)
+
+// chunkSize is the most text sent in one streamed message, well under gRPC's
+// default 4 MiB message limit.
+const chunkSize = 64 * 1024
+
+// Options are the per-request fetch settings a client can send.
+type Options struct {
+	WaitForSelector string            `json:"wait_for_selector,omitempty"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	UserAgent       string            `json:"user_agent,omitempty"`
+}
+
+// FetchFunc loads the HTML of an http or https URL.
+type FetchFunc func(ctx context.Context, url string, opts Options) (string, error)
+
+// Server implements essenzv1.ExtractServiceServer and the HTTP job API.
+type Server struct {
+	essenzv1.UnimplementedExtractServiceServer
+	fetch FetchFunc
+	queue *queue // Set by StartQueue
+}
+
+// NewServer creates a server that loads pages with fetch.
+func NewServer(fetch FetchFunc) *Server {
+	return &Server{fetch: fetch}
+}
+
+// Register adds the extraction service to a gRPC server.
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	essenzv1.RegisterExtractServiceServer(registrar, s)
+}
+
+// Fetch streams the HTML of a page.
+func (s *Server) Fetch(req *essenzv1.FetchRequest, stream grpc.ServerStreamingServer[essenzv1.FetchResponse]) error {
+	content, err := s.load(stream.Context(), req.GetUrl(), options(req.GetOptions()))
+	if err != nil {
+		return err
+	}
+	for _, chunk := range chunks(content) {
+		if err := stream.Send(&essenzv1.FetchResponse{Chunk: chunk}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Extract streams a page's metadata followed by its distilled markdown.
+func (s *Server) Extract(req *essenzv1.ExtractRequest, stream grpc.ServerStreamingServer[essenzv1.ExtractResponse]) error {
+	meta, markdown, err := s.distill(stream.Context(), req.GetUrl(), req.GetHtml(), options(req.GetOptions()), req.GetRaw(), req.GetWithComments())
+	if err != nil {
+		return err
+	}
+
+	if err := stream.Send(&essenzv1.ExtractResponse{Payload: &essenzv1.ExtractResponse_Metadata{Metadata: toProto(meta)}}); err != nil {
+		return err
+	}
+	for _, chunk := range chunks(markdown) {
+		if err := stream.Send(&essenzv1.ExtractResponse{Payload: &essenzv1.ExtractResponse_Chunk{Chunk: chunk}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// distill loads a page, unless its HTML is given, and returns its metadata
+// and reader view, or its HTML when raw is set.
+func (s *Server) distill(ctx context.Context, url, html string, opts Options, raw, withComments bool) (*metadata.Metadata, string, error) {
+	content := html
+	if content == "" {
+		var err error
+		if content, err = s.load(ctx, url, opts); err != nil {
+			return nil, "", err
+		}
+	}
+
+	meta := metadata.Extract(content, url)
+	if raw {
+		return meta, content, nil
+	}
+	markdown, err := extractor.New().WithComments(withComments).ExtractContent(content)
+	if err != nil {
+		return nil, "", status.Errorf(codes.Internal, "reader view extraction failed: %v", err)
+	}
+	return meta, markdown, nil
+}
+
+// load fetches a page, refusing anything but http and https so clients
+// cannot read the server's files.
+func (s *Server) load(ctx context.Context, url string, opts Options) (string, error) {
+	if !isWebURL(url) {
+		return "", status.Errorf(codes.InvalidArgument, "url must be http or https, got %q", url)
+	}
+	content, err := s.fetch(ctx, url, opts)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", status.FromContextError(ctx.Err()).Err()
+		}
+		return "", status.Errorf(codes.Unavailable, "fetching %s: %v", url, err)
+	}
+	return content, nil
+}
+
+// options reads the fetch options of an API request.
+func options(opts *essenzv1.FetchOptions) Options {
+	return Options{
+		WaitForSelector: opts.GetWaitForSelector(),
+		Headers:         opts.GetHeaders(),
+		UserAgent:       opts.GetUserAgent(),
+	}
+}
+
+// isWebURL reports whether url is an http or https URL.
+func isWebURL(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
+
+// chunks splits text into pieces of at most chunkSize bytes without cutting
+// a UTF-8 sequence, which proto strings must not contain half of.
+func chunks(text string) []string {
+	var parts []string
+	for len(text) > chunkSize {
+		end := chunkSize
+		for end > 0 && !utf8.RuneStart(text[end]) {
+			end--
+		}
+		parts = append(parts, text[:end])
+		text = text[end:]
+	}
+	if text != "" {
+		parts = append(parts, text)
+	}
+	return parts
+}
+
+// toProto converts page metadata to its API message.
+func toProto(meta *metadata.Metadata) *essenzv1.Metadata {
+	message := &essenzv1.Metadata{
+		Url:         meta.URL,
+		Title:       meta.Title,
+		Description: meta.Description,
+		Author:      meta.Author,
+		Published:   meta.Published,
+		Modified:    meta.Modified,
+		Canonical:   meta.Canonical,
+		Language:    meta.Language,
+		Image:       meta.Image,
+		SiteName:    meta.SiteName,
+	}
+	for _, feed := range meta.Feeds {
+		message.Feeds = append(message.Feeds, &essenzv1.Feed{Url: feed.URL, Title: feed.Title, Type: feed.Type})
+	}
+	return message
+}
@@ -0,0 +1,71 @@
+// Package search finds the lines of distilled markdown that match a pattern
+// and reports the headings each match falls under.
+package search
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Match is a matching line.
+type Match struct {
+	Line     int      // 1-based line number in the markdown
+	Text     string   // The line as it appears in the markdown
+	Headings []string // Titles of the enclosing headings, outermost first
+}
+
+// Section joins the enclosing headings into a path such as
+// "Guide > Installation".
+func (m Match) Section() string {
+	return strings.Join(m.Headings, " > ")
+}
+
+// Lines returns the lines of markdown matching pattern, in order. Heading
+// lines can match too; a heading's own path ends with its title. Lines inside
+// code fences are searched but never taken for headings.
+func Lines(markdown string, pattern *regexp.Regexp) []Match {
+	var matches []Match
+	var headings []string
+	inFence := false
+	for i, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		} else if !inFence {
+			if level, title, ok := heading(line); ok {
+				if len(headings) >= level {
+					headings = headings[:level-1]
+				}
+				for len(headings) < level-1 {
+					headings = append(headings, "")
+				}
+				headings = append(headings, title)
+			}
+		}
+		if pattern.MatchString(line) {
+			matches = append(matches, Match{Line: i + 1, Text: line, Headings: compact(headings)})
+		}
+	}
+	return matches
+}
+
+// heading reads an ATX heading line.
+func heading(line string) (level int, title string, ok bool) {
+	level = len(line) - len(strings.TrimLeft(line, "#"))
+	if level == 0 || level > 6 || (level < len(line) && line[level] != ' ') {
+		return 0, "", false
+	}
+	title = strings.TrimSpace(strings.TrimRight(strings.TrimSpace(line[level:]), "#"))
+	return level, title, title != ""
+}
+
+// compact copies headings without the gaps left by skipped levels.
+func compact(headings []string) []string {
+	var path []string
+	for _, title := range headings {
+		if title != "" {
+			path = append(path, title)
+		}
+	}
+	return path
+}
@@ -0,0 +1,200 @@
+// Package service installs the Chrome daemon as a per-user system service:
+// a systemd user unit on Linux or a launchd agent on macOS.
+package service
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Service describes the daemon instance to install.
+type Service struct {
+	Executable string            // Absolute path of the sz binary
+	Name       string            // Daemon name from --daemon-name; empty for the default daemon
+	Env        map[string]string // Environment the daemon runs with, such as ESSENZ_SOCKET
+}
+
+// Label returns the service name: essenz-daemon, or essenz-daemon-NAME for a named daemon.
+func (s Service) Label() string {
+	if s.Name == "" {
+		return "essenz-daemon"
+	}
+	return "essenz-daemon-" + s.Name
+}
+
+// args returns the command line that runs the daemon in the foreground.
+func (s Service) args() []string {
+	args := []string{s.Executable}
+	if s.Name != "" {
+		args = append(args, "--daemon-name", s.Name)
+	}
+	return append(args, "daemon", "start")
+}
+
+// SystemdUnit renders a systemd user unit that starts the daemon at login and
+// restarts it when it fails.
+func (s Service) SystemdUnit() string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	b.WriteString("Description=essenz Chrome daemon\n")
+	b.WriteString("After=network-online.target\n\n")
+	b.WriteString("[Service]\n")
+	b.WriteString("Type=simple\n")
+	b.WriteString("ExecStart=" + systemdQuote(s.args()) + "\n")
+	for _, key := range sortedKeys(s.Env) {
+		fmt.Fprintf(&b, "Environment=%s\n", systemdQuote([]string{key + "=" + s.Env[key]}))
+	}
+	b.WriteString("Restart=on-failure\n")
+	b.WriteString("RestartSec=5\n\n")
+	b.WriteString("[Install]\n")
+	b.WriteString("WantedBy=default.target\n")
+	return b.String()
+}
+
+// LaunchdPlist renders a launchd agent that starts the daemon at login and
+// restarts it when it exits with an error.
+func (s Service) LaunchdPlist() string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	b.WriteString(`<plist version="1.0">` + "\n<dict>\n")
+	fmt.Fprintf(&b, "\t<key>Label</key>\n\t<string>%s</string>\n", html.EscapeString(s.launchdLabel()))
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, arg := range s.args() {
+		fmt.Fprintf(&b, "\t\t<string>%s</string>\n", html.EscapeString(arg))
+	}
+	b.WriteString("\t</array>\n")
+	if len(s.Env) > 0 {
+		b.WriteString("\t<key>EnvironmentVariables</key>\n\t<dict>\n")
+		for _, key := range sortedKeys(s.Env) {
+			fmt.Fprintf(&b, "\t\t<key>%s</key>\n\t\t<string>%s</string>\n", html.EscapeString(key), html.EscapeString(s.Env[key]))
+		}
+		b.WriteString("\t</dict>\n")
+	}
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	b.WriteString("\t<key>KeepAlive</key>\n\t<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>\n")
+	b.WriteString("</dict>\n</plist>\n")
+	return b.String()
+}
+
+// launchdLabel returns the reverse-DNS label launchd identifies the agent by.
+func (s Service) launchdLabel() string {
+	return "io.essenz." + s.Label()
+}
+
+// Definition returns the service file for the current platform and where it is installed.
+func (s Service) Definition() (path, content string, err error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to determine home directory: %w", err)
+	}
+
+	switch runtime.GOOS {
+	case "linux":
+		dir := filepath.Join(home, ".config", "systemd", "user")
+		if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+			dir = filepath.Join(xdg, "systemd", "user")
+		}
+		return filepath.Join(dir, s.Label()+".service"), s.SystemdUnit(), nil
+	case "darwin":
+		return filepath.Join(home, "Library", "LaunchAgents", s.launchdLabel()+".plist"), s.LaunchdPlist(), nil
+	default:
+		return "", "", fmt.Errorf("service installation is not supported on %s: run sz daemon start from your login scripts instead", runtime.GOOS)
+	}
+}
+
+// Install writes the service file and, when start is true, registers it with
+// the service manager so it starts now and at every login.
+func (s Service) Install(start bool) (string, error) {
+	path, content, err := s.Definition()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create service directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write service file: %w", err)
+	}
+
+	if !start {
+		return path, nil
+	}
+	switch runtime.GOOS {
+	case "linux":
+		if err := run("systemctl", "--user", "daemon-reload"); err != nil {
+			return path, err
+		}
+		return path, run("systemctl", "--user", "enable", "--now", s.Label()+".service")
+	case "darwin":
+		return path, run("launchctl", "load", "-w", path)
+	}
+	return path, nil
+}
+
+// Uninstall stops the service and removes its file.
+func (s Service) Uninstall() (string, error) {
+	path, _, err := s.Definition()
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return "", fmt.Errorf("%s is not installed (no %s)", s.Label(), path)
+	}
+
+	// Stopping fails if the service manager never loaded it, which is fine
+	switch runtime.GOOS {
+	case "linux":
+		_ = run("systemctl", "--user", "disable", "--now", s.Label()+".service")
+	case "darwin":
+		_ = run("launchctl", "unload", "-w", path)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return "", fmt.Errorf("failed to remove service file: %w", err)
+	}
+	if runtime.GOOS == "linux" {
+		_ = run("systemctl", "--user", "daemon-reload")
+	}
+	return path, nil
+}
+
+// run executes a service manager command, including its output in errors.
+func run(name string, args ...string) error {
+	output, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s failed: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// systemdQuote joins words for an ExecStart or Environment line, escaping
+// specifiers and quoting any word that contains spaces, quotes, or backslashes.
+func systemdQuote(words []string) string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		word = strings.ReplaceAll(word, "%", "%%")
+		if strings.ContainsAny(word, " \t\"'\\") {
+			word = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(word) + `"`
+		}
+		quoted[i] = word
+	}
+	return strings.Join(quoted, " ")
+}
+
+// sortedKeys returns map keys in order so generated files are stable.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
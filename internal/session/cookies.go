@@ -0,0 +1,258 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// httpOnlyPrefix marks HttpOnly cookies in Netscape cookie files written by curl and browsers.
+const httpOnlyPrefix = "#HttpOnly_"
+
+// LoadCookies reads a cookie file in Netscape (cookies.txt) or JSON format.
+// JSON may be a list of cookies or a storage state file from sz login.
+func LoadCookies(path string) ([]Cookie, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cookie file: %w", err)
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		cookies, err := parseJSONCookies(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse cookie file %s: %w", path, err)
+		}
+		return cookies, nil
+	}
+
+	cookies, err := parseNetscapeCookies(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cookie file %s: %w", path, err)
+	}
+	return cookies, nil
+}
+
+// SaveCookies writes cookies to a file. Files ending in .json get a JSON list;
+// anything else gets the Netscape cookies.txt format.
+func SaveCookies(path string, cookies []Cookie) error {
+	var data []byte
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		encoded, err := json.MarshalIndent(cookies, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode cookies: %w", err)
+		}
+		data = append(encoded, '\n')
+	} else {
+		data = formatNetscapeCookies(cookies)
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write cookie file: %w", err)
+	}
+	return nil
+}
+
+// NewJar creates an HTTP cookie jar preloaded with cookies.
+func NewJar(cookies []Cookie) (http.CookieJar, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range cookies {
+		host := strings.TrimPrefix(c.Domain, ".")
+		if host == "" {
+			continue
+		}
+		scheme := "http"
+		if c.Secure {
+			scheme = "https"
+		}
+
+		cookie := &http.Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HttpOnly: c.HTTPOnly,
+		}
+		// A leading dot means the cookie also applies to subdomains
+		if strings.HasPrefix(c.Domain, ".") {
+			cookie.Domain = host
+		}
+		if c.Expires > 0 {
+			cookie.Expires = epochTime(c.Expires)
+		}
+		jar.SetCookies(&url.URL{Scheme: scheme, Host: host, Path: "/"}, []*http.Cookie{cookie})
+	}
+	return jar, nil
+}
+
+// JarCookies returns the initial cookies updated with what a jar holds for a
+// URL. Cookies the server changed keep their original attributes, and cookies
+// for unrelated domains are kept so the file can be saved again.
+func JarCookies(jar http.CookieJar, target *url.URL, initial []Cookie) []Cookie {
+	merged := append([]Cookie(nil), initial...)
+	host := target.Hostname()
+
+	for _, c := range jar.Cookies(target) {
+		updated := false
+		for i, existing := range merged {
+			if existing.Name == c.Name && domainMatches(host, existing.Domain) {
+				merged[i].Value = c.Value
+				updated = true
+				break
+			}
+		}
+		if !updated {
+			merged = append(merged, Cookie{
+				Name:   c.Name,
+				Value:  c.Value,
+				Domain: host,
+				Path:   "/",
+				Secure: target.Scheme == "https",
+			})
+		}
+	}
+	return merged
+}
+
+// domainMatches reports whether a cookie domain applies to a host.
+func domainMatches(host, domain string) bool {
+	domain = strings.TrimPrefix(domain, ".")
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// MergeCookies overlays cookies onto a base list, replacing entries with the
+// same name, domain, and path.
+func MergeCookies(base, overlay []Cookie) []Cookie {
+	merged := append([]Cookie(nil), base...)
+	for _, c := range overlay {
+		replaced := false
+		for i, existing := range merged {
+			if existing.Name == c.Name && strings.TrimPrefix(existing.Domain, ".") == strings.TrimPrefix(c.Domain, ".") && existing.Path == c.Path {
+				merged[i] = c
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, c)
+		}
+	}
+	return merged
+}
+
+// parseJSONCookies decodes a JSON cookie list or a storage state document.
+func parseJSONCookies(data []byte) ([]Cookie, error) {
+	if data[0] == '{' {
+		var state StorageState
+		if err := json.Unmarshal(data, &state); err != nil {
+			return nil, err
+		}
+		return state.Cookies, nil
+	}
+
+	var cookies []Cookie
+	if err := json.Unmarshal(data, &cookies); err != nil {
+		return nil, err
+	}
+	return cookies, nil
+}
+
+// parseNetscapeCookies decodes the tab-separated cookies.txt format:
+// domain, include-subdomains, path, secure, expires, name, value.
+func parseNetscapeCookies(data []byte) ([]Cookie, error) {
+	var cookies []Cookie
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		httpOnly := false
+		if strings.HasPrefix(line, httpOnlyPrefix) {
+			httpOnly = true
+			line = strings.TrimPrefix(line, httpOnlyPrefix)
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) < 7 {
+			return nil, fmt.Errorf("line %d: expected 7 tab-separated fields, got %d", lineNumber, len(fields))
+		}
+
+		expires, err := strconv.ParseFloat(fields[4], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid expiry %q", lineNumber, fields[4])
+		}
+
+		domain := fields[0]
+		if strings.EqualFold(fields[1], "TRUE") && !strings.HasPrefix(domain, ".") {
+			domain = "." + domain
+		}
+
+		cookies = append(cookies, Cookie{
+			Name:     fields[5],
+			Value:    strings.Join(fields[6:], "\t"),
+			Domain:   domain,
+			Path:     fields[2],
+			Expires:  expires,
+			HTTPOnly: httpOnly,
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cookies, nil
+}
+
+// formatNetscapeCookies encodes cookies in the cookies.txt format.
+func formatNetscapeCookies(cookies []Cookie) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("# Netscape HTTP Cookie File\n")
+	buf.WriteString("# Written by sz on " + time.Now().UTC().Format(time.RFC3339) + "\n\n")
+
+	for _, c := range cookies {
+		prefix := ""
+		if c.HTTPOnly {
+			prefix = httpOnlyPrefix
+		}
+		path := c.Path
+		if path == "" {
+			path = "/"
+		}
+		fmt.Fprintf(&buf, "%s%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			prefix,
+			c.Domain,
+			netscapeBool(strings.HasPrefix(c.Domain, ".")),
+			path,
+			netscapeBool(c.Secure),
+			int64(c.Expires),
+			c.Name,
+			c.Value,
+		)
+	}
+	return buf.Bytes()
+}
+
+// netscapeBool formats a boolean the way cookies.txt expects.
+func netscapeBool(value bool) string {
+	if value {
+		return "TRUE"
+	}
+	return "FALSE"
+}
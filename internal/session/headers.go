@@ -0,0 +1,24 @@
+package session
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ParseHeaders parses "Name: value" strings into a header map.
+func ParseHeaders(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	headers := make(map[string]string, len(values))
+	for _, value := range values {
+		name, content, ok := strings.Cut(value, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid header %q: expected \"Name: value\"", value)
+		}
+		headers[http.CanonicalHeaderKey(name)] = strings.TrimSpace(content)
+	}
+	return headers, nil
+}
@@ -0,0 +1,226 @@
+// Package session persists browser storage state (cookies and localStorage)
+// so authenticated pages can be fetched after logging in once.
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/storage"
+	"github.com/chromedp/chromedp"
+)
+
+// StorageState is the saved cookies and per-origin localStorage of a session.
+type StorageState struct {
+	Cookies []Cookie        `json:"cookies"`
+	Origins []OriginStorage `json:"origins"`
+}
+
+// Cookie is a browser cookie. Expires is seconds since the Unix epoch, or
+// zero for a session cookie.
+type Cookie struct {
+	Name     string  `json:"name"`
+	Value    string  `json:"value"`
+	Domain   string  `json:"domain"`
+	Path     string  `json:"path"`
+	Expires  float64 `json:"expires,omitempty"`
+	HTTPOnly bool    `json:"httpOnly,omitempty"`
+	Secure   bool    `json:"secure,omitempty"`
+	SameSite string  `json:"sameSite,omitempty"`
+}
+
+// OriginStorage holds the localStorage entries of one origin.
+type OriginStorage struct {
+	Origin       string            `json:"origin"`
+	LocalStorage map[string]string `json:"localStorage"`
+}
+
+// Load reads a storage state file.
+func Load(path string) (*StorageState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var state StorageState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// Save writes the storage state to a file readable only by the current user,
+// since it contains session credentials.
+func (s *StorageState) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("failed to create state directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+// Capture reads the cookies of the tab's browser context and the localStorage
+// of the page currently loaded in the tab.
+func Capture(chromeCtx context.Context) (*StorageState, error) {
+	state := &StorageState{
+		Cookies: []Cookie{},
+		Origins: []OriginStorage{},
+	}
+
+	var origin string
+	var entries map[string]string
+	err := chromedp.Run(chromeCtx,
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			cookies, err := storage.GetCookies().WithBrowserContextID(browserContextID(chromeCtx)).Do(ctx)
+			if err != nil {
+				return err
+			}
+			for _, c := range cookies {
+				cookie := Cookie{
+					Name:     c.Name,
+					Value:    c.Value,
+					Domain:   c.Domain,
+					Path:     c.Path,
+					HTTPOnly: c.HTTPOnly,
+					Secure:   c.Secure,
+					SameSite: c.SameSite.String(),
+				}
+				if !c.Session {
+					cookie.Expires = c.Expires
+				}
+				state.Cookies = append(state.Cookies, cookie)
+			}
+			return nil
+		}),
+		chromedp.Evaluate(`location.origin`, &origin),
+		chromedp.Evaluate(`
+			(function() {
+				const entries = {};
+				try {
+					for (let i = 0; i < localStorage.length; i++) {
+						const key = localStorage.key(i);
+						entries[key] = localStorage.getItem(key);
+					}
+				} catch (e) {}
+				return entries;
+			})();
+		`, &entries),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture storage state: %w", err)
+	}
+
+	if origin != "" && origin != "null" && len(entries) > 0 {
+		state.Origins = append(state.Origins, OriginStorage{
+			Origin:       origin,
+			LocalStorage: entries,
+		})
+	}
+	return state, nil
+}
+
+// Apply installs the cookies into the tab's browser context and the
+// localStorage into the tab. It must run before navigation so the first
+// request already carries the session.
+func (s *StorageState) Apply(chromeCtx context.Context) error {
+	return chromedp.Run(chromeCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		if params := s.cookieParams(); len(params) > 0 {
+			if err := storage.SetCookies(params).WithBrowserContextID(browserContextID(chromeCtx)).Do(ctx); err != nil {
+				return fmt.Errorf("failed to set cookies: %w", err)
+			}
+		}
+
+		if len(s.Origins) == 0 {
+			return nil
+		}
+		script, err := s.localStorageScript()
+		if err != nil {
+			return err
+		}
+		if _, err := page.AddScriptToEvaluateOnNewDocument(script).Do(ctx); err != nil {
+			return fmt.Errorf("failed to install localStorage: %w", err)
+		}
+		return nil
+	}))
+}
+
+// cookieParams converts the saved cookies into CDP cookie parameters.
+func (s *StorageState) cookieParams() []*network.CookieParam {
+	params := make([]*network.CookieParam, 0, len(s.Cookies))
+	for _, c := range s.Cookies {
+		param := &network.CookieParam{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			HTTPOnly: c.HTTPOnly,
+			Secure:   c.Secure,
+		}
+		if c.SameSite != "" {
+			param.SameSite = network.CookieSameSite(c.SameSite)
+		}
+		if c.Expires > 0 {
+			expires := cdp.TimeSinceEpoch(epochTime(c.Expires))
+			param.Expires = &expires
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// localStorageScript builds a script that seeds localStorage for matching origins.
+func (s *StorageState) localStorageScript() (string, error) {
+	origins, err := json.Marshal(s.Origins)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode localStorage: %w", err)
+	}
+	return fmt.Sprintf(`
+		(function() {
+			const origins = %s;
+			origins.forEach(function(entry) {
+				if (entry.origin !== location.origin) {
+					return;
+				}
+				try {
+					Object.keys(entry.localStorage || {}).forEach(function(key) {
+						if (localStorage.getItem(key) === null) {
+							localStorage.setItem(key, entry.localStorage[key]);
+						}
+					});
+				} catch (e) {}
+			});
+		})();
+	`, origins), nil
+}
+
+// browserContextID returns the browser context the tab belongs to. Storage
+// commands without one act on the browser's default context, whatever tab
+// they are sent through.
+func browserContextID(chromeCtx context.Context) cdp.BrowserContextID {
+	if c := chromedp.FromContext(chromeCtx); c != nil {
+		return c.BrowserContextID
+	}
+	return ""
+}
+
+// epochTime converts fractional seconds since the Unix epoch to a time.
+func epochTime(seconds float64) time.Time {
+	whole := int64(seconds)
+	return time.Unix(whole, int64((seconds-float64(whole))*float64(time.Second)))
+}
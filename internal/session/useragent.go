@@ -0,0 +1,33 @@
+package session
+
+import (
+	"sort"
+	"strings"
+)
+
+// userAgentPresets maps preset names to full user-agent strings.
+var userAgentPresets = map[string]string{
+	"chrome-desktop": "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	"chrome-mobile":  "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36",
+	"googlebot":      "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+	"curl":           "curl/8.5.0",
+}
+
+// ResolveUserAgent returns the user-agent string for a preset name, or the
+// value itself when it is not a preset.
+func ResolveUserAgent(value string) string {
+	if preset, ok := userAgentPresets[strings.ToLower(strings.TrimSpace(value))]; ok {
+		return preset
+	}
+	return value
+}
+
+// UserAgentPresets returns the preset names in sorted order.
+func UserAgentPresets() []string {
+	names := make([]string, 0, len(userAgentPresets))
+	for name := range userAgentPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,198 @@
+// Package sitemap reads XML sitemaps, following sitemap indexes to the
+// sitemaps they list, and filters the page URLs they contain.
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MaxSize is the most a single sitemap may hold once decompressed, the
+// limit set by the sitemaps protocol.
+const MaxSize = 50 << 20
+
+// maxDepth stops sitemap indexes that list each other from recursing forever.
+const maxDepth = 5
+
+// URL is one page listed in a sitemap.
+type URL struct {
+	Loc     string
+	LastMod time.Time // Zero when the sitemap gives no date
+}
+
+// document covers both a <urlset> and a <sitemapindex>.
+type document struct {
+	XMLName  xml.Name
+	URLs     []entry `xml:"url"`
+	Sitemaps []entry `xml:"sitemap"`
+}
+
+type entry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+// Parse reads a sitemap, gzipped or not, and returns the page URLs and the
+// locations of any sitemaps it indexes.
+func Parse(data []byte) (urls []URL, sitemaps []string, err error) {
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decompress sitemap: %w", err)
+		}
+		data, err = io.ReadAll(io.LimitReader(reader, MaxSize))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decompress sitemap: %w", err)
+		}
+	}
+
+	var doc document
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse sitemap: %w", err)
+	}
+	switch doc.XMLName.Local {
+	case "urlset", "sitemapindex":
+	default:
+		return nil, nil, fmt.Errorf("failed to parse sitemap: unexpected <%s> element", doc.XMLName.Local)
+	}
+
+	for _, e := range doc.URLs {
+		if loc := strings.TrimSpace(e.Loc); loc != "" {
+			urls = append(urls, URL{Loc: loc, LastMod: parseDate(e.LastMod)})
+		}
+	}
+	for _, e := range doc.Sitemaps {
+		if loc := strings.TrimSpace(e.Loc); loc != "" {
+			sitemaps = append(sitemaps, loc)
+		}
+	}
+	return urls, sitemaps, nil
+}
+
+// ReadFunc loads the sitemap at a URL or path.
+type ReadFunc func(ctx context.Context, location string) ([]byte, error)
+
+// Collect reads the sitemap at root and every sitemap it indexes, returning
+// the page URLs in the order they are listed, each once. A nested sitemap
+// that fails to load is passed to warn and skipped; failing to load root is
+// an error.
+func Collect(ctx context.Context, root string, read ReadFunc, warn func(location string, err error)) ([]URL, error) {
+	var urls []URL
+	seenURLs := make(map[string]bool)
+	seenMaps := map[string]bool{root: true}
+
+	var visit func(location string, depth int) error
+	visit = func(location string, depth int) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		data, err := read(ctx, location)
+		if err != nil {
+			return err
+		}
+		pages, nested, err := Parse(data)
+		if err != nil {
+			return err
+		}
+		for _, page := range pages {
+			if !seenURLs[page.Loc] {
+				seenURLs[page.Loc] = true
+				urls = append(urls, page)
+			}
+		}
+		for _, child := range nested {
+			if seenMaps[child] {
+				continue
+			}
+			seenMaps[child] = true
+			if depth >= maxDepth {
+				warn(child, fmt.Errorf("sitemap indexes nested more than %d deep", maxDepth))
+				continue
+			}
+			if err := visit(child, depth+1); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				warn(child, err)
+			}
+		}
+		return nil
+	}
+
+	if err := visit(root, 0); err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
+
+// Filter selects URLs by pattern and date.
+type Filter struct {
+	Include []*regexp.Regexp // When set, a URL must match one of these
+	Exclude []*regexp.Regexp // A URL matching any of these is dropped
+	Since   time.Time        // When set, drop URLs last modified before this or without a date
+	Until   time.Time        // When set, drop URLs last modified after this or without a date
+}
+
+// Match reports whether u passes the filter.
+func (f Filter) Match(u URL) bool {
+	for _, pattern := range f.Exclude {
+		if pattern.MatchString(u.Loc) {
+			return false
+		}
+	}
+	if len(f.Include) > 0 {
+		included := false
+		for _, pattern := range f.Include {
+			if pattern.MatchString(u.Loc) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	if !f.Since.IsZero() && (u.LastMod.IsZero() || u.LastMod.Before(f.Since)) {
+		return false
+	}
+	if !f.Until.IsZero() && (u.LastMod.IsZero() || u.LastMod.After(f.Until)) {
+		return false
+	}
+	return true
+}
+
+// dateLayouts are the W3C datetime forms sitemaps use for <lastmod>.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// ParseDate reads a date in any of the forms sitemaps use, such as
+// 2024-05-01 or 2024-05-01T12:00:00Z.
+func ParseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD or an RFC 3339 time", value)
+}
+
+// parseDate reads a <lastmod> value, returning the zero time when it is
+// missing or malformed.
+func parseDate(value string) time.Time {
+	t, _ := ParseDate(value)
+	return t
+}
@@ -0,0 +1,256 @@
+package textdoc
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	atxHeading      = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	setextUnderline = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	thematicBreak   = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	listItem        = regexp.MustCompile(`^([ \t]*)([-*+]|\d{1,9}[.)])(?:[ \t]+(.*))?$`)
+	linkDefinition  = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:[ \t]*<?([^\s>]+)>?(?:[ \t]+(?:"[^"]*"|'[^']*'|\([^)]*\)))?[ \t]*$`)
+	referenceLink   = regexp.MustCompile(`(!?)\[([^\[\]]+)\](?:\[([^\[\]]*)\])?`)
+	codeSpan        = regexp.MustCompile("`+[^`]*`+")
+)
+
+// blockKind is what the lines of a block are.
+type blockKind int
+
+const (
+	noBlock   blockKind = iota
+	paragraph           // Lines joined into one
+	listBlock           // One line per item, continuation lines joined into it
+	verbatim            // Lines kept as they are: quotes, tables, HTML
+	codeBlock           // Fenced or indented code, left untouched
+)
+
+// block is one run of lines of the normalized document.
+type block struct {
+	kind  blockKind
+	lines []string
+}
+
+// Normalize rewrites markdown, or plain text, in the style of the markdown sz
+// writes for HTML pages: ATX headings, "-" bullets, inline links, each
+// paragraph on one line and blocks separated by a single blank line.
+// Reference-style links are resolved against their definitions, which are
+// dropped. Code, fenced or indented, is left as it is.
+func Normalize(text string) string {
+	text = strings.ReplaceAll(strings.TrimPrefix(text, "\ufeff"), "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+	lines, definitions := takeDefinitions(lines)
+
+	var blocks []block
+	if front := frontMatter(lines); front > 0 {
+		// YAML front matter stays at the top as it is
+		blocks = append(blocks, block{kind: codeBlock, lines: lines[:front]})
+		lines = lines[front:]
+	}
+
+	var current block
+	flush := func() {
+		if current.kind != noBlock {
+			blocks = append(blocks, current)
+		}
+		current = block{}
+	}
+	start := func(kind blockKind) {
+		if current.kind != kind {
+			flush()
+			current.kind = kind
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		trimmed := strings.TrimSpace(line)
+
+		if fence := fenceMarker(trimmed); fence != "" {
+			flush()
+			code := []string{line}
+			for i+1 < len(lines) {
+				i++
+				code = append(code, strings.TrimRight(lines[i], " \t"))
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+					break
+				}
+			}
+			blocks = append(blocks, block{kind: codeBlock, lines: code})
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			flush()
+		case current.kind == listBlock && isIndented(line) && !listItem.MatchString(line):
+			last := len(current.lines) - 1
+			current.lines[last] += " " + trimmed
+		case isIndented(line) && current.kind != paragraph && current.kind != listBlock:
+			start(codeBlock)
+			current.lines = append(current.lines, line)
+		case atxHeading.MatchString(line):
+			flush()
+			match := atxHeading.FindStringSubmatch(line)
+			heading := strings.TrimSpace(match[1] + " " + match[2])
+			blocks = append(blocks, block{kind: paragraph, lines: []string{heading}})
+		case current.kind == paragraph && setextUnderline.MatchString(line):
+			level := "##"
+			if strings.HasPrefix(trimmed, "=") {
+				level = "#"
+			}
+			current.lines = []string{level + " " + strings.Join(current.lines, " ")}
+			flush()
+		case thematicBreak.MatchString(line):
+			flush()
+			blocks = append(blocks, block{kind: paragraph, lines: []string{"---"}})
+		case listItem.MatchString(line):
+			start(listBlock)
+			match := listItem.FindStringSubmatch(line)
+			marker := match[2]
+			if marker == "*" || marker == "+" {
+				marker = "-"
+			}
+			current.lines = append(current.lines, strings.TrimRight(match[1]+marker+" "+match[3], " "))
+		case strings.HasPrefix(trimmed, ">") || strings.HasPrefix(trimmed, "|") || strings.HasPrefix(trimmed, "<"):
+			start(verbatim)
+			current.lines = append(current.lines, line)
+		case current.kind == listBlock:
+			last := len(current.lines) - 1
+			current.lines[last] += " " + trimmed
+		case current.kind == verbatim:
+			current.lines = append(current.lines, line)
+		default:
+			start(paragraph)
+			current.lines = append(current.lines, trimmed)
+		}
+	}
+	flush()
+
+	parts := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		switch b.kind {
+		case paragraph:
+			parts = append(parts, inlineLinks(strings.Join(b.lines, " "), definitions))
+		case codeBlock:
+			parts = append(parts, strings.Join(b.lines, "\n"))
+		default:
+			parts = append(parts, inlineLinks(strings.Join(b.lines, "\n"), definitions))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+// frontMatter returns how many lines the YAML front matter at the start of a
+// document takes, or 0 when there is none.
+func frontMatter(lines []string) int {
+	if len(lines) == 0 || strings.TrimRight(lines[0], " \t") != "---" {
+		return 0
+	}
+	for i := 1; i < len(lines); i++ {
+		if line := strings.TrimRight(lines[i], " \t"); line == "---" || line == "..." {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// fenceMarker returns the backticks or tildes a line opening a fenced code
+// block starts with, or "".
+func fenceMarker(trimmed string) string {
+	for _, c := range []string{"`", "~"} {
+		n := len(trimmed) - len(strings.TrimLeft(trimmed, c))
+		if n >= 3 {
+			return strings.Repeat(c, n)
+		}
+	}
+	return ""
+}
+
+// isIndented reports whether a line is indented as far as code is.
+func isIndented(line string) bool {
+	return strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")
+}
+
+// takeDefinitions removes the link reference definitions outside fenced code
+// from lines, returning what is left and the URLs by label.
+func takeDefinitions(lines []string) ([]string, map[string]string) {
+	definitions := make(map[string]string)
+	kept := make([]string, 0, len(lines))
+	fence := ""
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		case fenceMarker(trimmed) != "":
+			fence = fenceMarker(trimmed)
+		default:
+			if match := linkDefinition.FindStringSubmatch(line); match != nil {
+				label := normalizeLabel(match[1])
+				if _, ok := definitions[label]; !ok {
+					definitions[label] = match[2]
+				}
+				continue
+			}
+		}
+		kept = append(kept, line)
+	}
+	return kept, definitions
+}
+
+// inlineLinks rewrites the reference-style links and images in text whose
+// labels are defined as inline ones, leaving code spans alone.
+func inlineLinks(text string, definitions map[string]string) string {
+	if len(definitions) == 0 {
+		return text
+	}
+	var out strings.Builder
+	last := 0
+	for _, span := range codeSpan.FindAllStringIndex(text, -1) {
+		out.WriteString(inlineLinksOutsideCode(text[last:span[0]], definitions))
+		out.WriteString(text[span[0]:span[1]])
+		last = span[1]
+	}
+	out.WriteString(inlineLinksOutsideCode(text[last:], definitions))
+	return out.String()
+}
+
+// inlineLinksOutsideCode rewrites the reference-style links in text that has
+// no code spans.
+func inlineLinksOutsideCode(text string, definitions map[string]string) string {
+	var out strings.Builder
+	last := 0
+	for _, match := range referenceLink.FindAllStringSubmatchIndex(text, -1) {
+		end := match[1]
+		// Inline links and definitions are not references
+		if end < len(text) && (text[end] == '(' || text[end] == ':') {
+			continue
+		}
+		label := text[match[4]:match[5]]
+		if match[6] >= 0 && match[7] > match[6] {
+			label = text[match[6]:match[7]]
+		}
+		url, ok := definitions[normalizeLabel(label)]
+		if !ok {
+			continue
+		}
+		out.WriteString(text[last:match[0]])
+		out.WriteString(text[match[2]:match[3]] + "[" + text[match[4]:match[5]] + "](" + url + ")")
+		last = end
+	}
+	out.WriteString(text[last:])
+	return out.String()
+}
+
+// normalizeLabel folds a link label the way markdown matches them: case and
+// runs of whitespace do not matter.
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
@@ -0,0 +1,41 @@
+// Package textdoc handles sources that are already markdown or plain text
+// rather than HTML: it recognises them, and normalizes them into the
+// markdown sz writes for HTML pages.
+package textdoc
+
+import (
+	"html"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// textExtensions are the file extensions of markdown and plain text files.
+var textExtensions = map[string]bool{
+	".md": true, ".markdown": true, ".mdown": true, ".mkd": true,
+	".txt": true, ".text": true,
+}
+
+// chromeTextViewer matches the page Chrome wraps a text/plain or markdown
+// response in, capturing the escaped text.
+var chromeTextViewer = regexp.MustCompile(`(?s)^\s*<html[^>]*>\s*<head>.*?</head>\s*<body>\s*<pre style="[^"]*white-space: pre-wrap[^"]*">(.*)</pre>\s*(?:<div class="json-formatter-container"></div>\s*)?</body>\s*</html>\s*$`)
+
+// IsTextFile reports whether a file path names a markdown or plain text
+// file.
+func IsTextFile(path string) bool {
+	return textExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// Detect returns the text of fetched content that is not HTML: a response
+// rendered by Chrome's text viewer, or one fetched over HTTP that does not
+// start with markup. It reports false for HTML.
+func Detect(content string) (string, bool) {
+	if match := chromeTextViewer.FindStringSubmatch(content); match != nil {
+		return html.UnescapeString(match[1]), true
+	}
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" || strings.HasPrefix(trimmed, "<") {
+		return "", false
+	}
+	return content, true
+}
@@ -0,0 +1,128 @@
+// Package tokens estimates how many tokens language models count in a text,
+// so content can be budgeted before it is pasted into a model's context.
+//
+// The estimate splits text the way tiktoken's encodings pre-split it (words
+// with their leading space, digit groups, punctuation runs, whitespace) and
+// charges each piece what such a piece typically costs in the model's
+// encoding. It runs offline and without vocabulary files, and lands within a
+// few percent of the exact count on English prose.
+package tokens
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Encoding describes how a family of tokenizers cuts text.
+type Encoding struct {
+	Name string
+	// wordChars is how many letters of a Latin-script word one token covers
+	wordChars float64
+	// ideographPerToken is how many CJK characters one token covers
+	ideographPerToken float64
+}
+
+// Encodings for the tokenizers of common models.
+var (
+	CL100K = Encoding{Name: "cl100k_base", wordChars: 6, ideographPerToken: 1}
+	O200K  = Encoding{Name: "o200k_base", wordChars: 7, ideographPerToken: 1.4}
+	Claude = Encoding{Name: "claude", wordChars: 5.5, ideographPerToken: 0.9}
+	Llama3 = Encoding{Name: "llama3", wordChars: 6, ideographPerToken: 1.1}
+)
+
+// DefaultModel is the model counted for when none is named.
+const DefaultModel = "gpt-4o"
+
+// modelPrefixes maps model name prefixes to their encoding, longest first
+// where one prefix starts another.
+var modelPrefixes = map[string]Encoding{
+	"gpt-4o":                 O200K,
+	"gpt-4.1":                O200K,
+	"gpt-4.5":                O200K,
+	"gpt-5":                  O200K,
+	"o1":                     O200K,
+	"o3":                     O200K,
+	"o4":                     O200K,
+	"gpt-4":                  CL100K,
+	"gpt-3.5":                CL100K,
+	"text-embedding-3":       CL100K,
+	"text-embedding-ada-002": CL100K,
+	"claude":                 Claude,
+	"llama":                  Llama3,
+	"mistral":                Llama3,
+}
+
+// ForModel returns the encoding a model counts tokens with. Encoding names
+// such as cl100k_base are accepted as well.
+func ForModel(model string) (Encoding, error) {
+	model = strings.ToLower(model)
+	for _, encoding := range []Encoding{CL100K, O200K, Claude, Llama3} {
+		if model == encoding.Name {
+			return encoding, nil
+		}
+	}
+	prefixes := make([]string, 0, len(modelPrefixes))
+	for prefix := range modelPrefixes {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(model, prefix) {
+			return modelPrefixes[prefix], nil
+		}
+	}
+	return Encoding{}, fmt.Errorf("unknown model %q: use a model such as gpt-4o, gpt-4, claude or llama, or an encoding such as cl100k_base", model)
+}
+
+// piecePattern pre-splits text like tiktoken: contractions, words with an
+// optional leading space, groups of up to three digits, punctuation runs and
+// whitespace.
+var piecePattern = regexp.MustCompile(`'(?:[sdmtSDMT]|ll|ve|re)| ?\p{L}+| ?\p{N}{1,3}| ?[^\s\p{L}\p{N}]+|\s+`)
+
+// Count estimates the tokens in text.
+func (e Encoding) Count(text string) int {
+	total := 0.0
+	for _, piece := range piecePattern.FindAllString(text, -1) {
+		total += e.cost(piece)
+	}
+	return int(math.Round(total))
+}
+
+// cost estimates the tokens in one pre-split piece.
+func (e Encoding) cost(piece string) float64 {
+	body := strings.TrimPrefix(piece, " ")
+	first, _ := utf8.DecodeRuneInString(body)
+	switch {
+	case strings.TrimSpace(piece) == "":
+		return 1
+	case unicode.IsLetter(first):
+		ideographs, latin, other := 0, 0, 0
+		for _, r := range body {
+			switch {
+			case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+				ideographs++
+			case r < utf8.RuneSelf:
+				latin++
+			default:
+				other++
+			}
+		}
+		cost := float64(ideographs) / e.ideographPerToken
+		if latin > 0 {
+			cost += math.Ceil(float64(latin) / e.wordChars)
+		}
+		// Accented and non-Latin letters take about a token per two
+		cost += float64(other) / 2
+		return math.Max(cost, 1)
+	case unicode.IsNumber(first):
+		return 1
+	default:
+		// Runs of punctuation such as ** or ](  are mostly merged in pairs
+		return math.Ceil(float64(utf8.RuneCountInString(body)) / 2)
+	}
+}
@@ -0,0 +1,29 @@
+// Package transcode turns fetched pages into UTF-8, which the rest of sz
+// assumes, whatever character encoding they were served in.
+package transcode
+
+import (
+	"bytes"
+
+	"golang.org/x/net/html/charset"
+)
+
+// utf8BOM is the byte order mark some editors put before UTF-8 text.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// ToUTF8 decodes an HTML document from the encoding its byte order mark, its
+// Content-Type header (contentType, which may be empty) or its <meta charset>
+// declares, in that order. Undeclared text that is not valid UTF-8 is taken
+// to be windows-1252, as browsers do. Text that fails to decode is returned
+// as it is.
+func ToUTF8(body []byte, contentType string) string {
+	encoding, name, _ := charset.DetermineEncoding(body, contentType)
+	if name == "utf-8" {
+		return string(bytes.TrimPrefix(body, utf8BOM))
+	}
+	decoded, err := encoding.NewDecoder().Bytes(body)
+	if err != nil {
+		return string(body)
+	}
+	return string(decoded)
+}
@@ -0,0 +1,91 @@
+package translate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jewell-lgtm/essenz/internal/config"
+)
+
+// defaultDeepLEndpoint is DeepL's free API; paid accounts use api.deepl.com.
+const defaultDeepLEndpoint = "https://api-free.deepl.com"
+
+// DeepL translates through the DeepL API or a service that speaks its
+// protocol, such as LibreTranslate's DeepL shim.
+type DeepL struct {
+	endpoint string
+	apiKey   string
+	http     *http.Client
+}
+
+// NewDeepL creates a DeepL translator. The API key in
+// ESSENZ_TRANSLATE_API_KEY takes precedence over the one in the config.
+func NewDeepL(cfg config.TranslateConfig) *DeepL {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = defaultDeepLEndpoint
+	}
+	apiKey := cfg.APIKey
+	if key := os.Getenv("ESSENZ_TRANSLATE_API_KEY"); key != "" {
+		apiKey = key
+	}
+	return &DeepL{
+		endpoint: strings.TrimRight(endpoint, "/"),
+		apiKey:   apiKey,
+		http:     &http.Client{Timeout: time.Minute},
+	}
+}
+
+// Translate translates text into lang, keeping its line breaks.
+func (d *DeepL) Translate(ctx context.Context, text, lang string) (string, error) {
+	body, err := json.Marshal(map[string]any{
+		"text":                []string{text},
+		"target_lang":         strings.ToUpper(lang),
+		"preserve_formatting": true,
+		"split_sentences":     "nonewlines",
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.endpoint+"/v2/translate", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if d.apiKey != "" {
+		req.Header.Set("Authorization", "DeepL-Auth-Key "+d.apiKey)
+	}
+
+	resp, err := d.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var answer struct {
+		Translations []struct {
+			Text string `json:"text"`
+		} `json:"translations"`
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&answer); err != nil && resp.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("DeepL answered with invalid JSON: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if answer.Message != "" {
+			return "", fmt.Errorf("DeepL answered %s: %s", resp.Status, answer.Message)
+		}
+		return "", fmt.Errorf("DeepL answered %s", resp.Status)
+	}
+	if len(answer.Translations) == 0 {
+		return "", errors.New("DeepL returned no translation")
+	}
+	return answer.Translations[0].Text, nil
+}
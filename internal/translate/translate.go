@@ -0,0 +1,144 @@
+// Package translate translates distilled markdown block by block through a
+// pluggable backend, keeping headings, lists, quotes and code as they are so
+// the translation reads like the original document.
+package translate
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/jewell-lgtm/essenz/internal/config"
+	"github.com/jewell-lgtm/essenz/internal/llm"
+)
+
+// Translator translates a piece of text into a language, given as a code such
+// as de or pt-BR, keeping its line breaks.
+type Translator interface {
+	Translate(ctx context.Context, text, lang string) (string, error)
+}
+
+// Backends a translator can be configured with.
+const (
+	BackendLLM   = "llm"
+	BackendDeepL = "deepl"
+)
+
+// langPattern matches language codes such as de, pt-BR or zh-Hans.
+var langPattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// ValidateLang checks that lang looks like a language code.
+func ValidateLang(lang string) error {
+	if !langPattern.MatchString(lang) {
+		return fmt.Errorf("invalid language %q: use a code such as de, fr or pt-BR", lang)
+	}
+	return nil
+}
+
+// New creates the translator set up in cfg: the language model by default,
+// or a DeepL-compatible API.
+func New(cfg *config.Config) (Translator, error) {
+	switch cfg.Translate.Backend {
+	case "", BackendLLM:
+		client, err := llm.NewClient(cfg.LLM)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	case BackendDeepL:
+		return NewDeepL(cfg.Translate), nil
+	}
+	return nil, fmt.Errorf("unknown translate.backend %q: use %s or %s", cfg.Translate.Backend, BackendLLM, BackendDeepL)
+}
+
+// prefixPattern matches the markdown syntax at the start of a line that is
+// kept out of translation: indentation, heading marks, list markers and
+// quote marks.
+var prefixPattern = regexp.MustCompile(`^\s*(?:(?:#{1,6}|[-*+]|\d+[.)]|>)\s+)*`)
+
+// Markdown translates a markdown document into lang. Each block between blank
+// lines is sent on its own, without the syntax that starts its lines; fenced
+// code, table rules and lines without letters are kept as they are.
+func Markdown(ctx context.Context, translator Translator, markdown, lang string) (string, error) {
+	lines := strings.Split(markdown, "\n")
+	var block []int // Indexes of the lines in the current block
+	flush := func() error {
+		if len(block) == 0 {
+			return nil
+		}
+		err := translateBlock(ctx, translator, lines, block, lang)
+		block = nil
+		return err
+	}
+
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			if err := flush(); err != nil {
+				return "", err
+			}
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if trimmed == "" {
+			if err := flush(); err != nil {
+				return "", err
+			}
+			continue
+		}
+		if hasLetters(line) && !isTableRule(trimmed) {
+			block = append(block, i)
+		}
+	}
+	if err := flush(); err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// translateBlock translates the given lines in place as one piece of text,
+// or line by line when the translation comes back with a different number
+// of lines.
+func translateBlock(ctx context.Context, translator Translator, lines []string, block []int, lang string) error {
+	prefixes := make([]string, len(block))
+	texts := make([]string, len(block))
+	for j, i := range block {
+		prefixes[j] = prefixPattern.FindString(lines[i])
+		texts[j] = lines[i][len(prefixes[j]):]
+	}
+
+	translated, err := translator.Translate(ctx, strings.Join(texts, "\n"), lang)
+	if err != nil {
+		return fmt.Errorf("translation failed: %w", err)
+	}
+	results := strings.Split(strings.Trim(translated, "\n"), "\n")
+	if len(results) != len(texts) {
+		results = make([]string, len(texts))
+		for j, text := range texts {
+			if results[j], err = translator.Translate(ctx, text, lang); err != nil {
+				return fmt.Errorf("translation failed: %w", err)
+			}
+		}
+	}
+
+	for j, i := range block {
+		lines[i] = prefixes[j] + strings.TrimSpace(results[j])
+	}
+	return nil
+}
+
+// hasLetters reports whether a line has any text worth translating.
+func hasLetters(line string) bool {
+	return strings.IndexFunc(line, unicode.IsLetter) >= 0
+}
+
+// isTableRule reports whether a line is the rule under a table's header.
+func isTableRule(line string) bool {
+	return strings.HasPrefix(line, "|") && strings.Trim(line, "|-: ") == ""
+}
@@ -0,0 +1,93 @@
+package transport
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// acceptEncoding lists the content codings decoder can undo.
+const acceptEncoding = "br, gzip, deflate"
+
+// decoder asks servers for compressed responses and decompresses them, so
+// callers read the body as sent before compression.
+type decoder struct {
+	next http.RoundTripper
+}
+
+// RoundTrip sends a request, asking for compression unless the caller chose
+// an encoding itself, and decodes the response.
+func (d *decoder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Accept-Encoding") != "" || req.Header.Get("Range") != "" {
+		return d.next.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+
+	resp, err := d.next.RoundTrip(req)
+	if err != nil || req.Method == http.MethodHead {
+		return resp, err
+	}
+
+	var body io.ReadCloser
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		body = &lazyReader{source: resp.Body, open: func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) }}
+	case "deflate":
+		body = &lazyReader{source: resp.Body, open: openDeflate}
+	case "br":
+		body = &lazyReader{source: resp.Body, open: func(r io.Reader) (io.ReadCloser, error) { return io.NopCloser(brotli.NewReader(r)), nil }}
+	default:
+		return resp, nil
+	}
+	resp.Body = body
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// openDeflate reads a deflate body, which most servers wrap in zlib as the
+// standard says and some send raw.
+func openDeflate(r io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(r)
+	header, err := buffered.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
+
+// lazyReader starts decompressing on the first read, so an empty or unread
+// body costs nothing and a bad header surfaces as a read error.
+type lazyReader struct {
+	source  io.ReadCloser
+	open    func(io.Reader) (io.ReadCloser, error)
+	decoded io.ReadCloser
+	err     error
+}
+
+func (l *lazyReader) Read(p []byte) (int, error) {
+	if l.decoded == nil && l.err == nil {
+		l.decoded, l.err = l.open(l.source)
+	}
+	if l.err != nil {
+		return 0, l.err
+	}
+	return l.decoded.Read(p)
+}
+
+// Close closes the response body, which returns the connection for reuse.
+func (l *lazyReader) Close() error {
+	if l.decoded != nil {
+		_ = l.decoded.Close()
+	}
+	return l.source.Close()
+}
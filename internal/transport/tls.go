@@ -0,0 +1,56 @@
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// TLSOptions says how to check the certificates servers present and which
+// certificate to present to servers that ask for one.
+type TLSOptions struct {
+	Insecure   bool   // Accept any server certificate
+	CACert     string // PEM file of certificate authorities to trust besides the system's
+	ClientCert string // PEM file of a client certificate, for sites protected by mutual TLS
+	ClientKey  string // PEM file of the client certificate's key; empty when ClientCert holds it
+}
+
+// Config builds the TLS configuration the options describe.
+func (o TLSOptions) Config() (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: o.Insecure, // Only when the user asks for it
+	}
+
+	if o.CACert != "" {
+		pem, err := os.ReadFile(o.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in %s", o.CACert)
+		}
+		config.RootCAs = pool
+	}
+
+	switch {
+	case o.ClientCert != "":
+		key := o.ClientKey
+		if key == "" {
+			key = o.ClientCert
+		}
+		certificate, err := tls.LoadX509KeyPair(o.ClientCert, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	case o.ClientKey != "":
+		return nil, errors.New("a client key needs a client certificate")
+	}
+	return config, nil
+}
@@ -0,0 +1,41 @@
+// Package transport is how sz talks HTTP when it fetches pages without
+// Chrome: HTTP/2 where the server offers it, connections kept open and shared
+// between fetches, compressed responses, and a timeout for each phase of a
+// request so a stalled server fails fast.
+package transport
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Timeouts for the phases of a request. The client's own timeout still bounds
+// the request as a whole, body included.
+const (
+	dialTimeout           = 10 * time.Second
+	keepAlive             = 30 * time.Second
+	tlsHandshakeTimeout   = 10 * time.Second
+	responseHeaderTimeout = 20 * time.Second
+	idleConnTimeout       = 90 * time.Second
+)
+
+// New returns a transport that connects with the given TLS configuration.
+// Use one for many requests: its open connections are what they reuse.
+func New(tlsConfig *tls.Config) http.RoundTripper {
+	dialer := &net.Dialer{Timeout: dialTimeout, KeepAlive: keepAlive}
+	return &decoder{next: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   tlsHandshakeTimeout,
+		ResponseHeaderTimeout: responseHeaderTimeout,
+		ExpectContinueTimeout: time.Second,
+		IdleConnTimeout:       idleConnTimeout,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   8,
+		// A custom TLS configuration turns HTTP/2 off unless asked for
+		ForceAttemptHTTP2: true,
+	}}
+}
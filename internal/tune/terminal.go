@@ -0,0 +1,292 @@
+package tune
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// key is a keypress understood by the full-screen interface.
+type key int
+
+const (
+	keyNone key = iota
+	keyUp
+	keyDown
+	keyPageUp
+	keyPageDown
+	keyHome
+	keyEnd
+	keyToggle
+	keyView
+	keySave
+	keyBack
+	keyQuit
+)
+
+// escapeKeys maps the escape sequences terminals send for special keys.
+var escapeKeys = []struct {
+	sequence string
+	key      key
+}{
+	{"\x1b[A", keyUp}, {"\x1bOA", keyUp},
+	{"\x1b[B", keyDown}, {"\x1bOB", keyDown},
+	{"\x1b[5~", keyPageUp}, {"\x1b[6~", keyPageDown},
+	{"\x1b[H", keyHome}, {"\x1bOH", keyHome}, {"\x1b[1~", keyHome}, {"\x1b[7~", keyHome},
+	{"\x1b[F", keyEnd}, {"\x1bOF", keyEnd}, {"\x1b[4~", keyEnd}, {"\x1b[8~", keyEnd},
+}
+
+// plainKeys maps single bytes to keys.
+var plainKeys = map[byte]key{
+	'k': keyUp, 'j': keyDown, 'g': keyHome, 'G': keyEnd,
+	' ': keyToggle, '\r': keyToggle, '\n': keyToggle,
+	'v': keyView, 's': keySave, 'q': keyQuit,
+	0x03: keyQuit, // Ctrl+C, which raw mode delivers as input
+	0x1b: keyBack,
+}
+
+// IsTerminal reports whether in and out are both terminals, so the session
+// can run full screen with RunTerminal.
+func IsTerminal(in, out *os.File) bool {
+	return term.IsTerminal(int(in.Fd())) && term.IsTerminal(int(out.Fd()))
+}
+
+// RunTerminal shows the blocks full screen on the terminal behind in and out,
+// where the arrow keys move between blocks and space toggles them. It returns
+// true when the user asked to save the resulting rules.
+func (s *Session) RunTerminal(in, out *os.File) (bool, error) {
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return false, fmt.Errorf("failed to set up terminal: %w", err)
+	}
+	defer func() { _ = term.Restore(int(in.Fd()), state) }()
+
+	// Draw on the alternate screen so the shell's scrollback is left as it was
+	_, _ = fmt.Fprint(out, "\x1b[?1049h\x1b[?25l")
+	defer func() { _, _ = fmt.Fprint(out, "\x1b[?25h\x1b[?1049l") }()
+
+	ui := &screen{
+		session: s,
+		out:     out,
+		size: func() (int, int) {
+			width, height, err := term.GetSize(int(out.Fd()))
+			if err != nil {
+				return 80, 24
+			}
+			return width, height
+		},
+	}
+	return ui.run(in)
+}
+
+// screen is the state of the full-screen interface: the selected block, the
+// scroll position, and whether the result is shown instead of the blocks.
+type screen struct {
+	session *Session
+	out     io.Writer
+	size    func() (width, height int)
+
+	cursor  int
+	top     int
+	viewing bool
+	result  []string
+}
+
+// run redraws the screen and handles keys until the user saves or quits.
+func (u *screen) run(in io.Reader) (bool, error) {
+	buf := make([]byte, 256)
+	for {
+		u.draw()
+
+		n, err := in.Read(buf)
+		for _, k := range parseKeys(buf[:n]) {
+			switch {
+			case k == keySave:
+				return true, nil
+			case k == keyQuit, k == keyBack && !u.viewing:
+				return false, nil
+			default:
+				u.handle(k)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to read input: %w", err)
+		}
+	}
+}
+
+// handle moves the selection or scrolls the result, toggles blocks, and
+// switches between the two views.
+func (u *screen) handle(k key) {
+	_, height := u.size()
+	page := max(height-2, 1)
+
+	if u.viewing {
+		switch k {
+		case keyUp:
+			u.top--
+		case keyDown:
+			u.top++
+		case keyPageUp:
+			u.top -= page
+		case keyPageDown:
+			u.top += page
+		case keyHome:
+			u.top = 0
+		case keyEnd:
+			u.top = len(u.result)
+		case keyView, keyBack:
+			u.viewing = false
+			u.top = 0
+		}
+		u.top = max(min(u.top, len(u.result)-page), 0)
+		return
+	}
+
+	blocks := u.session.blocks
+	switch k {
+	case keyUp:
+		u.cursor--
+	case keyDown:
+		u.cursor++
+	case keyPageUp:
+		u.cursor -= page
+	case keyPageDown:
+		u.cursor += page
+	case keyHome:
+		u.cursor = 0
+	case keyEnd:
+		u.cursor = len(blocks) - 1
+	case keyToggle:
+		if len(blocks) > 0 {
+			blocks[u.cursor].Kept = !blocks[u.cursor].Kept
+		}
+	case keyView:
+		width, _ := u.size()
+		u.viewing = true
+		u.result = u.session.resultLines(width)
+		u.top = 0
+		return
+	}
+	u.cursor = max(min(u.cursor, len(blocks)-1), 0)
+}
+
+// draw repaints the whole screen: a status line, as many blocks or result
+// lines as fit, and a line of key bindings.
+func (u *screen) draw() {
+	width, height := u.size()
+	rows := max(height-2, 1)
+
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	b.WriteString("\x1b[1m" + fit(u.session.status(), width) + "\x1b[0m\r\n")
+
+	if u.viewing {
+		for i := u.top; i < len(u.result) && i < u.top+rows; i++ {
+			b.WriteString(fit(u.result[i], width) + "\r\n")
+		}
+		b.WriteString(fit("↑/↓ scroll  v back to blocks  s save  q quit", width))
+	} else {
+		// Keep the selected block on screen
+		if u.cursor < u.top {
+			u.top = u.cursor
+		}
+		if u.cursor >= u.top+rows {
+			u.top = u.cursor - rows + 1
+		}
+
+		blocks := u.session.blocks
+		if len(blocks) == 0 {
+			b.WriteString("No blocks found\r\n")
+		}
+		for i := u.top; i < len(blocks) && i < u.top+rows; i++ {
+			line := fit(u.session.formatBlock(blocks[i], width-blockPrefixWidth), width)
+			if i == u.cursor {
+				line = "\x1b[7m" + line + "\x1b[0m"
+			}
+			b.WriteString(line + "\r\n")
+		}
+		b.WriteString(fit("↑/↓ move  space toggle  v view result  s save  q quit", width))
+	}
+
+	_, _ = io.WriteString(u.out, b.String())
+}
+
+// parseKeys splits one read from the terminal into keys. Escape sequences
+// for keys the interface does not use are skipped whole.
+func parseKeys(data []byte) []key {
+	var keys []key
+	for i := 0; i < len(data); {
+		rest := data[i:]
+
+		matched := false
+		for _, escape := range escapeKeys {
+			if bytes.HasPrefix(rest, []byte(escape.sequence)) {
+				keys = append(keys, escape.key)
+				i += len(escape.sequence)
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+
+		if len(rest) > 2 && rest[0] == 0x1b && (rest[1] == '[' || rest[1] == 'O') {
+			// Skip to the sequence's final byte
+			end := 2
+			for end < len(rest) && (rest[end] < 0x40 || rest[end] > 0x7e) {
+				end++
+			}
+			i += end + 1
+			continue
+		}
+
+		if k, ok := plainKeys[rest[0]]; ok {
+			keys = append(keys, k)
+		}
+		i++
+	}
+	return keys
+}
+
+// fit cuts a line to the terminal width.
+func fit(line string, width int) string {
+	if width < 4 || len([]rune(line)) <= width {
+		return line
+	}
+	return preview(line, width)
+}
+
+// wrap breaks text into lines no wider than width, at spaces where possible.
+func wrap(text string, width int) []string {
+	var lines []string
+	var line []rune
+	for _, word := range strings.Fields(text) {
+		runes := []rune(word)
+		if len(line) > 0 && len(line)+1+len(runes) > width {
+			lines = append(lines, string(line))
+			line = nil
+		}
+		for len(runes) > width {
+			lines = append(lines, string(runes[:width]))
+			runes = runes[width:]
+		}
+		if len(line) > 0 {
+			line = append(line, ' ')
+		}
+		line = append(line, runes...)
+	}
+	if len(line) > 0 {
+		lines = append(lines, string(line))
+	}
+	return lines
+}
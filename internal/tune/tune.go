@@ -0,0 +1,288 @@
+// Package tune provides an interactive session for adjusting content filter
+// decisions block by block and turning them into per-site rules.
+package tune
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jewell-lgtm/essenz/internal/config"
+	"github.com/jewell-lgtm/essenz/internal/filter"
+	"github.com/jewell-lgtm/essenz/internal/tree"
+)
+
+// Block is a unit of content the user can keep or remove.
+type Block struct {
+	Number   int
+	Selector string
+	Tag      string
+	Text     string
+	Filtered bool // Whether the content filter kept the block
+	Kept     bool // Current decision after user toggles
+}
+
+// Session drives the interactive tuning loop.
+type Session struct {
+	host   string
+	blocks []*Block
+	in     *bufio.Scanner
+	out    io.Writer
+}
+
+// blockTags are elements treated as blocks when they carry their own text.
+var blockTags = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "aside": true,
+	"nav": true, "header": true, "footer": true, "main": true, "span": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"blockquote": true, "figcaption": true, "dd": true, "dt": true, "a": true,
+	"button": true, "label": true, "td": true, "th": true,
+}
+
+// atomicTags are blocks that are listed as a whole instead of being split further.
+var atomicTags = map[string]bool{
+	"ul": true, "ol": true, "dl": true, "table": true, "pre": true, "figure": true, "form": true,
+}
+
+// CollectBlocks lists the content blocks of the original tree and records whether
+// each one survived in the filtered tree. Both trees must be built from the same HTML.
+func CollectBlocks(original, filtered *tree.TextNode) []*Block {
+	survivors := make(map[int]bool)
+	var mark func(node *tree.TextNode)
+	mark = func(node *tree.TextNode) {
+		if node == nil {
+			return
+		}
+		survivors[node.Index] = true
+		for _, child := range node.Children {
+			mark(child)
+		}
+	}
+	mark(filtered)
+
+	var blocks []*Block
+	var walk func(node *tree.TextNode)
+	walk = func(node *tree.TextNode) {
+		tag := strings.ToLower(node.Tag)
+		if tag == "head" || tag == "#text" {
+			return
+		}
+
+		if atomicTags[tag] || (blockTags[tag] && hasOwnText(node)) {
+			text := collapse(textContent(node))
+			if text != "" {
+				kept := survivors[node.Index]
+				blocks = append(blocks, &Block{
+					Number:   len(blocks) + 1,
+					Selector: filter.SelectorPath(node),
+					Tag:      tag,
+					Text:     text,
+					Filtered: kept,
+					Kept:     kept,
+				})
+			}
+			return
+		}
+
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(original)
+
+	return blocks
+}
+
+// NewSession creates a tuning session for the given host and blocks.
+func NewSession(host string, blocks []*Block, in io.Reader, out io.Writer) *Session {
+	return &Session{
+		host:   host,
+		blocks: blocks,
+		in:     bufio.NewScanner(in),
+		out:    out,
+	}
+}
+
+// Run shows the blocks and processes line commands until the user saves or
+// quits, for input that is not a terminal such as a script. It returns true
+// when the user asked to save the resulting rules.
+func (s *Session) Run() (bool, error) {
+	s.printBlocks()
+	s.printHelp()
+
+	for {
+		_, _ = fmt.Fprint(s.out, "> ")
+		if !s.in.Scan() {
+			if err := s.in.Err(); err != nil {
+				return false, fmt.Errorf("failed to read input: %w", err)
+			}
+			return false, nil
+		}
+
+		command := strings.TrimSpace(s.in.Text())
+		switch strings.ToLower(command) {
+		case "":
+			continue
+		case "s", "save", "w":
+			return true, nil
+		case "q", "quit":
+			return false, nil
+		case "l", "list":
+			s.printBlocks()
+		case "v", "view":
+			s.printResult()
+		case "h", "help", "?":
+			s.printHelp()
+		default:
+			numbers, err := parseNumbers(command, len(s.blocks))
+			if err != nil {
+				_, _ = fmt.Fprintf(s.out, "%v\n", err)
+				continue
+			}
+			for _, number := range numbers {
+				block := s.blocks[number-1]
+				block.Kept = !block.Kept
+				_, _ = fmt.Fprintln(s.out, s.formatBlock(block, 60))
+			}
+		}
+	}
+}
+
+// Rules converts the user's toggles into site rules. Blocks whose decision
+// matches the filter's own decision produce no rule.
+func (s *Session) Rules() *config.SiteRules {
+	rules := &config.SiteRules{Host: s.host}
+	for _, block := range s.blocks {
+		switch {
+		case block.Kept && !block.Filtered:
+			rules.Preserve = append(rules.Preserve, block.Selector)
+		case !block.Kept && block.Filtered:
+			rules.Exclude = append(rules.Exclude, block.Selector)
+		}
+	}
+	return rules
+}
+
+func (s *Session) printBlocks() {
+	_, _ = fmt.Fprintf(s.out, "Blocks for %s:\n", s.host)
+	for _, block := range s.blocks {
+		_, _ = fmt.Fprintln(s.out, s.formatBlock(block, 60))
+	}
+}
+
+func (s *Session) printHelp() {
+	_, _ = fmt.Fprintln(s.out, `Enter block numbers to toggle them (e.g. "3 7-9"), "v" to view the result,`)
+	_, _ = fmt.Fprintln(s.out, `"l" to list blocks, "s" to save site rules, or "q" to quit without saving.`)
+}
+
+func (s *Session) printResult() {
+	for _, block := range s.blocks {
+		if block.Kept {
+			_, _ = fmt.Fprintf(s.out, "%s\n\n", block.Text)
+		}
+	}
+}
+
+// status summarises the session's decisions for the full-screen interface.
+func (s *Session) status() string {
+	kept, changed := 0, 0
+	for _, block := range s.blocks {
+		if block.Kept {
+			kept++
+		}
+		if block.Kept != block.Filtered {
+			changed++
+		}
+	}
+	return fmt.Sprintf("Blocks for %s: %d of %d kept, %d changed", s.host, kept, len(s.blocks), changed)
+}
+
+// resultLines returns the text of the kept blocks wrapped to width, with a
+// blank line between blocks.
+func (s *Session) resultLines(width int) []string {
+	var lines []string
+	for _, block := range s.blocks {
+		if block.Kept {
+			lines = append(lines, wrap(block.Text, max(width, 1))...)
+			lines = append(lines, "")
+		}
+	}
+	return lines
+}
+
+// blockPrefixWidth is the width of the number, state and tag before a block's text.
+const blockPrefixWidth = 24
+
+// formatBlock describes a block on one line, its text cut to limit characters.
+func (s *Session) formatBlock(block *Block, limit int) string {
+	state := "drop"
+	if block.Kept {
+		state = "keep"
+	}
+	marker := " "
+	if block.Kept != block.Filtered {
+		marker = "*"
+	}
+	return fmt.Sprintf("%4d %s[%s] %-10s %s", block.Number, marker, state, block.Tag, preview(block.Text, max(limit, 4)))
+}
+
+// parseNumbers parses block numbers and ranges like "2 5-7".
+func parseNumbers(input string, max int) ([]int, error) {
+	var numbers []int
+	for _, field := range strings.Fields(strings.ReplaceAll(input, ",", " ")) {
+		first, last := field, field
+		if idx := strings.Index(field, "-"); idx > 0 {
+			first, last = field[:idx], field[idx+1:]
+		}
+
+		start, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, fmt.Errorf("unknown command %q", input)
+		}
+		end, err := strconv.Atoi(last)
+		if err != nil {
+			return nil, fmt.Errorf("unknown command %q", input)
+		}
+		if start < 1 || end > max || start > end {
+			return nil, fmt.Errorf("block numbers must be between 1 and %d", max)
+		}
+		for n := start; n <= end; n++ {
+			numbers = append(numbers, n)
+		}
+	}
+	return numbers, nil
+}
+
+func hasOwnText(node *tree.TextNode) bool {
+	for _, child := range node.Children {
+		if child.Tag == "#text" && strings.TrimSpace(child.Text) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func textContent(node *tree.TextNode) string {
+	if node.Tag == "#text" {
+		return node.Text
+	}
+	var parts []string
+	for _, child := range node.Children {
+		parts = append(parts, textContent(child))
+	}
+	return strings.Join(parts, " ")
+}
+
+func collapse(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func preview(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-3]) + "..."
+}
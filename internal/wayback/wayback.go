@@ -0,0 +1,105 @@
+// Package wayback finds archived copies of pages through the Internet
+// Archive's Wayback Machine availability API.
+package wayback
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultEndpoint is the availability API; ESSENZ_WAYBACK_ENDPOINT replaces
+// it, for a mirror or a test server.
+const defaultEndpoint = "https://archive.org/wayback/available"
+
+// timestampLayout is how the archive writes capture times.
+const timestampLayout = "20060102150405"
+
+// requestTimeout bounds one availability lookup.
+const requestTimeout = 30 * time.Second
+
+// ErrNotArchived is returned when the archive holds no copy of a page.
+var ErrNotArchived = errors.New("no archived copy in the Wayback Machine")
+
+// Snapshot is one archived copy of a page.
+type Snapshot struct {
+	URL       string    // The snapshot's page in the Wayback Machine
+	Timestamp string    // The capture time as the archive writes it, e.g. 20240131120000
+	Captured  time.Time // The capture time in UTC
+}
+
+// RawURL returns the address of the snapshot as it was captured, without
+// the archive's toolbar or rewritten links.
+func (s Snapshot) RawURL() string {
+	return strings.Replace(s.URL, "/"+s.Timestamp+"/", "/"+s.Timestamp+"id_/", 1)
+}
+
+// availability is the part of an availability API answer sz reads.
+type availability struct {
+	ArchivedSnapshots struct {
+		Closest *struct {
+			Available bool   `json:"available"`
+			URL       string `json:"url"`
+			Timestamp string `json:"timestamp"`
+			Status    string `json:"status"`
+		} `json:"closest"`
+	} `json:"archived_snapshots"`
+}
+
+// Closest returns the snapshot of page captured closest to at, or the most
+// recent one when at is zero.
+func Closest(ctx context.Context, page string, at time.Time) (Snapshot, error) {
+	query := url.Values{"url": {page}}
+	if !at.IsZero() {
+		query.Set("timestamp", at.UTC().Format(timestampLayout))
+	}
+	endpoint := defaultEndpoint
+	if env := os.Getenv("ESSENZ_WAYBACK_ENDPOINT"); env != "" {
+		endpoint = env
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("wayback lookup failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return Snapshot{}, fmt.Errorf("wayback lookup answered %s", resp.Status)
+	}
+
+	var answer availability
+	if err := json.NewDecoder(resp.Body).Decode(&answer); err != nil {
+		return Snapshot{}, fmt.Errorf("wayback lookup answered with invalid JSON: %w", err)
+	}
+	closest := answer.ArchivedSnapshots.Closest
+	if closest == nil || !closest.Available || closest.URL == "" {
+		return Snapshot{}, ErrNotArchived
+	}
+	captured, err := time.Parse(timestampLayout, closest.Timestamp)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("wayback snapshot has an invalid timestamp %q", closest.Timestamp)
+	}
+	return Snapshot{
+		URL:       closest.URL,
+		Timestamp: closest.Timestamp,
+		Captured:  captured,
+	}, nil
+}
+
+// Notice is a markdown line saying the content below comes from snapshot.
+func Notice(snapshot Snapshot) string {
+	return fmt.Sprintf("> Archived copy from the Wayback Machine, captured %s: %s\n\n",
+		snapshot.Captured.Format("2006-01-02 15:04 UTC"), snapshot.URL)
+}
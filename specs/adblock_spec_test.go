@@ -0,0 +1,62 @@
+package specs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdblockSpec(t *testing.T) {
+	t.Run("blocks_listed_requests", func(t *testing.T) {
+		t.Log("SPEC: Ad and Tracker Blocking")
+		t.Log("GIVEN a page whose ad script injects sponsored content")
+		t.Log("WHEN the user fetches it with --adblock-list naming that script")
+		t.Log("THEN the ad script should be blocked and only the article extracted")
+
+		binary := buildBinary(t)
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`<html><body>
+<article><h1>Main Story</h1><p>The article text readers came for, without distractions.</p></article>
+<div id="slot"></div>
+<script src="/promo/banner.js"></script>
+</body></html>`))
+		})
+		mux.HandleFunc("/promo/banner.js", func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/javascript")
+			_, _ = w.Write([]byte(`document.getElementById('slot').innerHTML = '<p>Sponsored: buy the amazing widget today</p>';`))
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		listPath := filepath.Join(t.TempDir(), "list.txt")
+		require.NoError(t, os.WriteFile(listPath, []byte("! test list\n/promo/*$script\n"), 0o644))
+
+		cmd := exec.Command(binary, "--adblock-list", listPath, server.URL)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Fetch should succeed: %s", string(output))
+
+		assert.Contains(t, string(output), "Main Story", "Should extract the article")
+		assert.NotContains(t, string(output), "Sponsored", "Should block the ad script")
+	})
+
+	t.Run("missing_list_rejected", func(t *testing.T) {
+		t.Log("SPEC: Missing Block List")
+		t.Log("GIVEN an --adblock-list path that does not exist")
+		t.Log("WHEN the command runs")
+		t.Log("THEN it should fail before fetching")
+
+		cmd := exec.Command("go", "run", "../cmd/essenz/main.go", "--adblock-list", "/nonexistent/list.txt", "https://example.com")
+		output, err := cmd.CombinedOutput()
+		require.Error(t, err, "Command should fail with a missing block list")
+
+		assert.Contains(t, string(output), "failed to read block list", "Should report the missing list")
+	})
+}
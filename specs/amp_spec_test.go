@@ -0,0 +1,74 @@
+package specs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferAMPSpec(t *testing.T) {
+	binary := buildBinary(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/recipes/bread":
+			_, _ = w.Write([]byte(`<html><head><title>Bread</title><link rel="amphtml" href="/amp/recipes/bread"></head>
+<body><article><h1>Sourdough Bread</h1><p>The full page version, wrapped in popups and a comment widget.</p></article></body></html>`))
+		case "/amp/recipes/bread":
+			_, _ = w.Write([]byte(`<html amp><head><title>Bread</title><link rel="canonical" href="/recipes/bread"></head>
+<body><article><h1>Sourdough Bread</h1><p>The lightweight AMP version with just the recipe steps.</p></article></body></html>`))
+		case "/recipes/cake":
+			_, _ = w.Write([]byte(`<html><head><title>Cake</title><link rel="amphtml" href="/amp/recipes/cake"></head>
+<body><article><h1>Sponge Cake</h1><p>The page version of the sponge cake recipe, with every step.</p></article></body></html>`))
+		case "/amp/recipes/cake":
+			_, _ = w.Write([]byte(`<html amp><head><title>Cake</title></head><body></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("fetches_amp_version", func(t *testing.T) {
+		t.Log("SPEC: AMP Preference")
+		t.Log("GIVEN a page that links to its AMP version with rel=amphtml")
+		t.Log("WHEN the user runs sz --prefer-amp on it")
+		t.Log("THEN the AMP version should be distilled")
+
+		output, err := exec.Command(binary, "--prefer-amp", server.URL+"/recipes/bread").Output()
+		require.NoError(t, err)
+
+		assert.Contains(t, string(output), "lightweight AMP version")
+		assert.NotContains(t, string(output), "popups")
+	})
+
+	t.Run("falls_back_to_page", func(t *testing.T) {
+		t.Log("SPEC: AMP Preference")
+		t.Log("GIVEN a page whose AMP version has no content to extract")
+		t.Log("WHEN the user runs sz --prefer-amp on it")
+		t.Log("THEN the page itself should be distilled")
+
+		output, err := exec.Command(binary, "--prefer-amp", server.URL+"/recipes/cake").Output()
+		require.NoError(t, err)
+
+		assert.Contains(t, string(output), "every step")
+	})
+
+	t.Run("meta_reports_amp", func(t *testing.T) {
+		t.Log("SPEC: AMP Preference")
+		t.Log("GIVEN the page with an AMP version")
+		t.Log("WHEN the user runs sz meta on it")
+		t.Log("THEN the AMP URL should be reported")
+
+		output, err := exec.Command(binary, "meta", server.URL+"/recipes/bread").Output()
+		require.NoError(t, err)
+
+		var meta map[string]any
+		require.NoError(t, json.Unmarshal(output, &meta), string(output))
+		assert.Equal(t, server.URL+"/amp/recipes/bread", meta["amp"])
+	})
+}
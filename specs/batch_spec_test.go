@@ -0,0 +1,237 @@
+package specs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchSpec(t *testing.T) {
+	t.Run("batch_reports_failures_and_continues", func(t *testing.T) {
+		t.Log("SPEC: Batch Processing")
+		t.Log("GIVEN a list on stdin with a readable page, a comment, and a missing file")
+		t.Log("WHEN the user runs sz batch -")
+		t.Log("THEN the page should be distilled to stdout, the missing file reported on stderr, and the command should exit with an error")
+
+		binary := buildBinary(t)
+
+		dir := t.TempDir()
+		page := filepath.Join(dir, "article.html")
+		require.NoError(t, os.WriteFile(page, []byte(`<html><body><article><h1>Batch Article</h1><p>The first page of the batch has enough text to be extracted as the main content.</p></article></body></html>`), 0o644))
+		missing := filepath.Join(dir, "missing.html")
+
+		cmd := exec.Command(binary, "batch", "-")
+		cmd.Stdin = strings.NewReader("# pages to read\n" + missing + "\n\n" + page + "\n")
+		var stdout, stderr strings.Builder
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		require.Error(t, err, "Batch with a failed target should exit with an error")
+
+		assert.Contains(t, stdout.String(), "<!-- source: "+page+" -->", "Each result should name its source")
+		assert.Contains(t, stdout.String(), "Batch Article", "The readable page should be distilled")
+		assert.Contains(t, stderr.String(), missing, "The failed target should be reported")
+		assert.Contains(t, stderr.String(), "Distilled 1 of 2 targets", "Comments and blank lines should be skipped")
+	})
+
+	t.Run("batch_writes_one_file_per_url", func(t *testing.T) {
+		t.Log("SPEC: Batch Output Directory")
+		t.Log("GIVEN a file listing two URLs")
+		t.Log("WHEN the user runs sz batch --output-dir DIR FILE")
+		t.Log("THEN each URL should be saved to its own markdown file named after it")
+
+		binary := buildBinary(t)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`<html><body><article><h1>Page ` + r.URL.Path + `</h1><p>Each page in this batch has enough text to be extracted as the main content.</p></article></body></html>`))
+		}))
+		defer server.Close()
+
+		dir := t.TempDir()
+		list := filepath.Join(dir, "urls.txt")
+		require.NoError(t, os.WriteFile(list, []byte(server.URL+"/first\n"+server.URL+"/second\n"), 0o644))
+		outDir := filepath.Join(dir, "out")
+
+		output, err := exec.Command(binary, "batch", "--output-dir", outDir, list).CombinedOutput()
+		require.NoError(t, err, "Batch should succeed: %s", string(output))
+
+		entries, err := os.ReadDir(outDir)
+		require.NoError(t, err)
+		require.Len(t, entries, 2, "Each URL should get its own file")
+		for _, entry := range entries {
+			assert.True(t, strings.HasSuffix(entry.Name(), ".md"), "Results should be markdown files")
+		}
+
+		data, err := os.ReadFile(filepath.Join(outDir, entries[0].Name()))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "Page /first", "Files should hold the distilled page")
+	})
+	t.Run("batch_limits_fetches_per_host_and_keeps_order", func(t *testing.T) {
+		t.Log("SPEC: Concurrent Batch Fetching")
+		t.Log("GIVEN four slow pages on one host")
+		t.Log("WHEN the user runs sz batch --concurrency 4 --per-host 2")
+		t.Log("THEN no more than two pages should be fetched from the host at once and results should keep the list order")
+
+		binary := buildBinary(t)
+
+		var mu sync.Mutex
+		inFlight, maxInFlight := 0, 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			inFlight++
+			maxInFlight = max(maxInFlight, inFlight)
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+			}()
+
+			time.Sleep(200 * time.Millisecond)
+			_, _ = w.Write([]byte(`<html><body><article><h1>Page ` + r.URL.Path + `</h1><p>Each page in this batch has enough text to be extracted as the main content.</p></article></body></html>`))
+		}))
+		defer server.Close()
+
+		var list strings.Builder
+		for _, path := range []string{"/a", "/b", "/c", "/d"} {
+			list.WriteString(server.URL + path + "\n")
+		}
+
+		cmd := exec.Command(binary, "batch", "--concurrency", "4", "--per-host", "2", "-")
+		cmd.Stdin = strings.NewReader(list.String())
+		output, err := cmd.Output()
+		require.NoError(t, err, "Batch should succeed")
+
+		mu.Lock()
+		assert.LessOrEqual(t, maxInFlight, 2, "At most two fetches should hit the host at once")
+		mu.Unlock()
+
+		text := string(output)
+		positions := []int{strings.Index(text, "Page /a"), strings.Index(text, "Page /b"), strings.Index(text, "Page /c"), strings.Index(text, "Page /d")}
+		for i, position := range positions {
+			require.NotEqual(t, -1, position, "Every page should be in the output")
+			if i > 0 {
+				assert.Greater(t, position, positions[i-1], "Results should keep the list order")
+			}
+		}
+	})
+	t.Run("batch_output_template_skips_existing_files", func(t *testing.T) {
+		t.Log("SPEC: Templated Output Paths")
+		t.Log("GIVEN a list of URLs and an output template of {{.Host}}/{{.Path}}.md")
+		t.Log("WHEN the batch runs twice with --if-exists skip")
+		t.Log("THEN the first run should create the directories and files and the second should fetch nothing")
+
+		binary := buildBinary(t)
+
+		var mu sync.Mutex
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests++
+			mu.Unlock()
+			_, _ = w.Write([]byte(`<html><body><article><h1>Page ` + r.URL.Path + `</h1><p>Each page in this batch has enough text to be extracted as the main content.</p></article></body></html>`))
+		}))
+		defer server.Close()
+
+		dir := t.TempDir()
+		list := filepath.Join(dir, "urls.txt")
+		require.NoError(t, os.WriteFile(list, []byte(server.URL+"/docs/intro\n"+server.URL+"/docs/setup\n"), 0o644))
+		template := filepath.Join(dir, "archive", "{{.Host}}", "{{.Path}}.md")
+
+		output, err := exec.Command(binary, "batch", "-o", template, "--if-exists", "skip", list).CombinedOutput()
+		require.NoError(t, err, "Batch should succeed: %s", string(output))
+
+		host := strings.ReplaceAll(strings.TrimPrefix(server.URL, "http://"), ":", "-")
+		data, err := os.ReadFile(filepath.Join(dir, "archive", host, "docs", "intro.md"))
+		require.NoError(t, err, "The template should decide the file's directory and name")
+		assert.Contains(t, string(data), "Page /docs/intro")
+		assert.FileExists(t, filepath.Join(dir, "archive", host, "docs", "setup.md"))
+
+		mu.Lock()
+		fetched := requests
+		mu.Unlock()
+
+		output, err = exec.Command(binary, "batch", "-o", template, "--if-exists", "skip", list).CombinedOutput()
+		require.NoError(t, err, "Rerun should succeed: %s", string(output))
+		assert.Contains(t, string(output), "2 skipped", "Existing files should be skipped")
+
+		mu.Lock()
+		assert.Equal(t, fetched, requests, "Skipped targets should not be fetched")
+		mu.Unlock()
+	})
+
+	t.Run("batch_writes_jsonl_corpus", func(t *testing.T) {
+		t.Log("SPEC: JSONL Corpus Export")
+		t.Log("GIVEN a list of a page with metadata and a page that fails")
+		t.Log("WHEN the user runs sz batch --jsonl FILE")
+		t.Log("THEN stdout should hold one JSON object per URL with its markdown and metadata, or its error, each timed from its own fetch")
+
+		binary := buildBinary(t)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/gone" {
+				time.Sleep(200 * time.Millisecond)
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(`<html lang="en"><head><title>Corpus Page</title><meta name="author" content="Ada"></head><body><article><h1>Corpus Page</h1><p>Each page in this corpus has enough text to be extracted as the main content.</p></article></body></html>`))
+		}))
+		defer server.Close()
+
+		list := filepath.Join(t.TempDir(), "urls.txt")
+		require.NoError(t, os.WriteFile(list, []byte(server.URL+"/page\n"+server.URL+"/gone\n"), 0o644))
+
+		cmd := exec.Command(binary, "batch", "--jsonl", list)
+		var stdout, stderr strings.Builder
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		started := time.Now()
+		require.Error(t, cmd.Run(), "A failed target should still fail the run")
+
+		type record struct {
+			URL        string    `json:"url"`
+			Title      string    `json:"title"`
+			Markdown   string    `json:"markdown"`
+			FetchedAt  time.Time `json:"fetched_at"`
+			DurationMS int64     `json:"duration_ms"`
+			Error      string    `json:"error"`
+			Metadata   *struct {
+				Author   string `json:"author"`
+				Language string `json:"language"`
+			} `json:"metadata"`
+		}
+		lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+		require.Len(t, lines, 2, "Each URL should get one line")
+		var records []record
+		for _, line := range lines {
+			var r record
+			require.NoError(t, json.Unmarshal([]byte(line), &r), "Each line should be JSON: %s", line)
+			records = append(records, r)
+		}
+
+		assert.Equal(t, server.URL+"/page", records[0].URL, "Records should follow the list order")
+		assert.Equal(t, "Corpus Page", records[0].Title)
+		assert.Contains(t, records[0].Markdown, "enough text to be extracted")
+		require.NotNil(t, records[0].Metadata)
+		assert.Equal(t, "Ada", records[0].Metadata.Author)
+		assert.Equal(t, "en", records[0].Metadata.Language)
+		assert.WithinDuration(t, time.Now(), records[0].FetchedAt, time.Minute)
+		assert.Empty(t, records[0].Error)
+
+		assert.Equal(t, server.URL+"/gone", records[1].URL)
+		assert.Contains(t, records[1].Error, "404")
+		assert.Empty(t, records[1].Markdown)
+		assert.GreaterOrEqual(t, records[1].DurationMS, int64(200), "A failed record should say how long its fetch took")
+		assert.WithinDuration(t, started, records[1].FetchedAt, 5*time.Second, "A failed record should say when its fetch started")
+	})
+}
@@ -0,0 +1,79 @@
+package specs
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookmarkSpec(t *testing.T) {
+	binary := buildBinary(t)
+	configDir := t.TempDir()
+	page := filepath.Join(t.TempDir(), "article.html")
+	require.NoError(t, os.WriteFile(page, []byte(`<html><body><article>
+<h1>Reading Later</h1>
+<p>The first version of this article, long enough to count as content.</p>
+</article></body></html>`), 0o644))
+
+	run := func(t *testing.T, args ...string) string {
+		cmd := exec.Command(binary, append([]string{"bookmark"}, args...)...)
+		cmd.Env = append(os.Environ(), "XDG_CONFIG_HOME="+configDir, "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+		output, err := cmd.Output()
+		require.NoError(t, err, string(output))
+		return string(output)
+	}
+
+	t.Run("bookmark_add_with_cache", func(t *testing.T) {
+		t.Log("SPEC: Cached Bookmark")
+		t.Log("GIVEN a local article")
+		t.Log("WHEN the user runs sz bookmark add --cache --tag reading FILE")
+		t.Log("THEN the bookmark should be saved with its tag and titled after the article's first heading")
+
+		output := run(t, "add", "--cache", "--tag", "reading", page)
+		assert.Equal(t, "Bookmarked "+page+" as 1\n", output)
+
+		output = run(t, "list")
+		assert.Equal(t, "1\t"+page+"\treading\tReading Later\n", output)
+	})
+
+	t.Run("bookmark_open_reads_cache", func(t *testing.T) {
+		t.Log("SPEC: Reading the Cached Copy")
+		t.Log("GIVEN the cached bookmark, and the article has since changed")
+		t.Log("WHEN the user runs sz bookmark open 1")
+		t.Log("THEN the copy saved with the bookmark should be printed, until --refresh replaces it")
+
+		require.NoError(t, os.WriteFile(page, []byte(`<html><body><article>
+<h1>Reading Later</h1>
+<p>A rewritten second version of this article, also long enough to count.</p>
+</article></body></html>`), 0o644))
+
+		output := run(t, "open", "1")
+		assert.Contains(t, output, "The first version")
+
+		output = run(t, "open", "--refresh", page)
+		assert.Contains(t, output, "A rewritten second version")
+		output = run(t, "open", "1")
+		assert.Contains(t, output, "A rewritten second version")
+	})
+
+	t.Run("bookmark_list_by_tag", func(t *testing.T) {
+		t.Log("SPEC: Tags")
+		t.Log("GIVEN a second bookmark with a different tag")
+		t.Log("WHEN the user runs sz bookmark list --tag news --json")
+		t.Log("THEN only the bookmark with that tag should be listed")
+
+		run(t, "add", "--tag", "news", "https://example.com/news")
+
+		var bookmarks []map[string]any
+		require.NoError(t, json.Unmarshal([]byte(run(t, "list", "--tag", "news", "--json")), &bookmarks))
+		require.Len(t, bookmarks, 1)
+		assert.Equal(t, float64(2), bookmarks[0]["id"])
+		assert.Equal(t, "https://example.com/news", bookmarks[0]["url"])
+		assert.Nil(t, bookmarks[0]["cached"])
+	})
+}
@@ -0,0 +1,73 @@
+package specs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrowserContextSpec(t *testing.T) {
+	t.Run("profile_keeps_cookies", func(t *testing.T) {
+		t.Log("SPEC: Persistent Browser Profiles")
+		t.Log("GIVEN a site that sets a cookie on the first visit")
+		t.Log("WHEN the user fetches it twice with --profile and once with --incognito")
+		t.Log("THEN the second profile fetch should carry the cookie and the incognito fetch should not")
+
+		binary := buildBinary(t)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			visitor := "new visitor"
+			if cookie, err := r.Cookie("visited"); err == nil {
+				visitor = "returning visitor " + cookie.Value
+			}
+			http.SetCookie(w, &http.Cookie{Name: "visited", Value: "yes", Path: "/", MaxAge: 3600})
+			_, _ = fmt.Fprintf(w, `<html><body><article><h1>Welcome</h1><p>Hello, %s.</p></article></body></html>`, visitor)
+		}))
+		defer server.Close()
+
+		env := append(os.Environ(), "XDG_CACHE_HOME="+t.TempDir())
+		fetch := func(args ...string) string {
+			cmd := exec.Command(binary, append(args, server.URL)...)
+			cmd.Env = env
+			output, err := cmd.CombinedOutput()
+			require.NoError(t, err, "Fetch should succeed: %s", string(output))
+			return string(output)
+		}
+
+		assert.Contains(t, fetch("--profile", "spec"), "new visitor", "First visit in a profile should have no cookies")
+		assert.Contains(t, fetch("--profile", "spec"), "returning visitor yes", "Profile should keep the cookie")
+		assert.Contains(t, fetch("--incognito"), "new visitor", "Incognito should not see the profile's cookies")
+	})
+
+	t.Run("incognito_and_profile_conflict", func(t *testing.T) {
+		t.Log("SPEC: Conflicting Browser Contexts")
+		t.Log("GIVEN both --incognito and --profile")
+		t.Log("WHEN the command runs")
+		t.Log("THEN it should fail and explain that they cannot be combined")
+
+		cmd := exec.Command("go", "run", "../cmd/essenz/main.go", "--incognito", "--profile", "work", "https://example.com")
+		output, err := cmd.CombinedOutput()
+		require.Error(t, err, "Command should fail when both are given")
+
+		assert.Contains(t, string(output), "cannot be combined", "Should explain the conflict")
+	})
+
+	t.Run("invalid_profile_name_rejected", func(t *testing.T) {
+		t.Log("SPEC: Invalid Profile Name")
+		t.Log("GIVEN a --profile name containing a path separator")
+		t.Log("WHEN the command runs")
+		t.Log("THEN it should fail instead of writing outside the profile directory")
+
+		cmd := exec.Command("go", "run", "../cmd/essenz/main.go", "--profile", "../escape", "https://example.com")
+		output, err := cmd.CombinedOutput()
+		require.Error(t, err, "Command should fail with an invalid profile name")
+
+		assert.Contains(t, string(output), "invalid profile name", "Should name the problem")
+	})
+}
@@ -0,0 +1,84 @@
+package specs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFollowCanonicalSpec(t *testing.T) {
+	binary := buildBinary(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/partner/reprint":
+			_, _ = w.Write([]byte(`<html><head><title>Reprint</title><meta property="og:url" content="/essays/lighthouses"></head>
+<body><article><h1>Lighthouses</h1><p>An excerpt reprinted by a partner site, cut short after the first section.</p></article></body></html>`))
+		case "/essays/lighthouses":
+			_, _ = w.Write([]byte(`<html><head><title>Lighthouses</title><link rel="canonical" href="/essays/lighthouses"></head>
+<body><article><h1>Lighthouses</h1><p>The complete essay on how lighthouse keepers tended their lamps through long winters.</p></article></body></html>`))
+		case "/moved":
+			_, _ = w.Write([]byte(`<html><head><link rel="canonical" href="/gone"></head>
+<body><article><h1>Moved</h1><p>This copy names a canonical page that no longer exists on the site.</p></article></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("follows_canonical", func(t *testing.T) {
+		t.Log("SPEC: Canonical URL Resolution")
+		t.Log("GIVEN a syndicated copy with tracking parameters whose og:url names the original")
+		t.Log("WHEN the user runs sz --follow-canonical on it")
+		t.Log("THEN the original page should be distilled instead")
+
+		output, err := exec.Command(binary, "--follow-canonical", server.URL+"/partner/reprint?utm_source=newsletter").Output()
+		require.NoError(t, err)
+
+		assert.Contains(t, string(output), "complete essay")
+		assert.NotContains(t, string(output), "excerpt reprinted")
+	})
+
+	t.Run("off_by_default", func(t *testing.T) {
+		t.Log("SPEC: Canonical URL Resolution")
+		t.Log("GIVEN the same syndicated copy")
+		t.Log("WHEN the user runs sz without --follow-canonical")
+		t.Log("THEN the copy itself should be distilled")
+
+		output, err := exec.Command(binary, server.URL+"/partner/reprint?utm_source=newsletter").Output()
+		require.NoError(t, err)
+
+		assert.Contains(t, string(output), "excerpt reprinted")
+	})
+
+	t.Run("unreachable_canonical", func(t *testing.T) {
+		t.Log("SPEC: Canonical URL Resolution")
+		t.Log("GIVEN a page whose canonical URL answers 404")
+		t.Log("WHEN the user runs sz fetch --follow-canonical on it")
+		t.Log("THEN the page as fetched should be used")
+
+		output, err := exec.Command(binary, "fetch", "--follow-canonical", "-r", server.URL+"/moved").Output()
+		require.NoError(t, err)
+
+		assert.Contains(t, string(output), "no longer exists")
+	})
+
+	t.Run("meta_reports_og_url", func(t *testing.T) {
+		t.Log("SPEC: Canonical URL Resolution")
+		t.Log("GIVEN a page that gives its canonical only as a relative og:url")
+		t.Log("WHEN the user runs sz meta on it")
+		t.Log("THEN the canonical should be reported as an absolute URL")
+
+		output, err := exec.Command(binary, "meta", server.URL+"/partner/reprint?utm_source=newsletter").Output()
+		require.NoError(t, err)
+
+		var meta map[string]any
+		require.NoError(t, json.Unmarshal(output, &meta), string(output))
+		assert.Equal(t, server.URL+"/essays/lighthouses", meta["canonical"])
+	})
+}
@@ -0,0 +1,79 @@
+package specs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestCharsetSpec(t *testing.T) {
+	binary := buildBinary(t)
+
+	shiftJIS, err := japanese.ShiftJIS.NewEncoder().String(`<html><head><meta charset="Shift_JIS"><title>東京</title></head><body><article>
+<h1>東京の運河</h1><p>江戸時代には多くの運河が物資を運んでいました。</p></article></body></html>`)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latin1":
+			// "Café crème" in ISO-8859-1, declared only in the header
+			w.Header().Set("Content-Type", "text/html; charset=ISO-8859-1")
+			_, _ = w.Write([]byte("<html><body><article><h1>Caf\xe9 cr\xe8me</h1><p>Le caf\xe9 du matin se boit au comptoir, debout et sans fa\xe7on.</p></article></body></html>"))
+		case "/shift-jis":
+			// Declared only in the page's own <meta charset>
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(shiftJIS))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("header_charset", func(t *testing.T) {
+		t.Log("SPEC: Character Encodings")
+		t.Log("GIVEN a page served as ISO-8859-1 in its Content-Type header")
+		t.Log("WHEN the user runs sz on it")
+		t.Log("THEN the accented text should come out as UTF-8")
+
+		output, err := exec.Command(binary, server.URL+"/latin1").Output()
+		require.NoError(t, err)
+
+		assert.Contains(t, string(output), "# Café crème")
+		assert.Contains(t, string(output), "sans façon")
+	})
+
+	t.Run("meta_charset", func(t *testing.T) {
+		t.Log("SPEC: Character Encodings")
+		t.Log("GIVEN a Shift_JIS page declaring its encoding with <meta charset>")
+		t.Log("WHEN the user runs sz on it")
+		t.Log("THEN the Japanese text should come out as UTF-8")
+
+		output, err := exec.Command(binary, server.URL+"/shift-jis").Output()
+		require.NoError(t, err)
+
+		assert.Contains(t, string(output), "# 東京の運河")
+		assert.Contains(t, string(output), "物資を運んでいました")
+	})
+
+	t.Run("local_file", func(t *testing.T) {
+		t.Log("SPEC: Character Encodings")
+		t.Log("GIVEN the Shift_JIS page saved to a file")
+		t.Log("WHEN the user runs sz on the file")
+		t.Log("THEN the Japanese text should come out as UTF-8")
+
+		page := filepath.Join(t.TempDir(), "tokyo.html")
+		require.NoError(t, os.WriteFile(page, []byte(shiftJIS), 0o644))
+
+		output, err := exec.Command(binary, page).Output()
+		require.NoError(t, err)
+
+		assert.Contains(t, string(output), "# 東京の運河")
+	})
+}
@@ -0,0 +1,119 @@
+package specs
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChromeInstallSpec(t *testing.T) {
+	t.Run("install_downloads_pinned_chrome", func(t *testing.T) {
+		t.Log("SPEC: Managed Chrome Download")
+		t.Log("GIVEN a machine without Chrome and a mirror serving Chrome for Testing archives")
+		t.Log("WHEN the user runs sz chrome install")
+		t.Log("THEN the archive should be unpacked into the sz cache directory with an executable binary that sz chrome path reports")
+
+		if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
+			t.Skip("archive layout below is for linux64")
+		}
+
+		binary := buildBinary(t)
+
+		var archive bytes.Buffer
+		writer := zip.NewWriter(&archive)
+		header := &zip.FileHeader{Name: "chrome-headless-shell-linux64/chrome-headless-shell", Method: zip.Deflate}
+		header.SetMode(0o755)
+		entry, err := writer.CreateHeader(header)
+		require.NoError(t, err)
+		_, err = entry.Write([]byte("#!/bin/sh\necho fake chrome\n"))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		var requested string
+		mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requested = r.URL.Path
+			_, _ = w.Write(archive.Bytes())
+		}))
+		defer mirror.Close()
+
+		cacheDir := t.TempDir()
+		env := append(os.Environ(), "XDG_CACHE_HOME="+cacheDir, "ESSENZ_CHROME_DOWNLOAD_URL="+mirror.URL)
+
+		install := exec.Command(binary, "chrome", "install")
+		install.Env = env
+		output, err := install.CombinedOutput()
+		require.NoError(t, err, "Install should succeed: %s", string(output))
+		assert.Contains(t, requested, "/linux64/chrome-headless-shell-linux64.zip", "Should download the linux64 archive")
+
+		path := exec.Command(binary, "chrome", "path")
+		path.Env = env
+		output, err = path.Output()
+		require.NoError(t, err, "Path should succeed after install")
+
+		installed := strings.TrimSpace(string(output))
+		assert.True(t, strings.HasPrefix(installed, filepath.Join(cacheDir, "essenz", "chrome")), "Chrome should live in the sz cache directory")
+		info, err := os.Stat(installed)
+		require.NoError(t, err, "Installed binary should exist")
+		assert.NotZero(t, info.Mode()&0o100, "Installed binary should be executable")
+	})
+
+	t.Run("chrome_path_list_is_authoritative", func(t *testing.T) {
+		t.Log("SPEC: Configurable Chrome Discovery")
+		t.Log("GIVEN a daemon whose ESSENZ_CHROME_PATH lists only browsers that do not exist")
+		t.Log("WHEN a page is fetched")
+		t.Log("THEN the daemon should not fall back to other browsers and should report the list it searched")
+
+		binary := buildBinary(t)
+
+		page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`<html><body><article><h1>Hello</h1><p>Static page.</p></article></body></html>`))
+		}))
+		defer page.Close()
+
+		socket := filepath.Join(t.TempDir(), "sz.sock")
+		chromePath := strings.Join([]string{"/missing/chrome", "/missing/edge"}, string(os.PathListSeparator))
+		daemon := exec.Command(binary, "--socket", socket, "daemon", "start")
+		daemon.Env = append(os.Environ(), "ESSENZ_CHROME_PATH="+chromePath)
+		require.NoError(t, daemon.Start(), "Daemon should start")
+		defer func() {
+			_ = daemon.Process.Kill()
+			_ = daemon.Wait()
+		}()
+
+		require.Eventually(t, func() bool {
+			output, err := exec.Command(binary, "--socket", socket, "daemon", "status").CombinedOutput()
+			return err == nil && strings.Contains(string(output), "is running")
+		}, 10*time.Second, 100*time.Millisecond, "Daemon should answer")
+
+		_ = exec.Command(binary, "--socket", socket, page.URL).Run()
+
+		output, err := exec.Command(binary, "--socket", socket, "daemon", "status", "--json").CombinedOutput()
+		require.NoError(t, err)
+		assert.Contains(t, string(output), "no browser found at ESSENZ_CHROME_PATH="+chromePath, "Daemon should report the paths it searched")
+	})
+
+	t.Run("path_without_install_fails", func(t *testing.T) {
+		t.Log("SPEC: Missing Managed Chrome")
+		t.Log("GIVEN no downloaded Chrome")
+		t.Log("WHEN the user runs sz chrome path")
+		t.Log("THEN it should fail and suggest sz chrome install")
+
+		cmd := exec.Command("go", "run", "../cmd/essenz/main.go", "chrome", "path")
+		cmd.Env = append(os.Environ(), "XDG_CACHE_HOME="+t.TempDir())
+		output, err := cmd.CombinedOutput()
+		require.Error(t, err, "Path should fail when Chrome is not installed")
+
+		assert.Contains(t, string(output), "sz chrome install", "Should suggest installing Chrome")
+	})
+}
@@ -0,0 +1,96 @@
+package specs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkSpec(t *testing.T) {
+	var page strings.Builder
+	page.WriteString("<html><body><article><h1>Field Guide</h1>")
+	for _, section := range []string{"Birds", "Insects", "Mosses"} {
+		fmt.Fprintf(&page, "<h2>%s</h2>", section)
+		for i := 1; i <= 4; i++ {
+			fmt.Fprintf(&page, "<p>%s paragraph %d describes what to look for on a walk through the woods in spring.</p>", section, i)
+		}
+	}
+	page.WriteString("</article></body></html>")
+	file := filepath.Join(t.TempDir(), "guide.html")
+	require.NoError(t, os.WriteFile(file, []byte(page.String()), 0o644))
+	binary := buildBinary(t)
+
+	t.Run("chunks_at_boundaries_with_overlap", func(t *testing.T) {
+		t.Log("SPEC: Chunked Output")
+		t.Log("GIVEN a long page with several sections")
+		t.Log("WHEN the user runs sz --chunk-size 250 --chunk-overlap 100")
+		t.Log("THEN the content should be printed as JSON lines of chunks no longer than 250 characters, cut between paragraphs, each repeating the end of the one before")
+
+		output, err := exec.Command(binary, "--chunk-size", "250", "--chunk-overlap", "100", file).Output()
+		require.NoError(t, err)
+
+		type chunk struct {
+			Title   string `json:"title"`
+			Index   int    `json:"index"`
+			Total   int    `json:"total"`
+			Section string `json:"section"`
+			Content string `json:"content"`
+		}
+		var chunks []chunk
+		for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+			var c chunk
+			require.NoError(t, json.Unmarshal([]byte(line), &c), "Each line should be a JSON chunk: %s", line)
+			chunks = append(chunks, c)
+		}
+		require.Greater(t, len(chunks), 3, "A long page should give several chunks")
+
+		for i, c := range chunks {
+			assert.Equal(t, "Field Guide", c.Title)
+			assert.Equal(t, i, c.Index)
+			assert.Equal(t, len(chunks), c.Total)
+			assert.LessOrEqual(t, utf8.RuneCountInString(c.Content), 250, "Chunk %d is too long", i)
+			assert.True(t, strings.HasSuffix(c.Content, ".") || strings.HasPrefix(c.Content, "#"), "Chunk %d should end at a paragraph: %q", i, c.Content)
+		}
+
+		// Sections start their own chunk and name where the chunk is
+		var sections []string
+		for _, c := range chunks {
+			if strings.HasPrefix(c.Content, "## ") {
+				sections = append(sections, c.Section)
+			}
+		}
+		assert.Contains(t, sections, "Field Guide > Insects")
+		assert.Contains(t, sections, "Field Guide > Mosses")
+
+		// A chunk continuing a section starts with the last paragraph of the one before
+		for i := 1; i < len(chunks); i++ {
+			if strings.HasPrefix(chunks[i].Content, "#") {
+				continue
+			}
+			first := strings.SplitN(chunks[i].Content, "\n\n", 2)[0]
+			assert.Contains(t, chunks[i-1].Content, first, "Chunk %d should overlap the one before", i)
+		}
+	})
+
+	t.Run("invalid_chunk_overlap_rejected", func(t *testing.T) {
+		t.Log("SPEC: Invalid Chunk Overlap")
+		t.Log("GIVEN an overlap as large as the chunk size")
+		t.Log("WHEN the command runs")
+		t.Log("THEN it should fail with a usage error")
+
+		cmd := exec.Command(binary, "--chunk-size", "100", "--chunk-overlap", "100", file)
+		output, err := cmd.CombinedOutput()
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 2, exitErr.ExitCode())
+		assert.Contains(t, string(output), "--chunk-overlap")
+	})
+}
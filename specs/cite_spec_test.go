@@ -0,0 +1,87 @@
+package specs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCiteSpec(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "tides.html")
+	require.NoError(t, os.WriteFile(page, []byte(`<html><head><title>Tide Pools</title>
+<meta name="author" content="Ada Lovelace and Charles Babbage">
+<meta property="article:published_time" content="2024-03-05T10:00:00Z">
+<meta property="og:site_name" content="Ocean Notes">
+<link rel="canonical" href="https://ocean.example.com/tide-pools">
+</head><body><article><p>Tide pools form where the sea leaves water behind.</p></article></body></html>`), 0o644))
+	anonymous := filepath.Join(dir, "anonymous.html")
+	require.NoError(t, os.WriteFile(anonymous, []byte(`<html><head><title>Rock Pools</title>
+<link rel="canonical" href="https://www.shore.example.org/rock-pools"></head><body><p>Rock pools.</p></body></html>`), 0o644))
+	binary := buildBinary(t)
+
+	cite := func(t *testing.T, args ...string) string {
+		t.Helper()
+		output, err := exec.Command(binary, append([]string{"cite", "--accessed", "2026-10-15"}, args...)...).Output()
+		require.NoError(t, err)
+		return strings.TrimSpace(string(output))
+	}
+
+	t.Run("apa_citation", func(t *testing.T) {
+		t.Log("SPEC: Citations")
+		t.Log("GIVEN a page with two authors, a publication date, a site name and a canonical URL")
+		t.Log("WHEN the user runs sz cite")
+		t.Log("THEN an APA reference should be printed")
+
+		assert.Equal(t, "Lovelace, A., & Babbage, C. (2024, March 5). *Tide Pools*. Ocean Notes. https://ocean.example.com/tide-pools", cite(t, page))
+	})
+
+	t.Run("mla_citation", func(t *testing.T) {
+		t.Log("SPEC: Citations")
+		t.Log("GIVEN the same page")
+		t.Log("WHEN the user runs sz cite --style mla --accessed 2026-10-15")
+		t.Log("THEN an MLA entry with the access date should be printed")
+
+		assert.Equal(t, `Lovelace, Ada, and Charles Babbage. "Tide Pools." *Ocean Notes*, 5 Mar. 2024, ocean.example.com/tide-pools. Accessed 15 Oct. 2026.`, cite(t, "--style", "mla", page))
+	})
+
+	t.Run("bibtex_citation", func(t *testing.T) {
+		t.Log("SPEC: Citations")
+		t.Log("GIVEN the same page")
+		t.Log("WHEN the user runs sz cite --style bibtex")
+		t.Log("THEN a BibTeX entry keyed by author, year and title should be printed")
+
+		entry := cite(t, "--style", "bibtex", page)
+		assert.True(t, strings.HasPrefix(entry, "@misc{lovelace2024tide,\n"), entry)
+		assert.Contains(t, entry, "author = {Lovelace, Ada and Babbage, Charles}")
+		assert.Contains(t, entry, "year = {2024}")
+		assert.Contains(t, entry, "url = {https://ocean.example.com/tide-pools}")
+		assert.Contains(t, entry, "urldate = {2026-10-15}")
+	})
+
+	t.Run("citation_without_author_or_date", func(t *testing.T) {
+		t.Log("SPEC: Citations")
+		t.Log("GIVEN a page with neither author nor date nor site name")
+		t.Log("WHEN the user runs sz cite")
+		t.Log("THEN the title should lead, with n.d. for the date and the host as the site")
+
+		assert.Equal(t, "*Rock Pools*. (n.d.). shore.example.org. https://www.shore.example.org/rock-pools", cite(t, anonymous))
+	})
+
+	t.Run("rejects_unknown_style", func(t *testing.T) {
+		t.Log("SPEC: Citations")
+		t.Log("GIVEN a style sz does not know")
+		t.Log("WHEN the user runs sz cite --style chicago")
+		t.Log("THEN sz should exit with status 2")
+
+		err := exec.Command(binary, "cite", "--style", "chicago", page).Run()
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 2, exitErr.ExitCode())
+	})
+}
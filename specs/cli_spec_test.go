@@ -1,7 +1,13 @@
 package specs
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -74,3 +80,147 @@ func TestExecutableBinarySpec(t *testing.T) {
 	cleanupCmd := exec.Command("rm", "sz-test")
 	_ = cleanupCmd.Run()
 }
+
+func TestStdinInputSpec(t *testing.T) {
+	t.Log("SPEC: Stdin Input")
+	t.Log("GIVEN HTML piped to sz from another tool")
+	t.Log("WHEN the user runs `sz -` and `sz fetch -`")
+	t.Log("THEN the HTML should go through the same pipeline as a file: reader view by default, raw for fetch")
+
+	html := `<html><body><nav>Menu</nav><article><h1>Piped Article</h1><p>This article arrived on stdin and has enough text to be extracted as the main content.</p></article></body></html>`
+
+	cmd := exec.Command("go", "run", "../cmd/essenz/main.go", "-")
+	cmd.Stdin = strings.NewReader(html)
+	output, err := cmd.Output()
+	require.NoError(t, err, "Command should execute successfully")
+	assert.Contains(t, string(output), "# Piped Article", "Stdin should be converted with reader view")
+	assert.NotContains(t, string(output), "<article>", "Reader view should strip the markup")
+
+	cmd = exec.Command("go", "run", "../cmd/essenz/main.go", "fetch", "-")
+	cmd.Stdin = strings.NewReader(html)
+	output, err = cmd.Output()
+	require.NoError(t, err, "Command should execute successfully")
+	assert.Contains(t, string(output), "<article>", "Fetch should pass stdin through unchanged")
+}
+
+func TestLoggingSpec(t *testing.T) {
+	t.Log("SPEC: Structured Logging")
+	t.Log("GIVEN a URL fetch run with --log-level debug --log-format json")
+	t.Log("WHEN the command runs")
+	t.Log("THEN stderr should carry JSON log records from the browser client while stdout keeps the page")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><article><h1>Logged Page</h1><p>This page has enough text to be extracted as the main content.</p></article></body></html>`))
+	}))
+	defer server.Close()
+
+	binary := buildBinary(t)
+	socket := filepath.Join(t.TempDir(), "sz.sock")
+	cmd := exec.Command(binary, "--socket", socket, "--log-level", "debug", "--log-format", "json", server.URL)
+	cmd.Env = append(os.Environ(), "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+	var stdout, stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	require.NoError(t, cmd.Run(), stderr.String())
+
+	assert.Contains(t, stdout.String(), "Logged Page", "Logs should not mix into stdout")
+
+	found := false
+	for _, line := range strings.Split(strings.TrimSpace(stderr.String()), "\n") {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record), "Every stderr line should be a JSON record: %s", line)
+		if record["msg"] == "fetching through daemon" {
+			found = true
+			assert.Equal(t, "DEBUG", record["level"])
+			assert.Equal(t, server.URL, record["url"])
+		}
+	}
+	assert.True(t, found, "Debug records should come from the browser client")
+
+	cmd = exec.Command(binary, "--log-format", "xml", "version")
+	output, err := cmd.CombinedOutput()
+	require.Error(t, err, "Unknown log formats should be rejected")
+	assert.Contains(t, string(output), "use text or json")
+}
+
+func TestExitCodeSpec(t *testing.T) {
+	t.Log("SPEC: Exit Codes")
+	t.Log("GIVEN scripts that need to tell failures apart")
+	t.Log("WHEN sz fails for different reasons")
+	t.Log("THEN each kind of failure should exit with its own status")
+
+	binary := buildBinary(t)
+	exitStatus := func(t *testing.T, cmd *exec.Cmd) (int, string) {
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			return 0, string(output)
+		}
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr, string(output))
+		return exitErr.ExitCode(), string(output)
+	}
+
+	t.Run("invalid_flags", func(t *testing.T) {
+		t.Log("SPEC: An unknown flag exits 2")
+		code, output := exitStatus(t, exec.Command(binary, "--no-such-flag", "page.html"))
+		assert.Equal(t, 2, code, output)
+	})
+
+	t.Run("invalid_selector", func(t *testing.T) {
+		t.Log("SPEC: A selector that does not parse exits 2 and is named")
+		page := filepath.Join(t.TempDir(), "page.html")
+		require.NoError(t, os.WriteFile(page, []byte(`<html><body><article><h1>Selectors</h1><p>Some text.</p></article></body></html>`), 0o644))
+
+		code, output := exitStatus(t, exec.Command(binary, "--content-filter", "--exclude-selector", "div[", page))
+		assert.Equal(t, 2, code, output)
+		assert.Contains(t, output, `invalid selector "div["`)
+
+		code, output = exitStatus(t, exec.Command(binary, "fetch", "--preserve-selector", "article >", page))
+		assert.Equal(t, 2, code, output)
+		assert.Contains(t, output, `invalid selector "article >"`)
+	})
+
+	t.Run("invalid_site_rule_selector", func(t *testing.T) {
+		t.Log("SPEC: A saved site rule that does not parse exits 2 and is named")
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`<html><body><article><h1>Selectors</h1><p>Some text.</p></article></body></html>`))
+		}))
+		defer server.Close()
+
+		configDir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(configDir, "essenz", "sites"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, "essenz", "sites", "127.0.0.1.yaml"), []byte("host: 127.0.0.1\nexclude:\n  - \"p:not(\"\n"), 0o644))
+
+		cmd := exec.Command(binary, "--socket", filepath.Join(t.TempDir(), "sz.sock"), "--content-filter", server.URL)
+		cmd.Env = append(os.Environ(), "XDG_CONFIG_HOME="+configDir, "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+		code, output := exitStatus(t, cmd)
+		assert.Equal(t, 2, code, output)
+		assert.Contains(t, output, `site rules for 127.0.0.1: invalid selector "p:not("`)
+	})
+
+	t.Run("http_error_status", func(t *testing.T) {
+		t.Log("SPEC: A page that answers 404 exits 3")
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+
+		cmd := exec.Command(binary, "--socket", filepath.Join(t.TempDir(), "sz.sock"), server.URL)
+		cmd.Env = append(os.Environ(), "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+		code, output := exitStatus(t, cmd)
+		assert.Equal(t, 3, code, output)
+	})
+
+	t.Run("fail_on_empty", func(t *testing.T) {
+		t.Log("SPEC: --fail-on-empty exits 5 when the output is shorter than --min-words")
+		page := filepath.Join(t.TempDir(), "page.html")
+		require.NoError(t, os.WriteFile(page, []byte(`<html><body><article><h1>Short</h1><p>Only a few words here.</p></article></body></html>`), 0o644))
+
+		code, output := exitStatus(t, exec.Command(binary, "--fail-on-empty", page))
+		assert.Equal(t, 5, code, output)
+		assert.Contains(t, output, "fewer than --min-words 20")
+		assert.NotContains(t, output, "# Short", "Nothing should be written when the check fails")
+
+		code, output = exitStatus(t, exec.Command(binary, "--fail-on-empty", "--min-words", "3", page))
+		assert.Equal(t, 0, code, output)
+		assert.Contains(t, output, "# Short")
+	})
+}
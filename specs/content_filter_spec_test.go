@@ -3,6 +3,7 @@ package specs
 import (
 	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -545,6 +546,338 @@ func TestContentFilterSpec(t *testing.T) {
 		// Should preserve custom whitelisted content
 		assert.Contains(t, outputStr, "Custom navigation", "Should preserve custom whitelisted content")
 	})
+
+	t.Run("aggressive_mode_semantics", func(t *testing.T) {
+		t.Log("SPEC: Aggressive Filtering Mode")
+		t.Log("GIVEN HTML with borderline link-heavy text, uncaptioned figures and short sections")
+		t.Log("WHEN sz applies content filtering with --aggressive-filtering")
+		t.Log("THEN it should remove the borderline content that default filtering keeps")
+
+		binary := buildContentFilterBinary(t)
+
+		aggressiveHTML := `<!DOCTYPE html>
+<html>
+<head>
+    <title>Aggressive Mode Test</title>
+</head>
+<body>
+    <article>
+        <h1>Quarterly Report</h1>
+        <section>
+            <h2>Results</h2>
+            <p>Revenue grew steadily across every region this quarter, driven by strong demand for the new product line and improved retention.</p>
+            <p>See the <a href="/notes">full methodology notes</a> in our appendix for details on how the data was gathered.</p>
+        </section>
+        <figure>
+            <img src="chart.png" alt="Revenue chart">
+            <figcaption>Figure 1: Revenue by region</figcaption>
+        </figure>
+        <figure>
+            <img src="banner.png">
+            <span>Decorative banner illustration</span>
+        </figure>
+        <section>
+            <h2>Follow us</h2>
+            <p>Stay tuned for more updates.</p>
+        </section>
+    </article>
+</body>
+</html>`
+
+		tmpFile, err := os.CreateTemp("", "aggressive-test*.html")
+		require.NoError(t, err)
+		defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+		_, err = tmpFile.Write([]byte(aggressiveHTML))
+		require.NoError(t, err)
+		err = tmpFile.Close()
+		require.NoError(t, err)
+
+		// Default filtering keeps borderline content
+		cmd := exec.Command(binary, "--content-filter", tmpFile.Name())
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Command should succeed: %s", string(output))
+
+		outputStr := string(output)
+
+		assert.Contains(t, outputStr, "full methodology notes", "Default mode should keep moderately linked text")
+		assert.Contains(t, outputStr, "Decorative banner illustration", "Default mode should keep uncaptioned figures")
+		assert.Contains(t, outputStr, "Stay tuned", "Default mode should keep short sections")
+
+		// Aggressive filtering removes it
+		cmd = exec.Command(binary, "--content-filter", "--aggressive-filtering", tmpFile.Name())
+		output, err = cmd.CombinedOutput()
+		require.NoError(t, err, "Command should succeed: %s", string(output))
+
+		outputStr = string(output)
+
+		assert.Contains(t, outputStr, "Quarterly Report", "Should preserve article heading")
+		assert.Contains(t, outputStr, "Revenue grew steadily", "Should preserve substantial sections")
+		assert.Contains(t, outputStr, "Revenue by region", "Should preserve captioned figures")
+		assert.NotContains(t, outputStr, "full methodology notes", "Should apply the lower link density threshold")
+		assert.NotContains(t, outputStr, "Decorative banner illustration", "Should strip figures without captions")
+		assert.NotContains(t, outputStr, "Stay tuned", "Should drop short sections")
+	})
+
+	t.Run("external_filter_hook", func(t *testing.T) {
+		t.Log("SPEC: External Filter Hooks")
+		t.Log("GIVEN an executable hook that rewrites the JSON tree")
+		t.Log("WHEN sz applies content filtering with --filter-hook")
+		t.Log("THEN the output should reflect the tree returned by the hook")
+
+		binary := buildContentFilterBinary(t)
+
+		hookHTML := `<!DOCTYPE html>
+<html>
+<head>
+    <title>Filter Hook Test</title>
+</head>
+<body>
+    <article>
+        <h1>Hooked Article</h1>
+        <p>This paragraph mentions a secret codename that the hook redacts.</p>
+    </article>
+</body>
+</html>`
+
+		dir := t.TempDir()
+		htmlPath := filepath.Join(dir, "hook-test.html")
+		require.NoError(t, os.WriteFile(htmlPath, []byte(hookHTML), 0o644))
+
+		hookPath := filepath.Join(dir, "redact.sh")
+		require.NoError(t, os.WriteFile(hookPath, []byte("#!/bin/sh\nsed 's/secret codename/[redacted]/g'\n"), 0o755))
+
+		cmd := exec.Command(binary, "--filter-hook="+hookPath, htmlPath)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Command should succeed: %s", string(output))
+
+		outputStr := string(output)
+
+		assert.Contains(t, outputStr, "Hooked Article", "Should keep content returned by the hook")
+		assert.Contains(t, outputStr, "[redacted]", "Should apply the hook's modifications")
+		assert.NotContains(t, outputStr, "secret codename", "Should not fall back to the unhooked tree")
+
+		// A failing hook aborts instead of silently skipping
+		failPath := filepath.Join(dir, "fail.sh")
+		require.NoError(t, os.WriteFile(failPath, []byte("#!/bin/sh\necho 'hook exploded' >&2\nexit 3\n"), 0o755))
+
+		cmd = exec.Command(binary, "--filter-hook="+failPath, htmlPath)
+		output, err = cmd.CombinedOutput()
+		require.Error(t, err, "Command should fail when the hook fails")
+		assert.Contains(t, string(output), "hook exploded", "Should surface the hook's stderr")
+	})
+
+	t.Run("multilingual_keyword_filtering", func(t *testing.T) {
+		t.Log("SPEC: Multilingual Keyword Filtering")
+		t.Log("GIVEN a non-English HTML document with localized boilerplate class names")
+		t.Log("WHEN sz applies content filtering")
+		t.Log("THEN it should use the keyword pack for the declared page language")
+
+		binary := buildContentFilterBinary(t)
+
+		germanHTML := `<!DOCTYPE html>
+<html lang="de-DE">
+<head>
+    <title>Mehrsprachiger Test</title>
+</head>
+<body>
+    <div class="werbung">
+        <p>Jetzt kaufen und sparen, nur heute im Angebot!</p>
+    </div>
+    <div id="seitenleiste">
+        <p>Beliebte Artikel aus unserem gesamten Archiv.</p>
+    </div>
+    <article>
+        <h1>Hauptartikel</h1>
+        <p>Dies ist der eigentliche Inhalt des Artikels, der erhalten bleiben soll.</p>
+    </article>
+</body>
+</html>`
+
+		tmpFile, err := os.CreateTemp("", "multilingual-test*.html")
+		require.NoError(t, err)
+		defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+		_, err = tmpFile.Write([]byte(germanHTML))
+		require.NoError(t, err)
+		err = tmpFile.Close()
+		require.NoError(t, err)
+
+		cmd := exec.Command(binary, "--content-filter", tmpFile.Name())
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Command should succeed: %s", string(output))
+
+		outputStr := string(output)
+
+		// Should preserve the article
+		assert.Contains(t, outputStr, "Hauptartikel", "Should preserve article title")
+		assert.Contains(t, outputStr, "eigentliche Inhalt", "Should preserve article content")
+
+		// Should remove German boilerplate
+		assert.NotContains(t, outputStr, "Jetzt kaufen", "Should remove German advertisement block")
+		assert.NotContains(t, outputStr, "Beliebte Artikel", "Should remove German sidebar block")
+	})
+
+	t.Run("exclude_selector_removal", func(t *testing.T) {
+		t.Log("SPEC: Exclude Selector Removal")
+		t.Log("GIVEN HTML with site-specific junk inside whitelisted content")
+		t.Log("WHEN sz applies content filtering with --exclude-selector")
+		t.Log("THEN it should remove matching subtrees before any other rule")
+
+		binary := buildContentFilterBinary(t)
+
+		excludeHTML := `<!DOCTYPE html>
+<html>
+<head>
+    <title>Exclude Selector Test</title>
+</head>
+<body>
+    <article>
+        <h1>Article Heading</h1>
+        <p>The body of the article that should always be kept.</p>
+        <div class="newsletter-box">
+            <p>Subscribe to our weekly newsletter for more great stories.</p>
+        </div>
+        <div id="paywall-teaser">
+            <p>Become a member today to unlock unlimited reading.</p>
+        </div>
+    </article>
+</body>
+</html>`
+
+		tmpFile, err := os.CreateTemp("", "exclude-selector-test*.html")
+		require.NoError(t, err)
+		defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+		_, err = tmpFile.Write([]byte(excludeHTML))
+		require.NoError(t, err)
+		err = tmpFile.Close()
+		require.NoError(t, err)
+
+		cmd := exec.Command(binary, "--content-filter",
+			"--exclude-selector=.newsletter-box",
+			"--exclude-selector=#paywall-teaser",
+			tmpFile.Name())
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Command should succeed: %s", string(output))
+
+		outputStr := string(output)
+
+		assert.Contains(t, outputStr, "Article Heading", "Should preserve article heading")
+		assert.Contains(t, outputStr, "always be kept", "Should preserve article body")
+		assert.NotContains(t, outputStr, "weekly newsletter", "Should remove excluded class selector")
+		assert.NotContains(t, outputStr, "unlimited reading", "Should remove excluded id selector")
+	})
+
+	t.Run("css_selector_matching", func(t *testing.T) {
+		t.Log("SPEC: CSS Selector Matching")
+		t.Log("GIVEN exclusion rules using combinators, attributes and :not()")
+		t.Log("WHEN sz applies content filtering")
+		t.Log("THEN only elements matching the full selector should be removed")
+
+		binary := buildContentFilterBinary(t)
+
+		selectorHTML := `<!DOCTYPE html>
+<html>
+<head>
+    <title>Selector Test</title>
+</head>
+<body>
+    <article>
+        <h1>Selector Article</h1>
+        <div class="body">
+            <p>Direct body paragraph that must stay in the output.</p>
+            <p data-tracking="promo-inline">Inline promotion that should be removed.</p>
+        </div>
+        <div class="box note">
+            <p>Boxed note that belongs to the article content.</p>
+        </div>
+        <div class="box">
+            <p>Generic box that should be removed by the negated rule.</p>
+        </div>
+    </article>
+</body>
+</html>`
+
+		tmpFile, err := os.CreateTemp("", "selector-test*.html")
+		require.NoError(t, err)
+		defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+		_, err = tmpFile.Write([]byte(selectorHTML))
+		require.NoError(t, err)
+		err = tmpFile.Close()
+		require.NoError(t, err)
+
+		cmd := exec.Command(binary, "--content-filter",
+			"--exclude-selector=article > .body p[data-tracking^=promo]",
+			"--exclude-selector=article .box:not(.note)",
+			tmpFile.Name())
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Command should succeed: %s", string(output))
+
+		outputStr := string(output)
+
+		assert.Contains(t, outputStr, "Direct body paragraph", "Should keep paragraphs not matching the attribute selector")
+		assert.Contains(t, outputStr, "Boxed note", "Should keep elements excluded from :not()")
+		assert.NotContains(t, outputStr, "Inline promotion", "Should remove element matching attribute selector")
+		assert.NotContains(t, outputStr, "Generic box", "Should remove element matching negated selector")
+	})
+
+	t.Run("aria_role_filtering", func(t *testing.T) {
+		t.Log("SPEC: ARIA Role Filtering")
+		t.Log("GIVEN HTML with obfuscated class names but ARIA landmark roles")
+		t.Log("WHEN sz applies content filtering")
+		t.Log("THEN it should remove navigation, banner, complementary and contentinfo landmarks")
+
+		binary := buildContentFilterBinary(t)
+
+		ariaHTML := `<!DOCTYPE html>
+<html>
+<head>
+    <title>ARIA Role Test</title>
+</head>
+<body>
+    <div class="x7Fq2" role="banner">
+        <p>Brand masthead with a long tagline text.</p>
+    </div>
+    <div class="k9Lm1" role="navigation">
+        <p>Jump to the sections of this website.</p>
+    </div>
+    <div class="p0Zr4" role="main">
+        <h1>Landmark Article</h1>
+        <p>Article content inside the main landmark is preserved.</p>
+    </div>
+    <div class="a1Bc3" role="complementary">
+        <p>Trending stories from around the network.</p>
+    </div>
+    <div class="q2Wd8" role="contentinfo">
+        <p>Copyright notice and legal information.</p>
+    </div>
+</body>
+</html>`
+
+		tmpFile, err := os.CreateTemp("", "aria-role-test*.html")
+		require.NoError(t, err)
+		defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+		_, err = tmpFile.Write([]byte(ariaHTML))
+		require.NoError(t, err)
+		err = tmpFile.Close()
+		require.NoError(t, err)
+
+		cmd := exec.Command(binary, "--content-filter", tmpFile.Name())
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Command should succeed: %s", string(output))
+
+		outputStr := string(output)
+
+		assert.Contains(t, outputStr, "Landmark Article", "Should preserve main landmark heading")
+		assert.Contains(t, outputStr, "inside the main landmark", "Should preserve main landmark content")
+		assert.NotContains(t, outputStr, "Brand masthead", "Should remove banner landmark")
+		assert.NotContains(t, outputStr, "Jump to the sections", "Should remove navigation landmark")
+		assert.NotContains(t, outputStr, "Trending stories", "Should remove complementary landmark")
+		assert.NotContains(t, outputStr, "Copyright notice", "Should remove contentinfo landmark")
+	})
 }
 
 // buildContentFilterBinary builds the sz binary for testing content filter functionality
@@ -0,0 +1,107 @@
+package specs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCookieJarSpec(t *testing.T) {
+	t.Run("cookie_file_round_trip", func(t *testing.T) {
+		t.Log("SPEC: Cookie Jar Load and Save")
+		t.Log("GIVEN a cookies.txt file with a session cookie for a site")
+		t.Log("WHEN the user fetches the site with --cookies and --save-cookies")
+		t.Log("THEN the request should carry the cookie and the saved file should include cookies the site set")
+
+		binary := buildBinary(t)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.SetCookie(w, &http.Cookie{Name: "visited", Value: "yes", Path: "/"})
+			if cookie, err := r.Cookie("session"); err == nil && cookie.Value == "member" {
+				_, _ = w.Write([]byte(`<html><body><article><h1>Members Article</h1><p>Exclusive content for logged-in members only.</p></article></body></html>`))
+				return
+			}
+			_, _ = w.Write([]byte(`<html><body><article><h1>Paywall</h1><p>Please log in to continue reading this article.</p></article></body></html>`))
+		}))
+		defer server.Close()
+
+		dir := t.TempDir()
+		cookiesPath := filepath.Join(dir, "cookies.txt")
+		cookies := "# Netscape HTTP Cookie File\n127.0.0.1\tFALSE\t/\tFALSE\t0\tsession\tmember\n"
+		require.NoError(t, os.WriteFile(cookiesPath, []byte(cookies), 0o600))
+		savedPath := filepath.Join(dir, "saved.json")
+
+		cmd := exec.Command(binary, "--cookies", cookiesPath, "--save-cookies", savedPath, server.URL+"/article")
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Fetch should succeed: %s", string(output))
+
+		assert.Contains(t, string(output), "Members Article", "Should send the cookie from the file")
+		assert.NotContains(t, string(output), "Please log in", "Should not see the paywall")
+
+		saved, err := os.ReadFile(savedPath)
+		require.NoError(t, err, "Should write the saved cookie file")
+		assert.Contains(t, string(saved), `"session"`, "Should keep the loaded cookie")
+		assert.Contains(t, string(saved), `"visited"`, "Should include cookies the site set")
+	})
+
+	t.Run("loaded_cookies_stay_with_their_request", func(t *testing.T) {
+		t.Log("SPEC: Cookie Jar Isolation")
+		t.Log("GIVEN a site fetched once with --cookies")
+		t.Log("WHEN the site is fetched again without --cookies")
+		t.Log("THEN the second request should carry no cookie")
+
+		binary := buildBinary(t)
+
+		var mu sync.Mutex
+		var sent []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/article" {
+				http.NotFound(w, r)
+				return
+			}
+			mu.Lock()
+			sent = append(sent, r.Header.Get("Cookie"))
+			mu.Unlock()
+			_, _ = w.Write([]byte(`<html><body><article><h1>Article</h1><p>The same article for every reader, cookie or not.</p></article></body></html>`))
+		}))
+		defer server.Close()
+
+		cookiesPath := filepath.Join(t.TempDir(), "cookies.txt")
+		cookies := "# Netscape HTTP Cookie File\n127.0.0.1\tFALSE\t/\tFALSE\t0\tsession\tmember\n"
+		require.NoError(t, os.WriteFile(cookiesPath, []byte(cookies), 0o600))
+
+		output, err := exec.Command(binary, "--cookies", cookiesPath, server.URL+"/article").CombinedOutput()
+		require.NoError(t, err, "Fetch should succeed: %s", string(output))
+		output, err = exec.Command(binary, server.URL+"/article").CombinedOutput()
+		require.NoError(t, err, "Fetch should succeed: %s", string(output))
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, sent, 2, "Each fetch should request the article once")
+		assert.Contains(t, sent[0], "session=member", "The first request should carry the loaded cookie")
+		assert.Empty(t, sent[1], "The second request should carry no cookie")
+	})
+
+	t.Run("invalid_cookie_file_rejected", func(t *testing.T) {
+		t.Log("SPEC: Invalid Cookie File")
+		t.Log("GIVEN a cookie file with a malformed line")
+		t.Log("WHEN the user fetches with --cookies")
+		t.Log("THEN the command should fail and name the bad line")
+
+		cookiesPath := filepath.Join(t.TempDir(), "cookies.txt")
+		require.NoError(t, os.WriteFile(cookiesPath, []byte("example.com\tTRUE\t/\n"), 0o600))
+
+		cmd := exec.Command("go", "run", "../cmd/essenz/main.go", "--cookies", cookiesPath, "https://example.com")
+		output, err := cmd.CombinedOutput()
+		require.Error(t, err, "Command should fail with a malformed cookie file")
+
+		assert.Contains(t, string(output), "line 1", "Should point at the malformed line")
+	})
+}
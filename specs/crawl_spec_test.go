@@ -0,0 +1,59 @@
+package specs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrawlSpec(t *testing.T) {
+	t.Run("crawl_follows_content_links_in_scope", func(t *testing.T) {
+		t.Log("SPEC: Crawl")
+		t.Log("GIVEN a docs site whose pages link to each other from their content and navigation")
+		t.Log("WHEN the user runs sz crawl --depth 2 --same-domain --include /docs/ on its first page")
+		t.Log("THEN every in-scope page within two links should be written to a HOST/PATH.md tree, and nothing else fetched")
+
+		links := map[string]string{
+			"/docs/":         `<a href="intro">Intro</a> and <a href="/docs/setup#install">Setup</a> and <a href="/blog/news">News</a> and <a href="https://elsewhere.example/docs/">Elsewhere</a>`,
+			"/docs/intro":    `<a href="/docs/advanced">Advanced</a>`,
+			"/docs/setup":    `<a href="/docs/intro">Back to intro</a> and <a href="/docs/logo.png">Logo</a>`,
+			"/docs/advanced": `<a href="/docs/too-deep">Too deep</a>`,
+		}
+		var mu sync.Mutex
+		var requested []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requested = append(requested, r.URL.Path)
+			mu.Unlock()
+			_, _ = w.Write([]byte(`<html><body><nav><a href="/docs/navigation-only">Nav</a></nav><article><h1>Page ` + r.URL.Path + `</h1><p>This documentation page has enough text to be extracted as the main content. ` + links[r.URL.Path] + `</p></article></body></html>`))
+		}))
+		defer server.Close()
+
+		binary := buildBinary(t)
+		dir := t.TempDir()
+		cmd := exec.Command(binary, "crawl", "--socket", filepath.Join(t.TempDir(), "sz.sock"),
+			"--depth", "2", "--same-domain", "--include", "/docs/", "--delay", "0", "--output-dir", dir, server.URL+"/docs/")
+		cmd.Env = append(os.Environ(), "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+
+		assert.ElementsMatch(t, []string{"/docs/", "/docs/intro", "/docs/setup", "/docs/advanced"}, requested,
+			"Only in-content, in-scope links within the depth should be fetched, each once")
+		assert.Contains(t, string(output), "Crawled 4 of 4 pages")
+
+		host := strings.ReplaceAll(strings.TrimPrefix(server.URL, "http://"), ":", "-")
+		for _, path := range []string{"docs.md", "docs/intro.md", "docs/setup.md", "docs/advanced.md"} {
+			content, err := os.ReadFile(filepath.Join(dir, host, path))
+			require.NoError(t, err, "Each page should be written under its host and path")
+			assert.Contains(t, string(content), "# Page /docs/")
+		}
+	})
+}
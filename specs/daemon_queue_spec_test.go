@@ -0,0 +1,25 @@
+package specs
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDaemonQueueSpec(t *testing.T) {
+	t.Run("invalid_queue_timeout_rejected", func(t *testing.T) {
+		t.Log("SPEC: Invalid Queue Timeout")
+		t.Log("GIVEN a --queue-timeout value that is not a duration")
+		t.Log("WHEN the command runs")
+		t.Log("THEN it should fail before contacting the daemon and explain the expected format")
+
+		cmd := exec.Command("go", "run", "../cmd/essenz/main.go", "--queue-timeout", "soon", "https://example.com")
+		output, err := cmd.CombinedOutput()
+		require.Error(t, err, "Command should fail with an invalid queue timeout")
+
+		assert.Contains(t, string(output), "--queue-timeout", "Should name the offending flag")
+		assert.Contains(t, string(output), "positive duration", "Should explain the expected format")
+	})
+}
@@ -1,9 +1,17 @@
 package specs
 
 import (
+	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -49,8 +57,43 @@ func TestChromeDaemonManagement(t *testing.T) {
 	})
 
 	t.Run("daemon shuts down after idle timeout", func(t *testing.T) {
-		// Skip this test for now as it requires daemon process architecture improvements
-		t.Skip("Timeout functionality requires daemon process lifecycle improvements")
+		socket := filepath.Join(t.TempDir(), "sz.sock")
+		daemon := exec.Command(szBinary, "--socket", socket, "daemon", "start")
+		daemon.Env = append(os.Environ(), "ESSENZ_DAEMON_TIMEOUT=2s")
+		require.NoError(t, daemon.Start(), "Daemon should start")
+		defer func() {
+			_ = exec.Command(szBinary, "--socket", socket, "daemon", "stop").Run()
+			_ = daemon.Process.Kill()
+			_ = daemon.Wait()
+		}()
+
+		chromeRunning := func() bool {
+			output, err := exec.Command(szBinary, "--socket", socket, "daemon", "status", "--json").Output()
+			if err != nil {
+				return false
+			}
+			var report struct {
+				Info *struct {
+					ChromeRunning bool `json:"chrome_running"`
+				} `json:"info"`
+			}
+			return json.Unmarshal(output, &report) == nil && report.Info != nil && report.Info.ChromeRunning
+		}
+
+		fetch := func() {
+			output, err := exec.Command(szBinary, "--socket", socket, "fetch", "https://example.com").CombinedOutput()
+			require.NoError(t, err, "Fetch should succeed: %s", string(output))
+		}
+
+		// A request starts Chrome, and it stops once the daemon has been idle
+		fetch()
+		require.True(t, chromeRunning(), "Chrome should be running after a fetch")
+		assert.Eventually(t, func() bool { return !chromeRunning() }, 10*time.Second, 250*time.Millisecond,
+			"Chrome should stop after the idle timeout")
+
+		// The next request brings Chrome back
+		fetch()
+		assert.True(t, chromeRunning(), "Chrome should restart on demand")
 	})
 
 	t.Run("daemon restarts automatically if crashed", func(t *testing.T) {
@@ -59,6 +102,613 @@ func TestChromeDaemonManagement(t *testing.T) {
 	})
 }
 
+// TestDaemonSocketSpec validates where the daemon listens and who may connect.
+func TestDaemonSocketSpec(t *testing.T) {
+	t.Run("custom_socket_path", func(t *testing.T) {
+		t.Log("SPEC: Custom Socket Path")
+		t.Log("GIVEN a --socket path in a directory that does not exist yet")
+		t.Log("WHEN the daemon is started with it")
+		t.Log("THEN the socket should be created there, readable only by the user, and report as running")
+
+		szBinary := buildSzBinary(t)
+		defer func() { _ = os.Remove(szBinary) }()
+
+		socket := filepath.Join(t.TempDir(), "run", "sz.sock")
+		daemon := exec.Command(szBinary, "--socket", socket, "daemon", "start")
+		require.NoError(t, daemon.Start(), "Daemon should start")
+		defer func() {
+			_ = exec.Command(szBinary, "--socket", socket, "daemon", "stop").Run()
+			_ = daemon.Process.Kill()
+			_ = daemon.Wait()
+		}()
+
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(socket)
+			return err == nil
+		}, 10*time.Second, 50*time.Millisecond, "Socket should appear at the requested path")
+
+		info, err := os.Stat(socket)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "Socket should only be accessible by the user")
+
+		token, err := os.Stat(socket + ".token")
+		require.NoError(t, err, "Daemon should write an auth token next to the socket")
+		assert.Equal(t, os.FileMode(0o600), token.Mode().Perm(), "Token file should only be readable by the user")
+
+		output, err := exec.Command(szBinary, "--socket", socket, "daemon", "status").CombinedOutput()
+		require.NoError(t, err)
+		assert.Contains(t, string(output), "is running", "Status should find the daemon at the custom socket")
+	})
+
+	t.Run("named_daemons_side_by_side", func(t *testing.T) {
+		t.Log("SPEC: Named Daemon Instances")
+		t.Log("GIVEN two daemons started with different --daemon-name values")
+		t.Log("WHEN the user checks each one's status")
+		t.Log("THEN each should run on its own socket and report its name")
+
+		szBinary := buildSzBinary(t)
+		defer func() { _ = os.Remove(szBinary) }()
+
+		runtimeDir := t.TempDir()
+		env := append(os.Environ(), "XDG_RUNTIME_DIR="+runtimeDir)
+
+		for _, name := range []string{"work", "scraper"} {
+			daemon := exec.Command(szBinary, "--daemon-name", name, "daemon", "start")
+			daemon.Env = env
+			require.NoError(t, daemon.Start(), "Daemon %s should start", name)
+			defer func() {
+				_ = daemon.Process.Kill()
+				_ = daemon.Wait()
+			}()
+		}
+
+		for _, name := range []string{"work", "scraper"} {
+			socket := filepath.Join(runtimeDir, "essenz", "daemon-"+name+".sock")
+			require.Eventually(t, func() bool {
+				status := exec.Command(szBinary, "--daemon-name", name, "daemon", "status")
+				status.Env = env
+				output, err := status.CombinedOutput()
+				return err == nil && strings.Contains(string(output), "Name:         "+name) && strings.Contains(string(output), socket)
+			}, 10*time.Second, 100*time.Millisecond, "Daemon %s should run on its own socket", name)
+		}
+	})
+
+	t.Run("detailed_status_json", func(t *testing.T) {
+		t.Log("SPEC: Detailed Daemon Status")
+		t.Log("GIVEN a running daemon")
+		t.Log("WHEN the user runs sz daemon status --json")
+		t.Log("THEN it should report the daemon's PID, uptime, pages served, open tabs, and memory use")
+
+		szBinary := buildSzBinary(t)
+		defer func() { _ = os.Remove(szBinary) }()
+
+		socket := filepath.Join(t.TempDir(), "sz.sock")
+		daemon := exec.Command(szBinary, "--socket", socket, "daemon", "start")
+		require.NoError(t, daemon.Start(), "Daemon should start")
+		defer func() {
+			_ = daemon.Process.Kill()
+			_ = daemon.Wait()
+		}()
+
+		var report struct {
+			Status string `json:"status"`
+			Info   *struct {
+				PID         int    `json:"pid"`
+				Uptime      string `json:"uptime"`
+				Browser     string `json:"browser"`
+				PagesServed int64  `json:"pages_served"`
+				OpenTabs    int    `json:"open_tabs"`
+				MemoryBytes uint64 `json:"memory_bytes"`
+			} `json:"info"`
+		}
+		require.Eventually(t, func() bool {
+			output, err := exec.Command(szBinary, "--socket", socket, "daemon", "status", "--json").Output()
+			return err == nil && json.Unmarshal(output, &report) == nil && report.Status == "running"
+		}, 10*time.Second, 100*time.Millisecond, "Status should report the daemon as running")
+
+		require.NotNil(t, report.Info, "Running daemon should report its details")
+		assert.Equal(t, daemon.Process.Pid, report.Info.PID, "Should report the daemon's PID")
+		assert.NotEmpty(t, report.Info.Uptime, "Should report uptime")
+		assert.Equal(t, "chrome", report.Info.Browser, "Should report the default browser engine")
+		assert.Zero(t, report.Info.PagesServed, "No pages have been served yet")
+		assert.Positive(t, report.Info.MemoryBytes, "Should report memory use")
+	})
+
+	t.Run("stale_socket_after_crash", func(t *testing.T) {
+		t.Log("SPEC: Stale Socket Detection")
+		t.Log("GIVEN a daemon that was killed without cleaning up its socket and PID file")
+		t.Log("WHEN the user checks the daemon status and starts a new daemon")
+		t.Log("THEN status should report the stale socket instead of a running daemon, and the new daemon should replace it")
+
+		szBinary := buildSzBinary(t)
+		defer func() { _ = os.Remove(szBinary) }()
+
+		socket := filepath.Join(t.TempDir(), "sz.sock")
+		crashed := exec.Command(szBinary, "--socket", socket, "daemon", "start")
+		require.NoError(t, crashed.Start(), "Daemon should start")
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(socket + ".pid")
+			return err == nil
+		}, 10*time.Second, 50*time.Millisecond, "Daemon should write its PID file")
+
+		require.NoError(t, crashed.Process.Kill())
+		_ = crashed.Wait()
+
+		output, err := exec.Command(szBinary, "--socket", socket, "daemon", "status").CombinedOutput()
+		require.NoError(t, err)
+		assert.Contains(t, string(output), "not running", "Status should not claim the crashed daemon is running")
+		assert.Contains(t, string(output), fmt.Sprintf("process %d exited", crashed.Process.Pid), "Status should name the crashed process")
+
+		replacement := exec.Command(szBinary, "--socket", socket, "daemon", "start")
+		require.NoError(t, replacement.Start(), "New daemon should start")
+		defer func() {
+			_ = replacement.Process.Kill()
+			_ = replacement.Wait()
+		}()
+
+		require.Eventually(t, func() bool {
+			output, err := exec.Command(szBinary, "--socket", socket, "daemon", "status").CombinedOutput()
+			return err == nil && strings.Contains(string(output), "is running")
+		}, 10*time.Second, 100*time.Millisecond, "New daemon should take over the stale socket")
+	})
+
+	t.Run("daemon_logs_to_file", func(t *testing.T) {
+		t.Log("SPEC: Daemon Log File")
+		t.Log("GIVEN a daemon started with --log-file and --log-level debug")
+		t.Log("WHEN a client checks its status and stops it")
+		t.Log("THEN the log file should record startup, each request with its ID, and shutdown")
+
+		szBinary := buildSzBinary(t)
+		defer func() { _ = os.Remove(szBinary) }()
+
+		socket := filepath.Join(t.TempDir(), "sz.sock")
+		logFile := filepath.Join(t.TempDir(), "logs", "daemon.log")
+		daemon := exec.Command(szBinary, "--socket", socket, "daemon", "start", "--log-file", logFile, "--log-level", "debug")
+		require.NoError(t, daemon.Start(), "Daemon should start")
+		defer func() {
+			_ = daemon.Process.Kill()
+			_ = daemon.Wait()
+		}()
+
+		require.Eventually(t, func() bool {
+			output, err := exec.Command(szBinary, "--socket", socket, "daemon", "status").CombinedOutput()
+			return err == nil && strings.Contains(string(output), "Log:          "+logFile)
+		}, 10*time.Second, 100*time.Millisecond, "Status should name the log file")
+
+		output, err := exec.Command(szBinary, "--socket", socket, "daemon", "stop").CombinedOutput()
+		require.NoError(t, err, "Stop should succeed: %s", string(output))
+
+		require.Eventually(t, func() bool {
+			data, _ := os.ReadFile(logFile)
+			return strings.Contains(string(data), "daemon stopped")
+		}, 5*time.Second, 50*time.Millisecond, "Log should record the shutdown")
+
+		data, err := os.ReadFile(logFile)
+		require.NoError(t, err)
+		log := string(data)
+		assert.Contains(t, log, `msg="daemon started"`, "Log should record startup")
+		assert.Contains(t, log, "level=DEBUG", "Debug level should include request details")
+		assert.Contains(t, log, "action=ping", "Log should record the status request")
+		assert.Regexp(t, `request=\d+`, log, "Requests should be logged with an ID")
+	})
+
+	t.Run("detached_daemon_runs_in_background", func(t *testing.T) {
+		t.Log("SPEC: Detached Daemon")
+		t.Log("GIVEN sz daemon start --detach")
+		t.Log("WHEN the command returns")
+		t.Log("THEN the daemon should keep running in the background, log to its file, and stop on request")
+
+		szBinary := buildSzBinary(t)
+		defer func() { _ = os.Remove(szBinary) }()
+
+		socket := filepath.Join(t.TempDir(), "sz.sock")
+		logFile := filepath.Join(t.TempDir(), "daemon.log")
+		start := exec.Command(szBinary, "--socket", socket, "daemon", "start", "--detach", "--log-file", logFile)
+		output, err := start.CombinedOutput()
+		require.NoError(t, err, "Detached start should succeed: %s", string(output))
+		assert.Contains(t, string(output), "started in the background", "Should report the background daemon")
+
+		status, err := exec.Command(szBinary, "--socket", socket, "daemon", "status").CombinedOutput()
+		require.NoError(t, err)
+		assert.Contains(t, string(status), "is running", "Daemon should answer after the start command returned")
+
+		output, err = exec.Command(szBinary, "--socket", socket, "daemon", "stop").CombinedOutput()
+		require.NoError(t, err, "Stop should succeed: %s", string(output))
+
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(socket)
+			return os.IsNotExist(err)
+		}, 5*time.Second, 50*time.Millisecond, "Stopped daemon should remove its socket")
+
+		data, err := os.ReadFile(logFile)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `msg="daemon started"`, "Detached daemon should log to its file")
+	})
+
+	t.Run("foreground_daemon_stops_on_sigterm", func(t *testing.T) {
+		t.Log("SPEC: Foreground Signal Handling")
+		t.Log("GIVEN a daemon running in the foreground")
+		t.Log("WHEN it receives SIGTERM")
+		t.Log("THEN it should exit successfully and remove its socket and PID file")
+
+		if runtime.GOOS == "windows" {
+			t.Skip("SIGTERM cannot be sent on Windows")
+		}
+
+		szBinary := buildSzBinary(t)
+		defer func() { _ = os.Remove(szBinary) }()
+
+		socket := filepath.Join(t.TempDir(), "sz.sock")
+		daemon := exec.Command(szBinary, "--socket", socket, "daemon", "start")
+		require.NoError(t, daemon.Start(), "Daemon should start")
+
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(socket + ".pid")
+			return err == nil
+		}, 10*time.Second, 50*time.Millisecond, "Daemon should write its PID file")
+
+		require.NoError(t, daemon.Process.Signal(syscall.SIGTERM))
+		require.NoError(t, daemon.Wait(), "Daemon should exit cleanly on SIGTERM")
+
+		_, err := os.Stat(socket)
+		assert.True(t, os.IsNotExist(err), "Socket should be removed")
+		_, err = os.Stat(socket + ".pid")
+		assert.True(t, os.IsNotExist(err), "PID file should be removed")
+	})
+
+	t.Run("graceful_restart_hands_over_socket", func(t *testing.T) {
+		t.Log("SPEC: Graceful Daemon Restart")
+		t.Log("GIVEN a running daemon")
+		t.Log("WHEN the user runs sz daemon restart --graceful")
+		t.Log("THEN a new daemon should serve the same socket and the old one should exit cleanly")
+
+		szBinary := buildSzBinary(t)
+		defer func() { _ = os.Remove(szBinary) }()
+
+		socket := filepath.Join(t.TempDir(), "sz.sock")
+		env := append(os.Environ(), "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+		old := exec.Command(szBinary, "--socket", socket, "daemon", "start")
+		old.Env = env
+		require.NoError(t, old.Start(), "Daemon should start")
+		defer func() {
+			_ = old.Process.Kill()
+			_ = old.Wait()
+		}()
+
+		require.Eventually(t, func() bool {
+			output, err := exec.Command(szBinary, "--socket", socket, "daemon", "status").CombinedOutput()
+			return err == nil && strings.Contains(string(output), "is running")
+		}, 10*time.Second, 100*time.Millisecond, "Daemon should answer")
+
+		restart := exec.Command(szBinary, "--socket", socket, "daemon", "restart", "--graceful")
+		restart.Env = env
+		output, err := restart.CombinedOutput()
+		require.NoError(t, err, "Graceful restart should succeed: %s", string(output))
+		defer func() { _ = exec.Command(szBinary, "--socket", socket, "daemon", "stop").Run() }()
+
+		exited := make(chan error, 1)
+		go func() { exited <- old.Wait() }()
+		select {
+		case err := <-exited:
+			assert.NoError(t, err, "Old daemon should exit cleanly after draining")
+		case <-time.After(10 * time.Second):
+			t.Fatal("Old daemon should exit after handing over")
+		}
+
+		output, err = exec.Command(szBinary, "--socket", socket, "daemon", "status", "--json").CombinedOutput()
+		require.NoError(t, err)
+		var report struct {
+			Status string `json:"status"`
+			Info   struct {
+				PID int `json:"pid"`
+			} `json:"info"`
+		}
+		require.NoError(t, json.Unmarshal(output, &report), string(output))
+		assert.Equal(t, "running", report.Status, "New daemon should serve the socket")
+		assert.NotEqual(t, old.Process.Pid, report.Info.PID, "A new process should answer")
+	})
+
+	t.Run("install_writes_user_service", func(t *testing.T) {
+		t.Log("SPEC: Daemon Service Installation")
+		t.Log("GIVEN a Linux user who wants the daemon to start at login")
+		t.Log("WHEN they run sz daemon install --no-start and then sz daemon uninstall")
+		t.Log("THEN a systemd user unit that restarts the daemon on failure should be written and then removed")
+
+		if runtime.GOOS != "linux" {
+			t.Skip("systemd user units are only installed on Linux")
+		}
+
+		szBinary := buildSzBinary(t)
+		defer func() { _ = os.Remove(szBinary) }()
+
+		configDir := t.TempDir()
+		env := append(os.Environ(), "XDG_CONFIG_HOME="+configDir)
+		socket := filepath.Join(t.TempDir(), "sz.sock")
+
+		install := exec.Command(szBinary, "--daemon-name", "work", "--socket", socket, "daemon", "install", "--no-start")
+		install.Env = env
+		output, err := install.CombinedOutput()
+		require.NoError(t, err, "Install should succeed: %s", string(output))
+
+		unitPath := filepath.Join(configDir, "systemd", "user", "essenz-daemon-work.service")
+		unit, err := os.ReadFile(unitPath)
+		require.NoError(t, err, "Unit file should be written")
+		assert.Contains(t, string(unit), "ExecStart="+szBinary+" --daemon-name work daemon start", "Unit should run this binary's daemon")
+		assert.Contains(t, string(unit), "Environment=ESSENZ_SOCKET="+socket, "Unit should keep the socket path")
+		assert.Contains(t, string(unit), "Restart=on-failure", "Unit should restart the daemon when it fails")
+		assert.Contains(t, string(unit), "WantedBy=default.target", "Unit should start at login")
+
+		uninstall := exec.Command(szBinary, "--daemon-name", "work", "daemon", "uninstall")
+		uninstall.Env = env
+		output, err = uninstall.CombinedOutput()
+		require.NoError(t, err, "Uninstall should succeed: %s", string(output))
+
+		_, err = os.Stat(unitPath)
+		assert.True(t, os.IsNotExist(err), "Unit file should be removed")
+	})
+}
+
+func TestRemoteChromeSpec(t *testing.T) {
+	t.Run("daemon_reports_remote_chrome", func(t *testing.T) {
+		t.Log("SPEC: Remote Chrome Endpoint")
+		t.Log("GIVEN a daemon started with --remote-chrome")
+		t.Log("WHEN the user checks its status")
+		t.Log("THEN it should report the remote endpoint instead of a Chrome process of its own")
+
+		szBinary := buildSzBinary(t)
+		defer func() { _ = os.Remove(szBinary) }()
+
+		socket := filepath.Join(t.TempDir(), "sz.sock")
+		daemon := exec.Command(szBinary, "--socket", socket, "--remote-chrome", "ws://127.0.0.1:9333", "daemon", "start")
+		require.NoError(t, daemon.Start(), "Daemon should start")
+		defer func() {
+			_ = daemon.Process.Kill()
+			_ = daemon.Wait()
+		}()
+
+		require.Eventually(t, func() bool {
+			output, err := exec.Command(szBinary, "--socket", socket, "daemon", "status").CombinedOutput()
+			return err == nil && strings.Contains(string(output), "remote at ws://127.0.0.1:9333")
+		}, 10*time.Second, 100*time.Millisecond, "Status should name the remote endpoint")
+	})
+
+	t.Run("invalid_remote_chrome_rejected", func(t *testing.T) {
+		t.Log("SPEC: Invalid Remote Chrome Endpoint")
+		t.Log("GIVEN a --remote-chrome value without a port")
+		t.Log("WHEN the command runs")
+		t.Log("THEN it should fail and show the expected form")
+
+		cmd := exec.Command("go", "run", "../cmd/essenz/main.go", "--remote-chrome", "ws://chrome-host", "https://example.com")
+		output, err := cmd.CombinedOutput()
+		require.Error(t, err, "Command should fail with an invalid endpoint")
+
+		assert.Contains(t, string(output), "missing port", "Should explain what is wrong")
+	})
+}
+
+func TestChromeContainerSpec(t *testing.T) {
+	t.Run("daemon_reports_chrome_container", func(t *testing.T) {
+		t.Log("SPEC: Containerized Chrome")
+		t.Log("GIVEN a daemon started with --chrome-container and --chrome-image")
+		t.Log("WHEN the user checks its status")
+		t.Log("THEN it should report the container runtime and image it launches Chrome from")
+
+		szBinary := buildSzBinary(t)
+		defer func() { _ = os.Remove(szBinary) }()
+
+		socket := filepath.Join(t.TempDir(), "sz.sock")
+		daemon := exec.Command(szBinary, "--socket", socket, "daemon", "start", "--chrome-container", "podman", "--chrome-image", "example/chrome:1")
+		require.NoError(t, daemon.Start(), "Daemon should start")
+		defer func() {
+			_ = daemon.Process.Kill()
+			_ = daemon.Wait()
+		}()
+
+		require.Eventually(t, func() bool {
+			output, err := exec.Command(szBinary, "--socket", socket, "daemon", "status").CombinedOutput()
+			return err == nil && strings.Contains(string(output), "podman container from example/chrome:1")
+		}, 10*time.Second, 100*time.Millisecond, "Status should name the container runtime and image")
+	})
+
+	t.Run("unknown_container_runtime_rejected", func(t *testing.T) {
+		t.Log("SPEC: Unknown Container Runtime")
+		t.Log("GIVEN a --chrome-container value other than docker or podman")
+		t.Log("WHEN the daemon starts")
+		t.Log("THEN it should fail and list the supported runtimes")
+
+		cmd := exec.Command("go", "run", "../cmd/essenz/main.go", "--socket", filepath.Join(t.TempDir(), "sz.sock"), "daemon", "start", "--chrome-container", "lxc")
+		output, err := cmd.CombinedOutput()
+		require.Error(t, err, "Daemon should not start with an unknown runtime")
+
+		assert.Contains(t, string(output), "use docker or podman", "Should list the supported runtimes")
+	})
+}
+
+func TestBrowserBackendSpec(t *testing.T) {
+	t.Run("unknown_browser_rejected", func(t *testing.T) {
+		t.Log("SPEC: Browser Backend Selection")
+		t.Log("GIVEN a --browser engine that is not built in")
+		t.Log("WHEN the daemon starts")
+		t.Log("THEN it should fail and list the available engines")
+
+		cmd := exec.Command("go", "run", "../cmd/essenz/main.go", "--socket", filepath.Join(t.TempDir(), "sz.sock"), "daemon", "start", "--browser", "netscape")
+		output, err := cmd.CombinedOutput()
+		require.Error(t, err, "Daemon should not start with an unknown engine")
+
+		assert.Contains(t, string(output), "available browsers are chrome", "Should list the available engines")
+	})
+}
+
+func TestDaemonTabsSpec(t *testing.T) {
+	// visitCounter counts, in the tab's session storage, how often the page
+	// has been loaded in the same tab
+	visitCounter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><article><h1>Visit Counter</h1><p id="visits"></p></article><script>
+var visits = Number(sessionStorage.getItem('visits') || 0) + 1;
+sessionStorage.setItem('visits', visits);
+document.getElementById('visits').textContent = 'Loaded ' + visits + ' times in this tab.';
+</script></body></html>`))
+	}))
+	defer visitCounter.Close()
+
+	// startDaemon runs a daemon on its own socket with env added to its
+	// environment and returns a function reporting its status.
+	startDaemon := func(t *testing.T, szBinary string, env ...string) (string, func() daemonStatus) {
+		socket := filepath.Join(t.TempDir(), "sz.sock")
+		daemon := exec.Command(szBinary, "--socket", socket, "daemon", "start")
+		daemon.Env = append(os.Environ(), append(env, "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))...)
+		require.NoError(t, daemon.Start(), "Daemon should start")
+		t.Cleanup(func() {
+			_ = exec.Command(szBinary, "--socket", socket, "daemon", "stop").Run()
+			_ = daemon.Process.Kill()
+			_ = daemon.Wait()
+		})
+
+		status := func() daemonStatus {
+			var report struct {
+				Info *daemonStatus `json:"info"`
+			}
+			output, err := exec.Command(szBinary, "--socket", socket, "daemon", "status", "--json").Output()
+			if err != nil || json.Unmarshal(output, &report) != nil || report.Info == nil {
+				return daemonStatus{}
+			}
+			return *report.Info
+		}
+		require.Eventually(t, func() bool { return status().PID != 0 }, 10*time.Second, 100*time.Millisecond, "Daemon should report its status")
+		return socket, status
+	}
+
+	fetch := func(t *testing.T, szBinary, socket string) string {
+		output, err := exec.Command(szBinary, "--socket", socket, "fetch", visitCounter.URL).CombinedOutput()
+		require.NoError(t, err, "Fetch should succeed: %s", string(output))
+		return string(output)
+	}
+
+	t.Run("tabs_are_reused", func(t *testing.T) {
+		t.Log("SPEC: Tab Pool")
+		t.Log("GIVEN a daemon keeping one warm tab")
+		t.Log("WHEN a client fetches the same page several times")
+		t.Log("THEN each fetch should reuse that tab, and the number of open tabs should not grow")
+
+		szBinary := buildSzBinary(t)
+		defer func() { _ = os.Remove(szBinary) }()
+		socket, status := startDaemon(t, szBinary, "ESSENZ_TAB_POOL_SIZE=1")
+
+		assert.Contains(t, fetch(t, szBinary, socket), "Loaded 1 times in this tab.")
+		tabs := status().OpenTabs
+		require.Positive(t, tabs, "Chrome should have tabs open after a fetch")
+
+		for visit := 2; visit <= 4; visit++ {
+			assert.Contains(t, fetch(t, szBinary, socket), fmt.Sprintf("Loaded %d times in this tab.", visit),
+				"The page should load in the tab the last fetch used")
+			assert.Equal(t, tabs, status().OpenTabs, "Reusing tabs should not open more")
+		}
+	})
+
+	t.Run("chrome_recycled_after_request_limit", func(t *testing.T) {
+		t.Log("SPEC: Chrome Recycling")
+		t.Log("GIVEN a daemon started with ESSENZ_RECYCLE_REQUESTS=2")
+		t.Log("WHEN a client fetches a page three times")
+		t.Log("THEN Chrome should be restarted after the second fetch, and the third should be rendered by the new Chrome")
+
+		szBinary := buildSzBinary(t)
+		defer func() { _ = os.Remove(szBinary) }()
+		socket, status := startDaemon(t, szBinary, "ESSENZ_RECYCLE_REQUESTS=2")
+
+		assert.Contains(t, fetch(t, szBinary, socket), "Loaded 1 times in this tab.")
+		first := status()
+		require.NotZero(t, first.ChromePID, "Chrome should be running after a fetch")
+		assert.Zero(t, first.Recycles)
+
+		fetch(t, szBinary, socket)
+		require.Eventually(t, func() bool { return status().Recycles == 1 }, 10*time.Second, 100*time.Millisecond,
+			"Chrome should be recycled once it has served two requests")
+
+		assert.Contains(t, fetch(t, szBinary, socket), "Loaded 1 times in this tab.",
+			"The third fetch should be rendered in a fresh Chrome")
+		third := status()
+		assert.NotZero(t, third.ChromePID, "A new Chrome should serve the third fetch")
+		assert.NotEqual(t, first.ChromePID, third.ChromePID, "The recycled Chrome should be a new process")
+		assert.Equal(t, 1, third.Recycles)
+		assert.Equal(t, int64(3), third.PagesServed)
+	})
+}
+
+// daemonStatus is the part of sz daemon status --json the tab specs read.
+type daemonStatus struct {
+	PID         int   `json:"pid"`
+	ChromePID   int   `json:"chrome_pid"`
+	PagesServed int64 `json:"pages_served"`
+	OpenTabs    int   `json:"open_tabs"`
+	Recycles    int   `json:"chrome_recycles"`
+}
+
+func TestDaemonAuthSpec(t *testing.T) {
+	t.Run("requests_need_the_token", func(t *testing.T) {
+		t.Log("SPEC: Daemon Authentication")
+		t.Log("GIVEN a running daemon with its socket and token file")
+		t.Log("WHEN a client sends requests without the token, with a wrong one, and with the one in the file")
+		t.Log("THEN only the request with the daemon's token should be answered, and both files should be readable only by the user")
+
+		szBinary := buildSzBinary(t)
+		defer func() { _ = os.Remove(szBinary) }()
+
+		socket := filepath.Join(t.TempDir(), "sz.sock")
+		env := append(os.Environ(), "ESSENZ_DAEMON_ADDR=unix:"+socket)
+		sz := func(args ...string) *exec.Cmd {
+			cmd := exec.Command(szBinary, args...)
+			cmd.Env = env
+			return cmd
+		}
+
+		daemon := sz("daemon", "start")
+		require.NoError(t, daemon.Start(), "Daemon should start")
+		defer func() {
+			_ = sz("daemon", "stop").Run()
+			_ = daemon.Process.Kill()
+			_ = daemon.Wait()
+		}()
+		require.Eventually(t, func() bool {
+			output, err := sz("daemon", "status").CombinedOutput()
+			return err == nil && strings.Contains(string(output), "is running")
+		}, 10*time.Second, 100*time.Millisecond, "Daemon should start")
+
+		for _, path := range []string{socket, socket + ".token"} {
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "%s should only be accessible by the user", filepath.Base(path))
+		}
+
+		type reply struct {
+			Success bool   `json:"success"`
+			Error   string `json:"error"`
+		}
+		send := func(request map[string]string) reply {
+			conn, err := net.DialTimeout("unix", socket, 5*time.Second)
+			require.NoError(t, err)
+			defer func() { _ = conn.Close() }()
+			require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+			require.NoError(t, json.NewEncoder(conn).Encode(request))
+			var answer reply
+			require.NoError(t, json.NewDecoder(conn).Decode(&answer))
+			return answer
+		}
+
+		missing := send(map[string]string{"action": "ping"})
+		assert.False(t, missing.Success, "A request without a token should be rejected")
+		assert.Contains(t, missing.Error, "unauthorized")
+
+		wrong := send(map[string]string{"action": "ping", "token": strings.Repeat("0", 64)})
+		assert.False(t, wrong.Success, "A request with a wrong token should be rejected")
+		assert.Contains(t, wrong.Error, "unauthorized")
+
+		token, err := os.ReadFile(socket + ".token")
+		require.NoError(t, err)
+		right := send(map[string]string{"action": "ping", "token": strings.TrimSpace(string(token))})
+		assert.True(t, right.Success, "A request with the daemon's token should be answered: %s", right.Error)
+	})
+}
+
 // Helper functions for Chrome process management testing
 
 func buildSzBinary(t *testing.T) string {
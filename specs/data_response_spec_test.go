@@ -0,0 +1,90 @@
+package specs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataResponseSpec(t *testing.T) {
+	binary := buildBinary(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/locks":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"total":2,"locks":[{"id":1,"name":"Caen Hill","flight":{"count":29}},{"id":2,"name":null}]}`))
+		case "/feed.xml":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><rss version="2.0"><channel><title>Canals &amp; Mills</title>` +
+				`<item><title>Locks</title><link>https://example.com/locks</link></item><item><title>Mills</title></item></channel></rss>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("json_pretty", func(t *testing.T) {
+		t.Log("SPEC: JSON and XML Responses")
+		t.Log("GIVEN an API endpoint answering with compact JSON")
+		t.Log("WHEN the user runs sz on it")
+		t.Log("THEN the JSON should be printed indented in a json code block")
+
+		output, err := exec.Command(binary, server.URL+"/api/locks").Output()
+		require.NoError(t, err)
+		text := string(output)
+
+		assert.True(t, strings.HasPrefix(text, "```json\n{\n  \"total\": 2,\n  \"locks\": [\n"), text)
+		assert.Contains(t, text, "      \"name\": \"Caen Hill\",\n")
+		assert.True(t, strings.HasSuffix(text, "}\n```\n"), text)
+	})
+
+	t.Run("xml_pretty", func(t *testing.T) {
+		t.Log("SPEC: JSON and XML Responses")
+		t.Log("GIVEN an RSS feed on one line")
+		t.Log("WHEN the user runs sz on it")
+		t.Log("THEN the XML should be printed indented in an xml code block")
+
+		output, err := exec.Command(binary, server.URL+"/feed.xml").Output()
+		require.NoError(t, err)
+		text := string(output)
+
+		assert.True(t, strings.HasPrefix(text, "```xml\n"), text)
+		assert.Contains(t, text, "\n  <channel>\n    <title>Canals &amp; Mills</title>\n    <item>\n      <title>Locks</title>\n")
+	})
+
+	t.Run("json_outline", func(t *testing.T) {
+		t.Log("SPEC: JSON and XML Responses")
+		t.Log("GIVEN the same JSON endpoint")
+		t.Log("WHEN the user runs sz --data-outline on it")
+		t.Log("THEN its keys and value types should be outlined, with the array's items merged")
+
+		output, err := exec.Command(binary, "--data-outline", server.URL+"/api/locks").Output()
+		require.NoError(t, err)
+
+		assert.Equal(t, "JSON object:\n\n"+
+			"- `total`: number\n"+
+			"- `locks`: array of 2 objects\n"+
+			"  - `id`: number\n"+
+			"  - `name`: string or null\n"+
+			"  - `flight`: object\n"+
+			"    - `count`: number\n", string(output))
+	})
+
+	t.Run("xml_outline", func(t *testing.T) {
+		t.Log("SPEC: JSON and XML Responses")
+		t.Log("GIVEN the same RSS feed")
+		t.Log("WHEN the user runs sz --data-outline on it")
+		t.Log("THEN its element tree should be outlined, with repeated elements given once with their count")
+
+		output, err := exec.Command(binary, "--data-outline", server.URL+"/feed.xml").Output()
+		require.NoError(t, err)
+
+		assert.Contains(t, string(output), "- `rss` (attributes: version)\n  - `channel`\n    - `title`: text\n    - `item` ×2\n      - `title`: text\n      - `link`: text\n")
+	})
+}
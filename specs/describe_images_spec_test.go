@@ -0,0 +1,74 @@
+package specs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeImagesSpec(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "harbour.html")
+	require.NoError(t, os.WriteFile(page, []byte(`<html><body><article><h1>Harbour</h1>
+<p>The boats come in at dawn with the catch.</p>
+<img src="harbour-boats.png">
+<img src="gulls.png" alt="Gulls over the harbour wall">
+<p>Gulls follow them all the way in.</p></article></body></html>`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "harbour-boats.png"), []byte("\x89PNG\r\n\x1a\n0000"), 0o644))
+	binary := buildBinary(t)
+
+	t.Run("model_describes_image_without_alt", func(t *testing.T) {
+		t.Log("SPEC: Describe Images")
+		t.Log("GIVEN a page with one image lacking alt text and one with alt text")
+		t.Log("WHEN the user runs sz --describe-images with a model configured")
+		t.Log("THEN only the undescribed image should be sent to the model, and its description used")
+
+		var calls int
+		var last map[string]any
+		model := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			last = nil
+			_ = json.NewDecoder(r.Body).Decode(&last)
+			_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"Fishing boats moored along a stone quay at sunrise"}}]}`))
+		}))
+		defer model.Close()
+
+		cmd := exec.Command(binary, "--describe-images", page)
+		cmd.Env = llmConfig(t, model.URL)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+
+		assert.Contains(t, string(output), "An image: Fishing boats moored along a stone quay at sunrise")
+		assert.Contains(t, string(output), "An image: Gulls over the harbour wall", "Alt text should be kept")
+		assert.Equal(t, 1, calls, "Only the image without alt text should be described")
+		request, _ := json.Marshal(last)
+		assert.Contains(t, string(request), "data:image/png;base64,", "A local image should be sent inline")
+	})
+
+	t.Run("falls_back_when_model_fails", func(t *testing.T) {
+		t.Log("SPEC: Describe Images Fallback")
+		t.Log("GIVEN a model endpoint that fails")
+		t.Log("WHEN the user runs sz --describe-images")
+		t.Log("THEN the image should be described from its file name as before")
+
+		model := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, `{"error":{"message":"model overloaded"}}`, http.StatusServiceUnavailable)
+		}))
+		defer model.Close()
+
+		cmd := exec.Command(binary, "--describe-images", page)
+		cmd.Env = llmConfig(t, model.URL)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+
+		assert.Contains(t, string(output), "An image: harbour boats")
+		assert.Contains(t, string(output), "model overloaded", "The failure should be reported on stderr")
+	})
+}
@@ -0,0 +1,57 @@
+package specs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffSpec(t *testing.T) {
+	binary := buildBinary(t)
+	dir := t.TempDir()
+
+	page := filepath.Join(dir, "guide.html")
+	require.NoError(t, os.WriteFile(page, []byte(`<html><body><nav>Home | Docs | Blog</nav><article><h1>Install Guide</h1><p>Download the archive for your platform and unpack it anywhere on your path.</p><p>Run the installer with version 2.0 of the toolkit.</p></article><footer>Copyright 2026</footer></body></html>`), 0o644))
+
+	t.Run("diff_ignores_rewrapping", func(t *testing.T) {
+		t.Log("SPEC: Content Diff")
+		t.Log("GIVEN a saved snapshot whose text is wrapped differently and names an older version")
+		t.Log("WHEN the user runs sz diff SNAPSHOT PAGE")
+		t.Log("THEN only the edited paragraph should be reported")
+
+		snapshot := filepath.Join(dir, "guide.md")
+		require.NoError(t, os.WriteFile(snapshot, []byte("# Install Guide\n\nDownload the archive for your platform\nand unpack it anywhere on your path.\n\nRun the installer with version 1.0 of the toolkit.\n"), 0o644))
+
+		output, err := exec.Command(binary, "diff", snapshot, page).CombinedOutput()
+		require.NoError(t, err, string(output))
+
+		assert.Contains(t, string(output), "--- "+snapshot)
+		assert.Contains(t, string(output), "-Run the installer with version 1.0 of the toolkit.")
+		assert.Contains(t, string(output), "+Run the installer with version 2.0 of the toolkit.")
+		assert.NotContains(t, string(output), "-Download", "Re-wrapped text should not count as a change")
+		assert.NotContains(t, string(output), "Copyright", "Page furniture should not be compared")
+	})
+
+	t.Run("diff_exit_code", func(t *testing.T) {
+		t.Log("SPEC: Content Diff Exit Code")
+		t.Log("GIVEN the same page on both sides, and then a changed one")
+		t.Log("WHEN the user runs sz diff --exit-code")
+		t.Log("THEN it should print nothing and exit 0 for the same content, and exit 1 when it differs")
+
+		output, err := exec.Command(binary, "diff", "--exit-code", page, page).CombinedOutput()
+		require.NoError(t, err, string(output))
+		assert.Empty(t, strings.TrimSpace(string(output)))
+
+		snapshot := filepath.Join(dir, "old.md")
+		require.NoError(t, os.WriteFile(snapshot, []byte("# Install Guide\n"), 0o644))
+		err = exec.Command(binary, "diff", "--exit-code", snapshot, page).Run()
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 1, exitErr.ExitCode())
+	})
+}
@@ -1,8 +1,12 @@
 package specs
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -226,6 +230,167 @@ func TestDOMReadyEventsSpec(t *testing.T) {
 		assert.GreaterOrEqual(t, duration, 1400*time.Millisecond, "Should wait for custom selector")
 	})
 
+	t.Run("custom_text_waiting", func(t *testing.T) {
+		t.Log("SPEC: Custom Text Waiting")
+		t.Log("GIVEN a page whose article text is rendered after a delay")
+		t.Log("WHEN sz waits for a phrase with --wait-for-text")
+		t.Log("THEN it should extract content only after the phrase appears in the body")
+
+		binary := buildBinary(t)
+
+		delayedHTML := `<!DOCTYPE html>
+<html>
+<head>
+    <title>Delayed Text</title>
+</head>
+<body>
+    <h1>Initial Content</h1>
+    <div id="loading">Loading article...</div>
+    <script>
+        setTimeout(() => {
+            document.getElementById('loading').innerHTML = '<p>The conclusion of the story is finally here.</p>';
+        }, 1500);
+    </script>
+</body>
+</html>`
+
+		tmpFile, err := os.CreateTemp("", "delayed-text*.html")
+		require.NoError(t, err)
+		defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+		_, err = tmpFile.Write([]byte(delayedHTML))
+		require.NoError(t, err)
+		err = tmpFile.Close()
+		require.NoError(t, err)
+
+		cmd := exec.Command(binary, "--wait-for-text=conclusion of the story", tmpFile.Name())
+		start := time.Now()
+		output, err := cmd.CombinedOutput()
+		duration := time.Since(start)
+
+		require.NoError(t, err, "Command should succeed: %s", string(output))
+
+		outputStr := string(output)
+
+		assert.Contains(t, outputStr, "conclusion of the story", "Should extract content after text appears")
+		assert.NotContains(t, outputStr, "Loading article...", "Should not extract loading state")
+
+		// Should have waited at least 1.5 seconds for the text
+		assert.GreaterOrEqual(t, duration, 1400*time.Millisecond, "Should wait for custom text")
+	})
+
+	t.Run("lazy_content_scrolling", func(t *testing.T) {
+		t.Log("SPEC: Auto-Scroll for Lazy Content")
+		t.Log("GIVEN a page that only loads its article body once the reader scrolls")
+		t.Log("WHEN sz processes the page with --scroll=auto")
+		t.Log("THEN it should scroll to the bottom and extract the lazy-loaded content")
+
+		binary := buildBinary(t)
+
+		lazyHTML := `<!DOCTYPE html>
+<html>
+<head>
+    <title>Lazy Content</title>
+</head>
+<body>
+    <h1>Infinite Article</h1>
+    <div style="height: 3000px">Scroll down to keep reading.</div>
+    <div id="more"></div>
+    <script>
+        window.addEventListener('scroll', () => {
+            const more = document.getElementById('more');
+            if (!more.innerHTML && window.scrollY + window.innerHeight >= document.body.scrollHeight - 10) {
+                more.innerHTML = '<p>Lazy loaded continuation of the article.</p>';
+            }
+        });
+    </script>
+</body>
+</html>`
+
+		tmpFile, err := os.CreateTemp("", "lazy*.html")
+		require.NoError(t, err)
+		defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+		_, err = tmpFile.Write([]byte(lazyHTML))
+		require.NoError(t, err)
+		err = tmpFile.Close()
+		require.NoError(t, err)
+
+		cmd := exec.Command(binary, "--scroll=auto", tmpFile.Name())
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Command should succeed: %s", string(output))
+
+		outputStr := string(output)
+
+		assert.Contains(t, outputStr, "Infinite Article", "Should extract initial content")
+		assert.Contains(t, outputStr, "Lazy loaded continuation", "Should extract content loaded by scrolling")
+	})
+
+	t.Run("pre_extraction_eval", func(t *testing.T) {
+		t.Log("SPEC: Pre-Extraction JavaScript Evaluation")
+		t.Log("GIVEN a page whose article body is collapsed behind a button")
+		t.Log("WHEN sz processes the page with --eval clicking the button")
+		t.Log("THEN it should extract the expanded content")
+
+		binary := buildBinary(t)
+
+		collapsedHTML := `<!DOCTYPE html>
+<html>
+<head>
+    <title>Collapsed Content</title>
+</head>
+<body>
+    <h1>Collapsed Article</h1>
+    <button class="expand" onclick="document.getElementById('rest').innerHTML = '<p>The hidden remainder of the article.</p>'">Read more</button>
+    <div id="rest"></div>
+</body>
+</html>`
+
+		tmpFile, err := os.CreateTemp("", "collapsed*.html")
+		require.NoError(t, err)
+		defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+		_, err = tmpFile.Write([]byte(collapsedHTML))
+		require.NoError(t, err)
+		err = tmpFile.Close()
+		require.NoError(t, err)
+
+		cmd := exec.Command(binary, `--eval=document.querySelector(".expand").click()`, tmpFile.Name())
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Command should succeed: %s", string(output))
+
+		outputStr := string(output)
+
+		assert.Contains(t, outputStr, "Collapsed Article", "Should extract initial content")
+		assert.Contains(t, outputStr, "hidden remainder", "Should extract content revealed by the script")
+	})
+
+	t.Run("invalid_action_script_rejected", func(t *testing.T) {
+		t.Log("SPEC: Action Script Validation")
+		t.Log("GIVEN an --actions file with a misspelled step")
+		t.Log("WHEN sz processes a URL with the script")
+		t.Log("THEN it should fail with an error naming the bad step instead of fetching")
+
+		binary := buildBinary(t)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("<html><body><h1>Behind a click</h1></body></html>"))
+		}))
+		defer server.Close()
+
+		actionsPath := filepath.Join(t.TempDir(), "steps.yaml")
+		require.NoError(t, os.WriteFile(actionsPath, []byte("steps:\n  - click: \"button.accept\"\n  - clik: \".expand\"\n"), 0o644))
+
+		cmd := exec.Command(binary, "--actions", actionsPath, server.URL)
+		output, err := cmd.CombinedOutput()
+		require.Error(t, err, "Command should fail for an invalid action script")
+
+		outputStr := string(output)
+
+		assert.Contains(t, outputStr, "clik", "Should name the unknown step")
+		assert.NotContains(t, outputStr, "Behind a click", "Should not extract content with a broken script")
+	})
+
 	t.Run("readiness_result_information", func(t *testing.T) {
 		t.Log("SPEC: Readiness Result Information")
 		t.Log("GIVEN a page with various readiness indicators")
@@ -270,6 +435,358 @@ func TestDOMReadyEventsSpec(t *testing.T) {
 		// For now, just ensure the content extraction works correctly
 	})
 
+	t.Run("readiness_report_output", func(t *testing.T) {
+		t.Log("SPEC: Machine-Readable Readiness Report")
+		t.Log("GIVEN a page processed with --readiness-report")
+		t.Log("WHEN sz finishes extraction")
+		t.Log("THEN it should write the readiness result as JSON to the report file")
+
+		binary := buildBinary(t)
+
+		dir := t.TempDir()
+		htmlPath := filepath.Join(dir, "report.html")
+		require.NoError(t, os.WriteFile(htmlPath, []byte(`<!DOCTYPE html>
+<html>
+<head>
+    <title>Report Page</title>
+</head>
+<body>
+    <h1>Reported Article</h1>
+    <p>Content used to check the readiness report.</p>
+</body>
+</html>`), 0o644))
+
+		reportPath := filepath.Join(dir, "readiness.json")
+		cmd := exec.Command(binary, "--readiness-report", reportPath, htmlPath)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Command should succeed: %s", string(output))
+
+		assert.Contains(t, string(output), "Reported Article", "Should still extract content")
+
+		data, err := os.ReadFile(reportPath)
+		require.NoError(t, err, "Should write the readiness report file")
+
+		var report map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &report), "Report should be valid JSON: %s", string(data))
+
+		assert.Contains(t, report, "is_ready", "Report should include readiness state")
+		assert.NotEmpty(t, report["event_type"], "Report should include the event type")
+		assert.Contains(t, report, "wait_time_ms", "Report should include the wait time")
+		assert.IsType(t, []interface{}{}, report["stages"], "Report should include per-stage timings")
+	})
+
+	t.Run("late_hydration", func(t *testing.T) {
+		t.Log("SPEC: Framework Hydration")
+		t.Log("GIVEN a SolidJS-style page whose app only hydrates its content well after the DOM is ready")
+		t.Log("WHEN sz processes the page with --wait-for-frameworks")
+		t.Log("THEN it should wait for the hydration-complete signal before extraction")
+
+		binary := buildBinary(t)
+
+		dir := t.TempDir()
+		htmlPath := filepath.Join(dir, "hydrate.html")
+		require.NoError(t, os.WriteFile(htmlPath, []byte(`<!DOCTYPE html>
+<html>
+<head>
+    <title>Hydrating App</title>
+</head>
+<body>
+    <main id="story" data-hk="0"><p>Loading...</p></main>
+    <script>
+        window._$HY = { done: false };
+        setTimeout(function() {
+            document.getElementById('story').innerHTML = '<h1>Hydrated Story</h1><p>This paragraph only exists once the app has finished hydrating.</p>';
+            window._$HY.done = true;
+        }, 1500);
+    </script>
+</body>
+</html>`), 0o644))
+
+		reportPath := filepath.Join(dir, "readiness.json")
+		cmd := exec.Command(binary, "--wait-for-frameworks", "--readiness-report", reportPath, htmlPath)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Command should succeed: %s", string(output))
+
+		assert.Contains(t, string(output), "Hydrated Story", "Should extract the hydrated content")
+		assert.Contains(t, string(output), "finished hydrating", "Should wait for hydration to complete")
+		assert.NotContains(t, string(output), "Loading...", "Should not extract the pre-hydration placeholder")
+
+		data, err := os.ReadFile(reportPath)
+		require.NoError(t, err, "Should write the readiness report file")
+		var report struct {
+			EventType string `json:"event_type"`
+		}
+		require.NoError(t, json.Unmarshal(data, &report), "Report should be valid JSON: %s", string(data))
+		assert.Equal(t, "SolidReady", report.EventType, "The framework stage should have recognised the app")
+	})
+
+	t.Run("hash_route_content", func(t *testing.T) {
+		t.Log("SPEC: Hash Route Readiness")
+		t.Log("GIVEN a single-page app whose router only renders the requested #/ route a second after load")
+		t.Log("WHEN sz fetches a URL with that hash route")
+		t.Log("THEN it should wait for the route's content instead of extracting the home view")
+
+		binary := buildBinary(t)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<!DOCTYPE html>
+<html>
+<head>
+    <title>Hash Router</title>
+</head>
+<body>
+    <main id="view"></main>
+    <script>
+        var target = location.hash;
+        history.replaceState(null, '', '#/');
+        document.getElementById('view').innerHTML = '<h1>Home</h1><p>Welcome to the front page of the reading room.</p>';
+        setTimeout(function() {
+            history.pushState(null, '', target);
+            document.getElementById('view').innerHTML = '<h1>Article Five</h1><p>The fifth article is only rendered once the router reaches its route.</p>';
+        }, 1000);
+    </script>
+</body>
+</html>`))
+		}))
+		defer server.Close()
+
+		reportPath := filepath.Join(t.TempDir(), "readiness.json")
+		cmd := exec.Command(binary, "--readiness-report", reportPath, server.URL+"/#/article/5")
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Command should succeed: %s", string(output))
+
+		assert.Contains(t, string(output), "Article Five", "Should extract the routed view")
+		assert.Contains(t, string(output), "reaches its route", "Should wait for the route's content")
+		assert.NotContains(t, string(output), "front page of the reading room", "Should not extract the home view")
+
+		data, err := os.ReadFile(reportPath)
+		require.NoError(t, err, "Should write the readiness report file")
+		var report struct {
+			Stages []struct {
+				Name    string `json:"name"`
+				Success bool   `json:"success"`
+			} `json:"stages"`
+		}
+		require.NoError(t, json.Unmarshal(data, &report), "Report should be valid JSON: %s", string(data))
+		var routed bool
+		for _, stage := range report.Stages {
+			if stage.Name == "route" {
+				routed = stage.Success
+			}
+		}
+		assert.True(t, routed, "The route stage should have run and succeeded: %s", string(data))
+	})
+
+	t.Run("shifting_layout", func(t *testing.T) {
+		t.Log("SPEC: Layout Stability")
+		t.Log("GIVEN a page that keeps pushing its article down with banners for over a second after load")
+		t.Log("WHEN sz processes the page with --wait-for-layout-stable")
+		t.Log("THEN it should wait for the layout to settle before extraction")
+
+		binary := buildBinary(t)
+
+		dir := t.TempDir()
+		htmlPath := filepath.Join(dir, "shifting.html")
+		require.NoError(t, os.WriteFile(htmlPath, []byte(`<!DOCTYPE html>
+<html>
+<head>
+    <title>Shifting Layout</title>
+</head>
+<body>
+    <article id="article">
+        <h1>Settling Page</h1>
+        <p>Banners keep loading above this article and pushing it down the page.</p>
+    </article>
+    <script>
+        var ticks = 0;
+        var timer = setInterval(function() {
+            ticks++;
+            var banner = document.createElement('div');
+            banner.style.height = '120px';
+            banner.textContent = 'Promotion ' + ticks;
+            document.body.insertBefore(banner, document.getElementById('article'));
+            if (ticks === 8) {
+                clearInterval(timer);
+                var final = document.createElement('p');
+                final.textContent = 'This paragraph is added once the layout has finally settled.';
+                document.getElementById('article').appendChild(final);
+            }
+        }, 200);
+    </script>
+</body>
+</html>`), 0o644))
+
+		reportPath := filepath.Join(dir, "readiness.json")
+		cmd := exec.Command(binary, "--wait-for-layout-stable", "--readiness-report", reportPath, htmlPath)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Command should succeed: %s", string(output))
+
+		assert.Contains(t, string(output), "Settling Page", "Should extract the article")
+		assert.Contains(t, string(output), "finally settled", "Should wait for the layout to stop shifting")
+
+		data, err := os.ReadFile(reportPath)
+		require.NoError(t, err, "Should write the readiness report file")
+		var report struct {
+			EventType string `json:"event_type"`
+			Stages    []struct {
+				Name    string `json:"name"`
+				Success bool   `json:"success"`
+			} `json:"stages"`
+		}
+		require.NoError(t, json.Unmarshal(data, &report), "Report should be valid JSON: %s", string(data))
+		assert.Equal(t, "LayoutStable", report.EventType)
+		var settled bool
+		for _, stage := range report.Stages {
+			if stage.Name == "layout" {
+				settled = stage.Success
+			}
+		}
+		assert.True(t, settled, "The layout stage should have succeeded: %s", string(data))
+	})
+
+	t.Run("stage_budget_exceeded", func(t *testing.T) {
+		t.Log("SPEC: Readiness Stage Budgets")
+		t.Log("GIVEN a page that never renders the selector sz is told to wait for")
+		t.Log("WHEN sz processes it with --stage-timeout selector=500ms and --readiness-report")
+		t.Log("THEN it should give up on that stage, still extract the page, and report a partial result naming the stage")
+
+		binary := buildBinary(t)
+
+		dir := t.TempDir()
+		htmlPath := filepath.Join(dir, "budget.html")
+		require.NoError(t, os.WriteFile(htmlPath, []byte(`<!DOCTYPE html>
+<html>
+<head>
+    <title>Budgeted Page</title>
+</head>
+<body>
+    <article>
+        <h1>Budgeted Page</h1>
+        <p>The element sz waits for is never added to this page.</p>
+    </article>
+</body>
+</html>`), 0o644))
+
+		reportPath := filepath.Join(dir, "readiness.json")
+		start := time.Now()
+		cmd := exec.Command(binary, "--wait-for-selector", "#never", "--stage-timeout", "selector=500ms", "--readiness-report", reportPath, htmlPath)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Command should succeed with a partial result: %s", string(output))
+		assert.Less(t, time.Since(start), 5*time.Second, "Should give up on the stage once its budget runs out")
+
+		assert.Contains(t, string(output), "never added to this page", "Should still extract the page")
+
+		data, err := os.ReadFile(reportPath)
+		require.NoError(t, err, "Should write the readiness report file")
+		assert.Contains(t, string(data), `"partial": true`, "Report should mark the result partial")
+
+		var report struct {
+			Partial bool `json:"partial"`
+			Stages  []struct {
+				Name     string `json:"name"`
+				TimedOut bool   `json:"timed_out"`
+			} `json:"stages"`
+		}
+		require.NoError(t, json.Unmarshal(data, &report), "Report should be valid JSON: %s", string(data))
+		var timedOut []string
+		for _, stage := range report.Stages {
+			if stage.TimedOut {
+				timedOut = append(timedOut, stage.Name)
+			}
+		}
+		assert.Equal(t, []string{"selector"}, timedOut, "Only the selector stage should have run out of budget")
+	})
+
+	t.Run("console_capture", func(t *testing.T) {
+		t.Log("SPEC: Console Log Capture")
+		t.Log("GIVEN a page that logs to the console and throws an uncaught error")
+		t.Log("WHEN sz processes it with --console-log")
+		t.Log("THEN the log file should contain the console message and the error")
+
+		binary := buildBinary(t)
+
+		dir := t.TempDir()
+		htmlPath := filepath.Join(dir, "console.html")
+		require.NoError(t, os.WriteFile(htmlPath, []byte(`<!DOCTYPE html>
+<html>
+<body>
+    <h1>Console Article</h1>
+    <p>Content used to check console capture.</p>
+    <script>
+        console.log('hydration starting');
+        setTimeout(function() { throw new Error('hydration exploded'); }, 0);
+    </script>
+</body>
+</html>`), 0o644))
+
+		logPath := filepath.Join(dir, "console.log")
+		cmd := exec.Command(binary, "--console-log", logPath, htmlPath)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Command should succeed: %s", string(output))
+
+		assert.Contains(t, string(output), "Console Article", "Should still extract content")
+
+		data, err := os.ReadFile(logPath)
+		require.NoError(t, err, "Should write the console log file")
+
+		assert.Contains(t, string(data), "[log] hydration starting", "Should record console messages")
+		assert.Contains(t, string(data), "hydration exploded", "Should record uncaught errors")
+	})
+
+	t.Run("har_capture", func(t *testing.T) {
+		t.Log("SPEC: Network Log Capture")
+		t.Log("GIVEN a page reached through a redirect that loads a stylesheet")
+		t.Log("WHEN sz processes it with --har")
+		t.Log("THEN the HAR file should list the redirect, the page, and the stylesheet")
+
+		binary := buildBinary(t)
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/article", http.StatusFound)
+		})
+		mux.HandleFunc("/article", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`<html><head><link rel="stylesheet" href="/style.css"></head><body><h1>Recorded Article</h1><p>Content used to check network recording.</p></body></html>`))
+		})
+		mux.HandleFunc("/style.css", func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/css")
+			_, _ = w.Write([]byte(`h1 { color: black; }`))
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		harPath := filepath.Join(t.TempDir(), "page.har")
+		cmd := exec.Command(binary, "--har", harPath, server.URL+"/old")
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Command should succeed: %s", string(output))
+
+		data, err := os.ReadFile(harPath)
+		require.NoError(t, err, "Should write the HAR file")
+
+		var log struct {
+			Log struct {
+				Entries []struct {
+					Request struct {
+						URL string `json:"url"`
+					} `json:"request"`
+					Response struct {
+						Status int `json:"status"`
+					} `json:"response"`
+				} `json:"entries"`
+			} `json:"log"`
+		}
+		require.NoError(t, json.Unmarshal(data, &log), "HAR should be valid JSON")
+
+		statuses := map[string]int{}
+		for _, entry := range log.Log.Entries {
+			statuses[entry.Request.URL] = entry.Response.Status
+		}
+		assert.Equal(t, http.StatusFound, statuses[server.URL+"/old"], "Should record the redirect")
+		assert.Equal(t, http.StatusOK, statuses[server.URL+"/article"], "Should record the page")
+		assert.Equal(t, http.StatusOK, statuses[server.URL+"/style.css"], "Should record the stylesheet")
+	})
+
 	t.Run("network_error_recovery", func(t *testing.T) {
 		t.Log("SPEC: Network Error Recovery")
 		t.Log("GIVEN an invalid URL that cannot be loaded")
@@ -0,0 +1,65 @@
+package specs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadSpec(t *testing.T) {
+	pdf := []byte("%PDF-1.4\n% fake report body\n%%EOF\n")
+
+	newServer := func() *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Header().Set("Content-Disposition", `attachment; filename="report.pdf"`)
+			_, _ = w.Write(pdf)
+		}))
+	}
+
+	t.Run("saves_file_downloads", func(t *testing.T) {
+		t.Log("SPEC: File Download Handling")
+		t.Log("GIVEN a URL that serves a PDF as an attachment")
+		t.Log("WHEN the user fetches it with --download-dir")
+		t.Log("THEN the file should be saved there and its path reported")
+
+		binary := buildBinary(t)
+		server := newServer()
+		defer server.Close()
+
+		dir := t.TempDir()
+		cmd := exec.Command(binary, "--download-dir", dir, server.URL+"/files/report")
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Fetch should succeed: %s", string(output))
+
+		savedPath := filepath.Join(dir, "report.pdf")
+		assert.Contains(t, string(output), savedPath, "Should report the saved path")
+
+		saved, err := os.ReadFile(savedPath)
+		require.NoError(t, err, "Should save the file")
+		assert.Equal(t, pdf, saved, "Should save the file unchanged")
+	})
+
+	t.Run("download_without_directory_rejected", func(t *testing.T) {
+		t.Log("SPEC: File Download Without a Directory")
+		t.Log("GIVEN a URL that serves a PDF as an attachment")
+		t.Log("WHEN the user fetches it without --download-dir")
+		t.Log("THEN the command should fail and suggest --download-dir")
+
+		binary := buildBinary(t)
+		server := newServer()
+		defer server.Close()
+
+		cmd := exec.Command(binary, server.URL+"/files/report")
+		output, err := cmd.CombinedOutput()
+		require.Error(t, err, "Fetch should fail without a download directory")
+
+		assert.Contains(t, string(output), "--download-dir", "Should suggest --download-dir")
+	})
+}
@@ -0,0 +1,111 @@
+package specs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmulationSpec(t *testing.T) {
+	t.Run("device_emulation", func(t *testing.T) {
+		t.Log("SPEC: Device Emulation")
+		t.Log("GIVEN a responsive page that only shows its article on narrow screens")
+		t.Log("WHEN the user fetches it with --device \"iPhone 14\"")
+		t.Log("THEN the page should render its mobile layout before extraction")
+
+		binary := buildBinary(t)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`<html><body>
+<article id="content"></article>
+<script>
+if (window.innerWidth < 600) {
+	document.getElementById('content').innerHTML = '<h1>Mobile Layout</h1><p>The compact article layout shown on phones.</p>';
+} else {
+	document.getElementById('content').innerHTML = '<h1>Desktop Layout</h1><p>The wide layout with sidebars shown on desktops.</p>';
+}
+</script>
+</body></html>`))
+		}))
+		defer server.Close()
+
+		cmd := exec.Command(binary, "--device", "iPhone 14", server.URL)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Fetch should succeed: %s", string(output))
+
+		assert.Contains(t, string(output), "Mobile Layout", "Should render the mobile layout")
+		assert.NotContains(t, string(output), "Desktop Layout", "Should not render the desktop layout")
+	})
+
+	t.Run("invalid_viewport_rejected", func(t *testing.T) {
+		t.Log("SPEC: Invalid Viewport")
+		t.Log("GIVEN a --viewport value that is not WIDTHxHEIGHT")
+		t.Log("WHEN the command runs")
+		t.Log("THEN it should fail and explain the expected format")
+
+		cmd := exec.Command("go", "run", "../cmd/essenz/main.go", "--viewport", "wide", "https://example.com")
+		output, err := cmd.CombinedOutput()
+		require.Error(t, err, "Command should fail with an invalid viewport")
+
+		assert.Contains(t, string(output), "WIDTHxHEIGHT", "Should explain the viewport format")
+	})
+
+	t.Run("unknown_device_rejected", func(t *testing.T) {
+		t.Log("SPEC: Unknown Device")
+		t.Log("GIVEN a --device name that is not a known device")
+		t.Log("WHEN the command runs")
+		t.Log("THEN it should fail and name the device")
+
+		cmd := exec.Command("go", "run", "../cmd/essenz/main.go", "--device", "Toaster 3000", "https://example.com")
+		output, err := cmd.CombinedOutput()
+		require.Error(t, err, "Command should fail with an unknown device")
+
+		assert.Contains(t, string(output), "unknown device", "Should report the unknown device")
+	})
+
+	t.Run("locale_language_preference", func(t *testing.T) {
+		t.Log("SPEC: Locale Emulation")
+		t.Log("GIVEN a site that serves content in the language the browser prefers")
+		t.Log("WHEN the user fetches it with --locale de-DE")
+		t.Log("THEN the German edition should be extracted")
+
+		binary := buildBinary(t)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.Header.Get("Accept-Language"), "de") {
+				_, _ = w.Write([]byte(`<html lang="de"><body><article><h1>Willkommen</h1><p>Dies ist die deutsche Ausgabe des Artikels.</p></article></body></html>`))
+				return
+			}
+			_, _ = w.Write([]byte(`<html lang="en"><body><article><h1>Welcome</h1><p>This is the English edition of the article.</p></article></body></html>`))
+		}))
+		defer server.Close()
+
+		cmd := exec.Command(binary, "--locale", "de-DE", "--timezone", "Europe/Berlin", server.URL)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Fetch should succeed: %s", string(output))
+
+		assert.Contains(t, string(output), "Willkommen", "Should fetch the German edition")
+	})
+
+	t.Run("invalid_region_rejected", func(t *testing.T) {
+		t.Log("SPEC: Invalid Region Options")
+		t.Log("GIVEN an unknown time zone or a malformed geolocation")
+		t.Log("WHEN the command runs")
+		t.Log("THEN it should fail and explain the expected format")
+
+		cmd := exec.Command("go", "run", "../cmd/essenz/main.go", "--timezone", "Mars/Olympus", "https://example.com")
+		output, err := cmd.CombinedOutput()
+		require.Error(t, err, "Command should fail with an unknown time zone")
+		assert.Contains(t, string(output), "invalid timezone", "Should report the time zone")
+
+		cmd = exec.Command("go", "run", "../cmd/essenz/main.go", "--geolocation", "north", "https://example.com")
+		output, err = cmd.CombinedOutput()
+		require.Error(t, err, "Command should fail with a malformed geolocation")
+		assert.Contains(t, string(output), "latitude,longitude", "Should explain the geolocation format")
+	})
+}
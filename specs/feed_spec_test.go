@@ -0,0 +1,86 @@
+package specs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedSpec(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rss.xml":
+			_, _ = w.Write([]byte(`<?xml version="1.0"?>
+<rss version="2.0"><channel><title>Example Blog</title>
+  <item><title>Second Post</title><link>` + server.URL + `/posts/second</link><pubDate>Tue, 04 Jun 2024 10:00:00 GMT</pubDate></item>
+  <item><title>First Post</title><link>` + server.URL + `/posts/first</link><pubDate>Sat, 06 Jan 2024 10:00:00 GMT</pubDate></item>
+</channel></rss>`))
+		case "/atom.xml":
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom"><title>Example Atom</title>
+  <entry><title>Full Text Entry</title><link href="` + server.URL + `/never-fetched"/><updated>2024-06-04T10:00:00Z</updated>
+    <content type="html">&lt;h1&gt;Inline Article&lt;/h1&gt;&lt;p&gt;This entry carries its whole text inside the feed, so there is nothing to fetch.&lt;/p&gt;</content></entry>
+</feed>`))
+		case "/never-fetched":
+			http.Error(w, "should not be fetched", http.StatusTeapot)
+		default:
+			_, _ = w.Write([]byte(`<html><body><nav>Blog menu</nav><article><h1>Article ` + r.URL.Path + `</h1><h2>Details</h2><p>Every post on this blog has enough text to be extracted as the main content.</p></article></body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	binary := buildBinary(t)
+	run := func(t *testing.T, args ...string) (string, string) {
+		cmd := exec.Command(binary, append([]string{"feed", "--socket", filepath.Join(t.TempDir(), "sz.sock")}, args...)...)
+		cmd.Env = append(os.Environ(), "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+		var stdout, stderr strings.Builder
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		require.NoError(t, cmd.Run(), stderr.String())
+		return stdout.String(), stderr.String()
+	}
+
+	t.Run("feed_digest", func(t *testing.T) {
+		t.Log("SPEC: Feed Digest")
+		t.Log("GIVEN an RSS feed linking to two posts")
+		t.Log("WHEN the user runs sz feed --since 2024-03-01 on it")
+		t.Log("THEN the recent post should be fetched, distilled, and written as one digest under linked headings")
+
+		stdout, stderr := run(t, "--since", "2024-03-01", server.URL+"/rss.xml")
+
+		assert.True(t, strings.HasPrefix(stdout, "# Example Blog\n"), stdout)
+		assert.Contains(t, stdout, "## [Second Post]("+server.URL+"/posts/second)")
+		assert.Contains(t, stdout, "*2024-06-04*")
+		assert.Contains(t, stdout, "### Article /posts/second", "Article headings should nest under the entry heading")
+		assert.Contains(t, stdout, "#### Details")
+		assert.NotContains(t, stdout, "First Post", "Entries before --since should be left out")
+		assert.NotContains(t, stdout, "Blog menu")
+		assert.Contains(t, stderr, "Distilled 1 of 1 articles")
+	})
+
+	t.Run("feed_from_feed_content", func(t *testing.T) {
+		t.Log("SPEC: Feed Content")
+		t.Log("GIVEN an Atom feed whose entry carries its full text")
+		t.Log("WHEN the user runs sz feed --from-feed --output-dir DIR")
+		t.Log("THEN the entry should be distilled from the feed into its own file without fetching the page")
+
+		dir := t.TempDir()
+		_, stderr := run(t, "--from-feed", "--output-dir", dir, server.URL+"/atom.xml")
+
+		assert.Contains(t, stderr, "Distilled 1 of 1 targets")
+		files, err := filepath.Glob(filepath.Join(dir, "*.md"))
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		content, err := os.ReadFile(files[0])
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "# Inline Article")
+	})
+}
@@ -39,7 +39,7 @@ func TestFetchLocalFileSpec(t *testing.T) {
 func TestFetchHTTPSURLSpec(t *testing.T) {
 	t.Log("SPEC: Fetch Command with HTTPS URL Support")
 	t.Log("GIVEN a valid HTTPS URL")
-	t.Log("WHEN the user runs `sz fetch https://example.com`")
+	t.Log("WHEN the user runs `sz fetch --insecure https://example.com` on a self-signed server")
 	t.Log("THEN the output should display the HTTPS response content")
 
 	// Create a test HTTP server
@@ -50,7 +50,7 @@ func TestFetchHTTPSURLSpec(t *testing.T) {
 	defer server.Close()
 
 	// Run the fetch command with the test server URL
-	cmd := exec.Command("go", "run", "../cmd/essenz/main.go", "fetch", server.URL)
+	cmd := exec.Command("go", "run", "../cmd/essenz/main.go", "fetch", "--insecure", server.URL)
 	output, err := cmd.CombinedOutput()
 	require.NoError(t, err, "Fetch command should succeed")
 
@@ -0,0 +1,144 @@
+package specs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterTuningSpec(t *testing.T) {
+	t.Run("tune_writes_site_rules", func(t *testing.T) {
+		t.Log("SPEC: Interactive Filter Tuning")
+		t.Log("GIVEN a page whose content filter result needs adjusting")
+		t.Log("WHEN the user toggles blocks in sz tune and saves")
+		t.Log("THEN a per-site rule file with preserve and exclude selectors is written")
+
+		binary := buildContentFilterBinary(t)
+		configHome := t.TempDir()
+
+		tuneHTML := `<!DOCTYPE html>
+<html>
+<body>
+    <div class="sidebar">
+        <p>Author biography worth keeping.</p>
+    </div>
+    <article>
+        <h1>Tuned Article</h1>
+        <p>Primary article paragraph with the real content.</p>
+        <p>Inline promotion paragraph to drop.</p>
+    </article>
+</body>
+</html>`
+
+		htmlFile := filepath.Join(t.TempDir(), "tune-test.html")
+		require.NoError(t, os.WriteFile(htmlFile, []byte(tuneHTML), 0o644))
+
+		cmd := exec.Command(binary, "tune", "--site", "example.com", htmlFile)
+		cmd.Env = append(os.Environ(), "XDG_CONFIG_HOME="+configHome)
+		cmd.Stdin = strings.NewReader("1 4\ns\n")
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Command should succeed: %s", string(output))
+
+		outputStr := string(output)
+		assert.Contains(t, outputStr, "[drop] p          Author biography", "Should show the filter removed the sidebar block")
+		assert.Contains(t, outputStr, "Saved 1 preserve and 1 exclude rules", "Should report saved rules")
+
+		rules, err := os.ReadFile(filepath.Join(configHome, "essenz", "sites", "example.com.yaml"))
+		require.NoError(t, err, "Should write the site rule file")
+
+		rulesStr := string(rules)
+		assert.Contains(t, rulesStr, "host: example.com")
+		assert.Contains(t, rulesStr, "preserve:")
+		assert.Contains(t, rulesStr, "div:nth-child(1) > p:nth-child(1)")
+		assert.Contains(t, rulesStr, "exclude:")
+		assert.Contains(t, rulesStr, "article:nth-child(2) > p:nth-child(3)")
+	})
+
+	t.Run("tune_reverses_an_earlier_decision", func(t *testing.T) {
+		t.Log("SPEC: Interactive Filter Tuning")
+		t.Log("GIVEN a block the user removed in an earlier sz tune session")
+		t.Log("WHEN the user tunes the site again and keeps that block")
+		t.Log("THEN the earlier exclude rule is replaced and the block is kept from then on")
+
+		binary := buildContentFilterBinary(t)
+		configHome := t.TempDir()
+
+		htmlFile := filepath.Join(t.TempDir(), "tune-reverse.html")
+		require.NoError(t, os.WriteFile(htmlFile, []byte(`<!DOCTYPE html>
+<html>
+<body>
+    <article>
+        <h1>Tuned Article</h1>
+        <p>Primary article paragraph with the real content.</p>
+        <p>Inline promotion paragraph the user changes their mind about.</p>
+    </article>
+</body>
+</html>`), 0o644))
+		rulesFile := filepath.Join(configHome, "essenz", "sites", "example.com.yaml")
+
+		tune := func(input string) string {
+			cmd := exec.Command(binary, "tune", "--site", "example.com", htmlFile)
+			cmd.Env = append(os.Environ(), "XDG_CONFIG_HOME="+configHome)
+			cmd.Stdin = strings.NewReader(input)
+			output, err := cmd.CombinedOutput()
+			require.NoError(t, err, "Command should succeed: %s", string(output))
+			return string(output)
+		}
+
+		output := tune("3\ns\n")
+		assert.Contains(t, output, "Saved 0 preserve and 1 exclude rules")
+
+		output = tune("3\ns\n")
+		assert.Contains(t, output, "[drop] p          Inline promotion", "The saved exclude rule should apply")
+		assert.Contains(t, output, "Saved 1 preserve and 0 exclude rules", "Keeping the block should replace its exclude rule")
+
+		rules, err := os.ReadFile(rulesFile)
+		require.NoError(t, err)
+		assert.NotContains(t, string(rules), "exclude:", "No exclude rule should be left: %s", string(rules))
+
+		output = tune("q\n")
+		assert.Contains(t, output, "[keep] p          Inline promotion", "The block should be kept again")
+	})
+}
+
+func TestSiteLearningSpec(t *testing.T) {
+	t.Run("learn_site_emits_exclude_rules", func(t *testing.T) {
+		t.Log("SPEC: Cross-Page Site Profile Learning")
+		t.Log("GIVEN several pages from one site sharing navigation, promos and footers")
+		t.Log("WHEN sz learn-site fetches them")
+		t.Log("THEN the repeated subtrees are emitted as exclude rules and article content is not")
+
+		binary := buildContentFilterBinary(t)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = fmt.Fprintf(w, `<html><body>
+<nav class="site-nav"><a href="/one">One</a><a href="/two">Two</a><a href="/three">Three</a></nav>
+<div class="promo-strip"><p>Subscribe to our newsletter for weekly updates.</p></div>
+<article><h1>Page %[1]s</h1><p>Unique article body for page %[1]s.</p></article>
+<div id="site-footer"><p>Copyright Example Corporation</p></div>
+</body></html>`, r.URL.Path)
+		}))
+		defer server.Close()
+
+		cmd := exec.Command(binary, "learn-site", "--dry-run", server.URL+"/one")
+		cmd.Env = append(os.Environ(), "XDG_CONFIG_HOME="+t.TempDir())
+		output, err := cmd.Output()
+		require.NoError(t, err, "Command should succeed")
+
+		outputStr := string(output)
+		assert.Contains(t, outputStr, "exclude:", "Should emit exclude rules")
+		assert.Contains(t, outputStr, "nav.site-nav", "Should exclude repeated navigation")
+		assert.Contains(t, outputStr, "div.promo-strip", "Should exclude repeated promo")
+		assert.Contains(t, outputStr, "div#site-footer", "Should exclude repeated footer")
+		assert.NotContains(t, outputStr, "article", "Should not exclude per-page article content")
+	})
+}
@@ -0,0 +1,177 @@
+//go:build linux
+
+package specs
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// terminal is a pseudo-terminal whose output is collected as sz writes it.
+type terminal struct {
+	ptm, pts *os.File
+	mu       sync.Mutex
+	output   strings.Builder
+	done     chan struct{}
+}
+
+// openTerminal opens a 100x24 pseudo-terminal.
+func openTerminal(t *testing.T) *terminal {
+	ptm, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	require.NoError(t, err)
+	require.NoError(t, unix.IoctlSetPointerInt(int(ptm.Fd()), unix.TIOCSPTLCK, 0))
+	n, err := unix.IoctlGetInt(int(ptm.Fd()), unix.TIOCGPTN)
+	require.NoError(t, err)
+	pts, err := os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR|syscall.O_NOCTTY, 0)
+	require.NoError(t, err)
+	require.NoError(t, unix.IoctlSetWinsize(int(pts.Fd()), unix.TIOCSWINSZ, &unix.Winsize{Row: 24, Col: 100}))
+
+	term := &terminal{ptm: ptm, pts: pts, done: make(chan struct{})}
+	go func() {
+		defer close(term.done)
+		buf := make([]byte, 4096)
+		for {
+			n, err := ptm.Read(buf)
+			term.mu.Lock()
+			term.output.Write(buf[:n])
+			term.mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}()
+	t.Cleanup(func() { _ = ptm.Close() })
+	return term
+}
+
+// start runs cmd with the terminal as its stdin, stdout and stderr.
+func (term *terminal) start(t *testing.T, cmd *exec.Cmd) {
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = term.pts, term.pts, term.pts
+	require.NoError(t, cmd.Start())
+	// Only the child holds the terminal open now, so reads end when it exits
+	require.NoError(t, term.pts.Close())
+}
+
+// waitFor waits until the terminal has shown text.
+func (term *terminal) waitFor(t *testing.T, text string) {
+	require.Eventually(t, func() bool {
+		return strings.Contains(term.String(), text)
+	}, 10*time.Second, 20*time.Millisecond, "terminal never showed %q: %q", text, term.String())
+}
+
+// press types keys into the terminal.
+func (term *terminal) press(t *testing.T, keys string) {
+	_, err := term.ptm.WriteString(keys)
+	require.NoError(t, err)
+}
+
+func (term *terminal) String() string {
+	term.mu.Lock()
+	defer term.mu.Unlock()
+	return term.output.String()
+}
+
+func TestFilterTuningTerminalSpec(t *testing.T) {
+	t.Run("tune_full_screen", func(t *testing.T) {
+		t.Log("SPEC: Interactive Filter Tuning")
+		t.Log("GIVEN a page whose content filter result needs adjusting, and sz tune running on a terminal")
+		t.Log("WHEN the user moves between blocks with the arrow keys, toggles them with space, views the result and saves")
+		t.Log("THEN the blocks should be shown full screen and a per-site rule file with preserve and exclude selectors written")
+
+		binary := buildContentFilterBinary(t)
+		configHome := t.TempDir()
+
+		tuneHTML := `<!DOCTYPE html>
+<html>
+<body>
+    <div class="sidebar">
+        <p>Author biography worth keeping.</p>
+    </div>
+    <article>
+        <h1>Tuned Article</h1>
+        <p>Primary article paragraph with the real content.</p>
+        <p>Inline promotion paragraph to drop.</p>
+    </article>
+</body>
+</html>`
+
+		htmlFile := filepath.Join(t.TempDir(), "tune-test.html")
+		require.NoError(t, os.WriteFile(htmlFile, []byte(tuneHTML), 0o644))
+
+		term := openTerminal(t)
+		cmd := exec.Command(binary, "tune", "--site", "example.com", htmlFile)
+		cmd.Env = append(os.Environ(), "XDG_CONFIG_HOME="+configHome, "TERM=xterm")
+		term.start(t, cmd)
+
+		term.waitFor(t, "space toggle")
+		screen := term.String()
+		assert.Contains(t, screen, "\x1b[?1049h", "Should switch to the alternate screen")
+		assert.Contains(t, screen, "Blocks for example.com: 3 of 4 kept, 0 changed")
+		assert.Contains(t, screen, "\x1b[7m   1  [drop] p          Author biography", "The first block should be selected")
+
+		term.press(t, " ")
+		term.waitFor(t, "4 of 4 kept, 1 changed")
+		term.press(t, "\x1b[B\x1b[B\x1b[B")
+		term.waitFor(t, "\x1b[7m   4  [keep] p          Inline promotion")
+		term.press(t, " ")
+		term.waitFor(t, "3 of 4 kept, 2 changed")
+
+		term.press(t, "v")
+		term.waitFor(t, "v back to blocks")
+		assert.Contains(t, term.String(), "Author biography worth keeping.", "The result should include the kept sidebar")
+
+		term.press(t, "s")
+		require.NoError(t, cmd.Wait(), term.String())
+		<-term.done
+
+		output := term.String()
+		assert.Contains(t, output, "\x1b[?1049l", "Should leave the alternate screen")
+		assert.Contains(t, output, "Saved 1 preserve and 1 exclude rules", "Should report saved rules")
+
+		rules, err := os.ReadFile(filepath.Join(configHome, "essenz", "sites", "example.com.yaml"))
+		require.NoError(t, err, "Should write the site rule file")
+
+		rulesStr := string(rules)
+		assert.Contains(t, rulesStr, "div:nth-child(1) > p:nth-child(1)")
+		assert.Contains(t, rulesStr, "article:nth-child(2) > p:nth-child(3)")
+	})
+
+	t.Run("tune_full_screen_quit", func(t *testing.T) {
+		t.Log("SPEC: Interactive Filter Tuning")
+		t.Log("GIVEN sz tune running on a terminal")
+		t.Log("WHEN the user toggles a block and quits with q")
+		t.Log("THEN no rule file should be written")
+
+		binary := buildContentFilterBinary(t)
+		configHome := t.TempDir()
+
+		htmlFile := filepath.Join(t.TempDir(), "tune-quit.html")
+		require.NoError(t, os.WriteFile(htmlFile, []byte(`<html><body><article><h1>Quit Article</h1><p>A paragraph nobody changes.</p></article></body></html>`), 0o644))
+
+		term := openTerminal(t)
+		cmd := exec.Command(binary, "tune", "--site", "example.com", htmlFile)
+		cmd.Env = append(os.Environ(), "XDG_CONFIG_HOME="+configHome, "TERM=xterm")
+		term.start(t, cmd)
+
+		term.waitFor(t, "space toggle")
+		term.press(t, " ")
+		term.waitFor(t, "1 changed")
+		term.press(t, "q")
+		require.NoError(t, cmd.Wait(), term.String())
+		<-term.done
+
+		assert.Contains(t, term.String(), "No rules saved")
+		assert.NoFileExists(t, filepath.Join(configHome, "essenz", "sites", "example.com.yaml"))
+	})
+}
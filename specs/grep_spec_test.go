@@ -0,0 +1,97 @@
+package specs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrepSpec(t *testing.T) {
+	dir := t.TempDir()
+	guide := filepath.Join(dir, "guide.md")
+	require.NoError(t, os.WriteFile(guide, []byte(`# Guide
+
+Read this first.
+
+## Installation
+
+Run go install to get the binary.
+
+### Rate Limits
+
+The API allows 100 requests per minute.
+
+`+"```"+`
+# not a heading: rate limit
+`+"```"+`
+`), 0o644))
+	notes := filepath.Join(dir, "notes.md")
+	require.NoError(t, os.WriteFile(notes, []byte("# Notes\n\nNothing about setup here.\n"), 0o644))
+
+	binary := buildBinary(t)
+	run := func(t *testing.T, args ...string) (string, int) {
+		cmd := exec.Command(binary, append([]string{"grep"}, args...)...)
+		cmd.Env = append(os.Environ(), "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+		output, err := cmd.Output()
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return string(output), exitErr.ExitCode()
+		}
+		require.NoError(t, err)
+		return string(output), 0
+	}
+
+	t.Run("grep_prints_heading_context", func(t *testing.T) {
+		t.Log("SPEC: Heading Context")
+		t.Log("GIVEN a markdown snapshot with nested sections")
+		t.Log("WHEN the user runs sz grep PATTERN FILE")
+		t.Log("THEN each matching line should be printed with its line number under its heading path")
+
+		output, code := run(t, "requests per", guide)
+		require.Equal(t, 0, code)
+		assert.Equal(t, "Guide > Installation > Rate Limits:\n  11: The API allows 100 requests per minute.\n", output)
+	})
+
+	t.Run("grep_ignore_case_skips_fenced_headings", func(t *testing.T) {
+		t.Log("SPEC: Case-Insensitive Matching")
+		t.Log("GIVEN the same snapshot, whose code fence holds a line starting with #")
+		t.Log("WHEN the user runs sz grep -i 'rate limit' FILE")
+		t.Log("THEN both the heading and the fenced line should match, and the fenced line should not start a section")
+
+		output, code := run(t, "-i", "rate limit", guide)
+		require.Equal(t, 0, code)
+		assert.Equal(t, 1, strings.Count(output, "Guide > Installation > Rate Limits:"))
+		assert.Contains(t, output, "  9: ### Rate Limits")
+		assert.Contains(t, output, "  14: # not a heading: rate limit")
+	})
+
+	t.Run("grep_multiple_targets", func(t *testing.T) {
+		t.Log("SPEC: Several Targets")
+		t.Log("GIVEN two snapshots")
+		t.Log("WHEN the user runs sz grep -l or -c across both")
+		t.Log("THEN only targets with a match should be listed, and counts should be prefixed by target")
+
+		output, code := run(t, "-l", "install", guide, notes)
+		require.Equal(t, 0, code)
+		assert.Equal(t, guide+"\n", output)
+
+		output, code = run(t, "-c", "-i", "install", guide, notes)
+		require.Equal(t, 0, code)
+		assert.Equal(t, guide+": 2\n", output)
+	})
+
+	t.Run("grep_no_match", func(t *testing.T) {
+		t.Log("SPEC: No Matches")
+		t.Log("GIVEN the same snapshot")
+		t.Log("WHEN the pattern matches nothing")
+		t.Log("THEN sz grep should print nothing and exit 1, like grep")
+
+		output, code := run(t, "-F", "a.b(c", guide)
+		assert.Equal(t, 1, code)
+		assert.Empty(t, output)
+	})
+}
@@ -0,0 +1,152 @@
+package specs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestHeadersSpec(t *testing.T) {
+	t.Run("custom_headers_and_basic_auth", func(t *testing.T) {
+		t.Log("SPEC: Custom Request Headers and Basic Auth")
+		t.Log("GIVEN a staging site that requires basic auth and an API key header")
+		t.Log("WHEN the user fetches it with --auth and --header")
+		t.Log("THEN the request should carry both and the content should be returned")
+
+		binary := buildBinary(t)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, pass, ok := r.BasicAuth()
+			if !ok || user != "staging" || pass != "s3cret" || r.Header.Get("X-Api-Key") != "abc123" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="staging"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`<html><body><article><h1>Staging Docs</h1><p>Internal API documentation for the staging environment.</p></article></body></html>`))
+		}))
+		defer server.Close()
+
+		cmd := exec.Command(binary, "--auth", "staging:s3cret", "--header", "X-Api-Key: abc123", server.URL+"/docs")
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Fetch should succeed: %s", string(output))
+
+		assert.Contains(t, string(output), "Staging Docs", "Should fetch the protected page")
+	})
+
+	t.Run("malformed_header_rejected", func(t *testing.T) {
+		t.Log("SPEC: Malformed Header")
+		t.Log("GIVEN a --header value without a colon")
+		t.Log("WHEN the command runs")
+		t.Log("THEN it should fail and explain the expected format")
+
+		cmd := exec.Command("go", "run", "../cmd/essenz/main.go", "--header", "X-Api-Key abc123", "https://example.com")
+		output, err := cmd.CombinedOutput()
+		require.Error(t, err, "Command should fail with a malformed header")
+
+		assert.Contains(t, string(output), "Name: value", "Should explain the header format")
+	})
+}
+
+func TestBasicAuthChromeSpec(t *testing.T) {
+	t.Run("basic_auth_stays_with_the_site", func(t *testing.T) {
+		t.Log("SPEC: Basic Auth Sent Only to the Site")
+		t.Log("GIVEN a protected page that loads an image from another host")
+		t.Log("WHEN the user fetches it with --auth")
+		t.Log("THEN the other host should never receive the credentials")
+
+		binary := buildBinary(t)
+
+		var mu sync.Mutex
+		var thirdParty []string
+		cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			thirdParty = append(thirdParty, r.Header.Get("Authorization"))
+			mu.Unlock()
+			// Ask for credentials too, which must not be answered with the site's
+			w.Header().Set("WWW-Authenticate", `Basic realm="cdn"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		}))
+		defer cdn.Close()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, pass, ok := r.BasicAuth()
+			if !ok || user != "staging" || pass != "s3cret" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="staging"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			_, _ = fmt.Fprintf(w, `<html><body><article><h1>Staging Docs</h1><img src="%s/logo.png" alt="logo"><p>Internal API documentation for the staging environment.</p></article></body></html>`, cdn.URL)
+		}))
+		defer server.Close()
+
+		cmd := exec.Command(binary, "--auth", "staging:s3cret", server.URL+"/docs")
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Fetch should succeed: %s", string(output))
+		assert.Contains(t, string(output), "Staging Docs", "Should fetch the protected page")
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.NotEmpty(t, thirdParty, "The page should load the image from the other host")
+		for _, authorization := range thirdParty {
+			assert.Empty(t, authorization, "The other host should not receive the credentials")
+		}
+	})
+}
+
+func TestUserAgentSpec(t *testing.T) {
+	t.Run("user_agent_preset", func(t *testing.T) {
+		t.Log("SPEC: User Agent Presets")
+		t.Log("GIVEN a site that serves cleaner markup to Googlebot")
+		t.Log("WHEN the user fetches it with --user-agent googlebot")
+		t.Log("THEN the request should carry the Googlebot user agent")
+
+		binary := buildBinary(t)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.Contains(r.UserAgent(), "Googlebot") {
+				_, _ = w.Write([]byte(`<html><body><article><h1>Crawler Edition</h1><p>Static markup served to search engine crawlers.</p></article></body></html>`))
+				return
+			}
+			_, _ = w.Write([]byte(`<html><body><article><h1>Browser Edition</h1><p>Script-heavy markup served to regular browsers.</p></article></body></html>`))
+		}))
+		defer server.Close()
+
+		cmd := exec.Command(binary, "--user-agent", "googlebot", server.URL)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Fetch should succeed: %s", string(output))
+
+		assert.Contains(t, string(output), "Crawler Edition", "Should send the preset user agent")
+	})
+
+	t.Run("custom_user_agent", func(t *testing.T) {
+		t.Log("SPEC: Custom User Agent")
+		t.Log("GIVEN a --user-agent value that is not a preset")
+		t.Log("WHEN the user fetches a page")
+		t.Log("THEN the value should be sent as the user agent verbatim")
+
+		binary := buildBinary(t)
+
+		received := make(chan string, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case received <- r.UserAgent():
+			default:
+			}
+			_, _ = w.Write([]byte(`<html><body><article><h1>Agent Echo</h1><p>This page records the user agent of each request.</p></article></body></html>`))
+		}))
+		defer server.Close()
+
+		cmd := exec.Command(binary, "--user-agent", "MyReader/1.0", server.URL)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Fetch should succeed: %s", string(output))
+
+		assert.Equal(t, "MyReader/1.0", <-received, "Should send the custom user agent")
+	})
+}
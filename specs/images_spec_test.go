@@ -0,0 +1,68 @@
+package specs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImagesSpec(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`<html><body>
+<nav><img src="/logo.png" alt="Site logo"></nav>
+<article><h1>Gallery</h1>
+  <figure><img src="/photos/harbour.jpg" alt="Boats in the harbour" width="800" height="600"><figcaption>The harbour at dawn.</figcaption></figure>
+  <p>Some text about the pictures. <img src="data:image/gif;base64,R0lGOD" data-src="/photos/lazy.jpg" alt="Lazy loaded" title="A lazy image"></p>
+  <img src="https://cdn.example.com/pixel.gif" width="1" height="1" alt="">
+  <img srcset="/photos/small.jpg 480w, /photos/large.jpg 1200w" alt="Responsive">
+</article></body></html>`))
+	}))
+	defer server.Close()
+
+	binary := buildBinary(t)
+	run := func(t *testing.T, args ...string) []byte {
+		cmd := exec.Command(binary, append([]string{"images", "--socket", filepath.Join(t.TempDir(), "sz.sock")}, args...)...)
+		cmd.Env = append(os.Environ(), "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+		output, err := cmd.Output()
+		require.NoError(t, err)
+		return output
+	}
+
+	t.Run("images_text_listing", func(t *testing.T) {
+		t.Log("SPEC: Image Inventory")
+		t.Log("GIVEN an article with a captioned figure, a lazy image, a tracking pixel, a srcset image and a logo in its navigation")
+		t.Log("WHEN the user runs sz images URL")
+		t.Log("THEN each content image should be listed on its own tab-separated line with an absolute URL")
+
+		output := run(t, server.URL+"/gallery")
+		assert.Equal(t, server.URL+"/photos/harbour.jpg\t800x600\tBoats in the harbour\tThe harbour at dawn.\n"+
+			server.URL+"/photos/lazy.jpg\t\tLazy loaded\tA lazy image\n"+
+			server.URL+"/photos/large.jpg\t\tResponsive\t\n", string(output))
+	})
+
+	t.Run("images_json", func(t *testing.T) {
+		t.Log("SPEC: Image Inventory as JSON")
+		t.Log("GIVEN the same article")
+		t.Log("WHEN the user runs sz images --format json URL")
+		t.Log("THEN the images should be printed as a JSON list with dimensions and captions")
+
+		var images []map[string]any
+		output := run(t, "--format", "json", server.URL+"/gallery")
+		require.NoError(t, json.Unmarshal(output, &images), string(output))
+		require.Len(t, images, 3)
+		assert.Equal(t, map[string]any{
+			"url":     server.URL + "/photos/harbour.jpg",
+			"alt":     "Boats in the harbour",
+			"width":   float64(800),
+			"height":  float64(600),
+			"caption": "The harbour at dawn.",
+		}, images[0])
+	})
+}
@@ -0,0 +1,119 @@
+package specs
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobQueueSpec(t *testing.T) {
+	binary := buildBinary(t)
+	queueDir := t.TempDir()
+
+	// serve starts sz serve with the job API and returns its base URL and a
+	// function that stops it
+	serve := func(t *testing.T, args ...string) (string, func()) {
+		args = append([]string{"serve", "--grpc", "", "--http", "127.0.0.1:0", "--queue-dir", queueDir,
+			"--socket", filepath.Join(t.TempDir(), "sz.sock")}, args...)
+		cmd := exec.Command(binary, args...)
+		cmd.Env = append(os.Environ(), "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+		stderr, err := cmd.StderrPipe()
+		require.NoError(t, err)
+		require.NoError(t, cmd.Start())
+		banner, err := bufio.NewReader(stderr).ReadString('\n')
+		require.NoError(t, err)
+		go func() { _, _ = io.Copy(io.Discard, stderr) }()
+		return "http://" + strings.TrimSpace(strings.TrimPrefix(banner, "Serving HTTP on ")), func() {
+			_ = cmd.Process.Signal(os.Interrupt)
+			_ = cmd.Wait()
+		}
+	}
+	get := func(t *testing.T, url string) (int, map[string]any) {
+		resp, err := http.Get(url)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return resp.StatusCode, body
+	}
+	submit := func(t *testing.T, base, title string) string {
+		job := `{"html":"<html><body><article><h1>` + title + `</h1><p>A queued page to distill.</p></article></body></html>"}`
+		resp, err := http.Post(base+"/v1/jobs", "application/json", strings.NewReader(job))
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+		var state map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&state))
+		assert.Equal(t, "queued", state["status"])
+		return state["id"].(string)
+	}
+	await := func(t *testing.T, base, id string) map[string]any {
+		var state map[string]any
+		require.Eventually(t, func() bool {
+			_, state = get(t, base+"/v1/jobs/"+id)
+			return state["status"] == "done" || state["status"] == "failed"
+		}, 30*time.Second, 50*time.Millisecond)
+		return state
+	}
+
+	t.Run("submit_status_result", func(t *testing.T) {
+		t.Log("SPEC: Queued Jobs")
+		t.Log("GIVEN sz serve with the HTTP job API")
+		t.Log("WHEN a job without a callback is submitted")
+		t.Log("THEN its status should be pollable, and its result served once done")
+
+		base, stop := serve(t)
+		defer stop()
+
+		id := submit(t, base, "First")
+		state := await(t, base, id)
+		assert.Equal(t, "done", state["status"])
+		assert.NotEmpty(t, state["finished"])
+
+		code, result := get(t, base+"/v1/jobs/"+id+"/result")
+		require.Equal(t, http.StatusOK, code)
+		assert.Contains(t, result["markdown"], "# First")
+
+		code, _ = get(t, base+"/v1/jobs/unknown")
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("rate_limit_and_restart", func(t *testing.T) {
+		t.Log("SPEC: Persistent, Rate-Limited Queue")
+		t.Log("GIVEN sz serve limited to one job start per minute")
+		t.Log("WHEN two jobs are submitted and the server is restarted without the limit")
+		t.Log("THEN the second job should wait in the queue, and run after the restart")
+
+		base, stop := serve(t, "--rate", "1", "--workers", "1")
+		first := submit(t, base, "Burst one")
+		second := submit(t, base, "Burst two")
+		await(t, base, first)
+
+		_, state := get(t, base+"/v1/jobs/"+second)
+		assert.Equal(t, "queued", state["status"])
+		code, reply := get(t, base+"/v1/jobs/"+second+"/result")
+		assert.Equal(t, http.StatusConflict, code)
+		assert.Contains(t, reply["error"], "queued")
+		stop()
+
+		base, stop = serve(t)
+		defer stop()
+		state = await(t, base, second)
+		assert.Equal(t, "done", state["status"])
+		_, result := get(t, base+"/v1/jobs/"+second+"/result")
+		assert.Contains(t, result["markdown"], "# Burst two")
+
+		_, state = get(t, base+"/v1/jobs/"+first)
+		assert.Equal(t, "done", state["status"], "Finished jobs should survive the restart")
+	})
+}
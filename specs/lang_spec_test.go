@@ -0,0 +1,91 @@
+package specs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLanguagePreferenceSpec(t *testing.T) {
+	binary := buildBinary(t)
+
+	// The server picks the edition from Accept-Language, and shows the header
+	// it was sent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accepted := r.Header.Get("Accept-Language")
+		if r.URL.Path == "/feed.xml" {
+			w.Header().Set("Content-Type", "application/rss+xml")
+			_, _ = fmt.Fprintf(w, `<?xml version="1.0"?><rss version="2.0"><channel><title>Phares</title><item><title>Phare de Cordouan</title><link>http://%s/phare</link><description>Le phare de Cordouan veille sur l'estuaire de la Gironde. Requested with %s.</description></item></channel></rss>`, r.Host, accepted)
+			return
+		}
+		if strings.HasPrefix(accepted, "fr") {
+			_, _ = fmt.Fprintf(w, `<html lang="fr"><body><article><h1>Les phares</h1><p>Les phares guident les navires le long des côtes depuis l'Antiquité.</p><p>En-tête : %s</p></article></body></html>`, accepted)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `<html lang="en"><body><article><h1>Lighthouses</h1><p>Lighthouses have guided ships along the coast since antiquity.</p><p>Header: %s</p></article></body></html>`, accepted)
+	}))
+	defer server.Close()
+
+	t.Run("sets_accept_language", func(t *testing.T) {
+		t.Log("SPEC: Language Preference")
+		t.Log("GIVEN a site that serves content in the language the client prefers")
+		t.Log("WHEN the user runs sz --lang fr-FR on it")
+		t.Log("THEN the French edition should be fetched with Accept-Language fr-FR,fr;q=0.9")
+
+		output, err := exec.Command(binary, "--lang", "fr-FR", server.URL).Output()
+		require.NoError(t, err)
+		assert.Contains(t, string(output), "# Les phares")
+		assert.Contains(t, string(output), "fr-FR,fr;q=0.9")
+	})
+
+	t.Run("ranks_several_languages", func(t *testing.T) {
+		t.Log("SPEC: Language Preference")
+		t.Log("GIVEN the same site")
+		t.Log("WHEN the user runs sz fetch --lang fr-CA,fr-FR,en")
+		t.Log("THEN Accept-Language should list them in order with falling weights")
+
+		output, err := exec.Command(binary, "fetch", "--lang", "fr-CA,fr-FR,en", server.URL).Output()
+		require.NoError(t, err)
+		assert.Contains(t, string(output), "fr-CA,fr-FR;q=0.9,fr;q=0.8,en;q=0.7")
+	})
+
+	t.Run("header_wins", func(t *testing.T) {
+		t.Log("SPEC: Language Preference")
+		t.Log("GIVEN the same site")
+		t.Log("WHEN the user runs sz with both --lang fr-FR and an Accept-Language --header")
+		t.Log("THEN the explicit header should be sent")
+
+		output, err := exec.Command(binary, "--lang", "fr-FR", "--header", "Accept-Language: en-GB", server.URL).Output()
+		require.NoError(t, err)
+		assert.Contains(t, string(output), "# Lighthouses")
+		assert.Contains(t, string(output), "Header: en-GB")
+	})
+
+	t.Run("applies_to_feeds", func(t *testing.T) {
+		t.Log("SPEC: Language Preference")
+		t.Log("GIVEN a feed on the same site")
+		t.Log("WHEN the user runs sz feed --lang fr-FR on it")
+		t.Log("THEN the feed itself should be requested with the language preference")
+
+		output, err := exec.Command(binary, "feed", "--lang", "fr-FR", "--from-feed", server.URL+"/feed.xml").Output()
+		require.NoError(t, err)
+		assert.Contains(t, string(output), "Requested with fr-FR,fr;q=0.9.")
+	})
+
+	t.Run("rejects_invalid_tags", func(t *testing.T) {
+		t.Log("SPEC: Language Preference")
+		t.Log("GIVEN a --lang value that is not a list of language tags")
+		t.Log("WHEN the user runs sz with it")
+		t.Log("THEN sz should fail and explain the expected format")
+
+		output, err := exec.Command(binary, "--lang", "fr_FR!", server.URL).CombinedOutput()
+		require.Error(t, err)
+		assert.Contains(t, string(output), `invalid language "fr_FR!"`)
+	})
+}
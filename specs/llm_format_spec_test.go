@@ -0,0 +1,78 @@
+package specs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLLMFormatSpec(t *testing.T) {
+	page := `<html><body><article>
+<h1>Field Guide</h1>
+<p>The <strong>red fox</strong> is the <em>largest</em> of the true foxes, see <a href="https://en.wikipedia.org/wiki/Red_fox">the encyclopedia</a> for more.</p>
+<p><img src="https://example.com/fox.jpg" alt="A red fox in snow"></p>
+<h2>Habitat</h2>
+<p>Red foxes live   across the Northern Hemisphere, from the Arctic Circle to North Africa and Central America.</p>
+<ul><li>Forests</li><li>Grasslands</li></ul>
+</article></body></html>`
+	file := filepath.Join(t.TempDir(), "guide.html")
+	require.NoError(t, os.WriteFile(file, []byte(page), 0o644))
+	binary := buildBinary(t)
+
+	t.Run("compacts_for_model_context", func(t *testing.T) {
+		t.Log("SPEC: LLM Output Format")
+		t.Log("GIVEN an article with links, an image, emphasis and sections")
+		t.Log("WHEN the user runs sz --format llm")
+		t.Log("THEN the output should keep the text and headings but drop link targets, image sources, emphasis and blank lines")
+
+		markdown, err := exec.Command(binary, file).Output()
+		require.NoError(t, err)
+		output, err := exec.Command(binary, "--format", "llm", file).Output()
+		require.NoError(t, err)
+		text := string(output)
+
+		assert.Contains(t, text, "# Field Guide")
+		assert.Contains(t, text, "## Habitat")
+		assert.Contains(t, text, "red fox")
+		assert.Contains(t, text, "largest")
+		assert.Contains(t, text, "the encyclopedia")
+		assert.NotContains(t, text, "*", "Emphasis should be dropped")
+		assert.Contains(t, text, "- Forests")
+		assert.NotContains(t, text, "https://", "Link targets and image sources should be dropped")
+		assert.NotContains(t, text, "\n\n", "Blank lines should be collapsed")
+		assert.Less(t, len(text), len(markdown), "The compact output should be shorter than the markdown")
+	})
+
+	t.Run("flattens_headings", func(t *testing.T) {
+		t.Log("SPEC: LLM Output Format")
+		t.Log("GIVEN the same article")
+		t.Log("WHEN the user runs sz --format llm --flatten-headings")
+		t.Log("THEN each heading should become a label naming its section")
+
+		output, err := exec.Command(binary, "--format", "llm", "--flatten-headings", file).Output()
+		require.NoError(t, err)
+		lines := strings.Split(string(output), "\n")
+
+		assert.Equal(t, "[Field Guide]", lines[0])
+		assert.Contains(t, lines, "[Field Guide > Habitat]")
+		assert.NotContains(t, string(output), "#")
+	})
+
+	t.Run("rejects_unknown_format", func(t *testing.T) {
+		t.Log("SPEC: LLM Output Format")
+		t.Log("GIVEN a format sz does not know")
+		t.Log("WHEN the user runs sz --format pdf")
+		t.Log("THEN sz should exit with status 2")
+
+		output, err := exec.Command(binary, "--format", "pdf", file).CombinedOutput()
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 2, exitErr.ExitCode())
+		assert.Contains(t, string(output), "markdown or llm")
+	})
+}
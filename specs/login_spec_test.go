@@ -0,0 +1,107 @@
+package specs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginCommandSpec(t *testing.T) {
+	t.Run("requires_save_state", func(t *testing.T) {
+		t.Log("SPEC: Login Requires a State File")
+		t.Log("GIVEN the user runs sz login without --save-state")
+		t.Log("WHEN the command starts")
+		t.Log("THEN it should fail before opening the browser")
+
+		cmd := exec.Command("go", "run", "../cmd/essenz/main.go", "login", "https://example.com/login")
+		output, err := cmd.CombinedOutput()
+		require.Error(t, err, "Command should fail without --save-state")
+
+		assert.Contains(t, string(output), "--save-state is required", "Should explain the missing flag")
+	})
+
+	t.Run("session_round_trip", func(t *testing.T) {
+		t.Log("SPEC: Login Session Persistence")
+		t.Log("GIVEN a site that shows members-only content once a session cookie is set")
+		t.Log("WHEN the user logs in with sz login and fetches with --load-state")
+		t.Log("THEN the fetch should see the members-only content")
+
+		binary := buildBinary(t)
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/login", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`<html><body>
+<button id="sign-in" onclick="document.cookie = 'session=member; path=/'; document.getElementById('done').textContent = 'Signed in'">Sign in</button>
+<p id="done"></p>
+</body></html>`))
+		})
+		mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+			if cookie, err := r.Cookie("session"); err == nil && cookie.Value == "member" {
+				_, _ = w.Write([]byte(`<html><body><article><h1>Members Article</h1><p>Exclusive content for logged-in members only.</p></article></body></html>`))
+				return
+			}
+			_, _ = w.Write([]byte(`<html><body><article><h1>Paywall</h1><p>Please log in to continue reading this article.</p></article></body></html>`))
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		dir := t.TempDir()
+		actionsPath := filepath.Join(dir, "login.yaml")
+		require.NoError(t, os.WriteFile(actionsPath, []byte("steps:\n  - click: \"#sign-in\"\n  - waitFor: \"#done\"\n"), 0o644))
+		statePath := filepath.Join(dir, "state.json")
+
+		cmd := exec.Command(binary, "login", server.URL+"/login", "--actions", actionsPath, "--save-state", statePath)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Login should succeed: %s", string(output))
+
+		state, err := os.ReadFile(statePath)
+		require.NoError(t, err, "Should write the state file")
+		assert.Contains(t, string(state), "session", "State should include the session cookie")
+
+		cmd = exec.Command(binary, "--load-state", statePath, server.URL+"/article")
+		output, err = cmd.CombinedOutput()
+		require.NoError(t, err, "Fetch should succeed: %s", string(output))
+
+		assert.Contains(t, string(output), "Members Article", "Should fetch as the logged-in user")
+		assert.NotContains(t, string(output), "Please log in", "Should not see the paywall")
+	})
+
+	t.Run("state_holds_only_the_login_session", func(t *testing.T) {
+		t.Log("SPEC: Login Session Isolation")
+		t.Log("GIVEN the daemon fetched an unrelated page that set a cookie")
+		t.Log("WHEN the user logs in to a site with sz login --save-state")
+		t.Log("THEN the state file should hold the login's session cookie and not the earlier page's")
+
+		binary := buildBinary(t)
+
+		tracker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.SetCookie(w, &http.Cookie{Name: "unrelated_visit", Value: "tracked", Path: "/", MaxAge: 3600})
+			_, _ = w.Write([]byte(`<html><body><article><h1>Unrelated Page</h1><p>This page sets a cookie of its own.</p></article></body></html>`))
+		}))
+		defer tracker.Close()
+
+		login := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "member", Path: "/", MaxAge: 3600})
+			_, _ = w.Write([]byte(`<html><body><article><h1>Signed In</h1><p>Your session has started.</p></article></body></html>`))
+		}))
+		defer login.Close()
+
+		output, err := exec.Command(binary, tracker.URL).CombinedOutput()
+		require.NoError(t, err, "Fetch should succeed: %s", string(output))
+
+		statePath := filepath.Join(t.TempDir(), "state.json")
+		output, err = exec.Command(binary, "login", login.URL, "--save-state", statePath).CombinedOutput()
+		require.NoError(t, err, "Login should succeed: %s", string(output))
+
+		state, err := os.ReadFile(statePath)
+		require.NoError(t, err, "Should write the state file")
+		assert.Contains(t, string(state), `"session"`, "State should include the login's session cookie")
+		assert.NotContains(t, string(state), "unrelated_visit", "State should not include cookies from other requests")
+	})
+}
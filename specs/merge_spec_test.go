@@ -0,0 +1,80 @@
+package specs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeSpec(t *testing.T) {
+	binary := buildBinary(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/boats":
+			_, _ = w.Write([]byte(`<html><head><title>Canal Boats | Water Weekly</title>
+<meta name="author" content="Edith Barlow"><meta property="article:published_time" content="2024-05-02T08:00:00Z">
+<meta property="og:site_name" content="Water Weekly"></head><body><article>
+<h1>Canal Boats</h1><p>Narrowboats were built to fit the narrow locks of the English canals.</p>
+<h2>Locks</h2><p>Locks lift boats between the levels of water on a canal.</p>
+<h3>Gates</h3><p>Lock gates are heavy and are opened by pushing a balance beam.</p></article></body></html>`))
+		case "/mills":
+			_, _ = w.Write([]byte(`<html><head><title>Tidal Mills</title></head><body><article>
+<h2>Tidal Mills</h2><p>Tidal mills stored sea water in a pond at high tide to turn their wheels later.</p>
+<h3>Locks</h3><p>Some mills shared locks with the canal that ran beside them.</p></article></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("merges_into_digest", func(t *testing.T) {
+		t.Log("SPEC: Merging Pages")
+		t.Log("GIVEN two articles, one with an h1 title and one that starts at h2")
+		t.Log("WHEN the user runs sz merge --title \"Monday reading\" on both with -o digest.md")
+		t.Log("THEN digest.md should hold a contents list and a section per article with attribution and headings below it")
+
+		digest := filepath.Join(t.TempDir(), "digest.md")
+		cmd := exec.Command(binary, "merge", "--title", "Monday reading", "-o", digest, server.URL+"/boats", server.URL+"/mills")
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+
+		data, err := os.ReadFile(digest)
+		require.NoError(t, err)
+		text := string(data)
+
+		assert.True(t, strings.HasPrefix(text, "# Monday reading\n\n## Contents\n\n"), text)
+		assert.Contains(t, text, "- [Canal Boats](#canal-boats)\n  - [Locks](#locks)\n- [Tidal Mills](#tidal-mills)\n  - [Locks](#locks-1)\n")
+		assert.Contains(t, text, "## Canal Boats\n\n*Source: [Water Weekly]("+server.URL+"/boats), by Edith Barlow, 2024-05-02*")
+		assert.Contains(t, text, "### Locks\n\nLocks lift boats")
+		assert.Contains(t, text, "#### Gates")
+		assert.Contains(t, text, "## Tidal Mills\n\n*Source: ")
+		assert.Equal(t, 1, strings.Count(text, "Tidal Mills\n"), "The page's own title should not be repeated under its section")
+		assert.Less(t, strings.Index(text, "Narrowboats"), strings.Index(text, "Tidal mills stored"), "Sections should follow the order given")
+	})
+
+	t.Run("failed_page_left_out", func(t *testing.T) {
+		t.Log("SPEC: Merging Pages")
+		t.Log("GIVEN one article that exists and one URL that answers 404")
+		t.Log("WHEN the user merges them")
+		t.Log("THEN the digest should hold the article, and the command should report the failure and exit 1")
+
+		cmd := exec.Command(binary, "merge", server.URL+"/boats", server.URL+"/missing")
+		var stdout, stderr strings.Builder
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		require.Error(t, err)
+
+		assert.Equal(t, 1, err.(*exec.ExitError).ExitCode())
+		assert.Contains(t, stdout.String(), "## Canal Boats")
+		assert.Contains(t, stderr.String(), "/missing")
+	})
+}
@@ -0,0 +1,92 @@
+package specs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaRefreshSpec(t *testing.T) {
+	binary := buildBinary(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			_, _ = w.Write([]byte(`<html><head><title>Moved</title><meta http-equiv="Refresh" content="0; URL='/moved'"></head><body></body></html>`))
+		case "/moved":
+			_, _ = w.Write([]byte(`<html><head><meta http-equiv="refresh" content="1;url=/article"></head><body><p>Redirecting…</p></body></html>`))
+		case "/article":
+			_, _ = w.Write([]byte(`<html><head><meta http-equiv="refresh" content="600; url=/"><title>Canal Locks</title></head><body><article>
+<h1>Canal Locks</h1><p>Locks lift boats between the levels of water on a canal, one chamber at a time.</p></article></body></html>`))
+		case "/loop":
+			_, _ = w.Write([]byte(`<html><head><meta http-equiv="refresh" content="0; url=/loop-back"></head></html>`))
+		case "/loop-back":
+			_, _ = w.Write([]byte(`<html><head><meta http-equiv="refresh" content="0; url=/loop"></head></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("follows_refresh_chain", func(t *testing.T) {
+		t.Log("SPEC: Meta Refresh Redirects")
+		t.Log("GIVEN a page that redirects with a meta refresh to another that does the same")
+		t.Log("WHEN the user runs sz on the first page")
+		t.Log("THEN the article at the end should be distilled and its URL reported on stderr")
+
+		cmd := exec.Command(binary, server.URL+"/old")
+		var stdout, stderr strings.Builder
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		require.NoError(t, cmd.Run(), stderr.String())
+
+		assert.Contains(t, stdout.String(), "one chamber at a time")
+		assert.Contains(t, stderr.String(), "Followed meta refresh to "+server.URL+"/article")
+	})
+
+	t.Run("ignores_idle_timeout", func(t *testing.T) {
+		t.Log("SPEC: Meta Refresh Redirects")
+		t.Log("GIVEN an article whose meta refresh sends idle readers home after ten minutes")
+		t.Log("WHEN the user runs sz on it")
+		t.Log("THEN the article itself should be distilled")
+
+		cmd := exec.Command(binary, server.URL+"/article")
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		require.NoError(t, err)
+
+		assert.Contains(t, string(output), "one chamber at a time")
+		assert.NotContains(t, stderr.String(), "Followed meta refresh")
+	})
+
+	t.Run("stops_redirect_loop", func(t *testing.T) {
+		t.Log("SPEC: Meta Refresh Redirects")
+		t.Log("GIVEN two pages that refresh to each other")
+		t.Log("WHEN the user runs sz on one")
+		t.Log("THEN sz should give up after its hop limit and exit 1")
+
+		output, err := exec.Command(binary, server.URL+"/loop").CombinedOutput()
+		require.Error(t, err)
+
+		assert.Equal(t, 1, err.(*exec.ExitError).ExitCode())
+		assert.Contains(t, string(output), "meta refresh redirects")
+	})
+
+	t.Run("meta_reports_refresh", func(t *testing.T) {
+		t.Log("SPEC: Meta Refresh Redirects")
+		t.Log("GIVEN the page that redirects with a meta refresh")
+		t.Log("WHEN the user runs sz meta on it")
+		t.Log("THEN the resolved redirect target should be reported as refresh")
+
+		output, err := exec.Command(binary, "meta", server.URL+"/old").Output()
+		require.NoError(t, err)
+
+		assert.Contains(t, string(output), `"refresh": "`+server.URL+`/moved"`)
+	})
+}
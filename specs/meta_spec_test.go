@@ -0,0 +1,59 @@
+package specs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetaSpec(t *testing.T) {
+	t.Run("meta_reads_head_without_chrome", func(t *testing.T) {
+		t.Log("SPEC: Page Metadata")
+		t.Log("GIVEN an article whose head carries Open Graph tags, JSON-LD, a canonical link and a feed")
+		t.Log("WHEN the user runs sz meta URL")
+		t.Log("THEN the metadata should be printed as JSON, fetched over HTTP without starting the daemon")
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`<!DOCTYPE html><html lang="en-GB"><head>
+<title>Fallback Title | Example</title>
+<meta name="description" content="A short summary of the article.">
+<meta property="og:title" content="The Real Headline">
+<meta property="og:image" content="/images/lead.jpg">
+<meta property="og:site_name" content="Example News">
+<meta property="article:author" content="https://example.com/staff/ada">
+<link rel="canonical" href="/articles/real-headline">
+<link rel="alternate" type="application/rss+xml" title="Example News RSS" href="/feed.xml">
+<script type="application/ld+json">{"@context":"https://schema.org","@type":"NewsArticle","headline":"The Real Headline","author":{"@type":"Person","name":"Ada Lovelace"},"datePublished":"2024-06-04T10:00:00Z"}</script>
+</head><body><article><h1>The Real Headline</h1><p>Body text.</p></article></body></html>`))
+		}))
+		defer server.Close()
+
+		binary := buildBinary(t)
+		socket := filepath.Join(t.TempDir(), "sz.sock")
+		cmd := exec.Command(binary, "meta", "--socket", socket, server.URL+"/articles/real-headline?ref=home")
+		cmd.Env = append(os.Environ(), "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+		output, err := cmd.Output()
+		require.NoError(t, err)
+
+		var meta map[string]any
+		require.NoError(t, json.Unmarshal(output, &meta), string(output))
+		assert.Equal(t, "The Real Headline", meta["title"])
+		assert.Equal(t, "A short summary of the article.", meta["description"])
+		assert.Equal(t, "Ada Lovelace", meta["author"], "A profile URL should not be reported as the author")
+		assert.Equal(t, "2024-06-04T10:00:00Z", meta["published"])
+		assert.Equal(t, server.URL+"/articles/real-headline", meta["canonical"])
+		assert.Equal(t, "en-GB", meta["language"])
+		assert.Equal(t, server.URL+"/images/lead.jpg", meta["image"])
+		assert.Equal(t, "Example News", meta["site_name"])
+		assert.Equal(t, []any{map[string]any{"url": server.URL + "/feed.xml", "title": "Example News RSS", "type": "application/rss+xml"}}, meta["feeds"])
+
+		assert.NoFileExists(t, socket, "Metadata should not need the Chrome daemon")
+	})
+}
@@ -0,0 +1,75 @@
+package specs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObsidianExportSpec(t *testing.T) {
+	image := []byte("\x89PNG\r\n\x1a\nnot really a fox")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/img/fox":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(image)
+		default:
+			_, _ = w.Write([]byte(`<html><head><title>Foxes: A Study</title><meta name="author" content="Ada"></head><body><article>
+<h1>Foxes: A Study</h1>
+<p>The fox is a small animal that lives in many places around the world and eats many things.</p>
+<figure><img src="/img/fox" alt="A red fox"><figcaption>Seen at dusk</figcaption></figure>
+<p>Read <a href="/more">more about foxes</a> on the next page.</p>
+</article></body></html>`))
+		}
+	}))
+	defer server.Close()
+	binary := buildBinary(t)
+
+	t.Run("clips_page_into_vault", func(t *testing.T) {
+		t.Log("SPEC: Obsidian Export")
+		t.Log("GIVEN an article with an image and a relative link")
+		t.Log("WHEN the user runs sz --export obsidian --vault DIR URL twice")
+		t.Log("THEN each run should write a note with properties, the image saved as an attachment and absolute links, without overwriting the first note")
+
+		vault := t.TempDir()
+		for range 2 {
+			output, err := exec.Command(binary, "--export", "obsidian", "--vault", vault, server.URL+"/article").CombinedOutput()
+			require.NoError(t, err, string(output))
+		}
+
+		note, err := os.ReadFile(filepath.Join(vault, "Foxes A Study.md"))
+		require.NoError(t, err, "The note should be named after the title without characters Obsidian forbids")
+		text := string(note)
+		assert.Contains(t, text, "---\ntitle: 'Foxes: A Study'\nsource: "+server.URL+"/article\nauthor: Ada\n", "The note should start with its properties")
+		assert.Contains(t, text, "  - clippings")
+		assert.Contains(t, text, "The fox is a small animal")
+		assert.Contains(t, text, "("+server.URL+"/more)", "Relative links should point at the site")
+		assert.Contains(t, text, "![A red fox](attachments/fox.png)", "The image should be linked from the attachments folder")
+
+		saved, err := os.ReadFile(filepath.Join(vault, "attachments", "fox.png"))
+		require.NoError(t, err, "The image should be downloaded, named with the extension of its content type")
+		assert.Equal(t, image, saved)
+
+		assert.FileExists(t, filepath.Join(vault, "Foxes A Study 1.md"), "A second clip should not overwrite the first")
+		assert.FileExists(t, filepath.Join(vault, "attachments", "fox 1.png"))
+	})
+
+	t.Run("requires_vault", func(t *testing.T) {
+		t.Log("SPEC: Obsidian Export")
+		t.Log("GIVEN no vault folder")
+		t.Log("WHEN the user runs sz --export obsidian URL")
+		t.Log("THEN sz should exit with status 2")
+
+		output, err := exec.Command(binary, "--export", "obsidian", server.URL+"/article").CombinedOutput()
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 2, exitErr.ExitCode())
+		assert.Contains(t, string(output), "--vault")
+	})
+}
@@ -0,0 +1,105 @@
+package specs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFollowPaginationSpec(t *testing.T) {
+	binary := buildBinary(t)
+
+	parts := []string{
+		"Canals were dug across the country to carry coal from the mines to the mills.",
+		"Barges were pulled by horses walking along the towpath beside the water.",
+		"Railways replaced most of the canal trade within a few decades.",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/canals":
+			// Numbered pages linked only from a "Page N of 3" pager
+			page := 1
+			_, _ = fmt.Sscan(r.URL.Query().Get("page"), &page)
+			if page < 1 || page > len(parts) {
+				http.NotFound(w, r)
+				return
+			}
+			var pager strings.Builder
+			for n := 1; n <= len(parts); n++ {
+				if n == page {
+					_, _ = fmt.Fprintf(&pager, "<span>%d</span> ", n)
+				} else {
+					_, _ = fmt.Fprintf(&pager, `<a href="/canals?page=%d">%d</a> `, n, n)
+				}
+			}
+			_, _ = fmt.Fprintf(w, `<html><head><title>The Canal Age</title></head><body><article>
+<h1>The Canal Age</h1><p>By Edith Barlow, a historian of inland waterways.</p>
+<p>%s</p><p>Page %d of %d</p><div class="pages">%s</div>
+<p>Share this story with anyone who loves old waterways.</p></article></body></html>`, parts[page-1], page, len(parts), pager.String())
+		case "/essay":
+			_, _ = w.Write([]byte(`<html><head><link rel="next" href="/essay/2"></head><body><article>
+<h1>Tidal Mills</h1><p>Tidal mills stored sea water in a pond at high tide to turn their wheels.</p></article></body></html>`))
+		case "/essay/2":
+			_, _ = w.Write([]byte(`<html><head><link rel="prev" href="/essay"></head><body><article>
+<h1>Tidal Mills</h1><p>At low tide the miller opened the sluice and ground grain for hours.</p></article></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("stitches_numbered_pages", func(t *testing.T) {
+		t.Log("SPEC: Multi-page Articles")
+		t.Log("GIVEN an article split across three pages with a \"Page 1 of 3\" pager")
+		t.Log("WHEN the user runs sz --follow-pagination on the first page")
+		t.Log("THEN all three parts should be distilled in order, with the title, byline and footer once and no pager")
+
+		output, err := exec.Command(binary, "--follow-pagination", server.URL+"/canals").Output()
+		require.NoError(t, err)
+		text := string(output)
+
+		last := -1
+		for _, part := range parts {
+			index := strings.Index(text, part)
+			require.Greater(t, index, last, "Parts should appear in page order: %s", text)
+			last = index
+		}
+		assert.Equal(t, 1, strings.Count(text, "# The Canal Age"), text)
+		assert.Equal(t, 1, strings.Count(text, "Edith Barlow"), text)
+		assert.Equal(t, 1, strings.Count(text, "Share this story"), text)
+		assert.Greater(t, strings.Index(text, "Share this story"), last, "The footer should come after the last part")
+		assert.NotContains(t, text, "Page 1 of 3")
+	})
+
+	t.Run("follows_rel_next", func(t *testing.T) {
+		t.Log("SPEC: Multi-page Articles")
+		t.Log("GIVEN an essay whose first page links to the second with rel=next")
+		t.Log("WHEN the user runs sz --follow-pagination on it")
+		t.Log("THEN both pages should be distilled into one document")
+
+		output, err := exec.Command(binary, "--follow-pagination", server.URL+"/essay").Output()
+		require.NoError(t, err)
+
+		assert.Contains(t, string(output), "high tide")
+		assert.Contains(t, string(output), "low tide")
+		assert.Equal(t, 1, strings.Count(string(output), "# Tidal Mills"))
+	})
+
+	t.Run("off_by_default", func(t *testing.T) {
+		t.Log("SPEC: Multi-page Articles")
+		t.Log("GIVEN the same essay")
+		t.Log("WHEN the user runs sz without --follow-pagination")
+		t.Log("THEN only the first page should be distilled")
+
+		output, err := exec.Command(binary, server.URL+"/essay").Output()
+		require.NoError(t, err)
+
+		assert.NotContains(t, string(output), "low tide")
+	})
+}
@@ -0,0 +1,67 @@
+package specs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresetSpec(t *testing.T) {
+	binary := buildBinary(t)
+
+	configDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(configDir, "essenz"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "essenz", "config.yaml"), []byte(`presets:
+  tree:
+    text-node-tree: true
+    tree-format: json
+  typo:
+    no-such-flag: true
+`), 0o644))
+	env := append(os.Environ(), "XDG_CONFIG_HOME="+configDir)
+
+	page := filepath.Join(t.TempDir(), "page.html")
+	require.NoError(t, os.WriteFile(page, []byte(`<html><body><article><h1>Preset Page</h1><p>Body text.</p></article></body></html>`), 0o644))
+
+	t.Run("preset_applies_bundled_flags", func(t *testing.T) {
+		t.Log("SPEC: Named Presets")
+		t.Log("GIVEN a config preset that turns on the JSON text node tree")
+		t.Log("WHEN the user runs sz --preset tree, with and without --tree-format text")
+		t.Log("THEN the preset's flags should apply, and a flag given on the command line should win")
+
+		cmd := exec.Command(binary, "--preset", "tree", page)
+		cmd.Env = env
+		output, err := cmd.Output()
+		require.NoError(t, err)
+		assert.Contains(t, string(output), `"tag": "document"`, "Preset should select the JSON tree")
+
+		cmd = exec.Command(binary, "--preset", "tree", "--tree-format", "text", page)
+		cmd.Env = env
+		output, err = cmd.Output()
+		require.NoError(t, err)
+		assert.Contains(t, string(output), "[0] document", "Command line flags should override the preset")
+	})
+
+	t.Run("unknown_preset_settings_are_rejected", func(t *testing.T) {
+		t.Log("SPEC: Preset Validation")
+		t.Log("GIVEN a missing preset and a preset that sets a flag sz does not have")
+		t.Log("WHEN the user selects them")
+		t.Log("THEN sz should fail, naming the defined presets or the unknown flag")
+
+		cmd := exec.Command(binary, "--preset", "missing", page)
+		cmd.Env = env
+		output, err := cmd.CombinedOutput()
+		require.Error(t, err)
+		assert.Contains(t, string(output), "defined presets are tree, typo")
+
+		cmd = exec.Command(binary, "--preset", "typo", page)
+		cmd.Env = env
+		output, err = cmd.CombinedOutput()
+		require.Error(t, err)
+		assert.Contains(t, string(output), "--no-such-flag")
+	})
+}
@@ -0,0 +1,81 @@
+package specs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferPrintSpec(t *testing.T) {
+	binary := buildBinary(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/news/harbour" && r.URL.Query().Get("print") == "1":
+			_, _ = w.Write([]byte(`<html><body><article><h1>Harbour Reopens</h1><p>The harbour reopened on Monday after three months of dredging work.</p>
+<p>Fishing boats were the first to return to their moorings.</p></article></body></html>`))
+		case r.URL.Path == "/news/harbour":
+			_, _ = w.Write([]byte(`<html><head><title>Harbour Reopens</title></head><body>
+<nav><a href="/news">News</a> <a href="/blueprints/">Blueprints</a></nav>
+<article><h1>Harbour Reopens</h1><p>The harbour reopened on Monday. Continue reading on page two.</p>
+<a href="/news/harbour?print=1">Print this article</a></article></body></html>`))
+		case r.URL.Path == "/news/ferry":
+			_, _ = w.Write([]byte(`<html><head><link rel="alternate" media="print" href="/print/news/ferry"></head>
+<body><article><h1>Ferry Timetable</h1><p>The summer ferry timetable starts in June with extra sailings.</p></article></body></html>`))
+		case r.URL.Path == "/print/news/ferry":
+			_, _ = w.Write([]byte(`<html><body></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("fetches_print_version", func(t *testing.T) {
+		t.Log("SPEC: Print Versions")
+		t.Log("GIVEN an article with a ?print=1 link to its full text")
+		t.Log("WHEN the user runs sz --prefer-print on it")
+		t.Log("THEN the print version should be distilled")
+
+		output, err := exec.Command(binary, "--prefer-print", server.URL+"/news/harbour").Output()
+		require.NoError(t, err)
+
+		assert.Contains(t, string(output), "Fishing boats")
+		assert.NotContains(t, string(output), "page two")
+	})
+
+	t.Run("falls_back_to_page", func(t *testing.T) {
+		t.Log("SPEC: Print Versions")
+		t.Log("GIVEN a page whose print stylesheet link leads to an empty page")
+		t.Log("WHEN the user runs sz --prefer-print on it")
+		t.Log("THEN the page itself should be distilled")
+
+		output, err := exec.Command(binary, "--prefer-print", server.URL+"/news/ferry").Output()
+		require.NoError(t, err)
+
+		assert.Contains(t, string(output), "extra sailings")
+	})
+
+	t.Run("meta_reports_print", func(t *testing.T) {
+		t.Log("SPEC: Print Versions")
+		t.Log("GIVEN pages with a print link and a rel=alternate media=print link")
+		t.Log("WHEN the user runs sz meta on them")
+		t.Log("THEN their print versions should be reported, ignoring paths that merely contain \"print\"")
+
+		for page, want := range map[string]string{
+			"/news/harbour": server.URL + "/news/harbour?print=1",
+			"/news/ferry":   server.URL + "/print/news/ferry",
+		} {
+			output, err := exec.Command(binary, "meta", server.URL+page).Output()
+			require.NoError(t, err)
+
+			var meta map[string]any
+			require.NoError(t, json.Unmarshal(output, &meta), string(output))
+			assert.Equal(t, want, meta["print"], page)
+		}
+	})
+}
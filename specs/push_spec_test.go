@@ -0,0 +1,152 @@
+package specs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pushConfig writes a config.yaml with a push section and returns the
+// environment that uses it.
+func pushConfig(t *testing.T, push string) []string {
+	t.Helper()
+	configDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(configDir, "essenz"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "essenz", "config.yaml"), []byte("push:\n"+push), 0o644))
+	return append(os.Environ(), "XDG_CONFIG_HOME="+configDir)
+}
+
+func TestPushSpec(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Tide Pools</title><meta name="author" content="Ada"></head><body><article><h1>Tide Pools</h1>
+<p>Tide pools form where the sea leaves water behind in hollows of rock as it goes out.</p>
+<p>Anemones, crabs and snails live in them, surviving heat and salt between tides.</p></article></body></html>`))
+	}))
+	defer site.Close()
+	binary := buildBinary(t)
+
+	type request struct {
+		path   string
+		header http.Header
+		body   map[string]any
+		form   map[string][]string
+	}
+	fakeService := func(t *testing.T) (*httptest.Server, *[]request) {
+		var requests []request
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			req := request{path: r.URL.Path, header: r.Header}
+			if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+				_ = json.NewDecoder(r.Body).Decode(&req.body)
+			} else {
+				_ = r.ParseForm()
+				req.form = r.PostForm
+			}
+			requests = append(requests, req)
+			w.WriteHeader(http.StatusCreated)
+		}))
+		t.Cleanup(server.Close)
+		return server, &requests
+	}
+
+	t.Run("push_to_readwise_reader", func(t *testing.T) {
+		t.Log("SPEC: Push to Read-it-later Services")
+		t.Log("GIVEN a Readwise token in config.yaml")
+		t.Log("WHEN the user runs sz push readwise --tag ocean URL")
+		t.Log("THEN the distilled page should be saved to Reader as HTML with its title, author and tags")
+
+		service, requests := fakeService(t)
+		cmd := exec.Command(binary, "push", "readwise", "--tag", "ocean", site.URL+"/tides")
+		cmd.Env = pushConfig(t, fmt.Sprintf("  readwise:\n    token: secret\n    endpoint: %s/api\n", service.URL))
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+		assert.Contains(t, string(output), "Pushed page of "+site.URL+"/tides to readwise")
+
+		require.Len(t, *requests, 1)
+		req := (*requests)[0]
+		assert.Equal(t, "/api/v3/save/", req.path)
+		assert.Equal(t, "Token secret", req.header.Get("Authorization"))
+		assert.Equal(t, site.URL+"/tides", req.body["url"])
+		assert.Equal(t, "Tide Pools", req.body["title"])
+		assert.Equal(t, "Ada", req.body["author"])
+		assert.Equal(t, []any{"ocean"}, req.body["tags"])
+		assert.Contains(t, req.body["html"], "<h1>Tide Pools</h1>")
+		assert.Contains(t, req.body["html"], "<p>Anemones, crabs and snails")
+	})
+
+	t.Run("push_highlights_to_readwise", func(t *testing.T) {
+		t.Log("SPEC: Push to Read-it-later Services")
+		t.Log("GIVEN a Readwise token in config.yaml")
+		t.Log("WHEN the user runs sz push readwise --highlight TEXT URL")
+		t.Log("THEN the passage should be saved as a highlight of the page")
+
+		service, requests := fakeService(t)
+		cmd := exec.Command(binary, "push", "readwise", "--highlight", "Anemones live in them.", site.URL+"/tides")
+		cmd.Env = pushConfig(t, fmt.Sprintf("  readwise:\n    token: secret\n    endpoint: %s/api\n", service.URL))
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+
+		require.Len(t, *requests, 1)
+		req := (*requests)[0]
+		assert.Equal(t, "/api/v2/highlights/", req.path)
+		highlights, ok := req.body["highlights"].([]any)
+		require.True(t, ok)
+		require.Len(t, highlights, 1)
+		highlight := highlights[0].(map[string]any)
+		assert.Equal(t, "Anemones live in them.", highlight["text"])
+		assert.Equal(t, site.URL+"/tides", highlight["source_url"])
+		assert.Equal(t, "Tide Pools", highlight["title"])
+	})
+
+	t.Run("push_to_pocket_and_instapaper", func(t *testing.T) {
+		t.Log("SPEC: Push to Read-it-later Services")
+		t.Log("GIVEN Pocket and Instapaper accounts in config.yaml")
+		t.Log("WHEN the user pushes a page to each")
+		t.Log("THEN Pocket should get the URL and title with its keys, and Instapaper the URL and title with basic auth")
+
+		service, requests := fakeService(t)
+		env := pushConfig(t, fmt.Sprintf("  pocket:\n    consumer_key: ck\n    access_token: at\n    endpoint: %[1]s/v3\n  instapaper:\n    username: ada\n    password: pw\n    endpoint: %[1]s/api\n", service.URL))
+		for _, name := range []string{"pocket", "instapaper"} {
+			cmd := exec.Command(binary, "push", name, site.URL+"/tides")
+			cmd.Env = env
+			output, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(output))
+		}
+
+		require.Len(t, *requests, 2)
+		pocket := (*requests)[0]
+		assert.Equal(t, "/v3/add", pocket.path)
+		assert.Equal(t, "ck", pocket.body["consumer_key"])
+		assert.Equal(t, "at", pocket.body["access_token"])
+		assert.Equal(t, site.URL+"/tides", pocket.body["url"])
+
+		instapaper := (*requests)[1]
+		assert.Equal(t, "/api/add", instapaper.path)
+		assert.Equal(t, []string{site.URL + "/tides"}, instapaper.form["url"])
+		assert.Equal(t, []string{"Tide Pools"}, instapaper.form["title"])
+		assert.True(t, strings.HasPrefix(instapaper.header.Get("Authorization"), "Basic "))
+	})
+
+	t.Run("push_without_account", func(t *testing.T) {
+		t.Log("SPEC: Push to Read-it-later Services")
+		t.Log("GIVEN no Pocket account in config.yaml")
+		t.Log("WHEN the user runs sz push pocket URL")
+		t.Log("THEN sz should exit with status 2 naming the settings to add")
+
+		cmd := exec.Command(binary, "push", "pocket", site.URL+"/tides")
+		cmd.Env = pushConfig(t, "  readwise:\n    token: secret\n")
+		output, err := cmd.CombinedOutput()
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 2, exitErr.ExitCode())
+		assert.Contains(t, string(output), "push.pocket.consumer_key")
+	})
+}
@@ -218,3 +218,56 @@ func TestReaderViewCompatibilitySpec(t *testing.T) {
 	containsHTML := strings.Contains(outputStr, "<") && strings.Contains(outputStr, ">")
 	assert.True(t, containsHTML, "Should preserve HTML structure without reader view")
 }
+
+func TestReaderViewWithCommentsSpec(t *testing.T) {
+	t.Log("SPEC: Opt-in Comments Extraction")
+	t.Log("GIVEN an article followed by a comment section")
+	t.Log("WHEN the user runs `sz --with-comments /path/to/file.html`")
+	t.Log("THEN comments should appear in a separate Comments section after the article")
+
+	htmlContent := `<!DOCTYPE html>
+<html>
+<body>
+	<article>
+		<h1>Discussion Article</h1>
+		<p>The article body is long enough to be recognised as the main content of the page.</p>
+	</article>
+	<section id="comments">
+		<ol class="comment-list">
+			<li class="comment">
+				<span class="comment-author">Alice</span>
+				<time datetime="2024-03-01T10:00">March 1</time>
+				<div class="comment-content"><p>Insightful write-up, thanks!</p></div>
+			</li>
+			<li class="comment">
+				<span class="comment-author">Bob</span>
+				<time datetime="2024-03-02T08:30">March 2</time>
+				<div class="comment-content"><p>I disagree with the second point.</p></div>
+			</li>
+		</ol>
+	</section>
+</body>
+</html>`
+
+	testFile := filepath.Join(t.TempDir(), "comments.html")
+	require.NoError(t, os.WriteFile(testFile, []byte(htmlContent), 0644))
+
+	cmd := exec.Command("go", "run", "../cmd/essenz/main.go", "--with-comments", testFile)
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "Command should succeed")
+
+	outputStr := string(output)
+	assert.Contains(t, outputStr, "# Discussion Article", "Should keep the article")
+	assert.Contains(t, outputStr, "## Comments", "Should add a Comments section")
+	assert.Contains(t, outputStr, "**Alice** — 2024-03-01T10:00", "Should include author and timestamp")
+	assert.Contains(t, outputStr, "Insightful write-up", "Should include comment body")
+	assert.Contains(t, outputStr, "**Bob** — 2024-03-02T08:30", "Should include every comment")
+	assert.Less(t, strings.Index(outputStr, "# Discussion Article"), strings.Index(outputStr, "## Comments"),
+		"Comments should follow the article")
+
+	// Comments stay stripped by default
+	cmd = exec.Command("go", "run", "../cmd/essenz/main.go", testFile)
+	output, err = cmd.CombinedOutput()
+	require.NoError(t, err, "Command should succeed")
+	assert.NotContains(t, string(output), "Insightful write-up", "Should strip comments without the flag")
+}
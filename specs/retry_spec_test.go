@@ -0,0 +1,150 @@
+package specs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetrySpec(t *testing.T) {
+	binary := buildBinary(t)
+
+	const article = `<html><body><article><h1>Canal Locks</h1>
+<p>Locks lift boats between the levels of water on a canal, one chamber at a time.</p></article></body></html>`
+
+	var mu sync.Mutex
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		count := requests[r.URL.Path]
+		mu.Unlock()
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/flaky") && count <= 2:
+			// The first two requests to each flaky page fail
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+		case r.URL.Path == "/reset" && count == 1:
+			// Drop the connection without answering
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				_ = conn.Close()
+			}
+		case r.URL.Path == "/missing":
+			http.NotFound(w, r)
+		default:
+			_, _ = w.Write([]byte(article))
+		}
+	}))
+	defer server.Close()
+	requestsTo := func(path string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests[path]
+	}
+
+	t.Run("retries_server_errors", func(t *testing.T) {
+		t.Log("SPEC: Retries")
+		t.Log("GIVEN a page that answers 503 twice before it succeeds")
+		t.Log("WHEN the user runs sz --retries 2 --retry-delay 10ms on it")
+		t.Log("THEN the page should be distilled after two retries")
+
+		output, err := exec.Command(binary, "--retries", "2", "--retry-delay", "10ms", server.URL+"/flaky-one").Output()
+		require.NoError(t, err)
+
+		assert.Contains(t, string(output), "one chamber at a time")
+		assert.Equal(t, 3, requestsTo("/flaky-one"))
+	})
+
+	t.Run("no_retries_by_default", func(t *testing.T) {
+		t.Log("SPEC: Retries")
+		t.Log("GIVEN the same kind of flaky page")
+		t.Log("WHEN the user runs sz on it without --retries")
+		t.Log("THEN the fetch should fail with the network exit code after one request")
+
+		err := exec.Command(binary, server.URL+"/flaky-two").Run()
+		require.Error(t, err)
+
+		assert.Equal(t, 3, err.(*exec.ExitError).ExitCode())
+		assert.Equal(t, 1, requestsTo("/flaky-two"))
+	})
+
+	t.Run("retries_dropped_connection", func(t *testing.T) {
+		t.Log("SPEC: Retries")
+		t.Log("GIVEN a server that drops the first connection without answering")
+		t.Log("WHEN the user runs sz fetch --retries 1 on it")
+		t.Log("THEN the second attempt should succeed")
+
+		output, err := exec.Command(binary, "fetch", "--retries", "1", "--retry-delay", "10ms", server.URL+"/reset").Output()
+		require.NoError(t, err)
+
+		assert.Contains(t, string(output), "Canal Locks")
+	})
+
+	t.Run("client_errors_not_retried", func(t *testing.T) {
+		t.Log("SPEC: Retries")
+		t.Log("GIVEN a page that answers 404")
+		t.Log("WHEN the user runs sz --retries 3 on it")
+		t.Log("THEN it should be requested only once")
+
+		err := exec.Command(binary, "--retries", "3", "--retry-delay", "10ms", server.URL+"/missing").Run()
+		require.Error(t, err)
+
+		assert.Equal(t, 1, requestsTo("/missing"))
+	})
+}
+
+func TestRetryChromeSpec(t *testing.T) {
+	t.Log("SPEC: Retries")
+	t.Log("GIVEN a page rendered by a script that answers 503 twice before it succeeds")
+	t.Log("WHEN the user runs sz --retries 2 --retry-delay 10ms on it and Chrome renders it")
+	t.Log("THEN Chrome should retry the server errors and the rendered content arrive")
+
+	binary := buildBinary(t)
+
+	var mu sync.Mutex
+	var agents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/flaky" {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		agents = append(agents, r.UserAgent())
+		count := len(agents)
+		mu.Unlock()
+
+		if count <= 2 {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`<html><body><article id="story"></article><script>
+document.getElementById('story').innerHTML = '<h1>Swing Bridges</h1><p>A swing bridge turns on a central pier to let tall boats through the channel.</p>';
+</script></body></html>`))
+	}))
+	defer server.Close()
+
+	cmd := exec.Command(binary, "--socket", filepath.Join(t.TempDir(), "sz.sock"), "--retries", "2", "--retry-delay", "10ms", server.URL+"/flaky")
+	cmd.Env = append(os.Environ(), "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	require.NoError(t, err, stderr.String())
+
+	assert.Contains(t, string(output), "let tall boats through", "The script-rendered content should arrive")
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, agents, 3, "Two failures and the success")
+	for _, agent := range agents {
+		assert.Contains(t, agent, "Chrome", "Chrome should retry the page itself")
+	}
+}
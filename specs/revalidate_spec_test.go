@@ -0,0 +1,179 @@
+package specs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// revalidatedPage serves a status page with an ETag and Last-Modified date,
+// answering conditional requests with 304 Not Modified. It counts GET and
+// HEAD requests, and those HEADs that were conditional; version says which
+// version of the page to serve.
+type revalidatedPage struct {
+	gets, heads, conditional atomic.Int32
+	version                  atomic.Int32
+}
+
+func (p *revalidatedPage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		p.gets.Add(1)
+	case http.MethodHead:
+		p.heads.Add(1)
+		if r.Header.Get("If-None-Match") != "" {
+			p.conditional.Add(1)
+		}
+	}
+	version := p.version.Load()
+	status := "All systems operational"
+	if version > 0 {
+		status = "Degraded performance on the API"
+	}
+	w.Header().Set("ETag", fmt.Sprintf(`"status-v%d"`, version))
+	page := fmt.Sprintf(`<html><body><article><h1>Status</h1><p>%s.</p><p>This page reports the current state of every service we run.</p></article></body></html>`, status)
+	modified := time.Date(2026, 1, 1, 0, 0, int(version), 0, time.UTC)
+	http.ServeContent(w, r, "status.html", modified, strings.NewReader(page))
+}
+
+func TestRevalidationSpec(t *testing.T) {
+	binary := buildBinary(t)
+
+	t.Run("watch_skips_unmodified_pages", func(t *testing.T) {
+		t.Log("SPEC: Conditional Revalidation")
+		t.Log("GIVEN a page served with an ETag that changes on the fourth check")
+		t.Log("WHEN the user runs sz watch --count 4 against it")
+		t.Log("THEN the server should be asked whether the page changed before each fetch after the first, and the page only fetched again when it did")
+
+		page := &revalidatedPage{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead && page.heads.Load() == 2 {
+				page.version.Store(1)
+			}
+			page.ServeHTTP(w, r)
+		}))
+		defer server.Close()
+
+		cmd := exec.Command(binary, "watch", "--socket", filepath.Join(t.TempDir(), "sz.sock"), "--interval", "50ms", "--count", "4", server.URL)
+		cmd.Env = append(os.Environ(), "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+		var stdout, stderr strings.Builder
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		require.NoError(t, cmd.Run(), stderr.String())
+
+		assert.Equal(t, int32(3), page.heads.Load(), "Each check after the first fetch should ask first")
+		assert.Equal(t, int32(3), page.conditional.Load(), "Each check should send If-None-Match")
+		assert.Equal(t, int32(2), page.gets.Load(), "The page should only be fetched again once it changed")
+		assert.Equal(t, 1, strings.Count(stdout.String(), "@@ "), "The change should be reported once: %s", stdout.String())
+		assert.Contains(t, stdout.String(), "+Degraded performance on the API.")
+	})
+
+	t.Run("watch_no_revalidate", func(t *testing.T) {
+		t.Log("SPEC: Conditional Revalidation")
+		t.Log("GIVEN a page served with an ETag that never changes")
+		t.Log("WHEN the user runs sz watch --no-revalidate --count 3 against it")
+		t.Log("THEN the page should be fetched every time without asking first")
+
+		page := &revalidatedPage{}
+		server := httptest.NewServer(page)
+		defer server.Close()
+
+		cmd := exec.Command(binary, "watch", "--no-revalidate", "--socket", filepath.Join(t.TempDir(), "sz.sock"), "--interval", "50ms", "--count", "3", server.URL)
+		cmd.Env = append(os.Environ(), "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+
+		assert.Equal(t, int32(0), page.heads.Load())
+		assert.Equal(t, int32(3), page.gets.Load())
+	})
+
+	t.Run("schedules_skip_unmodified_pages", func(t *testing.T) {
+		t.Log("SPEC: Conditional Revalidation")
+		t.Log("GIVEN a schedule in config.yaml for a page served with an ETag that never changes")
+		t.Log("WHEN the daemon runs it several times")
+		t.Log("THEN the page should be fetched once and only revalidated after that")
+
+		page := &revalidatedPage{}
+		server := httptest.NewServer(page)
+		defer server.Close()
+
+		configDir := t.TempDir()
+		output := filepath.Join(t.TempDir(), "status.md")
+		config := fmt.Sprintf("schedules:\n  - name: status\n    url: %s\n    interval: 100ms\n    output: %s\n", server.URL, output)
+		require.NoError(t, os.MkdirAll(filepath.Join(configDir, "essenz"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, "essenz", "config.yaml"), []byte(config), 0o644))
+
+		socket := filepath.Join(t.TempDir(), "sz.sock")
+		env := append(os.Environ(), "XDG_CONFIG_HOME="+configDir, "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+		daemon := exec.Command(binary, "--socket", socket, "daemon", "start")
+		daemon.Env = env
+		require.NoError(t, daemon.Start())
+		defer func() {
+			stop := exec.Command(binary, "--socket", socket, "daemon", "stop")
+			stop.Env = env
+			_ = stop.Run()
+			_ = daemon.Process.Kill()
+			_ = daemon.Wait()
+		}()
+
+		require.Eventually(t, func() bool {
+			return page.conditional.Load() >= 3
+		}, 60*time.Second, 100*time.Millisecond, "The daemon should keep asking whether the page changed")
+
+		assert.Equal(t, int32(1), page.gets.Load(), "The unchanged page should only be fetched once")
+		saved, err := os.ReadFile(output)
+		require.NoError(t, err)
+		assert.Contains(t, string(saved), "All systems operational.")
+	})
+
+	t.Run("schedules_revalidate_with_the_daemon_settings", func(t *testing.T) {
+		t.Log("SPEC: Conditional Revalidation")
+		t.Log("GIVEN a schedule in config.yaml and a daemon started with --lang fr-FR")
+		t.Log("WHEN the daemon asks whether the page changed")
+		t.Log("THEN its requests should carry the daemon's Accept-Language")
+
+		page := &revalidatedPage{}
+		var language atomic.Value
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				language.Store(r.Header.Get("Accept-Language"))
+			}
+			page.ServeHTTP(w, r)
+		}))
+		defer server.Close()
+
+		configDir := t.TempDir()
+		output := filepath.Join(t.TempDir(), "status.md")
+		config := fmt.Sprintf("schedules:\n  - name: status\n    url: %s\n    interval: 100ms\n    output: %s\n", server.URL, output)
+		require.NoError(t, os.MkdirAll(filepath.Join(configDir, "essenz"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, "essenz", "config.yaml"), []byte(config), 0o644))
+
+		socket := filepath.Join(t.TempDir(), "sz.sock")
+		env := append(os.Environ(), "XDG_CONFIG_HOME="+configDir, "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+		daemon := exec.Command(binary, "--socket", socket, "daemon", "start", "--lang", "fr-FR")
+		daemon.Env = env
+		require.NoError(t, daemon.Start())
+		defer func() {
+			stop := exec.Command(binary, "--socket", socket, "daemon", "stop")
+			stop.Env = env
+			_ = stop.Run()
+			_ = daemon.Process.Kill()
+			_ = daemon.Wait()
+		}()
+
+		require.Eventually(t, func() bool {
+			return page.conditional.Load() >= 1
+		}, 60*time.Second, 100*time.Millisecond, "The daemon should ask whether the page changed")
+		assert.Equal(t, "fr-FR,fr;q=0.9", language.Load())
+	})
+}
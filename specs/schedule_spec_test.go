@@ -0,0 +1,104 @@
+package specs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleSpec(t *testing.T) {
+	t.Run("daemon_writes_scheduled_pages", func(t *testing.T) {
+		t.Log("SPEC: Scheduled Fetches")
+		t.Log("GIVEN a schedule in config.yaml for a page whose text changes after the first fetch")
+		t.Log("WHEN the daemon runs")
+		t.Log("THEN the distilled page should be written to the output file, the change appended to the diff file and the change hook run")
+
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			status := "All systems operational"
+			if requests.Add(1) > 1 {
+				status = "Degraded performance on the API"
+			}
+			_, _ = fmt.Fprintf(w, `<html><body><article><h1>Status</h1><p>%s.</p><p>This page reports the current state of every service we run.</p></article></body></html>`, status)
+		}))
+		defer server.Close()
+
+		configDir := t.TempDir()
+		notes := t.TempDir()
+		output := filepath.Join(notes, "status.md")
+		changes := filepath.Join(notes, "status.diff")
+		hooked := filepath.Join(notes, "hook.txt")
+		config := fmt.Sprintf("schedules:\n  - name: status\n    url: %s\n    interval: 200ms\n    output: %s\n    diff: %s\n    on_change: echo \"$SZ_OUTPUT\" > %s\n", server.URL, output, changes, hooked)
+		require.NoError(t, os.MkdirAll(filepath.Join(configDir, "essenz"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, "essenz", "config.yaml"), []byte(config), 0o644))
+
+		binary := buildBinary(t)
+		socket := filepath.Join(t.TempDir(), "sz.sock")
+		env := append(os.Environ(), "XDG_CONFIG_HOME="+configDir, "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+		daemon := exec.Command(binary, "--socket", socket, "daemon", "start")
+		daemon.Env = env
+		require.NoError(t, daemon.Start())
+		defer func() {
+			stop := exec.Command(binary, "--socket", socket, "daemon", "stop")
+			stop.Env = env
+			_ = stop.Run()
+			_ = daemon.Process.Kill()
+			_ = daemon.Wait()
+		}()
+
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(changes)
+			return err == nil
+		}, 60*time.Second, 100*time.Millisecond, "The change should be written to the diff file")
+
+		saved, err := os.ReadFile(output)
+		require.NoError(t, err)
+		assert.Contains(t, string(saved), "Degraded performance on the API.", "The output should hold the latest version")
+
+		diff, err := os.ReadFile(changes)
+		require.NoError(t, err)
+		assert.Contains(t, string(diff), "-All systems operational.")
+		assert.Contains(t, string(diff), "+Degraded performance on the API.")
+		assert.Equal(t, 1, strings.Count(string(diff), "@@ "), "Unchanged fetches should not add to the diff: %s", diff)
+
+		require.Eventually(t, func() bool {
+			hook, err := os.ReadFile(hooked)
+			return err == nil && strings.TrimSpace(string(hook)) == output
+		}, 10*time.Second, 100*time.Millisecond, "The change hook should run with the output file in SZ_OUTPUT")
+
+		status := exec.Command(binary, "--socket", socket, "daemon", "status")
+		status.Env = env
+		report, err := status.CombinedOutput()
+		require.NoError(t, err, string(report))
+		assert.Contains(t, string(report), "Schedules:    1")
+	})
+
+	t.Run("invalid_schedule_rejected", func(t *testing.T) {
+		t.Log("SPEC: Invalid Schedule")
+		t.Log("GIVEN a schedule without an output file")
+		t.Log("WHEN the daemon starts")
+		t.Log("THEN it should refuse to start and name the schedule")
+
+		configDir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(configDir, "essenz"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, "essenz", "config.yaml"),
+			[]byte("schedules:\n  - name: news\n    url: https://example.com/news\n    interval: 1h\n"), 0o644))
+
+		binary := buildBinary(t)
+		cmd := exec.Command(binary, "--socket", filepath.Join(t.TempDir(), "sz.sock"), "daemon", "start")
+		cmd.Env = append(os.Environ(), "XDG_CONFIG_HOME="+configDir, "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+		output, err := cmd.CombinedOutput()
+		require.Error(t, err, "The daemon should not start")
+		assert.Contains(t, string(output), "schedule news: output is required")
+	})
+}
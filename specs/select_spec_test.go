@@ -0,0 +1,78 @@
+package specs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectSpec(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`<html><body>
+<article><h1>Releases</h1><p>Our release notes are below, in the sidebar.</p></article>
+<aside class="changelog">
+  <h2>v1.2.0</h2><ul><li>Added select</li></ul>
+  <div class="changelog"><p>Nested entry</p></div>
+</aside>
+<aside class="changelog"><h2>v1.1.0</h2><p>Fixed a crash.</p></aside>
+</body></html>`))
+	}))
+	defer server.Close()
+
+	binary := buildBinary(t)
+	run := func(t *testing.T, args ...string) (string, int) {
+		cmd := exec.Command(binary, append([]string{"select", "--socket", filepath.Join(t.TempDir(), "sz.sock")}, args...)...)
+		cmd.Env = append(os.Environ(), "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+		output, err := cmd.Output()
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return string(output), exitErr.ExitCode()
+		}
+		require.NoError(t, err)
+		return string(output), 0
+	}
+
+	t.Run("select_renders_matches", func(t *testing.T) {
+		t.Log("SPEC: Selector Rendering")
+		t.Log("GIVEN a page whose changelog sits in asides the reader view would drop")
+		t.Log("WHEN the user runs sz select --selector .changelog URL")
+		t.Log("THEN every matching element should be rendered once, in document order, and nothing else")
+
+		output, code := run(t, "--selector", ".changelog", server.URL)
+		require.Equal(t, 0, code)
+		assert.Contains(t, output, "v1.2.0")
+		assert.Contains(t, output, "Added select")
+		assert.Contains(t, output, "v1.1.0")
+		assert.Less(t, strings.Index(output, "v1.2.0"), strings.Index(output, "v1.1.0"))
+		assert.Equal(t, 1, strings.Count(output, "Nested entry"))
+		assert.NotContains(t, output, "Releases")
+	})
+
+	t.Run("select_first", func(t *testing.T) {
+		t.Log("SPEC: First Match Only")
+		t.Log("GIVEN the same page")
+		t.Log("WHEN the user runs sz select --first --selector .changelog URL")
+		t.Log("THEN only the first matching element should be rendered")
+
+		output, code := run(t, "--first", "--selector", ".changelog", server.URL)
+		require.Equal(t, 0, code)
+		assert.Contains(t, output, "v1.2.0")
+		assert.NotContains(t, output, "v1.1.0")
+	})
+
+	t.Run("select_no_match", func(t *testing.T) {
+		t.Log("SPEC: No Matching Elements")
+		t.Log("GIVEN the same page")
+		t.Log("WHEN the selector matches nothing")
+		t.Log("THEN sz select should exit with the empty-content code")
+
+		_, code := run(t, "--selector", "#missing", server.URL)
+		assert.Equal(t, 5, code)
+	})
+}
@@ -0,0 +1,124 @@
+package specs
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	essenzv1 "github.com/jewell-lgtm/essenz/api/essenz/v1"
+)
+
+func TestServeSpec(t *testing.T) {
+	binary := buildBinary(t)
+	cmd := exec.Command(binary, "serve", "--grpc", "127.0.0.1:0", "--socket", filepath.Join(t.TempDir(), "sz.sock"))
+	cmd.Env = append(os.Environ(), "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+	stderr, err := cmd.StderrPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+	defer func() {
+		_ = cmd.Process.Signal(os.Interrupt)
+		_ = cmd.Wait()
+	}()
+
+	banner, err := bufio.NewReader(stderr).ReadString('\n')
+	require.NoError(t, err)
+	address := strings.TrimSpace(strings.TrimPrefix(banner, "Serving gRPC on "))
+	go func() { _, _ = io.Copy(io.Discard, stderr) }()
+
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	client := essenzv1.NewExtractServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	t.Run("extract_streams_metadata_then_markdown", func(t *testing.T) {
+		t.Log("SPEC: Streamed Extraction")
+		t.Log("GIVEN a running sz serve and an article larger than one message chunk")
+		t.Log("WHEN a client calls Extract with the article's HTML")
+		t.Log("THEN the metadata should arrive first, followed by the markdown in several chunks")
+
+		paragraph := "<p>" + strings.Repeat("All work and no play makes a long article. ", 40) + "</p>"
+		html := `<html><head><title>Long Read</title></head><body><article><h1>Long Read</h1>` +
+			strings.Repeat(paragraph, 100) + `</article></body></html>`
+
+		stream, err := client.Extract(ctx, &essenzv1.ExtractRequest{Html: html})
+		require.NoError(t, err)
+
+		first, err := stream.Recv()
+		require.NoError(t, err)
+		require.NotNil(t, first.GetMetadata())
+		assert.Equal(t, "Long Read", first.GetMetadata().GetTitle())
+
+		var markdown strings.Builder
+		chunks := 0
+		for {
+			message, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			markdown.WriteString(message.GetChunk())
+			chunks++
+		}
+		assert.Greater(t, chunks, 1)
+		assert.True(t, strings.HasPrefix(markdown.String(), "# Long Read"))
+		assert.Equal(t, 100*40, strings.Count(markdown.String(), "All work and no play"))
+	})
+
+	t.Run("fetch_streams_html", func(t *testing.T) {
+		t.Log("SPEC: Streamed Fetch")
+		t.Log("GIVEN a page served over HTTP")
+		t.Log("WHEN a client calls Fetch with its URL and a request header")
+		t.Log("THEN the page's HTML should be streamed back, fetched with that header")
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`<html><body><p>Token ` + r.Header.Get("X-Token") + `</p></body></html>`))
+		}))
+		defer server.Close()
+
+		stream, err := client.Fetch(ctx, &essenzv1.FetchRequest{
+			Url:     server.URL,
+			Options: &essenzv1.FetchOptions{Headers: map[string]string{"X-Token": "secret"}},
+		})
+		require.NoError(t, err)
+		var content strings.Builder
+		for {
+			message, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			content.WriteString(message.GetChunk())
+		}
+		assert.Contains(t, content.String(), "Token secret")
+	})
+
+	t.Run("fetch_refuses_files", func(t *testing.T) {
+		t.Log("SPEC: Network Targets Only")
+		t.Log("GIVEN a running sz serve")
+		t.Log("WHEN a client asks for a file:// URL")
+		t.Log("THEN the call should fail with InvalidArgument rather than read the server's files")
+
+		stream, err := client.Fetch(ctx, &essenzv1.FetchRequest{Url: "file:///etc/passwd"})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,87 @@
+package specs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSitemapSpec(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap_index.xml":
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>` + server.URL + `/sitemap-posts.xml.gz</loc></sitemap>
+  <sitemap><loc>` + server.URL + `/sitemap-pages.xml</loc></sitemap>
+</sitemapindex>`))
+		case "/sitemap-posts.xml.gz":
+			var buf bytes.Buffer
+			zw := gzip.NewWriter(&buf)
+			_, _ = zw.Write([]byte(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>` + server.URL + `/blog/new-post</loc><lastmod>2024-06-01</lastmod></url>
+  <url><loc>` + server.URL + `/blog/old-post</loc><lastmod>2022-01-15T09:00:00Z</lastmod></url>
+</urlset>`))
+			_ = zw.Close()
+			w.Header().Set("Content-Type", "application/gzip")
+			_, _ = w.Write(buf.Bytes())
+		case "/sitemap-pages.xml":
+			_, _ = w.Write([]byte(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>` + server.URL + `/about</loc></url>
+  <url><loc>` + server.URL + `/blog/undated-post</loc></url>
+</urlset>`))
+		default:
+			_, _ = w.Write([]byte(`<html><body><article><h1>Page ` + r.URL.Path + `</h1><p>Every page on this site has enough text to be extracted as the main content.</p></article></body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	binary := buildBinary(t)
+
+	t.Run("sitemap_follows_index_and_filters", func(t *testing.T) {
+		t.Log("SPEC: Sitemap Filtering")
+		t.Log("GIVEN a sitemap index pointing at a gzipped and a plain sitemap")
+		t.Log("WHEN the user runs sz sitemap --list --include /blog/ --since 2024-01-01")
+		t.Log("THEN only blog pages modified since then should be listed")
+
+		cmd := exec.Command(binary, "sitemap", "--list", "--include", "/blog/", "--since", "2024-01-01", server.URL+"/sitemap_index.xml")
+		var stdout, stderr strings.Builder
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		require.NoError(t, cmd.Run(), stderr.String())
+
+		assert.Equal(t, server.URL+"/blog/new-post\n", stdout.String())
+		assert.Contains(t, stderr.String(), "Selected 1 of 4 URLs")
+	})
+
+	t.Run("sitemap_distills_pages", func(t *testing.T) {
+		t.Log("SPEC: Sitemap Distillation")
+		t.Log("GIVEN the same sitemap index")
+		t.Log("WHEN the user runs sz sitemap --exclude /blog/ --output-dir DIR")
+		t.Log("THEN each selected page should be distilled into its own file, as sz batch does")
+
+		dir := t.TempDir()
+		cmd := exec.Command(binary, "sitemap", "--socket", filepath.Join(t.TempDir(), "sz.sock"), "--exclude", "/blog/", "--output-dir", dir, server.URL+"/sitemap_index.xml")
+		cmd.Env = append(os.Environ(), "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+
+		assert.Contains(t, string(output), "Distilled 1 of 1 targets")
+		files, err := filepath.Glob(filepath.Join(dir, "*.md"))
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		content, err := os.ReadFile(files[0])
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "# Page /about")
+	})
+}
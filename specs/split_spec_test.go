@@ -0,0 +1,61 @@
+package specs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitBySpec(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "reference.html")
+	require.NoError(t, os.WriteFile(page, []byte(`<html><body><article>
+<h1>Widget API</h1><p>The Widget API lets you create, list and delete widgets over HTTP.</p>
+<h2>Authentication</h2><p>Send your API key in the Authorization header of every request.</p>
+<h2>Creating Widgets</h2><p>POST a JSON body with the widget's name and colour to the widgets endpoint.</p>
+<h3>Limits</h3><p>An account may hold at most one thousand widgets at any one time.</p>
+<h2>Deleting Widgets</h2><p>DELETE a widget by its identifier; deleted widgets cannot be restored.</p>
+</article></body></html>`), 0o644))
+	binary := buildBinary(t)
+
+	t.Run("splits_at_h2", func(t *testing.T) {
+		t.Log("SPEC: Splitting Output")
+		t.Log("GIVEN an API reference with an introduction and three h2 sections")
+		t.Log("WHEN the user runs sz --split-by h2 --output-dir parts on it")
+		t.Log("THEN each section should be written to its own numbered, slugged file")
+
+		parts := filepath.Join(t.TempDir(), "parts")
+		output, err := exec.Command(binary, "--split-by", "h2", "--output-dir", parts, page).CombinedOutput()
+		require.NoError(t, err, string(output))
+
+		entries, err := os.ReadDir(parts)
+		require.NoError(t, err)
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		assert.Equal(t, []string{"01-widget-api.md", "02-authentication.md", "03-creating-widgets.md", "04-deleting-widgets.md"}, names)
+
+		creating, err := os.ReadFile(filepath.Join(parts, "03-creating-widgets.md"))
+		require.NoError(t, err)
+		assert.Contains(t, string(creating), "## Creating Widgets")
+		assert.Contains(t, string(creating), "one thousand widgets", "Subsections should stay with their section")
+		assert.NotContains(t, string(creating), "DELETE")
+		assert.Contains(t, string(output), "02-authentication.md", "Written files should be listed")
+	})
+
+	t.Run("invalid_level", func(t *testing.T) {
+		t.Log("SPEC: Splitting Output")
+		t.Log("GIVEN a heading level sz does not split at")
+		t.Log("WHEN the user runs sz --split-by h4")
+		t.Log("THEN the command should exit 2")
+
+		err := exec.Command(binary, "--split-by", "h4", page).Run()
+		require.Error(t, err)
+		assert.Equal(t, 2, err.(*exec.ExitError).ExitCode())
+	})
+}
@@ -0,0 +1,87 @@
+package specs
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsSpec(t *testing.T) {
+	page := `<html><body>
+<nav><a href="/">Home</a> <a href="/about">About</a></nav>
+<article>
+  <h1>Title</h1>
+  <p>One two three four five, with a <a href="/x">link</a>.</p>
+  <h2>More</h2>
+  <p>Another paragraph here.</p>
+  <img src="/chart.png" alt="Chart">
+  <img src="/pixel.gif" width="1" height="1">
+</article>
+<footer class="footer">Copyright notice</footer>
+</body></html>`
+
+	binary := buildBinary(t)
+	run := func(t *testing.T, args ...string) (string, string) {
+		cmd := exec.Command(binary, args...)
+		cmd.Env = append(os.Environ(), "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+		cmd.Stdin = strings.NewReader(page)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		require.NoError(t, err, stderr.String())
+		return string(output), stderr.String()
+	}
+
+	t.Run("stats_json", func(t *testing.T) {
+		t.Log("SPEC: Content Statistics")
+		t.Log("GIVEN an article wrapped in navigation and a footer")
+		t.Log("WHEN the user runs sz stats --format json -")
+		t.Log("THEN the counts should cover the main content only, with the page's words and filter removals alongside")
+
+		output, _ := run(t, "stats", "--format", "json", "-")
+		var stats map[string]any
+		require.NoError(t, json.Unmarshal([]byte(output), &stats), output)
+		assert.Equal(t, float64(14), stats["words"])
+		assert.Equal(t, float64(1), stats["reading_minutes"])
+		assert.Equal(t, float64(2), stats["headings"])
+		assert.Equal(t, float64(2), stats["paragraphs"])
+		assert.Equal(t, float64(1), stats["links"], "Navigation links should not be counted")
+		assert.Equal(t, float64(1), stats["images"], "Tracking pixels should not be counted")
+		assert.Equal(t, float64(18), stats["source_words"])
+		require.IsType(t, map[string]any{}, stats["filter"])
+		filterStats := stats["filter"].(map[string]any)
+		assert.Greater(t, filterStats["nodes_removed"], float64(0))
+		assert.Contains(t, filterStats["rules_applied"], "SemanticTagFilter")
+	})
+
+	t.Run("stats_text", func(t *testing.T) {
+		t.Log("SPEC: Readable Statistics")
+		t.Log("GIVEN the same page")
+		t.Log("WHEN the user runs sz stats -")
+		t.Log("THEN the statistics should be printed one per line")
+
+		output, _ := run(t, "stats", "-")
+		assert.Contains(t, output, "Words:         14\n")
+		assert.Contains(t, output, "Reading time:  1 min\n")
+		assert.Contains(t, output, "Page words:    18 (78% kept)\n")
+	})
+
+	t.Run("stats_flag", func(t *testing.T) {
+		t.Log("SPEC: Statistics Alongside Output")
+		t.Log("GIVEN the same page")
+		t.Log("WHEN the user runs sz --stats -")
+		t.Log("THEN the content should go to stdout as usual and the statistics to stderr")
+
+		output, stderr := run(t, "--stats", "-")
+		assert.Contains(t, output, "# Title")
+		assert.NotContains(t, output, "Words:")
+		assert.Contains(t, stderr, "Words:         14\n")
+	})
+}
@@ -0,0 +1,110 @@
+package specs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// summarizeArticle is a page with enough text for the reader view to keep.
+const summarizeArticle = `<html><head><title>Tide Pools</title></head><body><article><h1>Tide Pools</h1>
+<p>Tide pools form where the sea leaves water behind in hollows of rock as it goes out.</p>
+<p>Anemones, crabs and snails live in them, surviving heat and salt between tides.</p></article></body></html>`
+
+// fakeModel serves an OpenAI-compatible chat completions endpoint that
+// answers every request with reply and keeps the last request it got.
+func fakeModel(t *testing.T, reply string) (*httptest.Server, *map[string]any) {
+	t.Helper()
+	var last map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		last = nil
+		_ = json.NewDecoder(r.Body).Decode(&last)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"choices":[{"message":{"role":"assistant","content":%q}}]}`, reply)
+	}))
+	t.Cleanup(server.Close)
+	return server, &last
+}
+
+// llmConfig writes a config.yaml pointing at a model and returns the
+// environment that uses it.
+func llmConfig(t *testing.T, endpoint string) []string {
+	t.Helper()
+	configDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(configDir, "essenz"), 0o755))
+	config := fmt.Sprintf("llm:\n  endpoint: %s/v1\n  model: test-model\n  max_tokens: 200\n", endpoint)
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "essenz", "config.yaml"), []byte(config), 0o644))
+	return append(os.Environ(), "XDG_CONFIG_HOME="+configDir, "ESSENZ_LLM_API_KEY=test-key")
+}
+
+func TestSummarizeSpec(t *testing.T) {
+	dir := t.TempDir()
+	article := filepath.Join(dir, "article.html")
+	require.NoError(t, os.WriteFile(article, []byte(summarizeArticle), 0o644))
+	binary := buildBinary(t)
+
+	t.Run("summarize_prints_model_summary", func(t *testing.T) {
+		t.Log("SPEC: Summarize")
+		t.Log("GIVEN a model configured under llm in config.yaml")
+		t.Log("WHEN the user runs sz summarize on a page")
+		t.Log("THEN the distilled markdown should be sent to the model and its summary printed")
+
+		model, last := fakeModel(t, "Tide pools are rocky hollows full of hardy sea life.")
+		cmd := exec.Command(binary, "summarize", article)
+		cmd.Env = llmConfig(t, model.URL)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+
+		assert.Equal(t, "Tide pools are rocky hollows full of hardy sea life.\n", string(output))
+		require.NotNil(t, *last, "The model should have been called")
+		assert.Equal(t, "test-model", (*last)["model"])
+		assert.EqualValues(t, 200, (*last)["max_tokens"])
+		request, _ := json.Marshal(*last)
+		assert.Contains(t, string(request), "Anemones, crabs and snails", "The prompt should carry the distilled content")
+		assert.Contains(t, string(request), "Title: Tide Pools")
+	})
+
+	t.Run("summary_flag_adds_section", func(t *testing.T) {
+		t.Log("SPEC: Summary Section")
+		t.Log("GIVEN a model configured under llm in config.yaml")
+		t.Log("WHEN the user runs sz --summary on a page")
+		t.Log("THEN the output should start with a Summary section followed by the full content")
+
+		model, _ := fakeModel(t, "A short summary.")
+		cmd := exec.Command(binary, "--summary", article)
+		cmd.Env = llmConfig(t, model.URL)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+
+		assert.True(t, strings.HasPrefix(string(output), "## Summary\n\nA short summary.\n\n"), "Output should start with the summary: %s", output)
+		assert.Contains(t, string(output), "Anemones, crabs and snails", "The full content should follow")
+	})
+
+	t.Run("summarize_needs_model", func(t *testing.T) {
+		t.Log("SPEC: Offline By Default")
+		t.Log("GIVEN no model in config.yaml")
+		t.Log("WHEN the user runs sz summarize")
+		t.Log("THEN it should fail with a usage error explaining what to configure")
+
+		cmd := exec.Command(binary, "summarize", article)
+		cmd.Env = append(os.Environ(), "XDG_CONFIG_HOME="+t.TempDir())
+		output, err := cmd.CombinedOutput()
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 2, exitErr.ExitCode())
+		assert.Contains(t, string(output), "llm.endpoint and llm.model")
+	})
+}
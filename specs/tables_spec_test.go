@@ -0,0 +1,81 @@
+package specs
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTablesSpec(t *testing.T) {
+	binary := buildBinary(t)
+	page := filepath.Join(t.TempDir(), "reference.html")
+	require.NoError(t, os.WriteFile(page, []byte(`<html><body>
+<nav><table><tr><td>Home</td><td>About</td></tr><tr><td>Docs</td><td>Blog</td></tr></table></nav>
+<article><h1>Reference</h1>
+<table><caption>Populations</caption>
+  <thead><tr><th>City</th><th>Year</th><th>Population</th></tr></thead>
+  <tbody>
+    <tr><td rowspan="2">Oslo</td><td>2020</td><td>693,494</td></tr>
+    <tr><td>2021</td><td>697,010</td></tr>
+  </tbody>
+</table>
+<table role="presentation"><tr><td>Layout</td><td>only</td></tr><tr><td>not</td><td>data</td></tr></table>
+<table><tr><th>Key</th><th>Meaning</th></tr><tr><td>a "quoted"</td><td>first, letter</td></tr></table>
+</article></body></html>`), 0o644))
+
+	t.Run("tables_indexed_csv_stream", func(t *testing.T) {
+		t.Log("SPEC: Table Extraction")
+		t.Log("GIVEN a page with two data tables in its content, a layout table, and a table in its navigation")
+		t.Log("WHEN the user runs sz tables on it")
+		t.Log("THEN both data tables should be written as one CSV stream, each row prefixed with its table number")
+
+		output, err := exec.Command(binary, "tables", page).Output()
+		require.NoError(t, err)
+		assert.Equal(t, `1,City,Year,Population
+1,Oslo,2020,"693,494"
+1,Oslo,2021,"697,010"
+2,Key,Meaning
+2,"a ""quoted""","first, letter"
+`, string(output))
+	})
+
+	t.Run("tables_single_json", func(t *testing.T) {
+		t.Log("SPEC: Single Table as JSON")
+		t.Log("GIVEN the same page")
+		t.Log("WHEN the user runs sz tables --table 1 --format json")
+		t.Log("THEN only that table should be printed, with its caption, header and rows")
+
+		output, err := exec.Command(binary, "tables", "--table", "1", "--format", "json", page).Output()
+		require.NoError(t, err)
+		var table struct {
+			Caption string
+			Header  []string
+			Rows    [][]string
+		}
+		require.NoError(t, json.Unmarshal(output, &table), string(output))
+		assert.Equal(t, "Populations", table.Caption)
+		assert.Equal(t, []string{"City", "Year", "Population"}, table.Header)
+		assert.Equal(t, [][]string{{"Oslo", "2020", "693,494"}, {"Oslo", "2021", "697,010"}}, table.Rows)
+	})
+
+	t.Run("tables_per_table_files", func(t *testing.T) {
+		t.Log("SPEC: Table Files")
+		t.Log("GIVEN the same page")
+		t.Log("WHEN the user runs sz tables --format tsv --output-dir DIR")
+		t.Log("THEN each table should be written to its own numbered file")
+
+		dir := t.TempDir()
+		output, err := exec.Command(binary, "tables", "--format", "tsv", "--output-dir", dir, page).CombinedOutput()
+		require.NoError(t, err, string(output))
+
+		second, err := os.ReadFile(filepath.Join(dir, "table-2.tsv"))
+		require.NoError(t, err)
+		assert.Equal(t, "Key\tMeaning\n\"a \"\"quoted\"\"\"\tfirst, letter\n", string(second))
+		assert.FileExists(t, filepath.Join(dir, "table-1.tsv"))
+	})
+}
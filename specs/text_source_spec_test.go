@@ -0,0 +1,115 @@
+package specs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const notesMarkdown = `Canal Notes
+===========
+
+Narrowboats were built
+to fit the narrow locks
+of the English canals. See [the map][map].
+
+Locks
+-----
+
+* Staircase locks lift boats
+  up steep hills.
++ Swing bridges cross the cut.
+
+` + "```" + `
+[map] stays as it is in code
+` + "```" + `
+
+[map]: https://example.com/canal-map "Canal map"
+`
+
+func TestTextSourceSpec(t *testing.T) {
+	binary := buildBinary(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/notes.md":
+			w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+			_, _ = w.Write([]byte(notesMarkdown))
+		case "/rfc.txt":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_, _ = w.Write([]byte("Tidal mills stored sea water\r\nin a pond at high tide.\r\n\r\n\r\n\r\nAt low tide the miller\r\nopened the sluice.\r\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	assertNormalized := func(t *testing.T, text string) {
+		t.Helper()
+		assert.True(t, strings.HasPrefix(text, "# Canal Notes\n\n"), text)
+		assert.Contains(t, text, "Narrowboats were built to fit the narrow locks of the English canals. See [the map](https://example.com/canal-map).")
+		assert.Contains(t, text, "## Locks\n\n- Staircase locks lift boats up steep hills.\n- Swing bridges cross the cut.")
+		assert.Contains(t, text, "```\n[map] stays as it is in code\n```", "Code should be left alone")
+		assert.NotContains(t, text, "[map]: ", "Link definitions should be dropped once inlined")
+	}
+
+	t.Run("markdown_url", func(t *testing.T) {
+		t.Log("SPEC: Markdown and Plain Text Sources")
+		t.Log("GIVEN a URL serving text/markdown with setext headings, hard wraps and reference links")
+		t.Log("WHEN the user runs sz on it")
+		t.Log("THEN the markdown should be normalized rather than parsed as HTML")
+
+		output, err := exec.Command(binary, server.URL+"/notes.md").Output()
+		require.NoError(t, err)
+
+		assertNormalized(t, string(output))
+	})
+
+	t.Run("markdown_file", func(t *testing.T) {
+		t.Log("SPEC: Markdown and Plain Text Sources")
+		t.Log("GIVEN the same markdown saved as a .md file")
+		t.Log("WHEN the user runs sz on the file")
+		t.Log("THEN it should be normalized the same way")
+
+		page := filepath.Join(t.TempDir(), "notes.md")
+		require.NoError(t, os.WriteFile(page, []byte(notesMarkdown), 0o644))
+
+		output, err := exec.Command(binary, page).Output()
+		require.NoError(t, err)
+
+		assertNormalized(t, string(output))
+	})
+
+	t.Run("plain_text_url", func(t *testing.T) {
+		t.Log("SPEC: Markdown and Plain Text Sources")
+		t.Log("GIVEN a URL serving hard-wrapped text/plain with CRLF line endings")
+		t.Log("WHEN the user runs sz on it")
+		t.Log("THEN each paragraph should be on one line, separated by a single blank line")
+
+		output, err := exec.Command(binary, server.URL+"/rfc.txt").Output()
+		require.NoError(t, err)
+
+		assert.Equal(t, "Tidal mills stored sea water in a pond at high tide.\n\nAt low tide the miller opened the sluice.\n", string(output))
+	})
+
+	t.Run("piped_markdown", func(t *testing.T) {
+		t.Log("SPEC: Markdown and Plain Text Sources")
+		t.Log("GIVEN the markdown piped to sz -")
+		t.Log("WHEN sz reads it from stdin")
+		t.Log("THEN it should be normalized the same way")
+
+		cmd := exec.Command(binary, "-")
+		cmd.Stdin = strings.NewReader(notesMarkdown)
+		output, err := cmd.Output()
+		require.NoError(t, err)
+
+		assertNormalized(t, string(output))
+	})
+}
@@ -0,0 +1,176 @@
+package specs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSVerificationSpec(t *testing.T) {
+	binary := buildBinary(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><article><h1>Signal Boxes</h1><p>Signal boxes held the levers that worked the points and signals of a railway junction.</p></article></body></html>`))
+	})
+
+	// writePEM writes PEM blocks to a file in dir and returns its path.
+	writePEM := func(t *testing.T, dir, name string, blocks ...*pem.Block) string {
+		var data []byte
+		for _, block := range blocks {
+			data = append(data, pem.EncodeToMemory(block)...)
+		}
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+		return path
+	}
+
+	serverCertificate := func(server *httptest.Server) *pem.Block {
+		return &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}
+	}
+
+	t.Run("verifies_certificates_by_default", func(t *testing.T) {
+		t.Log("SPEC: TLS Verification")
+		t.Log("GIVEN an HTTPS server with a self-signed certificate")
+		t.Log("WHEN the user runs sz on it without TLS flags")
+		t.Log("THEN the fetch should fail with the network exit code")
+
+		server := httptest.NewTLSServer(handler)
+		defer server.Close()
+
+		cmd := exec.Command(binary, server.URL)
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		var exitErr *exec.ExitError
+		require.True(t, errors.As(err, &exitErr), "sz should fail, got output %q", output)
+		assert.Equal(t, 3, exitErr.ExitCode())
+		assert.Contains(t, stderr.String(), "certificate")
+		assert.NotContains(t, string(output), "Signal Boxes")
+	})
+
+	t.Run("insecure_skips_verification", func(t *testing.T) {
+		t.Log("SPEC: TLS Verification")
+		t.Log("GIVEN an HTTPS server with a self-signed certificate")
+		t.Log("WHEN the user runs sz --insecure on it")
+		t.Log("THEN the page should be fetched")
+
+		server := httptest.NewTLSServer(handler)
+		defer server.Close()
+
+		output, err := exec.Command(binary, "--insecure", server.URL).Output()
+		require.NoError(t, err)
+		assert.Contains(t, string(output), "# Signal Boxes")
+	})
+
+	t.Run("trusts_a_ca_certificate", func(t *testing.T) {
+		t.Log("SPEC: TLS Verification")
+		t.Log("GIVEN an HTTPS server with a self-signed certificate saved as a PEM file")
+		t.Log("WHEN the user runs sz --ca-cert with that file")
+		t.Log("THEN the certificate should be trusted and the page fetched")
+
+		server := httptest.NewTLSServer(handler)
+		defer server.Close()
+		caFile := writePEM(t, t.TempDir(), "ca.pem", serverCertificate(server))
+
+		output, err := exec.Command(binary, "--ca-cert", caFile, server.URL).Output()
+		require.NoError(t, err)
+		assert.Contains(t, string(output), "# Signal Boxes")
+	})
+
+	t.Run("rejects_a_file_without_certificates", func(t *testing.T) {
+		t.Log("SPEC: TLS Verification")
+		t.Log("GIVEN a --ca-cert file that holds no PEM certificates")
+		t.Log("WHEN the user runs sz fetch with it")
+		t.Log("THEN sz should fail and name the file")
+
+		server := httptest.NewTLSServer(handler)
+		defer server.Close()
+		caFile := filepath.Join(t.TempDir(), "ca.pem")
+		require.NoError(t, os.WriteFile(caFile, []byte("not a certificate\n"), 0o600))
+
+		output, err := exec.Command(binary, "fetch", "--ca-cert", caFile, server.URL).CombinedOutput()
+		require.Error(t, err)
+		assert.Contains(t, string(output), "no PEM certificates found in "+caFile)
+	})
+
+	t.Run("presents_a_client_certificate", func(t *testing.T) {
+		t.Log("SPEC: TLS Verification")
+		t.Log("GIVEN an HTTPS server that requires a client certificate signed by its CA")
+		t.Log("WHEN the user runs sz with and without --client-cert and --client-key")
+		t.Log("THEN the page should only be fetched with the client certificate")
+
+		dir := t.TempDir()
+		caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		caTemplate := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: "Signal Box CA"},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(time.Hour),
+			KeyUsage:              x509.KeyUsageCertSign,
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+		}
+		caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+		require.NoError(t, err)
+		caCert, err := x509.ParseCertificate(caDER)
+		require.NoError(t, err)
+
+		clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		clientTemplate := &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      pkix.Name{CommonName: "sz"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}
+		clientDER, err := x509.CreateCertificate(rand.Reader, clientTemplate, caCert, &clientKey.PublicKey, caKey)
+		require.NoError(t, err)
+		keyDER, err := x509.MarshalECPrivateKey(clientKey)
+		require.NoError(t, err)
+		certBlock := &pem.Block{Type: "CERTIFICATE", Bytes: clientDER}
+		keyBlock := &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}
+		certFile := writePEM(t, dir, "client.pem", certBlock)
+		keyFile := writePEM(t, dir, "client-key.pem", keyBlock)
+		bundleFile := writePEM(t, dir, "client-bundle.pem", certBlock, keyBlock)
+
+		clientCAs := x509.NewCertPool()
+		clientCAs.AddCert(caCert)
+		server := httptest.NewUnstartedServer(handler)
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+		server.StartTLS()
+		defer server.Close()
+		caFile := writePEM(t, dir, "ca.pem", serverCertificate(server))
+
+		output, err := exec.Command(binary, "--ca-cert", caFile, server.URL).Output()
+		require.Error(t, err, "the server should refuse a client without a certificate")
+		assert.NotContains(t, string(output), "Signal Boxes")
+
+		output, err = exec.Command(binary, "--ca-cert", caFile, "--client-cert", certFile, "--client-key", keyFile, server.URL).Output()
+		require.NoError(t, err)
+		assert.Contains(t, string(output), "# Signal Boxes")
+
+		output, err = exec.Command(binary, "--ca-cert", caFile, "--client-cert", bundleFile, server.URL).Output()
+		require.NoError(t, err, "a file holding both the certificate and its key should do")
+		assert.Contains(t, string(output), "# Signal Boxes")
+	})
+}
@@ -0,0 +1,94 @@
+package specs
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountTokensSpec(t *testing.T) {
+	page := `<html><body><article><h1>Field Guide</h1>
+<p>The quick brown fox jumps over the lazy dog. Foxes are omnivorous mammals belonging to several genera of the family Canidae.</p>
+<h2>Birds</h2>
+<p>Birds are a group of warm-blooded vertebrates constituting the class Aves, characterised by feathers, toothless beaked jaws, and the laying of hard-shelled eggs.</p>
+</article></body></html>`
+	file := filepath.Join(t.TempDir(), "guide.html")
+	require.NoError(t, os.WriteFile(file, []byte(page), 0o644))
+	binary := buildBinary(t)
+	total := regexp.MustCompile(`Tokens:\s+(\d+) \(([^,]+), ([^,]+), estimated\)`)
+
+	t.Run("reports_document_tokens", func(t *testing.T) {
+		t.Log("SPEC: Token Counting")
+		t.Log("GIVEN a short article of about 50 words")
+		t.Log("WHEN the user runs sz --count-tokens")
+		t.Log("THEN stderr should report an estimate near what GPT-4o's tokenizer counts, and stdout the unchanged markdown")
+
+		cmd := exec.Command(binary, "--count-tokens", file)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		require.NoError(t, err, stderr.String())
+
+		assert.True(t, strings.HasPrefix(string(output), "# Field Guide"), "Content should be printed as usual")
+		match := total.FindStringSubmatch(stderr.String())
+		require.NotNil(t, match, "stderr should report the token count: %s", stderr.String())
+		assert.Equal(t, "gpt-4o", match[2])
+		assert.Equal(t, "o200k_base", match[3])
+		count, _ := strconv.Atoi(match[1])
+		assert.InDelta(t, 70, count, 15, "The estimate should be close to the real count")
+	})
+
+	t.Run("reports_chunk_tokens", func(t *testing.T) {
+		t.Log("SPEC: Token Counting")
+		t.Log("GIVEN the same article")
+		t.Log("WHEN the user runs sz --count-tokens=gpt-4 --chunk-size 200 --chunk-overlap 0")
+		t.Log("THEN each chunk should carry its token count, and the counts should add up to about the document's")
+
+		cmd := exec.Command(binary, "--count-tokens=gpt-4", "--chunk-size", "200", "--chunk-overlap", "0", file)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		require.NoError(t, err, stderr.String())
+
+		match := total.FindStringSubmatch(stderr.String())
+		require.NotNil(t, match, stderr.String())
+		assert.Equal(t, "cl100k_base", match[3])
+		documentTokens, _ := strconv.Atoi(match[1])
+
+		sum := 0
+		lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+		require.Len(t, lines, 2)
+		for _, line := range lines {
+			var c struct {
+				Tokens int `json:"tokens"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(line), &c))
+			assert.Positive(t, c.Tokens)
+			sum += c.Tokens
+		}
+		assert.InDelta(t, documentTokens, sum, 3, "Chunk counts should add up to the document's")
+	})
+
+	t.Run("rejects_unknown_model", func(t *testing.T) {
+		t.Log("SPEC: Token Counting")
+		t.Log("GIVEN a model sz has no tokenizer estimate for")
+		t.Log("WHEN the user runs sz --count-tokens=foo")
+		t.Log("THEN sz should exit with status 2 naming the models it knows")
+
+		output, err := exec.Command(binary, "--count-tokens=foo", file).CombinedOutput()
+		require.Error(t, err)
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 2, exitErr.ExitCode())
+		assert.Contains(t, string(output), "cl100k_base")
+	})
+}
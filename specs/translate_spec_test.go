@@ -0,0 +1,115 @@
+package specs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// translateArticle has a heading and a list whose syntax translation must keep.
+const translateArticle = `<html><body><article><h1>Tide Pools</h1>
+<p>Tide pools form where the sea leaves water behind in hollows of rock.</p>
+<ul><li>Anemones hold on to the rock.</li><li>Crabs hide under weed.</li></ul>
+<p>Visit them at low tide and tread carefully around the edges.</p></article></body></html>`
+
+func TestTranslateSpec(t *testing.T) {
+	dir := t.TempDir()
+	article := filepath.Join(dir, "article.html")
+	require.NoError(t, os.WriteFile(article, []byte(translateArticle), 0o644))
+	binary := buildBinary(t)
+
+	t.Run("translate_with_llm_keeps_structure", func(t *testing.T) {
+		t.Log("SPEC: Translate With A Language Model")
+		t.Log("GIVEN a model that translates by upper-casing text")
+		t.Log("WHEN the user runs sz --translate=de on a page")
+		t.Log("THEN every block should be translated while its markdown syntax stays as it was")
+
+		var requests int
+		model := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			var req struct {
+				Messages []struct {
+					Content string `json:"content"`
+				} `json:"messages"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			prompt := req.Messages[0].Content
+			assert.Contains(t, prompt, `"de"`, "The prompt should name the language")
+			text := strings.SplitN(prompt, "\n\n", 2)[1]
+			_, _ = fmt.Fprintf(w, `{"choices":[{"message":{"content":%q}}]}`, strings.ToUpper(text))
+		}))
+		defer model.Close()
+
+		cmd := exec.Command(binary, "--translate=de", article)
+		cmd.Env = llmConfig(t, model.URL)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+
+		assert.Contains(t, string(output), "# TIDE POOLS")
+		assert.Contains(t, string(output), "- ANEMONES HOLD ON TO THE ROCK.", "List markers should be kept")
+		assert.Contains(t, string(output), "- CRABS HIDE UNDER WEED.")
+		assert.Contains(t, string(output), "VISIT THEM AT LOW TIDE")
+		assert.Equal(t, 4, requests, "Each block should be sent once")
+	})
+
+	t.Run("translate_with_deepl", func(t *testing.T) {
+		t.Log("SPEC: Translate With DeepL")
+		t.Log("GIVEN translate.backend set to deepl in config.yaml")
+		t.Log("WHEN the user runs sz --translate=de on a page")
+		t.Log("THEN the blocks should be sent to the DeepL API with the key and target language")
+
+		deepl := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v2/translate", r.URL.Path)
+			assert.Equal(t, "DeepL-Auth-Key deepl-key", r.Header.Get("Authorization"))
+			var req struct {
+				Text       []string `json:"text"`
+				TargetLang string   `json:"target_lang"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "DE", req.TargetLang)
+			lines := strings.Split(req.Text[0], "\n")
+			for i := range lines {
+				lines[i] = "[de] " + lines[i]
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"translations": []map[string]string{{"text": strings.Join(lines, "\n")}}})
+		}))
+		defer deepl.Close()
+
+		configDir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(configDir, "essenz"), 0o755))
+		config := fmt.Sprintf("translate:\n  backend: deepl\n  endpoint: %s\n  api_key: deepl-key\n", deepl.URL)
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, "essenz", "config.yaml"), []byte(config), 0o644))
+
+		cmd := exec.Command(binary, "--translate", "de", article)
+		cmd.Env = append(os.Environ(), "XDG_CONFIG_HOME="+configDir)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+
+		assert.Contains(t, string(output), "# [de] Tide Pools")
+		assert.Contains(t, string(output), "- [de] Anemones hold on to the rock.")
+		assert.Contains(t, string(output), "- [de] Crabs hide under weed.")
+	})
+
+	t.Run("invalid_language_rejected", func(t *testing.T) {
+		t.Log("SPEC: Invalid Translation Language")
+		t.Log("GIVEN a --translate value that is not a language code")
+		t.Log("WHEN the command runs")
+		t.Log("THEN it should fail with a usage error before fetching anything")
+
+		cmd := exec.Command(binary, "--translate", "german please", article)
+		output, err := cmd.CombinedOutput()
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 2, exitErr.ExitCode())
+		assert.Contains(t, string(output), "invalid language")
+	})
+}
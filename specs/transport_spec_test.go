@@ -0,0 +1,122 @@
+package specs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPTransportSpec(t *testing.T) {
+	binary := buildBinary(t)
+
+	page := func(title, text string) []byte {
+		return []byte(fmt.Sprintf(`<html><body><article><h1>%s</h1><p>%s</p></article></body></html>`, title, text))
+	}
+
+	t.Run("decodes_compressed_responses", func(t *testing.T) {
+		t.Log("SPEC: HTTP Transport")
+		t.Log("GIVEN a server that compresses pages with Brotli, gzip or deflate when the client accepts them")
+		t.Log("WHEN the user runs sz on each page")
+		t.Log("THEN the pages should be decompressed before they are distilled")
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			accepted := r.Header.Get("Accept-Encoding")
+			var body bytes.Buffer
+			switch {
+			case r.URL.Path == "/gzip" && strings.Contains(accepted, "gzip"):
+				writer := gzip.NewWriter(&body)
+				_, _ = writer.Write(page("Gzip Locks", "Locks lift boats between the levels of water on a canal."))
+				_ = writer.Close()
+				w.Header().Set("Content-Encoding", "gzip")
+			case r.URL.Path == "/deflate" && strings.Contains(accepted, "deflate"):
+				writer := zlib.NewWriter(&body)
+				_, _ = writer.Write(page("Deflate Weirs", "Weirs hold back the river so boats can float above the shallows."))
+				_ = writer.Close()
+				w.Header().Set("Content-Encoding", "deflate")
+			case r.URL.Path == "/br" && strings.Contains(accepted, "br"):
+				writer := brotli.NewWriter(&body)
+				_, _ = writer.Write(page("Brotli Aqueducts", "Aqueducts carry a canal across a valley on a bridge of its own."))
+				_ = writer.Close()
+				w.Header().Set("Content-Encoding", "br")
+			default:
+				http.Error(w, "compression expected", http.StatusNotAcceptable)
+				return
+			}
+			_, _ = w.Write(body.Bytes())
+		}))
+		defer server.Close()
+
+		output, err := exec.Command(binary, server.URL+"/gzip").Output()
+		require.NoError(t, err)
+		assert.Contains(t, string(output), "# Gzip Locks")
+
+		output, err = exec.Command(binary, server.URL+"/deflate").Output()
+		require.NoError(t, err)
+		assert.Contains(t, string(output), "# Deflate Weirs")
+
+		output, err = exec.Command(binary, server.URL+"/br").Output()
+		require.NoError(t, err)
+		assert.Contains(t, string(output), "# Brotli Aqueducts")
+	})
+
+	t.Run("speaks_http2", func(t *testing.T) {
+		t.Log("SPEC: HTTP Transport")
+		t.Log("GIVEN an HTTPS server that offers HTTP/2")
+		t.Log("WHEN the user runs sz on a page it serves")
+		t.Log("THEN the page should be fetched over HTTP/2")
+
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(page("Protocol "+r.Proto, "Narrowboats were built to fit the narrow locks of the English canals."))
+		}))
+		server.EnableHTTP2 = true
+		server.StartTLS()
+		defer server.Close()
+
+		output, err := exec.Command(binary, "--insecure", server.URL).Output()
+		require.NoError(t, err)
+		assert.Contains(t, string(output), "# Protocol HTTP/2.0")
+	})
+
+	t.Run("reuses_connections", func(t *testing.T) {
+		t.Log("SPEC: HTTP Transport")
+		t.Log("GIVEN an article split across three pages of one site")
+		t.Log("WHEN the user runs sz --follow-pagination on it")
+		t.Log("THEN all three pages should be fetched over one connection")
+
+		var connections atomic.Int32
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next := ""
+			switch r.URL.Path {
+			case "/essay":
+				next = `<link rel="next" href="/essay/2">`
+			case "/essay/2":
+				next = `<link rel="next" href="/essay/3">`
+			}
+			_, _ = fmt.Fprintf(w, `<html><head>%s</head><body><article><h1>Tidal Mills</h1><p>Part %s of the story of the mills that ground grain with the tide.</p></article></body></html>`, next, r.URL.Path)
+		}))
+		server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				connections.Add(1)
+			}
+		}
+		server.Start()
+		defer server.Close()
+
+		output, err := exec.Command(binary, "--follow-pagination", server.URL+"/essay").Output()
+		require.NoError(t, err)
+		assert.Contains(t, string(output), "Part /essay/3")
+		assert.Equal(t, int32(1), connections.Load())
+	})
+}
@@ -0,0 +1,88 @@
+package specs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchSpec(t *testing.T) {
+	t.Run("watch_prints_diff_on_change", func(t *testing.T) {
+		t.Log("SPEC: Watch Mode")
+		t.Log("GIVEN a status page whose text changes after the second fetch")
+		t.Log("WHEN the user runs sz watch --count 3 against it")
+		t.Log("THEN only the change should be printed, as a unified diff of the markdown")
+
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			status := "All systems operational"
+			if requests.Add(1) > 2 {
+				status = "Degraded performance on the API"
+			}
+			_, _ = fmt.Fprintf(w, `<html><body><article><h1>Status</h1><p>%s.</p><p>This page reports the current state of every service we run.</p></article></body></html>`, status)
+		}))
+		defer server.Close()
+
+		binary := buildBinary(t)
+		cmd := exec.Command(binary, "watch", "--socket", filepath.Join(t.TempDir(), "sz.sock"), "--interval", "50ms", "--count", "3", server.URL)
+		cmd.Env = append(os.Environ(), "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+		var stdout, stderr strings.Builder
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		require.NoError(t, cmd.Run(), stderr.String())
+
+		assert.Equal(t, int32(3), requests.Load(), "The page should be fetched --count times")
+		assert.Equal(t, 1, strings.Count(stdout.String(), "@@ "), "Only the one change should be reported: %s", stdout.String())
+		assert.Contains(t, stdout.String(), "-All systems operational.")
+		assert.Contains(t, stdout.String(), "+Degraded performance on the API.")
+		assert.NotContains(t, stdout.String(), "-# Status", "Unchanged lines should only appear as context")
+	})
+
+	t.Run("watch_runs_hook_on_change", func(t *testing.T) {
+		t.Log("SPEC: Change Hook")
+		t.Log("GIVEN a page whose text changes after the first fetch")
+		t.Log("WHEN the user runs sz watch with --on-change")
+		t.Log("THEN the command should run once, with the new markdown on stdin and the URL and diff in its environment")
+
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			status := "All systems operational"
+			if requests.Add(1) > 1 {
+				status = "Degraded performance on the API"
+			}
+			_, _ = fmt.Fprintf(w, `<html><body><article><h1>Status</h1><p>%s.</p><p>This page reports the current state of every service we run.</p></article></body></html>`, status)
+		}))
+		defer server.Close()
+
+		dir := t.TempDir()
+		hook := `cat > ` + filepath.Join(dir, "stdin.md") + ` && echo "$SZ_URL" > ` + filepath.Join(dir, "url") + ` && cp "$SZ_DIFF_FILE" ` + filepath.Join(dir, "change.diff")
+
+		binary := buildBinary(t)
+		cmd := exec.Command(binary, "watch", "--socket", filepath.Join(t.TempDir(), "sz.sock"), "--interval", "50ms", "--count", "3", "--on-change", hook, server.URL)
+		cmd.Env = append(os.Environ(), "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		require.NoError(t, cmd.Run(), stderr.String())
+
+		markdown, err := os.ReadFile(filepath.Join(dir, "stdin.md"))
+		require.NoError(t, err, "The hook should have run: %s", stderr.String())
+		assert.Contains(t, string(markdown), "Degraded performance on the API.", "The hook should get the new version on stdin")
+
+		url, err := os.ReadFile(filepath.Join(dir, "url"))
+		require.NoError(t, err)
+		assert.Equal(t, server.URL, strings.TrimSpace(string(url)))
+
+		change, err := os.ReadFile(filepath.Join(dir, "change.diff"))
+		require.NoError(t, err)
+		assert.Contains(t, string(change), "+Degraded performance on the API.")
+	})
+}
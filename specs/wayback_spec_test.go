@@ -0,0 +1,234 @@
+package specs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaybackFallbackSpec(t *testing.T) {
+	binary := buildBinary(t)
+
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer site.Close()
+
+	var archive *httptest.Server
+	archive = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/wayback/available":
+			if !strings.HasSuffix(r.URL.Query().Get("url"), "/vanished") {
+				_, _ = fmt.Fprint(w, `{"url": "", "archived_snapshots": {}}`)
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"archived_snapshots": {"closest": {"status": "200", "available": true,
+				"url": "%s/web/20230415093000/%s/vanished", "timestamp": "20230415093000"}}}`, archive.URL, site.URL)
+		case strings.HasPrefix(r.URL.Path, "/web/20230415093000id_/"):
+			_, _ = fmt.Fprint(w, `<html><body><article><h1>Vanished Essay</h1><p>The essay as it stood before the site removed it.</p></article></body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer archive.Close()
+
+	run := func(args ...string) (string, string, error) {
+		cmd := exec.Command(binary, args...)
+		cmd.Env = append(os.Environ(), "ESSENZ_WAYBACK_ENDPOINT="+archive.URL+"/wayback/available")
+		var stdout, stderr strings.Builder
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		return stdout.String(), stderr.String(), err
+	}
+
+	t.Run("distills_latest_snapshot", func(t *testing.T) {
+		t.Log("SPEC: Wayback Machine Fallback")
+		t.Log("GIVEN a page that now answers 404 but was archived in April 2023")
+		t.Log("WHEN the user runs sz --wayback-fallback on it")
+		t.Log("THEN the archived copy should be distilled and marked as archived with its capture date")
+
+		stdout, stderr, err := run("--wayback-fallback", site.URL+"/vanished")
+		require.NoError(t, err, stderr)
+
+		assert.True(t, strings.HasPrefix(stdout, "> Archived copy from the Wayback Machine, captured 2023-04-15 09:30 UTC: "), stdout)
+		assert.Contains(t, stdout, "Vanished Essay")
+		assert.Contains(t, stderr, "Wayback Machine snapshot")
+	})
+
+	t.Run("fetch_marks_raw_html", func(t *testing.T) {
+		t.Log("SPEC: Wayback Machine Fallback")
+		t.Log("GIVEN the same page")
+		t.Log("WHEN the user runs sz fetch --wayback-fallback on it")
+		t.Log("THEN the archived HTML should start with a comment naming the snapshot")
+
+		stdout, stderr, err := run("fetch", "--wayback-fallback", site.URL+"/vanished")
+		require.NoError(t, err, stderr)
+
+		assert.True(t, strings.HasPrefix(stdout, "<!-- Archived copy from the Wayback Machine, captured 2023-04-15 09:30 UTC: "), stdout)
+		assert.Contains(t, stdout, "<h1>Vanished Essay</h1>")
+	})
+
+	t.Run("not_archived", func(t *testing.T) {
+		t.Log("SPEC: Wayback Machine Fallback")
+		t.Log("GIVEN a missing page the archive never captured")
+		t.Log("WHEN the user runs sz --wayback-fallback on it")
+		t.Log("THEN the original HTTP error should be reported with the network exit code")
+
+		_, stderr, err := run("--wayback-fallback", site.URL+"/never")
+		require.Error(t, err)
+
+		assert.Equal(t, 3, err.(*exec.ExitError).ExitCode())
+		assert.Contains(t, stderr, "404")
+	})
+
+	t.Run("off_by_default", func(t *testing.T) {
+		t.Log("SPEC: Wayback Machine Fallback")
+		t.Log("GIVEN the archived page")
+		t.Log("WHEN the user runs sz without --wayback-fallback")
+		t.Log("THEN the 404 should be reported rather than the archived copy")
+
+		stdout, _, err := run(site.URL + "/vanished")
+		require.Error(t, err)
+
+		assert.NotContains(t, stdout, "Vanished Essay")
+	})
+}
+
+func TestHistoricalSnapshotSpec(t *testing.T) {
+	binary := buildBinary(t)
+
+	var archive *httptest.Server
+	archive = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/wayback/available":
+			// Snapshots exist from 2021 and 2023; mid-2022 is closest to the later one
+			timestamp := "20230110080000"
+			if r.URL.Query().Get("timestamp") < "20220301000000" {
+				timestamp = "20211120170000"
+			}
+			_, _ = fmt.Fprintf(w, `{"archived_snapshots": {"closest": {"status": "200", "available": true,
+				"url": "%s/web/%s/https://example.com/pricing", "timestamp": "%s"}}}`, archive.URL, timestamp, timestamp)
+		case strings.HasPrefix(r.URL.Path, "/web/20211120170000id_/"):
+			_, _ = fmt.Fprint(w, `<html><body><article><h1>Pricing</h1><p>The basic plan costs five dollars a month.</p></article></body></html>`)
+		case strings.HasPrefix(r.URL.Path, "/web/20230110080000id_/"):
+			_, _ = fmt.Fprint(w, `<html><body><article><h1>Pricing</h1><p>The basic plan costs nine dollars a month.</p></article></body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer archive.Close()
+
+	run := func(args ...string) (string, string, error) {
+		cmd := exec.Command(binary, append([]string{"fetch"}, args...)...)
+		cmd.Env = append(os.Environ(), "ESSENZ_WAYBACK_ENDPOINT="+archive.URL+"/wayback/available")
+		var stdout, stderr strings.Builder
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		return stdout.String(), stderr.String(), err
+	}
+
+	t.Run("page_as_it_was", func(t *testing.T) {
+		t.Log("SPEC: Historical Snapshots")
+		t.Log("GIVEN a pricing page archived in November 2021 and January 2023")
+		t.Log("WHEN the user runs sz fetch --as-of 2021-12-01 --reader-view on it")
+		t.Log("THEN the 2021 snapshot should be distilled and marked with its capture date")
+
+		stdout, stderr, err := run("--as-of", "2021-12-01", "--reader-view", "https://example.com/pricing")
+		require.NoError(t, err, stderr)
+
+		assert.True(t, strings.HasPrefix(stdout, "> Archived copy from the Wayback Machine, captured 2021-11-20 17:00 UTC: "), stdout)
+		assert.Contains(t, stdout, "five dollars")
+	})
+
+	t.Run("closest_snapshot", func(t *testing.T) {
+		t.Log("SPEC: Historical Snapshots")
+		t.Log("GIVEN the same page")
+		t.Log("WHEN the user asks for it as of 2022-06-01")
+		t.Log("THEN the snapshot closest to that date should be used")
+
+		stdout, stderr, err := run("--as-of", "2022-06-01", "-r", "https://example.com/pricing")
+		require.NoError(t, err, stderr)
+
+		assert.Contains(t, stdout, "captured 2023-01-10 08:00 UTC")
+		assert.Contains(t, stdout, "nine dollars")
+	})
+
+	t.Run("invalid_date", func(t *testing.T) {
+		t.Log("SPEC: Historical Snapshots")
+		t.Log("GIVEN a date that is not YYYY-MM-DD")
+		t.Log("WHEN the user runs sz fetch --as-of with it")
+		t.Log("THEN the command should exit 2 and explain the format")
+
+		_, stderr, err := run("--as-of", "June 2022", "https://example.com/pricing")
+		require.Error(t, err)
+
+		assert.Equal(t, 2, err.(*exec.ExitError).ExitCode())
+		assert.Contains(t, stderr, "YYYY-MM-DD")
+	})
+}
+
+func TestWaybackFallbackChromeSpec(t *testing.T) {
+	t.Log("SPEC: Wayback Machine Fallback")
+	t.Log("GIVEN a page that answers 404 to Chrome with an error page of its own, but was archived")
+	t.Log("WHEN the user runs sz --wayback-fallback on it and Chrome renders it")
+	t.Log("THEN the 404 should be noticed without fetching the page again over HTTP, and the archived copy distilled")
+
+	binary := buildBinary(t)
+
+	var mu sync.Mutex
+	var agents []string
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/vanished" {
+			mu.Lock()
+			agents = append(agents, r.UserAgent())
+			mu.Unlock()
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprint(w, `<html><body><article><h1>Nothing Here</h1><p>The page you asked for has wandered off somewhere else on the site.</p></article></body></html>`)
+	}))
+	defer site.Close()
+
+	var archive *httptest.Server
+	archive = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/wayback/available":
+			_, _ = fmt.Fprintf(w, `{"archived_snapshots": {"closest": {"status": "200", "available": true,
+				"url": "%s/web/20230415093000/%s/vanished", "timestamp": "20230415093000"}}}`, archive.URL, site.URL)
+		case strings.HasPrefix(r.URL.Path, "/web/20230415093000id_/"):
+			_, _ = fmt.Fprint(w, `<html><body><article><h1>Vanished Essay</h1><p>The essay as it stood before the site removed it.</p></article></body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer archive.Close()
+
+	cmd := exec.Command(binary, "--socket", filepath.Join(t.TempDir(), "sz.sock"), "--wayback-fallback", site.URL+"/vanished")
+	cmd.Env = append(os.Environ(),
+		"ESSENZ_WAYBACK_ENDPOINT="+archive.URL+"/wayback/available",
+		"ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+	var stdout, stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	require.NoError(t, cmd.Run(), stderr.String())
+
+	assert.Contains(t, stdout.String(), "Vanished Essay")
+	assert.NotContains(t, stdout.String(), "Nothing Here", "The error page should not be distilled")
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.NotEmpty(t, agents)
+	for _, agent := range agents {
+		assert.Contains(t, agent, "Chrome", "Only Chrome should have asked for the page, not the HTTP fallback")
+	}
+}
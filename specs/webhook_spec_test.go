@@ -0,0 +1,115 @@
+package specs
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSpec(t *testing.T) {
+	binary := buildBinary(t)
+	cmd := exec.Command(binary, "serve", "--grpc", "", "--http", "127.0.0.1:0", "--queue-dir", t.TempDir(), "--socket", filepath.Join(t.TempDir(), "sz.sock"))
+	cmd.Env = append(os.Environ(), "ESSENZ_LOG_FILE="+filepath.Join(t.TempDir(), "daemon.log"))
+	stderr, err := cmd.StderrPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+	defer func() {
+		_ = cmd.Process.Signal(os.Interrupt)
+		_ = cmd.Wait()
+	}()
+
+	banner, err := bufio.NewReader(stderr).ReadString('\n')
+	require.NoError(t, err)
+	jobsURL := "http://" + strings.TrimSpace(strings.TrimPrefix(banner, "Serving HTTP on ")) + "/v1/jobs"
+	go func() { _, _ = io.Copy(io.Discard, stderr) }()
+
+	results := make(chan map[string]any, 1)
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var result map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&result)
+		results <- result
+	}))
+	defer callback.Close()
+
+	submit := func(t *testing.T, job map[string]any) (int, map[string]any) {
+		body, err := json.Marshal(job)
+		require.NoError(t, err)
+		resp, err := http.Post(jobsURL, "application/json", strings.NewReader(string(body)))
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		var reply map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&reply))
+		return resp.StatusCode, reply
+	}
+	receive := func(t *testing.T) map[string]any {
+		select {
+		case result := <-results:
+			return result
+		case <-time.After(time.Minute):
+			t.Fatal("no result was posted to the callback")
+			return nil
+		}
+	}
+
+	t.Run("job_posts_result_to_callback", func(t *testing.T) {
+		t.Log("SPEC: Webhook Delivery")
+		t.Log("GIVEN sz serve with the HTTP job API")
+		t.Log("WHEN a job with HTML and a callback URL is posted to /v1/jobs")
+		t.Log("THEN it should be accepted at once and the markdown and metadata posted to the callback")
+
+		code, reply := submit(t, map[string]any{
+			"html":         `<html><head><title>Queued</title></head><body><article><h1>Queued</h1><p>Processed in the background.</p></article></body></html>`,
+			"callback_url": callback.URL,
+		})
+		require.Equal(t, http.StatusAccepted, code)
+		require.NotEmpty(t, reply["id"])
+
+		result := receive(t)
+		assert.Equal(t, reply["id"], result["id"])
+		assert.Equal(t, "done", result["status"])
+		assert.Contains(t, result["markdown"], "Processed in the background.")
+		require.IsType(t, map[string]any{}, result["metadata"])
+		assert.Equal(t, "Queued", result["metadata"].(map[string]any)["title"])
+	})
+
+	t.Run("failed_job_reports_error", func(t *testing.T) {
+		t.Log("SPEC: Failed Jobs")
+		t.Log("GIVEN a page that cannot be fetched")
+		t.Log("WHEN a job for it is posted")
+		t.Log("THEN the callback should receive a failed status with the error")
+
+		gone := httptest.NewServer(http.NotFoundHandler())
+		gone.Close()
+
+		code, reply := submit(t, map[string]any{"url": gone.URL, "callback_url": callback.URL})
+		require.Equal(t, http.StatusAccepted, code)
+
+		result := receive(t)
+		assert.Equal(t, reply["id"], result["id"])
+		assert.Equal(t, "failed", result["status"])
+		assert.NotEmpty(t, result["error"])
+		assert.Nil(t, result["markdown"])
+	})
+
+	t.Run("job_rejects_bad_callback", func(t *testing.T) {
+		t.Log("SPEC: Job Validation")
+		t.Log("GIVEN sz serve with the HTTP job API")
+		t.Log("WHEN a job whose callback URL is not http or https is posted")
+		t.Log("THEN it should be rejected with 400")
+
+		code, reply := submit(t, map[string]any{"url": "https://example.com", "callback_url": "file:///tmp/hook"})
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, reply["error"], "callback_url")
+	})
+}